@@ -0,0 +1,187 @@
+package goth
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Checks of the warnings of an AuditReport.
+const (
+	AuditCheckCallback = "callback"
+	AuditCheckPKCE     = "pkce"
+	AuditCheckScope    = "scope"
+	AuditCheckStateTTL = "state_ttl"
+)
+
+// AuditReport describes the registered providers and what looks insecure in
+// their configuration. It marshals to JSON, to be served from an admin
+// end-point, and prints one warning per line, to be logged at startup.
+type AuditReport struct {
+	Providers []ProviderAudit `json:"providers"`
+	Warnings  []AuditWarning  `json:"warnings"`
+}
+
+// ProviderAudit describes the configuration of a registered provider, as far
+// as it can be read from its CallbackURL, PKCE and oauth2 config fields.
+type ProviderAudit struct {
+	Name        string   `json:"name"`
+	CallbackURL string   `json:"callback_url,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	// PKCE is nil for providers that cannot enable PKCE, or always use it.
+	PKCE *bool `json:"pkce,omitempty"`
+}
+
+// AuditWarning is a single finding of AuditConfiguration. Provider is empty
+// for warnings about gothic rather than a provider.
+type AuditWarning struct {
+	Provider string `json:"provider,omitempty"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+func (w AuditWarning) String() string {
+	if w.Provider == "" {
+		return fmt.Sprintf("%s: %s", w.Check, w.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", w.Provider, w.Check, w.Message)
+}
+
+func (r AuditReport) String() string {
+	var b strings.Builder
+	for _, w := range r.Warnings {
+		b.WriteString(w.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// BroadScopes lists, by provider name, the scopes granting much more than a
+// login needs, such as write access to the repositories or the mailbox of the
+// user. AuditConfiguration warns about the providers requesting them; add the
+// scopes of other providers as needed.
+var BroadScopes = map[string][]string{
+	"azureadv2":       {"Directory.ReadWrite.All", "Files.ReadWrite.All", "Mail.ReadWrite", "User.ReadWrite.All"},
+	"bitbucket":       {"account:write", "repository:admin"},
+	"github":          {"admin:org", "delete_repo", "repo", "write:org"},
+	"gitlab":          {"api", "sudo", "write_repository"},
+	"google":          {"https://mail.google.com/", "https://www.googleapis.com/auth/cloud-platform", "https://www.googleapis.com/auth/drive"},
+	"microsoftonline": {"Directory.ReadWrite.All", "Files.ReadWrite.All", "Mail.ReadWrite", "User.ReadWrite.All"},
+	"slack":           {"admin"},
+}
+
+// AuditCheck adds its warnings to report, for parts of the configuration goth
+// does not know about, such as how long gothic keeps the state of a login.
+type AuditCheck func(report *AuditReport)
+
+var (
+	auditChecksMu sync.Mutex
+	auditChecks   []AuditCheck
+)
+
+// RegisterAuditCheck adds check to the checks run by AuditConfiguration.
+func RegisterAuditCheck(check AuditCheck) {
+	auditChecksMu.Lock()
+	defer auditChecksMu.Unlock()
+	auditChecks = append(auditChecks, check)
+}
+
+// AuditConfiguration reports the registered providers, with warnings about
+// invalid or plain http callback URLs, callback URLs on hosts not allowed by
+// the CallbackPolicy providers were registered with, public clients not using PKCE, scopes listed in
+// BroadScopes and the checks registered with RegisterAuditCheck. Run it once
+// the providers are registered:
+//
+//	for _, w := range goth.AuditConfiguration().Warnings {
+//		log.Println(w)
+//	}
+func AuditConfiguration() AuditReport {
+	report := AuditReport{Providers: []ProviderAudit{}, Warnings: []AuditWarning{}}
+
+	providers := loadProviders()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	policy := auditCallbackPolicy()
+	for _, name := range names {
+		report.auditProvider(name, providers[name], policy)
+	}
+
+	auditChecksMu.Lock()
+	checks := append([]AuditCheck(nil), auditChecks...)
+	auditChecksMu.Unlock()
+	for _, check := range checks {
+		check(&report)
+	}
+	return report
+}
+
+// Warn adds a warning to the report.
+func (r *AuditReport) Warn(provider, check, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, AuditWarning{
+		Provider: provider,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *AuditReport) auditProvider(name string, provider Provider, policy CallbackPolicy) {
+	audit := ProviderAudit{Name: name}
+	v, _ := providerStruct(provider)
+
+	if callbackURL, ok := ProviderCallbackURL(provider); ok {
+		audit.CallbackURL = callbackURL
+		if err := policy.Validate(callbackURL); err != nil {
+			r.Warn(name, AuditCheckCallback, "%v", err)
+		}
+	}
+
+	if f := structField(v, "PKCE"); f.IsValid() && f.Kind() == reflect.Bool {
+		pkce := f.Bool()
+		audit.PKCE = &pkce
+		if !pkce && !hasSecret(v) {
+			r.Warn(name, AuditCheckPKCE, "public clients, without a client secret, must use PKCE")
+		}
+	}
+
+	if config := structField(v, "config"); config.IsValid() && config.Kind() == reflect.Ptr && !config.IsNil() {
+		if scopes := config.Elem().FieldByName("Scopes"); scopes.IsValid() && scopes.Kind() == reflect.Slice {
+			for i := 0; i < scopes.Len(); i++ {
+				audit.Scopes = append(audit.Scopes, scopes.Index(i).String())
+			}
+		}
+	}
+	for _, scope := range audit.Scopes {
+		for _, broad := range BroadScopes[name] {
+			if scope == broad {
+				r.Warn(name, AuditCheckScope, "scope %q grants more than a login needs", scope)
+			}
+		}
+	}
+
+	r.Providers = append(r.Providers, audit)
+}
+
+// hasSecret reports whether the provider has a client secret, or does not
+// tell.
+func hasSecret(v reflect.Value) bool {
+	for _, name := range []string{"Secret", "ClientSecret"} {
+		if f := structField(v, name); f.IsValid() && f.Kind() == reflect.String {
+			return f.String() != ""
+		}
+	}
+	return true
+}
+
+// structField returns the named field of v, or the zero Value when v is not a
+// struct or has no such field.
+func structField(v reflect.Value, name string) reflect.Value {
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v.FieldByName(name)
+}
@@ -0,0 +1,79 @@
+package goth_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/bgdsh/goth/providers/github"
+	"github.com/bgdsh/goth/providers/okta"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditConfiguration(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	public := okta.New("client_id", "", "https://example.okta.com", "https://example.com/auth/okta/callback", "openid")
+	goth.UseProviders(
+		github.New("key", "secret", "http://example.com/auth/github/callback", "user", "repo"),
+		public,
+		&faux.Provider{},
+	)
+
+	report := goth.AuditConfiguration()
+	a.Len(report.Providers, 3)
+	a.Equal("faux", report.Providers[0].Name)
+	a.Nil(report.Providers[0].PKCE)
+	a.Equal("github", report.Providers[1].Name)
+	a.Equal("http://example.com/auth/github/callback", report.Providers[1].CallbackURL)
+	a.Equal([]string{"user", "repo"}, report.Providers[1].Scopes)
+	a.False(*report.Providers[1].PKCE)
+
+	checks := map[string]string{}
+	for _, w := range report.Warnings {
+		checks[w.Provider+" "+w.Check] = w.Message
+	}
+	a.Contains(checks, "github "+goth.AuditCheckCallback)
+	a.Contains(checks, "github "+goth.AuditCheckScope)
+	a.NotContains(checks, "github "+goth.AuditCheckPKCE, "the client has a secret")
+	a.Contains(checks, "okta "+goth.AuditCheckPKCE)
+	a.Contains(report.String(), "github: callback: ")
+
+	public.PKCE = true
+	for _, w := range goth.AuditConfiguration().Warnings {
+		a.NotEqual("okta", w.Provider)
+	}
+
+	b, err := json.Marshal(report)
+	a.NoError(err)
+	a.Contains(string(b), `"check":"scope"`)
+}
+
+func Test_AuditConfigurationCallbackHosts(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	policy := goth.CallbackPolicy{RequireHTTPS: true, AllowedHosts: []string{"example.com"}}
+	a.NoError(policy.UseProviders(github.New("key", "secret", "https://example.com/auth/github/callback")))
+	// Providers registered without the policy skip the validation, the audit
+	// still flags them.
+	goth.UseProviders(okta.New("client_id", "secret", "https://example.okta.com", "https://evil.example.net/auth/okta/callback"))
+
+	checks := map[string]string{}
+	for _, w := range goth.AuditConfiguration().Warnings {
+		checks[w.Provider+" "+w.Check] = w.Message
+	}
+	a.NotContains(checks, "github "+goth.AuditCheckCallback)
+	a.Equal(`callback URL "https://evil.example.net/auth/okta/callback" points to a host that is not allowed`, checks["okta "+goth.AuditCheckCallback])
+}
+
+func Test_RegisterAuditCheck(t *testing.T) {
+	a := assert.New(t)
+
+	goth.RegisterAuditCheck(func(report *goth.AuditReport) {
+		report.Warn("", "custom", "checked %d providers", len(report.Providers))
+	})
+	a.Contains(goth.AuditConfiguration().Warnings, goth.AuditWarning{Check: "custom", Message: "checked 0 providers"})
+}
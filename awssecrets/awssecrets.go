@@ -0,0 +1,76 @@
+// Package awssecrets implements a goth.SecretSource reading the secrets of AWS
+// Secrets Manager:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	...
+//	source := awssecrets.New(secretsmanager.NewFromConfig(cfg), "goth/github")
+//	source.Key = "client_secret"
+//	rotation, err := goth.RotateProvider(source, 5*time.Minute, func(secret string) (goth.Provider, error) {
+//		return github.New(os.Getenv("GITHUB_KEY"), secret, callbackURL), nil
+//	})
+package awssecrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/bgdsh/goth"
+)
+
+// API is the part of the Secrets Manager client used by a Source,
+// implemented by *secretsmanager.Client.
+type API interface {
+	GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// Source reads the current version of a secret of AWS Secrets Manager.
+type Source struct {
+	Client   API
+	SecretID string
+	// Key, when set, is the key of the secret in the JSON object stored by
+	// Secrets Manager, for secrets holding several values.
+	Key string
+}
+
+var _ goth.SecretSource = &Source{}
+
+// New returns the source of the secret named or identified by the ARN
+// secretID.
+func New(client API, secretID string) *Source {
+	return &Source{Client: client, SecretID: secretID}
+}
+
+// Secret reads the secret from Secrets Manager.
+func (s *Source) Secret(ctx context.Context) (string, error) {
+	out, err := s.Client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(s.SecretID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("awssecrets: %w", err)
+	}
+	secret := aws.ToString(out.SecretString)
+	if secret == "" {
+		secret = string(out.SecretBinary)
+	}
+	if s.Key != "" {
+		values := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(secret), &values); err != nil {
+			return "", fmt.Errorf("awssecrets: %s is not a JSON object: %w", s.SecretID, err)
+		}
+		secret, _ = values[s.Key].(string)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("awssecrets: %s has no value", s.describe())
+	}
+	return secret, nil
+}
+
+func (s *Source) describe() string {
+	if s.Key == "" {
+		return s.SecretID
+	}
+	return s.SecretID + " " + s.Key
+}
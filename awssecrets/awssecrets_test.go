@@ -0,0 +1,45 @@
+package awssecrets_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/bgdsh/goth/awssecrets"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSecretsManager struct {
+	secrets map[string]string
+}
+
+func (f *fakeSecretsManager) GetSecretValue(_ context.Context, in *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(f.secrets[*in.SecretId])}, nil
+}
+
+func Test_Source(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	client := &fakeSecretsManager{secrets: map[string]string{
+		"goth/github": "github-secret",
+		"goth/all":    `{"client_secret": "okta-secret"}`,
+	}}
+
+	secret, err := awssecrets.New(client, "goth/github").Secret(context.Background())
+	a.NoError(err)
+	a.Equal("github-secret", secret)
+
+	source := awssecrets.New(client, "goth/all")
+	source.Key = "client_secret"
+	secret, err = source.Secret(context.Background())
+	a.NoError(err)
+	a.Equal("okta-secret", secret)
+
+	source.Key = "missing"
+	_, err = source.Secret(context.Background())
+	a.EqualError(err, "awssecrets: goth/all missing has no value")
+
+	_, err = awssecrets.New(client, "goth/unknown").Secret(context.Background())
+	a.EqualError(err, "awssecrets: goth/unknown has no value")
+}
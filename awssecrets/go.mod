@@ -0,0 +1,12 @@
+module github.com/bgdsh/goth/awssecrets
+
+go 1.21
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.32.7
+	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.34.6
+	github.com/bgdsh/goth v0.0.0
+	github.com/stretchr/testify v1.7.1
+)
+
+replace github.com/bgdsh/goth => ..
@@ -0,0 +1,247 @@
+package goth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrProviderUnavailable is matched, with errors.Is, by the errors returned
+// while the circuit breaker of a provider is open.
+var ErrProviderUnavailable = errors.New("provider unavailable")
+
+// ProviderUnavailableError is returned instead of calling an end-point of a
+// provider whose circuit breaker is open, so that logins fail fast rather than
+// hang while the end-point is down. It matches ErrProviderUnavailable.
+type ProviderUnavailableError struct {
+	// Provider is the name of the provider.
+	Provider string
+	// Endpoint is the URL, without its query, of the failing end-point.
+	Endpoint string
+	// Until is when the breaker lets a call through again to probe the
+	// end-point.
+	Until time.Time
+}
+
+func (e *ProviderUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s is unavailable until %s", e.Provider, e.Endpoint, e.Until.Format(time.RFC3339))
+}
+
+// Is reports whether target is ErrProviderUnavailable.
+func (e *ProviderUnavailableError) Is(target error) bool {
+	return target == ErrProviderUnavailable
+}
+
+// BreakerSettings are the thresholds of a circuit breaker.
+type BreakerSettings struct {
+	// Failures is how many consecutive failed calls to an end-point open
+	// its breaker.
+	Failures int
+	// OpenFor is how long an open breaker fails the calls to its end-point
+	// before letting one through to probe it.
+	OpenFor time.Duration
+	// IsFailure reports whether a call failed. By default, network errors,
+	// timeouts and 5xx responses are failures.
+	IsFailure func(resp *http.Response, err error) bool
+}
+
+// DefaultBreakerSettings are the settings of UseCircuitBreakers callers
+// usually want.
+var DefaultBreakerSettings = BreakerSettings{Failures: 5, OpenFor: 30 * time.Second}
+
+// CircuitBreaker tracks the failures of the end-points of a provider, each
+// end-point having its own breaker. It is safe for concurrent use.
+type CircuitBreaker struct {
+	provider string
+	settings BreakerSettings
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointBreaker
+}
+
+type endpointBreaker struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker returns a circuit breaker for the named provider.
+func NewCircuitBreaker(provider string, settings BreakerSettings) *CircuitBreaker {
+	return &CircuitBreaker{provider: provider, settings: settings, endpoints: map[string]*endpointBreaker{}}
+}
+
+// Available returns a *ProviderUnavailableError when the breaker of one of the
+// end-points of the provider is open, and nil otherwise.
+func (b *CircuitBreaker) Available() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := Now()
+	for endpoint, e := range b.endpoints {
+		if now.Before(e.openUntil) {
+			return &ProviderUnavailableError{Provider: b.provider, Endpoint: endpoint, Until: e.openUntil}
+		}
+	}
+	return nil
+}
+
+// Transport returns a RoundTripper making its calls with base, or
+// http.DefaultTransport, unless the breaker of their end-point is open.
+func (b *CircuitBreaker) Transport(base http.RoundTripper) http.RoundTripper {
+	return &breakerTransport{breaker: b, base: base}
+}
+
+// allow reports whether a call to endpoint may be made. Once an open breaker
+// times out, a single call is let through to probe the end-point.
+func (b *CircuitBreaker) allow(endpoint string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e := b.endpoints[endpoint]
+	if e == nil || e.failures < b.settings.Failures {
+		return nil
+	}
+	if now := Now(); now.Before(e.openUntil) || e.probing {
+		return &ProviderUnavailableError{Provider: b.provider, Endpoint: endpoint, Until: e.openUntil}
+	}
+	e.probing = true
+	return nil
+}
+
+func (b *CircuitBreaker) record(endpoint string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e := b.endpoints[endpoint]
+	if !failed {
+		delete(b.endpoints, endpoint)
+		return
+	}
+	if e == nil {
+		e = &endpointBreaker{}
+		b.endpoints[endpoint] = e
+	}
+	e.failures++
+	e.probing = false
+	if e.failures >= b.settings.Failures {
+		e.openUntil = Now().Add(b.settings.OpenFor)
+	}
+}
+
+func (b *CircuitBreaker) isFailure(resp *http.Response, err error) bool {
+	if b.settings.IsFailure != nil {
+		return b.settings.IsFailure(resp, err)
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode >= 500
+}
+
+type breakerTransport struct {
+	breaker *CircuitBreaker
+	base    http.RoundTripper
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	endpoint := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	if err := t.breaker.allow(endpoint); err != nil {
+		return nil, err
+	}
+	resp, err := base.RoundTrip(req)
+	t.breaker.record(endpoint, t.breaker.isFailure(resp, err))
+	return resp, err
+}
+
+var (
+	breakersMu             sync.Mutex
+	breakers               = map[string]*CircuitBreaker{}
+	breakerSettings        map[string]BreakerSettings
+	defaultBreakerSettings *BreakerSettings
+)
+
+// UseCircuitBreakers gives every provider a circuit breaker with the given
+// settings, see WithCircuitBreaker. gothic then fails logins with a
+// *ProviderUnavailableError as soon as the breaker of the provider is open.
+func UseCircuitBreakers(settings BreakerSettings) {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	defaultBreakerSettings = &settings
+	breakers = map[string]*CircuitBreaker{}
+}
+
+// UseCircuitBreaker gives the named provider a circuit breaker with the
+// given settings, overriding those of UseCircuitBreakers.
+func UseCircuitBreaker(provider string, settings BreakerSettings) {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	if breakerSettings == nil {
+		breakerSettings = map[string]BreakerSettings{}
+	}
+	breakerSettings[provider] = settings
+	delete(breakers, provider)
+}
+
+// ClearCircuitBreakers removes the circuit breakers of every provider.
+func ClearCircuitBreakers() {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakers = map[string]*CircuitBreaker{}
+	breakerSettings = nil
+	defaultBreakerSettings = nil
+}
+
+// GetCircuitBreaker returns the circuit breaker of the named provider, if
+// circuit breakers are in use for it.
+func GetCircuitBreaker(provider string) (*CircuitBreaker, bool) {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	if b, ok := breakers[provider]; ok {
+		return b, true
+	}
+	settings, ok := breakerSettings[provider]
+	if !ok {
+		if defaultBreakerSettings == nil {
+			return nil, false
+		}
+		settings = *defaultBreakerSettings
+	}
+	b := NewCircuitBreaker(provider, settings)
+	breakers[provider] = b
+	return b, true
+}
+
+// ProviderAvailable returns a *ProviderUnavailableError when the circuit
+// breaker of the named provider is open, so that its login button can be
+// disabled, and nil otherwise.
+func ProviderAvailable(provider string) error {
+	b, ok := GetCircuitBreaker(provider)
+	if !ok {
+		return nil
+	}
+	return b.Available()
+}
+
+// WithCircuitBreaker returns a provider whose HTTP calls go through the
+// circuit breaker of p, if circuit breakers are in use for it. Providers that
+// do not implement ClientBinder are returned unchanged.
+func WithCircuitBreaker(p Provider) Provider {
+	binder, ok := p.(ClientBinder)
+	if !ok {
+		return p
+	}
+	b, ok := GetCircuitBreaker(p.Name())
+	if !ok {
+		return p
+	}
+	return binder.BindClient(func(client *http.Client) *http.Client {
+		c := *HTTPClientWithFallBack(client)
+		c.Transport = b.Transport(c.Transport)
+		return &c
+	})
+}
@@ -0,0 +1,93 @@
+package goth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CircuitBreaker(t *testing.T) {
+	a := assert.New(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	status, calls := http.StatusServiceUnavailable, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	b := goth.NewCircuitBreaker("github", goth.BreakerSettings{Failures: 2, OpenFor: time.Minute})
+	client := &http.Client{Transport: b.Transport(nil)}
+	get := func() error {
+		resp, err := client.Get(ts.URL + "/token")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	a.NoError(get())
+	a.NoError(b.Available())
+	a.NoError(get())
+	a.Equal(2, calls)
+
+	err := get()
+	a.True(errors.Is(err, goth.ErrProviderUnavailable))
+	a.Equal(2, calls, "the end-point is not called while the breaker is open")
+	var unavailable *goth.ProviderUnavailableError
+	a.True(errors.As(b.Available(), &unavailable))
+	a.Equal("github", unavailable.Provider)
+	a.Equal(ts.URL+"/token", unavailable.Endpoint)
+	a.Equal(now.Add(time.Minute), unavailable.Until)
+
+	resp, err := client.Get(ts.URL + "/user")
+	a.NoError(err, "other end-points have their own breaker")
+	resp.Body.Close()
+
+	now = now.Add(time.Minute)
+	a.NoError(get(), "a probe is let through once the breaker times out")
+	a.Equal(4, calls)
+	a.Error(get(), "the failed probe opened the breaker again")
+
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	a.NoError(get())
+	a.NoError(get())
+	a.Equal(6, calls)
+	a.NoError(b.Available())
+}
+
+func Test_UseCircuitBreakers(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearCircuitBreakers()
+
+	_, ok := goth.GetCircuitBreaker("github")
+	a.False(ok)
+	a.NoError(goth.ProviderAvailable("github"))
+
+	goth.UseCircuitBreakers(goth.DefaultBreakerSettings)
+	goth.UseCircuitBreaker("gitlab", goth.BreakerSettings{Failures: 1, OpenFor: time.Minute})
+	b, ok := goth.GetCircuitBreaker("github")
+	a.True(ok)
+	same, _ := goth.GetCircuitBreaker("github")
+	a.Same(b, same)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+	gitlab, _ := goth.GetCircuitBreaker("gitlab")
+	resp, err := (&http.Client{Transport: gitlab.Transport(nil)}).Get(ts.URL)
+	a.NoError(err)
+	resp.Body.Close()
+	a.True(errors.Is(goth.ProviderAvailable("gitlab"), goth.ErrProviderUnavailable))
+	a.NoError(goth.ProviderAvailable("github"))
+}
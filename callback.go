@@ -0,0 +1,156 @@
+package goth
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// CallbackPolicy describes what a valid callback (redirect) URL looks like.
+// Validating callback URLs when providers are registered surfaces
+// misconfigurations at startup rather than as a failed login in production.
+type CallbackPolicy struct {
+	// RequireHTTPS rejects plain http callback URLs, except on loopback
+	// addresses which are only reachable from the user's own machine.
+	// Enable it in production.
+	RequireHTTPS bool
+
+	// AllowedHosts, when not empty, lists the hosts callback URLs may point
+	// to. An entry starting with "*." matches any subdomain.
+	AllowedHosts []string
+}
+
+// Validate reports why callbackURL does not satisfy the policy, if it does
+// not. A callback URL must be absolute, use http or https, and carry neither
+// user information nor a fragment.
+func (p CallbackPolicy) Validate(callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("callback URL %q is invalid: %v", callbackURL, err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("callback URL %q is not absolute", callbackURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("callback URL %q must use http or https", callbackURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("callback URL %q must not contain user information", callbackURL)
+	}
+	if u.Fragment != "" || strings.Contains(callbackURL, "#") {
+		return fmt.Errorf("callback URL %q must not contain a fragment", callbackURL)
+	}
+	if p.RequireHTTPS && u.Scheme != "https" && !isLoopback(u.Hostname()) {
+		return fmt.Errorf("callback URL %q must use https", callbackURL)
+	}
+	if len(p.AllowedHosts) > 0 && !hostAllowed(u.Hostname(), p.AllowedHosts) {
+		return fmt.Errorf("callback URL %q points to a host that is not allowed", callbackURL)
+	}
+	return nil
+}
+
+// ValidateProvider validates the callback URL of a provider. Providers keep
+// it in their exported CallbackURL field; providers without one are not
+// checked.
+func (p CallbackPolicy) ValidateProvider(provider Provider) error {
+	callbackURL, ok := ProviderCallbackURL(provider)
+	if !ok {
+		return nil
+	}
+	if err := p.Validate(callbackURL); err != nil {
+		return fmt.Errorf("%s: %v", provider.Name(), err)
+	}
+	return nil
+}
+
+// UseProviders validates the callback URL of every provider and registers
+// them with UseProviders. Nothing is registered when any of them is invalid.
+// The AllowedHosts of the policy are kept, so that AuditConfiguration also
+// flags the providers registered afterwards, without validation, with a
+// callback URL on another host.
+func (p CallbackPolicy) UseProviders(viders ...Provider) error {
+	for _, provider := range viders {
+		if err := p.ValidateProvider(provider); err != nil {
+			return err
+		}
+	}
+	if len(p.AllowedHosts) > 0 {
+		callbackHostsMu.Lock()
+		callbackHosts = append(callbackHosts, p.AllowedHosts...)
+		callbackHostsMu.Unlock()
+	}
+	UseProviders(viders...)
+	return nil
+}
+
+var (
+	callbackHostsMu sync.Mutex
+	callbackHosts   []string
+)
+
+// auditCallbackPolicy is the policy AuditConfiguration checks callback URLs
+// against: https, on the hosts allowed by the policies providers were
+// registered with.
+func auditCallbackPolicy() CallbackPolicy {
+	callbackHostsMu.Lock()
+	defer callbackHostsMu.Unlock()
+	return CallbackPolicy{RequireHTTPS: true, AllowedHosts: append([]string(nil), callbackHosts...)}
+}
+
+// ProviderCallbackURL returns the value of the CallbackURL field of a
+// provider, and false if the provider has no such field or it is empty.
+func ProviderCallbackURL(provider Provider) (string, bool) {
+	v, ok := providerStruct(provider)
+	if !ok {
+		return "", false
+	}
+
+	f := v.FieldByName("CallbackURL")
+	if !f.IsValid() || f.Kind() != reflect.String || f.String() == "" {
+		return "", false
+	}
+	return f.String(), true
+}
+
+// providerStruct returns the struct a provider points to.
+func providerStruct(provider Provider) (reflect.Value, bool) {
+	v := reflect.ValueOf(provider)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func hostAllowed(host string, allowed []string) bool {
+	host = strings.ToLower(host)
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if strings.HasPrefix(a, "*.") {
+			if strings.HasSuffix(host, a[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package goth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// ClientAssertionType is the client_assertion_type of clients authenticating
+// with a JWT, as with the private_key_jwt method of OpenID Connect.
+// See https://datatracker.ietf.org/doc/html/rfc7523#section-2.2
+const ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ClientAssertionLifetime is how long the assertions built by ClientAssertion
+// are valid for.
+var ClientAssertionLifetime = 5 * time.Minute
+
+// ClientAssertion returns a JWT authenticating clientID to the token endpoint
+// tokenURL, signed with key: RS256 for an *rsa.PrivateKey, ES256 for an
+// *ecdsa.PrivateKey. keyID, when set, is the kid header of the JWT.
+func ClientAssertion(clientID, tokenURL string, key interface{}, keyID string) (string, error) {
+	var method jwt.SigningMethod
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		method = jwt.SigningMethodES256
+	default:
+		return "", errors.New("client assertions can only be signed with RSA or ECDSA private keys")
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := Now()
+	token := jwt.NewWithClaims(method, jwt.RegisteredClaims{
+		Issuer:    clientID,
+		Subject:   clientID,
+		Audience:  jwt.ClaimStrings{tokenURL},
+		ID:        base64.RawURLEncoding.EncodeToString(jti),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ClientAssertionLifetime)),
+	})
+	if keyID != "" {
+		token.Header["kid"] = keyID
+	}
+	return token.SignedString(key)
+}
+
+// ClientAssertionOptions returns the options sending assertion on a token
+// request. The oauth2.Config must have no ClientSecret and use
+// oauth2.AuthStyleInParams.
+func ClientAssertionOptions(assertion string) []oauth2.AuthCodeOption {
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("client_assertion_type", ClientAssertionType),
+		oauth2.SetAuthURLParam("client_assertion", assertion),
+	}
+}
@@ -0,0 +1,48 @@
+package goth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ClientAssertion(t *testing.T) {
+	a := assert.New(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	assertion, err := goth.ClientAssertion("client", "https://example.com/token", rsaKey, "key-1")
+	a.NoError(err)
+
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(assertion, claims, func(*jwt.Token) (interface{}, error) {
+		return &rsaKey.PublicKey, nil
+	})
+	a.NoError(err)
+	a.Equal("RS256", token.Method.Alg())
+	a.Equal("key-1", token.Header["kid"])
+	a.Equal("client", claims.Issuer)
+	a.Equal("client", claims.Subject)
+	a.NoError(goth.ValidateClaims(claims, "client", "https://example.com/token"))
+	a.NotEmpty(claims.ID)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	assertion, err = goth.ClientAssertion("client", "https://example.com/token", ecKey, "")
+	a.NoError(err)
+	token, err = jwt.Parse(assertion, func(*jwt.Token) (interface{}, error) {
+		return &ecKey.PublicKey, nil
+	})
+	a.NoError(err)
+	a.Equal("ES256", token.Method.Alg())
+	a.Nil(token.Header["kid"])
+
+	_, err = goth.ClientAssertion("client", "https://example.com/token", []byte("secret"), "")
+	a.Error(err)
+}
@@ -0,0 +1,94 @@
+package goth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ClientCredentialsGranter is implemented by providers whose token endpoint
+// supports the client credentials grant, which issues tokens to the
+// application itself rather than to a user. Use it through ClientCredentials.
+type ClientCredentialsGranter interface {
+	ClientCredentialsToken(scopes ...string) (*oauth2.Token, error)
+}
+
+// ClientCredentialsNotSupportedError is returned by ClientCredentials when the
+// provider does not support the client credentials grant.
+type ClientCredentialsNotSupportedError struct {
+	Provider string
+}
+
+func (e *ClientCredentialsNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: the client credentials grant is not supported", e.Provider)
+}
+
+// ClientCredentials requests a token for the application, with the client
+// credentials of the registered provider named providerName, for background
+// jobs calling the APIs of the provider without a user. The token can be
+// stored like any oauth2.Token; it has no refresh token, a new one is
+// requested once it expires, see ClientCredentialsTokenSource.
+//
+// It returns a *ClientCredentialsNotSupportedError when the provider does not
+// support the grant.
+func ClientCredentials(providerName string, scopes ...string) (*oauth2.Token, error) {
+	provider, err := GetProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	granter, ok := provider.(ClientCredentialsGranter)
+	if !ok {
+		return nil, &ClientCredentialsNotSupportedError{Provider: providerName}
+	}
+	return granter.ClientCredentialsToken(scopes...)
+}
+
+// ClientCredentialsTokenSource returns a token source returning token, which
+// may be nil, until it expires, and new tokens requested with
+// ClientCredentials afterwards. Use it with oauth2.NewClient to call the APIs
+// of the provider.
+func ClientCredentialsTokenSource(token *oauth2.Token, providerName string, scopes ...string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(token, clientCredentialsSource{providerName: providerName, scopes: scopes})
+}
+
+type clientCredentialsSource struct {
+	providerName string
+	scopes       []string
+}
+
+func (s clientCredentialsSource) Token() (*oauth2.Token, error) {
+	return ClientCredentials(s.providerName, s.scopes...)
+}
+
+// ClientCredentialsRequest describes a single client credentials grant
+// request. AuthStyle says how the client credentials are sent, the default
+// tries HTTP Basic authentication first. Resource, when set, is sent as an
+// RFC 8707 resource indicator.
+type ClientCredentialsRequest struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	AuthStyle    oauth2.AuthStyle
+	Scopes       []string
+	Resource     string
+}
+
+// RequestClientCredentials performs a client credentials grant against the
+// token endpoint described by r.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-4.4
+func RequestClientCredentials(client *http.Client, r ClientCredentialsRequest) (*oauth2.Token, error) {
+	config := clientcredentials.Config{
+		ClientID:     r.ClientID,
+		ClientSecret: r.ClientSecret,
+		TokenURL:     r.TokenURL,
+		Scopes:       r.Scopes,
+		AuthStyle:    r.AuthStyle,
+	}
+	if r.Resource != "" {
+		config.EndpointParams = url.Values{ResourceParam: {r.Resource}}
+	}
+	return config.Token(ContextForClient(client))
+}
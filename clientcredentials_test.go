@@ -0,0 +1,63 @@
+package goth_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/bgdsh/goth/providers/okta"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ClientCredentials(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		a.Equal("/oauth2/default/v1/token", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("client_credentials", r.PostForm.Get("grant_type"))
+		a.Equal("jobs:run", r.PostForm.Get("scope"))
+		a.Equal("https://api.example.com", r.PostForm.Get("resource"))
+		id, secret, _ := r.BasicAuth()
+		a.Equal("client", id)
+		a.Equal("secret", secret)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, requests)
+	}))
+	defer ts.Close()
+
+	p := okta.New("client", "secret", ts.URL, "https://example.com/auth/okta/callback", "openid")
+	p.SetResources("https://api.example.com")
+	goth.UseProviders(p, &faux.Provider{})
+
+	token, err := goth.ClientCredentials("okta", "jobs:run")
+	a.NoError(err)
+	a.Equal("token-1", token.AccessToken)
+	a.Empty(token.RefreshToken)
+
+	source := goth.ClientCredentialsTokenSource(token, "okta", "jobs:run")
+	token, err = source.Token()
+	a.NoError(err)
+	a.Equal("token-1", token.AccessToken, "the token has not expired")
+
+	token.Expiry = goth.Now()
+	source = goth.ClientCredentialsTokenSource(token, "okta", "jobs:run")
+	token, err = source.Token()
+	a.NoError(err)
+	a.Equal("token-2", token.AccessToken)
+
+	var notSupported *goth.ClientCredentialsNotSupportedError
+	_, err = goth.ClientCredentials("faux")
+	a.True(errors.As(err, &notSupported))
+	a.Equal("faux", notSupported.Provider)
+
+	_, err = goth.ClientCredentials("unknown")
+	a.Error(err)
+}
@@ -0,0 +1,32 @@
+package goth
+
+import "time"
+
+// Clock is the time source used when computing token expiry and when
+// validating JWT timestamps. Replace it in tests, or on servers whose
+// clock should not be trusted directly.
+var Clock = time.Now
+
+// ClockSkew is the leeway allowed when validating expiry and issued-at
+// timestamps, to tolerate drift between this server and the provider.
+var ClockSkew = 10 * time.Second
+
+// Now returns the current time according to Clock.
+func Now() time.Time {
+	return Clock()
+}
+
+// ExpiresIn returns the expiry time of a token that is valid for the given
+// number of seconds from now.
+func ExpiresIn(seconds int64) time.Time {
+	return Now().Add(time.Duration(seconds) * time.Second)
+}
+
+// Expired reports whether t lies in the past, allowing for ClockSkew.
+// A zero time is never considered expired.
+func Expired(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	return t.Add(ClockSkew).Before(Now())
+}
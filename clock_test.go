@@ -0,0 +1,38 @@
+package goth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Clock(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	a.Equal(now, goth.Now())
+	a.Equal(now.Add(time.Hour), goth.ExpiresIn(3600))
+}
+
+func Test_Expired(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	a.False(goth.Expired(time.Time{}))
+	a.False(goth.Expired(now.Add(time.Minute)))
+	a.False(goth.Expired(now.Add(-5 * time.Second)))
+	a.True(goth.Expired(now.Add(-time.Minute)))
+
+	skew := goth.ClockSkew
+	goth.ClockSkew = 2 * time.Minute
+	defer func() { goth.ClockSkew = skew }()
+	a.False(goth.Expired(now.Add(-time.Minute)))
+}
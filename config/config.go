@@ -0,0 +1,247 @@
+// Package config registers the providers described by a YAML or JSON file, so
+// that deployments can change the providers they offer without recompiling:
+//
+//	providers:
+//	  - type: github
+//	    key: ${GITHUB_KEY}
+//	    secret: ${GITHUB_SECRET}
+//	    callback: https://app.example.com/auth/github/callback
+//	    scopes: [read:user, user:email]
+//	  - type: okta
+//	    name: okta-acme
+//	    key: ${ACME_OKTA_KEY}
+//	    secret: ${ACME_OKTA_SECRET}
+//	    callback: https://app.example.com/auth/okta-acme/callback
+//	    options:
+//	      org_url: https://acme.okta.com
+//
+// Values may reference environment variables, as ${VAR}, to keep secrets out
+// of the file. Any other $ is kept as it is, and $${ stands for a literal ${.
+// The type is the name of the package of the
+// provider; providers needing more than a key, secret and callback URL take
+// the rest from options, and types without a factory can be added with
+// Register.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/bgdsh/goth"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the content of a configuration file.
+type File struct {
+	Providers []Provider `yaml:"providers"`
+}
+
+// Provider describes a provider to register.
+type Provider struct {
+	// Type is the name of the package of the provider, such as "github".
+	Type string `yaml:"type"`
+	// Name, when set, registers the provider under another name than its
+	// default one, see goth.UseProviderAs.
+	Name     string   `yaml:"name"`
+	Key      string   `yaml:"key"`
+	Secret   string   `yaml:"secret"`
+	Callback string   `yaml:"callback"`
+	Scopes   []string `yaml:"scopes"`
+
+	// AuthURL, TokenURL, UserAgent, TokenParams and PKCE are applied with
+	// goth.Configure.
+	AuthURL     string            `yaml:"auth_url"`
+	TokenURL    string            `yaml:"token_url"`
+	UserAgent   string            `yaml:"user_agent"`
+	TokenParams map[string]string `yaml:"token_params"`
+	PKCE        bool              `yaml:"pkce"`
+
+	// Options are the settings specific to the type, such as the org_url of
+	// Okta.
+	Options map[string]string `yaml:"options"`
+}
+
+// Option returns the named option of the provider, or an error when it is
+// not set.
+func (p Provider) Option(name string) (string, error) {
+	value := p.Options[name]
+	if value == "" {
+		return "", fmt.Errorf("config: %s needs the %s option", p.Type, name)
+	}
+	return value, nil
+}
+
+// RequireCredentials returns an error when the key or the secret of the
+// provider is not set, for the factories of providers that cannot do without
+// them.
+func (p Provider) RequireCredentials() error {
+	if p.Key == "" || p.Secret == "" {
+		return fmt.Errorf("config: %s needs a key and a secret", p.Type)
+	}
+	return nil
+}
+
+// Factory creates the provider described by p.
+type Factory func(p Provider) (goth.Provider, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register makes the providers of typ available to the configuration files,
+// replacing the factory of the type if any.
+func Register(typ string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[typ] = factory
+}
+
+// Types returns the types of provider that can be configured, sorted.
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	types := make([]string, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Load reads the configuration file at path.
+func Load(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses a configuration file, in YAML or JSON, and expands the
+// environment variables its values reference.
+func Parse(data []byte) (*File, error) {
+	f := &File{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	for i := range f.Providers {
+		f.Providers[i].expandEnv()
+	}
+	return f, nil
+}
+
+func (p *Provider) expandEnv() {
+	for _, s := range []*string{&p.Name, &p.Key, &p.Secret, &p.Callback, &p.AuthURL, &p.TokenURL, &p.UserAgent} {
+		*s = expandEnv(*s)
+	}
+	for name, value := range p.Options {
+		p.Options[name] = expandEnv(value)
+	}
+	for name, value := range p.TokenParams {
+		p.TokenParams[name] = expandEnv(value)
+	}
+}
+
+// envRef matches the references to environment variables, ${VAR}, and their
+// escaped form, $${VAR}.
+var envRef = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces the ${VAR} references of s with the value of the
+// variables. Unlike os.ExpandEnv, it leaves a bare $ alone, as secrets may
+// contain one.
+func expandEnv(s string) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref[1] == '$' {
+			return ref[1:]
+		}
+		return os.Getenv(ref[2 : len(ref)-1])
+	})
+}
+
+// Build creates the providers of the file. It fails when a type is unknown,
+// when an option is missing or cannot be applied, or when two providers have
+// the same name.
+func (f *File) Build() ([]goth.Provider, error) {
+	providers := make([]goth.Provider, 0, len(f.Providers))
+	names := map[string]bool{}
+	for _, p := range f.Providers {
+		provider, err := p.Build()
+		if err != nil {
+			return nil, err
+		}
+		if names[provider.Name()] {
+			return nil, fmt.Errorf("config: two providers are named %s", provider.Name())
+		}
+		names[provider.Name()] = true
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+// Build creates the provider.
+func (p Provider) Build() (goth.Provider, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[p.Type]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("config: unknown provider type %q", p.Type)
+	}
+	provider, err := factory(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []goth.Option
+	if p.AuthURL != "" {
+		opts = append(opts, goth.WithAuthURL(p.AuthURL))
+	}
+	if p.TokenURL != "" {
+		opts = append(opts, goth.WithTokenURL(p.TokenURL))
+	}
+	if p.UserAgent != "" {
+		opts = append(opts, goth.WithUserAgent(p.UserAgent))
+	}
+	if len(p.TokenParams) > 0 {
+		params := url.Values{}
+		for name, value := range p.TokenParams {
+			params.Set(name, value)
+		}
+		opts = append(opts, goth.WithTokenParams(params))
+	}
+	if p.PKCE {
+		opts = append(opts, goth.WithPKCE())
+	}
+	if len(opts) > 0 {
+		if provider, err = goth.Configure(provider, opts...); err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+	}
+	if p.Name != "" {
+		provider.SetName(p.Name)
+	} else if provider.Name() == "" {
+		provider.SetName(p.Type)
+	}
+	return provider, nil
+}
+
+// Use registers the providers of the configuration file at path with
+// goth.UseProviders, in place of the providers of the same names. Nothing is
+// registered when the file has an error.
+func Use(path string) error {
+	f, err := Load(path)
+	if err != nil {
+		return err
+	}
+	providers, err := f.Build()
+	if err != nil {
+		return err
+	}
+	goth.UseProviders(providers...)
+	return nil
+}
@@ -0,0 +1,156 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/config"
+	"github.com/bgdsh/goth/providers/github"
+	"github.com/bgdsh/goth/providers/okta"
+	"github.com/stretchr/testify/assert"
+)
+
+const file = `
+providers:
+  - type: github
+    key: ${CONFIG_TEST_KEY}
+    secret: ${CONFIG_TEST_SECRET}
+    callback: https://app.example.com/auth/github/callback
+    scopes: [read:user]
+    user_agent: example-app/1.0
+  - type: okta
+    name: okta-acme
+    key: okta-key
+    secret: okta-secret
+    callback: https://app.example.com/auth/okta-acme/callback
+    pkce: true
+    token_params:
+      audience: api://${CONFIG_TEST_KEY}
+    options:
+      org_url: https://acme.okta.com
+`
+
+func Test_Parse(t *testing.T) {
+	a := assert.New(t)
+	os.Setenv("CONFIG_TEST_KEY", "github-key")
+	os.Setenv("CONFIG_TEST_SECRET", "github-secret")
+	defer os.Unsetenv("CONFIG_TEST_KEY")
+	defer os.Unsetenv("CONFIG_TEST_SECRET")
+
+	f, err := config.Parse([]byte(file))
+	a.NoError(err)
+	a.Len(f.Providers, 2)
+	a.Equal("github-key", f.Providers[0].Key)
+	a.Equal("github-secret", f.Providers[0].Secret)
+	a.Equal([]string{"read:user"}, f.Providers[0].Scopes)
+	a.Equal("https://acme.okta.com", f.Providers[1].Options["org_url"])
+	a.Equal("api://github-key", f.Providers[1].TokenParams["audience"])
+
+	providers, err := f.Build()
+	a.NoError(err)
+	a.Len(providers, 2)
+	gh := providers[0].(*github.Provider)
+	a.Equal("github", gh.Name())
+	a.Equal("github-key", gh.ClientKey)
+	a.NotNil(gh.HTTPClient)
+	o := providers[1].(*okta.Provider)
+	a.Equal("okta-acme", o.Name())
+	a.True(o.PKCE)
+
+	f, err = config.Parse([]byte(`{"providers": [{"type": "gitlab", "key": "k", "secret": "s", "callback": "/callback"}]}`))
+	a.NoError(err)
+	providers, err = f.Build()
+	a.NoError(err)
+	a.Equal("gitlab", providers[0].Name())
+}
+
+func Test_ParseDollarSigns(t *testing.T) {
+	a := assert.New(t)
+	os.Setenv("CONFIG_TEST_KEY", "github-key")
+	defer os.Unsetenv("CONFIG_TEST_KEY")
+
+	f, err := config.Parse([]byte(`
+providers:
+  - type: github
+    key: ${CONFIG_TEST_KEY}
+    secret: 'pa$$w0rd$HOME$'
+    callback: https://app.example.com/auth/github/callback?k=$${CONFIG_TEST_KEY}
+`))
+	a.NoError(err)
+	a.Equal("github-key", f.Providers[0].Key)
+	a.Equal("pa$$w0rd$HOME$", f.Providers[0].Secret)
+	a.Equal("https://app.example.com/auth/github/callback?k=${CONFIG_TEST_KEY}", f.Providers[0].Callback)
+}
+
+func Test_BuildErrors(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := config.Provider{Type: "myspace"}.Build()
+	a.EqualError(err, `config: unknown provider type "myspace"`)
+
+	_, err = config.Provider{Type: "okta", Key: "k", Secret: "s"}.Build()
+	a.EqualError(err, "config: okta needs the org_url option")
+
+	// google.New and xero.New exit the program without credentials.
+	_, err = config.Provider{Type: "google", Key: "k"}.Build()
+	a.EqualError(err, "config: google needs a key and a secret")
+	_, err = config.Provider{Type: "xero", Secret: "s"}.Build()
+	a.EqualError(err, "config: xero needs a key and a secret")
+
+	os.Setenv("XERO_METHOD", "private")
+	os.Setenv("XERO_PRIVATE_KEY_PATH", filepath.Join(t.TempDir(), "missing.pem"))
+	defer os.Unsetenv("XERO_METHOD")
+	defer os.Unsetenv("XERO_PRIVATE_KEY_PATH")
+	_, err = config.Provider{Type: "xero", Key: "k", Secret: "s"}.Build()
+	a.Error(err)
+
+	_, err = config.Provider{Type: "twitter", Key: "k", Secret: "s", Scopes: []string{"tweet.read"}}.Build()
+	var notSupported *goth.OptionNotSupportedError
+	a.True(errors.As(err, &notSupported))
+
+	f := &config.File{Providers: []config.Provider{{Type: "github"}, {Type: "github"}}}
+	_, err = f.Build()
+	a.EqualError(err, "config: two providers are named github")
+
+	_, err = config.Parse([]byte("providers: {"))
+	a.Error(err)
+}
+
+func Test_RegisterAndUse(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	a.Contains(config.Types(), "github")
+	a.Contains(config.Types(), "openidConnect")
+
+	config.Register("enterprise", func(p config.Provider) (goth.Provider, error) {
+		return github.NewCustomisedURL(p.Key, p.Secret, p.Callback, p.Options["host"]+"/login/oauth/authorize",
+			p.Options["host"]+"/login/oauth/access_token", p.Options["host"]+"/api/v3/user", p.Options["host"]+"/api/v3/user/emails"), nil
+	})
+	path := filepath.Join(t.TempDir(), "providers.yaml")
+	a.NoError(ioutil.WriteFile(path, []byte(`
+providers:
+  - type: enterprise
+    name: github-enterprise
+    key: k
+    secret: s
+    callback: /auth/github-enterprise/callback
+    options:
+      host: https://github.example.com
+`), 0600))
+
+	a.NoError(config.Use(path))
+	p, err := goth.GetProvider("github-enterprise")
+	a.NoError(err)
+	session, err := p.BeginAuth("state")
+	a.NoError(err)
+	url, err := session.GetAuthURL()
+	a.NoError(err)
+	a.Contains(url, "https://github.example.com/login/oauth/authorize")
+
+	a.Error(config.Use(filepath.Join(t.TempDir(), "missing.yaml")))
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// envNames are the default names of the types whose providers are not named
+// after their package.
+var envNames = map[string]string{
+	"openidConnect": "openid-connect",
+}
+
+// envKeyAliases are the variables of the keys of the types that examples/main.go
+// has been reading under another name.
+var envKeyAliases = map[string]string{
+	"okta": "OKTA_ID",
+}
+
+// EnvPrefix returns the prefix of the environment variables of the providers
+// of typ, such as GITHUB for github and OPENID_CONNECT for openidConnect.
+func EnvPrefix(typ string) string {
+	name := typ
+	if n, ok := envNames[typ]; ok {
+		name = n
+	}
+	return strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// FromEnv returns the providers configured by the environment: a provider of
+// every type whose <PREFIX>_KEY variable is set, see EnvPrefix, with
+//
+//	<PREFIX>_SECRET    the secret
+//	<PREFIX>_SCOPES    the scopes, separated by commas or spaces
+//	<PREFIX>_CALLBACK  the callback URL, <callbackBase>/<name>/callback when
+//	                   not set
+//	<PREFIX>_<OPTION>  the options of the type, such as OKTA_ORG_URL or
+//	                   OPENID_CONNECT_DISCOVERY_URL
+func FromEnv(callbackBase string) *File {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i > 0 && kv[i+1:] != "" {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	f := &File{}
+	for _, typ := range Types() {
+		prefix := EnvPrefix(typ) + "_"
+		key := env[prefix+"KEY"]
+		if key == "" {
+			key = env[envKeyAliases[typ]]
+		}
+		if key == "" {
+			continue
+		}
+
+		name := typ
+		if n, ok := envNames[typ]; ok {
+			name = n
+		}
+		p := Provider{
+			Type:     typ,
+			Key:      key,
+			Secret:   env[prefix+"SECRET"],
+			Callback: env[prefix+"CALLBACK"],
+			Scopes:   strings.FieldsFunc(env[prefix+"SCOPES"], func(r rune) bool { return r == ',' || r == ' ' }),
+		}
+		if p.Callback == "" {
+			p.Callback = strings.TrimSuffix(callbackBase, "/") + "/" + name + "/callback"
+		}
+		if len(p.Scopes) == 0 {
+			p.Scopes = nil
+		}
+		for variable, value := range env {
+			option := strings.TrimPrefix(variable, prefix)
+			switch {
+			case option == variable, option == "KEY", option == "SECRET", option == "CALLBACK", option == "SCOPES":
+				continue
+			}
+			if p.Options == nil {
+				p.Options = map[string]string{}
+			}
+			p.Options[strings.ToLower(option)] = value
+		}
+		f.Providers = append(f.Providers, p)
+	}
+	return f
+}
+
+// AutoRegisterFromEnv registers the providers configured by the environment,
+// see FromEnv, with goth.UseProviders, and returns their names, sorted.
+// Nothing is registered when one of them cannot be created, such as an Okta
+// provider without OKTA_ORG_URL.
+//
+//	names, err := config.AutoRegisterFromEnv("http://localhost:3000/auth")
+func AutoRegisterFromEnv(callbackBase string) ([]string, error) {
+	providers, err := FromEnv(callbackBase).Build()
+	if err != nil {
+		return nil, err
+	}
+	goth.UseProviders(providers...)
+
+	names := make([]string, 0, len(providers))
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
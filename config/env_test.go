@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/config"
+	"github.com/bgdsh/goth/providers/github"
+	"github.com/bgdsh/goth/providers/okta"
+	"github.com/stretchr/testify/assert"
+)
+
+func setenv(vars map[string]string) func() {
+	for k, v := range vars {
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k := range vars {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func Test_EnvPrefix(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("GITHUB", config.EnvPrefix("github"))
+	a.Equal("OPENID_CONNECT", config.EnvPrefix("openidConnect"))
+}
+
+func Test_AutoRegisterFromEnv(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+	defer setenv(map[string]string{
+		"GITHUB_KEY":        "github-key",
+		"GITHUB_SECRET":     "github-secret",
+		"GITHUB_SCOPES":     "read:user, user:email",
+		"BITLY_KEY":         "bitly-key",
+		"BITLY_SECRET":      "bitly-secret",
+		"OKTA_ID":           "okta-key",
+		"OKTA_SECRET":       "okta-secret",
+		"OKTA_ORG_URL":      "https://acme.okta.com",
+		"OKTA_CALLBACK":     "https://app.example.com/okta",
+		"GOOGLE_SECRET":     "no key, no provider",
+		"OPENID_CONNECT_ID": "not the key variable",
+	})()
+
+	names, err := config.AutoRegisterFromEnv("https://app.example.com/auth/")
+	a.NoError(err)
+	a.Equal([]string{"bitly", "github", "okta"}, names)
+
+	p, err := goth.GetProvider("github")
+	a.NoError(err)
+	gh := p.(*github.Provider)
+	a.Equal("github-secret", gh.Secret)
+	a.Equal("https://app.example.com/auth/github/callback", gh.CallbackURL)
+	session, _ := gh.BeginAuth("state")
+	url, _ := session.GetAuthURL()
+	a.Contains(url, "scope=read%3Auser+user%3Aemail")
+
+	p, err = goth.GetProvider("okta")
+	a.NoError(err)
+	a.Equal("https://app.example.com/okta", p.(*okta.Provider).CallbackURL)
+	session, _ = p.BeginAuth("state")
+	url, _ = session.GetAuthURL()
+	a.Contains(url, "https://acme.okta.com/oauth2/default/v1/authorize")
+
+	_, err = goth.GetProvider("google")
+	a.Error(err)
+}
+
+func Test_AutoRegisterFromEnvErrors(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+	defer setenv(map[string]string{
+		"GITHUB_KEY":         "github-key",
+		"OPENID_CONNECT_KEY": "oidc-key",
+	})()
+
+	_, err := config.AutoRegisterFromEnv("/auth")
+	a.EqualError(err, "config: openidConnect needs the discovery_url option")
+	a.Empty(goth.GetProviders())
+}
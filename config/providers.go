@@ -0,0 +1,523 @@
+package config
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/adobe"
+	"github.com/bgdsh/goth/providers/airtable"
+	"github.com/bgdsh/goth/providers/amazon"
+	"github.com/bgdsh/goth/providers/asana"
+	"github.com/bgdsh/goth/providers/auth0"
+	"github.com/bgdsh/goth/providers/azuread"
+	"github.com/bgdsh/goth/providers/azureadv2"
+	"github.com/bgdsh/goth/providers/basecamp"
+	"github.com/bgdsh/goth/providers/battlenet"
+	"github.com/bgdsh/goth/providers/bitbucket"
+	"github.com/bgdsh/goth/providers/bitly"
+	"github.com/bgdsh/goth/providers/box"
+	"github.com/bgdsh/goth/providers/canva"
+	"github.com/bgdsh/goth/providers/chatwork"
+	"github.com/bgdsh/goth/providers/cloudfoundry"
+	"github.com/bgdsh/goth/providers/coinbase"
+	"github.com/bgdsh/goth/providers/dailymotion"
+	"github.com/bgdsh/goth/providers/deezer"
+	"github.com/bgdsh/goth/providers/digitalocean"
+	"github.com/bgdsh/goth/providers/dingtalk"
+	"github.com/bgdsh/goth/providers/discord"
+	"github.com/bgdsh/goth/providers/docusign"
+	"github.com/bgdsh/goth/providers/dropbox"
+	"github.com/bgdsh/goth/providers/etsy"
+	"github.com/bgdsh/goth/providers/eventbrite"
+	"github.com/bgdsh/goth/providers/eveonline"
+	"github.com/bgdsh/goth/providers/facebook"
+	"github.com/bgdsh/goth/providers/fitbit"
+	"github.com/bgdsh/goth/providers/flickr"
+	"github.com/bgdsh/goth/providers/garmin"
+	"github.com/bgdsh/goth/providers/gitea"
+	"github.com/bgdsh/goth/providers/github"
+	"github.com/bgdsh/goth/providers/gitlab"
+	"github.com/bgdsh/goth/providers/google"
+	"github.com/bgdsh/goth/providers/gplus"
+	"github.com/bgdsh/goth/providers/harvest"
+	"github.com/bgdsh/goth/providers/heroku"
+	"github.com/bgdsh/goth/providers/hubspot"
+	"github.com/bgdsh/goth/providers/idme"
+	"github.com/bgdsh/goth/providers/influxcloud"
+	"github.com/bgdsh/goth/providers/instagram"
+	"github.com/bgdsh/goth/providers/instagrambusiness"
+	"github.com/bgdsh/goth/providers/intercom"
+	"github.com/bgdsh/goth/providers/kakao"
+	"github.com/bgdsh/goth/providers/kick"
+	"github.com/bgdsh/goth/providers/lastfm"
+	"github.com/bgdsh/goth/providers/line"
+	"github.com/bgdsh/goth/providers/lineworks"
+	"github.com/bgdsh/goth/providers/linkedin"
+	"github.com/bgdsh/goth/providers/linode"
+	"github.com/bgdsh/goth/providers/mastodon"
+	"github.com/bgdsh/goth/providers/medium"
+	"github.com/bgdsh/goth/providers/meetup"
+	"github.com/bgdsh/goth/providers/microsoftonline"
+	"github.com/bgdsh/goth/providers/miro"
+	"github.com/bgdsh/goth/providers/monday"
+	"github.com/bgdsh/goth/providers/myanimelist"
+	"github.com/bgdsh/goth/providers/naver"
+	"github.com/bgdsh/goth/providers/netlify"
+	"github.com/bgdsh/goth/providers/nextcloud"
+	"github.com/bgdsh/goth/providers/okta"
+	"github.com/bgdsh/goth/providers/onedrive"
+	"github.com/bgdsh/goth/providers/openidConnect"
+	"github.com/bgdsh/goth/providers/orcid"
+	"github.com/bgdsh/goth/providers/osu"
+	"github.com/bgdsh/goth/providers/oura"
+	"github.com/bgdsh/goth/providers/paypal"
+	"github.com/bgdsh/goth/providers/pinterest"
+	"github.com/bgdsh/goth/providers/polar"
+	"github.com/bgdsh/goth/providers/roblox"
+	"github.com/bgdsh/goth/providers/salesforce"
+	"github.com/bgdsh/goth/providers/schwab"
+	"github.com/bgdsh/goth/providers/seatalk"
+	"github.com/bgdsh/goth/providers/shopify"
+	"github.com/bgdsh/goth/providers/slack"
+	"github.com/bgdsh/goth/providers/snapchat"
+	"github.com/bgdsh/goth/providers/soundcloud"
+	"github.com/bgdsh/goth/providers/spotify"
+	"github.com/bgdsh/goth/providers/squarespace"
+	"github.com/bgdsh/goth/providers/strava"
+	"github.com/bgdsh/goth/providers/stripe"
+	"github.com/bgdsh/goth/providers/threads"
+	"github.com/bgdsh/goth/providers/tiktok"
+	"github.com/bgdsh/goth/providers/trakt"
+	"github.com/bgdsh/goth/providers/trello"
+	"github.com/bgdsh/goth/providers/tumblr"
+	"github.com/bgdsh/goth/providers/twitch"
+	"github.com/bgdsh/goth/providers/twitter"
+	"github.com/bgdsh/goth/providers/typetalk"
+	"github.com/bgdsh/goth/providers/uber"
+	"github.com/bgdsh/goth/providers/vk"
+	"github.com/bgdsh/goth/providers/weibo"
+	"github.com/bgdsh/goth/providers/wepay"
+	"github.com/bgdsh/goth/providers/whoop"
+	"github.com/bgdsh/goth/providers/withings"
+	"github.com/bgdsh/goth/providers/xero"
+	"github.com/bgdsh/goth/providers/yahoo"
+	"github.com/bgdsh/goth/providers/yammer"
+	"github.com/bgdsh/goth/providers/yandex"
+	"github.com/bgdsh/goth/providers/zoho"
+	"github.com/bgdsh/goth/providers/zoom"
+)
+
+// init registers the factories of the providers of goth. Those created with a
+// key, secret and callback URL only need no option.
+func init() {
+	for typ, factory := range map[string]Factory{
+		"adobe": func(p Provider) (goth.Provider, error) {
+			return adobe.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"airtable": func(p Provider) (goth.Provider, error) {
+			return airtable.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"amazon": func(p Provider) (goth.Provider, error) {
+			return amazon.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"asana": func(p Provider) (goth.Provider, error) {
+			return asana.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"basecamp": func(p Provider) (goth.Provider, error) {
+			return withScopes(basecamp.New(p.Key, p.Secret, p.Callback), p)
+		},
+		"battlenet": func(p Provider) (goth.Provider, error) {
+			return battlenet.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"bitbucket": func(p Provider) (goth.Provider, error) {
+			return bitbucket.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"bitly": func(p Provider) (goth.Provider, error) {
+			return bitly.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"box": func(p Provider) (goth.Provider, error) { return box.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil },
+		"canva": func(p Provider) (goth.Provider, error) {
+			return canva.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"chatwork": func(p Provider) (goth.Provider, error) {
+			return chatwork.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"coinbase": func(p Provider) (goth.Provider, error) {
+			return coinbase.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"dailymotion": func(p Provider) (goth.Provider, error) {
+			return dailymotion.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"deezer": func(p Provider) (goth.Provider, error) {
+			return deezer.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"digitalocean": func(p Provider) (goth.Provider, error) {
+			return digitalocean.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"dingtalk": func(p Provider) (goth.Provider, error) {
+			return dingtalk.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"discord": func(p Provider) (goth.Provider, error) {
+			return discord.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"docusign": func(p Provider) (goth.Provider, error) {
+			return docusign.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"dropbox": func(p Provider) (goth.Provider, error) {
+			return dropbox.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"etsy": func(p Provider) (goth.Provider, error) {
+			return etsy.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"eventbrite": func(p Provider) (goth.Provider, error) {
+			return withScopes(eventbrite.New(p.Key, p.Secret, p.Callback), p)
+		},
+		"eveonline": func(p Provider) (goth.Provider, error) {
+			return eveonline.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"facebook": func(p Provider) (goth.Provider, error) {
+			return facebook.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"fitbit": func(p Provider) (goth.Provider, error) {
+			return fitbit.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"flickr": func(p Provider) (goth.Provider, error) { return withScopes(flickr.New(p.Key, p.Secret, p.Callback), p) },
+		"garmin": func(p Provider) (goth.Provider, error) { return withScopes(garmin.New(p.Key, p.Secret, p.Callback), p) },
+		"gitea": func(p Provider) (goth.Provider, error) {
+			return gitea.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"gplus": func(p Provider) (goth.Provider, error) {
+			return gplus.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"harvest": func(p Provider) (goth.Provider, error) {
+			return harvest.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"heroku": func(p Provider) (goth.Provider, error) {
+			return heroku.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"hubspot": func(p Provider) (goth.Provider, error) {
+			return hubspot.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"idme": func(p Provider) (goth.Provider, error) {
+			return idme.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"influxcloud": func(p Provider) (goth.Provider, error) {
+			return influxcloud.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"instagram": func(p Provider) (goth.Provider, error) {
+			return instagram.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"instagrambusiness": func(p Provider) (goth.Provider, error) {
+			return instagrambusiness.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"intercom": func(p Provider) (goth.Provider, error) {
+			return intercom.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"kakao": func(p Provider) (goth.Provider, error) {
+			return kakao.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"kick": func(p Provider) (goth.Provider, error) {
+			return kick.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"lastfm": func(p Provider) (goth.Provider, error) { return withScopes(lastfm.New(p.Key, p.Secret, p.Callback), p) },
+		"line": func(p Provider) (goth.Provider, error) {
+			return line.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"lineworks": func(p Provider) (goth.Provider, error) {
+			return lineworks.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"linkedin": func(p Provider) (goth.Provider, error) {
+			return linkedin.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"linode": func(p Provider) (goth.Provider, error) {
+			return linode.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"medium": func(p Provider) (goth.Provider, error) {
+			return medium.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"meetup": func(p Provider) (goth.Provider, error) {
+			return meetup.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"microsoftonline": func(p Provider) (goth.Provider, error) {
+			return microsoftonline.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"miro": func(p Provider) (goth.Provider, error) {
+			return miro.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"monday": func(p Provider) (goth.Provider, error) {
+			return monday.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"myanimelist": func(p Provider) (goth.Provider, error) {
+			return withScopes(myanimelist.New(p.Key, p.Secret, p.Callback), p)
+		},
+		"naver": func(p Provider) (goth.Provider, error) { return withScopes(naver.New(p.Key, p.Secret, p.Callback), p) },
+		"netlify": func(p Provider) (goth.Provider, error) {
+			return withScopes(netlify.New(p.Key, p.Secret, p.Callback), p)
+		},
+		"nextcloud": func(p Provider) (goth.Provider, error) {
+			return nextcloud.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"onedrive": func(p Provider) (goth.Provider, error) {
+			return onedrive.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"orcid": func(p Provider) (goth.Provider, error) {
+			return orcid.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"osu": func(p Provider) (goth.Provider, error) { return osu.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil },
+		"oura": func(p Provider) (goth.Provider, error) {
+			return oura.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"paypal": func(p Provider) (goth.Provider, error) {
+			return paypal.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"pinterest": func(p Provider) (goth.Provider, error) {
+			return pinterest.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"polar": func(p Provider) (goth.Provider, error) {
+			return polar.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"roblox": func(p Provider) (goth.Provider, error) {
+			return roblox.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"salesforce": func(p Provider) (goth.Provider, error) {
+			return salesforce.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"schwab": func(p Provider) (goth.Provider, error) { return withScopes(schwab.New(p.Key, p.Secret, p.Callback), p) },
+		"seatalk": func(p Provider) (goth.Provider, error) {
+			return seatalk.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"shopify": func(p Provider) (goth.Provider, error) {
+			return shopify.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"slack": func(p Provider) (goth.Provider, error) {
+			return slack.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"snapchat": func(p Provider) (goth.Provider, error) {
+			return snapchat.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"soundcloud": func(p Provider) (goth.Provider, error) {
+			return soundcloud.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"spotify": func(p Provider) (goth.Provider, error) {
+			return spotify.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"squarespace": func(p Provider) (goth.Provider, error) {
+			return squarespace.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"strava": func(p Provider) (goth.Provider, error) {
+			return strava.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"stripe": func(p Provider) (goth.Provider, error) {
+			return stripe.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"threads": func(p Provider) (goth.Provider, error) {
+			return threads.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"tiktok": func(p Provider) (goth.Provider, error) {
+			return tiktok.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"trakt": func(p Provider) (goth.Provider, error) { return withScopes(trakt.New(p.Key, p.Secret, p.Callback), p) },
+		"trello": func(p Provider) (goth.Provider, error) {
+			return trello.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"tumblr": func(p Provider) (goth.Provider, error) { return withScopes(tumblr.New(p.Key, p.Secret, p.Callback), p) },
+		"twitch": func(p Provider) (goth.Provider, error) {
+			return twitch.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"twitter": func(p Provider) (goth.Provider, error) {
+			return withScopes(twitter.New(p.Key, p.Secret, p.Callback), p)
+		},
+		"typetalk": func(p Provider) (goth.Provider, error) {
+			return typetalk.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"uber": func(p Provider) (goth.Provider, error) {
+			return uber.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"vk": func(p Provider) (goth.Provider, error) { return vk.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil },
+		"weibo": func(p Provider) (goth.Provider, error) {
+			return weibo.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"wepay": func(p Provider) (goth.Provider, error) {
+			return wepay.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"whoop": func(p Provider) (goth.Provider, error) {
+			return whoop.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"withings": func(p Provider) (goth.Provider, error) {
+			return withings.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"yahoo": func(p Provider) (goth.Provider, error) {
+			return yahoo.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"yammer": func(p Provider) (goth.Provider, error) {
+			return yammer.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"yandex": func(p Provider) (goth.Provider, error) {
+			return yandex.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"zoho": func(p Provider) (goth.Provider, error) {
+			return zoho.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+		"zoom": func(p Provider) (goth.Provider, error) {
+			return zoom.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+		},
+
+		"auth0":         newAuth0,
+		"azuread":       newAzureAD,
+		"azureadv2":     newAzureADv2,
+		"cloudfoundry":  newCloudFoundry,
+		"github":        newGitHub,
+		"gitlab":        newGitLab,
+		"google":        newGoogle,
+		"mastodon":      newMastodon,
+		"okta":          newOkta,
+		"openidConnect": newOpenIDConnect,
+		"xero":          newXero,
+	} {
+		Register(typ, factory)
+	}
+}
+
+// withScopes applies the scopes of p to the providers whose constructor takes
+// none.
+func withScopes(provider goth.Provider, p Provider) (goth.Provider, error) {
+	if p.Scopes == nil {
+		return provider, nil
+	}
+	return goth.Configure(provider, goth.WithScopes(p.Scopes...))
+}
+
+func newAuth0(p Provider) (goth.Provider, error) {
+	domain, err := p.Option("domain")
+	if err != nil {
+		return nil, err
+	}
+	return auth0.New(p.Key, p.Secret, p.Callback, domain, p.Scopes...), nil
+}
+
+// newAzureAD reads the resources as a comma separated list.
+func newAzureAD(p Provider) (goth.Provider, error) {
+	var resources []string
+	if r := p.Options["resources"]; r != "" {
+		resources = strings.Split(r, ",")
+	}
+	return azuread.New(p.Key, p.Secret, p.Callback, resources, p.Scopes...), nil
+}
+
+func newAzureADv2(p Provider) (goth.Provider, error) {
+	opts := azureadv2.ProviderOptions{Tenant: azureadv2.TenantType(p.Options["tenant"])}
+	for _, scope := range p.Scopes {
+		opts.Scopes = append(opts.Scopes, azureadv2.ScopeType(scope))
+	}
+	return azureadv2.New(p.Key, p.Secret, p.Callback, opts), nil
+}
+
+func newCloudFoundry(p Provider) (goth.Provider, error) {
+	uaaURL, err := p.Option("uaa_url")
+	if err != nil {
+		return nil, err
+	}
+	return cloudfoundry.New(uaaURL, p.Key, p.Secret, p.Callback, p.Scopes...), nil
+}
+
+// newGitHub creates a GitHub Enterprise provider when the auth_url option is
+// set.
+func newGitHub(p Provider) (goth.Provider, error) {
+	if p.Options["auth_url"] == "" {
+		return github.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+	}
+	var urls [4]string
+	for i, name := range []string{"auth_url", "token_url", "profile_url", "email_url"} {
+		var err error
+		if urls[i], err = p.Option(name); err != nil {
+			return nil, err
+		}
+	}
+	return github.NewCustomisedURL(p.Key, p.Secret, p.Callback, urls[0], urls[1], urls[2], urls[3], p.Scopes...), nil
+}
+
+// newGitLab creates a self-hosted GitLab provider when the auth_url option is
+// set.
+func newGitLab(p Provider) (goth.Provider, error) {
+	if p.Options["auth_url"] == "" {
+		return gitlab.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+	}
+	var urls [3]string
+	for i, name := range []string{"auth_url", "token_url", "profile_url"} {
+		var err error
+		if urls[i], err = p.Option(name); err != nil {
+			return nil, err
+		}
+	}
+	return gitlab.NewCustomisedURL(p.Key, p.Secret, p.Callback, urls[0], urls[1], urls[2], p.Scopes...), nil
+}
+
+// newMastodon creates the provider of the instance_url option, mastodon.social
+// when it is not set.
+func newMastodon(p Provider) (goth.Provider, error) {
+	if instanceURL := p.Options["instance_url"]; instanceURL != "" {
+		return mastodon.NewCustomisedURL(p.Key, p.Secret, p.Callback, instanceURL, p.Scopes...), nil
+	}
+	return mastodon.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+}
+
+// newGoogle checks the credentials first, google.New exits the program
+// without them.
+func newGoogle(p Provider) (goth.Provider, error) {
+	if err := p.RequireCredentials(); err != nil {
+		return nil, err
+	}
+	return google.New(p.Key, p.Secret, p.Callback, p.Scopes...), nil
+}
+
+func newOkta(p Provider) (goth.Provider, error) {
+	orgURL, err := p.Option("org_url")
+	if err != nil {
+		return nil, err
+	}
+	return okta.New(p.Key, p.Secret, orgURL, p.Callback, p.Scopes...), nil
+}
+
+// newOpenIDConnect fetches the discovery document of the discovery_url
+// option, at once unless the lazy_discovery option is "true", see
+// openidConnect.NewLazy.
+func newOpenIDConnect(p Provider) (goth.Provider, error) {
+	discoveryURL, err := p.Option("discovery_url")
+	if err != nil {
+		return nil, err
+	}
+	if p.Options["lazy_discovery"] == "true" {
+		return openidConnect.NewLazy(p.Key, p.Secret, p.Callback, discoveryURL, p.Scopes...), nil
+	}
+	return openidConnect.New(p.Key, p.Secret, p.Callback, discoveryURL, p.Scopes...)
+}
+
+// newXero checks the credentials first, and the private key of the private
+// and partner applications, xero.New exits the program when it cannot read
+// it.
+func newXero(p Provider) (goth.Provider, error) {
+	if err := p.RequireCredentials(); err != nil {
+		return nil, err
+	}
+	switch os.Getenv("XERO_METHOD") {
+	case "private", "partner":
+		data, err := ioutil.ReadFile(os.Getenv("XERO_PRIVATE_KEY_PATH"))
+		if err != nil {
+			return nil, fmt.Errorf("config: xero private key: %w", err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("config: xero private key is not PEM encoded")
+		}
+		if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("config: xero private key: %w", err)
+		}
+	}
+	return withScopes(xero.New(p.Key, p.Secret, p.Callback), p)
+}
@@ -0,0 +1,63 @@
+package goth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrConsentRequired is matched, with errors.Is, by the errors providers
+// return when the user, or an administrator of their organisation, has not
+// consented to the scopes the application needs.
+var ErrConsentRequired = errors.New("consent required")
+
+// ConsentRequiredError describes the consent missing for a login to be of use
+// to the application. It matches ErrConsentRequired.
+type ConsentRequiredError struct {
+	// Provider is the name of the provider.
+	Provider string
+	// Scopes are the scopes missing consent, when they are known.
+	Scopes []string
+	// AdminConsent is true when only an administrator can grant the consent,
+	// for instance through the admin consent end-point of Microsoft.
+	AdminConsent bool
+	// Description is the reason given by the provider.
+	Description string
+}
+
+func (e *ConsentRequiredError) Error() string {
+	msg := e.Provider + ": consent required"
+	if e.AdminConsent {
+		msg = e.Provider + ": admin consent required"
+	}
+	if len(e.Scopes) > 0 {
+		msg += fmt.Sprintf(" for %s", strings.Join(e.Scopes, " "))
+	}
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	return msg
+}
+
+// Is reports whether target is ErrConsentRequired.
+func (e *ConsentRequiredError) Is(target error) bool {
+	return target == ErrConsentRequired
+}
+
+// MissingScopes returns the scopes of required which are not in granted.
+func MissingScopes(granted []string, required ...string) []string {
+	var missing []string
+	for _, scope := range required {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,29 @@
+package goth_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ConsentRequiredError(t *testing.T) {
+	a := assert.New(t)
+
+	var err error = &goth.ConsentRequiredError{Provider: "google", Scopes: []string{"a", "b"}}
+	a.EqualError(err, "google: consent required for a b")
+	a.True(errors.Is(fmt.Errorf("login: %w", err), goth.ErrConsentRequired))
+
+	err = &goth.ConsentRequiredError{Provider: "azureadv2", AdminConsent: true, Description: "AADSTS90094"}
+	a.EqualError(err, "azureadv2: admin consent required: AADSTS90094")
+	a.False(errors.Is(errors.New("consent required"), goth.ErrConsentRequired))
+}
+
+func Test_MissingScopes(t *testing.T) {
+	a := assert.New(t)
+
+	a.Nil(goth.MissingScopes([]string{"a", "b"}, "b", "a"))
+	a.Equal([]string{"c"}, goth.MissingScopes([]string{"a", "b"}, "a", "c"))
+}
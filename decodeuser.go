@@ -0,0 +1,42 @@
+package goth
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DecodeUser decodes the raw data of user, the profile or claims the provider
+// returned, into the value v points to, for the applications with typed user
+// models:
+//
+//	var profile struct {
+//		Sub          string `json:"id"`
+//		HostedDomain string `json:"hd"`
+//	}
+//	err := goth.DecodeUser(user, &profile)
+//
+// It fails for the users without raw data, as not every provider keeps it.
+func DecodeUser(user User, v interface{}) error {
+	if len(user.RawData) == 0 {
+		return fmt.Errorf("%s: the user has no raw data", user.Provider)
+	}
+	data, err := json.Marshal(user.RawData)
+	if err != nil {
+		return fmt.Errorf("%s: %w", user.Provider, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", user.Provider, err)
+	}
+	return nil
+}
+
+// FetchUserInto fetches the user of session, see Provider.FetchUser, and
+// decodes its raw data into the value v points to, see DecodeUser. It returns
+// the user as well, with its tokens.
+func FetchUserInto(provider Provider, session Session, v interface{}) (User, error) {
+	user, err := provider.FetchUser(session)
+	if err != nil {
+		return user, err
+	}
+	return user, DecodeUser(user, v)
+}
@@ -0,0 +1,56 @@
+package goth_test
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+type claimsProvider struct {
+	faux.Provider
+}
+
+func (p *claimsProvider) FetchUser(session goth.Session) (goth.User, error) {
+	user, err := p.Provider.FetchUser(session)
+	user.RawData = map[string]interface{}{
+		"sub":            user.UserID,
+		"email_verified": true,
+		"groups":         []interface{}{"admins", "staff"},
+		"address":        map[string]interface{}{"country": "US"},
+	}
+	return user, err
+}
+
+type typedClaims struct {
+	Subject       string   `json:"sub"`
+	EmailVerified bool     `json:"email_verified"`
+	Groups        []string `json:"groups"`
+	Address       struct {
+		Country string `json:"country"`
+	} `json:"address"`
+}
+
+func Test_FetchUserInto(t *testing.T) {
+	a := assert.New(t)
+	p := &claimsProvider{}
+
+	var claims typedClaims
+	user, err := goth.FetchUserInto(p, &faux.Session{ID: "42", AccessToken: "access"}, &claims)
+	a.NoError(err)
+	a.Equal("42", claims.Subject)
+	a.True(claims.EmailVerified)
+	a.Equal([]string{"admins", "staff"}, claims.Groups)
+	a.Equal("US", claims.Address.Country)
+	a.Equal("access", user.AccessToken)
+
+	_, err = goth.FetchUserInto(p, &faux.Session{ID: "42"}, &claims)
+	a.Contains(err.Error(), "cannot get user information without accessToken")
+
+	var wrong struct {
+		Subject int `json:"sub"`
+	}
+	a.Error(goth.DecodeUser(user, &wrong))
+	a.EqualError(goth.DecodeUser(goth.User{Provider: "faux"}, &claims), "faux: the user has no raw data")
+}
@@ -0,0 +1,154 @@
+package goth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUnknownEmailDomain is matched, with errors.Is, by the errors of
+// EmailDomains for emails no provider is known for.
+var ErrUnknownEmailDomain = errors.New("no provider for the email domain")
+
+// UnknownEmailDomainError is returned by EmailDomains.Provider when no provider
+// is known for the domain of an email. It matches ErrUnknownEmailDomain.
+type UnknownEmailDomainError struct {
+	Domain string
+}
+
+func (e *UnknownEmailDomainError) Error() string {
+	return fmt.Sprintf("no provider for the email domain %s", e.Domain)
+}
+
+// Is reports whether target is ErrUnknownEmailDomain.
+func (e *UnknownEmailDomainError) Is(target error) bool {
+	return target == ErrUnknownEmailDomain
+}
+
+// EmailDomains picks the provider users log in with from their email, for
+// login pages asking for a work email rather than showing a button per
+// provider:
+//
+//	domains := &goth.EmailDomains{
+//		Domains: map[string]string{"corp.com": "okta"},
+//		Default: "google",
+//	}
+type EmailDomains struct {
+	// Domains maps email domains to the name of their provider. A domain
+	// also matches its subdomains, so corp.com matches eu.corp.com.
+	Domains map[string]string
+	// Discover, when set, is asked about the emails whose domain is not in
+	// Domains, see WebFingerDiscovery. It returns an empty name when it
+	// does not know the domain either.
+	Discover func(email string) (string, error)
+	// Default is the provider of the emails of every other domain. When
+	// empty, they get an *UnknownEmailDomainError.
+	Default string
+}
+
+// Provider returns the name of the provider of email.
+func (d *EmailDomains) Provider(email string) (string, error) {
+	domain, err := EmailDomain(email)
+	if err != nil {
+		return "", err
+	}
+	for name := domain; name != ""; {
+		if provider, ok := d.Domains[name]; ok {
+			return provider, nil
+		}
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+
+	if d.Discover != nil {
+		provider, err := d.Discover(email)
+		if err != nil {
+			return "", err
+		}
+		if provider != "" {
+			return provider, nil
+		}
+	}
+	if d.Default != "" {
+		return d.Default, nil
+	}
+	return "", &UnknownEmailDomainError{Domain: domain}
+}
+
+// EmailDomain returns the domain of email, in lower case.
+func EmailDomain(email string) (string, error) {
+	i := strings.LastIndexByte(email, '@')
+	if i <= 0 || i == len(email)-1 || strings.ContainsAny(email, " \t\r\n/?#") {
+		return "", fmt.Errorf("%q is not an email address", email)
+	}
+	return strings.ToLower(email[i+1:]), nil
+}
+
+// WebFingerIssuerRel is the WebFinger relation of the OpenID Connect issuer of
+// an account.
+const WebFingerIssuerRel = "http://openid.net/specs/connect/1.0/issuer"
+
+// WebFingerIssuer asks the domain of email, with WebFinger, for the OpenID
+// Connect issuer of the account. It returns an empty issuer when the domain
+// does not tell.
+// See https://openid.net/specs/openid-connect-discovery-1_0.html#IssuerDiscovery
+func WebFingerIssuer(client *http.Client, email string) (string, error) {
+	domain, err := EmailDomain(email)
+	if err != nil {
+		return "", err
+	}
+	v := url.Values{"resource": {"acct:" + email}, "rel": {WebFingerIssuerRel}}
+	resp, err := HTTPClientWithFallBack(client).Get("https://" + domain + "/.well-known/webfinger?" + v.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s responded with a %d to WebFinger", domain, resp.StatusCode)
+	}
+
+	var jrd struct {
+		Links []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jrd); err != nil {
+		return "", fmt.Errorf("%s: WebFinger: %w", domain, err)
+	}
+	for _, link := range jrd.Links {
+		if link.Rel == WebFingerIssuerRel {
+			return link.Href, nil
+		}
+	}
+	return "", nil
+}
+
+// WebFingerDiscovery returns an EmailDomains.Discover function asking the
+// domain of an email for its OpenID Connect issuer, and returning the name of
+// the provider issuers maps it to. Domains that do not answer, or answer with
+// an issuer missing from issuers, are left to EmailDomains.Default.
+func WebFingerDiscovery(client *http.Client, issuers map[string]string) func(email string) (string, error) {
+	return func(email string) (string, error) {
+		issuer, err := WebFingerIssuer(client, email)
+		if err != nil || issuer == "" {
+			return "", nil
+		}
+		for iss, provider := range issuers {
+			if strings.TrimSuffix(iss, "/") == strings.TrimSuffix(issuer, "/") {
+				return provider, nil
+			}
+		}
+		return "", nil
+	}
+}
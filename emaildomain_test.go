@@ -0,0 +1,85 @@
+package goth_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EmailDomains(t *testing.T) {
+	a := assert.New(t)
+
+	d := &goth.EmailDomains{
+		Domains: map[string]string{"corp.com": "okta"},
+		Discover: func(email string) (string, error) {
+			if email == "bart@acme.com" {
+				return "openid-connect", nil
+			}
+			return "", nil
+		},
+	}
+
+	for email, provider := range map[string]string{
+		"homer@corp.com":    "okta",
+		"homer@EU.Corp.com": "okta",
+		"bart@acme.com":     "openid-connect",
+	} {
+		got, err := d.Provider(email)
+		a.NoError(err)
+		a.Equal(provider, got, email)
+	}
+
+	_, err := d.Provider("lisa@example.com")
+	a.True(errors.Is(err, goth.ErrUnknownEmailDomain))
+	var unknown *goth.UnknownEmailDomainError
+	a.True(errors.As(err, &unknown))
+	a.Equal("example.com", unknown.Domain)
+
+	_, err = d.Provider("lisa")
+	a.EqualError(err, `"lisa" is not an email address`)
+
+	d.Default = "google"
+	got, err := d.Provider("lisa@example.com")
+	a.NoError(err)
+	a.Equal("google", got)
+}
+
+func Test_WebFingerDiscovery(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/.well-known/webfinger", r.URL.Path)
+		a.Equal(goth.WebFingerIssuerRel, r.URL.Query().Get("rel"))
+		if r.URL.Query().Get("resource") != "acct:homer@"+r.Host {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jrd+json")
+		fmt.Fprintf(w, `{"subject":"acct:homer@%s","links":[{"rel":%q,"href":"https://login.corp.com/"}]}`, r.Host, goth.WebFingerIssuerRel)
+	}))
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+
+	issuer, err := goth.WebFingerIssuer(ts.Client(), "homer@"+u.Host)
+	a.NoError(err)
+	a.Equal("https://login.corp.com/", issuer)
+
+	issuer, err = goth.WebFingerIssuer(ts.Client(), "bart@"+u.Host)
+	a.NoError(err)
+	a.Empty(issuer)
+
+	discover := goth.WebFingerDiscovery(ts.Client(), map[string]string{"https://login.corp.com": "okta"})
+	provider, err := discover("homer@" + u.Host)
+	a.NoError(err)
+	a.Equal("okta", provider)
+
+	provider, err = discover("bart@" + u.Host)
+	a.NoError(err)
+	a.Empty(provider)
+}
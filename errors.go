@@ -0,0 +1,110 @@
+package goth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrProviderNotFound is matched, with errors.Is, by the errors returned for
+// providers goth was not told to use.
+var ErrProviderNotFound = errors.New("provider not found")
+
+// ProviderNotFoundError is returned by GetProvider for providers goth was not
+// told to use. It matches ErrProviderNotFound.
+type ProviderNotFoundError struct {
+	// Name is the name of the provider, empty when the request named none.
+	Name string
+}
+
+func (e *ProviderNotFoundError) Error() string {
+	if e.Name == "" {
+		return "you must select a provider"
+	}
+	return fmt.Sprintf("no provider for %s exists", e.Name)
+}
+
+// Is reports whether target is ErrProviderNotFound.
+func (e *ProviderNotFoundError) Is(target error) bool {
+	return target == ErrProviderNotFound
+}
+
+// ErrProviderNameTaken is matched, with errors.Is, by the errors returned by
+// UseProviderAs for names already in use.
+var ErrProviderNameTaken = errors.New("provider name taken")
+
+// ProviderNameTakenError is returned by UseProviderAs when another provider is
+// registered under Name, or, when RegisteredAs is set, the provider is
+// already registered under RegisteredAs. It matches ErrProviderNameTaken.
+type ProviderNameTakenError struct {
+	Name         string
+	RegisteredAs string
+}
+
+func (e *ProviderNameTakenError) Error() string {
+	if e.RegisteredAs != "" {
+		return fmt.Sprintf("cannot register the provider as %s, it is registered as %s", e.Name, e.RegisteredAs)
+	}
+	return fmt.Sprintf("a provider is already registered as %s", e.Name)
+}
+
+// Is reports whether target is ErrProviderNameTaken.
+func (e *ProviderNameTakenError) Is(target error) bool {
+	return target == ErrProviderNameTaken
+}
+
+// ErrTokenExpired is returned by ValidateClaims, and so by the providers
+// validating JWTs, for expired tokens.
+var ErrTokenExpired = errors.New("token is expired")
+
+// ErrProviderAPI is matched, with errors.Is, by the errors returned when a
+// provider answers a call with an unexpected status.
+var ErrProviderAPI = errors.New("provider API error")
+
+// maxErrorBody is how much of the body of a failed call ProviderAPIError
+// keeps.
+const maxErrorBody = 4 << 10
+
+// ProviderAPIError is returned when a provider answers a call with an
+// unexpected status. It matches ErrProviderAPI.
+type ProviderAPIError struct {
+	// Provider is the name of the provider, or of the end-point called.
+	Provider string
+	// Action describes the call, such as "trying to fetch user information".
+	Action string
+	// Status is the HTTP status of the response.
+	Status int
+	// Body is the start of the body of the response.
+	Body string
+}
+
+// NewProviderAPIError returns the error of provider answering the call
+// described by action with resp, keeping the start of its body.
+func NewProviderAPIError(provider, action string, resp *http.Response) *ProviderAPIError {
+	e := &ProviderAPIError{Provider: provider, Action: action, Status: resp.StatusCode}
+	if resp.Body != nil {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		e.Body = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// Error describes the call with Action or, without one, with Body.
+func (e *ProviderAPIError) Error() string {
+	msg := fmt.Sprintf("%s responded with a %d", e.Provider, e.Status)
+	if e.Action != "" {
+		return msg + " " + e.Action
+	}
+	if e.Body != "" {
+		return msg + ": " + e.Body
+	}
+	return msg
+}
+
+// Is reports whether target is ErrProviderAPI.
+func (e *ProviderAPIError) Is(target error) bool {
+	return target == ErrProviderAPI
+}
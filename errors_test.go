@@ -0,0 +1,47 @@
+package goth_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProviderNotFoundError(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := goth.GetProvider("unknown")
+	a.True(errors.Is(err, goth.ErrProviderNotFound))
+	var notFound *goth.ProviderNotFoundError
+	a.True(errors.As(err, &notFound))
+	a.Equal("unknown", notFound.Name)
+
+	a.EqualError(&goth.ProviderNotFoundError{}, "you must select a provider")
+}
+
+func Test_ProviderAPIError(t *testing.T) {
+	a := assert.New(t)
+
+	resp := &http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Body:       ioutil.NopCloser(strings.NewReader("{\"error\":\"invalid_token\"}\n")),
+	}
+	err := goth.NewProviderAPIError("faux", "trying to fetch user information", resp)
+	a.EqualError(err, "faux responded with a 401 trying to fetch user information")
+	a.Equal(http.StatusUnauthorized, err.Status)
+	a.Equal(`{"error":"invalid_token"}`, err.Body)
+
+	wrapped := fmt.Errorf("login failed: %w", err)
+	a.True(errors.Is(wrapped, goth.ErrProviderAPI))
+	var apiErr *goth.ProviderAPIError
+	a.True(errors.As(wrapped, &apiErr))
+	a.Equal("faux", apiErr.Provider)
+
+	a.EqualError(&goth.ProviderAPIError{Provider: "token revocation", Status: 400, Body: "bad"}, "token revocation responded with a 400: bad")
+	a.EqualError(&goth.ProviderAPIError{Provider: "faux", Status: 500}, "faux responded with a 500")
+}
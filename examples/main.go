@@ -18,63 +18,120 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/adobe"
+	"github.com/bgdsh/goth/providers/airtable"
+	"github.com/bgdsh/goth/providers/alipay"
 	"github.com/bgdsh/goth/providers/amazon"
+	"github.com/bgdsh/goth/providers/amazonseller"
 	"github.com/bgdsh/goth/providers/apple"
+	"github.com/bgdsh/goth/providers/asana"
 	"github.com/bgdsh/goth/providers/auth0"
 	"github.com/bgdsh/goth/providers/azuread"
+	"github.com/bgdsh/goth/providers/basecamp"
 	"github.com/bgdsh/goth/providers/battlenet"
+	"github.com/bgdsh/goth/providers/bigcommerce"
 	"github.com/bgdsh/goth/providers/bitbucket"
+	"github.com/bgdsh/goth/providers/bluesky"
 	"github.com/bgdsh/goth/providers/box"
+	"github.com/bgdsh/goth/providers/canva"
+	"github.com/bgdsh/goth/providers/chatwork"
+	"github.com/bgdsh/goth/providers/coinbase"
 	"github.com/bgdsh/goth/providers/dailymotion"
 	"github.com/bgdsh/goth/providers/deezer"
 	"github.com/bgdsh/goth/providers/digitalocean"
+	"github.com/bgdsh/goth/providers/dingtalk"
 	"github.com/bgdsh/goth/providers/discord"
+	"github.com/bgdsh/goth/providers/docusign"
 	"github.com/bgdsh/goth/providers/dropbox"
+	"github.com/bgdsh/goth/providers/ebay"
+	"github.com/bgdsh/goth/providers/etsy"
+	"github.com/bgdsh/goth/providers/eventbrite"
 	"github.com/bgdsh/goth/providers/eveonline"
 	"github.com/bgdsh/goth/providers/facebook"
 	"github.com/bgdsh/goth/providers/fitbit"
+	"github.com/bgdsh/goth/providers/flickr"
+	"github.com/bgdsh/goth/providers/garmin"
 	"github.com/bgdsh/goth/providers/gitea"
 	"github.com/bgdsh/goth/providers/github"
 	"github.com/bgdsh/goth/providers/gitlab"
 	"github.com/bgdsh/goth/providers/google"
 	"github.com/bgdsh/goth/providers/gplus"
+	"github.com/bgdsh/goth/providers/harvest"
 	"github.com/bgdsh/goth/providers/heroku"
+	"github.com/bgdsh/goth/providers/hubspot"
+	"github.com/bgdsh/goth/providers/idme"
 	"github.com/bgdsh/goth/providers/instagram"
+	"github.com/bgdsh/goth/providers/instagrambusiness"
 	"github.com/bgdsh/goth/providers/intercom"
+	"github.com/bgdsh/goth/providers/itchio"
 	"github.com/bgdsh/goth/providers/kakao"
+	"github.com/bgdsh/goth/providers/kick"
+	"github.com/bgdsh/goth/providers/larksuite"
 	"github.com/bgdsh/goth/providers/lastfm"
 	"github.com/bgdsh/goth/providers/line"
+	"github.com/bgdsh/goth/providers/lineworks"
 	"github.com/bgdsh/goth/providers/linkedin"
+	"github.com/bgdsh/goth/providers/linode"
 	"github.com/bgdsh/goth/providers/mastodon"
+	"github.com/bgdsh/goth/providers/matrix"
+	"github.com/bgdsh/goth/providers/medium"
 	"github.com/bgdsh/goth/providers/meetup"
 	"github.com/bgdsh/goth/providers/microsoftonline"
+	"github.com/bgdsh/goth/providers/miro"
+	"github.com/bgdsh/goth/providers/monday"
+	"github.com/bgdsh/goth/providers/myanimelist"
 	"github.com/bgdsh/goth/providers/naver"
+	"github.com/bgdsh/goth/providers/netlify"
 	"github.com/bgdsh/goth/providers/nextcloud"
 	"github.com/bgdsh/goth/providers/okta"
 	"github.com/bgdsh/goth/providers/onedrive"
 	"github.com/bgdsh/goth/providers/openidConnect"
+	"github.com/bgdsh/goth/providers/orcid"
+	"github.com/bgdsh/goth/providers/osu"
 	"github.com/bgdsh/goth/providers/paypal"
+	"github.com/bgdsh/goth/providers/pinterest"
+	"github.com/bgdsh/goth/providers/plaid"
+	"github.com/bgdsh/goth/providers/plex"
+	"github.com/bgdsh/goth/providers/polar"
+	"github.com/bgdsh/goth/providers/qq"
+	"github.com/bgdsh/goth/providers/questrade"
+	"github.com/bgdsh/goth/providers/roblox"
 	"github.com/bgdsh/goth/providers/salesforce"
+	"github.com/bgdsh/goth/providers/schwab"
 	"github.com/bgdsh/goth/providers/seatalk"
+	"github.com/bgdsh/goth/providers/servicenow"
 	"github.com/bgdsh/goth/providers/shopify"
 	"github.com/bgdsh/goth/providers/slack"
+	"github.com/bgdsh/goth/providers/smartonfhir"
+	"github.com/bgdsh/goth/providers/snapchat"
 	"github.com/bgdsh/goth/providers/soundcloud"
 	"github.com/bgdsh/goth/providers/spotify"
+	"github.com/bgdsh/goth/providers/squarespace"
 	"github.com/bgdsh/goth/providers/steam"
 	"github.com/bgdsh/goth/providers/strava"
 	"github.com/bgdsh/goth/providers/stripe"
+	"github.com/bgdsh/goth/providers/threads"
 	"github.com/bgdsh/goth/providers/tiktok"
+	"github.com/bgdsh/goth/providers/trakt"
+	"github.com/bgdsh/goth/providers/trello"
 	"github.com/bgdsh/goth/providers/twitch"
 	"github.com/bgdsh/goth/providers/twitter"
 	"github.com/bgdsh/goth/providers/typetalk"
 	"github.com/bgdsh/goth/providers/uber"
+	"github.com/bgdsh/goth/providers/vercel"
 	"github.com/bgdsh/goth/providers/vk"
 	"github.com/bgdsh/goth/providers/wecom"
+	"github.com/bgdsh/goth/providers/weibo"
 	"github.com/bgdsh/goth/providers/wepay"
+	"github.com/bgdsh/goth/providers/whoop"
+	"github.com/bgdsh/goth/providers/withings"
+	"github.com/bgdsh/goth/providers/wix"
 	"github.com/bgdsh/goth/providers/xero"
 	"github.com/bgdsh/goth/providers/yahoo"
 	"github.com/bgdsh/goth/providers/yammer"
 	"github.com/bgdsh/goth/providers/yandex"
+	"github.com/bgdsh/goth/providers/zendesk"
+	"github.com/bgdsh/goth/providers/zoho"
 	"github.com/bgdsh/goth/providers/zoom"
 )
 
@@ -126,6 +183,7 @@ func main() {
 		//By default paypal production auth urls will be used, please set PAYPAL_ENV=sandbox as environment variable for testing
 		//in sandbox environment
 		paypal.New(os.Getenv("PAYPAL_KEY"), os.Getenv("PAYPAL_SECRET"), "http://localhost:3000/auth/paypal/callback"),
+		qq.New(os.Getenv("QQ_APP_ID"), os.Getenv("QQ_APP_KEY"), "http://localhost:3000/auth/qq/callback"),
 		steam.New(os.Getenv("STEAM_KEY"), "http://localhost:3000/auth/steam/callback"),
 		heroku.New(os.Getenv("HEROKU_KEY"), os.Getenv("HEROKU_SECRET"), "http://localhost:3000/auth/heroku/callback"),
 		uber.New(os.Getenv("UBER_KEY"), os.Getenv("UBER_SECRET"), "http://localhost:3000/auth/uber/callback"),
@@ -149,8 +207,63 @@ func main() {
 		strava.New(os.Getenv("STRAVA_KEY"), os.Getenv("STRAVA_SECRET"), "http://localhost:3000/auth/strava/callback"),
 		okta.New(os.Getenv("OKTA_ID"), os.Getenv("OKTA_SECRET"), os.Getenv("OKTA_ORG_URL"), "http://localhost:3000/auth/okta/callback", "openid", "profile", "email"),
 		mastodon.New(os.Getenv("MASTODON_KEY"), os.Getenv("MASTODON_SECRET"), "http://localhost:3000/auth/mastodon/callback", "read:accounts"),
+		matrix.New("http://localhost:3000/auth/matrix/callback", os.Getenv("MATRIX_HOMESERVER_URL")),
 		wecom.New(os.Getenv("WECOM_CORP_ID"), os.Getenv("WECOM_SECRET"), os.Getenv("WECOM_AGENT_ID"), "http://localhost:3000/auth/wecom/callback"),
 		zoom.New(os.Getenv("ZOOM_KEY"), os.Getenv("ZOOM_SECRET"), "http://localhost:3000/auth/zoom/callback", "read:user"),
+		weibo.New(os.Getenv("WEIBO_KEY"), os.Getenv("WEIBO_SECRET"), "http://localhost:3000/auth/weibo/callback"),
+		dingtalk.New(os.Getenv("DINGTALK_KEY"), os.Getenv("DINGTALK_SECRET"), "http://localhost:3000/auth/dingtalk/callback"),
+		larksuite.New(os.Getenv("FEISHU_KEY"), os.Getenv("FEISHU_SECRET"), "http://localhost:3000/auth/feishu/callback"),
+		larksuite.NewLark(os.Getenv("LARK_KEY"), os.Getenv("LARK_SECRET"), "http://localhost:3000/auth/lark/callback"),
+		lineworks.New(os.Getenv("LINEWORKS_KEY"), os.Getenv("LINEWORKS_SECRET"), "http://localhost:3000/auth/lineworks/callback"),
+		chatwork.New(os.Getenv("CHATWORK_KEY"), os.Getenv("CHATWORK_SECRET"), "http://localhost:3000/auth/chatwork/callback"),
+		basecamp.New(os.Getenv("BASECAMP_KEY"), os.Getenv("BASECAMP_SECRET"), "http://localhost:3000/auth/basecamp/callback"),
+		harvest.New(os.Getenv("HARVEST_KEY"), os.Getenv("HARVEST_SECRET"), "http://localhost:3000/auth/harvest/callback"),
+		asana.New(os.Getenv("ASANA_KEY"), os.Getenv("ASANA_SECRET"), "http://localhost:3000/auth/asana/callback"),
+		monday.New(os.Getenv("MONDAY_KEY"), os.Getenv("MONDAY_SECRET"), "http://localhost:3000/auth/monday/callback"),
+		trello.New(os.Getenv("TRELLO_KEY"), os.Getenv("TRELLO_SECRET"), "http://localhost:3000/auth/trello/callback"),
+		hubspot.New(os.Getenv("HUBSPOT_KEY"), os.Getenv("HUBSPOT_SECRET"), "http://localhost:3000/auth/hubspot/callback"),
+		zoho.New(os.Getenv("ZOHO_KEY"), os.Getenv("ZOHO_SECRET"), "http://localhost:3000/auth/zoho/callback"),
+		airtable.New(os.Getenv("AIRTABLE_KEY"), os.Getenv("AIRTABLE_SECRET"), "http://localhost:3000/auth/airtable/callback"),
+		canva.New(os.Getenv("CANVA_KEY"), os.Getenv("CANVA_SECRET"), "http://localhost:3000/auth/canva/callback"),
+		miro.New(os.Getenv("MIRO_KEY"), os.Getenv("MIRO_SECRET"), "http://localhost:3000/auth/miro/callback"),
+		netlify.New(os.Getenv("NETLIFY_KEY"), os.Getenv("NETLIFY_SECRET"), "http://localhost:3000/auth/netlify/callback"),
+		vercel.New(os.Getenv("VERCEL_KEY"), os.Getenv("VERCEL_SECRET"), "http://localhost:3000/auth/vercel/callback", os.Getenv("VERCEL_SLUG")),
+		linode.New(os.Getenv("LINODE_KEY"), os.Getenv("LINODE_SECRET"), "http://localhost:3000/auth/linode/callback"),
+		roblox.New(os.Getenv("ROBLOX_KEY"), os.Getenv("ROBLOX_SECRET"), "http://localhost:3000/auth/roblox/callback"),
+		osu.New(os.Getenv("OSU_KEY"), os.Getenv("OSU_SECRET"), "http://localhost:3000/auth/osu/callback"),
+		itchio.New(os.Getenv("ITCHIO_KEY"), "http://localhost:3000/auth/itchio/callback"),
+		kick.New(os.Getenv("KICK_KEY"), os.Getenv("KICK_SECRET"), "http://localhost:3000/auth/kick/callback"),
+		bluesky.New(os.Getenv("BLUESKY_CLIENT_METADATA_URL"), "http://localhost:3000/auth/bluesky/callback"),
+		instagrambusiness.New(os.Getenv("FACEBOOK_KEY"), os.Getenv("FACEBOOK_SECRET"), "http://localhost:3000/auth/instagrambusiness/callback"),
+		threads.New(os.Getenv("THREADS_KEY"), os.Getenv("THREADS_SECRET"), "http://localhost:3000/auth/threads/callback"),
+		pinterest.New(os.Getenv("PINTEREST_KEY"), os.Getenv("PINTEREST_SECRET"), "http://localhost:3000/auth/pinterest/callback"),
+		snapchat.New(os.Getenv("SNAPCHAT_KEY"), os.Getenv("SNAPCHAT_SECRET"), "http://localhost:3000/auth/snapchat/callback"),
+		flickr.New(os.Getenv("FLICKR_KEY"), os.Getenv("FLICKR_SECRET"), "http://localhost:3000/auth/flickr/callback"),
+		medium.New(os.Getenv("MEDIUM_KEY"), os.Getenv("MEDIUM_SECRET"), "http://localhost:3000/auth/medium/callback"),
+		eventbrite.New(os.Getenv("EVENTBRITE_KEY"), os.Getenv("EVENTBRITE_SECRET"), "http://localhost:3000/auth/eventbrite/callback"),
+		myanimelist.New(os.Getenv("MYANIMELIST_KEY"), os.Getenv("MYANIMELIST_SECRET"), "http://localhost:3000/auth/myanimelist/callback"),
+		plex.New(os.Getenv("PLEX_CLIENT_IDENTIFIER"), "goth example", "http://localhost:3000/auth/plex/callback"),
+		trakt.New(os.Getenv("TRAKT_KEY"), os.Getenv("TRAKT_SECRET"), "http://localhost:3000/auth/trakt/callback"),
+		garmin.New(os.Getenv("GARMIN_KEY"), os.Getenv("GARMIN_SECRET"), "http://localhost:3000/auth/garmin/callback"),
+		polar.New(os.Getenv("POLAR_KEY"), os.Getenv("POLAR_SECRET"), "http://localhost:3000/auth/polar/callback"),
+		withings.New(os.Getenv("WITHINGS_KEY"), os.Getenv("WITHINGS_SECRET"), "http://localhost:3000/auth/withings/callback"),
+		whoop.New(os.Getenv("WHOOP_KEY"), os.Getenv("WHOOP_SECRET"), "http://localhost:3000/auth/whoop/callback"),
+		coinbase.New(os.Getenv("COINBASE_KEY"), os.Getenv("COINBASE_SECRET"), "http://localhost:3000/auth/coinbase/callback"),
+		questrade.New(os.Getenv("QUESTRADE_KEY"), "http://localhost:3000/auth/questrade/callback"),
+		schwab.New(os.Getenv("SCHWAB_KEY"), os.Getenv("SCHWAB_SECRET"), "https://127.0.0.1:3000/auth/schwab/callback"),
+		plaid.New(os.Getenv("PLAID_CLIENT_ID"), os.Getenv("PLAID_SECRET"), "http://localhost:3000/auth/plaid/callback"),
+		amazonseller.New(os.Getenv("AMAZON_SELLER_APP_ID"), os.Getenv("AMAZON_SELLER_KEY"), os.Getenv("AMAZON_SELLER_SECRET"), "http://localhost:3000/auth/amazonseller/callback"),
+		ebay.New(os.Getenv("EBAY_KEY"), os.Getenv("EBAY_SECRET"), os.Getenv("EBAY_RUNAME")),
+		etsy.New(os.Getenv("ETSY_KEY"), os.Getenv("ETSY_SECRET"), "http://localhost:3000/auth/etsy/callback"),
+		bigcommerce.New(os.Getenv("BIGCOMMERCE_APP_ID"), os.Getenv("BIGCOMMERCE_KEY"), os.Getenv("BIGCOMMERCE_SECRET"), "http://localhost:3000/auth/bigcommerce/callback"),
+		squarespace.New(os.Getenv("SQUARESPACE_KEY"), os.Getenv("SQUARESPACE_SECRET"), "http://localhost:3000/auth/squarespace/callback", squarespace.ScopeOrdersRead),
+		wix.New(os.Getenv("WIX_APP_ID"), os.Getenv("WIX_SECRET"), "http://localhost:3000/auth/wix/callback"),
+		adobe.New(os.Getenv("ADOBE_KEY"), os.Getenv("ADOBE_SECRET"), "http://localhost:3000/auth/adobe/callback"),
+		docusign.New(os.Getenv("DOCUSIGN_KEY"), os.Getenv("DOCUSIGN_SECRET"), "http://localhost:3000/auth/docusign/callback"),
+		zendesk.New(os.Getenv("ZENDESK_KEY"), os.Getenv("ZENDESK_SECRET"), "http://localhost:3000/auth/zendesk/callback", os.Getenv("ZENDESK_SUBDOMAIN")),
+		servicenow.New(os.Getenv("SERVICENOW_KEY"), os.Getenv("SERVICENOW_SECRET"), "http://localhost:3000/auth/servicenow/callback", os.Getenv("SERVICENOW_INSTANCE")),
+		orcid.New(os.Getenv("ORCID_KEY"), os.Getenv("ORCID_SECRET"), "http://localhost:3000/auth/orcid/callback"),
+		idme.New(os.Getenv("IDME_KEY"), os.Getenv("IDME_SECRET"), "http://localhost:3000/auth/idme/callback", idme.ScopeMilitary),
 	)
 
 	// OpenID Connect is based on OpenID Connect Auto Discovery URL (https://openid.net/specs/openid-connect-discovery-1_0-17.html)
@@ -161,6 +274,19 @@ func main() {
 		goth.UseProviders(openidConnect)
 	}
 
+	// SMART on FHIR discovers the end-points of the FHIR server in New(), like OpenID Connect.
+	smartonfhirProvider, _ := smartonfhir.New(os.Getenv("SMART_ON_FHIR_KEY"), os.Getenv("SMART_ON_FHIR_SECRET"), "http://localhost:3000/auth/smartonfhir/callback", os.Getenv("SMART_ON_FHIR_BASE_URL"),
+		smartonfhir.ScopeOpenID, smartonfhir.ScopeFHIRUser, smartonfhir.ScopeLaunchPatient)
+	if smartonfhirProvider != nil {
+		goth.UseProviders(smartonfhirProvider)
+	}
+
+	// Alipay signs its requests with the application's RSA private key, which has to be parsed first.
+	alipayKey, _ := alipay.ParsePrivateKey(os.Getenv("ALIPAY_PRIVATE_KEY"))
+	if alipayKey != nil {
+		goth.UseProviders(alipay.New(os.Getenv("ALIPAY_APP_ID"), alipayKey, "http://localhost:3000/auth/alipay/callback"))
+	}
+
 	m := make(map[string]string)
 	m["amazon"] = "Amazon"
 	m["bitbucket"] = "Bitbucket"
@@ -201,6 +327,7 @@ func main() {
 	m["microsoftonline"] = "Microsoft Online"
 	m["battlenet"] = "Battlenet"
 	m["paypal"] = "Paypal"
+	m["qq"] = "QQ"
 	m["twitter"] = "Twitter"
 	m["salesforce"] = "Salesforce"
 	m["typetalk"] = "Typetalk"
@@ -218,8 +345,65 @@ func main() {
 	m["strava"] = "Strava"
 	m["okta"] = "Okta"
 	m["mastodon"] = "Mastodon"
+	m["matrix"] = "Matrix"
 	m["wecom"] = "WeCom"
 	m["zoom"] = "Zoom"
+	m["weibo"] = "Weibo"
+	m["dingtalk"] = "DingTalk"
+	m["alipay"] = "Alipay"
+	m["feishu"] = "Feishu"
+	m["lark"] = "Lark"
+	m["lineworks"] = "LINE WORKS"
+	m["chatwork"] = "Chatwork"
+	m["basecamp"] = "Basecamp"
+	m["harvest"] = "Harvest"
+	m["asana"] = "Asana"
+	m["monday"] = "Monday.com"
+	m["trello"] = "Trello"
+	m["hubspot"] = "HubSpot"
+	m["zoho"] = "Zoho"
+	m["airtable"] = "Airtable"
+	m["canva"] = "Canva"
+	m["miro"] = "Miro"
+	m["netlify"] = "Netlify"
+	m["vercel"] = "Vercel"
+	m["linode"] = "Linode"
+	m["roblox"] = "Roblox"
+	m["osu"] = "osu!"
+	m["itchio"] = "itch.io"
+	m["kick"] = "Kick"
+	m["bluesky"] = "Bluesky"
+	m["instagrambusiness"] = "Instagram professional accounts"
+	m["threads"] = "Threads"
+	m["pinterest"] = "Pinterest"
+	m["snapchat"] = "Snapchat"
+	m["flickr"] = "Flickr"
+	m["medium"] = "Medium"
+	m["eventbrite"] = "Eventbrite"
+	m["myanimelist"] = "MyAnimeList"
+	m["plex"] = "Plex"
+	m["trakt"] = "Trakt"
+	m["garmin"] = "Garmin"
+	m["polar"] = "Polar"
+	m["withings"] = "Withings"
+	m["whoop"] = "WHOOP"
+	m["coinbase"] = "Coinbase"
+	m["questrade"] = "Questrade"
+	m["schwab"] = "Schwab"
+	m["plaid"] = "Plaid"
+	m["amazonseller"] = "Amazon Selling Partner"
+	m["ebay"] = "eBay"
+	m["etsy"] = "Etsy"
+	m["bigcommerce"] = "BigCommerce"
+	m["squarespace"] = "Squarespace"
+	m["wix"] = "Wix"
+	m["adobe"] = "Adobe"
+	m["docusign"] = "DocuSign"
+	m["zendesk"] = "Zendesk"
+	m["servicenow"] = "ServiceNow"
+	m["orcid"] = "ORCID"
+	m["idme"] = "ID.me"
+	m["smartonfhir"] = "SMART on FHIR"
 
 	var keys []string
 	for k := range m {
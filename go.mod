@@ -3,7 +3,7 @@ module github.com/bgdsh/goth
 go 1.15
 
 require (
-	cloud.google.com/go v0.67.0 // indirect
+	github.com/btcsuite/btcd/btcec/v2 v2.2.1
 	github.com/golang-jwt/jwt/v4 v4.2.0
 	github.com/gorilla/sessions v1.2.1
 	github.com/jarcoal/httpmock v0.0.0-20180424175123-9c70cfe4a1da
@@ -15,4 +15,5 @@ require (
 	github.com/mrjones/oauth v0.0.0-20180629183705-f4e24b6d100c
 	github.com/stretchr/testify v1.7.1
 	golang.org/x/oauth2 v0.0.0-20220411215720-9780585627b5
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
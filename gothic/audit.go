@@ -0,0 +1,47 @@
+package gothic
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/gorilla/sessions"
+)
+
+// MaxStateAge is the longest gothic should keep the state of a login, as
+// expected by goth.AuditConfiguration. A login takes minutes; states kept
+// longer can be replayed for longer.
+var MaxStateAge = 24 * time.Hour
+
+func init() {
+	goth.RegisterAuditCheck(auditStateTTL)
+}
+
+// auditStateTTL warns when Store keeps the gothic session, and so the state
+// of a login, without an expiry or longer than MaxStateAge. Stores are
+// checked through their Options field, as the stores of gorilla/sessions and
+// gothic/store have; the store of the echo session middleware is not.
+func auditStateTTL(report *goth.AuditReport) {
+	if Store == nil {
+		return
+	}
+	v := reflect.ValueOf(Store)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	f := v.Elem().FieldByName("Options")
+	if !f.IsValid() || !f.CanInterface() {
+		return
+	}
+	options, ok := f.Interface().(*sessions.Options)
+	if !ok || options == nil {
+		return
+	}
+
+	switch maxAge := time.Duration(options.MaxAge) * time.Second; {
+	case maxAge <= 0:
+		report.Warn("", goth.AuditCheckStateTTL, "the gothic session has no expiry, set a MaxAge on the options of Store")
+	case maxAge > MaxStateAge:
+		report.Warn("", goth.AuditCheckStateTTL, "the gothic session is kept for %s, more than the %s of MaxStateAge", maxAge, MaxStateAge)
+	}
+}
@@ -0,0 +1,35 @@
+package gothic
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditStateTTL(t *testing.T) {
+	a := assert.New(t)
+	providerStore := Store
+	defer func() { Store = providerStore }()
+
+	warnings := func() []goth.AuditWarning {
+		report := &goth.AuditReport{}
+		auditStateTTL(report)
+		return report.Warnings
+	}
+
+	store := sessions.NewCookieStore([]byte("secret"))
+	Store = store
+	a.Len(warnings(), 1, "gorilla keeps cookies for 30 days by default")
+	a.Equal(goth.AuditCheckStateTTL, warnings()[0].Check)
+
+	store.MaxAge(0)
+	a.Len(warnings(), 1)
+
+	store.MaxAge(600)
+	a.Empty(warnings())
+
+	Store = nil
+	a.Empty(warnings())
+}
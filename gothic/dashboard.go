@@ -0,0 +1,198 @@
+package gothic
+
+import (
+	"html/template"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// The dashboard helps operators debug misbehaving providers in production. It
+// lists the registered providers with their callback URLs, scopes and the
+// warnings of goth.AuditConfiguration, shows the latest logins completed by
+// CallbackHandler, and starts test logins whose user is shown on the
+// dashboard instead of being handed to OnAuthSuccess. It exposes the
+// configuration of the application: always mount it behind a middleware
+// restricting it to operators.
+//
+//	gothic.MountDashboard(e, "/admin/auth", middleware.BasicAuth(checkOperator))
+
+// dashboardKey is the gothic session key marking a test login started from
+// the dashboard, holding the dashboard path to return to.
+const dashboardKey = "_gothic_dashboard"
+
+// AuthOutcome is the outcome of a login completed by CallbackHandler.
+type AuthOutcome struct {
+	Time      time.Time `json:"time"`
+	Provider  string    `json:"provider,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Test      bool      `json:"test,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	AttemptID string    `json:"attempt_id,omitempty"`
+	// ImpersonatedBy is set for the impersonations started with
+	// StartImpersonation, to the ID of the administrator.
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
+}
+
+// RecentOutcomes keeps the latest outcomes of CallbackHandler, and the
+// impersonations started, for the dashboard. Set it to nil to stop recording
+// them.
+var RecentOutcomes = NewOutcomeLog(50)
+
+// OutcomeLog keeps the latest outcomes of logins in memory. It is safe for
+// concurrent use.
+type OutcomeLog struct {
+	mu       sync.Mutex
+	size     int
+	outcomes []AuthOutcome
+}
+
+// NewOutcomeLog returns a log keeping the last size outcomes.
+func NewOutcomeLog(size int) *OutcomeLog {
+	return &OutcomeLog{size: size}
+}
+
+// Add records an outcome, dropping the oldest one when the log is full.
+func (l *OutcomeLog) Add(o AuthOutcome) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.outcomes = append(l.outcomes, o)
+	if len(l.outcomes) > l.size {
+		l.outcomes = l.outcomes[len(l.outcomes)-l.size:]
+	}
+}
+
+// List returns the recorded outcomes, the latest first.
+func (l *OutcomeLog) List() []AuthOutcome {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := make([]AuthOutcome, len(l.outcomes))
+	for i, o := range l.outcomes {
+		list[len(list)-1-i] = o
+	}
+	return list
+}
+
+// recordOutcome adds the outcome of the login completed by c to
+// RecentOutcomes.
+func recordOutcome(c echo.Context, user goth.User, err error, test bool) {
+	if RecentOutcomes == nil {
+		return
+	}
+	o := AuthOutcome{
+		Time:      goth.Now(),
+		UserID:    user.UserID,
+		Test:      test,
+		RequestID: RequestID(c),
+		AttemptID: LoginAttemptID(c),
+	}
+	if o.Provider = user.Provider; o.Provider == "" {
+		o.Provider, _ = GetProviderName(c)
+	}
+	if err != nil {
+		o.Error = err.Error()
+	}
+	RecentOutcomes.Add(o)
+}
+
+// Dashboard is what DashboardHandler shows, and serves as JSON to clients
+// asking for it.
+type Dashboard struct {
+	goth.AuditReport
+	Outcomes []AuthOutcome `json:"outcomes"`
+}
+
+// MountDashboard registers the dashboard under prefix, "/admin/auth" when
+// empty:
+//
+//	GET <prefix>                   shows the dashboard, see DashboardHandler
+//	GET <prefix>/test/:provider    starts a test login
+//
+// The callback of test logins is the usual one, see Mount.
+func MountDashboard(r Router, prefix string, m ...echo.MiddlewareFunc) {
+	if prefix == "" {
+		prefix = "/admin/auth"
+	}
+	r.GET(prefix, DashboardHandler, m...)
+	r.GET(prefix+"/test/:provider", func(c echo.Context) error {
+		if err := StoreInSession(dashboardKey, prefix, c); err != nil {
+			return err
+		}
+		return beginAuth(c)
+	}, m...)
+}
+
+// endTestLogin forgets the test login marked in the session, if any, so that
+// the next logins of the browser are not taken for tests.
+func endTestLogin(c echo.Context) error {
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
+	if _, ok := sess.Values[dashboardKey]; !ok {
+		return nil
+	}
+	delete(sess.Values, dashboardKey)
+	return sess.Save(c.Request(), c.Response())
+}
+
+// DashboardHandler shows the providers, their audit and the recent login
+// outcomes, as JSON to clients asking for it and as a page otherwise. The page
+// links to the test logins registered by MountDashboard.
+func DashboardHandler(c echo.Context) error {
+	d := Dashboard{AuditReport: goth.AuditConfiguration(), Outcomes: []AuthOutcome{}}
+	if RecentOutcomes != nil {
+		d.Outcomes = RecentOutcomes.List()
+	}
+	if wantsJSON(c.Request()) {
+		return c.JSON(http.StatusOK, d)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	c.Response().WriteHeader(http.StatusOK)
+	return dashboardTemplate.Execute(c.Response(), struct {
+		Dashboard
+		Path string
+	}{d, c.Path()})
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"deref": func(b *bool) bool { return *b },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Authentication</title></head>
+<body>
+<h1>Providers</h1>
+<table>
+<tr><th>Name</th><th>Callback URL</th><th>Scopes</th><th>PKCE</th><th></th></tr>
+{{range .Providers}}<tr>
+<td>{{.Name}}</td>
+<td>{{.CallbackURL}}</td>
+<td>{{range $i, $s := .Scopes}}{{if $i}} {{end}}{{$s}}{{end}}</td>
+<td>{{if .PKCE}}{{if deref .PKCE}}on{{else}}off{{end}}{{end}}</td>
+<td><a href="{{$.Path}}/test/{{.Name}}">Test login</a></td>
+</tr>
+{{end}}</table>
+<h1>Warnings</h1>
+<ul>
+{{range .Warnings}}<li>{{.}}</li>
+{{else}}<li>None</li>
+{{end}}</ul>
+<h1>Recent logins</h1>
+<table>
+<tr><th>Time</th><th>Provider</th><th>User ID</th><th>Error</th><th>Request ID</th></tr>
+{{range .Outcomes}}<tr>
+<td>{{.Time.Format "2006-01-02 15:04:05 MST"}}{{if .Test}} (test){{end}}</td>
+<td>{{.Provider}}</td>
+<td>{{.UserID}}{{if .ImpersonatedBy}} (impersonated by {{.ImpersonatedBy}}){{end}}</td>
+<td>{{.Error}}</td>
+<td>{{.RequestID}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,134 @@
+package gothic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OutcomeLog(t *testing.T) {
+	a := assert.New(t)
+
+	l := NewOutcomeLog(2)
+	a.Empty(l.List())
+	for _, provider := range []string{"github", "google", "gitlab"} {
+		l.Add(AuthOutcome{Provider: provider})
+	}
+	a.Equal([]AuthOutcome{{Provider: "gitlab"}, {Provider: "google"}}, l.List())
+}
+
+func Test_DashboardHandler(t *testing.T) {
+	a := assert.New(t)
+	e := echo.New()
+	MountDashboard(e, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/auth", nil)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	a.Equal(http.StatusOK, res.Code)
+	d := Dashboard{}
+	a.NoError(json.Unmarshal(res.Body.Bytes(), &d))
+	a.Equal("faux", d.Providers[0].Name)
+	a.NotNil(d.Outcomes)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/auth", nil)
+	res = httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	a.Equal(http.StatusOK, res.Code)
+	a.Contains(res.Body.String(), `<a href="/admin/auth/test/faux">Test login</a>`)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/auth/test/faux", nil)
+	res = httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	dashboard, err := GetFromSession("_gothic_dashboard", echo.New().NewContext(req, res))
+	a.NoError(err)
+	a.Equal("/admin/auth", dashboard)
+}
+
+func Test_CallbackHandlerTestLogin(t *testing.T) {
+	a := assert.New(t)
+
+	OnAuthSuccess = func(c echo.Context, user goth.User) error {
+		t.Error("test logins must not log the operator in")
+		return nil
+	}
+	defer func() { OnAuthSuccess = nil }()
+
+	e := echo.New()
+	Mount(e, "")
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req.Header.Set(goth.RequestIDHeader, "request-1")
+	session, _ := Store.Get(req, SessionName)
+	session.Values["faux"] = gzipString((&faux.Session{Name: "Homer Simpson"}).Marshal())
+	session.Values["_gothic_dashboard"] = gzipString("/admin/auth")
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/admin/auth", res.Header().Get(echo.HeaderLocation))
+	outcome := RecentOutcomes.List()[0]
+	a.True(outcome.Test)
+	a.Equal("faux", outcome.Provider)
+	a.Equal("request-1", outcome.RequestID)
+	a.Empty(outcome.Error)
+}
+
+func Test_LoginAfterTestLogin(t *testing.T) {
+	a := assert.New(t)
+
+	var users []goth.User
+	OnAuthSuccess = func(c echo.Context, user goth.User) error {
+		users = append(users, user)
+		return nil
+	}
+	SuccessURL = "/welcome"
+	defer func() { OnAuthSuccess, SuccessURL = nil, "" }()
+
+	e := echo.New()
+	Mount(e, "")
+	MountDashboard(e, "")
+
+	// The test store keeps the session of the request, reused as the browser.
+	req := httptest.NewRequest(http.MethodGet, "/admin/auth/test/faux", nil)
+	login := func(path string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req.URL, _ = url.Parse(path)
+		req.RequestURI = path
+		e.ServeHTTP(res, req)
+		location, _ := url.Parse(res.Header().Get(echo.HeaderLocation))
+		if res.Code == http.StatusTemporaryRedirect {
+			res = httptest.NewRecorder()
+			path = "/auth/faux/callback?state=" + url.QueryEscape(location.Query().Get("state"))
+			req.URL, _ = url.Parse(path)
+			req.RequestURI = path
+			e.ServeHTTP(res, req)
+		}
+		return res
+	}
+
+	res := login("/admin/auth/test/faux")
+	a.Equal("/admin/auth", res.Header().Get(echo.HeaderLocation))
+	a.Empty(users)
+
+	res = login("/auth/faux")
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation), "only the test login goes back to the dashboard")
+	a.Len(users, 1)
+
+	// A test login abandoned before its callback is forgotten by the next
+	// login.
+	session, _ := Store.Get(req, SessionName)
+	session.Values["_gothic_dashboard"] = gzipString("/admin/auth")
+	res = login("/auth/faux")
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+	a.Len(users, 2)
+}
@@ -0,0 +1,68 @@
+package gothic
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// EmailParam is the parameter EmailLoginHandler reads the email from.
+const EmailParam = "email"
+
+// EmailDomains, when set, picks the provider of the logins begun with an
+// email by EmailLoginHandler.
+var EmailDomains *goth.EmailDomains
+
+// loginHintContextKey is the echo context key holding the email GetAuthURL
+// passes to the provider as a login_hint.
+const loginHintContextKey = "_gothic_login_hint"
+
+// EmailLoginHandler begins the login of the user whose email is in the email
+// parameter, with the provider EmailDomains picks for it, for login pages
+// asking for a work email:
+//
+//	<form method="post" action="/auth/_gothic/email">
+//		<input type="email" name="email">
+//	</form>
+//
+// The email is passed to the provider as a login_hint, so that the user does
+// not have to type it again. It answers with a 404 when EmailDomains is not
+// set.
+func EmailLoginHandler(c echo.Context) error {
+	if EmailDomains == nil {
+		return echo.ErrNotFound
+	}
+	email := c.FormValue(EmailParam)
+	providerName, err := EmailDomains.Provider(email)
+	if err != nil {
+		c.Logger().Error(err)
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	c.SetParamNames("provider")
+	c.SetParamValues(providerName)
+	c.Set(loginHintContextKey, email)
+	return BeginAuthHandler(c)
+}
+
+// withLoginHint adds the email of a login begun by EmailLoginHandler to
+// authURL, unless the provider already set a login_hint.
+func withLoginHint(c echo.Context, authURL string) (string, error) {
+	hint, ok := c.Get(loginHintContextKey).(string)
+	if !ok {
+		return authURL, nil
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if q.Get("login_hint") != "" {
+		return authURL, nil
+	}
+	q.Set("login_hint", hint)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
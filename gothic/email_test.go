@@ -0,0 +1,50 @@
+package gothic_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EmailLoginHandler(t *testing.T) {
+	a := assert.New(t)
+
+	e := echo.New()
+	Mount(e, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/_gothic/email?email=homer@corp.com", nil)
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	a.Equal(http.StatusNotFound, res.Code)
+
+	EmailDomains = &goth.EmailDomains{Domains: map[string]string{"corp.com": "faux"}}
+	defer func() { EmailDomains = nil }()
+
+	form := url.Values{EmailParam: {"homer@eu.corp.com"}}
+	req = httptest.NewRequest(http.MethodPost, "/auth/_gothic/email", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	res = httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	location, err := url.Parse(res.Header().Get(echo.HeaderLocation))
+	a.NoError(err)
+	a.Equal("example.com", location.Host)
+	a.Equal("homer@eu.corp.com", location.Query().Get("login_hint"))
+
+	sess, err := Store.Get(req, SessionName)
+	a.NoError(err)
+	a.Contains(sess.Values, "faux")
+
+	req = httptest.NewRequest(http.MethodGet, "/auth/_gothic/email?email=homer@example.com", nil)
+	res = httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	a.Equal(http.StatusBadRequest, res.Code)
+	a.Equal("no provider for the email domain example.com", res.Body.String())
+}
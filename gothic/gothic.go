@@ -8,11 +8,12 @@ See https://github.com/bgdsh/goth/blob/master/examples/main.go to see this in ac
 package gothic
 
 import (
-	"bytes"
 	"compress/gzip"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -21,8 +22,10 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/bgdsh/goth"
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
@@ -31,6 +34,31 @@ import (
 // SessionName is the key used to access the session store.
 const SessionName = "_gothic_session"
 
+// Store is the session store gothic keeps the in-flight provider sessions in.
+// When it is nil, the store registered with the echo-contrib session
+// middleware is used. Set it to keep auth sessions server side, for example
+// with the store implementations found in gothic/store.
+var Store sessions.Store
+
+// UserCache, when set, caches the users fetched by CompleteUserAuth, keyed by
+// a hash of their access token. See goth.NewMemoryUserCache.
+var UserCache goth.UserCache
+
+// Debug enables logging of full session payloads, including access and
+// refresh tokens. It must never be enabled in production; by default only
+// redacted payloads are logged.
+var Debug = false
+
+var (
+	// ErrStateMismatch is returned by CompleteUserAuth when the state of the
+	// callback is not the one the login was begun with.
+	ErrStateMismatch = errors.New("state token mismatch")
+	// ErrSessionNotFound is returned when the gothic session of the request
+	// does not hold the value asked for, such as the provider session of a
+	// callback whose login was begun elsewhere or has expired.
+	ErrSessionNotFound = errors.New("could not find a matching session for this request")
+)
+
 type key int
 
 // ProviderParamKey can be used as a key in context when passing in a provider
@@ -47,14 +75,42 @@ for the requested provider.
 See https://github.com/bgdsh/goth/examples/main.go to see this in action.
 */
 func BeginAuthHandler(c echo.Context) error {
+	if err := endTestLogin(c); err != nil {
+		return err
+	}
+	return beginAuth(c)
+}
+
+// beginAuth redirects to the authentication end-point, see BeginAuthHandler.
+func beginAuth(c echo.Context) error {
+	if err := storeHeadlessRequest(c); err != nil {
+		c.Logger().Error(err)
+		return c.String(http.StatusBadRequest, err.Error())
+	}
 	authUrl, err := GetAuthURL(c)
+	if redirected, err := redirectToInstanceForm(c, err); redirected {
+		return err
+	}
 	if err != nil {
 		c.Logger().Error(err)
+		var rejected *LoginRejectedError
+		if errors.As(err, &rejected) {
+			return c.String(http.StatusForbidden, "Sign in was refused.")
+		}
 		return c.String(http.StatusBadRequest, err.Error())
 	}
 	return c.Redirect(http.StatusTemporaryRedirect, authUrl)
 }
 
+// RandReader is the source of randomness used to generate state nonces.
+// It defaults to crypto/rand and should only be replaced in tests, for
+// example with DeterministicReader to get reproducible auth URLs.
+var RandReader io.Reader = rand.Reader
+
+// stateErrorKey is the echo context key under which SetState records a
+// failure to generate a state, so GetAuthURL can return it as an error.
+const stateErrorKey = "_gothic_state_error"
+
 // SetState sets the state string associated with the given request.
 // If no state string is associated with the request, one will be generated.
 // This state is sent to the provider and can be retrieved during the
@@ -65,17 +121,57 @@ var SetState = func(c echo.Context) string {
 		return state
 	}
 
-	// If a state query param is not passed in, generate a random
-	// base64-encoded nonce so that the state on the auth URL
-	// is unguessable, preventing CSRF attacks, as described in
-	//
-	// https://auth0.com/docs/protocols/oauth2/oauth-state#keep-reading
+	state, err := NewState()
+	if err != nil {
+		c.Set(stateErrorKey, err)
+		return ""
+	}
+	return state
+}
+
+// NewState generates a random base64-encoded nonce read from RandReader,
+// so that the state on the auth URL is unguessable, preventing CSRF attacks,
+// as described in
+//
+// https://auth0.com/docs/protocols/oauth2/oauth-state#keep-reading
+func NewState() (string, error) {
 	nonceBytes := make([]byte, 64)
-	_, err := io.ReadFull(rand.Reader, nonceBytes)
+	_, err := io.ReadFull(RandReader, nonceBytes)
 	if err != nil {
-		panic("gothic: source of randomness unavailable: " + err.Error())
+		return "", fmt.Errorf("gothic: source of randomness unavailable: %v", err)
+	}
+	return base64.URLEncoding.EncodeToString(nonceBytes), nil
+}
+
+// DeterministicReader returns an endless stream of bytes derived from seed.
+// Assign it to RandReader in tests to make generated states, and therefore
+// auth URLs, reproducible. It must never be used outside of tests.
+func DeterministicReader(seed string) io.Reader {
+	return &deterministicReader{seed: []byte(seed)}
+}
+
+type deterministicReader struct {
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func (r *deterministicReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			block := make([]byte, len(r.seed)+8)
+			copy(block, r.seed)
+			binary.BigEndian.PutUint64(block[len(r.seed):], r.counter)
+			sum := sha256.Sum256(block)
+			r.buf = sum[:]
+			r.counter++
+		}
+		c := copy(p[n:], r.buf)
+		r.buf = r.buf[c:]
+		n += c
 	}
-	return base64.URLEncoding.EncodeToString(nonceBytes)
+	return n, nil
 }
 
 // GetState gets the state returned by the provider during the callback.
@@ -104,22 +200,38 @@ func GetAuthURL(c echo.Context) (string, error) {
 		return "", err
 	}
 
-	provider, err := goth.GetProvider(providerName)
+	provider, err := getBeginProvider(c, providerName)
 	if err != nil {
 		return "", err
 	}
-	sess, err := provider.BeginAuth(SetState(c))
-	log.Println(sess.Marshal())
+	state := SetState(c)
+	if err, ok := c.Get(stateErrorKey).(error); ok {
+		return "", err
+	}
+	if state, err = withInvitation(c, state); err != nil {
+		return "", err
+	}
+	if err := beginAttempt(c, providerName); err != nil {
+		return "", err
+	}
+
+	sess, err := provider.BeginAuth(state)
 	if err != nil {
 		return "", err
 	}
+	value := sess.Marshal()
+	logSession(c, sess, value)
 
 	authUrl, err := sess.GetAuthURL()
 	if err != nil {
 		return "", err
 	}
+	authUrl, err = withLoginHint(c, authUrl)
+	if err != nil {
+		return "", err
+	}
 
-	err = StoreInSession(providerName, sess.Marshal(), c)
+	err = StoreInSession(providerName, value, c)
 
 	if err != nil {
 		return "", err
@@ -144,11 +256,17 @@ var CompleteUserAuth = func(c echo.Context) (goth.User, error) {
 		return goth.User{}, err
 	}
 
-	provider, err := goth.GetProvider(providerName)
+	provider, err := getProvider(c, providerName)
 	if err != nil {
 		return goth.User{}, err
 	}
 
+	if VerifyCallback {
+		if err := verifyCallback(c, provider); err != nil {
+			return goth.User{}, err
+		}
+	}
+
 	value, err := GetFromSession(providerName, c)
 	if err != nil {
 		return goth.User{}, err
@@ -163,11 +281,15 @@ var CompleteUserAuth = func(c echo.Context) (goth.User, error) {
 	if err != nil {
 		return goth.User{}, err
 	}
+	if err := completeAttempt(c, providerName); err != nil {
+		return goth.User{}, err
+	}
 
 	user, err := provider.FetchUser(sess)
 	if err == nil {
 		// user can be found with existing session data
-		return user, err
+		checkUserSchema(c, user)
+		return withInstance(c, user), rotateSession(c)
 	}
 
 	params := c.QueryParams()
@@ -179,7 +301,7 @@ var CompleteUserAuth = func(c echo.Context) (goth.User, error) {
 	}
 
 	// get new token and retry fetch
-	_, err = sess.Authorize(provider, params)
+	token, err := sess.Authorize(provider, params)
 	if err != nil {
 		return goth.User{}, err
 	}
@@ -190,8 +312,94 @@ var CompleteUserAuth = func(c echo.Context) (goth.User, error) {
 		return goth.User{}, err
 	}
 
-	gu, err := provider.FetchUser(sess)
-	return gu, err
+	gu, err := goth.FetchUserWithCache(UserCache, provider, sess, token)
+	if err != nil {
+		if gu, err = lenientUser(c, provider, sess, err); err != nil {
+			return gu, err
+		}
+	} else {
+		checkUserSchema(c, gu)
+	}
+	return withInstance(c, gu), rotateSession(c)
+}
+
+// checkUserSchema reports the users that do not match the schema of their
+// provider, see goth.UseUserSchema, and logs their violations.
+func checkUserSchema(c echo.Context, user goth.User) {
+	if violations := goth.CheckUserSchema(user); len(violations) > 0 {
+		c.Logger().Warnf("%s users drifted from their schema: %v", user.Provider, violations)
+	}
+}
+
+// VerifyCallback makes CompleteUserAuth check that the callback request
+// arrived on the host and path of the provider's callback URL, so that a
+// callback routed through an unexpected host or handler is rejected. The host
+// is compared with the request's Host header, which reverse proxies must
+// preserve.
+var VerifyCallback = false
+
+func verifyCallback(c echo.Context, provider goth.Provider) error {
+	callbackURL, ok := goth.ProviderCallbackURL(provider)
+	if !ok {
+		return nil
+	}
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return err
+	}
+
+	r := c.Request()
+	if u.Host != "" && !strings.EqualFold(u.Host, r.Host) {
+		return fmt.Errorf("callback for %s arrived on host %q instead of %q", provider.Name(), r.Host, u.Host)
+	}
+	if u.Path != r.URL.Path {
+		return fmt.Errorf("callback for %s arrived on path %q instead of %q", provider.Name(), r.URL.Path, u.Path)
+	}
+	return nil
+}
+
+// OnSessionRotate is called once gothic has regenerated its own session after
+// a successful CompleteUserAuth. Set it to regenerate the application's
+// session as well, for example with RegenerateSession, so that an identifier
+// planted before the login cannot be used to ride the authenticated session.
+var OnSessionRotate func(c echo.Context) error
+
+// rotateSession regenerates the gothic session, and the application's
+// through OnSessionRotate, to prevent session fixation.
+func rotateSession(c echo.Context) error {
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
+	if err := RegenerateSession(c, sess); err != nil {
+		return err
+	}
+	if OnSessionRotate != nil {
+		return OnSessionRotate(c)
+	}
+	return nil
+}
+
+// RegenerateSession replaces the identifier of sess, keeping its values and
+// options. The record stored under the old identifier is deleted and a new
+// cookie is written, so it works with cookie and server side stores alike.
+func RegenerateSession(c echo.Context, sess *sessions.Session) error {
+	values := sess.Values
+	opts := *sess.Options
+
+	expired := opts
+	expired.MaxAge = -1
+	sess.Values = make(map[interface{}]interface{})
+	sess.Options = &expired
+	if err := sess.Save(c.Request(), c.Response()); err != nil {
+		return err
+	}
+
+	sess.ID = ""
+	sess.IsNew = true
+	sess.Values = values
+	sess.Options = &opts
+	return sess.Save(c.Request(), c.Response())
 }
 
 // validateState ensures that the state token param from the original
@@ -211,15 +419,99 @@ func validateState(c echo.Context, sess goth.Session) error {
 
 	originalState := authURL.Query().Get("state")
 	if originalState != "" && (originalState != reqState) {
-		return errors.New("state token mismatch")
+		return ErrStateMismatch
 	}
 	return nil
 }
 
+// getProvider returns the named provider, bound to the request ID of c so
+// that the provider's HTTP calls can be correlated with the request, and to
+// its circuit breaker. Providers are resolved by Resolver first, and the
+// providers of goth.Instances are the one of the instance the login was begun
+// with.
+func getProvider(c echo.Context, name string) (goth.Provider, error) {
+	if provider, ok, err := resolveProvider(c, name); ok {
+		if err != nil {
+			return nil, err
+		}
+		return bindProvider(c, provider)
+	}
+	provider, err := goth.GetProvider(name)
+	if err != nil {
+		instances, ok := goth.GetInstances(name)
+		if !ok {
+			return nil, err
+		}
+		if provider, err = completeInstance(c, instances); err != nil {
+			return nil, err
+		}
+	}
+	return bindProvider(c, provider)
+}
+
+// getBeginProvider is getProvider for the start of a login, where the
+// providers of goth.Instances are the one of the instance named by the
+// request.
+func getBeginProvider(c echo.Context, name string) (goth.Provider, error) {
+	if provider, ok, err := resolveProvider(c, name); ok {
+		if err != nil {
+			return nil, err
+		}
+		return bindProvider(c, provider)
+	}
+	instances, ok := goth.GetInstances(name)
+	if _, err := goth.GetProvider(name); err == nil || !ok {
+		return getProvider(c, name)
+	}
+	provider, err := beginInstance(c, instances)
+	if err != nil {
+		return nil, err
+	}
+	return bindProvider(c, provider)
+}
+
+// bindProvider binds provider to the request ID of c and to its circuit
+// breaker, see goth.UseCircuitBreakers. It fails with a
+// *goth.ProviderUnavailableError while the breaker is open.
+func bindProvider(c echo.Context, provider goth.Provider) (goth.Provider, error) {
+	if err := goth.ProviderAvailable(provider.Name()); err != nil {
+		return nil, err
+	}
+	return goth.WithCircuitBreaker(goth.WithRequestID(provider, RequestID(c))), nil
+}
+
+// RequestID returns the correlation ID of the request, as sent by the client
+// or a proxy in the goth.RequestIDHeader header, or as generated by Echo's
+// RequestID middleware. It returns an empty string when there is none.
+func RequestID(c echo.Context) string {
+	if id := c.Request().Header.Get(goth.RequestIDHeader); id != "" {
+		return id
+	}
+	return c.Response().Header().Get(goth.RequestIDHeader)
+}
+
+// logf logs a message prefixed with the request ID, if any.
+func logf(c echo.Context, format string, v ...interface{}) {
+	if id := RequestID(c); id != "" {
+		format = "request_id=" + id + " " + format
+	}
+	log.Printf(format, v...)
+}
+
+// logSession logs the session, redacting its secrets unless Debug is enabled.
+// value is the already marshaled session.
+func logSession(c echo.Context, sess goth.Session, value string) {
+	if Debug {
+		logf(c, "%s", value)
+		return
+	}
+	logf(c, "%s", goth.MarshalRedacted(sess))
+}
+
 // Logout invalidates a user session.
 func Logout(c echo.Context) error {
-	log.Println("Logout")
-	sess, err := session.Get(SessionName, c)
+	logf(c, "Logout")
+	sess, err := getSession(c)
 	if err != nil {
 		return err
 	}
@@ -275,7 +567,10 @@ func getProviderName(c echo.Context) (string, error) {
 
 	// As a fallback, loop over the used providers, if we already have a valid session for any provider (ie. user has already begun authentication with a provider), then return that provider name
 	providers := goth.GetProviders()
-	sess, _ := session.Get(SessionName, c)
+	sess, err := getSession(c)
+	if err != nil {
+		return "", err
+	}
 	for _, provider := range providers {
 		p := provider.Name()
 		value := sess.Values[p]
@@ -285,7 +580,30 @@ func getProviderName(c echo.Context) (string, error) {
 	}
 
 	// if not found then return an empty string with the corresponding error
-	return "", errors.New("you must select a provider")
+	return "", &goth.ProviderNotFoundError{}
+}
+
+// getSession returns the gothic session for the current request from Store,
+// or from the echo session middleware when Store is not set. A cookie that
+// cannot be decoded, such as one signed with rotated keys, gives a new
+// session; the other errors of the store, such as an unreachable database,
+// are returned.
+func getSession(c echo.Context) (*sessions.Session, error) {
+	var sess *sessions.Session
+	var err error
+	if Store != nil {
+		sess, err = Store.Get(c.Request(), SessionName)
+	} else {
+		sess, err = session.Get(SessionName, c)
+	}
+	var cookieErr securecookie.Error
+	if errors.As(err, &cookieErr) && cookieErr.IsDecode() && sess != nil {
+		return sess, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sess, nil
 }
 
 // GetContextWithProvider returns a new request context containing the provider
@@ -295,53 +613,79 @@ func GetContextWithProvider(req *http.Request, provider string) *http.Request {
 
 // StoreInSession stores a specified key/value pair in the session.
 func StoreInSession(key string, value string, c echo.Context) error {
-	sess, _ := session.Get(SessionName, c)
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
 
 	if err := updateSessionValue(sess, key, value); err != nil {
 		return err
 	}
 
-	err := sess.Save(c.Request(), c.Response())
-
-	return err
+	return sess.Save(c.Request(), c.Response())
 }
 
 // GetFromSession retrieves a previously-stored value from the session.
 // If no value has previously been stored at the specified key, it will return an error.
 func GetFromSession(key string, c echo.Context) (string, error) {
-	sess, _ := session.Get(SessionName, c)
+	sess, err := getSession(c)
+	if err != nil {
+		return "", err
+	}
 	value, err := getSessionValue(sess, key)
 	if err != nil {
-		return "", errors.New("could not find a matching session for this request")
+		return "", ErrSessionNotFound
 	}
 
 	return value, nil
 }
 
+// gzip writers and readers are comparatively expensive to allocate, and
+// every auth request compresses and decompresses the session once.
+var (
+	gzipWriterPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(ioutil.Discard)
+		},
+	}
+	gzipReaderPool sync.Pool
+)
+
 func getSessionValue(sess *sessions.Session, key string) (string, error) {
-	value := sess.Values[key]
-	if value == nil {
-		return "", fmt.Errorf("could not find a matching session for this request")
+	value, ok := sess.Values[key].(string)
+	if !ok {
+		return "", ErrSessionNotFound
 	}
-	rdata := strings.NewReader(value.(string))
-	r, err := gzip.NewReader(rdata)
-	if err != nil {
-		return "", err
+	rdata := strings.NewReader(value)
+
+	r, ok := gzipReaderPool.Get().(*gzip.Reader)
+	if ok {
+		if err := r.Reset(rdata); err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		if r, err = gzip.NewReader(rdata); err != nil {
+			return "", err
+		}
 	}
-	s, err := ioutil.ReadAll(r)
-	if err != nil {
+	defer gzipReaderPool.Put(r)
+
+	var s strings.Builder
+	s.Grow(len(value) * 2)
+	if _, err := io.Copy(&s, r); err != nil {
 		return "", err
 	}
-	return string(s), nil
+	return s.String(), nil
 }
 
 func updateSessionValue(session *sessions.Session, key, value string) error {
-	var b bytes.Buffer
-	gz := gzip.NewWriter(&b)
-	if _, err := gz.Write([]byte(value)); err != nil {
-		return err
-	}
-	if err := gz.Flush(); err != nil {
+	var b strings.Builder
+	gz := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(gz)
+	gz.Reset(&b)
+
+	if _, err := io.WriteString(gz, value); err != nil {
 		return err
 	}
 	if err := gz.Close(); err != nil {
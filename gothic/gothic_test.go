@@ -3,18 +3,23 @@ package gothic_test
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/rand"
+	"errors"
 	"fmt"
-	"html"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bgdsh/goth"
 	. "github.com/bgdsh/goth/gothic"
 	"github.com/bgdsh/goth/providers/faux"
+	"github.com/bgdsh/goth/providers/github"
 	"github.com/gorilla/sessions"
 	"github.com/labstack/echo-contrib/session"
 	"github.com/labstack/echo/v4"
@@ -60,6 +65,24 @@ func (p ProviderStore) Save(r *http.Request, w http.ResponseWriter, s *sessions.
 
 var fauxProvider goth.Provider
 
+func init() {
+	Store = NewProviderStore()
+	fauxProvider = &faux.Provider{}
+	goth.UseProviders(fauxProvider)
+}
+
+// newContext returns a context of req with the test store registered, as by
+// session.Middleware, and the provider of the query as route parameter.
+func newContext(req *http.Request, res http.ResponseWriter) echo.Context {
+	c := echo.New().NewContext(req, res)
+	c.Set("_session_store", Store)
+	if p := req.URL.Query().Get("provider"); p != "" {
+		c.SetParamNames("provider")
+		c.SetParamValues(p)
+	}
+	return c
+}
+
 func Test_BeginAuthHandler(t *testing.T) {
 	a := assert.New(t)
 
@@ -67,7 +90,7 @@ func Test_BeginAuthHandler(t *testing.T) {
 	req, err := http.NewRequest("GET", "/auth?provider=faux", nil)
 	a.NoError(err)
 
-	c := echo.New().NewContext(req, res)
+	c := newContext(req, res)
 	BeginAuthHandler(c)
 
 	sess, err := session.Get(SessionName, c)
@@ -87,8 +110,7 @@ func Test_BeginAuthHandler(t *testing.T) {
 	au, _ := gothSession.GetAuthURL()
 
 	a.Equal(http.StatusTemporaryRedirect, res.Code)
-	a.Contains(res.Body.String(),
-		fmt.Sprintf(`<a href="%s">Temporary Redirect</a>`, html.EscapeString(au)))
+	a.Equal(au, res.Header().Get(echo.HeaderLocation))
 }
 
 func Test_GetAuthURL(t *testing.T) {
@@ -98,7 +120,7 @@ func Test_GetAuthURL(t *testing.T) {
 	req, err := http.NewRequest("GET", "/auth?provider=faux", nil)
 	a.NoError(err)
 
-	c := echo.New().NewContext(req, res)
+	c := newContext(req, res)
 
 	u, err := GetAuthURL(c)
 	a.NoError(err)
@@ -118,7 +140,7 @@ func Test_GetAuthURL(t *testing.T) {
 	req2, err := http.NewRequest("GET", "/auth?provider=faux", nil)
 	a.NoError(err)
 
-	c = echo.New().NewContext(req2, httptest.NewRecorder())
+	c = newContext(req2, httptest.NewRecorder())
 	url2, err := GetAuthURL(c)
 	a.NoError(err)
 	parsed2, err := url.Parse(url2)
@@ -134,7 +156,7 @@ func Test_CompleteUserAuth(t *testing.T) {
 	a.NoError(err)
 
 	sess := faux.Session{Name: "Homer Simpson", Email: "homer@example.com"}
-	c := echo.New().NewContext(req, res)
+	c := newContext(req, res)
 	session, _ := session.Get(SessionName, c)
 	session.Values["faux"] = gzipString(sess.Marshal())
 	err = session.Save(req, res)
@@ -147,6 +169,29 @@ func Test_CompleteUserAuth(t *testing.T) {
 	a.Equal(user.Email, "homer@example.com")
 }
 
+func Test_CompleteUserAuthChecksUserSchema(t *testing.T) {
+	a := assert.New(t)
+
+	goth.UseUserSchema("faux", goth.UserSchema{Fields: []string{"Email", "AvatarURL"}})
+	defer goth.ClearUserSchemas()
+	var drifts []goth.SchemaDrift
+	goth.OnSchemaDrift = func(d goth.SchemaDrift) { drifts = append(drifts, d) }
+	defer func() { goth.OnSchemaDrift = nil }()
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?provider=faux", nil)
+	sess := faux.Session{Name: "Homer Simpson", Email: "homer@example.com"}
+	session, _ := Store.Get(req, SessionName)
+	session.Values["faux"] = gzipString(sess.Marshal())
+	a.NoError(session.Save(req, res))
+
+	user, err := CompleteUserAuth(newContext(req, res))
+	a.NoError(err)
+	a.Equal("homer@example.com", user.Email)
+	a.Len(drifts, 1)
+	a.Equal([]goth.SchemaViolation{{Path: "AvatarURL", Problem: "is empty"}}, drifts[0].Violations)
+}
+
 func Test_CompleteUserAuthWithSessionDeducedProvider(t *testing.T) {
 	a := assert.New(t)
 
@@ -156,7 +201,7 @@ func Test_CompleteUserAuthWithSessionDeducedProvider(t *testing.T) {
 	a.NoError(err)
 
 	sess := faux.Session{Name: "Homer Simpson", Email: "homer@example.com"}
-	c := echo.New().NewContext(req, res)
+	c := newContext(req, res)
 
 	session, _ := session.Get(SessionName, c)
 	session.Values["faux"] = gzipString(sess.Marshal())
@@ -169,6 +214,48 @@ func Test_CompleteUserAuthWithSessionDeducedProvider(t *testing.T) {
 	a.Equal(user.Email, "homer@example.com")
 }
 
+func Test_CompleteUserAuthErrors(t *testing.T) {
+	a := assert.New(t)
+
+	req, err := http.NewRequest("GET", "/auth/callback?provider=faux&state=forged", nil)
+	a.NoError(err)
+	_, err = CompleteUserAuth(newContext(req, httptest.NewRecorder()))
+	a.True(errors.Is(err, ErrSessionNotFound))
+
+	res := httptest.NewRecorder()
+	sess := faux.Session{Name: "Homer Simpson", AuthURL: "http://example.com/auth?state=issued"}
+	session, _ := Store.Get(req, SessionName)
+	session.Values["faux"] = gzipString(sess.Marshal())
+	a.NoError(session.Save(req, res))
+	_, err = CompleteUserAuth(newContext(req, res))
+	a.True(errors.Is(err, ErrStateMismatch))
+
+	req, err = http.NewRequest("GET", "/auth/callback?provider=unknown", nil)
+	a.NoError(err)
+	_, err = CompleteUserAuth(newContext(req, httptest.NewRecorder()))
+	a.True(errors.Is(err, goth.ErrProviderNotFound))
+}
+
+func Test_GetAuthURLProviderUnavailable(t *testing.T) {
+	a := assert.New(t)
+	goth.UseCircuitBreaker("faux", goth.BreakerSettings{Failures: 1, OpenFor: time.Minute})
+	defer goth.ClearCircuitBreakers()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	b, _ := goth.GetCircuitBreaker("faux")
+	resp, err := (&http.Client{Transport: b.Transport(nil)}).Get(ts.URL)
+	a.NoError(err)
+	resp.Body.Close()
+
+	req, err := http.NewRequest("GET", "/auth?provider=faux", nil)
+	a.NoError(err)
+	_, err = GetAuthURL(newContext(req, httptest.NewRecorder()))
+	a.True(errors.Is(err, goth.ErrProviderUnavailable))
+}
+
 func Test_SetState(t *testing.T) {
 	a := assert.New(t)
 
@@ -177,6 +264,44 @@ func Test_SetState(t *testing.T) {
 	a.Equal(SetState(c), "state")
 }
 
+func Test_DeterministicState(t *testing.T) {
+	a := assert.New(t)
+
+	RandReader = DeterministicReader("seed")
+	s1, err := NewState()
+	a.NoError(err)
+	RandReader = DeterministicReader("seed")
+	s2, err := NewState()
+	a.NoError(err)
+	RandReader = rand.Reader
+
+	a.Equal(s1, s2)
+	a.Len(s1, 88)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+func Test_GetAuthURLWithoutRandomness(t *testing.T) {
+	a := assert.New(t)
+
+	RandReader = failingReader{}
+	defer func() { RandReader = rand.Reader }()
+
+	req, err := http.NewRequest("GET", "/auth/faux", nil)
+	a.NoError(err)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("provider")
+	c.SetParamValues("faux")
+
+	_, err = GetAuthURL(c)
+	a.Error(err)
+	a.Contains(err.Error(), "no entropy")
+}
+
 func Test_GetState(t *testing.T) {
 	a := assert.New(t)
 
@@ -225,3 +350,209 @@ func ungzipString(value string) string {
 
 	return string(s)
 }
+
+func benchmarkContext(b *testing.B, handler echo.HandlerFunc) func() {
+	// Benchmark the cookie store wired through the echo middleware, not the
+	// in-memory test store.
+	providerStore := Store
+	Store = nil
+	b.Cleanup(func() { Store = providerStore })
+
+	store := sessions.NewCookieStore([]byte("benchmark-secret"))
+	h := session.Middleware(store)(handler)
+	e := echo.New()
+	return func() {
+		req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("provider")
+		c.SetParamValues("faux")
+		if err := h(c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_StoreInSession(b *testing.B) {
+	value := (&faux.Session{ID: "id", AuthURL: "http://example.com/auth?state=state", AccessToken: "access"}).Marshal()
+	run := benchmarkContext(b, func(c echo.Context) error {
+		return StoreInSession("faux", value, c)
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		run()
+	}
+}
+
+func Benchmark_GetFromSession(b *testing.B) {
+	value := gzipString((&faux.Session{ID: "id", AuthURL: "http://example.com/auth?state=state", AccessToken: "access"}).Marshal())
+	run := benchmarkContext(b, func(c echo.Context) error {
+		sess, _ := session.Get(SessionName, c)
+		sess.Values["faux"] = value
+		_, err := GetFromSession("faux", c)
+		return err
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		run()
+	}
+}
+
+func Benchmark_GetAuthURL(b *testing.B) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	run := benchmarkContext(b, func(c echo.Context) error {
+		_, err := GetAuthURL(c)
+		return err
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		run()
+	}
+}
+
+func Test_RequestID(t *testing.T) {
+	a := assert.New(t)
+
+	req, _ := http.NewRequest("GET", "/auth?provider=faux", nil)
+	res := httptest.NewRecorder()
+	c := newContext(req, res)
+	a.Equal("", RequestID(c))
+
+	// Set by Echo's RequestID middleware.
+	res.Header().Set(echo.HeaderXRequestID, "generated")
+	a.Equal("generated", RequestID(c))
+
+	req.Header.Set(echo.HeaderXRequestID, "upstream")
+	a.Equal("upstream", RequestID(c))
+}
+
+// failingStore is a session store failing to load sessions.
+type failingStore struct{}
+
+func (failingStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingStore) Save(r *http.Request, w http.ResponseWriter, sess *sessions.Session) error {
+	return errors.New("store unavailable")
+}
+
+func Test_SessionStoreErrors(t *testing.T) {
+	a := assert.New(t)
+
+	providerStore := Store
+	Store = failingStore{}
+	defer func() { Store = providerStore }()
+
+	req, err := http.NewRequest("GET", "/auth?provider=faux", nil)
+	a.NoError(err)
+	c := newContext(req, httptest.NewRecorder())
+
+	err = StoreInSession("faux", "value", c)
+	a.EqualError(err, "store unavailable")
+	_, err = GetFromSession("faux", c)
+	a.EqualError(err, "store unavailable")
+}
+
+// idStore is a server side store handing out sequential session IDs.
+type idStore struct {
+	next     int
+	sessions map[string]map[interface{}]interface{}
+}
+
+func (s *idStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return s.New(r, name)
+}
+
+func (s *idStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	sess := sessions.NewSession(s, name)
+	sess.Options = &sessions.Options{Path: "/", MaxAge: 300}
+	return sess, nil
+}
+
+func (s *idStore) Save(r *http.Request, w http.ResponseWriter, sess *sessions.Session) error {
+	if sess.Options.MaxAge < 0 {
+		delete(s.sessions, sess.ID)
+		return nil
+	}
+	if sess.ID == "" {
+		s.next++
+		sess.ID = fmt.Sprint(s.next)
+	}
+	s.sessions[sess.ID] = sess.Values
+	return nil
+}
+
+func Test_RegenerateSession(t *testing.T) {
+	a := assert.New(t)
+
+	store := &idStore{sessions: map[string]map[interface{}]interface{}{}}
+	req, _ := http.NewRequest("GET", "/auth/callback", nil)
+	c := newContext(req, httptest.NewRecorder())
+
+	sess, _ := store.Get(req, SessionName)
+	sess.Values["user"] = "homer"
+	a.NoError(sess.Save(req, c.Response()))
+	a.Equal("1", sess.ID)
+
+	a.NoError(RegenerateSession(c, sess))
+	a.Equal("2", sess.ID)
+	a.Equal(300, sess.Options.MaxAge)
+	a.NotContains(store.sessions, "1")
+	a.Equal("homer", store.sessions["2"]["user"])
+}
+
+func Test_CompleteUserAuthRotatesSession(t *testing.T) {
+	a := assert.New(t)
+
+	rotated := false
+	OnSessionRotate = func(c echo.Context) error {
+		rotated = true
+		return nil
+	}
+	defer func() { OnSessionRotate = nil }()
+
+	req, err := http.NewRequest("GET", "/auth/callback?provider=faux", nil)
+	a.NoError(err)
+	res := httptest.NewRecorder()
+	c := newContext(req, res)
+
+	sess := faux.Session{Name: "Homer Simpson", Email: "homer@example.com"}
+	session, _ := Store.Get(req, SessionName)
+	session.Values["faux"] = gzipString(sess.Marshal())
+	a.NoError(session.Save(req, res))
+
+	_, err = CompleteUserAuth(c)
+	a.NoError(err)
+	a.True(rotated)
+}
+
+func Test_VerifyCallback(t *testing.T) {
+	a := assert.New(t)
+
+	VerifyCallback = true
+	defer func() { VerifyCallback = false }()
+	goth.UseProviders(github.New("key", "secret", "https://example.com/auth/github/callback"))
+	defer delete(goth.GetProviders(), "github")
+
+	req, _ := http.NewRequest("GET", "https://evil.com/auth/github/callback?provider=github", nil)
+	_, err := CompleteUserAuth(newContext(req, httptest.NewRecorder()))
+	a.Error(err)
+	a.Contains(err.Error(), "evil.com")
+
+	req, _ = http.NewRequest("GET", "https://example.com/auth/other?provider=github", nil)
+	_, err = CompleteUserAuth(newContext(req, httptest.NewRecorder()))
+	a.Error(err)
+	a.Contains(err.Error(), "/auth/other")
+}
@@ -0,0 +1,110 @@
+package gothic
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Stages of a login at which LoginGuard is called.
+const (
+	// LoginStageBegin is before the user is sent to the provider.
+	LoginStageBegin = "begin"
+	// LoginStageExchange is once the user is back, before the code is
+	// exchanged for tokens and the user fetched.
+	LoginStageExchange = "exchange"
+	// LoginStageMFA is before every second factor of the user is checked,
+	// see MFAHandler.
+	LoginStageMFA = "mfa"
+)
+
+// LoginAttempt describes the login LoginGuard is asked about.
+type LoginAttempt struct {
+	// ID is the same at both stages of a login, so that a completion can be
+	// tied to the start it follows.
+	ID        string
+	Stage     string
+	Provider  string
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// LoginGuard, when set, is called before every login is begun and before it
+// is completed, the place for CAPTCHA, rate limiting or risk checks. When it
+// returns an error, the login is aborted with a *LoginRejectedError wrapping
+// it.
+var LoginGuard func(c echo.Context, attempt LoginAttempt) error
+
+// LoginRejectedError is returned by GetAuthURL and CompleteUserAuth when
+// LoginGuard rejected the login.
+type LoginRejectedError struct {
+	Attempt LoginAttempt
+	Err     error
+}
+
+func (e *LoginRejectedError) Error() string {
+	return fmt.Sprintf("gothic: %s login rejected at %s: %v", e.Attempt.Provider, e.Attempt.Stage, e.Err)
+}
+
+// Unwrap returns the error of LoginGuard.
+func (e *LoginRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// attemptKey is the gothic session key holding the ID of the login attempt
+// between the start of the login and its callback.
+const attemptKey = "_gothic_attempt"
+
+// attemptContextKey is the echo context key the ID of the login attempt is
+// recorded under.
+const attemptContextKey = "_gothic_attempt"
+
+// LoginAttemptID returns the ID of the login begun or completed by the
+// request, once GetAuthURL or CompleteUserAuth has been called.
+func LoginAttemptID(c echo.Context) string {
+	id, _ := c.Get(attemptContextKey).(string)
+	return id
+}
+
+// beginAttempt gives the login begun by c a new attempt ID, kept in the
+// session for the callback, and asks LoginGuard about it.
+func beginAttempt(c echo.Context, provider string) error {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(RandReader, b); err != nil {
+		return fmt.Errorf("gothic: source of randomness unavailable: %v", err)
+	}
+	id := base64.RawURLEncoding.EncodeToString(b)
+	if err := StoreInSession(attemptKey, id, c); err != nil {
+		return err
+	}
+	return guardAttempt(c, id, LoginStageBegin, provider)
+}
+
+// completeAttempt asks LoginGuard about the login completed by c, under the
+// attempt ID it was begun with.
+func completeAttempt(c echo.Context, provider string) error {
+	id, _ := GetFromSession(attemptKey, c)
+	return guardAttempt(c, id, LoginStageExchange, provider)
+}
+
+func guardAttempt(c echo.Context, id, stage, provider string) error {
+	c.Set(attemptContextKey, id)
+	if LoginGuard == nil {
+		return nil
+	}
+	attempt := LoginAttempt{
+		ID:        id,
+		Stage:     stage,
+		Provider:  provider,
+		IP:        c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		RequestID: RequestID(c),
+	}
+	if err := LoginGuard(c, attempt); err != nil {
+		return &LoginRejectedError{Attempt: attempt, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package gothic_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoginGuard(t *testing.T) {
+	a := assert.New(t)
+
+	var attempts []LoginAttempt
+	errBot := errors.New("looks like a bot")
+	LoginGuard = func(c echo.Context, attempt LoginAttempt) error {
+		attempts = append(attempts, attempt)
+		if attempt.UserAgent == "bot" {
+			return errBot
+		}
+		return nil
+	}
+	defer func() { LoginGuard = nil }()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth?provider=faux", nil)
+	req.Header.Set("User-Agent", "browser")
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
+	c := newContext(req, httptest.NewRecorder())
+	_, err := GetAuthURL(c)
+	a.NoError(err)
+	a.Len(attempts, 1)
+	a.Equal(LoginStageBegin, attempts[0].Stage)
+	a.Equal("faux", attempts[0].Provider)
+	a.Equal("203.0.113.7", attempts[0].IP)
+	a.Equal("browser", attempts[0].UserAgent)
+	a.NotEmpty(attempts[0].ID)
+	a.Equal(attempts[0].ID, LoginAttemptID(c))
+
+	req = httptest.NewRequest(http.MethodGet, "/auth?provider=faux", nil)
+	req.Header.Set("User-Agent", "bot")
+	_, err = GetAuthURL(newContext(req, httptest.NewRecorder()))
+	var rejected *LoginRejectedError
+	a.True(errors.As(err, &rejected))
+	a.True(errors.Is(err, errBot))
+	a.Equal(LoginStageBegin, rejected.Attempt.Stage)
+
+	res := httptest.NewRecorder()
+	a.NoError(BeginAuthHandler(newContext(req, res)))
+	a.Equal(http.StatusForbidden, res.Code)
+}
+
+func Test_LoginGuardExchange(t *testing.T) {
+	a := assert.New(t)
+
+	var attempt LoginAttempt
+	LoginGuard = func(c echo.Context, a LoginAttempt) error {
+		attempt = a
+		return errors.New("too many attempts")
+	}
+	defer func() { LoginGuard = nil }()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?provider=faux", nil)
+	session, _ := Store.Get(req, SessionName)
+	session.Values["faux"] = gzipString((&faux.Session{Name: "Homer Simpson"}).Marshal())
+	session.Values["_gothic_attempt"] = gzipString("attempt-1")
+
+	_, err := CompleteUserAuth(newContext(req, httptest.NewRecorder()))
+	var rejected *LoginRejectedError
+	a.True(errors.As(err, &rejected))
+	a.Equal(LoginStageExchange, attempt.Stage)
+	a.Equal("attempt-1", attempt.ID)
+	a.Equal("faux", attempt.Provider)
+}
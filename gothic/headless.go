@@ -0,0 +1,251 @@
+package gothic
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// Headless mode lets native and mobile applications log users in through the
+// system browser without sharing the browser's cookies. The application
+// opens
+//
+//	/auth/<provider>?redirect_uri=<uri>&code_challenge=<challenge>
+//
+// where redirect_uri is one of HeadlessRedirectURIs and code_challenge, which
+// is required, is the base64url encoded SHA-256 of a random code_verifier, as
+// in PKCE (RFC 7636). Once the user is authenticated, CallbackHandler
+// redirects to redirect_uri with a one-time code instead of setting up a
+// browser session, and the application exchanges the code, its code_verifier
+// and the same redirect_uri for the user and its tokens by posting them to
+// TokenHandler.
+
+// HeadlessRedirectURIs lists the URIs native applications may be sent back
+// to. The port of loopback URIs is ignored, as native applications listen on
+// whatever port is free (RFC 8252, section 7.3). Headless mode is disabled
+// while the list is empty.
+var HeadlessRedirectURIs []string
+
+// HeadlessCodes keeps the codes minted in headless mode until they are
+// exchanged. Replace it with a shared implementation when running more than
+// one instance of the application.
+var HeadlessCodes CodeStore = NewMemoryCodeStore(time.Minute)
+
+// HeadlessGrant is what a headless code can be exchanged for.
+type HeadlessGrant struct {
+	User          goth.User `json:"user"`
+	RedirectURI   string    `json:"redirect_uri"`
+	CodeChallenge string    `json:"code_challenge,omitempty"`
+}
+
+// CodeStore keeps headless codes. Take must return a grant at most once.
+type CodeStore interface {
+	Save(code string, grant HeadlessGrant) error
+	Take(code string) (HeadlessGrant, bool, error)
+}
+
+// headlessKey is the gothic session key holding the headless request
+// between the start of the login and its callback.
+const headlessKey = "_gothic_headless"
+
+type headlessRequest struct {
+	RedirectURI   string `json:"redirect_uri"`
+	CodeChallenge string `json:"code_challenge,omitempty"`
+}
+
+// storeHeadlessRequest remembers the headless parameters of the request
+// starting a login, if any, and forgets those of a previous headless login
+// otherwise.
+func storeHeadlessRequest(c echo.Context) error {
+	redirectURI := c.QueryParam("redirect_uri")
+	if redirectURI == "" {
+		return clearHeadlessRequest(c)
+	}
+	if !headlessRedirectAllowed(redirectURI) {
+		return errors.New("redirect_uri is not allowed")
+	}
+	challenge := c.QueryParam("code_challenge")
+	if challenge == "" {
+		return errors.New("code_challenge is required")
+	}
+
+	value, err := json.Marshal(headlessRequest{
+		RedirectURI:   redirectURI,
+		CodeChallenge: challenge,
+	})
+	if err != nil {
+		return err
+	}
+	return StoreInSession(headlessKey, string(value), c)
+}
+
+// clearHeadlessRequest forgets the headless request of an abandoned login, so
+// that the next login of the browser is not sent to the native application.
+func clearHeadlessRequest(c echo.Context) error {
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
+	if _, ok := sess.Values[headlessKey]; !ok {
+		return nil
+	}
+	delete(sess.Values, headlessKey)
+	return sess.Save(c.Request(), c.Response())
+}
+
+func getHeadlessRequest(c echo.Context) (*headlessRequest, bool) {
+	value, err := GetFromSession(headlessKey, c)
+	if err != nil {
+		return nil, false
+	}
+	r := &headlessRequest{}
+	if err := json.Unmarshal([]byte(value), r); err != nil {
+		return nil, false
+	}
+	return r, true
+}
+
+// completeHeadless sends the native application back to its redirect URI,
+// with a code to exchange for user or with the error that ended the login.
+func completeHeadless(c echo.Context, r *headlessRequest, user goth.User, authErr error) error {
+	u, err := url.Parse(r.RedirectURI)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+
+	if authErr != nil {
+		if OnAuthFailure != nil {
+			OnAuthFailure(c, authErr)
+		}
+		q.Set("error", "access_denied")
+	} else {
+		code, err := NewState()
+		if err != nil {
+			return err
+		}
+		grant := HeadlessGrant{User: user, RedirectURI: r.RedirectURI, CodeChallenge: r.CodeChallenge}
+		if err := HeadlessCodes.Save(code, grant); err != nil {
+			return err
+		}
+		q.Set("code", code)
+	}
+
+	u.RawQuery = q.Encode()
+	return c.Redirect(http.StatusFound, u.String())
+}
+
+// TokenHandler exchanges a headless code, posted as the code form value along
+// with its code_verifier and the redirect_uri it was sent to, for the
+// authenticated user and its tokens.
+func TokenHandler(c echo.Context) error {
+	grant, ok, err := HeadlessCodes.Take(c.FormValue("code"))
+	if err != nil {
+		return err
+	}
+	if !ok || c.FormValue("redirect_uri") != grant.RedirectURI ||
+		!verifyCodeChallenge(grant.CodeChallenge, c.FormValue("code_verifier")) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+	}
+	return c.JSON(http.StatusOK, grant.User)
+}
+
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if challenge == "" || verifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func headlessRedirectAllowed(redirectURI string) bool {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Fragment != "" {
+		return false
+	}
+	for _, allowed := range HeadlessRedirectURIs {
+		a, err := url.Parse(allowed)
+		if err != nil {
+			continue
+		}
+		if a.Scheme != u.Scheme || a.Hostname() != u.Hostname() || a.Path != u.Path || a.Opaque != u.Opaque {
+			continue
+		}
+		if a.Port() == u.Port() || isLoopback(u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// MemoryCodeStore is an in-process CodeStore. Codes expire after TTL.
+type MemoryCodeStore struct {
+	TTL time.Duration
+
+	mu     sync.Mutex
+	grants map[string]memoryGrant
+}
+
+type memoryGrant struct {
+	grant     HeadlessGrant
+	expiresAt time.Time
+}
+
+// NewMemoryCodeStore returns an in-process CodeStore whose codes expire after
+// ttl.
+func NewMemoryCodeStore(ttl time.Duration) *MemoryCodeStore {
+	return &MemoryCodeStore{TTL: ttl}
+}
+
+// Save stores grant under code.
+func (s *MemoryCodeStore) Save(code string, grant HeadlessGrant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := goth.Now()
+	if s.grants == nil {
+		s.grants = map[string]memoryGrant{}
+	}
+	for k, g := range s.grants {
+		if !now.Before(g.expiresAt) {
+			delete(s.grants, k)
+		}
+	}
+	s.grants[code] = memoryGrant{grant: grant, expiresAt: now.Add(s.TTL)}
+	return nil
+}
+
+// Take returns the grant stored under code and removes it.
+func (s *MemoryCodeStore) Take(code string) (HeadlessGrant, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grants[code]
+	if !ok {
+		return HeadlessGrant{}, false, nil
+	}
+	delete(s.grants, code)
+	if !goth.Now().Before(g.expiresAt) {
+		return HeadlessGrant{}, false, nil
+	}
+	return g.grant, true, nil
+}
@@ -0,0 +1,221 @@
+package gothic_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/gorilla/sessions"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveHeadless(e *echo.Echo, method, target string, cookies []*http.Cookie, form url.Values) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	// Like a browser, only send the last value set for each cookie.
+	last := map[string]*http.Cookie{}
+	for _, cookie := range cookies {
+		last[cookie.Name] = cookie
+	}
+	for _, cookie := range last {
+		req.AddCookie(cookie)
+	}
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	return res
+}
+
+func Test_Headless(t *testing.T) {
+	a := assert.New(t)
+
+	previous := Store
+	Store = sessions.NewCookieStore([]byte("headless-test-key"))
+	HeadlessRedirectURIs = []string{"com.example.app:/oauth", "http://127.0.0.1/callback"}
+	defer func() {
+		Store = previous
+		HeadlessRedirectURIs = nil
+	}()
+
+	e := echo.New()
+	Mount(e, "")
+
+	verifier := "dBjftJeZ4CVP-mJ92K9bHSBoKWEvbbmFsbWFsb3NvbWV2ZXJpZmllcg"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	q := url.Values{"redirect_uri": {"com.example.app:/oauth"}, "code_challenge": {challenge}}
+	res := serveHeadless(e, http.MethodGet, "/auth/faux?"+q.Encode(), nil, nil)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	authURL, err := url.Parse(res.Header().Get(echo.HeaderLocation))
+	a.NoError(err)
+
+	res = serveHeadless(e, http.MethodGet, "/auth/faux/callback?state="+authURL.Query().Get("state"), res.Result().Cookies(), nil)
+	a.Equal(http.StatusFound, res.Code)
+	back, err := url.Parse(res.Header().Get(echo.HeaderLocation))
+	a.NoError(err)
+	a.Equal("com.example.app", back.Scheme)
+	code := back.Query().Get("code")
+	a.NotEmpty(code)
+
+	form := url.Values{"code": {code}, "code_verifier": {"wrong"}, "redirect_uri": {"com.example.app:/oauth"}}
+	res = serveHeadless(e, http.MethodPost, "/auth/_gothic/token", nil, form)
+	a.Equal(http.StatusBadRequest, res.Code)
+
+	// A failed exchange burns the code.
+	form.Set("code_verifier", verifier)
+	res = serveHeadless(e, http.MethodPost, "/auth/_gothic/token", nil, form)
+	a.Equal(http.StatusBadRequest, res.Code)
+	a.Contains(res.Body.String(), "invalid_grant")
+}
+
+func Test_HeadlessExchange(t *testing.T) {
+	a := assert.New(t)
+
+	previous := Store
+	Store = sessions.NewCookieStore([]byte("headless-test-key"))
+	HeadlessRedirectURIs = []string{"http://127.0.0.1/callback"}
+	defer func() {
+		Store = previous
+		HeadlessRedirectURIs = nil
+	}()
+
+	e := echo.New()
+	Mount(e, "")
+
+	res := beginHeadless(e, "http://127.0.0.1:51004/callback")
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	authURL, _ := url.Parse(res.Header().Get(echo.HeaderLocation))
+
+	res = serveHeadless(e, http.MethodGet, "/auth/faux/callback?state="+authURL.Query().Get("state"), res.Result().Cookies(), nil)
+	back, _ := url.Parse(res.Header().Get(echo.HeaderLocation))
+	a.Equal("127.0.0.1:51004", back.Host)
+
+	form := url.Values{
+		"code":          {back.Query().Get("code")},
+		"code_verifier": {testVerifier},
+		"redirect_uri":  {"http://127.0.0.1:51004/callback"},
+	}
+	res = serveHeadless(e, http.MethodPost, "/auth/_gothic/token", nil, form)
+	a.Equal(http.StatusOK, res.Code)
+	user := map[string]interface{}{}
+	a.NoError(json.Unmarshal(res.Body.Bytes(), &user))
+	a.Equal("faux", user["Provider"])
+	a.NotEmpty(user["AccessToken"])
+
+	res = serveHeadless(e, http.MethodPost, "/auth/_gothic/token", nil, form)
+	a.Equal(http.StatusBadRequest, res.Code, "codes can only be exchanged once")
+}
+
+func Test_HeadlessRedirectNotAllowed(t *testing.T) {
+	a := assert.New(t)
+
+	HeadlessRedirectURIs = []string{"https://app.example.com/callback"}
+	defer func() { HeadlessRedirectURIs = nil }()
+
+	e := echo.New()
+	Mount(e, "")
+
+	for _, uri := range []string{
+		"https://evil.example.com/callback",
+		"https://app.example.com:8443/callback",
+		"https://app.example.com/callback#x",
+	} {
+		res := serveHeadless(e, http.MethodGet, "/auth/faux?redirect_uri="+url.QueryEscape(uri), nil, nil)
+		a.Equal(http.StatusBadRequest, res.Code, uri)
+	}
+}
+
+// testVerifier is the code_verifier of the headless logins started by
+// beginHeadless.
+const testVerifier = "dBjftJeZ4CVP-mJ92K9bHSBoKWEvbbmFsbWFsb3NvbWV2ZXJpZmllcg"
+
+func beginHeadless(e *echo.Echo, redirectURI string) *httptest.ResponseRecorder {
+	sum := sha256.Sum256([]byte(testVerifier))
+	q := url.Values{"redirect_uri": {redirectURI}, "code_challenge": {base64.RawURLEncoding.EncodeToString(sum[:])}}
+	return serveHeadless(e, http.MethodGet, "/auth/faux?"+q.Encode(), nil, nil)
+}
+
+func Test_HeadlessRequiresCodeChallenge(t *testing.T) {
+	a := assert.New(t)
+
+	HeadlessRedirectURIs = []string{"http://127.0.0.1/callback"}
+	defer func() { HeadlessRedirectURIs = nil }()
+
+	e := echo.New()
+	Mount(e, "")
+
+	res := serveHeadless(e, http.MethodGet, "/auth/faux?redirect_uri="+url.QueryEscape("http://127.0.0.1/callback"), nil, nil)
+	a.Equal(http.StatusBadRequest, res.Code)
+	a.Contains(res.Body.String(), "code_challenge is required")
+}
+
+func Test_HeadlessRedirectURIMismatch(t *testing.T) {
+	a := assert.New(t)
+
+	previous := Store
+	Store = sessions.NewCookieStore([]byte("headless-test-key"))
+	HeadlessRedirectURIs = []string{"http://127.0.0.1/callback"}
+	defer func() {
+		Store = previous
+		HeadlessRedirectURIs = nil
+	}()
+
+	e := echo.New()
+	Mount(e, "")
+
+	res := beginHeadless(e, "http://127.0.0.1:51004/callback")
+	authURL, _ := url.Parse(res.Header().Get(echo.HeaderLocation))
+	res = serveHeadless(e, http.MethodGet, "/auth/faux/callback?state="+authURL.Query().Get("state"), res.Result().Cookies(), nil)
+	back, _ := url.Parse(res.Header().Get(echo.HeaderLocation))
+
+	form := url.Values{
+		"code":          {back.Query().Get("code")},
+		"code_verifier": {testVerifier},
+		"redirect_uri":  {"http://127.0.0.1:51005/callback"},
+	}
+	res = serveHeadless(e, http.MethodPost, "/auth/_gothic/token", nil, form)
+	a.Equal(http.StatusBadRequest, res.Code)
+	a.Contains(res.Body.String(), "invalid_grant")
+}
+
+func Test_HeadlessAbandoned(t *testing.T) {
+	a := assert.New(t)
+
+	previous := Store
+	Store = sessions.NewCookieStore([]byte("headless-test-key"))
+	HeadlessRedirectURIs = []string{"http://127.0.0.1/callback"}
+	SuccessURL = "/welcome"
+	defer func() {
+		Store = previous
+		HeadlessRedirectURIs = nil
+		SuccessURL = "/"
+	}()
+
+	e := echo.New()
+	Mount(e, "")
+
+	// The native application never completes the login it started, and the
+	// user then logs in to the website in the same browser.
+	res := beginHeadless(e, "http://127.0.0.1:51004/callback")
+	cookies := res.Result().Cookies()
+	res = serveHeadless(e, http.MethodGet, "/auth/faux", cookies, nil)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	cookies = append(cookies, res.Result().Cookies()...)
+	authURL, _ := url.Parse(res.Header().Get(echo.HeaderLocation))
+
+	res = serveHeadless(e, http.MethodGet, "/auth/faux/callback?state="+authURL.Query().Get("state"), cookies, nil)
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+}
@@ -0,0 +1,194 @@
+package gothic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// Support staff can act as a user, to see what they see, through an
+// impersonation: it is kept in the gothic session of the administrator, lasts
+// ImpersonationTTL at most, carries the reason it was started for and the
+// scopes it grants, and is recorded in RecentOutcomes and with
+// OnImpersonation. RequireAuth hands the impersonated user to the handlers
+// when its Impersonation option is set, with ImpersonatedBy set.
+//
+//	imp, err := gothic.StartImpersonation(c, admin, user, gothic.ImpersonationOptions{
+//		Reason: "ticket #1234",
+//		Scopes: []string{"read"},
+//	})
+
+// ImpersonationTTL is the longest an impersonation lasts.
+var ImpersonationTTL = 30 * time.Minute
+
+// impersonationKey is the gothic session key holding the impersonation.
+const impersonationKey = "_gothic_impersonation"
+
+// Actions reported to OnImpersonation.
+const (
+	ImpersonationStarted = "started"
+	ImpersonationStopped = "stopped"
+	ImpersonationExpired = "expired"
+)
+
+// OnImpersonation, when set, is called when an impersonation starts, stops
+// or expires, the place to write it to a durable audit log. When it returns an
+// error as the impersonation starts, the impersonation is refused.
+var OnImpersonation func(c echo.Context, action string, imp *Impersonation) error
+
+var (
+	// ErrImpersonationReason is returned by StartImpersonation when no reason
+	// is given.
+	ErrImpersonationReason = errors.New("gothic: an impersonation needs a reason")
+	// ErrAlreadyImpersonating is returned by StartImpersonation when an
+	// impersonation is already running.
+	ErrAlreadyImpersonating = errors.New("gothic: an impersonation is already running")
+)
+
+// ImpersonationOptions describes the impersonation to start.
+type ImpersonationOptions struct {
+	// Reason tells why the user is impersonated, a ticket number for
+	// instance. It is required.
+	Reason string
+	// Scopes are what the impersonation allows, see Impersonation.Allows and
+	// RequireAuthOptions.ImpersonationScope.
+	Scopes []string
+	// TTL is how long the impersonation lasts, ImpersonationTTL when zero or
+	// longer.
+	TTL time.Duration
+}
+
+// Impersonation is an administrator acting as a user.
+type Impersonation struct {
+	ID        string    `json:"id"`
+	Admin     goth.User `json:"admin"`
+	User      goth.User `json:"user"`
+	Reason    string    `json:"reason"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Allows reports whether the impersonation grants scope.
+func (i *Impersonation) Allows(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Impersonated returns the impersonated user, without tokens and with
+// ImpersonatedBy set to the ID of the administrator.
+func (i *Impersonation) Impersonated() goth.User {
+	u := i.User.Redacted()
+	u.ImpersonatedBy = i.Admin.UserID
+	return u
+}
+
+// StartImpersonation starts the impersonation of user by admin, the user
+// logged in to the application. The tokens of both users are left out of the
+// impersonation.
+func StartImpersonation(c echo.Context, admin, user goth.User, opts ImpersonationOptions) (*Impersonation, error) {
+	if opts.Reason == "" {
+		return nil, ErrImpersonationReason
+	}
+	if _, ok := GetImpersonation(c); ok {
+		return nil, ErrAlreadyImpersonating
+	}
+	ttl := opts.TTL
+	if ttl <= 0 || ttl > ImpersonationTTL {
+		ttl = ImpersonationTTL
+	}
+
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(RandReader, b); err != nil {
+		return nil, fmt.Errorf("gothic: source of randomness unavailable: %v", err)
+	}
+	now := goth.Now()
+	imp := &Impersonation{
+		ID:        base64.RawURLEncoding.EncodeToString(b),
+		Admin:     admin.Redacted(),
+		User:      user.Redacted(),
+		Reason:    opts.Reason,
+		Scopes:    opts.Scopes,
+		StartedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if OnImpersonation != nil {
+		if err := OnImpersonation(c, ImpersonationStarted, imp); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := json.Marshal(imp)
+	if err != nil {
+		return nil, err
+	}
+	if err := StoreInSession(impersonationKey, string(value), c); err != nil {
+		return nil, err
+	}
+	logf(c, "%s impersonates %s/%s: %s", admin.UserID, user.Provider, user.UserID, opts.Reason)
+	if RecentOutcomes != nil {
+		RecentOutcomes.Add(AuthOutcome{
+			Time:           now,
+			Provider:       user.Provider,
+			UserID:         user.UserID,
+			RequestID:      RequestID(c),
+			ImpersonatedBy: admin.UserID,
+		})
+	}
+	return imp, nil
+}
+
+// GetImpersonation returns the impersonation running in the request, ending
+// it once expired.
+func GetImpersonation(c echo.Context) (*Impersonation, bool) {
+	value, err := GetFromSession(impersonationKey, c)
+	if err != nil {
+		return nil, false
+	}
+	imp := &Impersonation{}
+	if err := json.Unmarshal([]byte(value), imp); err != nil {
+		return nil, false
+	}
+	if goth.Now().After(imp.ExpiresAt) {
+		if err := endImpersonation(c, ImpersonationExpired, imp); err != nil {
+			c.Logger().Error(err)
+		}
+		return nil, false
+	}
+	return imp, true
+}
+
+// StopImpersonation ends the impersonation running in the request, if any.
+func StopImpersonation(c echo.Context) error {
+	imp, ok := GetImpersonation(c)
+	if !ok {
+		return nil
+	}
+	return endImpersonation(c, ImpersonationStopped, imp)
+}
+
+func endImpersonation(c echo.Context, action string, imp *Impersonation) error {
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
+	delete(sess.Values, impersonationKey)
+	if err := sess.Save(c.Request(), c.Response()); err != nil {
+		return err
+	}
+	logf(c, "impersonation of %s/%s by %s %s", imp.User.Provider, imp.User.UserID, imp.Admin.UserID, action)
+	if OnImpersonation != nil {
+		return OnImpersonation(c, action, imp)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package gothic_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	supportAdmin = goth.User{Provider: "faux", UserID: "admin", AccessToken: "admin-token"}
+	customer     = goth.User{Provider: "faux", UserID: "42", Name: "Homer Simpson", AccessToken: "user-token"}
+)
+
+func Test_Impersonation(t *testing.T) {
+	a := assert.New(t)
+
+	var actions []string
+	OnImpersonation = func(c echo.Context, action string, imp *Impersonation) error {
+		actions = append(actions, action)
+		return nil
+	}
+	defer func() { OnImpersonation = nil }()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/impersonate", nil)
+	c := newContext(req, httptest.NewRecorder())
+
+	_, err := StartImpersonation(c, supportAdmin, customer, ImpersonationOptions{})
+	a.Equal(ErrImpersonationReason, err)
+
+	imp, err := StartImpersonation(c, supportAdmin, customer, ImpersonationOptions{
+		Reason: "ticket #1234",
+		Scopes: []string{"read"},
+		TTL:    24 * time.Hour,
+	})
+	a.NoError(err)
+	a.NotEmpty(imp.ID)
+	a.Equal(ImpersonationTTL, imp.ExpiresAt.Sub(imp.StartedAt))
+	a.Empty(imp.Admin.AccessToken)
+	a.True(imp.Allows("read"))
+	a.False(imp.Allows("write"))
+
+	_, err = StartImpersonation(c, supportAdmin, customer, ImpersonationOptions{Reason: "again"})
+	a.Equal(ErrAlreadyImpersonating, err)
+
+	running, ok := GetImpersonation(c)
+	a.True(ok)
+	a.Equal(imp.ID, running.ID)
+	user := running.Impersonated()
+	a.Equal("42", user.UserID)
+	a.Equal("admin", user.ImpersonatedBy)
+	a.Empty(user.AccessToken)
+
+	outcome := RecentOutcomes.List()[0]
+	a.Equal("42", outcome.UserID)
+	a.Equal("admin", outcome.ImpersonatedBy)
+
+	a.NoError(StopImpersonation(c))
+	_, ok = GetImpersonation(c)
+	a.False(ok)
+	a.Equal([]string{ImpersonationStarted, ImpersonationStopped}, actions)
+}
+
+func Test_ImpersonationExpiryAndRefusal(t *testing.T) {
+	a := assert.New(t)
+
+	var actions []string
+	OnImpersonation = func(c echo.Context, action string, imp *Impersonation) error {
+		actions = append(actions, action)
+		if imp.Reason == "curiosity" {
+			return errors.New("not a valid reason")
+		}
+		return nil
+	}
+	defer func() { OnImpersonation = nil }()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/impersonate", nil)
+	c := newContext(req, httptest.NewRecorder())
+
+	_, err := StartImpersonation(c, supportAdmin, customer, ImpersonationOptions{Reason: "curiosity"})
+	a.EqualError(err, "not a valid reason")
+	_, ok := GetImpersonation(c)
+	a.False(ok)
+
+	_, err = StartImpersonation(c, supportAdmin, customer, ImpersonationOptions{Reason: "ticket #1234"})
+	a.NoError(err)
+
+	now := time.Now()
+	goth.Clock = func() time.Time { return now.Add(time.Hour) }
+	defer func() { goth.Clock = time.Now }()
+
+	_, ok = GetImpersonation(c)
+	a.False(ok)
+	a.Equal([]string{ImpersonationStarted, ImpersonationStarted, ImpersonationExpired}, actions)
+}
+
+func Test_RequireAuthImpersonation(t *testing.T) {
+	a := assert.New(t)
+
+	loggedIn := supportAdmin
+	loader := UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+		return loggedIn, true, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/account", nil)
+	_, err := StartImpersonation(newContext(req, httptest.NewRecorder()), supportAdmin, customer, ImpersonationOptions{
+		Reason: "ticket #1234",
+		Scopes: []string{"read"},
+	})
+	a.NoError(err)
+
+	res, err := serveRequireAuth(RequireAuthOptions{Loader: loader}, req)
+	a.NoError(err)
+	a.Equal("", res.Body.String())
+
+	res, err = serveRequireAuth(RequireAuthOptions{Loader: loader, Impersonation: true, ImpersonationScope: "read"}, req)
+	a.NoError(err)
+	a.Equal("Homer Simpson", res.Body.String())
+
+	_, err = serveRequireAuth(RequireAuthOptions{Loader: loader, Impersonation: true, ImpersonationScope: "write"}, req)
+	var httpErr *echo.HTTPError
+	a.True(errors.As(err, &httpErr))
+	a.Equal(http.StatusForbidden, httpErr.Code)
+
+	// The impersonation only applies to the administrator who started it.
+	loggedIn = goth.User{Provider: "faux", UserID: "someone-else", Name: "Marge Simpson"}
+	res, err = serveRequireAuth(RequireAuthOptions{Loader: loader, Impersonation: true}, req)
+	a.NoError(err)
+	a.Equal("Marge Simpson", res.Body.String())
+}
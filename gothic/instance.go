@@ -0,0 +1,122 @@
+package gothic
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// Logins with the providers of goth.Instances, such as Mastodon, need the
+// instance of the user. The login page asks for it and opens
+//
+//	/auth/<provider>?instance=<instance URL>
+//
+// BeginAuthHandler resolves the provider of the instance, and keeps the
+// instance in the gothic session for the callback. As user IDs are only
+// unique on their instance, the users are returned with IDs naming it, see
+// goth.InstanceUserID, and with the instance in
+// RawData[goth.RawDataInstance].
+
+// InstanceParam is the parameter BeginAuthHandler reads the instance from.
+const InstanceParam = "instance"
+
+// InstanceFormURL, when set, is where BeginAuthHandler redirects the logins
+// with the providers of goth.Instances that did not name an instance, with
+// the name of the provider in the provider parameter. It is the page asking
+// the users for their instance.
+var InstanceFormURL string
+
+// ErrInstanceRequired is returned when a login with the providers of
+// goth.Instances did not name an instance.
+var ErrInstanceRequired = errors.New("gothic: an instance is required to log in with this provider")
+
+// instanceKey is the gothic session key holding the instance between the
+// start of the login and its callback.
+const instanceKey = "_gothic_instance"
+
+// instanceContextKey is the echo context key the instance of the login is
+// recorded under, to be added to the user.
+const instanceContextKey = "_gothic_instance"
+
+// beginInstance returns the provider of the instance named by the request,
+// and keeps the instance in the session for the callback.
+func beginInstance(c echo.Context, instances *goth.Instances) (goth.Provider, error) {
+	instanceURL := c.QueryParam(InstanceParam)
+	if instanceURL == "" {
+		instanceURL = c.FormValue(InstanceParam)
+	}
+	if instanceURL == "" {
+		return nil, ErrInstanceRequired
+	}
+	instanceURL, err := goth.NormalizeInstanceURL(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := instances.Provider(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := StoreInSession(instanceKey, instanceURL, c); err != nil {
+		return nil, err
+	}
+	c.Set(instanceContextKey, instanceURL)
+	return provider, nil
+}
+
+// completeInstance returns the provider of the instance the login was begun
+// with.
+func completeInstance(c echo.Context, instances *goth.Instances) (goth.Provider, error) {
+	instanceURL, err := GetFromSession(instanceKey, c)
+	if err != nil {
+		return nil, ErrInstanceRequired
+	}
+	provider, err := instances.Provider(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(instanceContextKey, instanceURL)
+	return provider, nil
+}
+
+// withInstance adds the instance of the login, if any, to the ID and the
+// RawData of user, so that the users of different instances never share an
+// ID.
+func withInstance(c echo.Context, user goth.User) goth.User {
+	instanceURL, ok := c.Get(instanceContextKey).(string)
+	if !ok {
+		return user
+	}
+	raw := make(map[string]interface{}, len(user.RawData)+1)
+	for k, v := range user.RawData {
+		raw[k] = v
+	}
+	raw[goth.RawDataInstance] = instanceURL
+	user.RawData = raw
+	user.UserID = goth.InstanceUserID(instanceURL, user.UserID)
+	return user
+}
+
+// redirectToInstanceForm sends the login to InstanceFormURL, when it is set
+// and the login did not name an instance.
+func redirectToInstanceForm(c echo.Context, err error) (bool, error) {
+	if InstanceFormURL == "" || !errors.Is(err, ErrInstanceRequired) {
+		return false, nil
+	}
+	providerName, nameErr := GetProviderName(c)
+	if nameErr != nil {
+		return false, nil
+	}
+
+	u, parseErr := url.Parse(InstanceFormURL)
+	if parseErr != nil {
+		return true, parseErr
+	}
+	q := u.Query()
+	q.Set("provider", providerName)
+	u.RawQuery = q.Encode()
+	return true, c.Redirect(http.StatusFound, u.String())
+}
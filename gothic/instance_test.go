@@ -0,0 +1,78 @@
+package gothic_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/mastodon"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InstanceLogin(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer","scope":"read"}`)
+		case "/api/v1/accounts/verify_credentials":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"id":"109302","username":"homer","display_name":"Homer Simpson"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	goth.DefaultHTTPClient = ts.Client()
+	defer func() { goth.DefaultHTTPClient = nil }()
+
+	instances := mastodon.NewInstances("goth", "/auth/mastodon/callback", func(instanceURL string) (string, string, error) {
+		return "key", "secret", nil
+	})
+	goth.UseInstances(instances)
+	defer goth.ClearInstances()
+
+	// No instance is allowed by default, and the private ones are refused.
+	for _, allow := range []func(string) bool{nil, goth.AllowInstanceURLs(ts.URL)} {
+		instances.Allow = allow
+		req := httptest.NewRequest("GET", "/auth?provider=mastodon&instance="+url.QueryEscape(ts.URL), nil)
+		res := httptest.NewRecorder()
+		a.NoError(BeginAuthHandler(newContext(req, res)))
+		a.Equal(http.StatusBadRequest, res.Code)
+	}
+	instances.AllowPrivateAddresses = true
+
+	// Logins without an instance are sent to the page asking for it.
+	InstanceFormURL = "/login/instance"
+	defer func() { InstanceFormURL = "" }()
+	req := httptest.NewRequest("GET", "/auth?provider=mastodon", nil)
+	res := httptest.NewRecorder()
+	a.NoError(BeginAuthHandler(newContext(req, res)))
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/login/instance?provider=mastodon", res.Header().Get(echo.HeaderLocation))
+
+	req = httptest.NewRequest("GET", "/auth?provider=mastodon&instance="+url.QueryEscape(strings.ToUpper(ts.URL)+"/"), nil)
+	res = httptest.NewRecorder()
+	a.NoError(BeginAuthHandler(newContext(req, res)))
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	location, err := url.Parse(res.Header().Get(echo.HeaderLocation))
+	a.NoError(err)
+	a.Equal(ts.URL+"/oauth/authorize", location.Scheme+"://"+location.Host+location.Path)
+
+	// The callback goes to the instance the login was begun with.
+	req.URL.RawQuery = url.Values{"provider": {"mastodon"}, "code": {"code"}, "state": {location.Query().Get("state")}}.Encode()
+	user, err := CompleteUserAuth(newContext(req, httptest.NewRecorder()))
+	a.NoError(err)
+	a.Equal("mastodon", user.Provider)
+	a.Equal("109302@"+strings.TrimPrefix(ts.URL, "https://"), user.UserID)
+	a.Equal("Homer Simpson", user.Name)
+	a.Equal(ts.URL, user.RawData[goth.RawDataInstance])
+}
@@ -0,0 +1,172 @@
+package gothic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// Users invited to an organization accept the invitation by logging in with
+// the link of the invitation token:
+//
+//	token, err := gothic.NewInvitationToken(gothic.Invitation{
+//		ID:           invite.ID,
+//		Organization: "acme",
+//		Email:        "homer@example.com",
+//		Role:         "member",
+//		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
+//	})
+//	link := "https://app.example.com/auth/google?invite=" + token
+//
+// GetAuthURL carries the token through the state of the login, and
+// CallbackHandler validates it before handing the user to OnAuthSuccess, which
+// reads it with GetInvitation:
+//
+//	gothic.OnAuthSuccess = func(c echo.Context, user goth.User) error {
+//		if inv, ok := gothic.GetInvitation(c); ok {
+//			return db.AcceptInvitation(inv.ID, user)
+//		}
+//		...
+//	}
+//
+// The tokens can be used until they expire: OnAuthSuccess should refuse the
+// invitations already accepted.
+
+// InvitationParam is the parameter GetAuthURL reads the invitation token from.
+const InvitationParam = "invite"
+
+// InvitationSecret signs the invitation tokens. Changing it invalidates the
+// tokens handed out.
+var InvitationSecret []byte
+
+var (
+	// ErrInvitationInvalid is returned for invitation tokens that are
+	// malformed or not signed with InvitationSecret.
+	ErrInvitationInvalid = errors.New("gothic: invalid invitation")
+	// ErrInvitationExpired is returned for expired invitation tokens.
+	ErrInvitationExpired = errors.New("gothic: the invitation has expired")
+	// ErrInvitationEmail fails the logins accepting an invitation for another
+	// email than the one of the user, or whose provider did not verify it.
+	ErrInvitationEmail = errors.New("gothic: the invitation is for another email")
+)
+
+// Invitation is an invitation to join an organization.
+type Invitation struct {
+	ID           string `json:"id"`
+	Organization string `json:"org,omitempty"`
+	// Email, when set, is the only email the invitation can be accepted
+	// with, compared case-insensitively with the email of the user, which
+	// the provider must have verified.
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	InvitedBy string    `json:"by,omitempty"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// invitationContextKey is the echo context key the invitation accepted by a
+// login is recorded under.
+const invitationContextKey = "_gothic_invitation"
+
+// NewInvitationToken returns the signed token of inv, to be sent to the
+// invited user in a login link with the InvitationParam parameter.
+func NewInvitationToken(inv Invitation) (string, error) {
+	if len(InvitationSecret) == 0 {
+		return "", errors.New("gothic: InvitationSecret is not set")
+	}
+	if inv.ID == "" || inv.ExpiresAt.IsZero() {
+		return "", errors.New("gothic: an invitation needs an ID and an expiry")
+	}
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signInvitation(encoded), nil
+}
+
+// ParseInvitationToken validates token and returns its invitation.
+func ParseInvitationToken(token string) (*Invitation, error) {
+	i := strings.IndexByte(token, '.')
+	if i < 0 || len(InvitationSecret) == 0 {
+		return nil, ErrInvitationInvalid
+	}
+	encoded, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(signInvitation(encoded))) {
+		return nil, ErrInvitationInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvitationInvalid
+	}
+	inv := &Invitation{}
+	if err := json.Unmarshal(payload, inv); err != nil {
+		return nil, ErrInvitationInvalid
+	}
+	if goth.Now().After(inv.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	return inv, nil
+}
+
+func signInvitation(encoded string) string {
+	mac := hmac.New(sha256.New, InvitationSecret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// GetInvitation returns the invitation accepted by the login completed by c,
+// for OnAuthSuccess.
+func GetInvitation(c echo.Context) (*Invitation, bool) {
+	inv, ok := c.Get(invitationContextKey).(*Invitation)
+	return inv, ok
+}
+
+// withInvitation adds the invitation token of the request, validated, to
+// state.
+func withInvitation(c echo.Context, state string) (string, error) {
+	token := c.QueryParam(InvitationParam)
+	if token == "" {
+		return state, nil
+	}
+	if _, err := ParseInvitationToken(token); err != nil {
+		return "", err
+	}
+	return state + "." + token, nil
+}
+
+// acceptInvitation validates the invitation carried by the state of the
+// callback, when any, for user, and records it for GetInvitation. The state
+// has already been checked against the session, so the token is the one
+// GetAuthURL added.
+func acceptInvitation(c echo.Context, user goth.User) error {
+	state := GetState(c)
+	sig := strings.LastIndexByte(state, '.')
+	if sig < 0 {
+		return nil
+	}
+	payload := strings.LastIndexByte(state[:sig], '.')
+	if payload < 0 {
+		return nil
+	}
+	inv, err := ParseInvitationToken(state[payload+1:])
+	if err == ErrInvitationInvalid {
+		// a state of SetState with dots in it
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// An unverified email is whatever the user typed at the provider.
+	if inv.Email != "" && (!user.EmailVerified || !strings.EqualFold(inv.Email, user.Email)) {
+		return ErrInvitationEmail
+	}
+	c.Set(invitationContextKey, inv)
+	return nil
+}
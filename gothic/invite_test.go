@@ -0,0 +1,121 @@
+package gothic_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InvitationToken(t *testing.T) {
+	a := assert.New(t)
+
+	inv := Invitation{ID: "inv1", Organization: "acme", Role: "member", ExpiresAt: time.Now().Add(time.Hour)}
+	_, err := NewInvitationToken(inv)
+	a.Error(err)
+
+	InvitationSecret = []byte("secret")
+	defer func() { InvitationSecret = nil }()
+
+	_, err = NewInvitationToken(Invitation{ID: "inv1"})
+	a.Error(err)
+	token, err := NewInvitationToken(inv)
+	a.NoError(err)
+
+	parsed, err := ParseInvitationToken(token)
+	a.NoError(err)
+	a.Equal("acme", parsed.Organization)
+	a.Equal("member", parsed.Role)
+
+	_, err = ParseInvitationToken(token + "x")
+	a.Equal(ErrInvitationInvalid, err)
+	_, err = ParseInvitationToken("garbage")
+	a.Equal(ErrInvitationInvalid, err)
+
+	now := time.Now()
+	goth.Clock = func() time.Time { return now.Add(2 * time.Hour) }
+	defer func() { goth.Clock = time.Now }()
+	_, err = ParseInvitationToken(token)
+	a.Equal(ErrInvitationExpired, err)
+}
+
+// serveInvitedCallback completes a login begun with the invitation token.
+func serveInvitedCallback(t *testing.T, token, email string, verified bool) *httptest.ResponseRecorder {
+	a := assert.New(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth?provider=faux&invite="+url.QueryEscape(token), nil)
+	authURL, err := GetAuthURL(newContext(req, httptest.NewRecorder()))
+	a.NoError(err)
+	u, _ := url.Parse(authURL)
+	state := u.Query().Get("state")
+	a.True(strings.HasSuffix(state, "."+token))
+
+	e := echo.New()
+	Mount(e, "")
+	callback := httptest.NewRequest(http.MethodGet, "/auth/faux/callback?state="+url.QueryEscape(state), nil)
+	sess := faux.Session{Name: "Homer Simpson", Email: email, EmailVerified: verified, AuthURL: authURL}
+	session, _ := Store.Get(callback, SessionName)
+	session.Values["faux"] = gzipString(sess.Marshal())
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, callback)
+	return res
+}
+
+func Test_InvitationLogin(t *testing.T) {
+	a := assert.New(t)
+
+	InvitationSecret = []byte("secret")
+	SuccessURL, FailureURL = "/welcome", "/login"
+	var accepted *Invitation
+	OnAuthSuccess = func(c echo.Context, user goth.User) error {
+		accepted, _ = GetInvitation(c)
+		return nil
+	}
+	var failure error
+	OnAuthFailure = func(c echo.Context, err error) {
+		failure = err
+	}
+	defer func() {
+		InvitationSecret = nil
+		SuccessURL, FailureURL = "/", "/"
+		OnAuthSuccess, OnAuthFailure = nil, nil
+	}()
+
+	token, err := NewInvitationToken(Invitation{ID: "inv1", Email: "Homer@example.com", ExpiresAt: time.Now().Add(time.Hour)})
+	a.NoError(err)
+
+	res := serveInvitedCallback(t, token, "homer@example.com", true)
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+	a.NotNil(accepted)
+	a.Equal("inv1", accepted.ID)
+
+	accepted = nil
+	res = serveInvitedCallback(t, token, "bart@example.com", true)
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Equal(ErrInvitationEmail, failure)
+	a.Nil(accepted)
+
+	// The email must have been verified by the provider.
+	failure = nil
+	res = serveInvitedCallback(t, token, "homer@example.com", false)
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Equal(ErrInvitationEmail, failure)
+	a.Nil(accepted)
+
+	// Logins without an invitation have none.
+	res, _ = serveCallback(t, true)
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+	a.Nil(accepted)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth?provider=faux&invite=forged.token", nil)
+	_, err = GetAuthURL(newContext(req, httptest.NewRecorder()))
+	a.Equal(ErrInvitationInvalid, err)
+}
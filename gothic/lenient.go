@@ -0,0 +1,44 @@
+package gothic
+
+import (
+	"errors"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// LenientUserFetch, when set, keeps CompleteUserAuth from failing the logins
+// whose tokens the provider issued but whose user it then failed to return,
+// for instance while its user API is down: the login completes with the
+// partial user built from the tokens by goth.PartialUser, and the failure is
+// logged and returned by GetUserFetchWarning, for OnAuthSuccess to refresh the
+// profile of the user later. Logins whose ID token does not identify the user
+// still fail.
+var LenientUserFetch = false
+
+// userFetchWarningContextKey is the echo context key the failure behind a
+// partial user is recorded under.
+const userFetchWarningContextKey = "_gothic_user_fetch_warning"
+
+// GetUserFetchWarning returns why the user completed by c is a partial one,
+// see LenientUserFetch.
+func GetUserFetchWarning(c echo.Context) (*goth.PartialUserError, bool) {
+	warning, ok := c.Get(userFetchWarningContextKey).(*goth.PartialUserError)
+	return warning, ok
+}
+
+// lenientUser returns the partial user of sess when LenientUserFetch is set,
+// and err otherwise.
+func lenientUser(c echo.Context, provider goth.Provider, sess goth.Session, err error) (goth.User, error) {
+	if !LenientUserFetch {
+		return goth.User{}, err
+	}
+	user, err := goth.PartialUser(provider, sess, err)
+	var warning *goth.PartialUserError
+	if !errors.As(err, &warning) {
+		return user, err
+	}
+	c.Logger().Warnf("%s/%s logged in with a partial user: %v", user.Provider, user.UserID, warning.Err)
+	c.Set(userFetchWarningContextKey, warning)
+	return user, nil
+}
@@ -0,0 +1,93 @@
+package gothic_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// brokenUserInfoProvider issues tokens, with an ID token, but fails to return
+// the user.
+type brokenUserInfoProvider struct {
+	faux.Provider
+}
+
+type idTokenSession struct {
+	faux.Session
+	RefreshToken string
+	ExpiresAt    time.Time
+	IDToken      string
+}
+
+func (s *idTokenSession) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (p *brokenUserInfoProvider) UnmarshalSession(data string) (goth.Session, error) {
+	sess := &idTokenSession{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(sess)
+	return sess, err
+}
+
+func (p *brokenUserInfoProvider) FetchUser(session goth.Session) (goth.User, error) {
+	return goth.User{}, errors.New("userinfo is down")
+}
+
+func (p *brokenUserInfoProvider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	return p
+}
+
+func Test_LenientUserFetch(t *testing.T) {
+	a := assert.New(t)
+
+	p := &brokenUserInfoProvider{}
+	p.SetName("broken")
+	goth.UseProviders(p)
+
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "42",
+		"email": "homer@example.com",
+	}).SignedString([]byte("secret"))
+	a.NoError(err)
+
+	complete := func() (echo.Context, goth.User, error) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/auth/callback?provider=broken", nil)
+		sess := &idTokenSession{IDToken: idToken}
+		session, _ := Store.Get(req, SessionName)
+		session.Values["broken"] = gzipString(sess.Marshal())
+		a.NoError(session.Save(req, res))
+		c := newContext(req, res)
+		user, err := CompleteUserAuth(c)
+		return c, user, err
+	}
+
+	c, _, err := complete()
+	a.EqualError(err, "userinfo is down")
+	_, ok := GetUserFetchWarning(c)
+	a.False(ok)
+
+	LenientUserFetch = true
+	defer func() { LenientUserFetch = false }()
+	c, user, err := complete()
+	a.NoError(err)
+	a.Equal("broken", user.Provider)
+	a.Equal("42", user.UserID)
+	a.Equal("homer@example.com", user.Email)
+	a.Equal("access", user.AccessToken)
+	warning, ok := GetUserFetchWarning(c)
+	a.True(ok)
+	a.EqualError(warning.Err, "userinfo is down")
+}
@@ -0,0 +1,379 @@
+package gothic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/local"
+	"github.com/labstack/echo/v4"
+)
+
+// A login can require a second factor once the provider has authenticated the
+// user. When MFARequired names factors for the user, CallbackHandler keeps the
+// user in a pending login, in the gothic session, and redirects to MFAURL, the
+// page of the application asking for one of them. The page posts the factor
+// to MFAHandler, which checks it with the MFAVerifier registered for it and
+// completes the login as CallbackHandler would have, handing the user to
+// OnAuthSuccess:
+//
+//	gothic.MFARequired = func(c echo.Context, user goth.User) ([]string, error) {
+//		if db.TOTPSecret(user.UserID) == "" {
+//			return nil, nil
+//		}
+//		return []string{gothic.FactorTOTP}, nil
+//	}
+//	gothic.MFAVerifiers[gothic.FactorTOTP] = gothic.TOTPVerifier(func(c echo.Context, user goth.User) (string, error) {
+//		return db.TOTPSecret(user.UserID), nil
+//	})
+
+// Factors with a well known name. WebAuthn has no verifier in gothic: register
+// one backed by a WebAuthn library, implementing MFAChallenger to hand out the
+// assertion options.
+const (
+	FactorTOTP     = "totp"
+	FactorWebAuthn = "webauthn"
+)
+
+var (
+	// MFAURL is where CallbackHandler redirects users who need a second
+	// factor. The page reads the factors they can use with GetPendingMFA.
+	MFAURL = "/auth/mfa"
+
+	// MFATimeout is how long users have to complete a pending login.
+	MFATimeout = 5 * time.Minute
+
+	// MFAMaxAttempts is how many wrong factors end a pending login.
+	MFAMaxAttempts = 5
+
+	// MFAAttempts counts the wrong factors of the pending logins server
+	// side, so that replaying an older session cookie does not reset the
+	// count. Replace it with a shared implementation when running more than
+	// one instance of the application.
+	MFAAttempts MFAAttemptStore = NewMemoryMFAAttemptStore()
+
+	// MFARequired, when set, is called by CallbackHandler with every
+	// authenticated user. It returns the factors the user can complete the
+	// login with, none when no second factor is required.
+	MFARequired func(c echo.Context, user goth.User) ([]string, error)
+
+	// MFAVerifiers are the verifiers of the factors, by name.
+	MFAVerifiers = map[string]MFAVerifier{}
+)
+
+var (
+	// ErrNoPendingMFA is the error of MFAHandler when no login is waiting
+	// for a second factor, or it timed out.
+	ErrNoPendingMFA = errors.New("gothic: no login is waiting for a second factor")
+	// ErrInvalidFactor is returned by MFAVerifiers for wrong factors, which
+	// the user can try again, up to MFAMaxAttempts.
+	ErrInvalidFactor = errors.New("gothic: invalid second factor")
+	// ErrTooManyFactorAttempts ends the logins with MFAMaxAttempts wrong
+	// factors.
+	ErrTooManyFactorAttempts = errors.New("gothic: too many wrong second factors")
+)
+
+// MFAAttemptStore counts the wrong factors of the pending logins, keyed by
+// the nonce of each login. A pending login without a record is refused.
+type MFAAttemptStore interface {
+	// Begin records a pending login, without wrong factors, until expiresAt.
+	Begin(nonce string, expiresAt time.Time) error
+	// Attempts returns how many wrong factors the pending login had, ok
+	// being false when there is no record of it.
+	Attempts(nonce string) (attempts int, ok bool, err error)
+	// Fail counts a wrong factor and returns how many the pending login had,
+	// ok being false when there is no record of it.
+	Fail(nonce string) (attempts int, ok bool, err error)
+	// End removes the record of a completed login.
+	End(nonce string) error
+}
+
+// MFAVerifier checks the second factor posted by user to MFAHandler. It
+// returns ErrInvalidFactor when the factor is wrong.
+type MFAVerifier interface {
+	VerifyFactor(c echo.Context, user goth.User) error
+}
+
+// MFAVerifierFunc adapts a function to the MFAVerifier interface.
+type MFAVerifierFunc func(c echo.Context, user goth.User) error
+
+// VerifyFactor calls f(c, user).
+func (f MFAVerifierFunc) VerifyFactor(c echo.Context, user goth.User) error {
+	return f(c, user)
+}
+
+// MFAChallenger is implemented by the MFAVerifiers of factors needing a
+// challenge before they are checked, such as WebAuthn assertions. MFAHandler
+// serves the challenge as JSON on GET.
+type MFAChallenger interface {
+	ChallengeFactor(c echo.Context, user goth.User) (interface{}, error)
+}
+
+// TOTPVerifier returns the verifier of the codes of authenticator apps, posted
+// as the code form value. secret returns the base32 secret of user.
+func TOTPVerifier(secret func(c echo.Context, user goth.User) (string, error)) MFAVerifier {
+	return MFAVerifierFunc(func(c echo.Context, user goth.User) error {
+		s, err := secret(c, user)
+		if err != nil {
+			return err
+		}
+		if s == "" || !local.ValidTOTP(s, c.FormValue("code")) {
+			return ErrInvalidFactor
+		}
+		return nil
+	})
+}
+
+// mfaKey is the gothic session key holding the pending login.
+const mfaKey = "_gothic_mfa"
+
+// PendingMFA is a login waiting for a second factor.
+type PendingMFA struct {
+	User      goth.User `json:"user"`
+	Factors   []string  `json:"factors"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Nonce names the record of the wrong factors in MFAAttempts.
+	Nonce string `json:"nonce"`
+
+	AttemptID  string           `json:"attempt_id,omitempty"`
+	Headless   *headlessRequest `json:"headless,omitempty"`
+	Invitation *Invitation      `json:"invitation,omitempty"`
+}
+
+// Allows reports whether the login can be completed with factor.
+func (p *PendingMFA) Allows(factor string) bool {
+	for _, f := range p.Factors {
+		if f == factor {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPendingMFA returns the login of the request waiting for a second factor,
+// ending it once timed out.
+func GetPendingMFA(c echo.Context) (*PendingMFA, bool) {
+	value, err := GetFromSession(mfaKey, c)
+	if err != nil {
+		return nil, false
+	}
+	pending := &PendingMFA{}
+	if err := json.Unmarshal([]byte(value), pending); err != nil {
+		return nil, false
+	}
+	if goth.Now().After(pending.ExpiresAt) {
+		if err := endMFA(c); err != nil {
+			c.Logger().Error(err)
+		}
+		return nil, false
+	}
+	return pending, true
+}
+
+// beginMFA keeps user waiting for one of factors. CompleteUserAuth has
+// cleared the session, so the session outlives the pending login.
+func beginMFA(c echo.Context, user goth.User, factors []string, headless *headlessRequest) error {
+	nonce, err := randomToken()
+	if err != nil {
+		return err
+	}
+	pending := &PendingMFA{
+		User:      user,
+		Factors:   factors,
+		ExpiresAt: goth.Now().Add(MFATimeout),
+		Nonce:     nonce,
+		AttemptID: LoginAttemptID(c),
+		Headless:  headless,
+	}
+	pending.Invitation, _ = GetInvitation(c)
+	if err := MFAAttempts.Begin(nonce, pending.ExpiresAt); err != nil {
+		return err
+	}
+	if err := saveMFA(c, pending); err != nil {
+		return err
+	}
+	logf(c, "%s/%s needs a second factor", user.Provider, user.UserID)
+	return nil
+}
+
+func saveMFA(c echo.Context, pending *PendingMFA) error {
+	value, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
+	if err := updateSessionValue(sess, mfaKey, string(value)); err != nil {
+		return err
+	}
+	if maxAge := int(MFATimeout.Seconds()); sess.Options.MaxAge < maxAge {
+		sess.Options.MaxAge = maxAge
+	}
+	return sess.Save(c.Request(), c.Response())
+}
+
+func endMFA(c echo.Context) error {
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
+	delete(sess.Values, mfaKey)
+	return sess.Save(c.Request(), c.Response())
+}
+
+// MFAHandler completes the pending login with the factor named by the
+// "factor" URL param, posted by the page at MFAURL. Wrong factors, counted by
+// MFAAttempts, send the user back to MFAURL, until MFAMaxAttempts ends the
+// login. On GET, it serves
+// the challenge of verifiers implementing MFAChallenger. LoginGuard is asked
+// about every factor at the LoginStageMFA stage.
+func MFAHandler(c echo.Context) error {
+	pending, ok := GetPendingMFA(c)
+	if !ok {
+		return completeLogin(c, goth.User{}, ErrNoPendingMFA, nil)
+	}
+	factor := c.Param("factor")
+	verifier, ok := MFAVerifiers[factor]
+	if !ok || !pending.Allows(factor) {
+		return echo.NewHTTPError(http.StatusNotFound, "unknown second factor "+factor)
+	}
+
+	if c.Request().Method == http.MethodGet {
+		challenger, ok := verifier.(MFAChallenger)
+		if !ok {
+			return echo.ErrMethodNotAllowed
+		}
+		challenge, err := challenger.ChallengeFactor(c, pending.User)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, challenge)
+	}
+
+	// The count of the wrong factors is kept by MFAAttempts, the record of
+	// a login that ran out of attempts staying there until it expires.
+	attempts, ok, err := MFAAttempts.Attempts(pending.Nonce)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !ok:
+		err = ErrNoPendingMFA
+	case attempts >= MFAMaxAttempts:
+		err = ErrTooManyFactorAttempts
+	default:
+		err = guardAttempt(c, pending.AttemptID, LoginStageMFA, pending.User.Provider)
+	}
+	if err == nil {
+		err = verifier.VerifyFactor(c, pending.User)
+	}
+	if errors.Is(err, ErrInvalidFactor) {
+		attempts, ok, failErr := MFAAttempts.Fail(pending.Nonce)
+		if failErr != nil {
+			return failErr
+		}
+		if ok && attempts < MFAMaxAttempts {
+			logf(c, "wrong %s factor for %s/%s", factor, pending.User.Provider, pending.User.UserID)
+			if err := flash(c, FlashError, "Wrong code, please try again."); err != nil {
+				return err
+			}
+			return c.Redirect(http.StatusFound, MFAURL)
+		}
+		err = ErrTooManyFactorAttempts
+		if !ok {
+			err = ErrNoPendingMFA
+		}
+	}
+
+	if endErr := endMFA(c); endErr != nil {
+		return endErr
+	}
+	if err == nil {
+		if err := MFAAttempts.End(pending.Nonce); err != nil {
+			return err
+		}
+		if err = rotateSession(c); err != nil {
+			return err
+		}
+	}
+	if pending.Invitation != nil {
+		c.Set(invitationContextKey, pending.Invitation)
+	}
+	return completeLogin(c, pending.User, err, pending.Headless)
+}
+
+// MemoryMFAAttemptStore is an in-process MFAAttemptStore. Records are dropped
+// once their pending login has expired.
+type MemoryMFAAttemptStore struct {
+	mu      sync.Mutex
+	records map[string]memoryMFAAttempts
+}
+
+type memoryMFAAttempts struct {
+	attempts  int
+	expiresAt time.Time
+}
+
+// NewMemoryMFAAttemptStore returns an in-process MFAAttemptStore.
+func NewMemoryMFAAttemptStore() *MemoryMFAAttemptStore {
+	return &MemoryMFAAttemptStore{}
+}
+
+// Begin records a pending login named nonce until expiresAt.
+func (s *MemoryMFAAttemptStore) Begin(nonce string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := goth.Now()
+	if s.records == nil {
+		s.records = map[string]memoryMFAAttempts{}
+	}
+	for k, r := range s.records {
+		if !now.Before(r.expiresAt) {
+			delete(s.records, k)
+		}
+	}
+	s.records[nonce] = memoryMFAAttempts{expiresAt: expiresAt}
+	return nil
+}
+
+// Attempts returns how many wrong factors the pending login named nonce had.
+func (s *MemoryMFAAttemptStore) Attempts(nonce string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[nonce]
+	if !ok || !goth.Now().Before(r.expiresAt) {
+		return 0, false, nil
+	}
+	return r.attempts, true, nil
+}
+
+// Fail counts a wrong factor for the pending login named nonce.
+func (s *MemoryMFAAttemptStore) Fail(nonce string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[nonce]
+	if !ok || !goth.Now().Before(r.expiresAt) {
+		return 0, false, nil
+	}
+	r.attempts++
+	s.records[nonce] = r
+	return r.attempts, true, nil
+}
+
+// End removes the record of the pending login named nonce.
+func (s *MemoryMFAAttemptStore) End(nonce string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, nonce)
+	return nil
+}
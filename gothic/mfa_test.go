@@ -0,0 +1,188 @@
+package gothic_test
+
+import (
+	"encoding/base32"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/local"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var mfaSecret = []byte("12345678901234567890")
+
+type webAuthnVerifier struct{}
+
+func (webAuthnVerifier) VerifyFactor(c echo.Context, user goth.User) error {
+	return ErrInvalidFactor
+}
+
+func (webAuthnVerifier) ChallengeFactor(c echo.Context, user goth.User) (interface{}, error) {
+	return map[string]string{"challenge": "abc", "user": user.Email}, nil
+}
+
+func setupMFA() func() {
+	SuccessURL, FailureURL = "/welcome", "/login"
+	MFARequired = func(c echo.Context, user goth.User) ([]string, error) {
+		return []string{FactorTOTP, FactorWebAuthn}, nil
+	}
+	MFAVerifiers[FactorTOTP] = TOTPVerifier(func(c echo.Context, user goth.User) (string, error) {
+		return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(mfaSecret), nil
+	})
+	MFAVerifiers[FactorWebAuthn] = webAuthnVerifier{}
+	return func() {
+		SuccessURL, FailureURL = "/", "/"
+		MFARequired, OnAuthSuccess, OnAuthFailure = nil, nil, nil
+		MFAVerifiers = map[string]MFAVerifier{}
+		MFAMaxAttempts = 5
+	}
+}
+
+// serveMFA sends code to the factor of the pending login of prev, carrying
+// its gothic session over.
+func serveMFA(prev *http.Request, method, factor, code string) (*httptest.ResponseRecorder, *http.Request) {
+	e := echo.New()
+	Mount(e, "")
+
+	req := httptest.NewRequest(method, "/auth/_gothic/mfa/"+factor, strings.NewReader(url.Values{"code": {code}}.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	from, _ := Store.Get(prev, SessionName)
+	to, _ := Store.Get(req, SessionName)
+	for k, v := range from.Values {
+		to.Values[k] = v
+	}
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	return res, req
+}
+
+func Test_MFA(t *testing.T) {
+	a := assert.New(t)
+	defer setupMFA()()
+
+	var got goth.User
+	OnAuthSuccess = func(c echo.Context, user goth.User) error {
+		got = user
+		return nil
+	}
+
+	res, req := serveCallback(t, true)
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/auth/mfa", res.Header().Get(echo.HeaderLocation))
+	a.Empty(got.Name)
+
+	pending, ok := GetPendingMFA(newContext(req, httptest.NewRecorder()))
+	a.True(ok)
+	a.Equal("Homer Simpson", pending.User.Name)
+	a.True(pending.Allows(FactorTOTP))
+
+	res, req = serveMFA(req, http.MethodPost, FactorTOTP, "000000")
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/auth/mfa", res.Header().Get(echo.HeaderLocation))
+	attempts, ok, err := MFAAttempts.Attempts(pending.Nonce)
+	a.NoError(err)
+	a.True(ok)
+	a.Equal(1, attempts)
+
+	res, _ = serveMFA(req, http.MethodPost, "sms", "000000")
+	a.Equal(http.StatusNotFound, res.Code)
+
+	res, req = serveMFA(req, http.MethodPost, FactorTOTP, local.TOTP(mfaSecret, time.Now()))
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+	a.Equal("Homer Simpson", got.Name)
+	_, ok = GetPendingMFA(newContext(req, httptest.NewRecorder()))
+	a.False(ok)
+	_, ok, _ = MFAAttempts.Attempts(pending.Nonce)
+	a.False(ok)
+
+	outcome := RecentOutcomes.List()[0]
+	a.Equal("faux", outcome.Provider)
+	a.Empty(outcome.Error)
+}
+
+func Test_MFAFailures(t *testing.T) {
+	a := assert.New(t)
+	defer setupMFA()()
+
+	var failure error
+	OnAuthFailure = func(c echo.Context, err error) {
+		failure = err
+	}
+	MFAMaxAttempts = 2
+
+	_, req := serveCallback(t, true)
+	res, req := serveMFA(req, http.MethodGet, FactorWebAuthn, "")
+	a.Equal(http.StatusOK, res.Code)
+	a.JSONEq(`{"challenge":"abc","user":"homer@example.com"}`, res.Body.String())
+	res, _ = serveMFA(req, http.MethodGet, FactorTOTP, "")
+	a.Equal(http.StatusMethodNotAllowed, res.Code)
+
+	_, req = serveMFA(req, http.MethodPost, FactorWebAuthn, "")
+	res, req = serveMFA(req, http.MethodPost, FactorTOTP, "000000")
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Equal(ErrTooManyFactorAttempts, failure)
+
+	res, _ = serveMFA(req, http.MethodPost, FactorTOTP, local.TOTP(mfaSecret, time.Now()))
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Equal(ErrNoPendingMFA, failure)
+
+	_, req = serveCallback(t, true)
+	now := time.Now()
+	goth.Clock = func() time.Time { return now.Add(MFATimeout + time.Minute) }
+	defer func() { goth.Clock = time.Now }()
+	_, ok := GetPendingMFA(newContext(req, httptest.NewRecorder()))
+	a.False(ok)
+}
+
+func Test_MFAReplayedSession(t *testing.T) {
+	a := assert.New(t)
+	defer setupMFA()()
+
+	var failure error
+	OnAuthFailure = func(c echo.Context, err error) {
+		failure = err
+	}
+	MFAMaxAttempts = 2
+
+	// The session of the pending login before any wrong factor is replayed
+	// once the attempts are used up.
+	_, start := serveCallback(t, true)
+	res, req := serveMFA(start, http.MethodPost, FactorTOTP, "000000")
+	a.Equal("/auth/mfa", res.Header().Get(echo.HeaderLocation))
+	res, _ = serveMFA(req, http.MethodPost, FactorTOTP, "000000")
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Equal(ErrTooManyFactorAttempts, failure)
+
+	failure = nil
+	res, _ = serveMFA(start, http.MethodPost, FactorTOTP, local.TOTP(mfaSecret, time.Now()))
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Equal(ErrTooManyFactorAttempts, failure)
+}
+
+func Test_MFAWithoutAttemptRecord(t *testing.T) {
+	a := assert.New(t)
+	defer setupMFA()()
+
+	var failure error
+	OnAuthFailure = func(c echo.Context, err error) {
+		failure = err
+	}
+
+	_, req := serveCallback(t, true)
+	pending, ok := GetPendingMFA(newContext(req, httptest.NewRecorder()))
+	a.True(ok)
+	a.NoError(MFAAttempts.End(pending.Nonce))
+
+	res, _ := serveMFA(req, http.MethodPost, FactorTOTP, local.TOTP(mfaSecret, time.Now()))
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Equal(ErrNoPendingMFA, failure)
+}
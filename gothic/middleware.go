@@ -0,0 +1,144 @@
+package gothic
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// UserContextKey is the echo context key RequireAuth stores the
+// authenticated goth.User under.
+const UserContextKey = "goth_user"
+
+// UserLoader loads the user of the current request from the application's
+// own session or database. gothic does not keep users around once
+// CompleteUserAuth returns, so remembering who is logged in is up to the
+// application. ok is false when nobody is logged in.
+type UserLoader interface {
+	LoadUser(c echo.Context) (user goth.User, ok bool, err error)
+}
+
+// UserLoaderFunc adapts a function to the UserLoader interface.
+type UserLoaderFunc func(c echo.Context) (goth.User, bool, error)
+
+// LoadUser calls f(c).
+func (f UserLoaderFunc) LoadUser(c echo.Context) (goth.User, bool, error) {
+	return f(c)
+}
+
+// RequireAuthOptions configures RequireAuth.
+type RequireAuthOptions struct {
+	// Loader loads the logged in user. It is required.
+	Loader UserLoader
+
+	// Provider is the provider anonymous users are sent to, through
+	// /auth/<Provider>. It is ignored when LoginURL is set.
+	Provider string
+
+	// LoginURL is where anonymous users are redirected. It defaults to
+	// /auth/<Provider>, or /auth when no provider is set.
+	LoginURL string
+
+	// API makes RequireAuth answer anonymous requests with a 401 JSON
+	// response instead of a redirect. Requests accepting JSON, or sent with
+	// XMLHttpRequest, always get the JSON response.
+	API bool
+
+	// Skipper, when it returns true, lets the request through unchecked.
+	Skipper func(c echo.Context) bool
+
+	// RememberMe, when set, logs back in the users Loader does not find but
+	// whose request carries a remember-me cookie.
+	RememberMe *RememberMe
+
+	// Impersonation hands the handlers the user impersonated by the logged
+	// in user, see StartImpersonation, instead of the logged in user.
+	Impersonation bool
+
+	// ImpersonationScope, when set, is the scope impersonations must grant
+	// for the route; others are answered with a 403.
+	ImpersonationScope string
+}
+
+// RequireAuth returns a middleware rejecting requests made by anonymous
+// users. The user returned by the loader is stored in the echo context, from
+// where handlers can get it with GetUser.
+//
+//	e.GET("/account", account, gothic.RequireAuth(gothic.RequireAuthOptions{
+//		Loader:   loadUserFromAppSession,
+//		Provider: "github",
+//	}))
+func RequireAuth(opts RequireAuthOptions) echo.MiddlewareFunc {
+	if opts.Loader == nil {
+		panic("gothic: RequireAuth needs a UserLoader")
+	}
+
+	loginURL := opts.LoginURL
+	if loginURL == "" {
+		loginURL = "/auth"
+		if opts.Provider != "" {
+			loginURL += "/" + opts.Provider
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if opts.Skipper != nil && opts.Skipper(c) {
+				return next(c)
+			}
+
+			user, ok, err := opts.Loader.LoadUser(c)
+			if err != nil {
+				return err
+			}
+			if !ok && opts.RememberMe != nil {
+				user, ok, err = opts.RememberMe.Login(c)
+				if errors.Is(err, ErrRememberMeTokenInvalid) {
+					c.Logger().Warn(err)
+				} else if err != nil {
+					return err
+				}
+			}
+			if ok && opts.Impersonation {
+				if imp, running := GetImpersonation(c); running && sameUser(imp.Admin, user) {
+					if opts.ImpersonationScope != "" && !imp.Allows(opts.ImpersonationScope) {
+						return echo.NewHTTPError(http.StatusForbidden, "the impersonation does not allow "+opts.ImpersonationScope)
+					}
+					user = imp.Impersonated()
+				}
+			}
+			if ok {
+				c.Set(UserContextKey, user)
+				return next(c)
+			}
+
+			if opts.API || wantsJSON(c.Request()) {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error":     "unauthorized",
+					"login_url": loginURL,
+				})
+			}
+			return c.Redirect(http.StatusFound, loginURL)
+		}
+	}
+}
+
+// GetUser returns the user stored in the echo context by RequireAuth.
+func GetUser(c echo.Context) (goth.User, bool) {
+	user, ok := c.Get(UserContextKey).(goth.User)
+	return user, ok
+}
+
+func sameUser(a, b goth.User) bool {
+	return a.Provider == b.Provider && a.UserID == b.UserID
+}
+
+func wantsJSON(r *http.Request) bool {
+	if r.Header.Get(echo.HeaderXRequestedWith) == "XMLHttpRequest" {
+		return true
+	}
+	return strings.Contains(r.Header.Get(echo.HeaderAccept), echo.MIMEApplicationJSON)
+}
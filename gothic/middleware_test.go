@@ -0,0 +1,79 @@
+package gothic_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveRequireAuth(opts RequireAuthOptions, req *http.Request) (*httptest.ResponseRecorder, error) {
+	res := httptest.NewRecorder()
+	c := echo.New().NewContext(req, res)
+	h := RequireAuth(opts)(func(c echo.Context) error {
+		user, ok := GetUser(c)
+		if !ok {
+			return errors.New("no user in context")
+		}
+		return c.String(http.StatusOK, user.Name)
+	})
+	return res, h(c)
+}
+
+func Test_RequireAuth(t *testing.T) {
+	a := assert.New(t)
+
+	loader := UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+		if c.Request().Header.Get("Cookie") == "" {
+			return goth.User{}, false, nil
+		}
+		return goth.User{Name: "Homer Simpson"}, true, nil
+	})
+	opts := RequireAuthOptions{Loader: loader, Provider: "faux"}
+
+	req := httptest.NewRequest(http.MethodGet, "/account", nil)
+	req.Header.Set("Cookie", "app=1")
+	res, err := serveRequireAuth(opts, req)
+	a.NoError(err)
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("Homer Simpson", res.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/account", nil)
+	res, err = serveRequireAuth(opts, req)
+	a.NoError(err)
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/auth/faux", res.Header().Get(echo.HeaderLocation))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/account", nil)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+	res, err = serveRequireAuth(opts, req)
+	a.NoError(err)
+	a.Equal(http.StatusUnauthorized, res.Code)
+	a.JSONEq(`{"error":"unauthorized","login_url":"/auth/faux"}`, res.Body.String())
+}
+
+func Test_RequireAuthSkipperAndErrors(t *testing.T) {
+	a := assert.New(t)
+
+	failing := UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+		return goth.User{}, false, errors.New("database down")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/account", nil)
+	_, err := serveRequireAuth(RequireAuthOptions{Loader: failing}, req)
+	a.EqualError(err, "database down")
+
+	opts := RequireAuthOptions{
+		Loader:  failing,
+		Skipper: func(c echo.Context) bool { return true },
+	}
+	_, err = serveRequireAuth(opts, req)
+	a.EqualError(err, "no user in context")
+
+	a.Panics(func() { RequireAuth(RequireAuthOptions{}) })
+}
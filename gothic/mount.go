@@ -0,0 +1,207 @@
+package gothic
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// Flash message kinds passed to a Flasher.
+const (
+	FlashSuccess = "success"
+	FlashError   = "error"
+)
+
+var (
+	// SuccessURL is where CallbackHandler redirects users once they are
+	// logged in.
+	SuccessURL = "/"
+
+	// FailureURL is where CallbackHandler redirects users whose login
+	// failed.
+	FailureURL = "/"
+
+	// OnAuthSuccess is called by CallbackHandler with the authenticated user,
+	// typically to persist it in the application's session or database.
+	// When it returns an error the login is treated as failed.
+	OnAuthSuccess func(c echo.Context, user goth.User) error
+
+	// OnAuthFailure is called by CallbackHandler when the login failed,
+	// before redirecting to FailureURL. The error is not shown to the user,
+	// so this is the place to log it.
+	OnAuthFailure func(c echo.Context, err error)
+
+	// Flash, when set, is used by CallbackHandler to leave a message for the
+	// page the user is redirected to.
+	Flash Flasher
+)
+
+// ReservedPath is the path, under the prefix given to Mount, of the routes
+// not taking a provider.
+const ReservedPath = "/_gothic"
+
+// Flasher stashes a one-time message for the next page the user sees.
+type Flasher interface {
+	Flash(c echo.Context, kind, message string) error
+}
+
+// Router is implemented by *echo.Echo and *echo.Group.
+type Router interface {
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// Mount registers the login routes under prefix, "/auth" when empty:
+//
+//	GET  <prefix>/:provider           starts the login, see BeginAuthHandler
+//	GET  <prefix>/:provider/callback  completes it, see CallbackHandler
+//	POST <prefix>/:provider/callback  same, for providers posting the callback
+//	POST <prefix>/:provider/backchannel-logout
+//	                                  receives back-channel logouts, see
+//	                                  BackChannelLogoutHandler
+//	POST <prefix>/_gothic/token       exchanges headless codes, see TokenHandler
+//	GET  <prefix>/_gothic/email       starts the login with the provider of
+//	POST <prefix>/_gothic/email       an email, see EmailLoginHandler
+//	GET  <prefix>/_gothic/mfa/:factor checks the second factor of a pending
+//	POST <prefix>/_gothic/mfa/:factor login, see MFAHandler
+//
+// The routes not taking a provider are under ReservedPath, so that they
+// never shadow the login of a provider, whatever its name.
+//
+// With OnAuthSuccess set, this is all an application needs to log users in.
+func Mount(r Router, prefix string, m ...echo.MiddlewareFunc) {
+	if prefix == "" {
+		prefix = "/auth"
+	}
+	r.GET(prefix+"/:provider", BeginAuthHandler, m...)
+	r.GET(prefix+"/:provider/callback", CallbackHandler, m...)
+	r.POST(prefix+"/:provider/callback", CallbackHandler, m...)
+	r.POST(prefix+"/:provider/backchannel-logout", BackChannelLogoutHandler, m...)
+	reserved := prefix + ReservedPath
+	r.POST(reserved+"/token", TokenHandler, m...)
+	r.GET(reserved+"/email", EmailLoginHandler, m...)
+	r.POST(reserved+"/email", EmailLoginHandler, m...)
+	r.GET(reserved+"/mfa/:factor", MFAHandler, m...)
+	r.POST(reserved+"/mfa/:factor", MFAHandler, m...)
+}
+
+// CallbackHandler completes the authentication with CompleteUserAuth, hands
+// the user to OnAuthSuccess and redirects to SuccessURL. When anything fails
+// the user is redirected to FailureURL instead. Logins started in headless
+// mode are sent back to the native application instead, and test logins
+// started from the dashboard back to the dashboard. Users needing a second
+// factor, see MFARequired, are redirected to MFAURL first. The invitations
+// carried by the login are validated, see GetInvitation. Outcomes are recorded
+// in RecentOutcomes.
+func CallbackHandler(c echo.Context) error {
+	headless, _ := getHeadlessRequest(c)
+	dashboard, testErr := GetFromSession(dashboardKey, c)
+	if testErr == nil {
+		if err := endTestLogin(c); err != nil {
+			return err
+		}
+	}
+
+	user, err := CompleteUserAuth(c)
+	if testErr == nil {
+		recordOutcome(c, user, err, true)
+		return c.Redirect(http.StatusFound, dashboard)
+	}
+	if err == nil {
+		err = acceptInvitation(c, user)
+	}
+	if err == nil && MFARequired != nil {
+		var factors []string
+		if factors, err = MFARequired(c, user); err == nil && len(factors) > 0 {
+			if err = beginMFA(c, user, factors, headless); err == nil {
+				return c.Redirect(http.StatusFound, MFAURL)
+			}
+		}
+	}
+	return completeLogin(c, user, err, headless)
+}
+
+// completeLogin provisions the user authenticated by c and hands it to
+// OnAuthSuccess, or reports err, and sends the user on.
+func completeLogin(c echo.Context, user goth.User, err error, headless *headlessRequest) error {
+	if err == nil {
+		err = provision(c, user)
+	}
+	if err == nil && OnAuthSuccess != nil {
+		err = OnAuthSuccess(c, user)
+	}
+	recordOutcome(c, user, err, false)
+	if headless != nil {
+		return completeHeadless(c, headless, user, err)
+	}
+	if err != nil {
+		return authFailed(c, err)
+	}
+
+	if err := flash(c, FlashSuccess, fmt.Sprintf("Signed in with %s.", user.Provider)); err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusFound, SuccessURL)
+}
+
+func authFailed(c echo.Context, err error) error {
+	if OnAuthFailure != nil {
+		OnAuthFailure(c, err)
+	} else {
+		log.Println(err)
+	}
+
+	if err := flash(c, FlashError, "Sign in failed, please try again."); err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusFound, FailureURL)
+}
+
+func flash(c echo.Context, kind, message string) error {
+	if Flash == nil {
+		return nil
+	}
+	return Flash.Flash(c, kind, message)
+}
+
+// SessionFlasher is a Flasher keeping the messages in the gothic session.
+// Read them back with Flashes.
+type SessionFlasher struct{}
+
+// Flash adds message to the gothic session.
+func (SessionFlasher) Flash(c echo.Context, kind, message string) error {
+	sess, err := getSession(c)
+	if err != nil {
+		return err
+	}
+	sess.AddFlash(message, kind)
+	return sess.Save(c.Request(), c.Response())
+}
+
+// Flashes returns, and removes, the messages of the given kind stashed by
+// SessionFlasher.
+func Flashes(c echo.Context, kind string) ([]string, error) {
+	sess, err := getSession(c)
+	if err != nil {
+		return nil, err
+	}
+
+	flashes := sess.Flashes(kind)
+	if len(flashes) == 0 {
+		return nil, nil
+	}
+	if err := sess.Save(c.Request(), c.Response()); err != nil {
+		return nil, err
+	}
+
+	messages := make([]string, 0, len(flashes))
+	for _, f := range flashes {
+		if m, ok := f.(string); ok {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
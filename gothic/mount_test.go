@@ -0,0 +1,97 @@
+package gothic_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveCallback(t *testing.T, withSession bool) (*httptest.ResponseRecorder, *http.Request) {
+	e := echo.New()
+	Mount(e, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	res := httptest.NewRecorder()
+	if withSession {
+		sess := faux.Session{Name: "Homer Simpson", Email: "homer@example.com"}
+		session, _ := Store.Get(req, SessionName)
+		session.Values["faux"] = gzipString(sess.Marshal())
+	}
+	e.ServeHTTP(res, req)
+	return res, req
+}
+
+func Test_CallbackHandler(t *testing.T) {
+	a := assert.New(t)
+
+	SuccessURL, FailureURL = "/welcome", "/login"
+	Flash = SessionFlasher{}
+	var got goth.User
+	OnAuthSuccess = func(c echo.Context, user goth.User) error {
+		got = user
+		return nil
+	}
+	defer func() {
+		SuccessURL, FailureURL = "/", "/"
+		Flash, OnAuthSuccess = nil, nil
+	}()
+
+	res, req := serveCallback(t, true)
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+	a.Equal("Homer Simpson", got.Name)
+
+	flashes, err := Flashes(echo.New().NewContext(req, httptest.NewRecorder()), FlashSuccess)
+	a.NoError(err)
+	a.Len(flashes, 1)
+
+	res, req = serveCallback(t, false)
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	flashes, err = Flashes(echo.New().NewContext(req, httptest.NewRecorder()), FlashError)
+	a.NoError(err)
+	a.Equal([]string{"Sign in failed, please try again."}, flashes)
+}
+
+func Test_CallbackHandlerRejectedUser(t *testing.T) {
+	a := assert.New(t)
+
+	var failure error
+	OnAuthSuccess = func(c echo.Context, user goth.User) error {
+		return errors.New("user is banned")
+	}
+	OnAuthFailure = func(c echo.Context, err error) {
+		failure = err
+	}
+	defer func() { OnAuthSuccess, OnAuthFailure = nil, nil }()
+
+	res, _ := serveCallback(t, true)
+	a.Equal(http.StatusFound, res.Code)
+	a.Equal("/", res.Header().Get(echo.HeaderLocation))
+	a.EqualError(failure, "user is banned")
+}
+
+func Test_MountDoesNotShadowProviders(t *testing.T) {
+	a := assert.New(t)
+
+	for _, name := range []string{"token", "email", "mfa"} {
+		a.NoError(goth.UseProviderAs(name, &faux.Provider{}))
+		defer goth.DeleteProvider(name)
+	}
+	e := echo.New()
+	Mount(e, "")
+
+	for _, name := range []string{"token", "email", "mfa"} {
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/auth/"+name, nil))
+		a.Equal(http.StatusTemporaryRedirect, res.Code, name)
+		a.Contains(res.Header().Get(echo.HeaderLocation), "http://example.com/auth", name)
+	}
+}
@@ -0,0 +1,132 @@
+package gothic
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// Provisioner, when set, provisions the accounts of the users logging in
+	// with ProvisionProviders: CallbackHandler calls its Provision method
+	// with the attributes of the user before OnAuthSuccess, failing the
+	// login when it returns an error.
+	Provisioner goth.Provisioner
+
+	// ProvisionProviders are the providers whose users are provisioned,
+	// every provider when empty.
+	ProvisionProviders []string
+
+	// LogoutTokenVerifiers are the verifiers of the logout tokens of the
+	// providers sending back-channel logouts, by provider name, see
+	// BackChannelLogoutHandler. They hold the keys URL, client ID and issuer
+	// of the provider, as for its ID tokens.
+	LogoutTokenVerifiers = map[string]*idtoken.Verifier{}
+)
+
+// backChannelLogoutEvent is the event logout tokens carry.
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// provisions reports whether the users of provider are provisioned.
+func provisions(provider string) bool {
+	if Provisioner == nil {
+		return false
+	}
+	if len(ProvisionProviders) == 0 {
+		return true
+	}
+	for _, p := range ProvisionProviders {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// provision provisions the account of user, when its provider is
+// provisioned.
+func provision(c echo.Context, user goth.User) error {
+	if !provisions(user.Provider) {
+		return nil
+	}
+	if err := Provisioner.Provision(c.Request().Context(), goth.AttributesOf(user)); err != nil {
+		return fmt.Errorf("gothic: provisioning %s/%s: %w", user.Provider, user.UserID, err)
+	}
+	return nil
+}
+
+// Deprovision asks Provisioner to deprovision the account of the user with
+// externalID at provider, for the webhook handlers of the application to call
+// when a provider reports the access of a user revoked, with the reason
+// goth.DeprovisionRevoked. It does nothing for providers that are not
+// provisioned.
+func Deprovision(c echo.Context, provider, externalID, reason string) error {
+	return deprovision(c, goth.Deprovisioning{Provider: provider, ExternalID: externalID, Reason: reason})
+}
+
+func deprovision(c echo.Context, d goth.Deprovisioning) error {
+	if !provisions(d.Provider) {
+		return nil
+	}
+	logf(c, "deprovisioning %s/%s: %s", d.Provider, d.ExternalID, d.Reason)
+	return Provisioner.Deprovision(c.Request().Context(), d)
+}
+
+// BackChannelLogoutHandler receives the OpenID Connect back-channel logouts
+// of the provider named by the "provider" URL param, posted with a
+// logout_token the verifier of LogoutTokenVerifiers validates, and hands them
+// to Provisioner with the reason goth.DeprovisionLogout.
+// See https://openid.net/specs/openid-connect-backchannel-1_0.html
+func BackChannelLogoutHandler(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	d, err := verifyLogoutToken(c)
+	if err == nil {
+		err = deprovision(c, d)
+	}
+	if err != nil {
+		logf(c, "back-channel logout rejected: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":             "invalid_request",
+			"error_description": err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func verifyLogoutToken(c echo.Context) (goth.Deprovisioning, error) {
+	name, err := GetProviderName(c)
+	if err != nil {
+		return goth.Deprovisioning{}, err
+	}
+	verifier, ok := LogoutTokenVerifiers[name]
+	if !ok {
+		return goth.Deprovisioning{}, fmt.Errorf("%s sends no back-channel logouts", name)
+	}
+	claims, err := verifier.Verify(goth.HTTPClientWithFallBack(nil), c.FormValue("logout_token"), "")
+	if err != nil {
+		return goth.Deprovisioning{}, err
+	}
+
+	events, _ := claims.Raw["events"].(map[string]interface{})
+	if _, ok := events[backChannelLogoutEvent]; !ok {
+		return goth.Deprovisioning{}, errors.New("logout token has no back-channel logout event")
+	}
+	if _, ok := claims.Raw["nonce"]; ok {
+		return goth.Deprovisioning{}, errors.New("logout token has a nonce")
+	}
+	sid, _ := claims.Raw["sid"].(string)
+	if claims.Subject == "" && sid == "" {
+		return goth.Deprovisioning{}, errors.New("logout token names neither a subject nor a session")
+	}
+	return goth.Deprovisioning{
+		Provider:   name,
+		ExternalID: claims.Subject,
+		SessionID:  sid,
+		Reason:     goth.DeprovisionLogout,
+	}, nil
+}
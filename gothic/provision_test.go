@@ -0,0 +1,134 @@
+package gothic_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingProvisioner struct {
+	provisioned   []goth.Attributes
+	deprovisioned []goth.Deprovisioning
+	err           error
+}
+
+func (p *recordingProvisioner) Provision(ctx context.Context, attrs goth.Attributes) error {
+	p.provisioned = append(p.provisioned, attrs)
+	return p.err
+}
+
+func (p *recordingProvisioner) Deprovision(ctx context.Context, d goth.Deprovisioning) error {
+	p.deprovisioned = append(p.deprovisioned, d)
+	return p.err
+}
+
+func Test_Provisioning(t *testing.T) {
+	a := assert.New(t)
+
+	provisioner := &recordingProvisioner{}
+	Provisioner = provisioner
+	SuccessURL, FailureURL = "/welcome", "/login"
+	defer func() {
+		Provisioner, ProvisionProviders = nil, nil
+		SuccessURL, FailureURL = "/", "/"
+	}()
+
+	res, _ := serveCallback(t, true)
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+	a.Len(provisioner.provisioned, 1)
+	a.Equal("faux", provisioner.provisioned[0].Provider)
+	a.Equal("homer@example.com", provisioner.provisioned[0].Email)
+
+	provisioner.err = errors.New("directory unavailable")
+	res, _ = serveCallback(t, true)
+	a.Equal("/login", res.Header().Get(echo.HeaderLocation))
+	a.Len(provisioner.provisioned, 2)
+
+	ProvisionProviders = []string{"okta"}
+	res, _ = serveCallback(t, true)
+	a.Equal("/welcome", res.Header().Get(echo.HeaderLocation))
+	a.Len(provisioner.provisioned, 2)
+
+	c := newContext(httptest.NewRequest(http.MethodPost, "/webhooks/okta", nil), httptest.NewRecorder())
+	a.NoError(Deprovision(c, "faux", "42", goth.DeprovisionRevoked))
+	a.Empty(provisioner.deprovisioned)
+	provisioner.err = nil
+	a.NoError(Deprovision(c, "okta", "42", goth.DeprovisionRevoked))
+	a.Equal([]goth.Deprovisioning{{Provider: "okta", ExternalID: "42", Reason: goth.DeprovisionRevoked}}, provisioner.deprovisioned)
+}
+
+func Test_BackChannelLogoutHandler(t *testing.T) {
+	a := assert.New(t)
+
+	provisioner := &recordingProvisioner{}
+	Provisioner = provisioner
+	LogoutTokenVerifiers["faux"] = &idtoken.Verifier{
+		ClientID:   "client",
+		Issuers:    []string{"https://idp.example.com"},
+		Algorithms: []string{"HS256"},
+		Secret:     "secret",
+	}
+	defer func() {
+		Provisioner = nil
+		LogoutTokenVerifiers = map[string]*idtoken.Verifier{}
+	}()
+
+	logout := func(provider string, claims jwt.MapClaims) *httptest.ResponseRecorder {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		a.NoError(err)
+		e := echo.New()
+		Mount(e, "")
+		req := httptest.NewRequest(http.MethodPost, "/auth/"+provider+"/backchannel-logout",
+			strings.NewReader(url.Values{"logout_token": {token}}.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    "https://idp.example.com",
+			"aud":    "client",
+			"iat":    time.Now().Unix(),
+			"exp":    time.Now().Add(time.Minute).Unix(),
+			"jti":    "1",
+			"sub":    "42",
+			"sid":    "s1",
+			"events": map[string]interface{}{"http://schemas.openid.net/event/backchannel-logout": map[string]interface{}{}},
+		}
+	}
+
+	res := logout("faux", claims())
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("no-store", res.Header().Get(echo.HeaderCacheControl))
+	a.Equal([]goth.Deprovisioning{{Provider: "faux", ExternalID: "42", SessionID: "s1", Reason: goth.DeprovisionLogout}}, provisioner.deprovisioned)
+
+	noEvent := claims()
+	delete(noEvent, "events")
+	withNonce := claims()
+	withNonce["nonce"] = "n"
+	anonymous := claims()
+	delete(anonymous, "sub")
+	delete(anonymous, "sid")
+	otherAudience := claims()
+	otherAudience["aud"] = "other"
+	for _, c := range []jwt.MapClaims{noEvent, withNonce, anonymous, otherAudience} {
+		res = logout("faux", c)
+		a.Equal(http.StatusBadRequest, res.Code)
+		a.Contains(res.Body.String(), "invalid_request")
+	}
+	a.Equal(http.StatusBadRequest, logout("github", claims()).Code)
+	a.Len(provisioner.deprovisioned, 1)
+}
@@ -0,0 +1,257 @@
+package gothic
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/labstack/echo/v4"
+)
+
+// ErrRememberMeTokenInvalid is returned by RememberMe.Login when the cookie
+// names a token whose validator does not match. Tokens are rotated on every
+// use, so this happens when a stolen cookie is replayed: the token is deleted,
+// logging out both the thief and the user.
+var ErrRememberMeTokenInvalid = errors.New("gothic: remember-me token is invalid")
+
+// ErrRememberMeImpersonated is returned by RememberMe.Remember for users with
+// ImpersonatedBy set: the cookie would log the user back in without the
+// administrator, making the impersonation outlive its session.
+var ErrRememberMeImpersonated = errors.New("gothic: impersonated users cannot be remembered")
+
+// RememberMe issues long-lived login cookies, so that users stay logged in
+// after the application session is gone without the provider tokens being
+// kept in cookies. The cookie holds a selector, naming the token in Tokens,
+// and a validator, of which only a hash is stored and which is replaced every
+// time the cookie is used.
+//
+//	remember := &gothic.RememberMe{Tokens: sqlstore.New(db, sqlstore.Postgres)}
+//
+// Call Remember once the user is logged in and Forget when they log out, and
+// set the RememberMe field of RequireAuthOptions to log remembered users back
+// in.
+type RememberMe struct {
+	// Tokens keeps the remember-me tokens. It is required.
+	Tokens store.RememberMeStore
+
+	// CookieName is the name of the cookie, "remember_me" when empty.
+	CookieName string
+
+	// MaxAge is how long the cookie lasts, 30 days when zero. It is extended
+	// every time the cookie is used.
+	MaxAge time.Duration
+
+	// GracePeriod is how long the validator replaced by a rotation is still
+	// accepted, 30 seconds when zero. Requests the browser sent with the
+	// previous cookie before receiving the new one log the user in without
+	// rotating the token again, instead of being taken for a replay.
+	GracePeriod time.Duration
+
+	// Secure sends the cookie on HTTPS requests only.
+	Secure bool
+
+	// LoadUser, when set, returns the remembered user from the database of
+	// the application. Without it, the user only has its Provider and
+	// UserID. Returning ok false, for users since deleted, forgets them.
+	LoadUser func(c echo.Context, provider, userID string) (user goth.User, ok bool, err error)
+
+	// OnLogin, when set, is called when a remembered user is logged back in,
+	// the place to start a new application session.
+	OnLogin func(c echo.Context, user goth.User) error
+}
+
+// Remember issues a remember-me cookie for user. It returns
+// ErrRememberMeImpersonated for impersonated users.
+func (r *RememberMe) Remember(c echo.Context, user goth.User) error {
+	if user.ImpersonatedBy != "" {
+		return ErrRememberMeImpersonated
+	}
+	selector, err := randomToken()
+	if err != nil {
+		return err
+	}
+	return r.issue(c, store.RememberMeToken{
+		Selector: selector,
+		Provider: user.Provider,
+		UserID:   user.UserID,
+	})
+}
+
+// Login logs back in the user of the remember-me cookie of the request, and
+// rotates the cookie. ok is false when the request has no valid cookie.
+func (r *RememberMe) Login(c echo.Context) (user goth.User, ok bool, err error) {
+	cookie, err := c.Cookie(r.cookieName())
+	if err != nil {
+		return user, false, nil
+	}
+	selector, validator := splitRememberMeCookie(cookie.Value)
+	if selector == "" || validator == "" {
+		r.clearCookie(c)
+		return user, false, nil
+	}
+
+	ctx := c.Request().Context()
+	token, err := r.Tokens.LoadRememberMeToken(ctx, selector)
+	if errors.Is(err, store.ErrRememberMeTokenNotFound) {
+		r.clearCookie(c)
+		return user, false, nil
+	}
+	if err != nil {
+		return user, false, err
+	}
+
+	hash := []byte(hashValidator(validator))
+	current := subtle.ConstantTimeCompare([]byte(token.ValidatorHash), hash) == 1
+	previous := !current && token.PreviousValidatorHash != "" &&
+		subtle.ConstantTimeCompare([]byte(token.PreviousValidatorHash), hash) == 1 &&
+		goth.Now().Before(token.RotatedAt.Add(r.gracePeriod()))
+	if !current && !previous {
+		r.clearCookie(c)
+		if err := r.Tokens.DeleteRememberMeToken(ctx, selector); err != nil {
+			return user, false, err
+		}
+		return user, false, ErrRememberMeTokenInvalid
+	}
+	if goth.Expired(token.ExpiresAt) {
+		return user, false, r.forget(c, selector)
+	}
+
+	user = goth.User{Provider: token.Provider, UserID: token.UserID}
+	if r.LoadUser != nil {
+		user, ok, err = r.LoadUser(c, user.Provider, user.UserID)
+		if err != nil {
+			return user, false, err
+		}
+		if !ok {
+			return user, false, r.forget(c, selector)
+		}
+	}
+
+	// The response to the request that rotated the token carries the new
+	// cookie, rotating it again would log that one out.
+	if current {
+		if err := r.issue(c, token); err != nil {
+			return user, false, err
+		}
+	}
+	if r.OnLogin != nil {
+		if err := r.OnLogin(c, user); err != nil {
+			return user, false, err
+		}
+	}
+	return user, true, nil
+}
+
+// Forget deletes the remember-me token of the request and its cookie.
+func (r *RememberMe) Forget(c echo.Context) error {
+	cookie, err := c.Cookie(r.cookieName())
+	if err != nil {
+		return nil
+	}
+	selector, _ := splitRememberMeCookie(cookie.Value)
+	if selector == "" {
+		r.clearCookie(c)
+		return nil
+	}
+	return r.forget(c, selector)
+}
+
+func (r *RememberMe) forget(c echo.Context, selector string) error {
+	r.clearCookie(c)
+	err := r.Tokens.DeleteRememberMeToken(c.Request().Context(), selector)
+	if errors.Is(err, store.ErrRememberMeTokenNotFound) {
+		return nil
+	}
+	return err
+}
+
+// issue saves token with a new validator, keeping the hash of the current one
+// for the grace period, and sets the cookie.
+func (r *RememberMe) issue(c echo.Context, token store.RememberMeToken) error {
+	validator, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	now := goth.Now()
+	if token.ValidatorHash != "" {
+		token.PreviousValidatorHash = token.ValidatorHash
+		token.RotatedAt = now
+	}
+	token.ValidatorHash = hashValidator(validator)
+	token.ExpiresAt = now.Add(r.maxAge())
+	if err := r.Tokens.SaveRememberMeToken(c.Request().Context(), token); err != nil {
+		return err
+	}
+
+	c.SetCookie(r.cookie(token.Selector+"."+validator, token.ExpiresAt, int(r.maxAge().Seconds())))
+	return nil
+}
+
+func splitRememberMeCookie(value string) (selector, validator string) {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+func (r *RememberMe) clearCookie(c echo.Context) {
+	c.SetCookie(r.cookie("", time.Unix(0, 0), -1))
+}
+
+func (r *RememberMe) cookie(value string, expires time.Time, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     r.cookieName(),
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   maxAge,
+		Secure:   r.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (r *RememberMe) cookieName() string {
+	if r.CookieName == "" {
+		return "remember_me"
+	}
+	return r.CookieName
+}
+
+func (r *RememberMe) maxAge() time.Duration {
+	if r.MaxAge == 0 {
+		return 30 * 24 * time.Hour
+	}
+	return r.MaxAge
+}
+
+func (r *RememberMe) gracePeriod() time.Duration {
+	if r.GracePeriod == 0 {
+		return 30 * time.Second
+	}
+	return r.GracePeriod
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := io.ReadFull(RandReader, b); err != nil {
+		return "", fmt.Errorf("gothic: source of randomness unavailable: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashValidator(validator string) string {
+	sum := sha256.Sum256([]byte(validator))
+	return hex.EncodeToString(sum[:])
+}
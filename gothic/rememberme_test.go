@@ -0,0 +1,219 @@
+package gothic_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRememberMeStore struct {
+	mu     sync.Mutex
+	tokens map[string]store.RememberMeToken
+}
+
+func (s *memoryRememberMeStore) SaveRememberMeToken(ctx context.Context, token store.RememberMeToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = map[string]store.RememberMeToken{}
+	}
+	s.tokens[token.Selector] = token
+	return nil
+}
+
+func (s *memoryRememberMeStore) LoadRememberMeToken(ctx context.Context, selector string) (store.RememberMeToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[selector]
+	if !ok {
+		return token, store.ErrRememberMeTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *memoryRememberMeStore) DeleteRememberMeToken(ctx context.Context, selector string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tokens[selector]; !ok {
+		return store.ErrRememberMeTokenNotFound
+	}
+	delete(s.tokens, selector)
+	return nil
+}
+
+func rememberMeContext(cookie *http.Cookie) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/account", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	res := httptest.NewRecorder()
+	return echo.New().NewContext(req, res), res
+}
+
+func rememberMeCookie(res *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range res.Result().Cookies() {
+		if cookie.Name == "remember_me" {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func Test_RememberMe(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := &memoryRememberMeStore{}
+	remember := &RememberMe{Tokens: tokens}
+
+	c, res := rememberMeContext(nil)
+	a.NoError(remember.Remember(c, goth.User{Provider: "faux", UserID: "42", AccessToken: "secret"}))
+	first := rememberMeCookie(res)
+	a.NotNil(first)
+	a.True(first.HttpOnly)
+	a.Len(tokens.tokens, 1)
+	for _, token := range tokens.tokens {
+		a.Equal("faux", token.Provider)
+		a.Equal("42", token.UserID)
+		a.NotContains(first.Value, token.ValidatorHash)
+	}
+
+	c, res = rememberMeContext(first)
+	user, ok, err := remember.Login(c)
+	a.NoError(err)
+	a.True(ok)
+	a.Equal("faux", user.Provider)
+	a.Equal("42", user.UserID)
+	second := rememberMeCookie(res)
+	a.NotEqual(first.Value, second.Value)
+	a.Len(tokens.tokens, 1)
+
+	// Requests sent with the rotated cookie before the browser got the new
+	// one are let in during the grace period, without rotating it again.
+	c, res = rememberMeContext(first)
+	user, ok, err = remember.Login(c)
+	a.NoError(err)
+	a.True(ok)
+	a.Equal("42", user.UserID)
+	a.Nil(rememberMeCookie(res))
+
+	// Replaying the rotated cookie afterwards revokes the token.
+	now := time.Now()
+	goth.Clock = func() time.Time { return now.Add(time.Minute) }
+	defer func() { goth.Clock = time.Now }()
+
+	c, _ = rememberMeContext(first)
+	_, ok, err = remember.Login(c)
+	a.Equal(ErrRememberMeTokenInvalid, err)
+	a.False(ok)
+	a.Len(tokens.tokens, 0)
+
+	c, _ = rememberMeContext(second)
+	_, ok, err = remember.Login(c)
+	a.NoError(err)
+	a.False(ok)
+}
+
+func Test_RememberMeForgetAndExpiry(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := &memoryRememberMeStore{}
+	remember := &RememberMe{Tokens: tokens, MaxAge: time.Hour}
+
+	c, res := rememberMeContext(nil)
+	a.NoError(remember.Remember(c, goth.User{Provider: "faux", UserID: "42"}))
+	cookie := rememberMeCookie(res)
+
+	c, res = rememberMeContext(cookie)
+	a.NoError(remember.Forget(c))
+	a.Equal(-1, rememberMeCookie(res).MaxAge)
+	a.Len(tokens.tokens, 0)
+
+	c, res = rememberMeContext(nil)
+	a.NoError(remember.Remember(c, goth.User{Provider: "faux", UserID: "42"}))
+	cookie = rememberMeCookie(res)
+
+	now := time.Now()
+	goth.Clock = func() time.Time { return now.Add(2 * time.Hour) }
+	defer func() { goth.Clock = time.Now }()
+
+	c, _ = rememberMeContext(cookie)
+	_, ok, err := remember.Login(c)
+	a.NoError(err)
+	a.False(ok)
+	a.Len(tokens.tokens, 0)
+}
+
+func Test_RememberMeImpersonated(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := &memoryRememberMeStore{}
+	remember := &RememberMe{Tokens: tokens}
+
+	c, res := rememberMeContext(nil)
+	err := remember.Remember(c, goth.User{Provider: "faux", UserID: "42", ImpersonatedBy: "admin"})
+	a.Equal(ErrRememberMeImpersonated, err)
+	a.Nil(rememberMeCookie(res))
+	a.Len(tokens.tokens, 0)
+}
+
+func Test_RequireAuthRememberMe(t *testing.T) {
+	a := assert.New(t)
+
+	var loggedIn goth.User
+	remember := &RememberMe{
+		Tokens: &memoryRememberMeStore{},
+		LoadUser: func(c echo.Context, provider, userID string) (goth.User, bool, error) {
+			return goth.User{Provider: provider, UserID: userID, Name: "Homer Simpson"}, userID == "42", nil
+		},
+		OnLogin: func(c echo.Context, user goth.User) error {
+			loggedIn = user
+			return nil
+		},
+	}
+	anonymous := UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+		return goth.User{}, false, nil
+	})
+	opts := RequireAuthOptions{Loader: anonymous, Provider: "faux", RememberMe: remember}
+
+	c, res := rememberMeContext(nil)
+	a.NoError(remember.Remember(c, goth.User{Provider: "faux", UserID: "42"}))
+	cookie := rememberMeCookie(res)
+
+	req := httptest.NewRequest(http.MethodGet, "/account", nil)
+	req.AddCookie(cookie)
+	res, err := serveRequireAuth(opts, req)
+	a.NoError(err)
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("Homer Simpson", res.Body.String())
+	a.Equal("42", loggedIn.UserID)
+
+	// The replayed cookie is refused like a missing one once the grace
+	// period is over.
+	now := time.Now()
+	goth.Clock = func() time.Time { return now.Add(time.Minute) }
+	defer func() { goth.Clock = time.Now }()
+
+	req = httptest.NewRequest(http.MethodGet, "/account", nil)
+	req.AddCookie(cookie)
+	res, err = serveRequireAuth(opts, req)
+	a.NoError(err)
+	a.Equal(http.StatusFound, res.Code)
+
+	// Users the application no longer knows are forgotten.
+	c, res = rememberMeContext(nil)
+	a.NoError(remember.Remember(c, goth.User{Provider: "faux", UserID: "7"}))
+	req = httptest.NewRequest(http.MethodGet, "/account", nil)
+	req.AddCookie(rememberMeCookie(res))
+	res, err = serveRequireAuth(opts, req)
+	a.NoError(err)
+	a.Equal(http.StatusFound, res.Code)
+}
@@ -0,0 +1,63 @@
+package gothic
+
+import (
+	"errors"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+)
+
+// ProviderResolver builds the providers of a request, for applications whose
+// tenants each bring their own client ID, secret and callback URL, kept in a
+// database rather than registered with goth at start up. The tenant must be
+// found the same way at the start of the login and at its callback, from the
+// host or a path parameter for instance, so the callback URL of each tenant
+// should carry it.
+type ProviderResolver interface {
+	// ResolveProvider returns the provider named name for the request. It
+	// returns an error matching goth.ErrProviderNotFound for providers it
+	// does not resolve, which are then looked up in the providers registered
+	// with goth.
+	ResolveProvider(c echo.Context, name string) (goth.Provider, error)
+}
+
+// ProviderResolverFunc adapts a function to the ProviderResolver interface.
+type ProviderResolverFunc func(c echo.Context, name string) (goth.Provider, error)
+
+// ResolveProvider calls f(c, name).
+func (f ProviderResolverFunc) ResolveProvider(c echo.Context, name string) (goth.Provider, error) {
+	return f(c, name)
+}
+
+// Resolver, when set, is asked for the provider of every login before the
+// providers registered with goth. It is called twice a login, at its start
+// and at its callback, so it should cache what it builds, such as providers
+// fetching an OpenID discovery document.
+//
+//	gothic.Resolver = gothic.ProviderResolverFunc(func(c echo.Context, name string) (goth.Provider, error) {
+//		tenant, err := db.TenantByHost(c.Request().Host)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return tenant.Provider(name)
+//	})
+var Resolver ProviderResolver
+
+// resolveProvider returns the provider of Resolver, and false when it does not
+// resolve name.
+func resolveProvider(c echo.Context, name string) (goth.Provider, bool, error) {
+	if Resolver == nil {
+		return nil, false, nil
+	}
+	provider, err := Resolver.ResolveProvider(c, name)
+	if errors.Is(err, goth.ErrProviderNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	if provider == nil {
+		return nil, true, &goth.ProviderNotFoundError{Name: name}
+	}
+	return provider, true, nil
+}
@@ -0,0 +1,79 @@
+package gothic_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	. "github.com/bgdsh/goth/gothic"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/bgdsh/goth/providers/gitlab"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Resolver(t *testing.T) {
+	a := assert.New(t)
+
+	tenants := map[string]string{"acme.example.com": "acme-key", "initech.example.com": "initech-key"}
+	Resolver = ProviderResolverFunc(func(c echo.Context, name string) (goth.Provider, error) {
+		if name != "gitlab" {
+			return nil, &goth.ProviderNotFoundError{Name: name}
+		}
+		key, ok := tenants[c.Request().Host]
+		if !ok {
+			return nil, errors.New("unknown tenant")
+		}
+		return gitlab.New(key, "secret", "https://"+c.Request().Host+"/auth/gitlab/callback"), nil
+	})
+	defer func() { Resolver = nil }()
+
+	for host, key := range tenants {
+		req := httptest.NewRequest(http.MethodGet, "/auth?provider=gitlab", nil)
+		req.Host = host
+		u, err := GetAuthURL(newContext(req, httptest.NewRecorder()))
+		a.NoError(err)
+		parsed, err := url.Parse(u)
+		a.NoError(err)
+		a.Equal(key, parsed.Query().Get("client_id"))
+		a.Equal("https://"+host+"/auth/gitlab/callback", parsed.Query().Get("redirect_uri"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/auth?provider=gitlab", nil)
+	req.Host = "unknown.example.com"
+	_, err := GetAuthURL(newContext(req, httptest.NewRecorder()))
+	a.EqualError(err, "unknown tenant")
+
+	// Providers the resolver does not know are looked up in the registry.
+	req = httptest.NewRequest(http.MethodGet, "/auth?provider=faux", nil)
+	_, err = GetAuthURL(newContext(req, httptest.NewRecorder()))
+	a.NoError(err)
+}
+
+func Test_ResolverCompletesLogin(t *testing.T) {
+	a := assert.New(t)
+
+	resolved := 0
+	Resolver = ProviderResolverFunc(func(c echo.Context, name string) (goth.Provider, error) {
+		resolved++
+		return &faux.Provider{}, nil
+	})
+	goth.DeleteProvider("faux")
+	defer func() {
+		Resolver = nil
+		goth.UseProviders(fauxProvider)
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?provider=faux", nil)
+	sess := faux.Session{Name: "Homer Simpson"}
+	session, _ := Store.Get(req, SessionName)
+	session.Values["faux"] = gzipString(sess.Marshal())
+
+	user, err := CompleteUserAuth(newContext(req, httptest.NewRecorder()))
+	a.NoError(err)
+	a.Equal("Homer Simpson", user.Name)
+	a.Equal(1, resolved)
+}
@@ -0,0 +1,290 @@
+// Package dynamostore implements a gorilla sessions.Store, a
+// store.TokenStore and a store.RememberMeStore backed by a single DynamoDB
+// table, for serverless deployments such as AWS Lambda where nothing survives
+// between invocations.
+//
+// The table needs a string partition key named "id" and no sort key. Enable
+// Time To Live on the "expires_at" attribute so DynamoDB removes abandoned
+// sessions and expired remember-me tokens on its own:
+//
+//	aws dynamodb update-time-to-live --table-name gothic \
+//		--time-to-live-specification "Enabled=true, AttributeName=expires_at"
+//
+// DynamoDB deletes expired items lazily, so the expiry is also checked when a
+// session is loaded.
+package dynamostore
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/gorilla/sessions"
+)
+
+// DefaultMaxAge is the lifetime, in seconds, of a session.
+const DefaultMaxAge = 5 * 60
+
+const (
+	attrID        = "id"
+	attrData      = "data"
+	attrExpiresAt = "expires_at"
+
+	sessionPrefix    = "session#"
+	tokenPrefix      = "token#"
+	rememberMePrefix = "remember_me#"
+)
+
+// API is the part of the DynamoDB client used by a Store, implemented by
+// *dynamodb.Client.
+type API interface {
+	GetItem(ctx context.Context, in *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, in *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, in *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// Store keeps sessions and tokens in a DynamoDB table.
+type Store struct {
+	Client  API
+	Table   string
+	Options *sessions.Options
+}
+
+var (
+	_ sessions.Store        = &Store{}
+	_ store.TokenStore      = &Store{}
+	_ store.RememberMeStore = &Store{}
+)
+
+// New creates a new DynamoDB backed store using the given table. Cookies are
+// HttpOnly, valid for the whole site and expire after DefaultMaxAge seconds;
+// change Options to tailor them.
+func New(client API, table string) *Store {
+	return &Store{
+		Client: client,
+		Table:  table,
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   DefaultMaxAge,
+			HttpOnly: true,
+		},
+	}
+}
+
+// Get returns the named session for the request, loading it from DynamoDB
+// only once per request.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the named session, loading its values from DynamoDB when the
+// request carries a cookie for a session that has not expired yet.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	return store.NewSession(r, s, backend{s}, name, s.Options)
+}
+
+// Save persists the session and writes the session cookie. A session with a
+// MaxAge of zero or less is deleted.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	return store.SaveSession(r, w, backend{s}, session)
+}
+
+// backend is the store.SessionBackend of a Store.
+type backend struct {
+	*Store
+}
+
+func (b backend) LoadSession(ctx context.Context, id string) ([]byte, error) {
+	item, err := b.get(ctx, sessionPrefix+id)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt, ok := fromEpoch(item[attrExpiresAt]); !ok || !goth.Now().Before(expiresAt) {
+		return nil, store.ErrSessionNotFound
+	}
+	data, ok := item[attrData].(*types.AttributeValueMemberB)
+	if !ok {
+		return nil, store.ErrSessionNotFound
+	}
+	return data.Value, nil
+}
+
+func (b backend) SaveSession(ctx context.Context, id string, data []byte, maxAge time.Duration) error {
+	_, err := b.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(b.Table),
+		Item: map[string]types.AttributeValue{
+			attrID:        &types.AttributeValueMemberS{Value: sessionPrefix + id},
+			attrData:      &types.AttributeValueMemberB{Value: data},
+			attrExpiresAt: epoch(goth.Now().Add(maxAge)),
+		},
+	})
+	return err
+}
+
+func (b backend) DeleteSession(ctx context.Context, id string) error {
+	return b.delete(ctx, sessionPrefix+id)
+}
+
+// SaveToken persists token, replacing the one previously saved for the same
+// provider and user. Tokens are kept until they are deleted, whatever their
+// expiry, since a refresh token usually outlives its access token.
+func (s *Store) SaveToken(ctx context.Context, token store.Token) error {
+	item := map[string]types.AttributeValue{
+		attrID:                &types.AttributeValueMemberS{Value: tokenKey(token.Provider, token.UserID)},
+		"provider":            &types.AttributeValueMemberS{Value: token.Provider},
+		"user_id":             &types.AttributeValueMemberS{Value: token.UserID},
+		"access_token":        &types.AttributeValueMemberS{Value: token.AccessToken},
+		"access_token_secret": &types.AttributeValueMemberS{Value: token.AccessTokenSecret},
+		"refresh_token":       &types.AttributeValueMemberS{Value: token.RefreshToken},
+		"id_token":            &types.AttributeValueMemberS{Value: token.IDToken},
+	}
+	if !token.ExpiresAt.IsZero() {
+		// Not stored as expires_at, which would make DynamoDB delete the
+		// refresh token along with the expired access token.
+		item["token_expires_at"] = epoch(token.ExpiresAt)
+	}
+
+	_, err := s.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.Table),
+		Item:      item,
+	})
+	return err
+}
+
+// LoadToken returns the token saved for the provider and user, or
+// store.ErrTokenNotFound.
+func (s *Store) LoadToken(ctx context.Context, provider, userID string) (store.Token, error) {
+	item, err := s.get(ctx, tokenKey(provider, userID))
+	if err != nil {
+		return store.Token{}, err
+	}
+	if item == nil {
+		return store.Token{}, store.ErrTokenNotFound
+	}
+
+	token := store.Token{
+		Provider:          provider,
+		UserID:            userID,
+		AccessToken:       str(item["access_token"]),
+		AccessTokenSecret: str(item["access_token_secret"]),
+		RefreshToken:      str(item["refresh_token"]),
+		IDToken:           str(item["id_token"]),
+	}
+	if expiresAt, ok := fromEpoch(item["token_expires_at"]); ok {
+		token.ExpiresAt = expiresAt
+	}
+	return token, nil
+}
+
+// DeleteToken removes the token saved for the provider and user, if any.
+func (s *Store) DeleteToken(ctx context.Context, provider, userID string) error {
+	return s.delete(ctx, tokenKey(provider, userID))
+}
+
+// SaveRememberMeToken persists token, replacing the one previously saved
+// under the same selector. DynamoDB deletes it once expired.
+func (s *Store) SaveRememberMeToken(ctx context.Context, token store.RememberMeToken) error {
+	item := map[string]types.AttributeValue{
+		attrID:                    &types.AttributeValueMemberS{Value: rememberMePrefix + token.Selector},
+		"provider":                &types.AttributeValueMemberS{Value: token.Provider},
+		"user_id":                 &types.AttributeValueMemberS{Value: token.UserID},
+		"validator_hash":          &types.AttributeValueMemberS{Value: token.ValidatorHash},
+		"previous_validator_hash": &types.AttributeValueMemberS{Value: token.PreviousValidatorHash},
+		attrExpiresAt:             epoch(token.ExpiresAt),
+	}
+	if !token.RotatedAt.IsZero() {
+		item["rotated_at"] = epoch(token.RotatedAt)
+	}
+
+	_, err := s.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.Table),
+		Item:      item,
+	})
+	return err
+}
+
+// LoadRememberMeToken returns the token saved under selector, or
+// store.ErrRememberMeTokenNotFound.
+func (s *Store) LoadRememberMeToken(ctx context.Context, selector string) (store.RememberMeToken, error) {
+	item, err := s.get(ctx, rememberMePrefix+selector)
+	if err != nil {
+		return store.RememberMeToken{}, err
+	}
+	if item == nil {
+		return store.RememberMeToken{}, store.ErrRememberMeTokenNotFound
+	}
+
+	token := store.RememberMeToken{
+		Selector:              selector,
+		Provider:              str(item["provider"]),
+		UserID:                str(item["user_id"]),
+		ValidatorHash:         str(item["validator_hash"]),
+		PreviousValidatorHash: str(item["previous_validator_hash"]),
+	}
+	token.RotatedAt, _ = fromEpoch(item["rotated_at"])
+	token.ExpiresAt, _ = fromEpoch(item[attrExpiresAt])
+	return token, nil
+}
+
+// DeleteRememberMeToken removes the token saved under selector, if any.
+func (s *Store) DeleteRememberMeToken(ctx context.Context, selector string) error {
+	return s.delete(ctx, rememberMePrefix+selector)
+}
+
+func (s *Store) get(ctx context.Context, id string) (map[string]types.AttributeValue, error) {
+	out, err := s.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.Table),
+		Key:            map[string]types.AttributeValue{attrID: &types.AttributeValueMemberS{Value: id}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+	return out.Item, nil
+}
+
+func (s *Store) delete(ctx context.Context, id string) error {
+	_, err := s.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.Table),
+		Key:       map[string]types.AttributeValue{attrID: &types.AttributeValueMemberS{Value: id}},
+	})
+	return err
+}
+
+func tokenKey(provider, userID string) string {
+	return tokenPrefix + provider + "#" + userID
+}
+
+// epoch returns t as a number of seconds since the Unix epoch, the format
+// DynamoDB expects for a TTL attribute.
+func epoch(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}
+}
+
+func fromEpoch(v types.AttributeValue) (time.Time, bool) {
+	n, ok := v.(*types.AttributeValueMemberN)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(n.Value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
+
+func str(v types.AttributeValue) string {
+	if s, ok := v.(*types.AttributeValueMemberS); ok {
+		return s.Value
+	}
+	return ""
+}
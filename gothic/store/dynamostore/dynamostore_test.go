@@ -0,0 +1,167 @@
+package dynamostore_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/bgdsh/goth/gothic/store/dynamostore"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDynamoDB keeps the items of a single table keyed by "id".
+type fakeDynamoDB struct {
+	items map[string]map[string]types.AttributeValue
+}
+
+func key(item map[string]types.AttributeValue) string {
+	return item["id"].(*types.AttributeValueMemberS).Value
+}
+
+func (f *fakeDynamoDB) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: f.items[key(in.Key)]}, nil
+}
+
+func (f *fakeDynamoDB) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.items[key(in.Item)] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(_ context.Context, in *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	delete(f.items, key(in.Key))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func newStore() (*dynamostore.Store, *fakeDynamoDB) {
+	db := &fakeDynamoDB{items: map[string]map[string]types.AttributeValue{}}
+	return dynamostore.New(db, "gothic"), db
+}
+
+func Test_SaveAndLoad(t *testing.T) {
+	a := assert.New(t)
+	s, db := newStore()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	res := httptest.NewRecorder()
+	sess, err := s.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(sess.IsNew)
+
+	sess.Values["faux"] = "\x1f\x8b\x08\x00\xff"
+	a.NoError(s.Save(req, res, sess))
+	a.Contains(db.items, "session#"+sess.ID)
+	a.IsType(&types.AttributeValueMemberN{}, db.items["session#"+sess.ID]["expires_at"])
+
+	cookies := res.Result().Cookies()
+	a.Len(cookies, 1)
+
+	req2 := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req2.AddCookie(cookies[0])
+	sess2, err := s.Get(req2, "_gothic_session")
+	a.NoError(err)
+	a.False(sess2.IsNew)
+	a.Equal("\x1f\x8b\x08\x00\xff", sess2.Values["faux"])
+}
+
+func Test_Expired(t *testing.T) {
+	a := assert.New(t)
+	s, _ := newStore()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	res := httptest.NewRecorder()
+	sess, _ := s.Get(req, "_gothic_session")
+	sess.Values["faux"] = "value"
+	a.NoError(s.Save(req, res, sess))
+
+	// DynamoDB may keep the item around well after its TTL.
+	goth.Clock = func() time.Time { return time.Now().Add(dynamostore.DefaultMaxAge * time.Second) }
+	defer func() { goth.Clock = time.Now }()
+
+	req2 := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req2.AddCookie(res.Result().Cookies()[0])
+	sess2, err := s.Get(req2, "_gothic_session")
+	a.NoError(err)
+	a.True(sess2.IsNew)
+	a.Empty(sess2.ID)
+	a.Nil(sess2.Values["faux"])
+}
+
+func Test_Delete(t *testing.T) {
+	a := assert.New(t)
+	s, db := newStore()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	sess, _ := s.Get(req, "_gothic_session")
+	sess.Values["faux"] = "value"
+	a.NoError(s.Save(req, httptest.NewRecorder(), sess))
+
+	sess.Options.MaxAge = -1
+	a.NoError(s.Save(req, httptest.NewRecorder(), sess))
+	a.NotContains(db.items, "session#"+sess.ID)
+}
+
+func Test_Tokens(t *testing.T) {
+	a := assert.New(t)
+	s, db := newStore()
+	ctx := context.Background()
+	expiresAt := time.Unix(1893456000, 0)
+
+	a.NoError(s.SaveToken(ctx, store.Token{
+		Provider:     "faux",
+		UserID:       "1234",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    expiresAt,
+	}))
+	// The token must not be subject to the table's TTL.
+	a.Nil(db.items["token#faux#1234"]["expires_at"])
+
+	token, err := s.LoadToken(ctx, "faux", "1234")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+	a.Equal("refresh", token.RefreshToken)
+	a.True(expiresAt.Equal(token.ExpiresAt))
+
+	a.NoError(s.DeleteToken(ctx, "faux", "1234"))
+	_, err = s.LoadToken(ctx, "faux", "1234")
+	a.Equal(store.ErrTokenNotFound, err)
+}
+
+func Test_RememberMeTokens(t *testing.T) {
+	a := assert.New(t)
+	s, db := newStore()
+	ctx := context.Background()
+	rotatedAt := time.Unix(1893456000, 0)
+	expiresAt := rotatedAt.Add(30 * 24 * time.Hour)
+
+	a.NoError(s.SaveRememberMeToken(ctx, store.RememberMeToken{
+		Selector:              "sel",
+		Provider:              "faux",
+		UserID:                "1234",
+		ValidatorHash:         "new",
+		PreviousValidatorHash: "old",
+		RotatedAt:             rotatedAt,
+		ExpiresAt:             expiresAt,
+	}))
+	// Expired tokens are left to the table's TTL.
+	a.IsType(&types.AttributeValueMemberN{}, db.items["remember_me#sel"]["expires_at"])
+
+	token, err := s.LoadRememberMeToken(ctx, "sel")
+	a.NoError(err)
+	a.Equal("faux", token.Provider)
+	a.Equal("1234", token.UserID)
+	a.Equal("new", token.ValidatorHash)
+	a.Equal("old", token.PreviousValidatorHash)
+	a.True(rotatedAt.Equal(token.RotatedAt))
+	a.True(expiresAt.Equal(token.ExpiresAt))
+
+	a.NoError(s.DeleteRememberMeToken(ctx, "sel"))
+	_, err = s.LoadRememberMeToken(ctx, "sel")
+	a.Equal(store.ErrRememberMeTokenNotFound, err)
+}
@@ -0,0 +1,13 @@
+module github.com/bgdsh/goth/gothic/store/dynamostore
+
+go 1.21
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.32.7
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.38.1
+	github.com/bgdsh/goth v0.0.0
+	github.com/gorilla/sessions v1.2.1
+	github.com/stretchr/testify v1.7.1
+)
+
+replace github.com/bgdsh/goth => ../../..
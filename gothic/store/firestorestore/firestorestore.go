@@ -0,0 +1,262 @@
+// Package firestorestore implements a gorilla sessions.Store, a
+// store.TokenStore and a store.RememberMeStore backed by Cloud Firestore, for
+// serverless deployments such as Cloud Run or Cloud Functions.
+//
+// Sessions are kept in one document each, with an "expires_at" timestamp
+// field. Configure a TTL policy on that field for the sessions collection so
+// Firestore removes abandoned sessions on its own:
+//
+//	gcloud firestore fields ttls update expires_at \
+//		--collection-group=gothic_sessions --enable-ttl
+//
+// Remember-me tokens have the same field, so the policy can be enabled for
+// the gothic_remember_me collection group as well.
+//
+// Firestore deletes expired documents lazily, so the expiry is also checked
+// when a session is loaded.
+package firestorestore
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/gorilla/sessions"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultMaxAge is the lifetime, in seconds, of a session.
+	DefaultMaxAge = 5 * 60
+
+	// DefaultSessionCollection is the collection sessions are stored in.
+	DefaultSessionCollection = "gothic_sessions"
+
+	// DefaultTokenCollection is the collection tokens are stored in.
+	DefaultTokenCollection = "gothic_tokens"
+
+	// DefaultRememberMeCollection is the collection remember-me tokens are
+	// stored in.
+	DefaultRememberMeCollection = "gothic_remember_me"
+)
+
+// Store keeps sessions and tokens in Firestore.
+type Store struct {
+	Client               *firestore.Client
+	SessionCollection    string
+	TokenCollection      string
+	RememberMeCollection string
+	Options              *sessions.Options
+}
+
+var (
+	_ sessions.Store        = &Store{}
+	_ store.TokenStore      = &Store{}
+	_ store.RememberMeStore = &Store{}
+)
+
+type sessionDoc struct {
+	Data      []byte    `firestore:"data"`
+	ExpiresAt time.Time `firestore:"expires_at"`
+}
+
+type tokenDoc struct {
+	Provider          string    `firestore:"provider"`
+	UserID            string    `firestore:"user_id"`
+	AccessToken       string    `firestore:"access_token"`
+	AccessTokenSecret string    `firestore:"access_token_secret"`
+	RefreshToken      string    `firestore:"refresh_token"`
+	IDToken           string    `firestore:"id_token"`
+	ExpiresAt         time.Time `firestore:"token_expires_at,omitempty"`
+}
+
+type rememberMeDoc struct {
+	Provider              string    `firestore:"provider"`
+	UserID                string    `firestore:"user_id"`
+	ValidatorHash         string    `firestore:"validator_hash"`
+	PreviousValidatorHash string    `firestore:"previous_validator_hash"`
+	RotatedAt             time.Time `firestore:"rotated_at,omitempty"`
+	ExpiresAt             time.Time `firestore:"expires_at"`
+}
+
+// New creates a new Firestore backed store using the default collections.
+// Cookies are HttpOnly, valid for the whole site and expire after
+// DefaultMaxAge seconds; change Options to tailor them.
+func New(client *firestore.Client) *Store {
+	return &Store{
+		Client:               client,
+		SessionCollection:    DefaultSessionCollection,
+		TokenCollection:      DefaultTokenCollection,
+		RememberMeCollection: DefaultRememberMeCollection,
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   DefaultMaxAge,
+			HttpOnly: true,
+		},
+	}
+}
+
+// Get returns the named session for the request, loading it from Firestore
+// only once per request.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the named session, loading its values from Firestore when the
+// request carries a cookie for a session that has not expired yet.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	return store.NewSession(r, s, backend{s}, name, s.Options)
+}
+
+// Save persists the session and writes the session cookie. A session with a
+// MaxAge of zero or less is deleted.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	return store.SaveSession(r, w, backend{s}, session)
+}
+
+// backend is the store.SessionBackend of a Store.
+type backend struct {
+	*Store
+}
+
+func (b backend) LoadSession(ctx context.Context, id string) ([]byte, error) {
+	snap, err := b.sessionDoc(id).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, store.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc sessionDoc
+	if err := snap.DataTo(&doc); err != nil {
+		return nil, err
+	}
+	if !goth.Now().Before(doc.ExpiresAt) {
+		return nil, store.ErrSessionNotFound
+	}
+	return doc.Data, nil
+}
+
+func (b backend) SaveSession(ctx context.Context, id string, data []byte, maxAge time.Duration) error {
+	_, err := b.sessionDoc(id).Set(ctx, sessionDoc{Data: data, ExpiresAt: goth.Now().Add(maxAge)})
+	return err
+}
+
+func (b backend) DeleteSession(ctx context.Context, id string) error {
+	_, err := b.sessionDoc(id).Delete(ctx)
+	return err
+}
+
+// SaveToken persists token, replacing the one previously saved for the same
+// provider and user. The token expiry is stored as token_expires_at so that a
+// TTL policy on expires_at never removes a refresh token.
+func (s *Store) SaveToken(ctx context.Context, token store.Token) error {
+	_, err := s.tokenDoc(token.Provider, token.UserID).Set(ctx, tokenDoc{
+		Provider:          token.Provider,
+		UserID:            token.UserID,
+		AccessToken:       token.AccessToken,
+		AccessTokenSecret: token.AccessTokenSecret,
+		RefreshToken:      token.RefreshToken,
+		IDToken:           token.IDToken,
+		ExpiresAt:         token.ExpiresAt,
+	})
+	return err
+}
+
+// LoadToken returns the token saved for the provider and user, or
+// store.ErrTokenNotFound.
+func (s *Store) LoadToken(ctx context.Context, provider, userID string) (store.Token, error) {
+	snap, err := s.tokenDoc(provider, userID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return store.Token{}, store.ErrTokenNotFound
+	}
+	if err != nil {
+		return store.Token{}, err
+	}
+
+	var doc tokenDoc
+	if err := snap.DataTo(&doc); err != nil {
+		return store.Token{}, err
+	}
+	return store.Token{
+		Provider:          provider,
+		UserID:            userID,
+		AccessToken:       doc.AccessToken,
+		AccessTokenSecret: doc.AccessTokenSecret,
+		RefreshToken:      doc.RefreshToken,
+		IDToken:           doc.IDToken,
+		ExpiresAt:         doc.ExpiresAt,
+	}, nil
+}
+
+// DeleteToken removes the token saved for the provider and user, if any.
+func (s *Store) DeleteToken(ctx context.Context, provider, userID string) error {
+	_, err := s.tokenDoc(provider, userID).Delete(ctx)
+	return err
+}
+
+// SaveRememberMeToken persists token, replacing the one previously saved
+// under the same selector.
+func (s *Store) SaveRememberMeToken(ctx context.Context, token store.RememberMeToken) error {
+	_, err := s.rememberMeDoc(token.Selector).Set(ctx, rememberMeDoc{
+		Provider:              token.Provider,
+		UserID:                token.UserID,
+		ValidatorHash:         token.ValidatorHash,
+		PreviousValidatorHash: token.PreviousValidatorHash,
+		RotatedAt:             token.RotatedAt,
+		ExpiresAt:             token.ExpiresAt,
+	})
+	return err
+}
+
+// LoadRememberMeToken returns the token saved under selector, or
+// store.ErrRememberMeTokenNotFound.
+func (s *Store) LoadRememberMeToken(ctx context.Context, selector string) (store.RememberMeToken, error) {
+	snap, err := s.rememberMeDoc(selector).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return store.RememberMeToken{}, store.ErrRememberMeTokenNotFound
+	}
+	if err != nil {
+		return store.RememberMeToken{}, err
+	}
+
+	var doc rememberMeDoc
+	if err := snap.DataTo(&doc); err != nil {
+		return store.RememberMeToken{}, err
+	}
+	return store.RememberMeToken{
+		Selector:              selector,
+		Provider:              doc.Provider,
+		UserID:                doc.UserID,
+		ValidatorHash:         doc.ValidatorHash,
+		PreviousValidatorHash: doc.PreviousValidatorHash,
+		RotatedAt:             doc.RotatedAt,
+		ExpiresAt:             doc.ExpiresAt,
+	}, nil
+}
+
+// DeleteRememberMeToken removes the token saved under selector, if any.
+func (s *Store) DeleteRememberMeToken(ctx context.Context, selector string) error {
+	_, err := s.rememberMeDoc(selector).Delete(ctx)
+	return err
+}
+
+func (s *Store) sessionDoc(id string) *firestore.DocumentRef {
+	return s.Client.Collection(s.SessionCollection).Doc(id)
+}
+
+// tokenDoc escapes the provider and user ID, which may contain a slash, to
+// build a valid document ID.
+func (s *Store) tokenDoc(provider, userID string) *firestore.DocumentRef {
+	return s.Client.Collection(s.TokenCollection).Doc(url.PathEscape(provider) + ":" + url.PathEscape(userID))
+}
+
+func (s *Store) rememberMeDoc(selector string) *firestore.DocumentRef {
+	return s.Client.Collection(s.RememberMeCollection).Doc(url.PathEscape(selector))
+}
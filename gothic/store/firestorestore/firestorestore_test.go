@@ -0,0 +1,121 @@
+package firestorestore_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/bgdsh/goth/gothic/store/firestorestore"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStore connects to the Firestore emulator, started with
+// `gcloud beta emulators firestore start`. The tests are skipped when
+// FIRESTORE_EMULATOR_HOST is not set.
+func newStore(t *testing.T) *firestorestore.Store {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+
+	client, err := firestore.NewClient(context.Background(), "goth-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return firestorestore.New(client)
+}
+
+func Test_SaveAndLoad(t *testing.T) {
+	a := assert.New(t)
+	s := newStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	res := httptest.NewRecorder()
+	sess, err := s.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(sess.IsNew)
+
+	sess.Values["faux"] = "\x1f\x8b\x08\x00\xff"
+	a.NoError(s.Save(req, res, sess))
+
+	req2 := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req2.AddCookie(res.Result().Cookies()[0])
+	sess2, err := s.Get(req2, "_gothic_session")
+	a.NoError(err)
+	a.False(sess2.IsNew)
+	a.Equal("\x1f\x8b\x08\x00\xff", sess2.Values["faux"])
+
+	sess2.Options.MaxAge = -1
+	a.NoError(s.Save(req2, httptest.NewRecorder(), sess2))
+}
+
+func Test_Tokens(t *testing.T) {
+	a := assert.New(t)
+	s := newStore(t)
+	ctx := context.Background()
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a.NoError(s.SaveToken(ctx, store.Token{
+		Provider:     "faux",
+		UserID:       "users/1234",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    expiresAt,
+	}))
+
+	token, err := s.LoadToken(ctx, "faux", "users/1234")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+	a.True(expiresAt.Equal(token.ExpiresAt))
+
+	a.NoError(s.DeleteToken(ctx, "faux", "users/1234"))
+	_, err = s.LoadToken(ctx, "faux", "users/1234")
+	a.Equal(store.ErrTokenNotFound, err)
+}
+
+func Test_RememberMeTokens(t *testing.T) {
+	a := assert.New(t)
+	s := newStore(t)
+	ctx := context.Background()
+	rotatedAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := rotatedAt.Add(30 * 24 * time.Hour)
+
+	a.NoError(s.SaveRememberMeToken(ctx, store.RememberMeToken{
+		Selector:              "sel",
+		Provider:              "faux",
+		UserID:                "1234",
+		ValidatorHash:         "new",
+		PreviousValidatorHash: "old",
+		RotatedAt:             rotatedAt,
+		ExpiresAt:             expiresAt,
+	}))
+
+	token, err := s.LoadRememberMeToken(ctx, "sel")
+	a.NoError(err)
+	a.Equal("faux", token.Provider)
+	a.Equal("new", token.ValidatorHash)
+	a.Equal("old", token.PreviousValidatorHash)
+	a.True(rotatedAt.Equal(token.RotatedAt))
+	a.True(expiresAt.Equal(token.ExpiresAt))
+
+	a.NoError(s.DeleteRememberMeToken(ctx, "sel"))
+	_, err = s.LoadRememberMeToken(ctx, "sel")
+	a.Equal(store.ErrRememberMeTokenNotFound, err)
+}
+
+func Test_UnknownID(t *testing.T) {
+	a := assert.New(t)
+	s := newStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	req.AddCookie(&http.Cookie{Name: "_gothic_session", Value: "chosen-by-attacker"})
+	sess, err := s.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(sess.IsNew)
+	a.Empty(sess.ID)
+}
@@ -0,0 +1,13 @@
+module github.com/bgdsh/goth/gothic/store/firestorestore
+
+go 1.21
+
+require (
+	cloud.google.com/go/firestore v1.6.1
+	github.com/bgdsh/goth v0.0.0
+	github.com/gorilla/sessions v1.2.1
+	github.com/stretchr/testify v1.7.1
+	google.golang.org/grpc v1.41.0
+)
+
+replace github.com/bgdsh/goth => ../../..
@@ -0,0 +1,13 @@
+module github.com/bgdsh/goth/gothic/store/redisstore
+
+go 1.15
+
+require (
+	github.com/alicebob/miniredis/v2 v2.17.0
+	github.com/bgdsh/goth v0.0.0
+	github.com/go-redis/redis/v8 v8.11.4
+	github.com/gorilla/sessions v1.2.1
+	github.com/stretchr/testify v1.7.1
+)
+
+replace github.com/bgdsh/goth => ../../..
@@ -0,0 +1,94 @@
+// Package redisstore implements a gorilla sessions.Store backed by Redis, to
+// be used as gothic.Store by applications running on more than one server.
+//
+// Only a random session ID is kept in the cookie. The session values are
+// JSON encoded and stored in Redis with a TTL matching the cookie's
+// MaxAge, so abandoned authentication attempts expire on their own.
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/sessions"
+)
+
+const (
+	// DefaultKeyPrefix is prepended to the session ID to build the Redis key.
+	DefaultKeyPrefix = "gothic:session:"
+
+	// DefaultMaxAge is the lifetime, in seconds, of a session. An auth flow
+	// rarely takes more than a few minutes to complete.
+	DefaultMaxAge = 5 * 60
+)
+
+// Store is a sessions.Store that keeps session values in Redis.
+type Store struct {
+	Client    redis.UniversalClient
+	Options   *sessions.Options
+	KeyPrefix string
+}
+
+var _ sessions.Store = &Store{}
+
+// New creates a new Redis backed store. Cookies are HttpOnly, valid for the
+// whole site and expire after DefaultMaxAge seconds; change Options to
+// tailor them.
+func New(client redis.UniversalClient) *Store {
+	return &Store{
+		Client: client,
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   DefaultMaxAge,
+			HttpOnly: true,
+		},
+		KeyPrefix: DefaultKeyPrefix,
+	}
+}
+
+// Get returns the named session for the request, loading it from Redis
+// only once per request.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the named session, loading its values from Redis when the
+// request carries a cookie for a session that has not expired yet.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	return store.NewSession(r, s, backend{s}, name, s.Options)
+}
+
+// Save persists the session to Redis and writes the session cookie. A
+// session with a MaxAge of zero or less is deleted.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	return store.SaveSession(r, w, backend{s}, session)
+}
+
+func (s *Store) key(id string) string {
+	return s.KeyPrefix + id
+}
+
+// backend is the store.SessionBackend of a Store.
+type backend struct {
+	*Store
+}
+
+func (b backend) LoadSession(ctx context.Context, id string) ([]byte, error) {
+	data, err := b.Client.Get(ctx, b.key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, store.ErrSessionNotFound
+	}
+	return data, err
+}
+
+func (b backend) SaveSession(ctx context.Context, id string, data []byte, maxAge time.Duration) error {
+	return b.Client.Set(ctx, b.key(id), data, maxAge).Err()
+}
+
+func (b backend) DeleteSession(ctx context.Context, id string) error {
+	return b.Client.Del(ctx, b.key(id)).Err()
+}
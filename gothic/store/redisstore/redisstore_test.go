@@ -0,0 +1,103 @@
+package redisstore_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bgdsh/goth/gothic/store/redisstore"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStore(t *testing.T) (*redisstore.Store, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mr.Close)
+	return redisstore.New(redis.NewClient(&redis.Options{Addr: mr.Addr()})), mr
+}
+
+func Test_SaveAndLoad(t *testing.T) {
+	a := assert.New(t)
+	store, mr := newStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	res := httptest.NewRecorder()
+	sess, err := store.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(sess.IsNew)
+
+	sess.Values["faux"] = "\x1f\x8b\x08\x00\xff"
+	a.NoError(store.Save(req, res, sess))
+	a.NotEmpty(sess.ID)
+	a.True(mr.Exists(redisstore.DefaultKeyPrefix + sess.ID))
+	a.Equal(redisstore.DefaultMaxAge*time.Second, mr.TTL(redisstore.DefaultKeyPrefix+sess.ID))
+
+	cookies := res.Result().Cookies()
+	a.Len(cookies, 1)
+	a.Equal(sess.ID, cookies[0].Value)
+
+	req2 := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req2.AddCookie(cookies[0])
+	sess2, err := store.Get(req2, "_gothic_session")
+	a.NoError(err)
+	a.False(sess2.IsNew)
+	a.Equal("\x1f\x8b\x08\x00\xff", sess2.Values["faux"])
+}
+
+func Test_Expired(t *testing.T) {
+	a := assert.New(t)
+	store, mr := newStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	res := httptest.NewRecorder()
+	sess, _ := store.Get(req, "_gothic_session")
+	sess.Values["faux"] = "value"
+	a.NoError(store.Save(req, res, sess))
+
+	mr.FastForward(redisstore.DefaultMaxAge * time.Second)
+
+	req2 := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req2.AddCookie(res.Result().Cookies()[0])
+	sess2, err := store.Get(req2, "_gothic_session")
+	a.NoError(err)
+	a.True(sess2.IsNew)
+	a.Nil(sess2.Values["faux"])
+}
+
+func Test_Delete(t *testing.T) {
+	a := assert.New(t)
+	store, mr := newStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	sess, _ := store.Get(req, "_gothic_session")
+	sess.Values["faux"] = "value"
+	a.NoError(store.Save(req, httptest.NewRecorder(), sess))
+
+	sess.Options.MaxAge = -1
+	a.NoError(store.Save(req, httptest.NewRecorder(), sess))
+	a.False(mr.Exists(redisstore.DefaultKeyPrefix + sess.ID))
+}
+
+func Test_UnknownID(t *testing.T) {
+	a := assert.New(t)
+	store, mr := newStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	req.AddCookie(&http.Cookie{Name: "_gothic_session", Value: "chosen-by-attacker"})
+	res := httptest.NewRecorder()
+	sess, err := store.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(sess.IsNew)
+	a.Empty(sess.ID)
+
+	sess.Values["faux"] = "value"
+	a.NoError(store.Save(req, res, sess))
+	a.NotEqual("chosen-by-attacker", sess.ID)
+	a.False(mr.Exists(redisstore.DefaultKeyPrefix + "chosen-by-attacker"))
+	a.Equal(sess.ID, res.Result().Cookies()[0].Value)
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRememberMeTokenNotFound is returned by
+// RememberMeStore.LoadRememberMeToken when no token was saved under the given
+// selector.
+var ErrRememberMeTokenNotFound = errors.New("store: remember-me token not found")
+
+// RememberMeToken is a login token of gothic.RememberMe, named by the selector
+// of its cookie. Only the hashes of the validators are kept.
+type RememberMeToken struct {
+	Selector string
+
+	// Provider and UserID name the remembered user.
+	Provider string
+	UserID   string
+
+	// ValidatorHash is the hash of the validator of the current cookie.
+	ValidatorHash string
+
+	// PreviousValidatorHash is the hash of the validator replaced at
+	// RotatedAt, still accepted for a short while so that concurrent
+	// requests made with the previous cookie are not taken for a replay.
+	PreviousValidatorHash string
+	RotatedAt             time.Time
+
+	ExpiresAt time.Time
+}
+
+// RememberMeStore persists the login tokens of gothic.RememberMe, keyed by
+// selector.
+type RememberMeStore interface {
+	SaveRememberMeToken(ctx context.Context, token RememberMeToken) error
+	LoadRememberMeToken(ctx context.Context, selector string) (RememberMeToken, error)
+	DeleteRememberMeToken(ctx context.Context, selector string) error
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/sessions"
+)
+
+// ErrSessionNotFound is returned by SessionBackend.LoadSession when no
+// session was saved for the given ID, or it has expired.
+var ErrSessionNotFound = errors.New("store: session not found")
+
+// SessionBackend persists the encoded values of sessions by session ID. It
+// is what a sessions.Store built with NewSession and SaveSession implements
+// for its database.
+type SessionBackend interface {
+	// LoadSession returns the values saved for id, or ErrSessionNotFound.
+	LoadSession(ctx context.Context, id string) ([]byte, error)
+	// SaveSession saves the values of id, to expire after maxAge.
+	SaveSession(ctx context.Context, id string, data []byte, maxAge time.Duration) error
+	// DeleteSession deletes the values of id, if any.
+	DeleteSession(ctx context.Context, id string) error
+}
+
+// NewSession returns the named session of s, loading its values from b when
+// the request carries a cookie for a session that has not expired yet. It
+// implements sessions.Store.New, with a copy of options.
+//
+// The ID of a cookie for which b holds no session is dropped rather than
+// reused, so that a client cannot choose the ID of its next session.
+func NewSession(r *http.Request, s sessions.Store, b SessionBackend, name string, options *sessions.Options) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	data, err := b.LoadSession(r.Context(), c.Value)
+	if errors.Is(err, ErrSessionNotFound) {
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+	if err := DecodeValues(data, &session.Values); err != nil {
+		return session, err
+	}
+	session.ID = c.Value
+	session.IsNew = false
+	return session, nil
+}
+
+// SaveSession saves session to b and writes the session cookie. It
+// implements sessions.Store.Save: a session with a MaxAge of zero or less is
+// deleted, and a session without ID gets a new one.
+func SaveSession(r *http.Request, w http.ResponseWriter, b SessionBackend, session *sessions.Session) error {
+	if session.Options.MaxAge <= 0 {
+		if session.ID != "" {
+			if err := b.DeleteSession(r.Context(), session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		id, err := NewSessionID()
+		if err != nil {
+			return err
+		}
+		session.ID = id
+	}
+
+	data, err := EncodeValues(session.Values)
+	if err != nil {
+		return err
+	}
+	maxAge := time.Duration(session.Options.MaxAge) * time.Second
+	if err := b.SaveSession(r.Context(), session.ID, data, maxAge); err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), session.ID, session.Options))
+	return nil
+}
@@ -0,0 +1,127 @@
+package sqlstore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect identifies the SQL database behind a Store. It decides the column
+// types, the placeholder style and how rows are upserted.
+type Dialect int
+
+const (
+	// Postgres is PostgreSQL 9.5 or later.
+	Postgres Dialect = iota
+	// MySQL is MySQL 5.7 or later, or MariaDB.
+	MySQL
+	// SQLite is SQLite 3.24 or later.
+	SQLite
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case SQLite:
+		return "sqlite"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// migrations returns the statements creating the schema. They only create
+// what is missing, so running them again is harmless.
+func (d Dialect) migrations() []string {
+	blob, timestamp := "BLOB", "TIMESTAMP"
+	switch d {
+	case Postgres:
+		blob = "BYTEA"
+	case MySQL:
+		blob, timestamp = "LONGBLOB", "DATETIME(6)"
+	}
+
+	return []string{
+		`CREATE TABLE IF NOT EXISTS gothic_sessions (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	data ` + blob + ` NOT NULL,
+	expires_at ` + timestamp + ` NOT NULL
+)`,
+		`CREATE TABLE IF NOT EXISTS gothic_tokens (
+	provider VARCHAR(64) NOT NULL,
+	user_id VARCHAR(255) NOT NULL,
+	access_token TEXT NOT NULL,
+	access_token_secret TEXT NOT NULL,
+	refresh_token TEXT NOT NULL,
+	id_token TEXT NOT NULL,
+	expires_at ` + timestamp + ` NULL,
+	PRIMARY KEY (provider, user_id)
+)`,
+		`CREATE TABLE IF NOT EXISTS gothic_remember_me (
+	selector VARCHAR(64) NOT NULL PRIMARY KEY,
+	provider VARCHAR(64) NOT NULL,
+	user_id VARCHAR(255) NOT NULL,
+	validator_hash VARCHAR(64) NOT NULL,
+	previous_validator_hash VARCHAR(64) NOT NULL,
+	rotated_at ` + timestamp + ` NULL,
+	expires_at ` + timestamp + ` NOT NULL
+)`,
+	}
+}
+
+// upsert returns an INSERT statement for table that updates the non key
+// columns when a row with the same key already exists.
+func (d Dialect) upsert(table string, keys, columns []string) string {
+	all := append(append([]string{}, keys...), columns...)
+
+	updates := make([]string, len(columns))
+	for i, c := range columns {
+		if d == MySQL {
+			updates[i] = fmt.Sprintf("%s = VALUES(%s)", c, c)
+		} else {
+			updates[i] = fmt.Sprintf("%s = excluded.%s", c, c)
+		}
+	}
+
+	q := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(all, ", "), d.placeholders(1, len(all)))
+	if d == MySQL {
+		return q + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+	return q + fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(updates, ", "))
+}
+
+// placeholders returns n comma separated bind parameters, numbered from
+// start when the dialect needs it.
+func (d Dialect) placeholders(start, n int) string {
+	p := make([]string, n)
+	for i := range p {
+		p[i] = d.placeholder(start + i)
+	}
+	return strings.Join(p, ", ")
+}
+
+func (d Dialect) placeholder(n int) string {
+	if d == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// rebind rewrites a query written with ? placeholders for the dialect.
+func (d Dialect) rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(d.placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
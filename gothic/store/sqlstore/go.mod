@@ -0,0 +1,12 @@
+module github.com/bgdsh/goth/gothic/store/sqlstore
+
+go 1.15
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
+	github.com/bgdsh/goth v0.0.0
+	github.com/gorilla/sessions v1.2.1
+	github.com/stretchr/testify v1.7.1
+)
+
+replace github.com/bgdsh/goth => ../../..
@@ -0,0 +1,222 @@
+// Package sqlstore implements a gorilla sessions.Store, a store.TokenStore
+// and a store.RememberMeStore on top of database/sql, for applications that already run PostgreSQL,
+// MySQL or SQLite and would rather not add Redis to their stack.
+//
+// The package does not import any driver; open the *sql.DB with the driver of
+// your choice and call Migrate once at startup to create the tables:
+//
+//	db, err := sql.Open("postgres", dsn)
+//	...
+//	s := sqlstore.New(db, sqlstore.Postgres)
+//	if err := s.Migrate(ctx); err != nil {
+//		...
+//	}
+//	gothic.Store = s
+//
+// Expired sessions are ignored when loading but stay in the table until
+// DeleteExpired is called, typically from a periodic job.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/gorilla/sessions"
+)
+
+// DefaultMaxAge is the lifetime, in seconds, of a session.
+const DefaultMaxAge = 5 * 60
+
+// Store keeps sessions and tokens in a SQL database.
+type Store struct {
+	DB      *sql.DB
+	Dialect Dialect
+	Options *sessions.Options
+}
+
+var (
+	_ sessions.Store        = &Store{}
+	_ store.TokenStore      = &Store{}
+	_ store.RememberMeStore = &Store{}
+)
+
+// New creates a new SQL backed store. Cookies are HttpOnly, valid for the
+// whole site and expire after DefaultMaxAge seconds; change Options to
+// tailor them.
+func New(db *sql.DB, dialect Dialect) *Store {
+	return &Store{
+		DB:      db,
+		Dialect: dialect,
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   DefaultMaxAge,
+			HttpOnly: true,
+		},
+	}
+}
+
+// Migrate creates the gothic_sessions, gothic_tokens and gothic_remember_me
+// tables if they do not exist yet.
+func (s *Store) Migrate(ctx context.Context) error {
+	for _, m := range s.Dialect.migrations() {
+		if _, err := s.DB.ExecContext(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the named session for the request, loading it from the
+// database only once per request.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the named session, loading its values from the database when
+// the request carries a cookie for a session that has not expired yet.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	return store.NewSession(r, s, backend{s}, name, s.Options)
+}
+
+// Save persists the session and writes the session cookie. A session with a
+// MaxAge of zero or less is deleted.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	return store.SaveSession(r, w, backend{s}, session)
+}
+
+// DeleteExpired removes the sessions that have expired and returns how many
+// were removed.
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	q := s.Dialect.rebind("DELETE FROM gothic_sessions WHERE expires_at <= ?")
+	res, err := s.DB.ExecContext(ctx, q, goth.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// backend is the store.SessionBackend of a Store.
+type backend struct {
+	*Store
+}
+
+func (b backend) LoadSession(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	q := b.Dialect.rebind("SELECT data FROM gothic_sessions WHERE id = ? AND expires_at > ?")
+	err := b.DB.QueryRowContext(ctx, q, id, goth.Now().UTC()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrSessionNotFound
+	}
+	return data, err
+}
+
+func (b backend) SaveSession(ctx context.Context, id string, data []byte, maxAge time.Duration) error {
+	q := b.Dialect.upsert("gothic_sessions", []string{"id"}, []string{"data", "expires_at"})
+	_, err := b.DB.ExecContext(ctx, q, id, data, goth.Now().Add(maxAge).UTC())
+	return err
+}
+
+func (b backend) DeleteSession(ctx context.Context, id string) error {
+	q := b.Dialect.rebind("DELETE FROM gothic_sessions WHERE id = ?")
+	_, err := b.DB.ExecContext(ctx, q, id)
+	return err
+}
+
+// SaveToken persists token, replacing the one previously saved for the same
+// provider and user.
+func (s *Store) SaveToken(ctx context.Context, token store.Token) error {
+	var expiresAt sql.NullTime
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: token.ExpiresAt.UTC(), Valid: true}
+	}
+
+	q := s.Dialect.upsert("gothic_tokens",
+		[]string{"provider", "user_id"},
+		[]string{"access_token", "access_token_secret", "refresh_token", "id_token", "expires_at"})
+	_, err := s.DB.ExecContext(ctx, q,
+		token.Provider, token.UserID,
+		token.AccessToken, token.AccessTokenSecret, token.RefreshToken, token.IDToken, expiresAt)
+	return err
+}
+
+// LoadToken returns the token saved for the provider and user, or
+// store.ErrTokenNotFound.
+func (s *Store) LoadToken(ctx context.Context, provider, userID string) (store.Token, error) {
+	token := store.Token{Provider: provider, UserID: userID}
+	var expiresAt sql.NullTime
+
+	q := s.Dialect.rebind(`SELECT access_token, access_token_secret, refresh_token, id_token, expires_at
+FROM gothic_tokens WHERE provider = ? AND user_id = ?`)
+	err := s.DB.QueryRowContext(ctx, q, provider, userID).Scan(
+		&token.AccessToken, &token.AccessTokenSecret, &token.RefreshToken, &token.IDToken, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.Token{}, store.ErrTokenNotFound
+	}
+	if err != nil {
+		return store.Token{}, err
+	}
+
+	if expiresAt.Valid {
+		token.ExpiresAt = expiresAt.Time
+	}
+	return token, nil
+}
+
+// DeleteToken removes the token saved for the provider and user, if any.
+func (s *Store) DeleteToken(ctx context.Context, provider, userID string) error {
+	q := s.Dialect.rebind("DELETE FROM gothic_tokens WHERE provider = ? AND user_id = ?")
+	_, err := s.DB.ExecContext(ctx, q, provider, userID)
+	return err
+}
+
+// SaveRememberMeToken persists token, replacing the one previously saved
+// under the same selector.
+func (s *Store) SaveRememberMeToken(ctx context.Context, token store.RememberMeToken) error {
+	var rotatedAt sql.NullTime
+	if !token.RotatedAt.IsZero() {
+		rotatedAt = sql.NullTime{Time: token.RotatedAt.UTC(), Valid: true}
+	}
+
+	q := s.Dialect.upsert("gothic_remember_me",
+		[]string{"selector"},
+		[]string{"provider", "user_id", "validator_hash", "previous_validator_hash", "rotated_at", "expires_at"})
+	_, err := s.DB.ExecContext(ctx, q,
+		token.Selector,
+		token.Provider, token.UserID, token.ValidatorHash, token.PreviousValidatorHash, rotatedAt, token.ExpiresAt.UTC())
+	return err
+}
+
+// LoadRememberMeToken returns the token saved under selector, or
+// store.ErrRememberMeTokenNotFound.
+func (s *Store) LoadRememberMeToken(ctx context.Context, selector string) (store.RememberMeToken, error) {
+	token := store.RememberMeToken{Selector: selector}
+	var rotatedAt sql.NullTime
+
+	q := s.Dialect.rebind(`SELECT provider, user_id, validator_hash, previous_validator_hash, rotated_at, expires_at
+FROM gothic_remember_me WHERE selector = ?`)
+	err := s.DB.QueryRowContext(ctx, q, selector).Scan(
+		&token.Provider, &token.UserID, &token.ValidatorHash, &token.PreviousValidatorHash, &rotatedAt, &token.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.RememberMeToken{}, store.ErrRememberMeTokenNotFound
+	}
+	if err != nil {
+		return store.RememberMeToken{}, err
+	}
+
+	if rotatedAt.Valid {
+		token.RotatedAt = rotatedAt.Time
+	}
+	return token, nil
+}
+
+// DeleteRememberMeToken removes the token saved under selector, if any.
+func (s *Store) DeleteRememberMeToken(ctx context.Context, selector string) error {
+	q := s.Dialect.rebind("DELETE FROM gothic_remember_me WHERE selector = ?")
+	_, err := s.DB.ExecContext(ctx, q, selector)
+	return err
+}
@@ -0,0 +1,227 @@
+package sqlstore_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/bgdsh/goth/gothic/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStore(t *testing.T, dialect sqlstore.Dialect) (*sqlstore.Store, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return sqlstore.New(db, dialect), mock
+}
+
+func Test_Migrate(t *testing.T) {
+	a := assert.New(t)
+
+	types := map[sqlstore.Dialect]string{
+		sqlstore.Postgres: `data BYTEA NOT NULL,\s+expires_at TIMESTAMP NOT NULL`,
+		sqlstore.MySQL:    `data LONGBLOB NOT NULL,\s+expires_at DATETIME\(6\) NOT NULL`,
+		sqlstore.SQLite:   `data BLOB NOT NULL,\s+expires_at TIMESTAMP NOT NULL`,
+	}
+	for d, columns := range types {
+		db, mock, err := sqlmock.New()
+		a.NoError(err)
+
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS gothic_sessions \(\s+id VARCHAR\(64\) NOT NULL PRIMARY KEY,\s+` + columns).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS gothic_tokens \(.*PRIMARY KEY \(provider, user_id\)`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS gothic_remember_me \(\s+selector VARCHAR\(64\) NOT NULL PRIMARY KEY,`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		a.NoError(sqlstore.New(db, d).Migrate(context.Background()), d.String())
+		a.NoError(mock.ExpectationsWereMet(), d.String())
+		db.Close()
+	}
+}
+
+func Test_SaveAndLoad(t *testing.T) {
+	a := assert.New(t)
+	s, mock := newStore(t, sqlstore.Postgres)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	res := httptest.NewRecorder()
+	sess, err := s.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(sess.IsNew)
+
+	sess.Values["faux"] = "\x1f\x8b\x08\x00\xff"
+	var data []byte
+	mock.ExpectExec("INSERT INTO gothic_sessions (id, data, expires_at) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at").
+		WithArgs(sqlmock.AnyArg(), capture{&data}, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	a.NoError(s.Save(req, res, sess))
+	a.NotEmpty(sess.ID)
+
+	cookies := res.Result().Cookies()
+	a.Len(cookies, 1)
+	a.Equal(sess.ID, cookies[0].Value)
+
+	mock.ExpectQuery("SELECT data FROM gothic_sessions WHERE id = $1 AND expires_at > $2").
+		WithArgs(sess.ID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(data))
+
+	req2 := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req2.AddCookie(cookies[0])
+	sess2, err := s.Get(req2, "_gothic_session")
+	a.NoError(err)
+	a.False(sess2.IsNew)
+	a.Equal("\x1f\x8b\x08\x00\xff", sess2.Values["faux"])
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func Test_LoadExpired(t *testing.T) {
+	a := assert.New(t)
+	s, mock := newStore(t, sqlstore.MySQL)
+
+	mock.ExpectQuery("SELECT data FROM gothic_sessions WHERE id = ? AND expires_at > ?").
+		WithArgs("expired", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req.AddCookie(&http.Cookie{Name: "_gothic_session", Value: "expired"})
+	sess, err := s.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(sess.IsNew)
+	a.Empty(sess.ID)
+	a.Empty(sess.Values)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func Test_Delete(t *testing.T) {
+	a := assert.New(t)
+	s, mock := newStore(t, sqlstore.SQLite)
+
+	req := httptest.NewRequest(http.MethodGet, "/logout", nil)
+	sess, _ := s.Get(req, "_gothic_session")
+	sess.ID = "id"
+	sess.Options.MaxAge = -1
+
+	mock.ExpectExec("DELETE FROM gothic_sessions WHERE id = ?").
+		WithArgs("id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	a.NoError(s.Save(req, httptest.NewRecorder(), sess))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func Test_DeleteExpired(t *testing.T) {
+	a := assert.New(t)
+	s, mock := newStore(t, sqlstore.Postgres)
+
+	mock.ExpectExec("DELETE FROM gothic_sessions WHERE expires_at <= $1").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	n, err := s.DeleteExpired(context.Background())
+	a.NoError(err)
+	a.Equal(int64(3), n)
+}
+
+func Test_Tokens(t *testing.T) {
+	a := assert.New(t)
+	s, mock := newStore(t, sqlstore.MySQL)
+	ctx := context.Background()
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("INSERT INTO gothic_tokens (provider, user_id, access_token, access_token_secret, refresh_token, id_token, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE access_token = VALUES(access_token), access_token_secret = VALUES(access_token_secret), refresh_token = VALUES(refresh_token), id_token = VALUES(id_token), expires_at = VALUES(expires_at)").
+		WithArgs("faux", "1234", "access", "", "refresh", "", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	a.NoError(s.SaveToken(ctx, store.Token{
+		Provider:     "faux",
+		UserID:       "1234",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    expiresAt,
+	}))
+
+	mock.ExpectQuery("SELECT access_token, access_token_secret, refresh_token, id_token, expires_at\nFROM gothic_tokens WHERE provider = ? AND user_id = ?").
+		WithArgs("faux", "1234").
+		WillReturnRows(sqlmock.NewRows([]string{"access_token", "access_token_secret", "refresh_token", "id_token", "expires_at"}).
+			AddRow("access", "", "refresh", "", expiresAt))
+	token, err := s.LoadToken(ctx, "faux", "1234")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+	a.Equal("refresh", token.RefreshToken)
+	a.Equal(expiresAt, token.ExpiresAt)
+
+	mock.ExpectQuery("SELECT access_token, access_token_secret, refresh_token, id_token, expires_at\nFROM gothic_tokens WHERE provider = ? AND user_id = ?").
+		WithArgs("faux", "5678").
+		WillReturnRows(sqlmock.NewRows([]string{"access_token", "access_token_secret", "refresh_token", "id_token", "expires_at"}))
+	_, err = s.LoadToken(ctx, "faux", "5678")
+	a.Equal(store.ErrTokenNotFound, err)
+
+	mock.ExpectExec("DELETE FROM gothic_tokens WHERE provider = ? AND user_id = ?").
+		WithArgs("faux", "1234").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	a.NoError(s.DeleteToken(ctx, "faux", "1234"))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func Test_RememberMeTokens(t *testing.T) {
+	a := assert.New(t)
+	s, mock := newStore(t, sqlstore.Postgres)
+	ctx := context.Background()
+	rotatedAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := rotatedAt.Add(30 * 24 * time.Hour)
+
+	mock.ExpectExec("INSERT INTO gothic_remember_me (selector, provider, user_id, validator_hash, previous_validator_hash, rotated_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (selector) DO UPDATE SET provider = excluded.provider, user_id = excluded.user_id, validator_hash = excluded.validator_hash, previous_validator_hash = excluded.previous_validator_hash, rotated_at = excluded.rotated_at, expires_at = excluded.expires_at").
+		WithArgs("sel", "faux", "1234", "new", "old", rotatedAt, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	a.NoError(s.SaveRememberMeToken(ctx, store.RememberMeToken{
+		Selector:              "sel",
+		Provider:              "faux",
+		UserID:                "1234",
+		ValidatorHash:         "new",
+		PreviousValidatorHash: "old",
+		RotatedAt:             rotatedAt,
+		ExpiresAt:             expiresAt,
+	}))
+
+	columns := []string{"provider", "user_id", "validator_hash", "previous_validator_hash", "rotated_at", "expires_at"}
+	mock.ExpectQuery("SELECT provider, user_id, validator_hash, previous_validator_hash, rotated_at, expires_at\nFROM gothic_remember_me WHERE selector = $1").
+		WithArgs("sel").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("faux", "1234", "new", "old", rotatedAt, expiresAt))
+	token, err := s.LoadRememberMeToken(ctx, "sel")
+	a.NoError(err)
+	a.Equal("faux", token.Provider)
+	a.Equal("1234", token.UserID)
+	a.Equal("new", token.ValidatorHash)
+	a.Equal("old", token.PreviousValidatorHash)
+	a.Equal(rotatedAt, token.RotatedAt)
+	a.Equal(expiresAt, token.ExpiresAt)
+
+	mock.ExpectQuery("SELECT provider, user_id, validator_hash, previous_validator_hash, rotated_at, expires_at\nFROM gothic_remember_me WHERE selector = $1").
+		WithArgs("other").
+		WillReturnRows(sqlmock.NewRows(columns))
+	_, err = s.LoadRememberMeToken(ctx, "other")
+	a.Equal(store.ErrRememberMeTokenNotFound, err)
+
+	mock.ExpectExec("DELETE FROM gothic_remember_me WHERE selector = $1").
+		WithArgs("sel").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	a.NoError(s.DeleteRememberMeToken(ctx, "sel"))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+// capture is a sqlmock.Argument that records the value it is matched with.
+type capture struct {
+	v *[]byte
+}
+
+func (c capture) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	*c.v = b
+	return ok
+}
@@ -0,0 +1,104 @@
+// Package store defines what gothic expects from a persistent store and holds
+// the helpers shared by the implementations in its sub packages.
+//
+// Every implementation is a gorilla sessions.Store, so it can be assigned to
+// gothic.Store to keep in-flight authentication sessions server side. Stores
+// that can also persist provider tokens implement TokenStore.
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// ErrTokenNotFound is returned by TokenStore.LoadToken when no token was saved
+// for the given provider and user.
+var ErrTokenNotFound = errors.New("store: token not found")
+
+// Token is a provider token persisted for a user. The tokens of a TokenStore
+// can be refreshed in batches with goth.RefreshUsers.
+type Token = goth.StoredToken
+
+// NewToken returns the token held by an authenticated user.
+func NewToken(user goth.User) Token {
+	return Token{
+		Provider:          user.Provider,
+		UserID:            user.UserID,
+		AccessToken:       user.AccessToken,
+		AccessTokenSecret: user.AccessTokenSecret,
+		RefreshToken:      user.RefreshToken,
+		IDToken:           user.IDToken,
+		ExpiresAt:         user.ExpiresAt,
+	}
+}
+
+// TokenStore persists provider tokens, keyed by provider name and user ID, so
+// they can be used after the authentication session is gone.
+type TokenStore interface {
+	SaveToken(ctx context.Context, token Token) error
+	LoadToken(ctx context.Context, provider, userID string) (Token, error)
+	DeleteToken(ctx context.Context, provider, userID string) error
+}
+
+// TokenStores save the tokens refreshed by the clients of goth.UserClient.
+var _ goth.TokenSaver = TokenStore(nil)
+
+// NewSessionID returns a random identifier suitable for a session cookie.
+func NewSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(b), "="), nil
+}
+
+// EncodeValues serializes session values as a JSON object. The keys must be
+// strings. String values are base64 encoded, as gothic stores gzipped, and
+// so binary, strings; other values are encoded as they are by encoding/json.
+func EncodeValues(values map[interface{}]interface{}) ([]byte, error) {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("store: session value key %v is not a string", k)
+		}
+		if str, ok := v.(string); ok {
+			v = []byte(str)
+		}
+		m[key] = v
+	}
+	return json.Marshal(m)
+}
+
+// DecodeValues deserializes session values produced by EncodeValues into
+// values. Values other than strings are decoded as by encoding/json, numbers
+// as float64.
+func DecodeValues(data []byte, values *map[interface{}]interface{}) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if *values == nil {
+		*values = make(map[interface{}]interface{}, len(m))
+	}
+	for k, v := range m {
+		if str, ok := v.(string); ok {
+			b, err := base64.StdEncoding.DecodeString(str)
+			if err != nil {
+				return err
+			}
+			v = string(b)
+		}
+		(*values)[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package store_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EncodeValues(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	data, err := store.EncodeValues(map[interface{}]interface{}{
+		"faux":  "\x1f\x8b\x08\x00\xff",
+		"count": 2,
+	})
+	a.NoError(err)
+	a.JSONEq(`{"faux":"H4sIAP8=","count":2}`, string(data))
+
+	values := map[interface{}]interface{}{}
+	a.NoError(store.DecodeValues(data, &values))
+	a.Equal(map[interface{}]interface{}{"faux": "\x1f\x8b\x08\x00\xff", "count": float64(2)}, values)
+
+	_, err = store.EncodeValues(map[interface{}]interface{}{1: "value"})
+	a.Error(err)
+}
+
+// mapBackend is a store.SessionBackend keeping sessions in a map.
+type mapBackend map[string][]byte
+
+func (b mapBackend) LoadSession(ctx context.Context, id string) ([]byte, error) {
+	data, ok := b[id]
+	if !ok {
+		return nil, store.ErrSessionNotFound
+	}
+	return data, nil
+}
+
+func (b mapBackend) SaveSession(ctx context.Context, id string, data []byte, maxAge time.Duration) error {
+	b[id] = data
+	return nil
+}
+
+func (b mapBackend) DeleteSession(ctx context.Context, id string) error {
+	delete(b, id)
+	return nil
+}
+
+func Test_NewSessionAndSaveSession(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	b := mapBackend{}
+	opts := &sessions.Options{Path: "/", MaxAge: 300}
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/faux", nil)
+	req.AddCookie(&http.Cookie{Name: "_gothic_session", Value: "chosen-by-attacker"})
+	res := httptest.NewRecorder()
+	sess, err := store.NewSession(req, nil, b, "_gothic_session", opts)
+	a.NoError(err)
+	a.True(sess.IsNew)
+	a.Empty(sess.ID, "unknown session IDs are not reused")
+
+	sess.Values["faux"] = "value"
+	a.NoError(store.SaveSession(req, res, b, sess))
+	a.NotEqual("chosen-by-attacker", sess.ID)
+	a.Len(b, 1)
+
+	req2 := httptest.NewRequest(http.MethodGet, "/auth/faux/callback", nil)
+	req2.AddCookie(res.Result().Cookies()[0])
+	sess2, err := store.NewSession(req2, nil, b, "_gothic_session", opts)
+	a.NoError(err)
+	a.False(sess2.IsNew)
+	a.Equal(sess.ID, sess2.ID)
+	a.Equal("value", sess2.Values["faux"])
+
+	sess2.Options.MaxAge = -1
+	a.NoError(store.SaveSession(req2, httptest.NewRecorder(), b, sess2))
+	a.Empty(b)
+}
@@ -0,0 +1,197 @@
+// Package idtoken validates the OpenID Connect ID tokens providers return
+// along with their access tokens, checking their signature with the keys the
+// provider publishes, and their issuer, audience, nonce and expiry.
+// See https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation
+package idtoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// KeysTTL is how long the key sets returned by Keys are kept before being
+// fetched again.
+var KeysTTL = 12 * time.Hour
+
+var (
+	keysMu sync.Mutex
+	keys   = map[string]*goth.KeySet{}
+)
+
+// Keys returns the key set published at url, fetched once and shared by every
+// Verifier using it.
+func Keys(url string) *goth.KeySet {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	k, ok := keys[url]
+	if !ok {
+		k = goth.NewKeySet(url, KeysTTL)
+		keys[url] = k
+	}
+	return k
+}
+
+// Claims are the claims of a validated ID token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce,omitempty"`
+	AccessTokenHash string `json:"at_hash,omitempty"`
+	AuthorizedParty string `json:"azp,omitempty"`
+	// Raw holds every claim of the token, including those of the provider.
+	Raw map[string]interface{} `json:"-"`
+
+	alg string
+}
+
+// Verifier validates the ID tokens issued by a provider to a client. Either
+// Issuers or ValidIssuer must be set.
+type Verifier struct {
+	// KeysURL is where the provider publishes the keys it signs the tokens
+	// with, as a JSON Web Key Set.
+	KeysURL string
+	// ClientID is the audience the tokens must be issued to.
+	ClientID string
+	// Issuers lists the issuers accepted.
+	Issuers []string
+	// ValidIssuer reports whether an issuer is accepted, for multi-tenant
+	// providers whose issuer names the tenant of the user.
+	ValidIssuer func(issuer string) bool
+	// Algorithms are the signing algorithms accepted, RS256 when empty.
+	Algorithms []string
+	// Secret is the key of the tokens signed with HMAC, such as HS256, which
+	// some providers sign with the client secret.
+	Secret string
+}
+
+// Verify parses and validates rawIDToken, fetching the keys of the provider
+// with client when needed. When nonce is not empty, the token must carry it.
+func (v *Verifier) Verify(client *http.Client, rawIDToken, nonce string) (*Claims, error) {
+	if rawIDToken == "" {
+		return nil, errors.New("id token is missing")
+	}
+	methods := v.Algorithms
+	if len(methods) == 0 {
+		methods = []string{"RS256"}
+	}
+
+	claims := &Claims{}
+	keyfunc := func(t *jwt.Token) (interface{}, error) {
+		claims.alg = t.Method.Alg()
+		return v.keyfunc(client)(t)
+	}
+	raw, err := Validate(rawIDToken, claims, keyfunc, methods, "", v.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+	if !v.validIssuer(claims.Issuer) {
+		return nil, fmt.Errorf("id token: issuer is incorrect: %q", claims.Issuer)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != "" && claims.AuthorizedParty != v.ClientID {
+		return nil, errors.New("id token: authorized party is incorrect")
+	}
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, errors.New("id token: nonce is incorrect")
+	}
+	claims.Raw = raw
+	return claims, nil
+}
+
+// Validate parses the JWT token into claims, checking that it is signed with
+// one of methods with the key returned by keyfunc, and validates its
+// registered claims with goth.ValidateClaims against issuer and audience. It
+// returns every claim of the token, for the RawData of users.
+func Validate(token string, claims jwt.Claims, keyfunc jwt.Keyfunc, methods []string, issuer, audience string) (jwt.MapClaims, error) {
+	// The time based claims are checked by goth.ValidateClaims, against
+	// goth.Clock.
+	parser := &jwt.Parser{ValidMethods: methods, SkipClaimsValidation: true}
+	if _, err := parser.ParseWithClaims(token, claims, keyfunc); err != nil {
+		return nil, err
+	}
+
+	payload, err := jwt.DecodeSegment(strings.Split(token, ".")[1])
+	if err != nil {
+		return nil, err
+	}
+	raw := jwt.MapClaims{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, err
+	}
+	registered := &jwt.RegisteredClaims{}
+	if err := json.Unmarshal(payload, registered); err != nil {
+		return nil, err
+	}
+	if err := goth.ValidateClaims(registered, issuer, audience); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (v *Verifier) keyfunc(client *http.Client) jwt.Keyfunc {
+	return func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+			if v.Secret == "" {
+				return nil, fmt.Errorf("no secret to validate %s signatures", t.Method.Alg())
+			}
+			return []byte(v.Secret), nil
+		}
+		if v.KeysURL == "" {
+			return nil, errors.New("the provider publishes no keys")
+		}
+		return Keys(v.KeysURL).Keyfunc(client)(t)
+	}
+}
+
+func (v *Verifier) validIssuer(issuer string) bool {
+	for _, iss := range v.Issuers {
+		if iss == issuer {
+			return true
+		}
+	}
+	return v.ValidIssuer != nil && v.ValidIssuer(issuer)
+}
+
+// VerifyAccessToken checks that the token was issued along with accessToken,
+// with its at_hash claim. It fails when the token has no at_hash.
+// See https://openid.net/specs/openid-connect-core-1_0.html#CodeIDToken
+func (c *Claims) VerifyAccessToken(accessToken string) error {
+	var h hash.Hash
+	switch {
+	case strings.HasSuffix(c.alg, "256"):
+		h = sha256.New()
+	case strings.HasSuffix(c.alg, "384"):
+		h = sha512.New384()
+	case strings.HasSuffix(c.alg, "512"):
+		h = sha512.New()
+	default:
+		return fmt.Errorf("id token: unsupported algorithm %q", c.alg)
+	}
+	h.Write([]byte(accessToken))
+	sum := h.Sum(nil)
+	if c.AccessTokenHash == "" || base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]) != c.AccessTokenHash {
+		return errors.New("id token: access token hash is incorrect")
+	}
+	return nil
+}
+
+// NewNonce returns a random nonce, to be sent with the authorization request
+// and checked by Verify.
+func NewNonce() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,178 @@
+package idtoken_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+type claims struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce,omitempty"`
+	AccessTokenHash string `json:"at_hash,omitempty"`
+	Email           string `json:"email,omitempty"`
+}
+
+func keysServer(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k, err := jwk.New(&key.PublicKey)
+		assert.NoError(t, err)
+		assert.NoError(t, k.Set(jwk.KeyIDKey, "k1"))
+		set := jwk.NewSet()
+		set.Add(k)
+		assert.NoError(t, json.NewEncoder(w).Encode(set))
+	}))
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, c claims) string {
+	token := jwt.NewWithClaims(method, c)
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func Test_Verify(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	ts := keysServer(t, key)
+	defer ts.Close()
+
+	v := &idtoken.Verifier{KeysURL: ts.URL, ClientID: "client", Issuers: []string{"https://issuer.example.com"}}
+	valid := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://issuer.example.com",
+			Subject:   "user",
+			Audience:  jwt.ClaimStrings{"client"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Nonce: "nonce",
+		Email: "homer@example.com",
+	}
+
+	c, err := v.Verify(nil, sign(t, jwt.SigningMethodRS256, key, valid), "nonce")
+	a.NoError(err)
+	a.Equal("user", c.Subject)
+	a.Equal("homer@example.com", c.Raw["email"])
+
+	invalid := valid
+	invalid.Issuer = "https://evil.example.com"
+	_, err = v.Verify(nil, sign(t, jwt.SigningMethodRS256, key, invalid), "nonce")
+	a.EqualError(err, `id token: issuer is incorrect: "https://evil.example.com"`)
+
+	invalid = valid
+	invalid.Audience = jwt.ClaimStrings{"other"}
+	_, err = v.Verify(nil, sign(t, jwt.SigningMethodRS256, key, invalid), "nonce")
+	a.EqualError(err, "id token: audience is incorrect")
+
+	invalid = valid
+	invalid.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour))
+	_, err = v.Verify(nil, sign(t, jwt.SigningMethodRS256, key, invalid), "nonce")
+	a.EqualError(err, "id token: token is expired")
+
+	_, err = v.Verify(nil, sign(t, jwt.SigningMethodRS256, key, valid), "other")
+	a.EqualError(err, "id token: nonce is incorrect")
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	_, err = v.Verify(nil, sign(t, jwt.SigningMethodRS256, other, valid), "nonce")
+	a.Error(err)
+
+	// Tokens signed with the client secret are only accepted when allowed.
+	_, err = v.Verify(nil, sign(t, jwt.SigningMethodHS256, []byte("secret"), valid), "nonce")
+	a.Error(err)
+	v.Algorithms, v.Secret = []string{"RS256", "HS256"}, "secret"
+	_, err = v.Verify(nil, sign(t, jwt.SigningMethodHS256, []byte("secret"), valid), "nonce")
+	a.NoError(err)
+}
+
+func Test_VerifyAccessToken(t *testing.T) {
+	a := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	ts := keysServer(t, key)
+	defer ts.Close()
+
+	hash := sha256.Sum256([]byte("access"))
+	c := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://issuer.example.com",
+			Audience:  jwt.ClaimStrings{"client"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		AccessTokenHash: base64.RawURLEncoding.EncodeToString(hash[:16]),
+	}
+	v := &idtoken.Verifier{KeysURL: ts.URL, ClientID: "client", Issuers: []string{"https://issuer.example.com"}}
+	verified, err := v.Verify(nil, sign(t, jwt.SigningMethodRS256, key, c), "")
+	a.NoError(err)
+	a.NoError(verified.VerifyAccessToken("access"))
+	a.EqualError(verified.VerifyAccessToken("other"), "id token: access token hash is incorrect")
+}
+
+func Test_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	secret := []byte("secret")
+	keyfunc := func(*jwt.Token) (interface{}, error) { return secret, nil }
+	valid := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://issuer.example.com",
+			Subject:   "user",
+			Audience:  jwt.ClaimStrings{"client"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Email: "homer@example.com",
+	}
+
+	c := &claims{}
+	raw, err := idtoken.Validate(sign(t, jwt.SigningMethodHS256, secret, valid), c, keyfunc, []string{"HS256"}, "https://issuer.example.com", "client")
+	a.NoError(err)
+	a.Equal("user", c.Subject)
+	a.Equal("homer@example.com", raw["email"])
+
+	_, err = idtoken.Validate(sign(t, jwt.SigningMethodHS256, secret, valid), &claims{}, keyfunc, []string{"RS256"}, "https://issuer.example.com", "client")
+	a.Error(err)
+
+	_, err = idtoken.Validate(sign(t, jwt.SigningMethodHS256, secret, valid), &claims{}, keyfunc, []string{"HS256"}, "https://other.example.com", "client")
+	a.EqualError(err, `issuer is incorrect: "https://issuer.example.com"`)
+
+	// Expiry is checked against goth.Clock, allowing for goth.ClockSkew.
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(now.Add(-goth.ClockSkew / 2))
+	_, err = idtoken.Validate(sign(t, jwt.SigningMethodHS256, secret, expired), &claims{}, keyfunc, []string{"HS256"}, "https://issuer.example.com", "client")
+	a.NoError(err)
+	expired.ExpiresAt = jwt.NewNumericDate(now.Add(-goth.ClockSkew - time.Minute))
+	_, err = idtoken.Validate(sign(t, jwt.SigningMethodHS256, secret, expired), &claims{}, keyfunc, []string{"HS256"}, "https://issuer.example.com", "client")
+	a.EqualError(err, "token is expired")
+}
+
+func Test_Keys(t *testing.T) {
+	a := assert.New(t)
+
+	a.Same(idtoken.Keys("https://example.com/keys"), idtoken.Keys("https://example.com/keys"))
+	a.NotSame(idtoken.Keys("https://example.com/keys"), idtoken.Keys("https://example.com/other"))
+}
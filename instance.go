@@ -0,0 +1,255 @@
+package goth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// RawDataInstance is the key of RawData holding the URL of the instance a
+// user logged in with, for providers resolved by Instances.
+const RawDataInstance = "instance"
+
+// DefaultMaxInstances is the number of providers Instances keep when their
+// MaxInstances is not set.
+const DefaultMaxInstances = 1000
+
+// InstanceCredentials returns the client credentials registered on the
+// instance at instanceURL, for services without dynamic client registration.
+type InstanceCredentials func(instanceURL string) (clientKey, secret string, err error)
+
+// Instances resolves the providers of the instances of a federated or
+// self-hosted service, such as Mastodon servers or GitLab installations,
+// whose users type in the URL of their server. The provider of an instance is
+// created by resolve the first time it is used, registering a client when the
+// service supports it, and kept for the next logins. Register Instances with
+// UseInstances, gothic then asks for the instance when the login begins.
+// It is safe for concurrent use.
+//
+// No instance is allowed until Allow is set, as resolving an instance makes
+// the server call the host a user typed in.
+type Instances struct {
+	// Allow decides the instances users can log in with. It is given
+	// normalized URLs, see NormalizeInstanceURL and AllowInstanceURLs, and
+	// allows none when nil.
+	Allow func(instanceURL string) bool
+
+	// AllowPrivateAddresses lets users log in with instances on loopback,
+	// private or link-local addresses, such as the servers of a company
+	// network. Applications open to the Internet must not set it, their users
+	// could have them call their own network.
+	AllowPrivateAddresses bool
+
+	// MaxInstances is the number of providers kept, DefaultMaxInstances when
+	// it is not set. The oldest one is dropped to keep a new one.
+	MaxInstances int
+
+	name      string
+	resolve   func(instanceURL string) (Provider, error)
+	mu        sync.Mutex
+	providers map[string]Provider
+	order     []string
+}
+
+// AllowInstanceURLs returns an Instances.Allow allowing the instances at
+// urls only, normalized with NormalizeInstanceURL.
+func AllowInstanceURLs(urls ...string) func(instanceURL string) bool {
+	allowed := map[string]bool{}
+	for _, u := range urls {
+		if u, err := NormalizeInstanceURL(u); err == nil {
+			allowed[u] = true
+		}
+	}
+	return func(instanceURL string) bool {
+		return allowed[instanceURL]
+	}
+}
+
+// NewInstances returns the Instances of the service name, whose providers are
+// created by resolve for the normalized URL of an instance.
+func NewInstances(name string, resolve func(instanceURL string) (Provider, error)) *Instances {
+	return &Instances{
+		name:      name,
+		resolve:   resolve,
+		providers: map[string]Provider{},
+	}
+}
+
+// Name is the name the providers of the instances are registered under.
+func (i *Instances) Name() string {
+	return i.name
+}
+
+// Provider returns the provider of the instance at instanceURL, resolving it
+// the first time. The provider is named after the Instances. Failed
+// resolutions are not kept, so they are retried on the next login. Instances
+// Allow refuses, and those on private addresses unless AllowPrivateAddresses
+// is set, are never resolved.
+func (i *Instances) Provider(instanceURL string) (Provider, error) {
+	instanceURL, err := NormalizeInstanceURL(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+	if i.Allow == nil || !i.Allow(instanceURL) {
+		return nil, fmt.Errorf("%s: instance %s is not allowed", i.name, instanceURL)
+	}
+
+	i.mu.Lock()
+	provider, ok := i.providers[instanceURL]
+	i.mu.Unlock()
+	if ok {
+		return provider, nil
+	}
+
+	if !i.AllowPrivateAddresses {
+		if err := checkPublicHost(instanceURL); err != nil {
+			return nil, fmt.Errorf("%s: %w", i.name, err)
+		}
+	}
+
+	// The lock is not held while resolving, which may register a client over
+	// the network; concurrent first logins keep the first provider resolved.
+	provider, err = i.resolve(instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", i.name, err)
+	}
+	provider.SetName(i.name)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if cached, ok := i.providers[instanceURL]; ok {
+		return cached, nil
+	}
+	if len(i.order) >= i.maxInstances() {
+		delete(i.providers, i.order[0])
+		i.order = i.order[1:]
+	}
+	i.providers[instanceURL] = provider
+	i.order = append(i.order, instanceURL)
+	return provider, nil
+}
+
+func (i *Instances) maxInstances() int {
+	if i.MaxInstances > 0 {
+		return i.MaxInstances
+	}
+	return DefaultMaxInstances
+}
+
+// checkPublicHost fails when the host of instanceURL has a loopback, private
+// or link-local address.
+func checkPublicHost(instanceURL string) error {
+	u, err := url.Parse(instanceURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(context.Background(), u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("instance %s has the private address %s", instanceURL, addr.IP)
+		}
+	}
+	return nil
+}
+
+// privateNetworks are the networks, besides the loopback and link-local ones,
+// not reachable from the Internet.
+var privateNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("fc00::/7"),
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// InstanceUserID returns the ID of the user userID of the instance at
+// instanceURL, such as 109302@mastodon.social. IDs are only unique on their
+// instance, gothic gives the users of Instances these instead.
+func InstanceUserID(instanceURL, userID string) string {
+	return userID + "@" + strings.TrimPrefix(instanceURL, "https://")
+}
+
+// NormalizeInstanceURL returns the URL of the instance a user typed in, such
+// as mastodon.social or https://GitLab.example.com/, as scheme and host with
+// an optional path: https://mastodon.social. https is assumed when there is
+// no scheme, and is the only one accepted.
+func NormalizeInstanceURL(instanceURL string) (string, error) {
+	instanceURL = strings.TrimSpace(instanceURL)
+	if instanceURL == "" {
+		return "", errors.New("instance URL is empty")
+	}
+	if !strings.Contains(instanceURL, "://") {
+		instanceURL = "https://" + instanceURL
+	}
+
+	u, err := url.Parse(instanceURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("instance URL %q is not https", instanceURL)
+	}
+	if u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("instance URL %q is invalid", instanceURL)
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host) + strings.TrimSuffix(u.EscapedPath(), "/"), nil
+}
+
+var (
+	instancesMu sync.RWMutex
+	instances   = map[string]*Instances{}
+)
+
+// UseInstances adds Instances for use with goth, under their name. The name
+// must not be the one of a provider added with UseProviders.
+func UseInstances(list ...*Instances) {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	for _, i := range list {
+		instances[i.Name()] = i
+	}
+}
+
+// GetInstances returns the Instances added under name.
+func GetInstances(name string) (*Instances, bool) {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+	i, ok := instances[name]
+	return i, ok
+}
+
+// ClearInstances removes all the Instances in use.
+// This is useful, mostly, for testing purposes.
+func ClearInstances() {
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+	instances = map[string]*Instances{}
+}
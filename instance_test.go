@@ -0,0 +1,128 @@
+package goth_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NormalizeInstanceURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	for in, want := range map[string]string{
+		"mastodon.social":                "https://mastodon.social",
+		" https://Mastodon.Social/ ":     "https://mastodon.social",
+		"https://localhost:8443":         "https://localhost:8443",
+		"https://example.com/nextcloud/": "https://example.com/nextcloud",
+	} {
+		got, err := goth.NormalizeInstanceURL(in)
+		a.NoError(err, in)
+		a.Equal(want, got, in)
+	}
+
+	for _, in := range []string{"", "ftp://example.com", "http://example.com", "https://user@example.com", "https://example.com/?next=/", "https://"} {
+		_, err := goth.NormalizeInstanceURL(in)
+		a.Error(err, in)
+	}
+}
+
+func Test_Instances(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	resolved := []string{}
+	instances := goth.NewInstances("faux", func(instanceURL string) (goth.Provider, error) {
+		resolved = append(resolved, instanceURL)
+		if instanceURL == "https://down.example.com" {
+			return nil, errors.New("instance is down")
+		}
+		return &faux.Provider{}, nil
+	})
+	_, err := instances.Provider("example.com")
+	a.EqualError(err, "faux: instance https://example.com is not allowed", "no instance is allowed by default")
+
+	instances.Allow = func(instanceURL string) bool {
+		return instanceURL != "https://blocked.example.com"
+	}
+	instances.AllowPrivateAddresses = true
+
+	p1, err := instances.Provider("Example.com")
+	a.NoError(err)
+	p2, err := instances.Provider("https://example.com/")
+	a.NoError(err)
+	a.Same(p1, p2)
+
+	_, err = instances.Provider("blocked.example.com")
+	a.EqualError(err, "faux: instance https://blocked.example.com is not allowed")
+
+	// Failed resolutions are retried.
+	_, err = instances.Provider("down.example.com")
+	a.EqualError(err, "faux: instance is down")
+	_, err = instances.Provider("down.example.com")
+	a.Error(err)
+	a.Equal([]string{"https://example.com", "https://down.example.com", "https://down.example.com"}, resolved)
+}
+
+func Test_InstancesPrivateAddresses(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	instances := goth.NewInstances("faux", func(instanceURL string) (goth.Provider, error) {
+		return &faux.Provider{}, nil
+	})
+	instances.Allow = func(string) bool { return true }
+
+	for _, instanceURL := range []string{
+		"https://127.0.0.1", "https://localhost", "https://[::1]:8443", "https://10.0.0.1",
+		"https://172.20.1.1", "https://192.168.1.1", "https://169.254.169.254", "https://[fd00::1]",
+		"https://0.0.0.0",
+	} {
+		_, err := instances.Provider(instanceURL)
+		a.Error(err, instanceURL)
+	}
+	_, err := instances.Provider("https://93.184.216.34")
+	a.NoError(err)
+}
+
+func Test_InstancesCache(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	resolutions := 0
+	instances := goth.NewInstances("faux", func(instanceURL string) (goth.Provider, error) {
+		resolutions++
+		return &faux.Provider{}, nil
+	})
+	instances.Allow = goth.AllowInstanceURLs("a.example.com", "https://b.example.com/", "c.example.com")
+	instances.AllowPrivateAddresses = true
+	instances.MaxInstances = 2
+
+	for _, instanceURL := range []string{"a.example.com", "b.example.com", "c.example.com", "a.example.com", "c.example.com"} {
+		_, err := instances.Provider(instanceURL)
+		a.NoError(err)
+	}
+	a.Equal(4, resolutions, "the oldest instance is dropped beyond MaxInstances")
+	_, err := instances.Provider("d.example.com")
+	a.EqualError(err, "faux: instance https://d.example.com is not allowed")
+
+	a.Equal("109302@mastodon.social", goth.InstanceUserID("https://mastodon.social", "109302"))
+	a.Equal("42@example.com/nextcloud", goth.InstanceUserID("https://example.com/nextcloud", "42"))
+}
+
+func Test_UseInstances(t *testing.T) {
+	a := assert.New(t)
+
+	instances := goth.NewInstances("mastodon", nil)
+	goth.UseInstances(instances)
+	defer goth.ClearInstances()
+
+	got, ok := goth.GetInstances("mastodon")
+	a.True(ok)
+	a.Same(instances, got)
+	_, ok = goth.GetInstances("gitlab")
+	a.False(ok)
+}
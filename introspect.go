@@ -0,0 +1,164 @@
+package goth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenIntrospector is implemented by providers with an introspection
+// end-point, which tells resource servers whether a bearer token they were
+// handed is active, and what it grants. Check for it with IntrospectToken.
+type TokenIntrospector interface {
+	Introspect(token string) (*Introspection, error)
+}
+
+// IntrospectionNotSupportedError is returned by IntrospectToken when the
+// provider has no introspection end-point.
+type IntrospectionNotSupportedError struct {
+	Provider string
+}
+
+func (e *IntrospectionNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: token introspection is not supported", e.Provider)
+}
+
+// Introspection is the answer of an introspection end-point about a token.
+// Only Active is always set; inactive tokens come without the other fields.
+type Introspection struct {
+	Active    bool
+	Scopes    []string
+	Subject   string
+	ClientID  string
+	Username  string
+	TokenType string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	// RawData holds every member of the response, including those of the
+	// provider.
+	RawData map[string]interface{}
+}
+
+// Valid reports whether the token is active and, if the response tells when
+// it expires, not expired yet, allowing for ClockSkew.
+func (i *Introspection) Valid() bool {
+	return i.Active && !Expired(i.ExpiresAt)
+}
+
+// HasScope reports whether the token grants scope.
+func (i *Introspection) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IntrospectToken asks provider about token, an access or refresh token
+// presented to a resource server. It returns a *IntrospectionNotSupportedError
+// when the provider cannot introspect tokens. An error means the provider could
+// not be asked; check the Valid method of the result before trusting the token.
+func IntrospectToken(provider Provider, token string) (*Introspection, error) {
+	introspector, ok := provider.(TokenIntrospector)
+	if !ok {
+		return nil, &IntrospectionNotSupportedError{Provider: provider.Name()}
+	}
+	return introspector.Introspect(token)
+}
+
+// IntrospectionRequest describes a single RFC 7662 token introspection
+// request. ClientID and ClientSecret are sent as AuthStyle says, with HTTP
+// Basic authentication by default; most servers require them.
+type IntrospectionRequest struct {
+	IntrospectionURL string
+	ClientID         string
+	ClientSecret     string
+	AuthStyle        oauth2.AuthStyle
+	Token            string
+	TokenTypeHint    string
+}
+
+// Introspect performs an RFC 7662 token introspection against the
+// introspection end-point described by r.
+// See https://datatracker.ietf.org/doc/html/rfc7662#section-2
+func Introspect(client *http.Client, r IntrospectionRequest) (*Introspection, error) {
+	v := url.Values{"token": {r.Token}}
+	if r.TokenTypeHint != "" {
+		v.Set("token_type_hint", r.TokenTypeHint)
+	}
+	if r.ClientID != "" && r.AuthStyle == oauth2.AuthStyleInParams {
+		v.Set("client_id", r.ClientID)
+		if r.ClientSecret != "" {
+			v.Set("client_secret", r.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequest("POST", r.IntrospectionURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if r.ClientID != "" && r.AuthStyle != oauth2.AuthStyleInParams {
+		req.SetBasicAuth(url.QueryEscape(r.ClientID), url.QueryEscape(r.ClientSecret))
+	}
+
+	resp, err := HTTPClientWithFallBack(client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ProviderAPIError{Provider: "token introspection", Status: resp.StatusCode, Body: string(body)}
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("token introspection: %w", err)
+	}
+	return newIntrospection(raw), nil
+}
+
+func newIntrospection(raw map[string]interface{}) *Introspection {
+	i := &Introspection{RawData: raw}
+	i.Active, _ = raw["active"].(bool)
+	if scope, ok := raw["scope"].(string); ok {
+		i.Scopes = strings.Fields(scope)
+	}
+	i.Subject, _ = raw["sub"].(string)
+	i.ClientID, _ = raw["client_id"].(string)
+	i.Username, _ = raw["username"].(string)
+	i.TokenType, _ = raw["token_type"].(string)
+	i.Issuer, _ = raw["iss"].(string)
+	switch aud := raw["aud"].(type) {
+	case string:
+		i.Audience = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				i.Audience = append(i.Audience, s)
+			}
+		}
+	}
+	if exp, ok := raw["exp"].(float64); ok {
+		i.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	if iat, ok := raw["iat"].(float64); ok {
+		i.IssuedAt = time.Unix(int64(iat), 0)
+	}
+	return i
+}
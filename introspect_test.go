@@ -0,0 +1,109 @@
+package goth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type introspectingProvider struct {
+	faux.Provider
+}
+
+func (p *introspectingProvider) Introspect(token string) (*goth.Introspection, error) {
+	return &goth.Introspection{Active: token == "active"}, nil
+}
+
+func Test_IntrospectToken(t *testing.T) {
+	a := assert.New(t)
+
+	i, err := goth.IntrospectToken(&introspectingProvider{}, "active")
+	a.NoError(err)
+	a.True(i.Active)
+
+	var notSupported *goth.IntrospectionNotSupportedError
+	_, err = goth.IntrospectToken(&faux.Provider{}, "token")
+	a.True(errors.As(err, &notSupported))
+	a.Equal("faux", notSupported.Provider)
+}
+
+func Test_Introspect(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("token", r.PostForm.Get("token"))
+		a.Equal("access_token", r.PostForm.Get("token_type_hint"))
+		if id, secret, ok := r.BasicAuth(); ok {
+			a.Equal("client", id)
+			a.Equal("secret", secret)
+			a.Empty(r.PostForm.Get("client_id"))
+		} else {
+			a.Equal("client", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"active":true,"scope":"read write","sub":"user","client_id":"client","username":"jdoe","token_type":"Bearer","iss":"https://example.com","aud":"api","exp":2000000000,"iat":1000000000,"tenant":"acme"}`))
+	}))
+	defer ts.Close()
+
+	r := goth.IntrospectionRequest{
+		IntrospectionURL: ts.URL,
+		ClientID:         "client",
+		ClientSecret:     "secret",
+		Token:            "token",
+		TokenTypeHint:    goth.TokenTypeHintAccessToken,
+	}
+	i, err := goth.Introspect(nil, r)
+	a.NoError(err)
+	a.True(i.Active)
+	a.Equal([]string{"read", "write"}, i.Scopes)
+	a.True(i.HasScope("write"))
+	a.False(i.HasScope("admin"))
+	a.Equal("user", i.Subject)
+	a.Equal("client", i.ClientID)
+	a.Equal("jdoe", i.Username)
+	a.Equal("Bearer", i.TokenType)
+	a.Equal("https://example.com", i.Issuer)
+	a.Equal([]string{"api"}, i.Audience)
+	a.Equal(time.Unix(2000000000, 0), i.ExpiresAt)
+	a.Equal(time.Unix(1000000000, 0), i.IssuedAt)
+	a.Equal("acme", i.RawData["tenant"])
+
+	r.AuthStyle = oauth2.AuthStyleInParams
+	_, err = goth.Introspect(nil, r)
+	a.NoError(err)
+}
+
+func Test_IntrospectionValid(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	a.False((&goth.Introspection{}).Valid())
+	a.True((&goth.Introspection{Active: true}).Valid())
+	a.True((&goth.Introspection{Active: true, ExpiresAt: now.Add(time.Minute)}).Valid())
+	a.False((&goth.Introspection{Active: true, ExpiresAt: now.Add(-time.Minute)}).Valid())
+}
+
+func Test_IntrospectRejected(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer ts.Close()
+
+	_, err := goth.Introspect(nil, goth.IntrospectionRequest{IntrospectionURL: ts.URL, Token: "token"})
+	a.EqualError(err, `token introspection responded with a 401: {"error":"invalid_client"}`)
+}
@@ -0,0 +1,163 @@
+package goth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+// KeySetMinRefresh is the minimum time between two fetches of a KeySet,
+// so that tokens signed with unknown keys cannot make it hammer the
+// provider.
+var KeySetMinRefresh = time.Minute
+
+// KeySet is a JSON Web Key Set, fetched from URL and kept for TTL. It is
+// refreshed early when a token names a key it does not hold, as providers
+// rotate their keys. Fetches, successful or not, are at least
+// KeySetMinRefresh apart, and concurrent callers share the same fetch. It is
+// safe for concurrent use.
+type KeySet struct {
+	URL string
+	TTL time.Duration
+
+	mu      sync.Mutex
+	set     jwk.Set
+	fetched time.Time
+	// attempted is when the last fetch completed, and err its error.
+	attempted time.Time
+	err       error
+	fetch     *keySetFetch
+}
+
+// keySetFetch is a fetch in progress, done once the set or err is known.
+type keySetFetch struct {
+	done chan struct{}
+	set  jwk.Set
+	err  error
+}
+
+// NewKeySet returns the key set published at url, kept for ttl.
+func NewKeySet(url string, ttl time.Duration) *KeySet {
+	return &KeySet{URL: url, TTL: ttl}
+}
+
+// Key returns the raw public key (such as *rsa.PublicKey or
+// *ecdsa.PublicKey) with the id kid, fetching the set with client when
+// needed.
+func (k *KeySet) Key(client *http.Client, kid string) (interface{}, error) {
+	return k.KeyContext(context.Background(), client, kid)
+}
+
+// KeyContext is Key, fetching the set within ctx.
+func (k *KeySet) KeyContext(ctx context.Context, client *http.Client, kid string) (interface{}, error) {
+	set, err := k.keys(ctx, client, kid)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := set.LookupKeyID(kid)
+	if !ok {
+		return nil, fmt.Errorf("could not find public key %q in %s", kid, k.URL)
+	}
+	return rawKey(key)
+}
+
+// keys returns the set, fetched again when it has expired or does not hold
+// kid, unless the last fetch completed within KeySetMinRefresh.
+func (k *KeySet) keys(ctx context.Context, client *http.Client, kid string) (jwk.Set, error) {
+	k.mu.Lock()
+	now := Now()
+	recent := now.Before(k.attempted.Add(KeySetMinRefresh))
+	if k.set != nil && now.Before(k.fetched.Add(k.TTL)) {
+		if _, ok := k.set.LookupKeyID(kid); ok || recent {
+			set := k.set
+			k.mu.Unlock()
+			return set, nil
+		}
+	} else if k.err != nil && recent {
+		err := k.err
+		k.mu.Unlock()
+		return nil, err
+	}
+
+	if f := k.fetch; f != nil {
+		k.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.set, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	f := &keySetFetch{done: make(chan struct{})}
+	k.fetch = f
+	k.mu.Unlock()
+
+	f.set, f.err = jwk.Fetch(ctx, k.URL, jwk.WithHTTPClient(HTTPClientWithFallBack(client)))
+
+	k.mu.Lock()
+	k.fetch = nil
+	// A fetch given up by its caller says nothing of the provider.
+	if f.err == nil || ctx.Err() == nil {
+		k.attempted, k.err = Now(), f.err
+		if f.err == nil {
+			k.set, k.fetched = f.set, k.attempted
+		}
+	}
+	k.mu.Unlock()
+	close(f.done)
+	return f.set, f.err
+}
+
+// Keyfunc returns a jwt.Keyfunc returning the key named by the kid header of
+// the token.
+func (k *KeySet) Keyfunc(client *http.Client) jwt.Keyfunc {
+	return k.KeyfuncContext(context.Background(), client)
+}
+
+// KeyfuncContext is Keyfunc, fetching the set within ctx.
+func (k *KeySet) KeyfuncContext(ctx context.Context, client *http.Client) jwt.Keyfunc {
+	return func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid header")
+		}
+		return k.KeyContext(ctx, client, kid)
+	}
+}
+
+func rawKey(key jwk.Key) (interface{}, error) {
+	var raw interface{}
+	if err := key.Raw(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// ValidateClaims checks the time based claims of a JWT against Clock,
+// allowing for ClockSkew, and that it was issued by issuer for audience.
+// The expiry is required, an empty issuer or audience is not checked.
+func ValidateClaims(claims *jwt.RegisteredClaims, issuer, audience string) error {
+	now := Now()
+	if !claims.VerifyExpiresAt(now.Add(-ClockSkew), true) {
+		return ErrTokenExpired
+	}
+	if !claims.VerifyIssuedAt(now.Add(ClockSkew), false) {
+		return errors.New("token used before issued")
+	}
+	if !claims.VerifyNotBefore(now.Add(ClockSkew), false) {
+		return errors.New("token is not valid yet")
+	}
+	if issuer != "" && !claims.VerifyIssuer(issuer, true) {
+		return fmt.Errorf("issuer is incorrect: %q", claims.Issuer)
+	}
+	if audience != "" && !claims.VerifyAudience(audience, true) {
+		return errors.New("audience is incorrect")
+	}
+	return nil
+}
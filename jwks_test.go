@@ -0,0 +1,187 @@
+package goth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_KeySet(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	kids := []string{"k1"}
+	fetches := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		set := jwk.NewSet()
+		for _, kid := range kids {
+			key, err := jwk.New(&priv.PublicKey)
+			a.NoError(err)
+			a.NoError(key.Set(jwk.KeyIDKey, kid))
+			set.Add(key)
+		}
+		a.NoError(json.NewEncoder(w).Encode(set))
+	}))
+	defer ts.Close()
+
+	keys := goth.NewKeySet(ts.URL, time.Hour)
+	key, err := keys.Key(nil, "k1")
+	a.NoError(err)
+	a.Equal(&priv.PublicKey, key)
+	_, err = keys.Key(nil, "k1")
+	a.NoError(err)
+	a.Equal(1, fetches)
+
+	// Unknown keys only trigger a refresh once KeySetMinRefresh has passed.
+	kids = append(kids, "k2")
+	_, err = keys.Key(nil, "k2")
+	a.Error(err)
+	a.Equal(1, fetches)
+	now = now.Add(2 * time.Minute)
+	_, err = keys.Key(nil, "k2")
+	a.NoError(err)
+	a.Equal(2, fetches)
+
+	// The set is fetched again once its TTL has passed.
+	now = now.Add(2 * time.Hour)
+	_, err = keys.Key(nil, "k1")
+	a.NoError(err)
+	a.Equal(3, fetches)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{Subject: "sub"})
+	token.Header["kid"] = "k2"
+	signed, err := token.SignedString(priv)
+	a.NoError(err)
+	parsed, err := jwt.Parse(signed, keys.Keyfunc(nil))
+	a.NoError(err)
+	a.True(parsed.Valid)
+}
+
+func Test_KeySetFailures(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	var fetches int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	keys := goth.NewKeySet(ts.URL, time.Hour)
+	_, err := keys.Key(nil, "k1")
+	a.Error(err)
+	_, err = keys.Key(nil, "k1")
+	a.Error(err)
+	a.Equal(int32(1), atomic.LoadInt32(&fetches), "failed fetches are rate limited")
+
+	now = now.Add(2 * time.Minute)
+	_, err = keys.Key(nil, "k1")
+	a.Error(err)
+	a.Equal(int32(2), atomic.LoadInt32(&fetches))
+
+	// A fetch cancelled by its caller does not count.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	now = now.Add(2 * time.Minute)
+	_, err = keys.KeyContext(ctx, nil, "k1")
+	a.Error(err)
+	_, err = keys.Key(nil, "k1")
+	a.Error(err)
+	a.Equal(int32(3), atomic.LoadInt32(&fetches))
+}
+
+func Test_KeySetConcurrentFetch(t *testing.T) {
+	a := assert.New(t)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	var fetches int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		key, _ := jwk.New(&priv.PublicKey)
+		_ = key.Set(jwk.KeyIDKey, "k1")
+		set := jwk.NewSet()
+		set.Add(key)
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	defer ts.Close()
+
+	keys := goth.NewKeySet(ts.URL, time.Hour)
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = keys.Key(nil, "k1")
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, err := range errs {
+		a.NoError(err)
+	}
+	a.Equal(int32(1), atomic.LoadInt32(&fetches))
+}
+
+func Test_ValidateClaims(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	claims := func() *jwt.RegisteredClaims {
+		return &jwt.RegisteredClaims{
+			Issuer:    "https://issuer.example.com",
+			Audience:  jwt.ClaimStrings{"aud"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		}
+	}
+
+	a.NoError(goth.ValidateClaims(claims(), "https://issuer.example.com", "aud"))
+	a.NoError(goth.ValidateClaims(claims(), "", ""))
+	a.EqualError(goth.ValidateClaims(claims(), "https://other.example.com", "aud"), `issuer is incorrect: "https://issuer.example.com"`)
+	a.EqualError(goth.ValidateClaims(claims(), "https://issuer.example.com", "other"), "audience is incorrect")
+
+	c := claims()
+	c.ExpiresAt = nil
+	a.EqualError(goth.ValidateClaims(c, "", ""), "token is expired")
+	c = claims()
+	c.ExpiresAt = jwt.NewNumericDate(now.Add(-5 * time.Second))
+	a.NoError(goth.ValidateClaims(c, "", ""))
+	c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+	a.EqualError(goth.ValidateClaims(c, "", ""), "token is expired")
+	a.Equal(goth.ErrTokenExpired, goth.ValidateClaims(c, "", ""))
+	c = claims()
+	c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute))
+	a.EqualError(goth.ValidateClaims(c, "", ""), "token is not valid yet")
+}
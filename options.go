@@ -0,0 +1,343 @@
+package goth
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// ProviderOptions are the settings changed by Options, see Configure.
+type ProviderOptions struct {
+	// Scopes, when not nil, replace the scopes the provider asks for.
+	Scopes []string
+	// HTTPClient makes the HTTP calls of the provider.
+	HTTPClient *http.Client
+	// AuthURL and TokenURL replace the end-points of the provider, for
+	// self-hosted servers and proxies.
+	AuthURL  string
+	TokenURL string
+	// UserAgent is sent in the User-Agent header of the HTTP calls.
+	UserAgent string
+	// TokenParams are added to the token requests of the provider.
+	TokenParams url.Values
+	// PKCE protects the authorization codes with PKCE, see NewPKCE.
+	PKCE bool
+
+	// tokenEndpoint receives the token end-point of the provider from
+	// OAuth2Config, the only URL TokenParams are sent to.
+	tokenEndpoint *tokenEndpoint
+}
+
+// Option changes a setting of a provider, see Configure.
+type Option func(*ProviderOptions)
+
+// WithScopes replaces the scopes the provider asks for, including those it
+// asks for by default.
+func WithScopes(scopes ...string) Option {
+	return func(o *ProviderOptions) {
+		o.Scopes = append([]string{}, scopes...)
+	}
+}
+
+// WithHTTPClient makes all of the HTTP calls of the provider, to the token
+// end-point as well as those of FetchUser, with client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *ProviderOptions) {
+		o.HTTPClient = client
+	}
+}
+
+// WithAuthURL replaces the authorization end-point of the provider.
+func WithAuthURL(authURL string) Option {
+	return func(o *ProviderOptions) {
+		o.AuthURL = authURL
+	}
+}
+
+// WithTokenURL replaces the token end-point of the provider.
+func WithTokenURL(tokenURL string) Option {
+	return func(o *ProviderOptions) {
+		o.TokenURL = tokenURL
+	}
+}
+
+// WithUserAgent sends userAgent in the User-Agent header of the HTTP calls of
+// the provider, which some APIs, such as GitHub's, ask clients to set.
+func WithUserAgent(userAgent string) Option {
+	return func(o *ProviderOptions) {
+		o.UserAgent = userAgent
+	}
+}
+
+// WithTokenParams adds params to the token requests of the provider, those
+// exchanging codes as well as refreshing tokens, for the providers asking
+// for more than OAuth2 does, see HTTPClientWithTokenParams. Only the requests
+// to the token end-point of the provider have them, so only the providers
+// configuring an oauth2.Config support the option.
+//
+//	goth.WithTokenParams(url.Values{"appid": {appID}})
+func WithTokenParams(params url.Values) Option {
+	return func(o *ProviderOptions) {
+		if o.TokenParams == nil {
+			o.TokenParams = url.Values{}
+		}
+		for name, values := range params {
+			o.TokenParams[name] = append(o.TokenParams[name], values...)
+		}
+	}
+}
+
+// WithPKCE protects the authorization codes of the provider with PKCE.
+func WithPKCE() Option {
+	return func(o *ProviderOptions) {
+		o.PKCE = true
+	}
+}
+
+// Configurable is implemented by the providers accepting the scopes, URLs and
+// PKCE options. Configure returns a copy of the provider with them applied.
+type Configurable interface {
+	Configure(options ProviderOptions) (Provider, error)
+}
+
+// OptionNotSupportedError is returned by Configure when the provider cannot
+// apply an option.
+type OptionNotSupportedError struct {
+	Provider string
+	Option   string
+}
+
+func (e *OptionNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: the %s option is not supported", e.Provider, e.Option)
+}
+
+// Configure returns a copy of p with opts applied, leaving p as it is. It
+// returns a *OptionNotSupportedError when the provider cannot apply one of
+// them.
+//
+//	provider, err := goth.Configure(github.New(key, secret, callbackURL),
+//		goth.WithScopes("read:user", "read:org"),
+//		goth.WithHTTPClient(proxied),
+//		goth.WithUserAgent("example-app/1.0"),
+//	)
+func Configure(p Provider, opts ...Option) (Provider, error) {
+	var o ProviderOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.tokenEndpoint = &tokenEndpoint{}
+
+	if option := o.configurableOption(); option != "" {
+		c, ok := p.(Configurable)
+		if !ok {
+			return nil, &OptionNotSupportedError{Provider: p.Name(), Option: option}
+		}
+		var err error
+		if p, err = c.Configure(o); err != nil {
+			return nil, err
+		}
+	}
+	if len(o.TokenParams) > 0 && !o.tokenEndpoint.recorded() {
+		return nil, &OptionNotSupportedError{Provider: p.Name(), Option: "WithTokenParams"}
+	}
+
+	if o.HTTPClient != nil || o.UserAgent != "" || len(o.TokenParams) > 0 {
+		b, ok := p.(ClientBinder)
+		if !ok {
+			option := "WithHTTPClient"
+			if o.HTTPClient == nil && o.UserAgent != "" {
+				option = "WithUserAgent"
+			} else if o.HTTPClient == nil {
+				option = "WithTokenParams"
+			}
+			return nil, &OptionNotSupportedError{Provider: p.Name(), Option: option}
+		}
+		p = b.BindClient(func(c *http.Client) *http.Client {
+			if o.HTTPClient != nil {
+				c = o.HTTPClient
+			}
+			if o.UserAgent != "" {
+				c = HTTPClientWithUserAgent(c, o.UserAgent)
+			}
+			if len(o.TokenParams) > 0 {
+				c = httpClientWithTokenParams(c, o.tokenEndpoint, o.TokenParams)
+			}
+			return c
+		})
+	}
+	return p, nil
+}
+
+// configurableOption returns the name of the first option set that only
+// Configurable providers apply.
+func (o ProviderOptions) configurableOption() string {
+	switch {
+	case o.Scopes != nil:
+		return "WithScopes"
+	case o.AuthURL != "":
+		return "WithAuthURL"
+	case o.TokenURL != "":
+		return "WithTokenURL"
+	case o.PKCE:
+		return "WithPKCE"
+	case len(o.TokenParams) > 0:
+		return "WithTokenParams"
+	}
+	return ""
+}
+
+// OAuth2Config returns a copy of config with the scopes and end-points of the
+// options, for the Configure method of the providers of OAuth2.
+func (o ProviderOptions) OAuth2Config(config *oauth2.Config) *oauth2.Config {
+	if config == nil {
+		// The end-points are not known yet, the provider applies the options
+		// again once they are.
+		o.tokenEndpoint.set("")
+		return nil
+	}
+	c := *config
+	if o.Scopes != nil {
+		c.Scopes = o.Scopes
+	}
+	if o.AuthURL != "" {
+		c.Endpoint.AuthURL = o.AuthURL
+	}
+	if o.TokenURL != "" {
+		c.Endpoint.TokenURL = o.TokenURL
+	}
+	o.tokenEndpoint.set(c.Endpoint.TokenURL)
+	return &c
+}
+
+// HTTPClientWithUserAgent returns a copy of client that sends userAgent in
+// the User-Agent header of every request.
+func HTTPClientWithUserAgent(client *http.Client, userAgent string) *http.Client {
+	c := *HTTPClientWithFallBack(client)
+	c.Transport = &userAgentTransport{base: c.Transport, userAgent: userAgent}
+	return &c
+}
+
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// A RoundTripper must not modify the request it is given.
+	r := req.Clone(req.Context())
+	r.Header.Set("User-Agent", t.userAgent)
+	return base.RoundTrip(r)
+}
+
+// HTTPClientWithTokenParams returns a copy of client adding params to the
+// token requests it sends to tokenURL: the form-encoded POST requests with a
+// grant_type, made by oauth2.Config for the exchanges of codes and the
+// refreshes of tokens. Requests to any other URL are left alone, and the
+// parameters already in a request are kept.
+func HTTPClientWithTokenParams(client *http.Client, tokenURL string, params url.Values) *http.Client {
+	e := &tokenEndpoint{}
+	e.set(tokenURL)
+	return httpClientWithTokenParams(client, e, params)
+}
+
+func httpClientWithTokenParams(client *http.Client, endpoint *tokenEndpoint, params url.Values) *http.Client {
+	c := *HTTPClientWithFallBack(client)
+	c.Transport = &tokenParamsTransport{base: c.Transport, endpoint: endpoint, params: params}
+	return &c
+}
+
+// tokenEndpoint is the token end-point of a provider, recorded by
+// OAuth2Config when Configure applies the options, or later by the providers
+// discovering their end-points.
+type tokenEndpoint struct {
+	mu    sync.Mutex
+	url   string
+	isSet bool
+}
+
+func (e *tokenEndpoint) set(tokenURL string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.url, e.isSet = tokenURL, true
+}
+
+func (e *tokenEndpoint) get() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.url
+}
+
+// recorded tells whether the provider applied the options to an
+// oauth2.Config, the token requests of which TokenParams are added to.
+func (e *tokenEndpoint) recorded() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.isSet
+}
+
+type tokenParamsTransport struct {
+	base     http.RoundTripper
+	endpoint *tokenEndpoint
+	params   url.Values
+}
+
+// isTokenRequest tells whether req is sent to the token end-point, its query
+// aside.
+func (t *tokenParamsTransport) isTokenRequest(req *http.Request) bool {
+	u, err := url.Parse(t.endpoint.get())
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(req.URL.Scheme, u.Scheme) && strings.EqualFold(req.URL.Host, u.Host) &&
+		req.URL.Path == u.Path
+}
+
+func (t *tokenParamsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodPost || req.Body == nil || !t.isTokenRequest(req) ||
+		!strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return base.RoundTrip(req)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err == nil && form.Get("grant_type") != "" {
+		for name, values := range t.params {
+			if _, ok := form[name]; !ok {
+				form[name] = values
+			}
+		}
+		body = []byte(form.Encode())
+	}
+
+	// A RoundTripper must not modify the request it is given.
+	r := req.Clone(req.Context())
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.ContentLength = int64(len(body))
+	return base.RoundTrip(r)
+}
@@ -0,0 +1,146 @@
+package goth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/amazonseller"
+	"github.com/bgdsh/goth/providers/github"
+	"github.com/bgdsh/goth/providers/gitlab"
+	"github.com/bgdsh/goth/providers/nostr"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Configure(t *testing.T) {
+	a := assert.New(t)
+
+	var userAgent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"login":"homer","email":"homer@example.com"}`))
+	}))
+	defer ts.Close()
+
+	original := github.NewCustomisedURL("key", "secret", "/foo", ts.URL, ts.URL, ts.URL, ts.URL)
+	p, err := goth.Configure(original,
+		goth.WithScopes("read:org"),
+		goth.WithAuthURL("https://github.example.com/login/oauth/authorize"),
+		goth.WithHTTPClient(ts.Client()),
+		goth.WithUserAgent("example-app/1.0"),
+		goth.WithPKCE(),
+	)
+	a.NoError(err)
+	a.NotSame(original, p)
+	a.False(original.PKCE)
+	a.Nil(original.HTTPClient)
+	a.True(p.(*github.Provider).PKCE)
+
+	session, err := p.BeginAuth("state")
+	a.NoError(err)
+	authURL, _ := session.GetAuthURL()
+	u, err := url.Parse(authURL)
+	a.NoError(err)
+	a.Equal("github.example.com", u.Host)
+	a.Equal("read:org", u.Query().Get("scope"))
+	a.NotEmpty(u.Query().Get("code_challenge"))
+
+	_, err = p.FetchUser(&github.Session{AccessToken: "token"})
+	a.NoError(err)
+	a.Equal("example-app/1.0", userAgent)
+
+	session, err = original.BeginAuth("state")
+	a.NoError(err)
+	authURL, _ = session.GetAuthURL()
+	a.Contains(authURL, ts.URL)
+	a.NotContains(authURL, "read%3Aorg")
+}
+
+func Test_ConfigureNotSupported(t *testing.T) {
+	a := assert.New(t)
+
+	var notSupported *goth.OptionNotSupportedError
+	_, err := goth.Configure(nostr.New("/login", "/callback"), goth.WithScopes("profile"))
+	a.True(errors.As(err, &notSupported))
+	a.Equal("nostr", notSupported.Provider)
+	a.Equal("WithScopes", notSupported.Option)
+
+	_, err = goth.Configure(nostr.New("/login", "/callback"), goth.WithUserAgent("example-app/1.0"))
+	a.EqualError(err, "nostr: the WithUserAgent option is not supported")
+
+	_, err = goth.Configure(amazonseller.New("app", "key", "secret", "/foo"), goth.WithPKCE())
+	a.EqualError(err, "amazonseller: the WithPKCE option is not supported")
+
+	p := nostr.New("/login", "/callback")
+	configured, err := goth.Configure(p)
+	a.NoError(err)
+	a.Same(p, configured)
+}
+
+func Test_WithTokenParams(t *testing.T) {
+	a := assert.New(t)
+
+	var forms []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		forms = append(forms, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token","token_type":"bearer","refresh_token":"refresh","expires_in":3600}`))
+	}))
+	defer ts.Close()
+
+	p, err := goth.Configure(gitlab.NewCustomisedURL("key", "secret", "/foo", ts.URL, ts.URL, ts.URL),
+		goth.WithTokenParams(url.Values{"appid": {"app"}, "client_id": {"other"}}),
+		goth.WithTokenParams(url.Values{"shop": {"example"}}),
+	)
+	a.NoError(err)
+
+	session, err := p.BeginAuth("state")
+	a.NoError(err)
+	_, err = session.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	_, err = p.RefreshToken("refresh")
+	a.NoError(err)
+
+	a.Len(forms, 2)
+	for _, form := range forms {
+		a.Equal("app", form.Get("appid"))
+		a.Equal("example", form.Get("shop"))
+	}
+	a.Equal("authorization_code", forms[0].Get("grant_type"))
+	a.Equal("code", forms[0].Get("code"))
+	a.Equal("refresh_token", forms[1].Get("grant_type"))
+	a.Equal("refresh", forms[1].Get("refresh_token"))
+
+	p.FetchUser(&gitlab.Session{AccessToken: "token"})
+	a.Len(forms, 3)
+	a.Empty(forms[2].Get("appid"), "only the token requests have the parameters")
+
+	_, err = goth.Configure(nostr.New("/login", "/callback"), goth.WithTokenParams(url.Values{"appid": {"app"}}))
+	a.EqualError(err, "nostr: the WithTokenParams option is not supported")
+}
+
+func Test_HTTPClientWithTokenParams(t *testing.T) {
+	a := assert.New(t)
+
+	forms := map[string]url.Values{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		forms[r.URL.Path] = r.PostForm
+	}))
+	defer ts.Close()
+
+	c := goth.HTTPClientWithTokenParams(nil, ts.URL+"/token", url.Values{"appid": {"app"}})
+	for _, path := range []string{"/token", "/other"} {
+		res, err := c.PostForm(ts.URL+path+"?q=1", url.Values{"grant_type": {"refresh_token"}})
+		a.NoError(err)
+		res.Body.Close()
+	}
+
+	a.Equal("app", forms["/token"].Get("appid"))
+	a.Empty(forms["/other"].Get("appid"), "only the requests to the token end-point have the parameters")
+}
@@ -0,0 +1,100 @@
+package goth
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrPartialUser is matched, with errors.Is, by the errors returned along with
+// the partial users of PartialUser.
+var ErrPartialUser = errors.New("partial user")
+
+// PartialUserError is returned by PartialUser and FetchUserLenient along with
+// a user built from the tokens of the session. Err is the error of FetchUser.
+// It matches ErrPartialUser.
+type PartialUserError struct {
+	Provider string
+	Err      error
+}
+
+func (e *PartialUserError) Error() string {
+	return fmt.Sprintf("%s: only the claims of the tokens are known: %v", e.Provider, e.Err)
+}
+
+// Unwrap returns the error of FetchUser.
+func (e *PartialUserError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrPartialUser.
+func (e *PartialUserError) Is(target error) bool {
+	return target == ErrPartialUser
+}
+
+// FetchUserLenient fetches the user of s like p.FetchUser, falling back on
+// PartialUser when the provider fails to return it.
+func FetchUserLenient(p Provider, s Session) (User, error) {
+	user, err := p.FetchUser(s)
+	if err != nil {
+		return PartialUser(p, s, err)
+	}
+	return user, nil
+}
+
+// PartialUser builds the user of s from its tokens when FetchUser failed with
+// err after a successful token exchange, for instance because the user API of
+// the provider is down or has changed: the access and refresh tokens, their
+// expiry and the claims of the ID token (sub, email, name, given_name,
+// family_name, nickname or preferred_username, and picture), also kept in
+// RawData. It returns the user with a *PartialUserError, or err itself when
+// the session holds no ID token with a subject to identify the user by.
+//
+// The signature of the ID token is not checked: the token is trusted for
+// having been received from the token end-point of the provider, as OpenID
+// Connect allows. Sessions without an IDToken field never give a partial
+// user.
+func PartialUser(p Provider, s Session, err error) (User, error) {
+	v, ok := sessionTokens(s)
+	if !ok {
+		return User{}, err
+	}
+	f := v.FieldByName("IDToken")
+	if !f.IsValid() || f.Kind() != reflect.String || f.String() == "" {
+		return User{}, err
+	}
+	claims := jwt.MapClaims{}
+	if _, _, parseErr := (&jwt.Parser{}).ParseUnverified(f.String(), claims); parseErr != nil {
+		return User{}, err
+	}
+	claim := func(names ...string) string {
+		for _, name := range names {
+			if value, ok := claims[name].(string); ok && value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	if claim("sub") == "" {
+		return User{}, err
+	}
+
+	return User{
+		RawData:      map[string]interface{}(claims),
+		Provider:     p.Name(),
+		UserID:       claim("sub"),
+		Email:        claim("email"),
+		Name:         claim("name"),
+		FirstName:    claim("given_name"),
+		LastName:     claim("family_name"),
+		NickName:     claim("nickname", "preferred_username"),
+		AvatarURL:    claim("picture"),
+		AccessToken:  v.FieldByName("AccessToken").String(),
+		RefreshToken: v.FieldByName("RefreshToken").String(),
+		ExpiresAt:    v.FieldByName("ExpiresAt").Interface().(time.Time),
+		IDToken:      f.String(),
+	}, &PartialUserError{Provider: p.Name(), Err: err}
+}
@@ -0,0 +1,93 @@
+package goth_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var errUserInfo = errors.New("userinfo is down")
+
+type brokenUserInfoProvider struct {
+	faux.Provider
+}
+
+func (p *brokenUserInfoProvider) FetchUser(session goth.Session) (goth.User, error) {
+	return goth.User{}, errUserInfo
+}
+
+func unsignedIDToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func Test_FetchUserLenient(t *testing.T) {
+	a := assert.New(t)
+	p := &brokenUserInfoProvider{}
+	expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &tokenSession{RefreshToken: "refresh", ExpiresAt: expiresAt}
+	s.AccessToken = "access"
+	s.IDToken = unsignedIDToken(t, jwt.MapClaims{
+		"sub":                "42",
+		"email":              "homer@example.com",
+		"name":               "Homer Simpson",
+		"given_name":         "Homer",
+		"family_name":        "Simpson",
+		"preferred_username": "homer",
+		"picture":            "https://example.com/homer.png",
+	})
+
+	user, err := goth.FetchUserLenient(p, s)
+	a.True(errors.Is(err, goth.ErrPartialUser))
+	a.True(errors.Is(err, errUserInfo))
+	var partial *goth.PartialUserError
+	a.True(errors.As(err, &partial))
+	a.Equal("faux", partial.Provider)
+	a.EqualError(err, "faux: only the claims of the tokens are known: userinfo is down")
+
+	a.Equal("faux", user.Provider)
+	a.Equal("42", user.UserID)
+	a.Equal("homer@example.com", user.Email)
+	a.Equal("Homer Simpson", user.Name)
+	a.Equal("Homer", user.FirstName)
+	a.Equal("Simpson", user.LastName)
+	a.Equal("homer", user.NickName)
+	a.Equal("https://example.com/homer.png", user.AvatarURL)
+	a.Equal("access", user.AccessToken)
+	a.Equal("refresh", user.RefreshToken)
+	a.Equal(expiresAt, user.ExpiresAt)
+	a.Equal(s.IDToken, user.IDToken)
+	a.Equal("42", user.RawData["sub"])
+}
+
+func Test_PartialUserNeedsASubject(t *testing.T) {
+	a := assert.New(t)
+	p := &brokenUserInfoProvider{}
+
+	s := &tokenSession{}
+	_, err := goth.PartialUser(p, s, errUserInfo)
+	a.Equal(errUserInfo, err, "no ID token")
+
+	s.IDToken = "not a JWT"
+	_, err = goth.PartialUser(p, s, errUserInfo)
+	a.Equal(errUserInfo, err)
+
+	s.IDToken = unsignedIDToken(t, jwt.MapClaims{"email": "homer@example.com"})
+	_, err = goth.PartialUser(p, s, errUserInfo)
+	a.Equal(errUserInfo, err, "no subject")
+
+	_, err = goth.PartialUser(p, &faux.Session{AccessToken: "access"}, errUserInfo)
+	a.Equal(errUserInfo, err, "no token fields")
+
+	user, err := goth.FetchUserLenient(&faux.Provider{}, &faux.Session{ID: "42", AccessToken: "access"})
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+}
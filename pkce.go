@@ -0,0 +1,66 @@
+package goth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"golang.org/x/oauth2"
+)
+
+// Providers of OAuth2 protect their authorization codes with PKCE (RFC 7636)
+// when their PKCE field is set, as public clients and providers such as TikTok
+// or Okta require: BeginAuth keeps a code verifier in the session and sends its
+// challenge, Authorize sends the verifier back with the code. gothic stores the
+// session between the two, nothing else is needed:
+//
+//	p := github.New(key, secret, callbackURL)
+//	p.PKCE = true
+//
+// See https://datatracker.ietf.org/doc/html/rfc7636
+
+// NewCodeVerifier returns a random PKCE code verifier.
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 code challenge of verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewPKCE returns a new code verifier and the options adding its challenge to
+// the authorization URL, or nothing when enabled is false.
+func NewPKCE(enabled bool) (string, []oauth2.AuthCodeOption, error) {
+	if !enabled {
+		return "", nil, nil
+	}
+	verifier, err := NewCodeVerifier()
+	if err != nil {
+		return "", nil, err
+	}
+	return verifier, ChallengeOptions(verifier), nil
+}
+
+// ChallengeOptions returns the options adding the S256 challenge of verifier
+// to the authorization URL.
+func ChallengeOptions(verifier string) []oauth2.AuthCodeOption {
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", CodeChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+}
+
+// VerifierOptions returns the option sending verifier with the token request,
+// or nothing when verifier is empty.
+func VerifierOptions(verifier string) []oauth2.AuthCodeOption {
+	if verifier == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("code_verifier", verifier)}
+}
@@ -0,0 +1,53 @@
+package goth_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func Test_CodeChallenge(t *testing.T) {
+	a := assert.New(t)
+
+	// Example of RFC 7636, appendix B.
+	a.Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", goth.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func Test_NewCodeVerifier(t *testing.T) {
+	a := assert.New(t)
+
+	v1, err := goth.NewCodeVerifier()
+	a.NoError(err)
+	v2, err := goth.NewCodeVerifier()
+	a.NoError(err)
+	a.Len(v1, 43)
+	a.NotEqual(v1, v2)
+}
+
+func Test_NewPKCE(t *testing.T) {
+	a := assert.New(t)
+
+	verifier, opts, err := goth.NewPKCE(false)
+	a.NoError(err)
+	a.Empty(verifier)
+	a.Empty(opts)
+
+	verifier, opts, err = goth.NewPKCE(true)
+	a.NoError(err)
+	a.NotEmpty(verifier)
+	config := &oauth2.Config{Endpoint: oauth2.Endpoint{AuthURL: "https://example.com/auth"}}
+	u, err := url.Parse(config.AuthCodeURL("state", opts...))
+	a.NoError(err)
+	a.Equal(goth.CodeChallenge(verifier), u.Query().Get("code_challenge"))
+	a.Equal("S256", u.Query().Get("code_challenge_method"))
+}
+
+func Test_VerifierOptions(t *testing.T) {
+	a := assert.New(t)
+
+	a.Empty(goth.VerifierOptions(""))
+	a.Len(goth.VerifierOptions("verifier"), 1)
+}
@@ -2,14 +2,24 @@ package goth
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"path"
+	"reflect"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/oauth2"
 )
 
 // Provider needs to be implemented for each 3rd party authentication provider
 // e.g. Facebook, Twitter, etc...
+//
+// A Provider is shared by every request once it has been registered with
+// UseProviders, so its methods must be safe for concurrent use. Setters such
+// as SetName should only be called while configuring the provider, before it
+// is registered; register a changed copy with ReplaceProvider instead. Any
+// state a provider caches between requests must be guarded by the provider
+// itself.
 type Provider interface {
 	Name() string
 	SetName(name string)
@@ -26,50 +36,187 @@ const NoAuthUrlErrorMessage = "an AuthURL has not been set"
 // Providers is list of known/available providers.
 type Providers map[string]Provider
 
-var providers = Providers{}
+// The registered providers are kept in an immutable map, replaced as a whole
+// under providersMu by every change, so that lookups, made on every request,
+// need no lock and never see a change half made.
+var (
+	providersMu sync.Mutex
+	providers   atomic.Value // Providers
+)
+
+func init() {
+	providers.Store(Providers{})
+}
+
+func loadProviders() Providers {
+	return providers.Load().(Providers)
+}
+
+// updateProviders replaces the registered providers with a copy changed by
+// update.
+func updateProviders(update func(Providers)) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	current := loadProviders()
+	next := make(Providers, len(current)+1)
+	for name, provider := range current {
+		next[name] = provider
+	}
+	update(next)
+	providers.Store(next)
+}
 
 // UseProviders adds a list of available providers for use with goth.
 // Can be called multiple times. If you pass the same provider more
-// than once, the last will be used.
+// than once, the last will be used. The callback URLs of the providers are
+// not validated: register them with CallbackPolicy.UseProviders to reject
+// misconfigured ones at startup.
 func UseProviders(viders ...Provider) {
-	for _, provider := range viders {
-		providers[provider.Name()] = provider
+	updateProviders(func(p Providers) {
+		for _, provider := range viders {
+			p[provider.Name()] = provider
+		}
+	})
+}
+
+// UseProvider adds provider for use with goth, in place of any provider of the
+// same name.
+func UseProvider(provider Provider) {
+	UseProviders(provider)
+}
+
+// UseProviderAs registers provider under name, for applications using several
+// instances of a provider type, such as two GitLab servers or several Okta
+// organizations:
+//
+//	goth.UseProviderAs("gitlab-internal", gitlab.NewCustomisedURL(key, secret, internalCallbackURL, authURL, tokenURL, profileURL))
+//	goth.UseProviderAs("gitlab-public", gitlab.New(key, secret, publicCallbackURL))
+//
+// The name, set with SetName, is the one gothic reads from the URL and the
+// Provider of the users, so each instance needs its own callback URL ending
+// in its name. UseProviderAs returns a *ProviderNameTakenError, registering
+// nothing, when another provider is registered under name or provider is
+// already registered under another name.
+func UseProviderAs(name string, provider Provider) error {
+	var err error
+	updateProviders(func(p Providers) {
+		if registered, ok := p[name]; ok && registered != provider {
+			err = &ProviderNameTakenError{Name: name}
+			return
+		}
+		for registeredName, registered := range p {
+			if registered == provider && registeredName != name {
+				err = &ProviderNameTakenError{Name: name, RegisteredAs: registeredName}
+				return
+			}
+		}
+		provider.SetName(name)
+		p[name] = provider
+	})
+	return err
+}
+
+// ProviderType returns the type of provider, the name of its package such as
+// "gitlab", whatever name it is registered under.
+func ProviderType(provider Provider) string {
+	t := reflect.TypeOf(provider)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
+	return path.Base(t.PkgPath())
 }
 
-// GetProviders returns a list of all the providers currently in use.
+// ReplaceProvider swaps the registered provider of the same name for
+// provider, for instance with a copy using a rotated secret. Requests that
+// already got the previous provider complete with it. It returns a
+// *ProviderNotFoundError when no provider of that name is registered.
+func ReplaceProvider(provider Provider) error {
+	var err error
+	updateProviders(func(p Providers) {
+		if _, ok := p[provider.Name()]; !ok {
+			err = &ProviderNotFoundError{Name: provider.Name()}
+			return
+		}
+		p[provider.Name()] = provider
+	})
+	return err
+}
+
+// DeleteProvider removes the named provider. Logins begun with it can no
+// longer complete.
+func DeleteProvider(name string) {
+	updateProviders(func(p Providers) {
+		delete(p, name)
+	})
+}
+
+// GetProviders returns a snapshot of the providers currently in use, which
+// later changes do not affect.
 func GetProviders() Providers {
-	return providers
+	current := loadProviders()
+	snapshot := make(Providers, len(current))
+	for name, provider := range current {
+		snapshot[name] = provider
+	}
+	return snapshot
 }
 
 // GetProvider returns a previously created provider. If Goth has not
 // been told to use the named provider it will return an error.
 func GetProvider(name string) (Provider, error) {
-	provider := providers[name]
+	provider := loadProviders()[name]
 	if provider == nil {
-		return nil, fmt.Errorf("no provider for %s exists", name)
+		return nil, &ProviderNotFoundError{Name: name}
 	}
 	return provider, nil
 }
 
-// ClearProviders will remove all providers currently in use.
+// ClearProviders will remove all providers currently in use, and forget the
+// hosts allowed by CallbackPolicy.UseProviders.
 // This is useful, mostly, for testing purposes.
 func ClearProviders() {
-	providers = Providers{}
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers.Store(Providers{})
+
+	callbackHostsMu.Lock()
+	callbackHosts = nil
+	callbackHostsMu.Unlock()
 }
 
 // ContextForClient provides a context for use with oauth2.
 func ContextForClient(h *http.Client) context.Context {
+	if h == nil {
+		h = DefaultHTTPClient
+	}
 	if h == nil {
 		return oauth2.NoContext
 	}
 	return context.WithValue(oauth2.NoContext, oauth2.HTTPClient, h)
 }
 
+// DefaultHTTPClient, when set, is the client of the providers that were not
+// given one, instead of http.DefaultClient, to send their calls through a
+// proxy or to trust a private CA for example.
+var DefaultHTTPClient *http.Client
+
 // HTTPClientWithFallBack to be used in all fetch operations.
 func HTTPClientWithFallBack(h *http.Client) *http.Client {
 	if h != nil {
 		return h
 	}
+	if DefaultHTTPClient != nil {
+		return DefaultHTTPClient
+	}
 	return http.DefaultClient
 }
+
+// ClientFunc makes its calls with the client it returns at the time of each
+// call, for libraries taking a client once, such as the OAuth 1.0a consumers,
+// to follow the HTTPClient of the provider.
+type ClientFunc func() *http.Client
+
+// Do sends req with the client returned by f.
+func (f ClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f().Do(req)
+}
@@ -1,11 +1,17 @@
 package goth_test
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/bgdsh/goth"
 	"github.com/bgdsh/goth/providers/faux"
+	"github.com/bgdsh/goth/providers/gitlab"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 func Test_UseProviders(t *testing.T) {
@@ -33,3 +39,121 @@ func Test_GetProvider(t *testing.T) {
 	a.Equal(err.Error(), "no provider for unknown exists")
 	goth.ClearProviders()
 }
+
+func Test_ReplaceAndDeleteProvider(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	first := &faux.Provider{}
+	a.Error(goth.ReplaceProvider(first))
+	goth.UseProvider(first)
+	snapshot := goth.GetProviders()
+
+	second := &faux.Provider{HTTPClient: &http.Client{}}
+	a.NoError(goth.ReplaceProvider(second))
+	p, err := goth.GetProvider("faux")
+	a.NoError(err)
+	a.Same(second, p)
+	a.Same(first, snapshot["faux"])
+
+	snapshot["other"] = first
+	_, err = goth.GetProvider("other")
+	a.Error(err)
+
+	goth.DeleteProvider("faux")
+	_, err = goth.GetProvider("faux")
+	a.True(errors.Is(err, goth.ErrProviderNotFound))
+	a.Len(goth.GetProviders(), 0)
+}
+
+func Test_UseProviderAs(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	internal := gitlab.NewCustomisedURL("key", "secret", "https://app.example.com/auth/gitlab-internal/callback",
+		"https://gitlab.example.com/oauth/authorize", "https://gitlab.example.com/oauth/token", "https://gitlab.example.com/api/v4/user")
+	public := gitlab.New("key", "secret", "https://app.example.com/auth/gitlab-public/callback")
+	a.NoError(goth.UseProviderAs("gitlab-internal", internal))
+	a.NoError(goth.UseProviderAs("gitlab-public", public))
+	a.NoError(goth.UseProviderAs("gitlab-public", public))
+
+	p, err := goth.GetProvider("gitlab-internal")
+	a.NoError(err)
+	a.Same(internal, p)
+	a.Equal("gitlab-internal", p.Name())
+	a.Equal("gitlab", goth.ProviderType(p))
+	p, err = goth.GetProvider("gitlab-public")
+	a.NoError(err)
+	a.Same(public, p)
+	_, err = goth.GetProvider("gitlab")
+	a.Error(err)
+
+	err = goth.UseProviderAs("gitlab-public", gitlab.New("key", "secret", "https://app.example.com/auth/gitlab/callback"))
+	a.True(errors.Is(err, goth.ErrProviderNameTaken))
+	err = goth.UseProviderAs("gitlab", public)
+	a.True(errors.Is(err, goth.ErrProviderNameTaken))
+	a.Equal("cannot register the provider as gitlab, it is registered as gitlab-public", err.Error())
+	a.Equal("gitlab-public", public.Name())
+	a.Len(goth.GetProviders(), 2)
+
+	a.Equal("faux", goth.ProviderType(&faux.Provider{}))
+}
+
+func Test_ProvidersConcurrentUse(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	goth.UseProvider(&faux.Provider{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				goth.ReplaceProvider(&faux.Provider{})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := goth.GetProvider("faux")
+				a.NoError(err)
+				a.Len(goth.GetProviders(), 1)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_DefaultHTTPClient(t *testing.T) {
+	a := assert.New(t)
+
+	a.Same(http.DefaultClient, goth.HTTPClientWithFallBack(nil))
+
+	client := &http.Client{}
+	goth.DefaultHTTPClient = client
+	defer func() { goth.DefaultHTTPClient = nil }()
+	a.Same(client, goth.HTTPClientWithFallBack(nil))
+	a.Same(client, goth.ContextForClient(nil).Value(oauth2.HTTPClient))
+
+	own := &http.Client{}
+	a.Same(own, goth.HTTPClientWithFallBack(own))
+}
+
+func Test_ClientFunc(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	calls := 0
+	f := goth.ClientFunc(func() *http.Client {
+		calls++
+		return ts.Client()
+	})
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := f.Do(req)
+	a.NoError(err)
+	resp.Body.Close()
+	a.Equal(1, calls)
+}
@@ -0,0 +1,209 @@
+// Package adobe implements the OAuth2 protocol for authenticating users through
+// Adobe IMS, the identity service of Creative Cloud, Document Cloud and Acrobat Sign.
+// Reference: https://developer.adobe.com/developer-console/docs/guides/authentication/UserAuthentication/
+package adobe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://ims-na1.adobelogin.com/ims/authorize/v2"
+	TokenURL   = "https://ims-na1.adobelogin.com/ims/token/v3"
+	ProfileURL = "https://ims-na1.adobelogin.com/ims/userinfo/v2"
+)
+
+// Scopes of Adobe IMS, ScopeOpenID, ScopeAdobeID, ScopeEmail and ScopeProfile
+// are requested when no scopes are given. ScopeOfflineAccess is needed for a
+// refresh token.
+const (
+	ScopeOpenID        = "openid"
+	ScopeAdobeID       = "AdobeID"
+	ScopeEmail         = "email"
+	ScopeProfile       = "profile"
+	ScopeOfflineAccess = "offline_access"
+)
+
+// New creates a new Adobe provider, and sets up important connection details.
+// You should always call `adobe.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeOpenID, ScopeAdobeID, ScopeEmail, ScopeProfile}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "adobe",
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Adobe.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the adobe package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Adobe for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      goth.ScopeFormatOf(p.Name(), goth.CommaSeparatedScopes).AuthCodeURL(p.config, state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Adobe and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		IDToken:      sess.IDToken,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Sub        string `json:"sub"`
+		Name       string `json:"name"`
+		GivenName  string `json:"given_name"`
+		FamilyName string `json:"family_name"`
+		Email      string `json:"email"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.Sub
+	user.Name = u.Name
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.Email = u.Email
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token, it is only
+// issued with the ScopeOfflineAccess scope.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
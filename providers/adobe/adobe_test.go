@@ -0,0 +1,91 @@
+package adobe_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/adobe"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), adobeProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := adobeProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := adobeProvider().BeginAuth("test_state")
+	s := session.(*adobe.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://ims-na1.adobelogin.com/ims/authorize/v2")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=openid%2CAdobeID%2Cemail%2Cprofile")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/ims/token/v3":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","refresh_token":"refresh","id_token":"id","expires_in":86399}`)
+		case "/ims/userinfo/v2":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"sub":"A1B2C3D4E5F6@AdobeID","account_type":"type1","email_verified":true,"name":"Jane Doe","given_name":"Jane","family_name":"Doe","email":"jane@example.com"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	defer func(tokenURL, profileURL string) {
+		adobe.TokenURL = tokenURL
+		adobe.ProfileURL = profileURL
+	}(adobe.TokenURL, adobe.ProfileURL)
+	adobe.TokenURL = ts.URL + "/ims/token/v3"
+	adobe.ProfileURL = ts.URL + "/ims/userinfo/v2"
+	provider := adobeProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal("id", session.(*adobe.Session).IDToken)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("A1B2C3D4E5F6@AdobeID", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("refresh", user.RefreshToken)
+	a.Equal("id", user.IDToken)
+}
+
+func adobeProvider() *adobe.Provider {
+	return adobe.New("key", "secret", "/foo")
+}
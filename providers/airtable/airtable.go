@@ -0,0 +1,211 @@
+// Package airtable implements the OAuth2 protocol for authenticating users through Airtable.
+// Airtable requires PKCE, the code verifier is kept in the session between
+// BeginAuth and Authorize.
+// Reference: https://airtable.com/developers/web/api/oauth-reference
+package airtable
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://airtable.com/oauth2/v1/authorize"
+	TokenURL   = "https://airtable.com/oauth2/v1/token"
+	ProfileURL = "https://api.airtable.com/v0/meta/whoami"
+)
+
+// Scopes of the Airtable API, ScopeUserEmailRead is requested when no scopes
+// are given.
+const (
+	ScopeUserEmailRead    = "user.email:read"
+	ScopeDataRecordsRead  = "data.records:read"
+	ScopeDataRecordsWrite = "data.records:write"
+	ScopeSchemaBasesRead  = "schema.bases:read"
+	ScopeSchemaBasesWrite = "schema.bases:write"
+	ScopeWebhookManage    = "webhook:manage"
+)
+
+// New creates a new Airtable provider, and sets up important connection details.
+// You should always call `airtable.New` to get a new Provider. Never try to create
+// one manually. Leave secret empty for apps registered without a client secret.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeUserEmailRead}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "airtable",
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Airtable.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the airtable package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Airtable for an authentication end-point, with the challenge
+// of a new PKCE code verifier.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state,
+			oauth2.SetAuthURLParam("code_challenge", goth.CodeChallenge(verifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Airtable and access basic information about the user.
+// The email address is only returned with the user.email:read scope.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.ID
+	user.Email = u.Email
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+			// Confidential clients authenticate with basic auth, the others
+			// only send their client_id, in the body.
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+	if provider.Secret == "" {
+		c.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. Airtable
+// rotates refresh tokens, each one can be used once.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,97 @@
+package airtable_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/airtable"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), airtableProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := airtableProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := airtable.New("key", "secret", "/foo", airtable.ScopeUserEmailRead, airtable.ScopeDataRecordsRead).BeginAuth("test_state")
+	s := session.(*airtable.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://airtable.com/oauth2/v1/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=user.email%3Aread+data.records%3Aread")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			_, _, basic := r.BasicAuth()
+			if r.FormValue("client_id") == "public" {
+				a.False(basic)
+			} else {
+				a.True(basic)
+			}
+			a.NotEmpty(r.FormValue("code_verifier"))
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","scope":"user.email:read","expires_in":3600,"refresh_expires_in":5184000}`)
+		case "/v0/meta/whoami":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"id":"usrL2PNC5o3H4lBEi","email":"foo@bar.com","scopes":["user.email:read"]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	airtable.TokenURL, airtable.ProfileURL = ts.URL+"/token", ts.URL+"/v0/meta/whoami"
+	defer func() {
+		airtable.TokenURL, airtable.ProfileURL = "https://airtable.com/oauth2/v1/token", "https://api.airtable.com/v0/meta/whoami"
+	}()
+
+	for _, provider := range []*airtable.Provider{airtableProvider(), airtable.New("public", "", "/foo")} {
+		session, _ := provider.BeginAuth("state")
+		token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal("token", token)
+
+		user, err := provider.FetchUser(session)
+		a.NoError(err)
+		a.Equal("usrL2PNC5o3H4lBEi", user.UserID)
+		a.Equal("foo@bar.com", user.Email)
+	}
+}
+
+func airtableProvider() *airtable.Provider {
+	return airtable.New("key", "secret", "/foo")
+}
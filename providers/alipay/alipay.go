@@ -0,0 +1,368 @@
+// Package alipay implements the OAuth2 protocol for authenticating users through Alipay.
+// Reference: https://opendocs.alipay.com/open/284/web
+//
+// Alipay does not have an OAuth2 token end-point: tokens are requested from the Alipay gateway
+// with the alipay.system.oauth.token method, and every gateway request is signed with the
+// application's RSA private key (RSA2, that is SHA256WithRSA).
+package alipay
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://openauth.alipay.com/oauth2/publicAppAuthorize.htm"
+	GatewayURL = "https://openapi.alipay.com/gateway.do"
+)
+
+// shanghai is the time zone of the timestamps Alipay expects.
+var shanghai = time.FixedZone("CST", 8*60*60)
+
+// New creates a new Alipay provider, and sets up important connection details.
+// privateKey signs the requests made to the Alipay gateway, see ParsePrivateKey.
+// The auth_user scope is requested when no scopes are given.
+func New(appID string, privateKey *rsa.PrivateKey, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{"auth_user"}
+	}
+	return &Provider{
+		ClientKey:    appID,
+		PrivateKey:   privateKey,
+		CallbackURL:  callbackURL,
+		Scopes:       scopes,
+		providerName: "alipay",
+		authURL:      AuthURL,
+		gatewayURL:   GatewayURL,
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Alipay.
+type Provider struct {
+	ClientKey   string
+	PrivateKey  *rsa.PrivateKey
+	CallbackURL string
+	Scopes      []string
+	HTTPClient  *http.Client
+
+	// AlipayPublicKey, when set, is used to verify the signature of the
+	// gateway responses. Set it in production, see ParsePublicKey.
+	AlipayPublicKey *rsa.PublicKey
+
+	providerName string
+	authURL      string
+	gatewayURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the alipay package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Alipay for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	params := url.Values{}
+	params.Add("app_id", p.ClientKey)
+	params.Add("scope", goth.ScopeFormatOf(p.Name(), goth.CommaSeparatedScopes).Join(p.Scopes))
+	params.Add("redirect_uri", p.CallbackURL)
+	params.Add("state", state)
+	session := &Session{
+		AuthURL: fmt.Sprintf("%s?%s", p.authURL, params.Encode()),
+	}
+	return session, nil
+}
+
+// FetchUser will go to Alipay and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		Provider:     p.Name(),
+		UserID:       sess.UserID,
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	obj := struct {
+		UserID   string `json:"user_id"`
+		OpenID   string `json:"open_id"`
+		Avatar   string `json:"avatar"`
+		NickName string `json:"nick_name"`
+		Province string `json:"province"`
+		City     string `json:"city"`
+	}{}
+	raw, err := p.call("alipay.user.info.share", url.Values{"auth_token": {user.AccessToken}}, &obj)
+	if err != nil {
+		return user, err
+	}
+	if err := json.Unmarshal(raw, &user.RawData); err != nil {
+		return user, err
+	}
+
+	if obj.UserID != "" {
+		user.UserID = obj.UserID
+	} else if obj.OpenID != "" {
+		user.UserID = obj.OpenID
+	}
+	user.NickName = obj.NickName
+	user.Name = obj.NickName
+	user.AvatarURL = obj.Avatar
+	user.Location = strings.TrimSpace(obj.Province + " " + obj.City)
+
+	return user, nil
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token, _, err := p.fetchToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	return token, err
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// fetchToken calls alipay.system.oauth.token and returns the token along
+// with the id of the user it belongs to.
+func (p *Provider) fetchToken(params url.Values) (*oauth2.Token, string, error) {
+	obj := struct {
+		UserID       string      `json:"user_id"`
+		OpenID       string      `json:"open_id"`
+		AccessToken  string      `json:"access_token"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    json.Number `json:"expires_in"`
+	}{}
+	if _, err := p.call("alipay.system.oauth.token", params, &obj); err != nil {
+		return nil, "", err
+	}
+	if obj.AccessToken == "" {
+		return nil, "", errors.New("Invalid token received from provider")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  obj.AccessToken,
+		RefreshToken: obj.RefreshToken,
+	}
+	if expiresIn, err := strconv.ParseInt(obj.ExpiresIn.String(), 10, 64); err == nil && expiresIn > 0 {
+		token.Expiry = goth.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	userID := obj.UserID
+	if userID == "" {
+		userID = obj.OpenID
+	}
+	return token, userID, nil
+}
+
+// call signs and sends a request for method to the Alipay gateway, and
+// decodes the response into v. It returns the raw response.
+func (p *Provider) call(method string, params url.Values, v interface{}) (json.RawMessage, error) {
+	if p.PrivateKey == nil {
+		return nil, errors.New("alipay: no private key to sign requests with")
+	}
+
+	params.Set("app_id", p.ClientKey)
+	params.Set("method", method)
+	params.Set("format", "JSON")
+	params.Set("charset", "utf-8")
+	params.Set("sign_type", "RSA2")
+	params.Set("timestamp", goth.Now().In(shanghai).Format("2006-01-02 15:04:05"))
+	params.Set("version", "1.0")
+	sign, err := Sign(p.PrivateKey, params)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("sign", sign)
+
+	resp, err := p.Client().PostForm(p.gatewayURL, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("alipay %s returns code: %d", method, resp.StatusCode)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	responseKey := strings.Replace(method, ".", "_", -1) + "_response"
+	raw, ok := body[responseKey]
+	if !ok {
+		raw, ok = body["error_response"]
+	}
+	if !ok {
+		return nil, fmt.Errorf("alipay %s returns no %s", method, responseKey)
+	}
+
+	if p.AlipayPublicKey != nil {
+		var sign string
+		if err := json.Unmarshal(body["sign"], &sign); err != nil {
+			return nil, fmt.Errorf("alipay %s returns no signature", method)
+		}
+		if err := Verify(p.AlipayPublicKey, raw, sign); err != nil {
+			return nil, err
+		}
+	}
+
+	status := struct {
+		Code    string `json:"code"`
+		Msg     string `json:"msg"`
+		SubCode string `json:"sub_code"`
+		SubMsg  string `json:"sub_msg"`
+	}{}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return nil, err
+	}
+	// Successful responses carry code 10000, except for
+	// alipay.system.oauth.token which carries no code at all.
+	if status.Code != "" && status.Code != "10000" {
+		return nil, fmt.Errorf("CODE: %s, MSG: %s, SUB_CODE: %s, SUB_MSG: %s", status.Code, status.Msg, status.SubCode, status.SubMsg)
+	}
+
+	return raw, json.Unmarshal(raw, v)
+}
+
+// Sign returns the RSA2 signature of params: the SHA256WithRSA signature of
+// the non-empty params, except sign, sorted by key and joined as key=value
+// pairs with &.
+func Sign(key *rsa.PrivateKey, params url.Values) (string, error) {
+	hashed := sha256.Sum256([]byte(signingString(params)))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Verify checks the RSA2 signature of a gateway response.
+func Verify(key *rsa.PublicKey, content []byte, sign string) error {
+	sig, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil {
+		return fmt.Errorf("alipay: invalid signature: %v", err)
+	}
+	hashed := sha256.Sum256(content)
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], sig); err != nil {
+		return errors.New("alipay: response signature does not match")
+	}
+	return nil
+}
+
+func signingString(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k == "sign" || params.Get(k) == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+params.Get(k))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// ParsePrivateKey parses the application private key, in PKCS #1 or PKCS #8
+// form. The PEM header and footer may be left out, as in the keys generated
+// by the Alipay key tool.
+func ParsePrivateKey(key string) (*rsa.PrivateKey, error) {
+	der, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("alipay: invalid private key: %v", err)
+	}
+	rsaKey, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("alipay: private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// ParsePublicKey parses the Alipay public key, used to verify responses. The
+// PEM header and footer may be left out.
+func ParsePublicKey(key string) (*rsa.PublicKey, error) {
+	der, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	k, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("alipay: invalid public key: %v", err)
+	}
+	rsaKey, ok := k.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("alipay: public key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+func decodeKey(key string) ([]byte, error) {
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		return block.Bytes, nil
+	}
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(key), ""))
+	if err != nil {
+		return nil, fmt.Errorf("alipay: invalid key: %v", err)
+	}
+	return der, nil
+}
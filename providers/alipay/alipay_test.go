@@ -0,0 +1,145 @@
+package alipay_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/alipay"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	appKey, _    = rsa.GenerateKey(rand.Reader, 2048)
+	alipayKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), alipayProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := alipayProvider()
+	a.Equal(provider.ClientKey, "2021000000000000")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.Scopes, []string{"auth_user"})
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := alipayProvider()
+	session, err := provider.BeginAuth("test_state")
+	s := session.(*alipay.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://openauth.alipay.com/oauth2/publicAppAuthorize.htm")
+	a.Contains(s.AuthURL, "app_id=2021000000000000")
+	a.Contains(s.AuthURL, "scope=auth_user")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	respond := func(w http.ResponseWriter, key, content string) {
+		fmt.Fprintf(w, `{"%s":%s,"sign":"%s"}`, key, content, signContent(content))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("RSA2", r.PostForm.Get("sign_type"))
+		sign := r.PostForm.Get("sign")
+		expected, _ := alipay.Sign(appKey, r.PostForm)
+		a.Equal(expected, sign, "requests are signed with the application key")
+
+		switch r.PostForm.Get("method") {
+		case "alipay.system.oauth.token":
+			if r.PostForm.Get("code") != "good" {
+				respond(w, "error_response", `{"code":"40002","msg":"Invalid Arguments","sub_code":"isv.code-invalid","sub_msg":"授权码code无效"}`)
+				return
+			}
+			respond(w, "alipay_system_oauth_token_response", `{"user_id":"2088102150477652","access_token":"token","expires_in":1296000,"refresh_token":"refresh","re_expires_in":2592000}`)
+		case "alipay.user.info.share":
+			a.Equal("token", r.PostForm.Get("auth_token"))
+			respond(w, "alipay_user_info_share_response", `{"code":"10000","msg":"Success","user_id":"2088102150477652","avatar":"http://tfsimg.alipay.com/images/partner/T1uIxXXbpXXXXXXXX","nick_name":"Homer","province":"安徽省","city":"安庆"}`)
+		}
+	}))
+	defer ts.Close()
+
+	alipay.GatewayURL = ts.URL
+	defer func() { alipay.GatewayURL = "https://openapi.alipay.com/gateway.do" }()
+	provider := alipayProvider()
+	provider.AlipayPublicKey = &alipayKey.PublicKey
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"auth_code": {"good"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("2088102150477652", user.UserID)
+	a.Equal("Homer", user.NickName)
+	a.Equal("安徽省 安庆", user.Location)
+	a.Equal("refresh", user.RefreshToken)
+
+	session, _ = provider.BeginAuth("state")
+	_, err = session.Authorize(provider, url.Values{"auth_code": {"bad"}})
+	a.Error(err)
+	a.Contains(err.Error(), "isv.code-invalid")
+
+	provider.AlipayPublicKey = &appKey.PublicKey
+	session, _ = provider.BeginAuth("state")
+	_, err = session.Authorize(provider, url.Values{"auth_code": {"good"}})
+	a.EqualError(err, "alipay: response signature does not match")
+}
+
+func Test_ParseKeys(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	der := x509.MarshalPKCS1PrivateKey(appKey)
+	k, err := alipay.ParsePrivateKey(base64.StdEncoding.EncodeToString(der))
+	a.NoError(err)
+	a.True(k.Equal(appKey))
+
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(appKey)
+	k, err = alipay.ParsePrivateKey(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})))
+	a.NoError(err)
+	a.True(k.Equal(appKey))
+
+	pub, _ := x509.MarshalPKIXPublicKey(&alipayKey.PublicKey)
+	pk, err := alipay.ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	a.NoError(err)
+	a.True(pk.Equal(&alipayKey.PublicKey))
+
+	_, err = alipay.ParsePrivateKey("not a key")
+	a.Error(err)
+}
+
+// signContent signs a gateway response the way Alipay does.
+func signContent(content string) string {
+	hashed := sha256.Sum256([]byte(content))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, alipayKey, crypto.SHA256, hashed[:])
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func alipayProvider() *alipay.Provider {
+	return alipay.New("2021000000000000", appKey, "/foo")
+}
@@ -0,0 +1,65 @@
+package alipay
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with Alipay.
+type Session struct {
+	AuthURL      string
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+	UserID       string
+}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Alipay provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with Alipay and return the access token to be stored for future use.
+// Alipay sends the authorization code back in the auth_code parameter.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	code := params.Get("auth_code")
+	if code == "" {
+		code = params.Get("code")
+	}
+
+	token, userID, err := p.fetchToken(url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+	s.UserID = userID
+	return s.AccessToken, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	sess := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(sess)
+	return sess, err
+}
@@ -28,6 +28,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -50,6 +51,23 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return p.providerName
@@ -65,8 +83,13 @@ func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Amazon for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -93,7 +116,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -15,6 +15,7 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
+	CodeVerifier string `json:",omitempty"`
 }
 
 var _ goth.Session = &Session{}
@@ -30,7 +31,7 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Amazon and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -42,6 +43,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
@@ -0,0 +1,167 @@
+// Package amazonseller implements the Selling Partner API authorization of
+// Login with Amazon (LWA), for authorizing applications on behalf of sellers.
+// The seller consents in Seller Central, which redirects to the CallbackURL
+// with the selling_partner_id of the seller and an spapi_oauth_code exchanged
+// for LWA tokens. Sellers have no profile: the user is the selling partner id,
+// also found, with the mws_auth_token of hybrid applications, in RawData.
+// Reference: https://developer-docs.amazon.com/sp-api/docs/website-authorization-workflow
+package amazonseller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// SellerCentralURL is the Seller Central of the marketplace of the
+	// sellers, such as https://sellercentral-europe.amazon.com.
+	SellerCentralURL = "https://sellercentral.amazon.com"
+	TokenURL         = "https://api.amazon.com/auth/o2/token"
+)
+
+// New creates a new Amazon Selling Partner provider, and sets up important connection details.
+// You should always call `amazonseller.New` to get a new Provider. Never try to create
+// one manually. applicationID is the id of the application in Seller Central,
+// clientKey and secret its LWA credentials.
+func New(applicationID, clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		ApplicationID: applicationID,
+		ClientKey:     clientKey,
+		Secret:        secret,
+		CallbackURL:   callbackURL,
+		providerName:  "amazonseller",
+		authURL:       SellerCentralURL + "/apps/authorize/consent",
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for authorizing
+// applications with Amazon sellers.
+type Provider struct {
+	ApplicationID string
+	ClientKey     string
+	Secret        string
+	CallbackURL   string
+	HTTPClient    *http.Client
+
+	// Draft is to be set while the application is not published, Amazon
+	// only lets its own sellers authorize it then.
+	Draft bool
+
+	config       *oauth2.Config
+	providerName string
+	authURL      string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	if options.PKCE {
+		return nil, &goth.OptionNotSupportedError{Provider: p.Name(), Option: "WithPKCE"}
+	}
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the amazonseller package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth returns the consent page of Seller Central for the application.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	params := url.Values{
+		"application_id": {p.ApplicationID},
+		"state":          {state},
+		"redirect_uri":   {p.CallbackURL},
+	}
+	if p.Draft {
+		params.Set("version", "beta")
+	}
+	return &Session{
+		AuthURL: p.authURL + "?" + params.Encode(),
+	}, nil
+}
+
+// FetchUser returns the seller the session was authorized by. No call is
+// made, Amazon has no profile of sellers.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.SellingPartnerID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	user.RawData = map[string]interface{}{
+		"selling_partner_id": sess.SellingPartnerID,
+	}
+	if sess.MWSAuthToken != "" {
+		user.RawData["mws_auth_token"] = sess.MWSAuthToken
+	}
+	return user, nil
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.authURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
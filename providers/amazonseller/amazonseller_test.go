@@ -0,0 +1,91 @@
+package amazonseller_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/amazonseller"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), amazonsellerProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := amazonsellerProvider()
+	a.Equal(provider.ApplicationID, "amzn1.sp.solution.app")
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := amazonsellerProvider()
+	session, err := provider.BeginAuth("test_state")
+	s := session.(*amazonseller.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://sellercentral.amazon.com/apps/authorize/consent?")
+	a.Contains(s.AuthURL, "application_id=amzn1.sp.solution.app")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.NotContains(s.AuthURL, "version=beta")
+
+	provider.Draft = true
+	session, err = provider.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*amazonseller.Session).AuthURL, "version=beta")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		a.NoError(r.ParseForm())
+		a.Equal("key", r.PostForm.Get("client_id"))
+		a.Equal("secret", r.PostForm.Get("client_secret"))
+		a.Equal("RHxWxYVjMhLDyPtpbfhj", r.PostForm.Get("code"))
+		fmt.Fprint(w, `{"access_token":"Atza|token","refresh_token":"Atzr|refresh","token_type":"bearer","expires_in":3600}`)
+	}))
+	defer ts.Close()
+
+	amazonseller.TokenURL = ts.URL
+	defer func() { amazonseller.TokenURL = "https://api.amazon.com/auth/o2/token" }()
+	provider := amazonsellerProvider()
+
+	session, _ := provider.BeginAuth("state")
+	_, err := session.Authorize(provider, url.Values{"spapi_oauth_code": {"RHxWxYVjMhLDyPtpbfhj"}})
+	a.Error(err)
+
+	token, err := session.Authorize(provider, url.Values{
+		"state":              {"state"},
+		"selling_partner_id": {"A3FHI3ZWB9GZWL"},
+		"spapi_oauth_code":   {"RHxWxYVjMhLDyPtpbfhj"},
+	})
+	a.NoError(err)
+	a.Equal("Atza|token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("A3FHI3ZWB9GZWL", user.UserID)
+	a.Equal("A3FHI3ZWB9GZWL", user.RawData["selling_partner_id"])
+	a.NotContains(user.RawData, "mws_auth_token")
+	a.Equal("Atzr|refresh", user.RefreshToken)
+}
+
+func amazonsellerProvider() *amazonseller.Provider {
+	return amazonseller.New("amzn1.sp.solution.app", "key", "secret", "/foo")
+}
@@ -0,0 +1,72 @@
+package amazonseller
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with Amazon.
+type Session struct {
+	AuthURL          string
+	AccessToken      string
+	RefreshToken     string
+	ExpiresAt        time.Time
+	SellingPartnerID string
+	MWSAuthToken     string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Amazon Selling Partner provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with Amazon and return the access token to be stored for future use.
+// The code is the spapi_oauth_code parameter.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	if params.Get("selling_partner_id") == "" {
+		return "", errors.New("no selling_partner_id received from provider")
+	}
+
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("spapi_oauth_code"))
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid() {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+	s.SellingPartnerID = params.Get("selling_partner_id")
+	s.MWSAuthToken = params.Get("mws_auth_token")
+	return token.AccessToken, err
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
@@ -0,0 +1,223 @@
+// Package anonymous implements guest logins, for applications letting users
+// start without an account. There is no identity provider: the guest is a
+// random ID kept in a signed cookie of the device, so the same browser is the
+// same guest until the cookie is cleared or expires. The guest can later log
+// in with a real provider and the application move their data over.
+//
+// BeginAuth sends the user to LoginURL, served by the provider itself as an
+// http.Handler, which reads the device cookie, setting a new one for new
+// guests, and sends the user on to the callback with the guest ID signed
+// along with the state of the login:
+//
+//	provider := anonymous.New(secret, "https://app.example.com/auth/anonymous/device", callbackURL)
+//	e.GET("/auth/anonymous/device", echo.WrapHandler(provider))
+//
+// Requests of guests who do not go through the login are checked with
+// UserFromRequest.
+package anonymous
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoGuest is returned by UserFromRequest when the request has no valid
+// device cookie.
+var ErrNoGuest = errors.New("anonymous: request has no guest cookie")
+
+// New creates a new anonymous provider. secret signs the device cookies and
+// the guest IDs sent to the callback; changing it turns every device into a
+// new guest. loginURL is where the provider is served, and callbackURL the
+// callback of the login.
+func New(secret, loginURL, callbackURL string) *Provider {
+	return &Provider{
+		Secret:       secret,
+		LoginURL:     loginURL,
+		CallbackURL:  callbackURL,
+		CookieName:   "goth_guest",
+		CookieMaxAge: 365 * 24 * time.Hour,
+		providerName: "anonymous",
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for guest logins.
+type Provider struct {
+	Secret      string
+	LoginURL    string
+	CallbackURL string
+
+	// CookieName is the name of the device cookie.
+	CookieName string
+	// CookieMaxAge is how long the device cookie lasts. It is extended on
+	// every login.
+	CookieMaxAge time.Duration
+	// Secure sends the device cookie on HTTPS requests only.
+	Secure bool
+
+	providerName string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Debug is a no-op for the anonymous package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth sends the user to LoginURL with state.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	u, err := url.Parse(p.LoginURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("state", state)
+	u.RawQuery = q.Encode()
+	return &Session{
+		AuthURL: u.String(),
+		State:   state,
+	}, nil
+}
+
+// ServeHTTP serves LoginURL: it sends the user to the callback with the ID of
+// the guest of the device, a new guest when the request has no valid device
+// cookie, signed along with the state of the login.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		http.Error(w, "the login has no state", http.StatusBadRequest)
+		return
+	}
+	guestID, err := p.guestID(r)
+	if err != nil {
+		if guestID, err = newGuestID(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.CookieName,
+		Value:    guestID + "." + p.sign(guestID),
+		Path:     "/",
+		Expires:  goth.Now().Add(p.CookieMaxAge),
+		MaxAge:   int(p.CookieMaxAge.Seconds()),
+		Secure:   p.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	u, err := url.Parse(p.CallbackURL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q := u.Query()
+	q.Set("state", state)
+	q.Set("guest", guestID+"."+p.sign(guestID+"."+state))
+	u.RawQuery = q.Encode()
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+// FetchUser returns the guest the login was completed for.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		Provider: p.Name(),
+	}
+
+	if sess.GuestID == "" {
+		// data is not yet retrieved since the device has not been checked yet
+		return user, fmt.Errorf("%s cannot get user information without a guest ID", p.providerName)
+	}
+	return p.user(sess.GuestID), nil
+}
+
+// UserFromRequest returns the guest of the device cookie of r. It returns
+// ErrNoGuest when the request has no valid device cookie.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	guestID, err := p.guestID(r)
+	if err != nil {
+		return goth.User{Provider: p.Name()}, err
+	}
+	return p.user(guestID), nil
+}
+
+func (p *Provider) user(guestID string) goth.User {
+	return goth.User{
+		Provider: p.Name(),
+		UserID:   guestID,
+		NickName: "Guest",
+		RawData:  map[string]interface{}{"guest": true},
+	}
+}
+
+// guestID returns the guest ID of the device cookie of r.
+func (p *Provider) guestID(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(p.CookieName)
+	if err != nil {
+		return "", ErrNoGuest
+	}
+	guestID, ok := p.verify(cookie.Value, "")
+	if !ok {
+		return "", ErrNoGuest
+	}
+	return guestID, nil
+}
+
+// sign returns the signature of value by the secret of the provider.
+func (p *Provider) sign(value string) string {
+	mac := hmac.New(sha256.New, []byte(p.Secret))
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks signed is a guest ID signed along with state, when not empty,
+// and returns the guest ID.
+func (p *Provider) verify(signed, state string) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i <= 0 || p.Secret == "" {
+		return "", false
+	}
+	guestID, sig := signed[:i], signed[i+1:]
+	value := guestID
+	if state != "" {
+		value += "." + state
+	}
+	return guestID, hmac.Equal([]byte(sig), []byte(p.sign(value)))
+}
+
+func newGuestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by anonymous
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by anonymous")
+}
@@ -0,0 +1,131 @@
+package anonymous_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/anonymous"
+	"github.com/stretchr/testify/assert"
+)
+
+func provider() *anonymous.Provider {
+	return anonymous.New("secret", "https://app.example.com/auth/anonymous/device", "https://app.example.com/auth/anonymous/callback")
+}
+
+// login runs a login of the device with cookies, and returns its guest and
+// the cookies set.
+func login(t *testing.T, p *anonymous.Provider, cookies []*http.Cookie) (goth.User, []*http.Cookie) {
+	a := assert.New(t)
+
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	authURL, _ := session.GetAuthURL()
+
+	req := httptest.NewRequest(http.MethodGet, authURL, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	res := httptest.NewRecorder()
+	p.ServeHTTP(res, req)
+	a.Equal(http.StatusFound, res.Code)
+
+	callback, err := url.Parse(res.Header().Get("Location"))
+	a.NoError(err)
+	a.Equal("/auth/anonymous/callback", callback.Path)
+	a.Equal("test_state", callback.Query().Get("state"))
+
+	_, err = p.FetchUser(session)
+	a.Error(err)
+	_, err = session.Authorize(p, callback.Query())
+	a.NoError(err)
+	user, err := p.FetchUser(session)
+	a.NoError(err)
+	return user, res.Result().Cookies()
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	a.Equal("anonymous", p.Name())
+	a.Equal("goth_guest", p.CookieName)
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := provider().BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*anonymous.Session)
+	a.Equal("test_state", s.State)
+	a.Equal("https://app.example.com/auth/anonymous/device?state=test_state", s.AuthURL)
+}
+
+func Test_Login(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	user, cookies := login(t, p, nil)
+	a.Equal("anonymous", user.Provider)
+	a.NotEmpty(user.UserID)
+	a.Equal(true, user.RawData["guest"])
+	a.Len(cookies, 1)
+	a.True(cookies[0].HttpOnly)
+
+	// The same device is the same guest.
+	again, _ := login(t, p, cookies)
+	a.Equal(user.UserID, again.UserID)
+
+	other, _ := login(t, p, nil)
+	a.NotEqual(user.UserID, other.UserID)
+
+	// A cookie signed with another secret is a new guest.
+	forged, _ := login(t, anonymous.New("other", p.LoginURL, p.CallbackURL), cookies)
+	a.NotEqual(user.UserID, forged.UserID)
+}
+
+func Test_AuthorizeRejectsForgedGuest(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	_, cookies := login(t, p, nil)
+	session, _ := p.BeginAuth("test_state")
+
+	_, err := session.Authorize(p, url.Values{"guest": {cookies[0].Value}})
+	a.Error(err)
+	_, err = session.Authorize(p, url.Values{"guest": {"id.signature"}})
+	a.Error(err)
+	_, err = session.Authorize(p, url.Values{})
+	a.Error(err)
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	user, cookies := login(t, p, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/cart", nil)
+	req.AddCookie(cookies[0])
+	guest, err := p.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal(user.UserID, guest.UserID)
+
+	_, err = p.UserFromRequest(httptest.NewRequest(http.MethodGet, "/cart", nil))
+	a.Equal(anonymous.ErrNoGuest, err)
+}
@@ -0,0 +1,61 @@
+package anonymous
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process of a guest.
+type Session struct {
+	AuthURL string
+	State   string
+	GuestID string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the anonymous provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize checks the guest ID sent to the callback was signed along with
+// the state of the session, and returns it.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	signed := params.Get("guest")
+	if signed == "" {
+		return "", errors.New("anonymous: the callback has no guest ID")
+	}
+	guestID, ok := p.verify(signed, s.State)
+	if s.State == "" || !ok {
+		return "", fmt.Errorf("%s: the guest ID is not signed for the state of the session", p.providerName)
+	}
+
+	s.GuestID = guestID
+	return s.GuestID, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
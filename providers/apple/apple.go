@@ -3,13 +3,13 @@
 package apple
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -37,6 +37,7 @@ type Provider struct {
 	httpClient           *http.Client
 	formPostResponseMode bool
 	timeNowFn            func() time.Time
+	PKCE                 bool
 }
 
 func New(clientId, secret, redirectURL string, httpClient *http.Client, scopes ...string) *Provider {
@@ -89,6 +90,43 @@ func MakeSecret(sp SecretParams) (*string, error) {
 	return &ss, err
 }
 
+// MaxSecretLifetime is the longest Apple accepts client secrets for.
+const MaxSecretLifetime = 15777000 * time.Second
+
+// KeySecret returns the source of client secrets signed with the .p8 private
+// key supplied by key, for goth.RotateProvider. Every call makes a new secret,
+// valid for lifetime, capped at MaxSecretLifetime, so rotating the provider
+// more often than lifetime keeps its secret valid and picks up new keys:
+//
+//	source := apple.KeySecret(goth.FileSecret("/run/secrets/AuthKey.p8"), teamID, keyID, clientID, 24*time.Hour)
+//	rotation, err := goth.RotateProvider(source, time.Hour, func(secret string) (goth.Provider, error) {
+//		return apple.New(clientID, secret, callbackURL, nil, apple.ScopeName, apple.ScopeEmail), nil
+//	})
+func KeySecret(key goth.SecretSource, teamID, keyID, clientID string, lifetime time.Duration) goth.SecretSource {
+	if lifetime <= 0 || lifetime > MaxSecretLifetime {
+		lifetime = MaxSecretLifetime
+	}
+	return goth.SecretSourceFunc(func(ctx context.Context) (string, error) {
+		pk, err := key.Secret(ctx)
+		if err != nil {
+			return "", err
+		}
+		iat := goth.Now()
+		ss, err := MakeSecret(SecretParams{
+			PKCS8PrivateKey: pk,
+			TeamId:          teamID,
+			KeyId:           keyID,
+			ClientId:        clientID,
+			Iat:             int(iat.Unix()),
+			Exp:             int(iat.Add(lifetime).Unix()),
+		})
+		if err != nil {
+			return "", fmt.Errorf("apple: %w", err)
+		}
+		return *ss, nil
+	})
+}
+
 func (p Provider) Secret() string {
 	return p.secret
 }
@@ -98,20 +136,18 @@ func (p Provider) RedirectURL() string {
 }
 
 func (p Provider) BeginAuth(state string) (goth.Session, error) {
-	opts := make([]oauth2.AuthCodeOption, 0, 1)
+	verifier, opts, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
 	if p.formPostResponseMode {
 		opts = append(opts, oauth2.SetAuthURLParam("response_mode", "form_post"))
 	}
-	authURL := p.config.AuthCodeURL(state, opts...)
-	if authURL != "" {
-		if u, err := url.Parse(authURL); err == nil {
-			// Apple requires spaces to be encoded as %20 instead of +
-			u.RawQuery = strings.ReplaceAll(u.RawQuery, "+", "%20")
-			authURL = u.String()
-		}
-	}
+	// Apple requires spaces to be encoded as %20 instead of +
+	authURL := goth.ScopeFormatOf(p.Name(), goth.PercentEncodedScopes).AuthCodeURL(p.config, state, opts...)
 	return &Session{
-		AuthURL: authURL,
+		AuthURL:      authURL,
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -134,12 +170,13 @@ func (p Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return goth.User{}, fmt.Errorf("no access token obtained for session with provider %s", p.Name())
 	}
 	return goth.User{
-		Provider:     p.Name(),
-		UserID:       s.ID.Sub,
-		Email:        s.ID.Email,
-		AccessToken:  s.AccessToken,
-		RefreshToken: s.RefreshToken,
-		ExpiresAt:    s.ExpiresAt,
+		Provider:      p.Name(),
+		UserID:        s.ID.Sub,
+		Email:         s.ID.Email,
+		EmailVerified: s.ID.EmailVerified,
+		AccessToken:   s.AccessToken,
+		RefreshToken:  s.RefreshToken,
+		ExpiresAt:     s.ExpiresAt,
 	}, nil
 }
 
@@ -150,6 +187,22 @@ func (p Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.httpClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	p.httpClient = bind(p.Client())
+	return &p
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 func (p Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	token := &oauth2.Token{RefreshToken: refreshToken}
 	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
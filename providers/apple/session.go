@@ -1,18 +1,14 @@
 package apple
 
 import (
-	"context"
-	"crypto/rsa"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/lestrrat-go/jwx/jwk"
 	"golang.org/x/oauth2"
 )
 
@@ -20,10 +16,20 @@ const (
 	idTokenVerificationKeyEndpoint = "https://appleid.apple.com/auth/keys"
 )
 
+// verifier validates the identity tokens Apple issues to the client.
+func (p *Provider) verifier() *idtoken.Verifier {
+	return &idtoken.Verifier{
+		KeysURL:  idTokenVerificationKeyEndpoint,
+		ClientID: p.clientId,
+		Issuers:  []string{AppleAudOrIss},
+	}
+}
+
 type ID struct {
 	Sub            string `json:"sub"`
 	Email          string `json:"email"`
 	IsPrivateEmail bool   `json:"is_private_email"`
+	EmailVerified  bool   `json:"email_verified,omitempty"`
 }
 
 type Session struct {
@@ -32,6 +38,7 @@ type Session struct {
 	RefreshToken string
 	ExpiresAt    time.Time
 	ID
+	CodeVerifier string `json:",omitempty"`
 }
 
 func (s Session) GetAuthURL() (string, error) {
@@ -61,7 +68,8 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 		oauth2.SetAuthURLParam("client_id", p.clientId),
 		oauth2.SetAuthURLParam("client_secret", p.secret),
 	}
-	token, err := p.config.Exchange(context.Background(), params.Get("code"), opts...)
+	opts = append(opts, goth.VerifierOptions(s.CodeVerifier)...)
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), opts...)
 	if err != nil {
 		return "", err
 	}
@@ -74,59 +82,27 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
 
-	if idToken := token.Extra("id_token"); idToken != nil {
-		idToken, err := jwt.ParseWithClaims(idToken.(string), &IDTokenClaims{}, func(t *jwt.Token) (interface{}, error) {
-			kid := t.Header["kid"].(string)
-			claims := t.Claims.(*IDTokenClaims)
-			vErr := new(jwt.ValidationError)
-			if !claims.VerifyAudience(p.clientId, true) {
-				vErr.Inner = fmt.Errorf("audience is incorrect")
-				vErr.Errors |= jwt.ValidationErrorAudience
-			}
-			if !claims.VerifyIssuer(AppleAudOrIss, true) {
-				vErr.Inner = fmt.Errorf("issuer is incorrect")
-				vErr.Errors |= jwt.ValidationErrorIssuer
-			}
-			if vErr.Errors > 0 {
-				return nil, vErr
-			}
-
-			// per OpenID Connect Core 1.0 §3.2.2.9, Access Token Validation
-			hash := sha256.Sum256([]byte(s.AccessToken))
-			halfHash := hash[0:(len(hash) / 2)]
-			encodedHalfHash := base64.RawURLEncoding.EncodeToString(halfHash)
-			if encodedHalfHash != claims.AccessTokenHash {
-				vErr.Inner = fmt.Errorf(`identity token invalid`)
-				vErr.Errors |= jwt.ValidationErrorClaimsInvalid
-				return nil, vErr
-			}
-
-			// get the public key for verifying the identity token signature
-			set, err := jwk.Fetch(context.Background(), idTokenVerificationKeyEndpoint, jwk.WithHTTPClient(p.Client()))
-			if err != nil {
-				return nil, err
-			}
-			selectedKey, found := set.LookupKeyID(kid)
-			if !found {
-				return nil, errors.New("could not find matching public key")
-			}
-			pubKey := &rsa.PublicKey{}
-			err = selectedKey.Raw(pubKey)
-			if err != nil {
-				return nil, err
-			}
-			return pubKey, nil
-		})
+	if idToken, ok := token.Extra("id_token").(string); ok {
+		claims, err := p.verifier().Verify(p.Client(), idToken, "")
 		if err != nil {
-			return "", err
+			return "", fmt.Errorf("%s: %w", p.providerName, err)
+		}
+		if err := claims.VerifyAccessToken(s.AccessToken); err != nil {
+			return "", fmt.Errorf("%s: %w", p.providerName, err)
 		}
-		s.ID = ID{
-			Sub:            idToken.Claims.(*IDTokenClaims).Subject,
-			Email:          idToken.Claims.(*IDTokenClaims).Email,
-			IsPrivateEmail: idToken.Claims.(*IDTokenClaims).IsPrivateEmail,
+		s.ID = ID{Sub: claims.Subject}
+		s.Email, _ = claims.Raw["email"].(string)
+		s.EmailVerified = goth.BoolClaim(claims.Raw, "email_verified")
+		// Apple sends is_private_email as a string or as a boolean.
+		switch private := claims.Raw["is_private_email"].(type) {
+		case bool:
+			s.IsPrivateEmail = private
+		case string:
+			s.IsPrivateEmail = private == "true"
 		}
 	}
 
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
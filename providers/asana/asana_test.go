@@ -0,0 +1,85 @@
+package asana_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/asana"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), asanaProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := asanaProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := asana.New("key", "secret", "/foo", asana.ScopeOpenID, asana.ScopeEmail).BeginAuth("test_state")
+	s := session.(*asana.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://app.asana.com/-/oauth_authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=openid+email")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":3600,"data":{"id":4673218951,"gid":"4673218951","name":"Greg Sanchez","email":"gsanchez@example.com"}}`)
+		case "/api/1.0/users/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"data":{"gid":"4673218951","resource_type":"user","name":"Greg Sanchez","email":"gsanchez@example.com","photo":{"image_128x128":"https://example.com/128.png"},"workspaces":[{"gid":"12345","name":"My Company Workspace"}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	asana.TokenURL, asana.ProfileURL = ts.URL+"/token", ts.URL+"/api/1.0/users/me"
+	defer func() {
+		asana.TokenURL, asana.ProfileURL = "https://app.asana.com/-/oauth_token", "https://app.asana.com/api/1.0/users/me"
+	}()
+	provider := asanaProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("4673218951", user.UserID)
+	a.Equal("Greg Sanchez", user.Name)
+	a.Equal("gsanchez@example.com", user.Email)
+	a.Equal("https://example.com/128.png", user.AvatarURL)
+	a.Contains(user.RawData, "workspaces")
+}
+
+func asanaProvider() *asana.Provider {
+	return asana.New("key", "secret", "/foo")
+}
@@ -17,6 +17,7 @@ import (
 const (
 	authEndpoint    string = "/authorize"
 	tokenEndpoint   string = "/oauth/token"
+	revokeEndpoint  string = "/oauth/revoke"
 	endpointProfile string = "/userinfo"
 	protocol        string = "https://"
 )
@@ -28,6 +29,7 @@ type Provider struct {
 	CallbackURL  string
 	Domain       string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -69,13 +71,35 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the auth0 package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Auth0 for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -113,7 +137,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
 	}
 
 	err = userFromReader(resp.Body, &user)
@@ -159,6 +183,7 @@ func userFromReader(r io.Reader, user *goth.User) error {
 		return err
 	}
 	user.Email = u.Email
+	user.EmailVerified = goth.BoolClaim(rawData, "email_verified")
 	user.Name = u.Name
 	user.NickName = u.NickName
 	user.UserID = u.UserID
@@ -175,10 +200,23 @@ func (p *Provider) RefreshTokenAvailable() bool {
 //RefreshToken get new access token based on the refresh token
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	token := &oauth2.Token{RefreshToken: refreshToken}
-	ts := p.config.TokenSource(oauth2.NoContext, token)
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
 	newToken, err := ts.Token()
 	if err != nil {
 		return nil, err
 	}
 	return newToken, err
 }
+
+// RevokeToken revokes a refresh token, and the access tokens issued with it.
+// Auth0 does not revoke access tokens, which stay valid until they expire.
+func (p *Provider) RevokeToken(token string) error {
+	return goth.Revoke(p.Client(), goth.RevocationRequest{
+		RevocationURL: protocol + p.Domain + revokeEndpoint,
+		ClientID:      p.ClientKey,
+		ClientSecret:  p.Secret,
+		AuthStyle:     oauth2.AuthStyleInParams,
+		Token:         token,
+		TokenTypeHint: goth.TokenTypeHintRefreshToken,
+	})
+}
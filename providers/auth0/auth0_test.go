@@ -89,6 +89,7 @@ func Test_FetchUser(t *testing.T) {
 	u, err := p.FetchUser(s)
 	a.Nil(err)
 	a.Equal(u.Email, "test.account@userinfo.com")
+	a.False(u.EmailVerified)
 	a.Equal(u.UserID, "auth0|58454...")
 	a.Equal(u.NickName, "test.account")
 	a.Equal(u.Name, "test.account@userinfo.com")
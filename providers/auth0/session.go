@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/bgdsh/goth"
-	"golang.org/x/oauth2"
 )
 
 // Session stores data during the auth process with Auth0.
@@ -16,6 +15,7 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
+	CodeVerifier string `json:",omitempty"`
 }
 
 var _ goth.Session = &Session{}
@@ -31,7 +31,7 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Auth0 and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(oauth2.NoContext, params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -43,6 +43,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
 	"golang.org/x/oauth2"
 )
 
@@ -20,6 +21,8 @@ const (
 	tokenURL         string = "https://login.microsoftonline.com/common/oauth2/token"
 	endpointProfile  string = "https://graph.windows.net/me?api-version=1.6"
 	graphAPIResource string = "https://graph.windows.net/"
+	keysURL          string = "https://login.microsoftonline.com/common/discovery/keys"
+	issuerPrefix     string = "https://sts.windows.net/"
 )
 
 // New creates a new AzureAD provider, and sets up important connection details.
@@ -47,6 +50,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 	resources    []string
@@ -67,18 +71,40 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks AzureAD for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	authURL := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	authURL := p.config.AuthCodeURL(state, pkce...)
 
 	// Azure ad requires at least one resource
 	authURL += "&resource=" + url.QueryEscape(strings.Join(p.resources, " "))
 
 	return &Session{
-		AuthURL: authURL,
+		AuthURL:      authURL,
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -89,12 +115,25 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		AccessToken: msSession.AccessToken,
 		Provider:    p.Name(),
 		ExpiresAt:   msSession.ExpiresAt,
+		IDToken:     msSession.IDToken,
 	}
 
 	if user.AccessToken == "" {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
+	// The ID token only comes with the openid scope.
+	emailVerified := false
+	if msSession.IDToken != "" {
+		claims, err := p.verifier().Verify(p.Client(), msSession.IDToken, "")
+		if err != nil {
+			return user, fmt.Errorf("%s: %w", p.providerName, err)
+		}
+		// Azure AD does not verify the mail of its users, but tells whether
+		// the owner of its domain was with the xms_edov optional claim.
+		emailVerified = goth.BoolClaim(claims.Raw, "xms_edov")
+	}
+
 	req, err := http.NewRequest("GET", endpointProfile, nil)
 	if err != nil {
 		return user, err
@@ -109,13 +148,26 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	err = userFromReader(response.Body, &user)
+	user.EmailVerified = emailVerified && user.Email != ""
 	return user, err
 }
 
+// verifier validates the ID tokens issued to the client. The issuer names the
+// tenant of the user, which is any tenant with the common end-points.
+func (p *Provider) verifier() *idtoken.Verifier {
+	return &idtoken.Verifier{
+		KeysURL:  keysURL,
+		ClientID: p.ClientKey,
+		ValidIssuer: func(issuer string) bool {
+			return strings.HasPrefix(issuer, issuerPrefix)
+		},
+	}
+}
+
 //RefreshTokenAvailable refresh token is provided by auth provider or not
 func (p *Provider) RefreshTokenAvailable() bool {
 	return true
@@ -0,0 +1,101 @@
+package azureadv2
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Scope presets for admin consoles, they include UserReadScope to sign the
+// user in. All of them require admin consent.
+var (
+	// DirectoryReadScopes read the users, groups and other objects of the
+	// directory of the organization.
+	DirectoryReadScopes = []ScopeType{UserReadScope, UserReadAllScope, GroupReadAllScope, DirectoryReadAllScope}
+	// DirectoryReadWriteScopes manage the users, groups and other objects of
+	// the directory of the organization.
+	DirectoryReadWriteScopes = []ScopeType{UserReadScope, UserReadWriteAllScope, GroupReadWriteAllScope, DirectoryReadWriteAllScope}
+)
+
+// Error codes of Microsoft identity platform asking for consent.
+// See https://learn.microsoft.com/en-us/entra/identity-platform/reference-error-codes
+var (
+	consentCodes      = []string{"AADSTS65001"}
+	adminConsentCodes = []string{"AADSTS90094", "AADSTS90095", "AADSTS90008"}
+)
+
+// AdminConsentURL returns the end-point an administrator grants the
+// permissions configured for the application to the whole organization at.
+// Microsoft redirects to the CallbackURL with admin_consent=True and state.
+// See https://learn.microsoft.com/en-us/entra/identity-platform/v2-admin-consent
+func (p *Provider) AdminConsentURL(state string) string {
+	endpoint := strings.TrimSuffix(p.config.Endpoint.AuthURL, "/oauth2/v2.0/authorize") + "/v2.0/adminconsent"
+	return endpoint + "?" + url.Values{
+		"client_id":    {p.ClientKey},
+		"redirect_uri": {p.CallbackURL},
+		"scope":        {"https://graph.microsoft.com/.default"},
+		"state":        {state},
+	}.Encode()
+}
+
+// CheckScopes returns a *goth.ConsentRequiredError when scopes were not all
+// granted to the session. Sessions created before the granted scopes were
+// recorded pass.
+func (p *Provider) CheckScopes(session goth.Session, scopes ...ScopeType) error {
+	sess := session.(*Session)
+	if len(sess.Scopes) == 0 {
+		return nil
+	}
+	if missing := goth.MissingScopes(sess.Scopes, scopesToStrings(scopes...)...); len(missing) > 0 {
+		return &goth.ConsentRequiredError{Provider: p.providerName, Scopes: missing}
+	}
+	return nil
+}
+
+// callbackError returns the error Microsoft redirected to the callback with,
+// if any.
+func (p *Provider) callbackError(params goth.Params) error {
+	code := params.Get("error")
+	if code == "" {
+		return nil
+	}
+	description := params.Get("error_description")
+	if err := consentError(p.providerName, code, description); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s responded with %s: %s", p.providerName, code, description)
+}
+
+// exchangeError turns the refusals of the token end-point asking for consent
+// into a *goth.ConsentRequiredError.
+func (p *Provider) exchangeError(err error) error {
+	var re *oauth2.RetrieveError
+	if !errors.As(err, &re) {
+		return err
+	}
+	if cerr := consentError(p.providerName, "", string(re.Body)); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func consentError(providerName, code, description string) error {
+	for _, c := range adminConsentCodes {
+		if strings.Contains(description, c) {
+			return &goth.ConsentRequiredError{Provider: providerName, AdminConsent: true, Description: description}
+		}
+	}
+	if code == "consent_required" {
+		return &goth.ConsentRequiredError{Provider: providerName, Description: description}
+	}
+	for _, c := range consentCodes {
+		if strings.Contains(description, c) {
+			return &goth.ConsentRequiredError{Provider: providerName, Description: description}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package azureadv2_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/azureadv2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AdminConsentURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := azureadv2.New(applicationID, secret, redirectUri, azureadv2.ProviderOptions{
+		Scopes: azureadv2.DirectoryReadScopes,
+		Tenant: azureadv2.OrganizationsTenant,
+	})
+	u := provider.AdminConsentURL("state")
+	a.Contains(u, "https://login.microsoftonline.com/organizations/v2.0/adminconsent?")
+	a.Contains(u, "client_id="+applicationID)
+	a.Contains(u, "scope=https%3A%2F%2Fgraph.microsoft.com%2F.default")
+	a.Contains(u, "state=state")
+}
+
+func Test_CheckScopes(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := azureadProvider()
+	a.NoError(provider.CheckScopes(&azureadv2.Session{}, azureadv2.DirectoryReadAllScope))
+
+	session := &azureadv2.Session{Scopes: []string{"User.Read", "Directory.Read.All", "profile", "openid", "email"}}
+	a.NoError(provider.CheckScopes(session, azureadv2.UserReadScope, azureadv2.DirectoryReadAllScope))
+	err := provider.CheckScopes(session, azureadv2.DirectoryReadScopes...)
+	a.True(errors.Is(err, goth.ErrConsentRequired))
+	a.Equal([]string{"User.Read.All", "Group.Read.All"}, err.(*goth.ConsentRequiredError).Scopes)
+}
+
+func Test_Authorize_ConsentRequired(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := azureadProvider()
+	session, _ := provider.BeginAuth("state")
+
+	_, err := session.Authorize(provider, url.Values{
+		"error":             {"access_denied"},
+		"error_description": {"AADSTS90094: Admin consent is required for the permissions requested by this application."},
+	})
+	a.True(errors.Is(err, goth.ErrConsentRequired))
+	a.True(err.(*goth.ConsentRequiredError).AdminConsent)
+
+	_, err = session.Authorize(provider, url.Values{"error": {"consent_required"}})
+	a.True(errors.Is(err, goth.ErrConsentRequired))
+	a.False(err.(*goth.ConsentRequiredError).AdminConsent)
+
+	_, err = session.Authorize(provider, url.Values{"error": {"access_denied"}, "error_description": {"AADSTS65004: User declined to consent."}})
+	a.False(errors.Is(err, goth.ErrConsentRequired))
+	a.EqualError(err, "azureadv2 responded with access_denied: AADSTS65004: User declined to consent.")
+}
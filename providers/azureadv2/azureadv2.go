@@ -32,6 +32,7 @@ type (
 		Secret       string
 		CallbackURL  string
 		HTTPClient   *http.Client
+		PKCE         bool
 		config       *oauth2.Config
 		providerName string
 	}
@@ -115,15 +116,37 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the package
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks for an authentication end-point for AzureAD.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	authURL := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	authURL := p.config.AuthCodeURL(state, pkce...)
 
 	return &Session{
-		AuthURL: authURL,
+		AuthURL:      authURL,
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -154,7 +177,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	err = userFromReader(response.Body, &user)
@@ -180,6 +203,20 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	return newToken, err
 }
 
+// ClientCredentialsToken requests a token for the application itself with the
+// client credentials grant. Microsoft expects a single scope ending in
+// "/.default", such as "https://graph.microsoft.com/.default", granting the
+// application permissions an administrator consented to.
+func (p *Provider) ClientCredentialsToken(scopes ...string) (*oauth2.Token, error) {
+	return goth.RequestClientCredentials(p.Client(), goth.ClientCredentialsRequest{
+		TokenURL:     p.config.Endpoint.TokenURL,
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		AuthStyle:    oauth2.AuthStyleInParams,
+		Scopes:       scopes,
+	})
+}
+
 func authorizationHeader(session *Session) (string, string) {
 	return "Authorization", fmt.Sprintf("Bearer %s", session.AccessToken)
 }
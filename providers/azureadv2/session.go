@@ -15,6 +15,9 @@ type Session struct {
 	AccessToken  string    `json:"at"`
 	RefreshToken string    `json:"rt"`
 	ExpiresAt    time.Time `json:"exp"`
+	// Scopes are the scopes granted to the application.
+	Scopes       []string `json:"scp,omitempty"`
+	CodeVerifier string   `json:",omitempty"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` func
@@ -29,10 +32,13 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with AzureAD and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
-	if err != nil {
+	if err := p.callbackError(params); err != nil {
 		return "", err
 	}
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
+	if err != nil {
+		return "", p.exchangeError(err)
+	}
 
 	if !token.Valid() {
 		return "", errors.New("invalid token received from provider")
@@ -41,7 +47,11 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
+	if scope, ok := token.Extra("scope").(string); ok {
+		s.Scopes = strings.Fields(scope)
+	}
 
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
@@ -51,6 +61,18 @@ func (s Session) Marshal() string {
 	return string(b)
 }
 
+// MarshalRedacted marshals the session with its tokens replaced, for logging.
+// The short JSON keys used by this session are not recognised by goth.RedactJSON.
+func (s Session) MarshalRedacted() string {
+	if s.AccessToken != "" {
+		s.AccessToken = goth.Redacted
+	}
+	if s.RefreshToken != "" {
+		s.RefreshToken = goth.Redacted
+	}
+	return s.Marshal()
+}
+
 func (s Session) String() string {
 	return s.Marshal()
 }
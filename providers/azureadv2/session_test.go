@@ -46,3 +46,14 @@ func Test_String(t *testing.T) {
 
 	a.Equal(s.String(), s.Marshal())
 }
+
+func Test_MarshalRedacted(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &azureadv2.Session{AuthURL: "/foo", AccessToken: "access", RefreshToken: "refresh"}
+
+	data := goth.MarshalRedacted(s)
+	a.NotContains(data, "access")
+	a.NotContains(data, "refresh")
+	a.Contains(data, "/foo")
+}
@@ -0,0 +1,239 @@
+// Package basecamp implements the OAuth2 protocol for authenticating users through Basecamp,
+// whose users sign in with their 37signals Launchpad identity.
+// Reference: https://github.com/basecamp/api/blob/master/sections/authentication.md
+package basecamp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://launchpad.37signals.com/authorization/new"
+	TokenURL   = "https://launchpad.37signals.com/authorization/token"
+	ProfileURL = "https://launchpad.37signals.com/authorization.json"
+)
+
+// New creates a new Basecamp provider, and sets up important connection details.
+// You should always call `basecamp.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "basecamp",
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Basecamp.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Account is a Basecamp (or other 37signals product) account the user has
+// access to. API calls are made to its Href.
+type Account struct {
+	Product string `json:"product"`
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Href    string `json:"href"`
+	AppHref string `json:"app_href"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the basecamp package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Basecamp for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]oauth2.AuthCodeOption{oauth2.SetAuthURLParam("type", "web_server")}, pkce...)
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, opts...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Basecamp and access basic information about the user,
+// and the accounts the user has access to. Get these with Accounts.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+// Accounts returns the accounts of a user fetched by FetchUser. Launchpad
+// lists the accounts of every 37signals product, pass product (for example
+// "bc3" for Basecamp 3 and 4) to get those of a single product only.
+func Accounts(user goth.User, product string) ([]Account, error) {
+	raw, ok := user.RawData["accounts"]
+	if !ok {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var all []Account
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	if product == "" {
+		return all, nil
+	}
+
+	var accounts []Account
+	for _, a := range all {
+		if a.Product == product {
+			accounts = append(accounts, a)
+		}
+	}
+	return accounts, nil
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ExpiresAt time.Time `json:"expires_at"`
+		Identity  struct {
+			ID           int64  `json:"id"`
+			FirstName    string `json:"first_name"`
+			LastName     string `json:"last_name"`
+			EmailAddress string `json:"email_address"`
+		} `json:"identity"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = strconv.FormatInt(u.Identity.ID, 10)
+	user.FirstName = u.Identity.FirstName
+	user.LastName = u.Identity.LastName
+	user.Name = u.Identity.FirstName + " " + u.Identity.LastName
+	user.Email = u.Identity.EmailAddress
+	if user.ExpiresAt.IsZero() {
+		user.ExpiresAt = u.ExpiresAt
+	}
+	return nil
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. Launchpad
+// wants type=refresh on refresh requests, and keeps the refresh token the
+// same.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	c := *p.config
+	c.Endpoint.TokenURL += "?type=refresh"
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := c.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,101 @@
+package basecamp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/basecamp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), basecampProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := basecampProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := basecampProvider().BeginAuth("test_state")
+	s := session.(*basecamp.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://launchpad.37signals.com/authorization/new")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "type=web_server")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/authorization/token":
+			a.NoError(r.ParseForm())
+			if r.Form.Get("grant_type") == "refresh_token" {
+				a.Equal("refresh", r.URL.Query().Get("type"))
+			} else {
+				a.Equal("web_server", r.Form.Get("type"))
+			}
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","expires_in":1209600}`)
+		case "/authorization.json":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"expires_at":"2026-11-01T12:00:00Z","identity":{"id":9999999,"first_name":"Jason","last_name":"Fried","email_address":"jason@basecamp.com"},"accounts":[{"product":"bc3","id":99999999,"name":"Honcho Design","href":"https://3.basecampapi.com/99999999","app_href":"https://3.basecamp.com/99999999"},{"product":"hey","id":93825189,"name":"HEY","href":"https://app.hey.com/93825189","app_href":"https://app.hey.com/93825189"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	basecamp.TokenURL, basecamp.ProfileURL = ts.URL+"/authorization/token", ts.URL+"/authorization.json"
+	defer func() {
+		basecamp.TokenURL, basecamp.ProfileURL = "https://launchpad.37signals.com/authorization/token", "https://launchpad.37signals.com/authorization.json"
+	}()
+	provider := basecampProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("9999999", user.UserID)
+	a.Equal("Jason Fried", user.Name)
+	a.Equal("jason@basecamp.com", user.Email)
+
+	accounts, err := basecamp.Accounts(user, "bc3")
+	a.NoError(err)
+	a.Len(accounts, 1)
+	a.Equal(int64(99999999), accounts[0].ID)
+	a.Equal("https://3.basecampapi.com/99999999", accounts[0].Href)
+
+	accounts, _ = basecamp.Accounts(user, "")
+	a.Len(accounts, 2)
+
+	refreshed, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token", refreshed.AccessToken)
+}
+
+func basecampProvider() *basecamp.Provider {
+	return basecamp.New("key", "secret", "/foo")
+}
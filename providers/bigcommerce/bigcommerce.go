@@ -0,0 +1,212 @@
+// Package bigcommerce implements the OAuth2 protocol for installing applications on BigCommerce stores.
+// Installs start from BigCommerce, which calls the auth callback of the
+// application with the code, so there is no state: call Authorize and
+// FetchUser of a session of BeginAuth from it. BigCommerce then calls the
+// load, uninstall and remove user callbacks with a signed_payload_jwt, which
+// DecodeSignedPayload verifies.
+// Reference: https://developer.bigcommerce.com/docs/integrations/apps/guide/auth
+package bigcommerce
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// InstallURL is the install page of applications, %s is the id of the
+	// application.
+	InstallURL = "https://login.bigcommerce.com/app/%s/install"
+	TokenURL   = "https://login.bigcommerce.com/oauth2/token"
+)
+
+// Issuer is the issuer of the signed payloads of BigCommerce.
+const Issuer = "bc"
+
+// New creates a new BigCommerce provider, and sets up important connection details.
+// You should always call `bigcommerce.New` to get a new Provider. Never try to create
+// one manually. appID is the id of the application in the marketplace, its
+// scopes are set when registering it.
+func New(appID, clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		AppID:        appID,
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "bigcommerce",
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing BigCommerce.
+type Provider struct {
+	AppID        string
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// SignedPayload is the payload of the load, uninstall and remove user
+// callbacks.
+type SignedPayload struct {
+	jwt.RegisteredClaims
+	User struct {
+		ID     int64  `json:"id"`
+		Email  string `json:"email"`
+		Locale string `json:"locale"`
+	} `json:"user"`
+	Owner struct {
+		ID    int64  `json:"id"`
+		Email string `json:"email"`
+	} `json:"owner"`
+	URL       string `json:"url"`
+	ChannelID *int64 `json:"channel_id"`
+}
+
+// StoreHash returns the hash of the store the payload is about.
+func (s *SignedPayload) StoreHash() string {
+	return strings.TrimPrefix(s.Subject, "stores/")
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	if options.PKCE {
+		return nil, &goth.OptionNotSupportedError{Provider: p.Name(), Option: "WithPKCE"}
+	}
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the bigcommerce package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth returns the install page of the application. BigCommerce does not
+// support the "state" variable.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: fmt.Sprintf(InstallURL, p.AppID),
+	}, nil
+}
+
+// FetchUser returns the user who installed the application, as told by the
+// token response. No call is made, the store hash, the account and the owner
+// of the store are found in RawData.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		UserID:      sess.UserID,
+		Email:       sess.Email,
+		NickName:    sess.Username,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	user.RawData = map[string]interface{}{
+		"store_hash":   sess.StoreHash,
+		"account_uuid": sess.AccountUUID,
+		"owner_id":     sess.OwnerID,
+		"scope":        sess.Scope,
+	}
+	return user, nil
+}
+
+// DecodeSignedPayload verifies the signed_payload_jwt parameter of the load,
+// uninstall and remove user callbacks, and returns its payload.
+func (p *Provider) DecodeSignedPayload(signedPayloadJWT string) (*SignedPayload, error) {
+	payload := &SignedPayload{}
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		return []byte(p.Secret), nil
+	}
+	if _, err := idtoken.Validate(signedPayloadJWT, payload, keyfunc, []string{"HS256"}, Issuer, p.ClientKey); err != nil {
+		return nil, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	return payload, nil
+}
+
+// userFromToken reads the user and the store of the token response.
+func userFromToken(token *oauth2.Token, s *Session) error {
+	user, _ := token.Extra("user").(map[string]interface{})
+	id, _ := user["id"].(float64)
+	context, _ := token.Extra("context").(string)
+	if id == 0 || context == "" {
+		return errors.New("Invalid token received from provider")
+	}
+
+	s.UserID = strconv.FormatFloat(id, 'f', -1, 64)
+	s.Username, _ = user["username"].(string)
+	s.Email, _ = user["email"].(string)
+	if owner, ok := token.Extra("owner").(map[string]interface{}); ok {
+		if id, ok := owner["id"].(float64); ok {
+			s.OwnerID = strconv.FormatFloat(id, 'f', -1, 64)
+		}
+	}
+	s.StoreHash = strings.TrimPrefix(context, "stores/")
+	s.AccountUUID, _ = token.Extra("account_uuid").(string)
+	s.Scope, _ = token.Extra("scope").(string)
+	return nil
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token. BigCommerce
+// tokens do not expire.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by BigCommerce")
+}
@@ -0,0 +1,116 @@
+package bigcommerce_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/bigcommerce"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), bigcommerceProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := bigcommerceProvider()
+	a.Equal(provider.AppID, "12345")
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := bigcommerceProvider().BeginAuth("test_state")
+	a.NoError(err)
+	a.Equal("https://login.bigcommerce.com/app/12345/install", session.(*bigcommerce.Session).AuthURL)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		a.NoError(r.ParseForm())
+		a.Equal("key", r.PostForm.Get("client_id"))
+		a.Equal("secret", r.PostForm.Get("client_secret"))
+		a.Equal("stores/abc123", r.PostForm.Get("context"))
+		a.Equal("store_v2_orders", r.PostForm.Get("scope"))
+		a.Equal("/foo", r.PostForm.Get("redirect_uri"))
+		fmt.Fprint(w, `{"access_token":"token","scope":"store_v2_orders","user":{"id":24654,"username":"merchant","email":"merchant@example.com"},"owner":{"id":24653,"username":"owner","email":"owner@example.com"},"context":"stores/abc123","account_uuid":"1e4a2b60-5c2d-4b50-9a1c-7b8f5e6d9c0a"}`)
+	}))
+	defer ts.Close()
+
+	bigcommerce.TokenURL = ts.URL
+	defer func() { bigcommerce.TokenURL = "https://login.bigcommerce.com/oauth2/token" }()
+	provider := bigcommerceProvider()
+
+	session, _ := provider.BeginAuth("")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}, "scope": {"store_v2_orders"}, "context": {"stores/abc123"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("24654", user.UserID)
+	a.Equal("merchant", user.NickName)
+	a.Equal("merchant@example.com", user.Email)
+	a.Equal("abc123", user.RawData["store_hash"])
+	a.Equal("24653", user.RawData["owner_id"])
+}
+
+func Test_DecodeSignedPayload(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	sign := func(secret, audience string, expiresAt time.Time) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"aud":   audience,
+			"iss":   "bc",
+			"iat":   time.Now().Unix(),
+			"nbf":   time.Now().Unix() - 5,
+			"exp":   expiresAt.Unix(),
+			"jti":   "4a5b",
+			"sub":   "stores/abc123",
+			"user":  map[string]interface{}{"id": 24654, "email": "merchant@example.com", "locale": "en-US"},
+			"owner": map[string]interface{}{"id": 24653, "email": "owner@example.com"},
+			"url":   "/",
+		}).SignedString([]byte(secret))
+		a.NoError(err)
+		return s
+	}
+	provider := bigcommerceProvider()
+
+	payload, err := provider.DecodeSignedPayload(sign("secret", "key", time.Now().Add(time.Hour)))
+	a.NoError(err)
+	a.Equal("abc123", payload.StoreHash())
+	a.Equal(int64(24654), payload.User.ID)
+	a.Equal("owner@example.com", payload.Owner.Email)
+	a.Equal("/", payload.URL)
+
+	_, err = provider.DecodeSignedPayload(sign("other", "key", time.Now().Add(time.Hour)))
+	a.Error(err)
+	_, err = provider.DecodeSignedPayload(sign("secret", "other", time.Now().Add(time.Hour)))
+	a.EqualError(err, "bigcommerce: audience is incorrect")
+	_, err = provider.DecodeSignedPayload(sign("secret", "key", time.Now().Add(-time.Hour)))
+	a.EqualError(err, "bigcommerce: token is expired")
+}
+
+func bigcommerceProvider() *bigcommerce.Provider {
+	return bigcommerce.New("12345", "key", "secret", "/foo")
+}
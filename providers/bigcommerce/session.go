@@ -0,0 +1,73 @@
+package bigcommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Session stores data during the auth process with BigCommerce.
+type Session struct {
+	AuthURL     string
+	AccessToken string
+	Scope       string
+	StoreHash   string
+	AccountUUID string
+	UserID      string
+	Username    string
+	Email       string
+	OwnerID     string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the BigCommerce provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with BigCommerce and return the access token to be stored for future use.
+// params are the ones of the auth callback: code, scope and context.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"),
+		oauth2.SetAuthURLParam("scope", params.Get("scope")),
+		oauth2.SetAuthURLParam("context", params.Get("context")),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid() {
+		return "", errors.New("Invalid token received from provider")
+	}
+	if err := userFromToken(token, s); err != nil {
+		return "", err
+	}
+
+	s.AccessToken = token.AccessToken
+	return token.AccessToken, err
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
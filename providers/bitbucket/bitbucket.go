@@ -39,6 +39,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -57,14 +58,36 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the bitbucket package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Bitbucket for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state, pkce...)
 	session := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return session, nil
 }
@@ -108,7 +131,7 @@ func (p *Provider) getUserInfo(user *goth.User, sess *Session) error {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -159,7 +182,7 @@ func (p *Provider) getEmail(user *goth.User, sess *Session) error {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("%s responded with a %d trying to fetch email addresses", p.providerName, response.StatusCode)
+		return goth.NewProviderAPIError(p.providerName, "trying to fetch email addresses", response)
 	}
 
 	var mailList = []struct {
@@ -40,6 +40,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -61,14 +62,36 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the bitly package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Bitly for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state, pkce...)
 	session := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return session, nil
 }
@@ -93,7 +116,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 
 	resp, err := p.Client().Do(req)
 	if err != nil {
-		return u, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
+		return u, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
 	}
 	defer resp.Body.Close()
 
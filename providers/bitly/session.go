@@ -10,8 +10,9 @@ import (
 
 // Session stores data during the auth process with Bitly.
 type Session struct {
-	AuthURL     string
-	AccessToken string
+	AuthURL      string
+	AccessToken  string
+	CodeVerifier string `json:",omitempty"`
 }
 
 // Ensure `bitly.Session` implements `goth.Session`.
@@ -28,7 +29,7 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Bitly and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -38,6 +39,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	}
 
 	s.AccessToken = token.AccessToken
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
@@ -0,0 +1,396 @@
+// Package bluesky implements the AT Protocol OAuth profile for authenticating users through Bluesky.
+// The client is public: its ClientKey is the URL of its client metadata
+// document, which must list the CallbackURL. Requests are pushed to the
+// authorization server (PAR), use PKCE, and the tokens are bound to DPoPKey
+// (DPoP): keep the key of the provider to refresh the tokens later.
+// Reference: https://docs.bsky.app/docs/advanced-guides/oauth-client
+package bluesky
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// AuthServerURL is the authorization server of Bluesky, users hosted on
+	// other servers need a provider of their own, see NewCustomisedURL.
+	AuthServerURL = "https://bsky.social"
+	ProfileURL    = "https://public.api.bsky.app/xrpc/app.bsky.actor.getProfile"
+	// PLCDirectoryURL is the directory resolving did:plc identifiers.
+	PLCDirectoryURL = "https://plc.directory"
+)
+
+// Scopes of the AT Protocol. ScopeATProto is always required, it is requested
+// with ScopeTransitionGeneric, which grants access to the account as app
+// passwords do, when no scopes are given.
+const (
+	ScopeATProto           = "atproto"
+	ScopeTransitionGeneric = "transition:generic"
+	ScopeTransitionEmail   = "transition:email"
+)
+
+// New creates a new Bluesky provider, and sets up important connection details.
+// You should always call `bluesky.New` to get a new Provider. Never try to create
+// one manually. clientKey is the URL of the client metadata document.
+func New(clientKey, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, callbackURL, AuthServerURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but authenticates the users of the
+// authorization server at authServerURL.
+func NewCustomisedURL(clientKey, callbackURL, authServerURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeATProto, ScopeTransitionGeneric}
+	}
+	p := &Provider{
+		ClientKey:     clientKey,
+		CallbackURL:   callbackURL,
+		AuthServerURL: strings.TrimSuffix(authServerURL, "/"),
+		providerName:  "bluesky",
+		profileURL:    ProfileURL,
+		plcURL:        strings.TrimSuffix(PLCDirectoryURL, "/"),
+		scopes:        scopes,
+	}
+	// The key can only fail to be generated if the system has no randomness,
+	// the error is returned by every request needing a proof then.
+	p.DPoPKey, p.keyErr = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Bluesky.
+type Provider struct {
+	ClientKey     string
+	CallbackURL   string
+	AuthServerURL string
+	HTTPClient    *http.Client
+
+	// DPoPKey is the P-256 key the tokens are bound to, generated by New.
+	// Tokens can only be refreshed with the key they were issued for.
+	DPoPKey *ecdsa.PrivateKey
+
+	providerName string
+	profileURL   string
+	plcURL       string
+	scopes       []string
+	keyErr       error
+}
+
+// metadata is the metadata of an authorization server (RFC 8414).
+type metadata struct {
+	Issuer                             string `json:"issuer"`
+	AuthorizationEndpoint              string `json:"authorization_endpoint"`
+	TokenEndpoint                      string `json:"token_endpoint"`
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
+}
+
+// tokenResponse is the response of the token end-point, which names the
+// account the tokens were issued for.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Sub          string `json:"sub"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the bluesky package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth pushes an authorization request to the authorization server, and
+// returns the end-point authenticating the user for it.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	md, err := p.metadata()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := goth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	par := struct {
+		RequestURI string `json:"request_uri"`
+	}{}
+	nonce, err := p.post(md.PushedAuthorizationRequestEndpoint, "", url.Values{
+		"client_id":             {p.ClientKey},
+		"response_type":         {"code"},
+		"redirect_uri":          {p.CallbackURL},
+		"scope":                 {strings.Join(p.scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {goth.CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}, &par)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		AuthURL: md.AuthorizationEndpoint + "?" + url.Values{
+			"client_id":   {p.ClientKey},
+			"request_uri": {par.RequestURI},
+		}.Encode(),
+		CodeVerifier: verifier,
+		DPoPNonce:    nonce,
+	}, nil
+}
+
+// FetchUser will go to Bluesky and access the public profile of the user.
+// The email address of users is not part of it. The account named by the
+// token response is only trusted once its DID document shows that it is
+// hosted on a PDS whose authorization server is the one the user signed in
+// with. NickName is the handle of the DID document, and is left empty when
+// the handle does not resolve back to the DID.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.DID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	md, err := p.metadata()
+	if err != nil {
+		return user, err
+	}
+	doc, err := p.resolveDID(sess.DID)
+	if err != nil {
+		return user, err
+	}
+	if err := p.checkAuthServer(doc, md.Issuer); err != nil {
+		return user, err
+	}
+
+	resp, err := p.Client().Get(p.profileURL + "?" + url.Values{"actor": {sess.DID}}.Encode())
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	if err := userFromReader(resp.Body, &user); err != nil {
+		return user, err
+	}
+	user.UserID = sess.DID
+	user.NickName = p.verifiedHandle(doc)
+	return user, nil
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		DID         string `json:"did"`
+		Handle      string `json:"handle"`
+		DisplayName string `json:"displayName"`
+		Description string `json:"description"`
+		Avatar      string `json:"avatar"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.DID
+	user.Name = u.DisplayName
+	user.NickName = u.Handle
+	user.Description = u.Description
+	user.AvatarURL = u.Avatar
+	return nil
+}
+
+// metadata fetches the metadata of the authorization server.
+func (p *Provider) metadata() (*metadata, error) {
+	resp, err := p.Client().Get(p.AuthServerURL + "/.well-known/oauth-authorization-server")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch the authorization server metadata", resp)
+	}
+
+	md := &metadata{}
+	if err := json.NewDecoder(resp.Body).Decode(md); err != nil {
+		return nil, err
+	}
+	if md.PushedAuthorizationRequestEndpoint == "" {
+		return nil, fmt.Errorf("%s: authorization server %s does not support pushed authorization requests", p.providerName, md.Issuer)
+	}
+	return md, nil
+}
+
+// post posts form to endpoint with a DPoP proof and decodes the response into
+// v. The authorization server answers the first request with the nonce the
+// proofs must carry, the request is then retried with it. post returns the
+// last nonce, to be used by the next request.
+func (p *Provider) post(endpoint, nonce string, form url.Values, v interface{}) (string, error) {
+	for retry := 0; ; retry++ {
+		proof, err := p.dpopProof("POST", endpoint, nonce)
+		if err != nil {
+			return nonce, err
+		}
+		req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nonce, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("DPoP", proof)
+
+		resp, err := p.Client().Do(req)
+		if err != nil {
+			return nonce, err
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nonce, err
+		}
+		if n := resp.Header.Get("DPoP-Nonce"); n != "" {
+			nonce = n
+		}
+
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+			return nonce, json.Unmarshal(b, v)
+		}
+		e := struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}{}
+		_ = json.Unmarshal(b, &e)
+		if e.Error == "use_dpop_nonce" && retry == 0 {
+			continue
+		}
+		if e.Error != "" {
+			return nonce, fmt.Errorf("%s responded with %s: %s", p.providerName, e.Error, e.ErrorDescription)
+		}
+		return nonce, goth.NewProviderAPIError(p.providerName, "", resp)
+	}
+}
+
+// dpopProof returns a DPoP proof (RFC 9449) of a request to uri, signed with
+// DPoPKey.
+func (p *Provider) dpopProof(method, uri, nonce string) (string, error) {
+	if p.DPoPKey == nil {
+		if p.keyErr != nil {
+			return "", fmt.Errorf("%s: generating the DPoP key: %v", p.providerName, p.keyErr)
+		}
+		return "", fmt.Errorf("%s: no DPoP key", p.providerName)
+	}
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"htm": method,
+		"htu": uri,
+		"iat": goth.Clock().Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(p.DPoPKey.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(p.DPoPKey.Y.FillBytes(make([]byte, 32))),
+	}
+	return token.SignedString(p.DPoPKey)
+}
+
+// token turns a response of the token end-point into an oauth2.Token.
+func (t *tokenResponse) token() *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  t.AccessToken,
+		TokenType:    t.TokenType,
+		RefreshToken: t.RefreshToken,
+	}
+	if t.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	return token.WithExtra(map[string]interface{}{"sub": t.Sub})
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. The refresh
+// token must have been issued for DPoPKey.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	md, err := p.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tokenResponse{}
+	if _, err := p.post(md.TokenEndpoint, "", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {p.ClientKey},
+	}, t); err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+	return t.token(), nil
+}
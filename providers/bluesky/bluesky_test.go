@@ -0,0 +1,150 @@
+package bluesky_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/bluesky"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), bluesky.New("https://example.com/client-metadata.json", "/foo"))
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := bluesky.New("https://example.com/client-metadata.json", "/foo")
+	a.Equal(provider.ClientKey, "https://example.com/client-metadata.json")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.AuthServerURL, "https://bsky.social")
+	a.NotNil(provider.DPoPKey)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	const clientID = "https://example.com/client-metadata.json"
+	var ts *httptest.Server
+	var challenge, verifier string
+
+	// checkProof verifies the DPoP proof of r, and asks for a nonce when it
+	// has none.
+	checkProof := func(w http.ResponseWriter, r *http.Request) bool {
+		token, err := jwt.Parse(r.Header.Get("DPoP"), func(token *jwt.Token) (interface{}, error) {
+			jwk := token.Header["jwk"].(map[string]interface{})
+			x, _ := base64.RawURLEncoding.DecodeString(jwk["x"].(string))
+			y, _ := base64.RawURLEncoding.DecodeString(jwk["y"].(string))
+			return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+		})
+		a.NoError(err)
+		a.Equal("dpop+jwt", token.Header["typ"])
+		claims := token.Claims.(jwt.MapClaims)
+		a.Equal("POST", claims["htm"])
+		a.Equal(ts.URL+r.URL.Path, claims["htu"])
+		w.Header().Set("DPoP-Nonce", "nonce")
+		if claims["nonce"] != "nonce" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"use_dpop_nonce","error_description":"Authorization server requires nonce in DPoP proof"}`)
+			return false
+		}
+		return true
+	}
+
+	ts = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			fmt.Fprintf(w, `{"issuer":"%[1]s","authorization_endpoint":"%[1]s/oauth/authorize","token_endpoint":"%[1]s/oauth/token","pushed_authorization_request_endpoint":"%[1]s/oauth/par","dpop_signing_alg_values_supported":["ES256"]}`, ts.URL)
+		case "/oauth/par":
+			if !checkProof(w, r) {
+				return
+			}
+			a.Equal(clientID, r.FormValue("client_id"))
+			a.Equal("atproto transition:generic", r.FormValue("scope"))
+			a.Equal("state", r.FormValue("state"))
+			challenge = r.FormValue("code_challenge")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"request_uri":"urn:ietf:params:oauth:request_uri:req-123","expires_in":299}`)
+		case "/oauth/token":
+			if !checkProof(w, r) {
+				return
+			}
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"token","token_type":"DPoP","refresh_token":"refresh","expires_in":3600,"scope":"atproto transition:generic","sub":"did:plc:ewvi7nxzyoun6zhxrhs64oiz"}`)
+		case "/did:plc:ewvi7nxzyoun6zhxrhs64oiz":
+			fmt.Fprintf(w, `{"id":"did:plc:ewvi7nxzyoun6zhxrhs64oiz","alsoKnownAs":["at://%s"],"service":[{"id":"#atproto_pds","type":"AtprotoPersonalDataServer","serviceEndpoint":"%s"}]}`, r.Host, ts.URL)
+		case "/did:plc:other":
+			fmt.Fprintf(w, `{"id":"did:plc:other","alsoKnownAs":["at://other.example.com"],"service":[{"id":"#atproto_pds","type":"AtprotoPersonalDataServer","serviceEndpoint":"%s/other"}]}`, ts.URL)
+		case "/.well-known/oauth-protected-resource":
+			fmt.Fprintf(w, `{"resource":"%[1]s","authorization_servers":["%[1]s"]}`, ts.URL)
+		case "/other/.well-known/oauth-protected-resource":
+			fmt.Fprintf(w, `{"resource":"%s/other","authorization_servers":["https://other.example.com"]}`, ts.URL)
+		case "/.well-known/atproto-did":
+			fmt.Fprint(w, "did:plc:ewvi7nxzyoun6zhxrhs64oiz")
+		case "/xrpc/app.bsky.actor.getProfile":
+			a.Equal("did:plc:ewvi7nxzyoun6zhxrhs64oiz", r.URL.Query().Get("actor"))
+			fmt.Fprint(w, `{"did":"did:plc:ewvi7nxzyoun6zhxrhs64oiz","handle":"jane.bsky.social","displayName":"Jane Doe","description":"Hello","avatar":"https://cdn.bsky.app/avatar.jpg","followersCount":12}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	bluesky.ProfileURL = ts.URL + "/xrpc/app.bsky.actor.getProfile"
+	defer func() { bluesky.ProfileURL = "https://public.api.bsky.app/xrpc/app.bsky.actor.getProfile" }()
+	bluesky.PLCDirectoryURL = ts.URL
+	defer func() { bluesky.PLCDirectoryURL = "https://plc.directory" }()
+	provider := bluesky.NewCustomisedURL(clientID, "/foo", ts.URL)
+	provider.HTTPClient = ts.Client()
+
+	session, err := provider.BeginAuth("state")
+	a.NoError(err)
+	s := session.(*bluesky.Session)
+	a.Equal(ts.URL+"/oauth/authorize?client_id=https%3A%2F%2Fexample.com%2Fclient-metadata.json&request_uri=urn%3Aietf%3Aparams%3Aoauth%3Arequest_uri%3Areq-123", s.AuthURL)
+	a.Equal("nonce", s.DPoPNonce)
+	a.NotEmpty(challenge)
+
+	session, err = provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	_, err = session.Authorize(provider, url.Values{"code": {"code"}, "iss": {"https://other.example.com"}})
+	a.EqualError(err, `bluesky: issuer is incorrect: "https://other.example.com"`)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}, "iss": {ts.URL}})
+	a.NoError(err)
+	a.Equal("token", token)
+	sum := sha256.Sum256([]byte(verifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), challenge)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("did:plc:ewvi7nxzyoun6zhxrhs64oiz", user.UserID)
+	a.Equal(strings.TrimPrefix(ts.URL, "https://"), user.NickName)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("https://cdn.bsky.app/avatar.jpg", user.AvatarURL)
+
+	// The authorization server cannot sign in accounts hosted elsewhere.
+	_, err = provider.FetchUser(&bluesky.Session{AccessToken: "token", DID: "did:plc:other"})
+	a.EqualError(err, "bluesky: DID did:plc:other is not hosted by the authorization server "+ts.URL)
+
+	// Refreshing asks for the nonce again.
+	newToken, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token", newToken.AccessToken)
+	a.Equal("DPoP", newToken.TokenType)
+}
@@ -0,0 +1,133 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// didDocument is the part of a DID document naming the handle and the PDS of
+// an account.
+type didDocument struct {
+	ID          string   `json:"id"`
+	AlsoKnownAs []string `json:"alsoKnownAs"`
+	Service     []struct {
+		ID              string `json:"id"`
+		Type            string `json:"type"`
+		ServiceEndpoint string `json:"serviceEndpoint"`
+	} `json:"service"`
+}
+
+// resolveDID fetches the DID document of did, from the PLC directory for
+// did:plc identifiers and from the host of did:web ones.
+func (p *Provider) resolveDID(did string) (*didDocument, error) {
+	var docURL string
+	switch {
+	case strings.HasPrefix(did, "did:plc:"):
+		docURL = p.plcURL + "/" + url.PathEscape(did)
+	case strings.HasPrefix(did, "did:web:"):
+		host, err := url.PathUnescape(strings.TrimPrefix(did, "did:web:"))
+		if err != nil || host == "" || strings.ContainsAny(host, "/?#@:") {
+			return nil, fmt.Errorf("%s: unsupported DID %q", p.providerName, did)
+		}
+		docURL = "https://" + host + "/.well-known/did.json"
+	default:
+		return nil, fmt.Errorf("%s: unsupported DID %q", p.providerName, did)
+	}
+
+	resp, err := p.Client().Get(docURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to resolve the DID of the user", resp)
+	}
+
+	doc := &didDocument{}
+	if err := json.NewDecoder(resp.Body).Decode(doc); err != nil {
+		return nil, err
+	}
+	if doc.ID != did {
+		return nil, fmt.Errorf("%s: DID document of %s is for %s", p.providerName, did, doc.ID)
+	}
+	return doc, nil
+}
+
+// pds returns the URL of the Personal Data Server hosting the account.
+func (d *didDocument) pds() string {
+	for _, s := range d.Service {
+		if (s.ID == "#atproto_pds" || s.ID == d.ID+"#atproto_pds") && s.Type == "AtprotoPersonalDataServer" {
+			return strings.TrimSuffix(s.ServiceEndpoint, "/")
+		}
+	}
+	return ""
+}
+
+// checkAuthServer returns an error unless the PDS of doc is protected by the
+// authorization server issuer, so that an authorization server can only sign
+// in the accounts it is responsible for.
+func (p *Provider) checkAuthServer(doc *didDocument, issuer string) error {
+	pds := doc.pds()
+	if pds == "" {
+		return fmt.Errorf("%s: DID %s has no PDS", p.providerName, doc.ID)
+	}
+
+	resp, err := p.Client().Get(pds + "/.well-known/oauth-protected-resource")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return goth.NewProviderAPIError(p.providerName, "trying to fetch the PDS metadata", resp)
+	}
+
+	md := struct {
+		AuthorizationServers []string `json:"authorization_servers"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+		return err
+	}
+	if len(md.AuthorizationServers) == 0 || md.AuthorizationServers[0] != issuer {
+		return fmt.Errorf("%s: DID %s is not hosted by the authorization server %s", p.providerName, doc.ID, issuer)
+	}
+	return nil
+}
+
+// verifiedHandle returns the handle of doc if it resolves back to the DID,
+// through its well-known HTTPS end-point or its _atproto DNS record.
+func (p *Provider) verifiedHandle(doc *didDocument) string {
+	var handle string
+	for _, aka := range doc.AlsoKnownAs {
+		if strings.HasPrefix(aka, "at://") {
+			handle = strings.TrimPrefix(aka, "at://")
+			break
+		}
+	}
+	if handle == "" || strings.ContainsAny(handle, "/?#@") {
+		return ""
+	}
+
+	if resp, err := p.Client().Get("https://" + handle + "/.well-known/atproto-did"); err == nil {
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil && resp.StatusCode == http.StatusOK && strings.TrimSpace(string(b)) == doc.ID {
+			return handle
+		}
+	}
+	records, _ := net.LookupTXT("_atproto." + handle)
+	for _, r := range records {
+		if r == "did="+doc.ID {
+			return handle
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with Bluesky.
+type Session struct {
+	AuthURL      string
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+	CodeVerifier string
+	// DPoPNonce is the last nonce of the authorization server.
+	DPoPNonce string
+	// DID is the decentralized identifier of the account.
+	DID string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Bluesky provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with Bluesky and return the access token to be stored for future use.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	md, err := p.metadata()
+	if err != nil {
+		return "", err
+	}
+	// The authorization server names itself in the callback (RFC 9207).
+	if iss := params.Get("iss"); iss != "" && iss != md.Issuer {
+		return "", fmt.Errorf("%s: issuer is incorrect: %q", p.providerName, iss)
+	}
+
+	t := &tokenResponse{}
+	s.DPoPNonce, err = p.post(md.TokenEndpoint, s.DPoPNonce, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {params.Get("code")},
+		"redirect_uri":  {p.CallbackURL},
+		"client_id":     {p.ClientKey},
+		"code_verifier": {s.CodeVerifier},
+	}, t)
+	if err != nil {
+		return "", err
+	}
+
+	token := t.token()
+	if !token.Valid() || t.Sub == "" {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+	s.DID = t.Sub
+	s.CodeVerifier = ""
+	return token.AccessToken, err
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
@@ -0,0 +1,204 @@
+// Package canva implements the OAuth2 protocol for authenticating users through Canva Connect.
+// Canva requires PKCE, the code verifier is kept in the session between
+// BeginAuth and Authorize.
+// Reference: https://www.canva.dev/docs/connect/authentication/
+package canva
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL  = "https://www.canva.com/api/oauth/authorize"
+	TokenURL = "https://api.canva.com/rest/v1/oauth/token"
+	APIURL   = "https://api.canva.com/rest/v1"
+)
+
+// ScopeProfileRead lets the provider read the profile of the user, it is
+// requested when no scopes are given.
+const ScopeProfileRead = "profile:read"
+
+// New creates a new Canva provider, and sets up important connection details.
+// You should always call `canva.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeProfileRead}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "canva",
+		apiURL:       APIURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Canva.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	apiURL       string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the canva package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Canva for an authentication end-point, with the challenge of
+// a new PKCE code verifier.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state,
+			oauth2.SetAuthURLParam("code_challenge", goth.CodeChallenge(verifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Canva and access basic information about the user.
+// Canva does not share the email address of users.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	me := struct {
+		TeamUser struct {
+			UserID string `json:"user_id"`
+			TeamID string `json:"team_id"`
+		} `json:"team_user"`
+	}{}
+	if err := p.get("/users/me", sess.AccessToken, &me); err != nil {
+		return user, err
+	}
+	profile := struct {
+		Profile struct {
+			DisplayName string `json:"display_name"`
+		} `json:"profile"`
+	}{}
+	if err := p.get("/users/me/profile", sess.AccessToken, &profile); err != nil {
+		return user, err
+	}
+
+	user.UserID = me.TeamUser.UserID
+	user.Name = profile.Profile.DisplayName
+	user.RawData = map[string]interface{}{
+		"user_id":      me.TeamUser.UserID,
+		"team_id":      me.TeamUser.TeamID,
+		"display_name": profile.Profile.DisplayName,
+	}
+	return user, nil
+}
+
+// get calls the end-point path of the Connect API and decodes the response
+// into v.
+func (p *Provider) get(path, accessToken string, v interface{}) error {
+	req, err := http.NewRequest("GET", p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. Canva
+// rotates refresh tokens, each one can be used once.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
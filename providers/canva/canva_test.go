@@ -0,0 +1,104 @@
+package canva_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/canva"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), canvaProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := canvaProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := canvaProvider().BeginAuth("test_state")
+	s := session.(*canva.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.canva.com/api/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=profile%3Aread")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","expires_in":14400,"scope":"profile:read"}`)
+		case "/users/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"team_user":{"user_id":"auDAbliZ2rQNNOsUl5OLu","team_id":"Oi2RJILTrKk0KRhRUZozX"}}`)
+		case "/users/me/profile":
+			fmt.Fprint(w, `{"profile":{"display_name":"Jane Doe"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	canva.TokenURL, canva.APIURL = ts.URL+"/oauth/token", ts.URL
+	defer func() {
+		canva.TokenURL, canva.APIURL = "https://api.canva.com/rest/v1/oauth/token", "https://api.canva.com/rest/v1"
+	}()
+	provider := canvaProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*canva.Session).CodeVerifier
+
+	// The verifier must survive the round trip through the user's session.
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("auDAbliZ2rQNNOsUl5OLu", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Oi2RJILTrKk0KRhRUZozX", user.RawData["team_id"])
+}
+
+func canvaProvider() *canva.Provider {
+	return canva.New("key", "secret", "/foo")
+}
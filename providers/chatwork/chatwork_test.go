@@ -0,0 +1,87 @@
+package chatwork_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/chatwork"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), chatworkProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := chatworkProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := chatworkProvider().BeginAuth("test_state")
+	s := session.(*chatwork.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.chatwork.com/packages/oauth2/login.php")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=users.profile.me%3Aread")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","expires_in":1800,"scope":"users.profile.me:read"}`)
+		case "/v2/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"account_id":123,"room_id":322,"name":"John Smith","chatwork_id":"tarochatworkid","organization_name":"Hello Company","mail":"taro@example.com","login_mail":"account@example.com","avatar_image_url":"https://example.com/abc.png"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	chatwork.TokenURL, chatwork.ProfileURL = ts.URL+"/token", ts.URL+"/v2/me"
+	defer func() {
+		chatwork.TokenURL, chatwork.ProfileURL = "https://oauth.chatwork.com/token", "https://api.chatwork.com/v2/me"
+	}()
+	provider := chatworkProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("123", user.UserID)
+	a.Equal("John Smith", user.Name)
+	a.Equal("tarochatworkid", user.NickName)
+	a.Equal("account@example.com", user.Email)
+}
+
+func chatworkProvider() *chatwork.Provider {
+	return chatwork.New("key", "secret", "/foo")
+}
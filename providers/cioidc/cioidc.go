@@ -0,0 +1,221 @@
+// Package cioidc authenticates CI workloads with the OpenID Connect tokens of GitHub Actions and GitLab CI.
+// Jobs request a token from their CI and present it as a bearer token, the
+// provider validates it and turns its claims into a goth.User describing the
+// workload: UserID is the subject, Name the repository and NickName the user
+// who triggered the job. The claims are kept in RawData, get them with ClaimsOf.
+//
+// Any repository can get a token for any audience: set Repositories to the
+// repositories allowed to authenticate.
+//
+// Reference: https://docs.github.com/en/actions/deployment/security-hardening-your-deployments/about-security-hardening-with-openid-connect
+// Reference: https://docs.gitlab.com/ee/ci/secrets/id_token_authentication.html
+package cioidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Issuers of the tokens of GitHub Actions and GitLab.com.
+const (
+	GitHubIssuer = "https://token.actions.githubusercontent.com"
+	GitLabIssuer = "https://gitlab.com"
+)
+
+// ErrNoToken is returned when a request has no bearer token.
+var ErrNoToken = errors.New("cioidc: request has no bearer token")
+
+// ErrNoAudience is returned for every token while the provider has no
+// Audience, as the CI issues tokens for any audience a job asks for.
+var ErrNoAudience = errors.New("cioidc: the provider has no audience")
+
+// NewGitHubActions creates a provider accepting the tokens GitHub Actions
+// issues for audience. The audience is required by every constructor, without
+// it every token is refused with ErrNoAudience.
+func NewGitHubActions(audience string) *Provider {
+	return newProvider("githubactions", GitHubIssuer, GitHubIssuer+"/.well-known/jwks", audience)
+}
+
+// NewGitLab creates a provider accepting the tokens GitLab.com CI issues for
+// audience.
+func NewGitLab(audience string) *Provider {
+	return NewGitLabCustomisedURL(GitLabIssuer, audience)
+}
+
+// NewGitLabCustomisedURL is similar to NewGitLab(...) but accepts the tokens
+// of a self-managed GitLab instance, at instanceURL.
+func NewGitLabCustomisedURL(instanceURL, audience string) *Provider {
+	instanceURL = strings.TrimSuffix(instanceURL, "/")
+	return newProvider("gitlabci", instanceURL, instanceURL+"/oauth/discovery/keys", audience)
+}
+
+func newProvider(name, issuer, keysURL, audience string) *Provider {
+	return &Provider{
+		Issuer:       issuer,
+		Audience:     audience,
+		providerName: name,
+		keys:         goth.NewKeySet(keysURL, time.Hour),
+	}
+}
+
+// Provider validates the OpenID Connect tokens of a CI.
+type Provider struct {
+	Issuer     string
+	Audience   string
+	HTTPClient *http.Client
+
+	// Repositories are the repositories, "owner/name" on GitHub and
+	// "group/project" on GitLab, allowed to authenticate. Patterns are
+	// matched with path.Match, "octo-org/*" allows every repository of
+	// octo-org. Tokens of other repositories are rejected.
+	Repositories []string
+
+	providerName string
+	keys         *goth.KeySet
+}
+
+// Claims are the claims describing the workload, common to GitHub and
+// GitLab under different names.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Repository is the repository, or GitLab project, the job runs for.
+	Repository string
+	// Ref is the git ref the job runs on, such as refs/heads/main.
+	Ref string
+	// SHA is the commit the job runs on.
+	SHA string
+	// Actor is the login of the user who triggered the job.
+	Actor string
+	// Environment is the deployment environment of the job, if any.
+	Environment string
+	// RunID is the id of the workflow run, or GitLab pipeline.
+	RunID string
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Repository  string `json:"repository"`
+	Ref         string `json:"ref"`
+	SHA         string `json:"sha"`
+	Actor       string `json:"actor"`
+	Environment string `json:"environment"`
+	RunID       string `json:"run_id"`
+
+	ProjectPath string `json:"project_path"`
+	UserLogin   string `json:"user_login"`
+	UserEmail   string `json:"user_email"`
+	PipelineID  string `json:"pipeline_id"`
+	RefType     string `json:"ref_type"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromRequest returns the workload the bearer token of the request was
+// issued for. It returns ErrNoToken when the request has no bearer token.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return goth.User{Provider: p.Name()}, ErrNoToken
+	}
+	return p.UserFromToken(strings.TrimSpace(auth[7:]))
+}
+
+// UserFromToken validates a token of the CI, and returns the workload it was
+// issued for.
+func (p *Provider) UserFromToken(token string) (goth.User, error) {
+	user := goth.User{Provider: p.Name()}
+	if p.Audience == "" {
+		return user, ErrNoAudience
+	}
+
+	c := &tokenClaims{}
+	raw, err := idtoken.Validate(token, c, p.keys.Keyfunc(p.Client()), []string{"RS256"}, p.Issuer, p.Audience)
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+
+	claims := c.normalize()
+	if !p.allowed(claims.Repository) {
+		return user, fmt.Errorf("%s: repository %q is not allowed", p.providerName, claims.Repository)
+	}
+
+	user.UserID = claims.Subject
+	user.Name = claims.Repository
+	user.NickName = claims.Actor
+	user.Email = c.UserEmail
+	user.RawData = raw
+	if claims.ExpiresAt != nil {
+		user.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return user, nil
+}
+
+func (p *Provider) allowed(repository string) bool {
+	for _, pattern := range p.Repositories {
+		if ok, _ := path.Match(pattern, repository); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimsOf returns the claims of a workload returned by UserFromToken.
+func ClaimsOf(user goth.User) (Claims, error) {
+	b, err := json.Marshal(user.RawData)
+	if err != nil {
+		return Claims{}, err
+	}
+	c := &tokenClaims{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return Claims{}, err
+	}
+	return c.normalize(), nil
+}
+
+// normalize returns the claims of GitHub, or GitLab, as Claims.
+func (c *tokenClaims) normalize() Claims {
+	claims := Claims{
+		RegisteredClaims: c.RegisteredClaims,
+		Repository:       c.Repository,
+		Ref:              c.Ref,
+		SHA:              c.SHA,
+		Actor:            c.Actor,
+		Environment:      c.Environment,
+		RunID:            c.RunID,
+	}
+	if c.ProjectPath != "" {
+		// GitLab names the ref without its prefix, next to its type.
+		claims.Repository = c.ProjectPath
+		claims.Actor = c.UserLogin
+		claims.RunID = c.PipelineID
+		switch c.RefType {
+		case "branch":
+			claims.Ref = "refs/heads/" + c.Ref
+		case "tag":
+			claims.Ref = "refs/tags/" + c.Ref
+		}
+	}
+	return claims
+}
@@ -0,0 +1,149 @@
+package cioidc_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/cioidc"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := cioidc.NewGitHubActions("https://example.com")
+	a.Equal(cioidc.GitHubIssuer, provider.Issuer)
+	a.Equal("githubactions", provider.Name())
+
+	provider = cioidc.NewGitLab("https://example.com")
+	a.Equal(cioidc.GitLabIssuer, provider.Issuer)
+	a.Equal("gitlabci", provider.Name())
+
+	provider = cioidc.NewGitLabCustomisedURL("https://gitlab.example.com/", "https://example.com")
+	a.Equal("https://gitlab.example.com", provider.Issuer)
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/jwks", "/oauth/discovery/keys":
+			key, err := jwk.New(&priv.PublicKey)
+			a.NoError(err)
+			a.NoError(key.Set(jwk.KeyIDKey, "78167F727DEC5D801DD1C8784C704A1C880EC0E1"))
+			set := jwk.NewSet()
+			set.Add(key)
+			a.NoError(json.NewEncoder(w).Encode(set))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "78167F727DEC5D801DD1C8784C704A1C880EC0E1"
+		signed, err := token.SignedString(priv)
+		a.NoError(err)
+		return signed
+	}
+
+	// GitLab tokens are validated against the keys of the instance.
+	provider := cioidc.NewGitLabCustomisedURL(ts.URL, "https://vault.example.com")
+	provider.Repositories = []string{"mygroup/*"}
+	user, err := provider.UserFromToken(sign(jwt.MapClaims{
+		"iss":          ts.URL,
+		"aud":          "https://vault.example.com",
+		"sub":          "project_path:mygroup/myproject:ref_type:branch:ref:main",
+		"project_path": "mygroup/myproject",
+		"user_login":   "myuser",
+		"user_email":   "myuser@example.com",
+		"pipeline_id":  "1212",
+		"ref":          "main",
+		"ref_type":     "branch",
+		"sha":          "714a629c0b401fdce83e847fc9589983fc6f46bc",
+		"iat":          now.Unix(),
+		"exp":          now.Add(5 * time.Minute).Unix(),
+	}))
+	a.NoError(err)
+	a.Equal("gitlabci", user.Provider)
+	a.Equal("project_path:mygroup/myproject:ref_type:branch:ref:main", user.UserID)
+	a.Equal("mygroup/myproject", user.Name)
+	a.Equal("myuser", user.NickName)
+	a.Equal("myuser@example.com", user.Email)
+	claims, err := cioidc.ClaimsOf(user)
+	a.NoError(err)
+	a.Equal("refs/heads/main", claims.Ref)
+	a.Equal("1212", claims.RunID)
+
+	// GitHub tokens come as bearer tokens. The issuer must match the
+	// provider, pretend GitHub lives on the test server.
+	provider = cioidc.NewGitLabCustomisedURL(ts.URL, "https://example.com")
+	provider.Issuer = cioidc.GitHubIssuer
+	provider.Repositories = []string{"octo-org/octo-repo"}
+	claimsOf := func(repository string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":         cioidc.GitHubIssuer,
+			"aud":         "https://example.com",
+			"sub":         "repo:" + repository + ":environment:prod",
+			"repository":  repository,
+			"ref":         "refs/heads/main",
+			"sha":         "example-sha",
+			"actor":       "octocat",
+			"environment": "prod",
+			"run_id":      "example-run-id",
+			"iat":         now.Unix(),
+			"exp":         now.Add(5 * time.Minute).Unix(),
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	_, err = provider.UserFromRequest(req)
+	a.Equal(cioidc.ErrNoToken, err)
+
+	req.Header.Set("Authorization", "Bearer "+sign(claimsOf("octo-org/octo-repo")))
+	user, err = provider.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal("repo:octo-org/octo-repo:environment:prod", user.UserID)
+	a.Equal("octo-org/octo-repo", user.Name)
+	a.Equal("octocat", user.NickName)
+	claims, err = cioidc.ClaimsOf(user)
+	a.NoError(err)
+	a.Equal("refs/heads/main", claims.Ref)
+	a.Equal("prod", claims.Environment)
+
+	_, err = provider.UserFromToken(sign(claimsOf("octo-org/other-repo")))
+	a.EqualError(err, `gitlabci: repository "octo-org/other-repo" is not allowed`)
+
+	c := claimsOf("octo-org/octo-repo")
+	c["aud"] = "https://other.example.com"
+	_, err = provider.UserFromToken(sign(c))
+	a.EqualError(err, "gitlabci: audience is incorrect")
+
+	c = claimsOf("octo-org/octo-repo")
+	c["exp"] = now.Add(-time.Minute).Unix()
+	_, err = provider.UserFromToken(sign(c))
+	a.EqualError(err, "gitlabci: token is expired")
+
+	// Without an audience, tokens a job got for any other service would be
+	// accepted, whatever the repositories.
+	provider.Audience = ""
+	_, err = provider.UserFromToken(sign(claimsOf("octo-org/octo-repo")))
+	a.Equal(cioidc.ErrNoAudience, err)
+}
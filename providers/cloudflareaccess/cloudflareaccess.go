@@ -0,0 +1,179 @@
+// Package cloudflareaccess authenticates users of applications protected by Cloudflare Access (Zero Trust).
+// Access signs in the users itself and passes their identity along with every
+// request in a signed JWT, there is no OAuth dance: the provider validates the
+// JWT against the keys of the team and turns its claims into a goth.User. Use
+// it to load the user of gothic.RequireAuth:
+//
+//	access := cloudflareaccess.New("example.cloudflareaccess.com", "4714c1358e65fe4b408ad6d432a5f878f08194bdb4752441fd56faefa9b2b6f2")
+//	loader := gothic.UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+//		user, err := access.UserFromRequest(c.Request())
+//		return user, err == nil, nil
+//	})
+//
+// Reference: https://developers.cloudflare.com/cloudflare-one/identity/authorization-cookie/validating-json/
+package cloudflareaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// HeaderAssertion is the header Access passes the signed JWT in.
+const HeaderAssertion = "Cf-Access-Jwt-Assertion"
+
+// CookieName is the cookie Access keeps the signed JWT in, on the browsers of
+// the users.
+const CookieName = "CF_Authorization"
+
+// ErrNoAssertion is returned when a request has neither HeaderAssertion nor
+// CookieName, when it did not go through Access.
+var ErrNoAssertion = errors.New("cloudflareaccess: request has no Access token")
+
+// ErrNoAudience is returned for every token while the provider has no
+// Audience, as Access signs the tokens of every application of the team with
+// the same keys.
+var ErrNoAudience = errors.New("cloudflareaccess: the provider has no audience")
+
+// New creates a new Cloudflare Access provider. teamDomain is the domain of
+// the Zero Trust organization, such as example.cloudflareaccess.com, and
+// audience the Application Audience (AUD) tag of the application. The tag is
+// required, without it every token is refused with ErrNoAudience.
+func New(teamDomain, audience string) *Provider {
+	teamURL := strings.TrimSuffix(teamDomain, "/")
+	if !strings.Contains(teamURL, "://") {
+		teamURL = "https://" + teamURL
+	}
+	return &Provider{
+		TeamURL:      teamURL,
+		Audience:     audience,
+		providerName: "cloudflareaccess",
+		keys:         goth.NewKeySet(teamURL+"/cdn-cgi/access/certs", time.Hour),
+	}
+}
+
+// Provider validates the JWTs signed by Cloudflare Access.
+type Provider struct {
+	TeamURL    string
+	Audience   string
+	HTTPClient *http.Client
+
+	// FetchIdentity makes UserFromAssertion fetch the full identity of users
+	// from Access, which holds their name and groups besides the claims of
+	// the JWT. It is kept in RawData as identity.
+	FetchIdentity bool
+
+	providerName string
+	keys         *goth.KeySet
+}
+
+// Claims are the claims of the JWTs signed by Cloudflare Access.
+type Claims struct {
+	jwt.RegisteredClaims
+	Email         string `json:"email"`
+	Type          string `json:"type"`
+	IdentityNonce string `json:"identity_nonce"`
+	Country       string `json:"country"`
+	// CommonName is the Client ID of the service token the request was
+	// made with, which has no user.
+	CommonName string `json:"common_name"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromRequest returns the user Access authenticated the request for, from
+// HeaderAssertion or else CookieName. It returns ErrNoAssertion when the
+// request did not go through Access.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	assertion := r.Header.Get(HeaderAssertion)
+	if assertion == "" {
+		if cookie, err := r.Cookie(CookieName); err == nil {
+			assertion = cookie.Value
+		}
+	}
+	if assertion == "" {
+		return goth.User{Provider: p.Name()}, ErrNoAssertion
+	}
+	return p.UserFromAssertion(assertion)
+}
+
+// UserFromAssertion validates a JWT signed by Access, and returns the user
+// it was issued for. The claims are kept in RawData. Requests made with a
+// service token have no user, the Client ID of the token is their UserID.
+func (p *Provider) UserFromAssertion(assertion string) (goth.User, error) {
+	user := goth.User{Provider: p.Name()}
+	if p.Audience == "" {
+		return user, ErrNoAudience
+	}
+
+	claims := &Claims{}
+	raw, err := idtoken.Validate(assertion, claims, p.keys.Keyfunc(p.Client()), []string{"RS256"}, p.TeamURL, p.Audience)
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+
+	user.UserID = claims.Subject
+	if user.UserID == "" {
+		user.UserID = claims.CommonName
+		user.NickName = claims.CommonName
+	}
+	user.Email = claims.Email
+	user.Location = claims.Country
+	user.RawData = raw
+	if claims.ExpiresAt != nil {
+		user.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	if p.FetchIdentity && claims.Subject != "" {
+		identity, err := p.identity(assertion)
+		if err != nil {
+			return user, err
+		}
+		user.Name, _ = identity["name"].(string)
+		user.RawData["identity"] = identity
+	}
+	return user, nil
+}
+
+// identity fetches the identity of the user the JWT was issued for.
+func (p *Provider) identity(assertion string) (map[string]interface{}, error) {
+	req, err := http.NewRequest("GET", p.TeamURL+"/cdn-cgi/access/get-identity", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: CookieName, Value: assertion})
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	identity := map[string]interface{}{}
+	err = json.NewDecoder(resp.Body).Decode(&identity)
+	return identity, err
+}
@@ -0,0 +1,138 @@
+package cloudflareaccess_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/cloudflareaccess"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+const audience = "4714c1358e65fe4b408ad6d432a5f878f08194bdb4752441fd56faefa9b2b6f2"
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := cloudflareaccess.New("example.cloudflareaccess.com", audience)
+	a.Equal("https://example.cloudflareaccess.com", provider.TeamURL)
+	a.Equal(audience, provider.Audience)
+	a.Equal("cloudflareaccess", provider.Name())
+
+	provider = cloudflareaccess.New("https://example.cloudflareaccess.com/", audience)
+	a.Equal("https://example.cloudflareaccess.com", provider.TeamURL)
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cdn-cgi/access/certs":
+			key, err := jwk.New(&priv.PublicKey)
+			a.NoError(err)
+			a.NoError(key.Set(jwk.KeyIDKey, "b9e0f5a2"))
+			set := jwk.NewSet()
+			set.Add(key)
+			a.NoError(json.NewEncoder(w).Encode(set))
+		case "/cdn-cgi/access/get-identity":
+			cookie, err := r.Cookie(cloudflareaccess.CookieName)
+			a.NoError(err)
+			a.NotEmpty(cookie.Value)
+			fmt.Fprint(w, `{"id":"1164449231815010287495","name":"Jane Doe","email":"jane@example.com","groups":[{"id":"g1","name":"engineering"}],"idp":{"id":"b8bc0d5f","type":"okta"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	provider := cloudflareaccess.New(ts.URL, audience)
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "b9e0f5a2"
+		signed, err := token.SignedString(priv)
+		a.NoError(err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            ts.URL,
+			"aud":            []string{audience},
+			"sub":            "7335d417-61da-459d-899c-0a01c76a2f94",
+			"email":          "jane@example.com",
+			"type":           "app",
+			"identity_nonce": "6ei69kawdKzMIAPF",
+			"country":        "US",
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	_, err = provider.UserFromRequest(req)
+	a.Equal(cloudflareaccess.ErrNoAssertion, err)
+
+	req.Header.Set(cloudflareaccess.HeaderAssertion, sign(claims()))
+	user, err := provider.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal("cloudflareaccess", user.Provider)
+	a.Equal("7335d417-61da-459d-899c-0a01c76a2f94", user.UserID)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("US", user.Location)
+	a.Empty(user.Name)
+
+	// Browsers send the token in a cookie.
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: cloudflareaccess.CookieName, Value: sign(claims())})
+	provider.FetchIdentity = true
+	user, err = provider.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal("Jane Doe", user.Name)
+	a.Contains(user.RawData, "identity")
+
+	// Service tokens have no user.
+	c := claims()
+	delete(c, "sub")
+	delete(c, "email")
+	c["common_name"] = "88bf3b6d86161464f6509f7219099e57.access"
+	user, err = provider.UserFromAssertion(sign(c))
+	a.NoError(err)
+	a.Equal("88bf3b6d86161464f6509f7219099e57.access", user.UserID)
+	a.Empty(user.Email)
+
+	c = claims()
+	c["aud"] = []string{"other"}
+	_, err = provider.UserFromAssertion(sign(c))
+	a.EqualError(err, "cloudflareaccess: audience is incorrect")
+
+	c = claims()
+	c["iss"] = "https://other.cloudflareaccess.com"
+	_, err = provider.UserFromAssertion(sign(c))
+	a.EqualError(err, `cloudflareaccess: issuer is incorrect: "https://other.cloudflareaccess.com"`)
+
+	c = claims()
+	c["exp"] = now.Add(-time.Minute).Unix()
+	_, err = provider.UserFromAssertion(sign(c))
+	a.EqualError(err, "cloudflareaccess: token is expired")
+
+	// Without an AUD tag, the tokens of any application of the team would be
+	// accepted.
+	_, err = cloudflareaccess.New(ts.URL, "").UserFromAssertion(sign(claims()))
+	a.Equal(cloudflareaccess.ErrNoAudience, err)
+}
@@ -0,0 +1,226 @@
+// Package coinbase implements the OAuth2 protocol for authenticating users through Coinbase.
+// The provider uses PKCE, the code verifier is kept in the session between
+// BeginAuth and Authorize. Coinbase access tokens are valid an hour, and each
+// refresh returns a new refresh token, the previous one can not be used again.
+// Reference: https://docs.cdp.coinbase.com/coinbase-app/oauth2-integration/overview
+package coinbase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://login.coinbase.com/oauth2/auth"
+	TokenURL   = "https://login.coinbase.com/oauth2/token"
+	ProfileURL = "https://api.coinbase.com/v2/user"
+)
+
+// Scopes of the user information, ScopeUserRead and ScopeUserEmail are
+// requested when no scopes are given.
+const (
+	ScopeUserRead  = "wallet:user:read"
+	ScopeUserEmail = "wallet:user:email"
+)
+
+// Scopes of the Advanced Trade API.
+const (
+	ScopeAccountsRead     = "wallet:accounts:read"
+	ScopeOrdersRead       = "wallet:orders:read"
+	ScopeOrdersCreate     = "wallet:orders:create"
+	ScopeTransactionsRead = "wallet:transactions:read"
+	ScopePortfoliosView   = "wallet:portfolios:view"
+)
+
+// New creates a new Coinbase provider, and sets up important connection details.
+// You should always call `coinbase.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeUserRead, ScopeUserEmail}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "coinbase",
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Coinbase.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the coinbase package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Coinbase for an authentication end-point, with the challenge
+// of a new PKCE code verifier.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state,
+			oauth2.SetAuthURLParam("code_challenge", goth.CodeChallenge(verifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Coinbase and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Data struct {
+			ID              string `json:"id"`
+			Name            string `json:"name"`
+			Username        string `json:"username"`
+			ProfileLocation string `json:"profile_location"`
+			ProfileBio      string `json:"profile_bio"`
+			AvatarURL       string `json:"avatar_url"`
+			Email           string `json:"email"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	raw := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	user.UserID = u.Data.ID
+	user.Name = u.Data.Name
+	user.NickName = u.Data.Username
+	user.Location = u.Data.ProfileLocation
+	user.Description = u.Data.ProfileBio
+	user.AvatarURL = u.Data.AvatarURL
+	user.Email = u.Data.Email
+	user.RawData = raw.Data
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. Coinbase
+// rotates refresh tokens, each one can be used once.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
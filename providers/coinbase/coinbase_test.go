@@ -0,0 +1,99 @@
+package coinbase_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/coinbase"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), coinbaseProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := coinbaseProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := coinbaseProvider().BeginAuth("test_state")
+	s := session.(*coinbase.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://login.coinbase.com/oauth2/auth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=wallet%3Auser%3Aread+wallet%3Auser%3Aemail")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth2/token":
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":3600,"scope":"wallet:user:read wallet:user:email"}`)
+		case "/v2/user":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"data":{"id":"9da7a204-544e-5fd1-9a12-61176c5d4cd8","name":"User One","username":"user1","profile_location":null,"profile_bio":null,"profile_url":"https://coinbase.com/user1","avatar_url":"https://images.coinbase.com/avatar?h=vR%2FY8igBoPwuwGren5JMwvDNGpURAY%2F0nRIOgH%2FY2Qh%2BQ6nomR3qusA%2Bh6o2%0Af9rH&s=128","resource":"user","resource_path":"/v2/user","email":"user1@example.com"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	coinbase.TokenURL, coinbase.ProfileURL = ts.URL+"/oauth2/token", ts.URL+"/v2/user"
+	defer func() {
+		coinbase.TokenURL, coinbase.ProfileURL = "https://login.coinbase.com/oauth2/token", "https://api.coinbase.com/v2/user"
+	}()
+	provider := coinbaseProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*coinbase.Session).CodeVerifier
+
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("9da7a204-544e-5fd1-9a12-61176c5d4cd8", user.UserID)
+	a.Equal("User One", user.Name)
+	a.Equal("user1", user.NickName)
+	a.Equal("user1@example.com", user.Email)
+	a.Equal("refresh", user.RefreshToken)
+}
+
+func coinbaseProvider() *coinbase.Provider {
+	return coinbase.New("key", "secret", "/foo")
+}
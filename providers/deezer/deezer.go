@@ -23,12 +23,35 @@ const (
 	endpointProfile string = "https://api.deezer.com/user/me"
 )
 
+// Permissions which can be requested from Deezer, passed as scopes to New.
+// The email permission is always requested.
+const (
+	// ScopeBasicAccess grants access to the user's basic information.
+	ScopeBasicAccess = "basic_access"
+	// ScopeEmail grants access to the user's email address.
+	ScopeEmail = "email"
+	// ScopeOfflineAccess grants an access token which does not expire.
+	ScopeOfflineAccess = "offline_access"
+	// ScopeManageLibrary grants write access to the user's library,
+	// including playlists.
+	ScopeManageLibrary = "manage_library"
+	// ScopeManageCommunity grants write access to the user's friends and
+	// followed artists.
+	ScopeManageCommunity = "manage_community"
+	// ScopeDeleteLibrary grants permission to delete items from the user's
+	// library.
+	ScopeDeleteLibrary = "delete_library"
+	// ScopeListeningHistory grants access to the user's listening history.
+	ScopeListeningHistory = "listening_history"
+)
+
 // Provider is the implementation of `goth.Provider` for accessing Deezer.
 type Provider struct {
 	ClientKey    string
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -61,13 +84,35 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the deezer package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Deezer for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -95,7 +140,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -141,6 +186,21 @@ func userFromReader(reader io.Reader, user *goth.User) error {
 	return nil
 }
 
+// ExplicitContent returns the explicit content setting of a Deezer user, as
+// found in RawData: the level the user chose (for example
+// "explicit_display" or "explicit_hide") and the levels the user may choose
+// from, which depend on the user's country and age.
+func ExplicitContent(user goth.User) (level string, available []string) {
+	level, _ = user.RawData["explicit_content_level"].(string)
+	levels, _ := user.RawData["explicit_content_levels_available"].([]interface{})
+	for _, l := range levels {
+		if l, ok := l.(string); ok {
+			available = append(available, l)
+		}
+	}
+	return level, available
+}
+
 // [Private] newConfig creates a new OAuth2 config
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 	c := &oauth2.Config{
@@ -152,12 +212,12 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 			TokenURL: tokenURL,
 		},
 		Scopes: []string{
-			"email",
+			ScopeEmail,
 		},
 	}
 
 	defaultScopes := map[string]struct{}{
-		"email": {},
+		ScopeEmail: {},
 	}
 
 	for _, scope := range scopes {
@@ -1,11 +1,14 @@
 package deezer_test
 
 import (
+	"net/http"
+	"net/url"
 	"os"
 	"testing"
 
 	"github.com/bgdsh/goth"
 	"github.com/bgdsh/goth/providers/deezer"
+	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,3 +54,40 @@ func Test_SessionFromJSON(t *testing.T) {
 func deezerProvider() *deezer.Provider {
 	return deezer.New(os.Getenv("DEEZER_KEY"), os.Getenv("DEEZER_SECRET"), "/foo", "email")
 }
+
+func Test_OfflineAccess(t *testing.T) {
+	a := assert.New(t)
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	expires := "0"
+	httpmock.RegisterResponder("POST", "https://connect.deezer.com/oauth/access_token.php", func(r *http.Request) (*http.Response, error) {
+		a.NoError(r.ParseForm())
+		a.Equal("json", r.PostForm.Get("output"))
+		resp := httpmock.NewStringResponse(200, `{"access_token":"token","expires":`+expires+`}`)
+		resp.Header.Set("Content-Type", "text/html; charset=utf-8")
+		return resp, nil
+	})
+	httpmock.RegisterResponder("GET", "https://api.deezer.com/user/me", httpmock.NewStringResponder(200, `{"id":"2529","name":"dzuser","email":"dz@example.com","explicit_content_level":"explicit_display","explicit_content_levels_available":["explicit_display","explicit_hide"]}`))
+
+	p := deezer.New("key", "secret", "/foo", deezer.ScopeOfflineAccess, deezer.ScopeManageLibrary)
+	session, _ := p.BeginAuth("state")
+	a.Contains(session.(*deezer.Session).AuthURL, "scope=email+offline_access+manage_library")
+
+	_, err := session.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	user, err := p.FetchUser(session)
+	a.NoError(err)
+	a.True(user.ExpiresAt.IsZero(), "offline_access tokens never expire")
+
+	level, available := deezer.ExplicitContent(user)
+	a.Equal("explicit_display", level)
+	a.Equal([]string{"explicit_display", "explicit_hide"}, available)
+
+	expires = "3600"
+	session, _ = p.BeginAuth("state")
+	_, err = session.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.False(session.(*deezer.Session).ExpiresAt.IsZero())
+}
@@ -3,6 +3,8 @@ package deezer
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,9 +14,10 @@ import (
 
 // Session stores data during the auth process with Deezer.
 type Session struct {
-	AuthURL     string
-	AccessToken string
-	ExpiresAt   time.Time
+	AuthURL      string
+	AccessToken  string
+	ExpiresAt    time.Time
+	CodeVerifier string `json:",omitempty"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the Deezer provider.
@@ -28,7 +31,8 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Deezer and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(oauth2.NoContext, params.Get("code"))
+	opts := append(goth.VerifierOptions(s.CodeVerifier), oauth2.SetAuthURLParam("output", "json"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), opts...)
 	if err != nil {
 		return "", err
 	}
@@ -39,6 +43,15 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 
 	s.AccessToken = token.AccessToken
 	s.ExpiresAt = token.Expiry
+	// Deezer names the lifetime of the token "expires" rather than
+	// "expires_in", and sets it to 0 for tokens granted offline_access,
+	// which never expire.
+	if s.ExpiresAt.IsZero() {
+		if seconds, err := strconv.ParseInt(fmt.Sprint(token.Extra("expires")), 10, 64); err == nil && seconds > 0 {
+			s.ExpiresAt = goth.Now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
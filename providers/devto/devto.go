@@ -0,0 +1,115 @@
+// Package devto authenticates users of DEV (dev.to) with their API keys.
+// DEV has no OAuth for third party applications: users generate an API key in
+// their settings and hand it to the application, the provider checks it and
+// returns the user it belongs to. The key is the AccessToken of the user.
+//
+//	user, err := devto.New().UserFromAPIKey(c.FormValue("api_key"))
+//
+// Reference: https://developers.forem.com/api/v1#tag/users/operation/getUserMe
+package devto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/bgdsh/goth"
+)
+
+var ProfileURL = "https://dev.to/api/users/me"
+
+// New creates a new DEV provider.
+func New() *Provider {
+	return &Provider{
+		providerName: "devto",
+		profileURL:   ProfileURL,
+	}
+}
+
+// Provider checks DEV API keys.
+type Provider struct {
+	HTTPClient   *http.Client
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromAPIKey returns the user apiKey belongs to. DEV does not share the
+// email address of users.
+func (p *Provider) UserFromAPIKey(apiKey string) (goth.User, error) {
+	user := goth.User{
+		AccessToken: apiKey,
+		Provider:    p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("api-key", apiKey)
+	req.Header.Add("Accept", "application/vnd.forem.api-v1+json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID           int64  `json:"id"`
+		Username     string `json:"username"`
+		Name         string `json:"name"`
+		Summary      string `json:"summary"`
+		Location     string `json:"location"`
+		ProfileImage string `json:"profile_image"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = strconv.FormatInt(u.ID, 10)
+	user.Name = u.Name
+	user.NickName = u.Username
+	user.Description = u.Summary
+	user.Location = u.Location
+	user.AvatarURL = u.ProfileImage
+	return nil
+}
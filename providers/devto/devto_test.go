@@ -0,0 +1,54 @@
+package devto_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/devto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal("devto", devto.New().Name())
+}
+
+func Test_UserFromAPIKey(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("api-key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"type_of":"user","id":1234,"username":"bob","name":"bob","summary":"Hello, world","twitter_username":"bob","github_username":"bob","website_url":null,"location":"New York","joined_at":"Jan 1, 2017","profile_image":"https://res.cloudinary.com/profile.jpeg"}`)
+	}))
+	defer ts.Close()
+
+	devto.ProfileURL = ts.URL
+	defer func() { devto.ProfileURL = "https://dev.to/api/users/me" }()
+	provider := devto.New()
+
+	user, err := provider.UserFromAPIKey("key")
+	a.NoError(err)
+	a.Equal("devto", user.Provider)
+	a.Equal("1234", user.UserID)
+	a.Equal("bob", user.NickName)
+	a.Equal("New York", user.Location)
+	a.Equal("key", user.AccessToken)
+	a.Equal("bob", user.RawData["github_username"])
+
+	_, err = provider.UserFromAPIKey("other")
+	a.EqualError(err, "devto responded with a 401 trying to fetch user information")
+	var apiErr *goth.ProviderAPIError
+	a.True(errors.As(err, &apiErr))
+	a.Equal(http.StatusUnauthorized, apiErr.Status)
+	_, err = provider.UserFromAPIKey("")
+	a.Error(err)
+}
@@ -42,6 +42,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -62,14 +63,36 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the digitalocean package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Github for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state, pkce...)
 	session := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return session, nil
 }
@@ -103,7 +126,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
 	}
 
 	bits, err := ioutil.ReadAll(resp.Body)
@@ -138,6 +161,7 @@ func userFromReader(reader io.Reader, user *goth.User) error {
 	}
 
 	user.Email = u.Account.Email
+	user.EmailVerified = u.Account.EmailVerified
 	user.UserID = u.Account.UUID
 
 	return err
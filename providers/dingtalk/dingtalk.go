@@ -0,0 +1,241 @@
+// Package dingtalk implements the OAuth2 protocol for authenticating users through DingTalk,
+// using the new (v2) login and the api.dingtalk.com end-points.
+// Reference: https://open.dingtalk.com/document/orgapp/tutorial-obtaining-user-personal-information
+package dingtalk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL = "https://login.dingtalk.com/oauth2/auth"
+	APIURL  = "https://api.dingtalk.com"
+)
+
+// New creates a new DingTalk provider, and sets up important connection details.
+// The openid scope is requested when no scopes are given.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{"openid"}
+	}
+	return &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		Scopes:       scopes,
+		providerName: "dingtalk",
+		authURL:      AuthURL,
+		apiURL:       APIURL,
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for accessing DingTalk.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	Scopes      []string
+	HTTPClient  *http.Client
+
+	// CorpID restricts the login to the members of an organization, by
+	// asking DingTalk to let users pick that organization only.
+	CorpID string
+
+	providerName string
+	authURL      string
+	apiURL       string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the dingtalk package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks DingTalk for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	params := url.Values{}
+	params.Add("response_type", "code")
+	params.Add("client_id", p.ClientKey)
+	params.Add("redirect_uri", p.CallbackURL)
+	params.Add("scope", strings.Join(p.Scopes, " "))
+	params.Add("state", state)
+	params.Add("prompt", "consent")
+	if p.CorpID != "" {
+		params.Add("org_type", "management")
+		params.Add("corpId", p.CorpID)
+		params.Add("exclusiveCorpId", p.CorpID)
+	}
+	session := &Session{
+		AuthURL: fmt.Sprintf("%s?%s", p.authURL, params.Encode()),
+	}
+	return session, nil
+}
+
+// FetchUser will go to DingTalk and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		Provider:     p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.apiURL+"/v1.0/contact/users/me", nil)
+	if err != nil {
+		return user, err
+	}
+	// The new DingTalk API does not accept bearer tokens, the access token is
+	// sent in its own header.
+	req.Header.Set("x-acs-dingtalk-access-token", user.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp, "/v1.0/contact/users/me"); err != nil {
+		return user, err
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.fetchToken(map[string]string{
+		"grantType":    "refresh_token",
+		"refreshToken": refreshToken,
+	})
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+func (p *Provider) fetchToken(params map[string]string) (*oauth2.Token, error) {
+	params["clientId"] = p.ClientKey
+	params["clientSecret"] = p.Secret
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Client().Post(p.apiURL+"/v1.0/oauth2/userAccessToken", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp, "/v1.0/oauth2/userAccessToken"); err != nil {
+		return nil, err
+	}
+
+	obj := struct {
+		AccessToken  string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
+		ExpireIn     int64  `json:"expireIn"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, err
+	}
+	if obj.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  obj.AccessToken,
+		RefreshToken: obj.RefreshToken,
+	}
+	if obj.ExpireIn > 0 {
+		token.Expiry = goth.Now().Add(time.Duration(obj.ExpireIn) * time.Second)
+	}
+	return token, nil
+}
+
+// checkResponse turns the errors of the DingTalk API, answered with a non 200
+// status and a code and message in the body, into Go errors.
+func checkResponse(resp *http.Response, endpoint string) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	obj := struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{}
+	b, _ := ioutil.ReadAll(resp.Body)
+	if err := json.Unmarshal(b, &obj); err != nil || obj.Code == "" {
+		return fmt.Errorf("dingtalk %s returns code: %d", endpoint, resp.StatusCode)
+	}
+	return fmt.Errorf("CODE: %s, MSG: %s", obj.Code, obj.Message)
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	obj := struct {
+		Nick      string `json:"nick"`
+		AvatarURL string `json:"avatarUrl"`
+		Email     string `json:"email"`
+		OpenID    string `json:"openId"`
+		UnionID   string `json:"unionId"`
+	}{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	// The unionId identifies the user across all the applications of an
+	// organization, the openId within this application only.
+	user.UserID = obj.UnionID
+	if user.UserID == "" {
+		user.UserID = obj.OpenID
+	}
+	user.Name = obj.Nick
+	user.NickName = obj.Nick
+	user.Email = obj.Email
+	user.AvatarURL = obj.AvatarURL
+	return nil
+}
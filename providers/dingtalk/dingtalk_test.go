@@ -0,0 +1,102 @@
+package dingtalk_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/dingtalk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), dingtalkProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := dingtalkProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.Scopes, []string{"openid"})
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := dingtalkProvider()
+	provider.CorpID = "ding123"
+	session, err := provider.BeginAuth("test_state")
+	s := session.(*dingtalk.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://login.dingtalk.com/oauth2/auth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=openid")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "exclusiveCorpId=ding123")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.0/oauth2/userAccessToken":
+			body := map[string]string{}
+			a.NoError(json.NewDecoder(r.Body).Decode(&body))
+			a.Equal("key", body["clientId"])
+			a.Equal("secret", body["clientSecret"])
+			if body["code"] != "good" && body["refreshToken"] != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"code":"invalidAuthCode","message":"authCode is invalid","requestid":"1"}`)
+				return
+			}
+			fmt.Fprint(w, `{"accessToken":"token","refreshToken":"refresh","expireIn":7200,"corpId":"ding123"}`)
+		case "/v1.0/contact/users/me":
+			a.Equal("token", r.Header.Get("x-acs-dingtalk-access-token"))
+			fmt.Fprint(w, `{"nick":"Homer","avatarUrl":"https://static.dingtalk.com/a.png","email":"homer@example.com","openId":"open","unionId":"union"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	dingtalk.APIURL = ts.URL
+	defer func() { dingtalk.APIURL = "https://api.dingtalk.com" }()
+	provider := dingtalkProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"authCode": {"good"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("union", user.UserID)
+	a.Equal("Homer", user.NickName)
+	a.Equal("homer@example.com", user.Email)
+	a.False(user.ExpiresAt.IsZero())
+
+	refreshed, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token", refreshed.AccessToken)
+
+	session, _ = provider.BeginAuth("state")
+	_, err = session.Authorize(provider, url.Values{"authCode": {"bad"}})
+	a.EqualError(err, "CODE: invalidAuthCode, MSG: authCode is invalid")
+}
+
+func dingtalkProvider() *dingtalk.Provider {
+	return dingtalk.New("key", "secret", "/foo")
+}
@@ -18,6 +18,7 @@ import (
 const (
 	authURL      string = "https://discord.com/api/oauth2/authorize"
 	tokenURL     string = "https://discord.com/api/oauth2/token"
+	revokeURL    string = "https://discord.com/api/oauth2/token/revoke"
 	userEndpoint string = "https://discord.com/api/users/@me"
 )
 
@@ -60,6 +61,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -78,16 +80,39 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is no-op for the Discord package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Discord for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]oauth2.AuthCodeOption{oauth2.AccessTypeOnline}, pkce...)
 
-	url := p.config.AuthCodeURL(state, oauth2.AccessTypeOnline)
+	url := p.config.AuthCodeURL(state, opts...)
 
 	s := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return s, nil
 }
@@ -125,7 +150,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
 	}
 
 	bits, err := ioutil.ReadAll(resp.Body)
@@ -177,6 +202,7 @@ func userFromReader(r io.Reader, user *goth.User) error {
 
 	user.Name = u.Name
 	user.Email = u.Email
+	user.EmailVerified = u.Email != "" && u.Verified
 	user.UserID = u.ID
 
 	return nil
@@ -213,10 +239,31 @@ func (p *Provider) RefreshTokenAvailable() bool {
 //RefreshToken get new access token based on the refresh token
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	token := &oauth2.Token{RefreshToken: refreshToken}
-	ts := p.config.TokenSource(oauth2.NoContext, token)
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
 	newToken, err := ts.Token()
 	if err != nil {
 		return nil, err
 	}
 	return newToken, err
 }
+
+// RevokeToken revokes an access or refresh token.
+func (p *Provider) RevokeToken(token string) error {
+	return goth.Revoke(p.Client(), goth.RevocationRequest{
+		RevocationURL: revokeURL,
+		ClientID:      p.ClientKey,
+		ClientSecret:  p.Secret,
+		Token:         token,
+	})
+}
+
+// ClientCredentialsToken requests a token for the owner of the application
+// with the client credentials grant.
+func (p *Provider) ClientCredentialsToken(scopes ...string) (*oauth2.Token, error) {
+	return goth.RequestClientCredentials(p.Client(), goth.ClientCredentialsRequest{
+		TokenURL:     p.config.Endpoint.TokenURL,
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		Scopes:       scopes,
+	})
+}
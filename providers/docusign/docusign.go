@@ -0,0 +1,258 @@
+// Package docusign implements the OAuth2 protocol for authenticating users through DocuSign.
+// The eSignature API is served from a base URI per account: FetchUser reads the
+// accounts of the user from the user info end-point, get them with Accounts,
+// or DefaultAccount.
+// Reference: https://developers.docusign.com/platform/auth/authcode/
+package docusign
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Account servers of DocuSign, New uses AccountServerURL.
+const (
+	ProductionURL = "https://account.docusign.com"
+	DemoURL       = "https://account-d.docusign.com"
+)
+
+var (
+	AccountServerURL = ProductionURL
+)
+
+// Scopes of DocuSign, ScopeSignature is requested when no scopes are given.
+// ScopeExtended extends the lifetime of refresh tokens when they are used.
+const (
+	ScopeSignature = "signature"
+	ScopeExtended  = "extended"
+	ScopeOpenID    = "openid"
+)
+
+// New creates a new DocuSign provider, and sets up important connection details.
+// You should always call `docusign.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, AccountServerURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set the account
+// server of DocuSign, such as DemoURL.
+func NewCustomisedURL(clientKey, secret, callbackURL, accountServerURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeSignature}
+	}
+	accountServerURL = strings.TrimSuffix(accountServerURL, "/")
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "docusign",
+		profileURL:   accountServerURL + "/oauth/userinfo",
+	}
+	p.config = newConfig(p, accountServerURL, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing DocuSign.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Account is a DocuSign account of the user, BaseURI is where its API is
+// served from.
+type Account struct {
+	AccountID   string `json:"account_id"`
+	AccountName string `json:"account_name"`
+	IsDefault   bool   `json:"is_default"`
+	BaseURI     string `json:"base_uri"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the docusign package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks DocuSign for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to DocuSign and access basic information about the user,
+// and its accounts.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Sub        string `json:"sub"`
+		Name       string `json:"name"`
+		GivenName  string `json:"given_name"`
+		FamilyName string `json:"family_name"`
+		Email      string `json:"email"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.Sub
+	user.Name = u.Name
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.Email = u.Email
+	return nil
+}
+
+// Accounts returns the accounts of a user fetched by FetchUser.
+func Accounts(user goth.User) ([]Account, error) {
+	raw, ok := user.RawData["accounts"]
+	if !ok {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []Account
+	err = json.Unmarshal(b, &accounts)
+	return accounts, err
+}
+
+// DefaultAccount returns the default account of a user fetched by FetchUser.
+func DefaultAccount(user goth.User) (*Account, error) {
+	accounts, err := Accounts(user)
+	if err != nil {
+		return nil, err
+	}
+	for i := range accounts {
+		if accounts[i].IsDefault {
+			return &accounts[i], nil
+		}
+	}
+	return nil, errors.New("docusign: the user has no default account")
+}
+
+func newConfig(provider *Provider, accountServerURL string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   accountServerURL + "/oauth/auth",
+			TokenURL:  accountServerURL + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
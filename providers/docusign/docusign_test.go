@@ -0,0 +1,96 @@
+package docusign_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/docusign"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), docusignProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := docusignProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := docusignProvider().BeginAuth("test_state")
+	s := session.(*docusign.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://account.docusign.com/oauth/auth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=signature")
+	a.Contains(s.AuthURL, "state=test_state")
+
+	session, err = docusign.NewCustomisedURL("key", "secret", "/foo", docusign.DemoURL).BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*docusign.Session).AuthURL, "https://account-d.docusign.com/oauth/auth")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer","refresh_token":"refresh","expires_in":28800}`)
+		case "/oauth/userinfo":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"sub":"4799e5e9-1559-4915-9862-cf4713bbcacc","name":"Susan Smart","given_name":"Susan","family_name":"Smart","created":"2015-08-13T22:03:03.45","email":"susan.smart@example.com","accounts":[{"account_id":"a4ec37d6-04ee-4fe5-b5ac-8c5f1e1cea1b","is_default":false,"account_name":"Other","base_uri":"https://eu.docusign.net"},{"account_id":"9ee9a8e6-7a33-4a1b-9a4f-8f3e1b2c3d4e","is_default":true,"account_name":"Susan Smart","base_uri":"https://na2.docusign.net"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := docusign.NewCustomisedURL("key", "secret", "/foo", ts.URL)
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("4799e5e9-1559-4915-9862-cf4713bbcacc", user.UserID)
+	a.Equal("Susan Smart", user.Name)
+	a.Equal("susan.smart@example.com", user.Email)
+
+	accounts, err := docusign.Accounts(user)
+	a.NoError(err)
+	a.Len(accounts, 2)
+	account, err := docusign.DefaultAccount(user)
+	a.NoError(err)
+	a.Equal("9ee9a8e6-7a33-4a1b-9a4f-8f3e1b2c3d4e", account.AccountID)
+	a.Equal("https://na2.docusign.net", account.BaseURI)
+
+	_, err = docusign.DefaultAccount(goth.User{})
+	a.Error(err)
+}
+
+func docusignProvider() *docusign.Provider {
+	return docusign.New("key", "secret", "/foo")
+}
@@ -0,0 +1,268 @@
+// Package ebay implements the OAuth2 protocol for authenticating users through eBay.
+// eBay does not redirect to URLs given by the application: the redirect_uri is
+// the RuName (eBay Redirect URL name) of the application, configured with the
+// URL eBay redirects to. Give it to New as callbackURL.
+// Reference: https://developer.ebay.com/api-docs/static/oauth-authorization-code-grant.html
+package ebay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://auth.ebay.com/oauth2/authorize"
+	TokenURL   = "https://api.ebay.com/identity/v1/oauth2/token"
+	ProfileURL = "https://apiz.ebay.com/commerce/identity/v1/user/"
+)
+
+// Scopes of the eBay APIs, ScopeAPI and ScopeIdentity are requested when no
+// scopes are given.
+const (
+	ScopeAPI      = "https://api.ebay.com/oauth/api_scope"
+	ScopeIdentity = "https://api.ebay.com/oauth/api_scope/commerce.identity.readonly"
+)
+
+// New creates a new eBay provider, and sets up important connection details.
+// You should always call `ebay.New` to get a new Provider. Never try to create
+// one manually. ruName is the RuName of the application.
+func New(clientKey, secret, ruName string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, ruName, AuthURL, TokenURL, ProfileURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set custom URLs to connect to,
+// such as the ones of the sandbox of eBay.
+func NewCustomisedURL(clientKey, secret, ruName, authURL, tokenURL, profileURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeAPI, ScopeIdentity}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  ruName,
+		providerName: "ebay",
+		profileURL:   profileURL,
+	}
+	p.config = newConfig(p, authURL, tokenURL, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing eBay.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the ebay package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks eBay for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to eBay and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	type address struct {
+		City string `json:"city"`
+	}
+	u := struct {
+		UserID            string `json:"userId"`
+		Username          string `json:"username"`
+		IndividualAccount *struct {
+			FirstName           string  `json:"firstName"`
+			LastName            string  `json:"lastName"`
+			Email               string  `json:"email"`
+			RegistrationAddress address `json:"registrationAddress"`
+		} `json:"individualAccount"`
+		BusinessAccount *struct {
+			Name    string  `json:"name"`
+			Email   string  `json:"email"`
+			Address address `json:"address"`
+		} `json:"businessAccount"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.UserID
+	user.NickName = u.Username
+	if a := u.IndividualAccount; a != nil {
+		user.FirstName = a.FirstName
+		user.LastName = a.LastName
+		user.Name = strings.TrimSpace(a.FirstName + " " + a.LastName)
+		user.Email = a.Email
+		user.Location = a.RegistrationAddress.City
+	}
+	if a := u.BusinessAccount; a != nil {
+		user.Name = a.Name
+		user.Email = a.Email
+		user.Location = a.Address.City
+	}
+	return nil
+}
+
+func newConfig(provider *Provider, authURL, tokenURL string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   authURL,
+			TokenURL:  tokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. eBay expects
+// the scopes in the refresh request, golang.org/x/oauth2 does not send them.
+// The refresh token is not renewed, it is valid 18 months.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	req, err := http.NewRequest("POST", p.config.Endpoint.TokenURL, strings.NewReader(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"scope":         {strings.Join(p.config.Scopes, " ")},
+	}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.ClientKey, p.Secret)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to refresh the token", resp)
+	}
+
+	t := struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+	return &oauth2.Token{
+		AccessToken:  t.AccessToken,
+		TokenType:    t.TokenType,
+		RefreshToken: refreshToken,
+		Expiry:       time.Now().Add(time.Duration(t.ExpiresIn) * time.Second),
+	}, nil
+}
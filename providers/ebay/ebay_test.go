@@ -0,0 +1,98 @@
+package ebay_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/ebay"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), ebayProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := ebayProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "My_App-MyApp-Prod-abcdefg")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := ebayProvider().BeginAuth("test_state")
+	s := session.(*ebay.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://auth.ebay.com/oauth2/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "redirect_uri=My_App-MyApp-Prod-abcdefg")
+	a.Contains(s.AuthURL, "scope=https%3A%2F%2Fapi.ebay.com%2Foauth%2Fapi_scope+https%3A%2F%2Fapi.ebay.com%2Foauth%2Fapi_scope%2Fcommerce.identity.readonly")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			a.NoError(r.ParseForm())
+			if r.PostForm.Get("grant_type") == "refresh_token" {
+				a.Equal("refresh", r.PostForm.Get("refresh_token"))
+				a.Equal(ebay.ScopeAPI+" "+ebay.ScopeIdentity, r.PostForm.Get("scope"))
+				fmt.Fprint(w, `{"access_token":"token2","expires_in":7200,"token_type":"User Access Token"}`)
+				return
+			}
+			a.Equal("My_App-MyApp-Prod-abcdefg", r.PostForm.Get("redirect_uri"))
+			fmt.Fprint(w, `{"access_token":"token","expires_in":7200,"refresh_token":"refresh","refresh_token_expires_in":47304000,"token_type":"User Access Token"}`)
+		case "/user/":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"userId":"1a2b3c4d","username":"jane_doe","accountType":"INDIVIDUAL","registrationMarketplaceId":"EBAY_US","individualAccount":{"firstName":"Jane","lastName":"Doe","email":"jane@example.com","registrationAddress":{"city":"San Jose","country":"US"}},"status":"CONFIRMED"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	provider := ebay.NewCustomisedURL("key", "secret", "My_App-MyApp-Prod-abcdefg", ebay.AuthURL, ts.URL+"/token", ts.URL+"/user/")
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("1a2b3c4d", user.UserID)
+	a.Equal("jane_doe", user.NickName)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("San Jose", user.Location)
+
+	newToken, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token2", newToken.AccessToken)
+	a.Equal("refresh", newToken.RefreshToken)
+}
+
+func ebayProvider() *ebay.Provider {
+	return ebay.New("key", "secret", "My_App-MyApp-Prod-abcdefg")
+}
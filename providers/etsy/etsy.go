@@ -0,0 +1,245 @@
+// Package etsy implements the OAuth2 protocol for authenticating users through Etsy.
+// Etsy requires PKCE, the code verifier is kept in the session between
+// BeginAuth and Authorize. Calls to the API are identified by the x-api-key
+// header: the keystring of the application, followed by its shared secret
+// when given.
+// Reference: https://developers.etsy.com/documentation/essentials/authentication
+package etsy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL  = "https://www.etsy.com/oauth/connect"
+	TokenURL = "https://api.etsy.com/v3/public/oauth/token"
+	APIURL   = "https://openapi.etsy.com/v3/application"
+)
+
+// Scopes of the Etsy API, ScopeProfileRead and ScopeEmailRead are requested
+// when no scopes are given.
+const (
+	ScopeProfileRead      = "profile_r"
+	ScopeEmailRead        = "email_r"
+	ScopeShopsRead        = "shops_r"
+	ScopeListingsRead     = "listings_r"
+	ScopeTransactionsRead = "transactions_r"
+)
+
+// New creates a new Etsy provider, and sets up important connection details.
+// You should always call `etsy.New` to get a new Provider. Never try to create
+// one manually. clientKey is the keystring of the application, and secret its
+// shared secret, it is only sent in the x-api-key header.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeProfileRead, ScopeEmailRead}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "etsy",
+		apiURL:       APIURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Etsy.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	apiURL       string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the etsy package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Etsy for an authentication end-point, with a new PKCE code
+// verifier.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state,
+			oauth2.SetAuthURLParam("code_challenge", goth.CodeChallenge(verifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Etsy and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	body, err := p.get("/users/me", sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+
+	me := struct {
+		UserID int64 `json:"user_id"`
+	}{}
+	if err := json.NewDecoder(body).Decode(&me); err != nil {
+		return user, err
+	}
+
+	body, err = p.get("/users/"+strconv.FormatInt(me.UserID, 10), sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+
+	err = userFromReader(body, &user)
+	return user, err
+}
+
+// get calls the end-point path of the API with the access token of the user.
+func (p *Provider) get(path, accessToken string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", p.apiURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	req.Header.Add("x-api-key", p.apiKey())
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+	return resp.Body, nil
+}
+
+// apiKey returns the x-api-key header of the application.
+func (p *Provider) apiKey() string {
+	if p.Secret == "" {
+		return p.ClientKey
+	}
+	return p.ClientKey + ":" + p.Secret
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		UserID       int64  `json:"user_id"`
+		PrimaryEmail string `json:"primary_email"`
+		FirstName    string `json:"first_name"`
+		LastName     string `json:"last_name"`
+		Image        string `json:"image_url_75x75"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = strconv.FormatInt(u.UserID, 10)
+	user.Email = u.PrimaryEmail
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = strings.TrimSpace(u.FirstName + " " + u.LastName)
+	user.AvatarURL = u.Image
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:    provider.ClientKey,
+		RedirectURL: provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
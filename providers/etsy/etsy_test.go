@@ -0,0 +1,102 @@
+package etsy_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/etsy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), etsyProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := etsyProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := etsyProvider().BeginAuth("test_state")
+	s := session.(*etsy.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.etsy.com/oauth/connect")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=profile_r+email_r")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			a.Empty(r.FormValue("client_secret"))
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"12345678.token","token_type":"Bearer","expires_in":3600,"refresh_token":"12345678.refresh"}`)
+		case "/v3/application/users/me":
+			a.Equal("Bearer 12345678.token", r.Header.Get("Authorization"))
+			a.Equal("key:secret", r.Header.Get("x-api-key"))
+			fmt.Fprint(w, `{"user_id":12345678,"shop_id":87654321}`)
+		case "/v3/application/users/12345678":
+			fmt.Fprint(w, `{"user_id":12345678,"primary_email":"jane@example.com","first_name":"Jane","last_name":"Doe","image_url_75x75":"https://i.etsystatic.com/iusa/75x75.jpg"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	etsy.TokenURL, etsy.APIURL = ts.URL+"/token", ts.URL+"/v3/application"
+	defer func() {
+		etsy.TokenURL, etsy.APIURL = "https://api.etsy.com/v3/public/oauth/token", "https://openapi.etsy.com/v3/application"
+	}()
+	provider := etsyProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*etsy.Session).CodeVerifier
+
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("12345678.token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("12345678", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("12345678.refresh", user.RefreshToken)
+}
+
+func etsyProvider() *etsy.Provider {
+	return etsy.New("key", "secret", "/foo")
+}
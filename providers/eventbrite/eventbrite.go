@@ -0,0 +1,241 @@
+// Package eventbrite implements the OAuth2 protocol for authenticating users through Eventbrite.
+// Events belong to organizations: besides the user, the provider fetches the
+// organizations the user is a member of. Get them with Organizations.
+// Reference: https://www.eventbrite.com/platform/docs/authentication
+package eventbrite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL  = "https://www.eventbrite.com/oauth/authorize"
+	TokenURL = "https://www.eventbrite.com/oauth/token"
+	APIURL   = "https://www.eventbriteapi.com/v3"
+)
+
+// New creates a new Eventbrite provider, and sets up important connection details.
+// You should always call `eventbrite.New` to get a new Provider. Never try to create
+// one manually. Eventbrite has no scopes, tokens grant access to the whole
+// account of the user.
+func New(clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "eventbrite",
+		apiURL:       APIURL,
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Eventbrite.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	apiURL       string
+}
+
+// Organization is an Eventbrite organization the user is a member of.
+type Organization struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Vertical string `json:"vertical"`
+	ImageID  string `json:"image_id"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the eventbrite package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Eventbrite for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Eventbrite and access basic information about the
+// user, and the organizations the user is a member of.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	body, err := p.get("/users/me/", sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+	if err := userFromReader(body, &user); err != nil {
+		return user, err
+	}
+
+	body, err = p.get("/users/me/organizations/", sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+
+	organizations := struct {
+		Organizations []map[string]interface{} `json:"organizations"`
+	}{}
+	if err := json.NewDecoder(body).Decode(&organizations); err != nil {
+		return user, err
+	}
+	user.RawData["organizations"] = organizations.Organizations
+	return user, nil
+}
+
+// get calls the end-point path of the API with the access token of the user.
+func (p *Provider) get(path, accessToken string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", p.apiURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+	return resp.Body, nil
+}
+
+// Organizations returns the organizations of a user fetched by FetchUser.
+func Organizations(user goth.User) ([]Organization, error) {
+	raw, ok := user.RawData["organizations"]
+	if !ok {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var organizations []Organization
+	err = json.Unmarshal(b, &organizations)
+	return organizations, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Emails    []struct {
+			Email   string `json:"email"`
+			Primary bool   `json:"primary"`
+		} `json:"emails"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.ID
+	user.Name = u.Name
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	for _, e := range u.Emails {
+		if e.Primary || user.Email == "" {
+			user.Email = e.Email
+		}
+	}
+	return nil
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token. Eventbrite
+// tokens do not expire.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Eventbrite")
+}
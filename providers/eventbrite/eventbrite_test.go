@@ -0,0 +1,90 @@
+package eventbrite_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/eventbrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), eventbriteProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := eventbriteProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := eventbriteProvider().BeginAuth("test_state")
+	s := session.(*eventbrite.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.eventbrite.com/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.Equal("key", r.FormValue("client_id"))
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer"}`)
+		case "/v3/users/me/":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"emails":[{"email":"old@example.com","verified":true,"primary":false},{"email":"jane@example.com","verified":true,"primary":true}],"id":"142429416488","name":"Jane Doe","first_name":"Jane","last_name":"Doe","is_public":false,"image_id":null}`)
+		case "/v3/users/me/organizations/":
+			fmt.Fprint(w, `{"organizations":[{"_type":"organization","name":"Jane's Events","vertical":"default","image_id":null,"id":"1234567890"}],"pagination":{"object_count":1,"page_number":1,"page_size":50,"page_count":1,"has_more_items":false}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	eventbrite.TokenURL, eventbrite.APIURL = ts.URL+"/oauth/token", ts.URL+"/v3"
+	defer func() {
+		eventbrite.TokenURL, eventbrite.APIURL = "https://www.eventbrite.com/oauth/token", "https://www.eventbriteapi.com/v3"
+	}()
+	provider := eventbriteProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("142429416488", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("jane@example.com", user.Email)
+
+	organizations, err := eventbrite.Organizations(user)
+	a.NoError(err)
+	a.Equal([]eventbrite.Organization{{ID: "1234567890", Name: "Jane's Events", Vertical: "default"}}, organizations)
+}
+
+func eventbriteProvider() *eventbrite.Provider {
+	return eventbrite.New("key", "secret", "/foo")
+}
@@ -20,16 +20,20 @@ type Provider struct {
 
 // Session is used only for testing.
 type Session struct {
-	ID          string
-	Name        string
-	Email       string
-	AuthURL     string
-	AccessToken string
+	ID            string
+	Name          string
+	Email         string
+	EmailVerified bool
+	AuthURL       string
+	AccessToken   string
 }
 
 // Name is used only for testing.
 func (p *Provider) Name() string {
-	return "faux"
+	if p.providerName == "" {
+		return "faux"
+	}
+	return p.providerName
 }
 
 // SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
@@ -55,11 +59,12 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	sess := session.(*Session)
 	user := goth.User{
-		UserID:      sess.ID,
-		Name:        sess.Name,
-		Email:       sess.Email,
-		Provider:    p.Name(),
-		AccessToken: sess.AccessToken,
+		UserID:        sess.ID,
+		Name:          sess.Name,
+		Email:         sess.Email,
+		EmailVerified: sess.EmailVerified,
+		Provider:      p.Name(),
+		AccessToken:   sess.AccessToken,
 	}
 
 	if user.AccessToken == "" {
@@ -79,6 +84,14 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
 // Debug is used only for testing.
 func (p *Provider) Debug(debug bool) {}
 
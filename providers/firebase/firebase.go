@@ -0,0 +1,244 @@
+// Package firebase authenticates the users of Firebase Authentication in a Go
+// backend. Clients sign in with the Firebase SDK and send their ID token along
+// with their requests, there is no OAuth dance: the provider validates the
+// token against the certificates of Firebase and turns its claims into a
+// goth.User. Use it to load the user of gothic.RequireAuth:
+//
+//	fb := firebase.New("my-project-id")
+//	loader := gothic.UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+//		user, err := fb.UserFromRequest(c.Request())
+//		return user, err == nil, nil
+//	})
+//
+// Reference: https://firebase.google.com/docs/auth/admin/verify-id-tokens#verify_id_tokens_using_a_third-party_jwt_library
+package firebase
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// IssuerPrefix is the prefix of the issuer of the ID tokens, followed by the
+// project ID.
+const IssuerPrefix = "https://securetoken.google.com/"
+
+// CertsURL is where Google publishes the X.509 certificates Firebase signs
+// the ID tokens with.
+var CertsURL = "https://www.googleapis.com/robot/v1/metadata/x509/securetoken@system.gserviceaccount.com"
+
+// RawDataSignInProvider is the key of RawData holding the sign in provider of
+// the user, such as google.com or password.
+const RawDataSignInProvider = "sign_in_provider"
+
+// ErrNoToken is returned when a request has no bearer token.
+var ErrNoToken = errors.New("firebase: request has no bearer token")
+
+// New creates a new Firebase provider for the ID tokens of the project
+// projectID.
+func New(projectID string) *Provider {
+	return &Provider{
+		ProjectID:    projectID,
+		providerName: "firebase",
+		certs:        &certSet{url: CertsURL},
+	}
+}
+
+// Provider validates the ID tokens of Firebase Authentication.
+type Provider struct {
+	ProjectID    string
+	HTTPClient   *http.Client
+	providerName string
+	certs        *certSet
+}
+
+// Claims are the claims of a Firebase ID token.
+type Claims struct {
+	jwt.RegisteredClaims
+	AuthTime      int64  `json:"auth_time"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	Firebase      struct {
+		Identities     map[string][]string `json:"identities"`
+		SignInProvider string              `json:"sign_in_provider"`
+		Tenant         string              `json:"tenant"`
+	} `json:"firebase"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromRequest returns the user of the ID token the request carries in
+// its Authorization header. It returns ErrNoToken when there is none.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return goth.User{Provider: p.Name()}, ErrNoToken
+	}
+	return p.UserFromIDToken(strings.TrimSpace(auth[7:]))
+}
+
+// UserFromIDToken validates a Firebase ID token, and returns the user it was
+// issued for. The claims are kept in RawData, along with the sign in
+// provider under RawDataSignInProvider.
+func (p *Provider) UserFromIDToken(idToken string) (goth.User, error) {
+	user := goth.User{Provider: p.Name(), IDToken: idToken}
+
+	claims := &Claims{}
+	raw, err := idtoken.Validate(idToken, claims, p.keyfunc, []string{"RS256"}, IssuerPrefix+p.ProjectID, p.ProjectID)
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	if claims.Subject == "" {
+		return user, fmt.Errorf("%s: token has no subject", p.providerName)
+	}
+	if claims.AuthTime > goth.Now().Add(goth.ClockSkew).Unix() {
+		return user, fmt.Errorf("%s: token authenticated in the future", p.providerName)
+	}
+
+	raw[RawDataSignInProvider] = claims.Firebase.SignInProvider
+
+	user.UserID = claims.Subject
+	user.Email = claims.Email
+	user.EmailVerified = claims.EmailVerified
+	user.Name = claims.Name
+	user.AvatarURL = claims.Picture
+	user.RawData = raw
+	if claims.ExpiresAt != nil {
+		user.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return user, nil
+}
+
+// SignInProvider returns the provider the user signed in to Firebase with,
+// such as google.com, apple.com, password or anonymous.
+func SignInProvider(user goth.User) string {
+	s, _ := user.RawData[RawDataSignInProvider].(string)
+	return s
+}
+
+func (p *Provider) keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid header")
+	}
+	return p.certs.key(p.Client(), kid)
+}
+
+// certSet holds the certificates published at url, until they expire as
+// told by the Cache-Control header of the response. Firebase rotates its
+// keys, a token naming an unknown key refetches them early.
+type certSet struct {
+	url string
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+	expires time.Time
+}
+
+// certsTTL is how long the certificates are kept when the response has no
+// max-age.
+const certsTTL = time.Hour
+
+func (c *certSet) key(client *http.Client, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := goth.Now()
+	stale := c.keys == nil || !now.Before(c.expires)
+	if !stale {
+		if key, ok := c.keys[kid]; ok {
+			return key, nil
+		}
+		stale = !now.Before(c.fetched.Add(goth.KeySetMinRefresh))
+	}
+	if stale {
+		if err := c.fetch(client, now); err != nil {
+			return nil, err
+		}
+	}
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("could not find certificate %q in %s", kid, c.url)
+	}
+	return key, nil
+}
+
+func (c *certSet) fetch(client *http.Client, now time.Time) error {
+	resp, err := client.Get(c.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return goth.NewProviderAPIError(c.url, "", resp)
+	}
+
+	certs := map[string]string{}
+	if err := json.NewDecoder(resp.Body).Decode(&certs); err != nil {
+		return err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for kid, data := range certs {
+		block, _ := pem.Decode([]byte(data))
+		if block == nil {
+			return fmt.Errorf("certificate %q is not PEM encoded", kid)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return err
+		}
+		key, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("certificate %q has no RSA public key", kid)
+		}
+		keys[kid] = key
+	}
+
+	c.keys, c.fetched, c.expires = keys, now, now.Add(maxAge(resp.Header.Get("Cache-Control")))
+	return nil
+}
+
+// maxAge returns the max-age directive of a Cache-Control header, or
+// certsTTL when there is none.
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, "max-age=") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return certsTTL
+}
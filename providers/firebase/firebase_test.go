@@ -0,0 +1,137 @@
+package firebase_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/firebase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const projectID = "my-project"
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := firebase.New(projectID)
+	a.Equal(projectID, provider.ProjectID)
+	a.Equal("firebase", provider.Name())
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "securetoken.system.gserviceaccount.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	a.NoError(err)
+	fetches := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Cache-Control", "public, max-age=19302, must-revalidate, no-transform")
+		certs := map[string]string{"f90fb1": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}
+		a.NoError(json.NewEncoder(w).Encode(certs))
+	}))
+	defer ts.Close()
+
+	firebase.CertsURL = ts.URL
+	defer func() {
+		firebase.CertsURL = "https://www.googleapis.com/robot/v1/metadata/x509/securetoken@system.gserviceaccount.com"
+	}()
+	provider := firebase.New(projectID)
+
+	sign := func(kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(priv)
+		a.NoError(err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            firebase.IssuerPrefix + projectID,
+			"aud":            projectID,
+			"sub":            "kXl3sCuw2cYGbFfRXNjXvMp0i5m1",
+			"user_id":        "kXl3sCuw2cYGbFfRXNjXvMp0i5m1",
+			"auth_time":      now.Add(-time.Minute).Unix(),
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+			"email":          "jane@example.com",
+			"email_verified": true,
+			"name":           "Jane Doe",
+			"picture":        "https://example.com/jane.png",
+			"firebase": map[string]interface{}{
+				"identities":       map[string]interface{}{"google.com": []string{"118194327462862"}, "email": []string{"jane@example.com"}},
+				"sign_in_provider": "google.com",
+			},
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	_, err = provider.UserFromRequest(req)
+	a.Equal(firebase.ErrNoToken, err)
+
+	req.Header.Set("Authorization", "Bearer "+sign("f90fb1", claims()))
+	user, err := provider.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal("firebase", user.Provider)
+	a.Equal("kXl3sCuw2cYGbFfRXNjXvMp0i5m1", user.UserID)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("https://example.com/jane.png", user.AvatarURL)
+	a.Equal("google.com", firebase.SignInProvider(user))
+	a.Equal(now.Add(time.Hour), user.ExpiresAt)
+
+	c := claims()
+	c["aud"] = "other-project"
+	_, err = provider.UserFromIDToken(sign("f90fb1", c))
+	a.EqualError(err, "firebase: audience is incorrect")
+
+	c = claims()
+	c["iss"] = "https://securetoken.google.com/other-project"
+	_, err = provider.UserFromIDToken(sign("f90fb1", c))
+	a.EqualError(err, `firebase: issuer is incorrect: "https://securetoken.google.com/other-project"`)
+
+	c = claims()
+	c["auth_time"] = now.Add(time.Hour).Unix()
+	_, err = provider.UserFromIDToken(sign("f90fb1", c))
+	a.EqualError(err, "firebase: token authenticated in the future")
+
+	c = claims()
+	delete(c, "sub")
+	_, err = provider.UserFromIDToken(sign("f90fb1", c))
+	a.EqualError(err, "firebase: token has no subject")
+
+	// The certificates are kept for their max-age, a rotated key refetches
+	// them once.
+	a.Equal(1, fetches)
+	_, err = provider.UserFromIDToken(sign("rotated", claims()))
+	a.Error(err)
+	a.Equal(1, fetches)
+	now = now.Add(goth.KeySetMinRefresh)
+	_, err = provider.UserFromIDToken(sign("rotated", claims()))
+	a.Error(err)
+	a.Equal(2, fetches)
+}
@@ -0,0 +1,226 @@
+// Package flickr implements the OAuth protocol for authenticating users through Flickr.
+// Reference: https://www.flickr.com/services/api/auth.oauth.html
+package flickr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"github.com/mrjones/oauth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	requestURL   = "https://www.flickr.com/services/oauth/request_token"
+	authorizeURL = "https://www.flickr.com/services/oauth/authorize"
+	tokenURL     = "https://www.flickr.com/services/oauth/access_token"
+	endpointREST = "https://www.flickr.com/services/rest"
+)
+
+// Permissions an application can ask Flickr for, each one includes the
+// previous ones. PermsRead is asked for when Perms is empty.
+const (
+	PermsRead   = "read"
+	PermsWrite  = "write"
+	PermsDelete = "delete"
+)
+
+// New creates a new Flickr provider, and sets up important connection details.
+// You should always call `flickr.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "flickr",
+	}
+	p.consumer = newConsumer(p)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Flickr.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+
+	// Perms is the permission asked to the user, one of PermsRead,
+	// PermsWrite and PermsDelete.
+	Perms string
+
+	debug        bool
+	consumer     *oauth.Consumer
+	providerName string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	consumer := *p.consumer
+	consumer.HttpClient = goth.ClientFunc(c.Client)
+	c.consumer = &consumer
+	return &c
+}
+
+// Debug sets the logging of the OAuth client to verbose.
+func (p *Provider) Debug(debug bool) {
+	p.debug = debug
+}
+
+// BeginAuth asks Flickr for an authentication end-point and a request token for a session.
+// Flickr does not support the "state" variable.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	requestToken, authURL, err := p.consumer.GetRequestTokenAndUrl(p.CallbackURL)
+	if err == nil {
+		perms := p.Perms
+		if perms == "" {
+			perms = PermsRead
+		}
+		authURL += "&" + url.Values{"perms": {perms}}.Encode()
+	}
+	session := &Session{
+		AuthURL:      authURL,
+		RequestToken: requestToken,
+	}
+	return session, err
+}
+
+// FetchUser will go to Flickr and access basic information about the user.
+// Flickr does not share the email address of users.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		Provider: p.Name(),
+	}
+
+	if sess.AccessToken == nil {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	// The access token names the user it was granted by.
+	nsid := sess.AccessToken.AdditionalData["user_nsid"]
+	response, err := p.consumer.Get(
+		endpointREST,
+		map[string]string{
+			"method":         "flickr.people.getInfo",
+			"user_id":        nsid,
+			"format":         "json",
+			"nojsoncallback": "1",
+		},
+		sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	type content struct {
+		Content string `json:"_content"`
+	}
+	u := struct {
+		Person struct {
+			NSID        string      `json:"nsid"`
+			IconServer  string      `json:"iconserver"`
+			IconFarm    json.Number `json:"iconfarm"`
+			Username    content     `json:"username"`
+			RealName    content     `json:"realname"`
+			Location    content     `json:"location"`
+			Description content     `json:"description"`
+		} `json:"person"`
+		Stat    string `json:"stat"`
+		Message string `json:"message"`
+	}{}
+	if err = json.Unmarshal(bits, &u); err != nil {
+		return user, err
+	}
+	// Errors are answered with a 200 too.
+	if u.Stat != "ok" {
+		return user, fmt.Errorf("%s responded with an error: %s", p.providerName, u.Message)
+	}
+
+	raw := struct {
+		Person map[string]interface{} `json:"person"`
+	}{}
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&raw)
+	if err != nil {
+		return user, err
+	}
+
+	user.UserID = u.Person.NSID
+	user.NickName = u.Person.Username.Content
+	user.Name = u.Person.RealName.Content
+	user.Location = u.Person.Location.Content
+	user.Description = u.Person.Description.Content
+	user.AvatarURL = buddyIcon(u.Person.NSID, u.Person.IconServer, u.Person.IconFarm.String())
+	user.RawData = raw.Person
+	user.AccessToken = sess.AccessToken.Token
+	user.AccessTokenSecret = sess.AccessToken.Secret
+	return user, err
+}
+
+// buddyIcon returns the URL of the avatar of a user.
+// https://www.flickr.com/services/api/misc.buddyicons.html
+func buddyIcon(nsid, iconServer, iconFarm string) string {
+	if iconServer == "" || iconServer == "0" {
+		return "https://www.flickr.com/images/buddyicon.gif"
+	}
+	return fmt.Sprintf("https://farm%s.staticflickr.com/%s/buddyicons/%s.jpg", iconFarm, iconServer, nsid)
+}
+
+func newConsumer(provider *Provider) *oauth.Consumer {
+	c := oauth.NewConsumer(
+		provider.ClientKey,
+		provider.Secret,
+		oauth.ServiceProvider{
+			RequestTokenUrl:   requestURL,
+			AuthorizeTokenUrl: authorizeURL,
+			AccessTokenUrl:    tokenURL,
+		})
+
+	c.HttpClient = goth.ClientFunc(provider.Client)
+	c.Debug(provider.debug)
+	return c
+}
+
+// RefreshToken refresh token is not provided by Flickr
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Flickr")
+}
+
+// RefreshTokenAvailable refresh token is not provided by Flickr
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
@@ -0,0 +1,100 @@
+package flickr
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+	"github.com/mrjones/oauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := flickrProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), flickrProvider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := flickrProvider()
+	session, err := provider.BeginAuth("state")
+	s := session.(*Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "services/oauth/authorize?")
+	a.Contains(s.AuthURL, "oauth_token=TOKEN")
+	a.Contains(s.AuthURL, "perms=read")
+	a.Equal("TOKEN", s.RequestToken.Token)
+	a.Equal("SECRET", s.RequestToken.Secret)
+
+	provider.Perms = PermsWrite
+	session, err = provider.BeginAuth("state")
+	a.NoError(err)
+	a.Contains(session.(*Session).AuthURL, "perms=write")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := flickrProvider()
+	session := Session{AccessToken: &oauth.AccessToken{
+		Token:          "TOKEN",
+		Secret:         "SECRET",
+		AdditionalData: map[string]string{"user_nsid": "21207597@N07", "username": "jamalfanaian", "fullname": "Jamal Fanaian"},
+	}}
+
+	user, err := provider.FetchUser(&session)
+	a.NoError(err)
+
+	a.Equal("21207597@N07", user.UserID)
+	a.Equal("jamalfanaian", user.NickName)
+	a.Equal("Jamal Fanaian", user.Name)
+	a.Equal("Toronto, Canada", user.Location)
+	a.Equal("https://farm5.staticflickr.com/4062/buddyicons/21207597@N07.jpg", user.AvatarURL)
+	a.Equal("TOKEN", user.AccessToken)
+	a.Equal("SECRET", user.AccessTokenSecret)
+
+	session.AccessToken.AdditionalData["user_nsid"] = "unknown"
+	_, err = provider.FetchUser(&session)
+	a.EqualError(err, "flickr responded with an error: User not found")
+}
+
+func flickrProvider() *Provider {
+	return New("key", "secret", "/foo")
+}
+
+func init() {
+	e := echo.New()
+	e.GET("/services/oauth/request_token", func(c echo.Context) error {
+		fmt.Fprint(c.Response(), "oauth_callback_confirmed=true&oauth_token=TOKEN&oauth_token_secret=SECRET")
+		return nil
+	})
+	e.GET("/services/rest", func(c echo.Context) error {
+		if c.QueryParam("method") != "flickr.people.getInfo" || c.QueryParam("user_id") != "21207597@N07" {
+			fmt.Fprint(c.Response(), `{"stat":"fail","code":1,"message":"User not found"}`)
+			return nil
+		}
+		fmt.Fprint(c.Response(), `{"person":{"id":"21207597@N07","nsid":"21207597@N07","ispro":0,"iconserver":"4062","iconfarm":5,"path_alias":"jamalfanaian","username":{"_content":"jamalfanaian"},"realname":{"_content":"Jamal Fanaian"},"location":{"_content":"Toronto, Canada"},"description":{"_content":""}},"stat":"ok"}`)
+		return nil
+	})
+	ts := httptest.NewServer(e)
+
+	requestURL = ts.URL + "/services/oauth/request_token"
+	endpointREST = ts.URL + "/services/rest"
+}
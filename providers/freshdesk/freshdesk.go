@@ -0,0 +1,132 @@
+// Package freshdesk authenticates the agents of a Freshdesk helpdesk with their
+// API keys. Freshdesk has no OAuth for third party applications: agents find
+// their API key in their profile settings and hand it to the application, the
+// provider checks it and returns the agent it belongs to. The key is the
+// AccessToken of the user, the URL of the helpdesk RawData["instance_url"].
+//
+//	user, err := freshdesk.New("acme").UserFromAPIKey(c.FormValue("api_key"))
+//
+// Reference: https://developers.freshdesk.com/api/#me
+package freshdesk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// New creates a new Freshdesk provider for the helpdesk served from
+// https://<subdomain>.freshdesk.com.
+func New(subdomain string) *Provider {
+	return NewCustomisedURL("https://" + subdomain + ".freshdesk.com")
+}
+
+// NewCustomisedURL is similar to New(...) but takes the URL of the helpdesk,
+// for helpdesks served from a custom domain.
+func NewCustomisedURL(instanceURL string) *Provider {
+	instanceURL = strings.TrimSuffix(instanceURL, "/")
+	return &Provider{
+		InstanceURL:  instanceURL,
+		providerName: "freshdesk",
+		profileURL:   instanceURL + "/api/v2/agents/me",
+	}
+}
+
+// Provider checks Freshdesk API keys.
+type Provider struct {
+	InstanceURL  string
+	HTTPClient   *http.Client
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromAPIKey returns the agent apiKey belongs to. Its type, such as
+// support_agent, is RawData["type"].
+func (p *Provider) UserFromAPIKey(apiKey string) (goth.User, error) {
+	user := goth.User{
+		AccessToken: apiKey,
+		Provider:    p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	// The password is ignored by Freshdesk.
+	req.SetBasicAuth(apiKey, "X")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	if err == nil {
+		user.RawData["instance_url"] = p.InstanceURL
+	}
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID      int64 `json:"id"`
+		Contact struct {
+			Name     string `json:"name"`
+			Email    string `json:"email"`
+			JobTitle string `json:"job_title"`
+			Avatar   *struct {
+				AttachmentURL string `json:"attachment_url"`
+			} `json:"avatar"`
+		} `json:"contact"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = strconv.FormatInt(u.ID, 10)
+	user.Name = u.Contact.Name
+	user.Email = u.Contact.Email
+	user.Description = u.Contact.JobTitle
+	if u.Contact.Avatar != nil {
+		user.AvatarURL = u.Contact.Avatar.AttachmentURL
+	}
+	return nil
+}
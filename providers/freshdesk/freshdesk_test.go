@@ -0,0 +1,50 @@
+package freshdesk_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth/providers/freshdesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := freshdesk.New("acme")
+	a.Equal("freshdesk", provider.Name())
+	a.Equal("https://acme.freshdesk.com", provider.InstanceURL)
+}
+
+func Test_UserFromAPIKey(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key, _, _ := r.BasicAuth(); r.URL.Path != "/api/v2/agents/me" || key != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"available":true,"occasional":false,"id":19000012345,"ticket_scope":1,"type":"support_agent","contact":{"active":true,"email":"jane@example.com","job_title":"Support Lead","language":"en","name":"Jane Agent","time_zone":"Eastern Time (US & Canada)","avatar":null}}`)
+	}))
+	defer ts.Close()
+	provider := freshdesk.NewCustomisedURL(ts.URL + "/")
+
+	user, err := provider.UserFromAPIKey("key")
+	a.NoError(err)
+	a.Equal("freshdesk", user.Provider)
+	a.Equal("19000012345", user.UserID)
+	a.Equal("Jane Agent", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("Support Lead", user.Description)
+	a.Equal("key", user.AccessToken)
+	a.Equal("support_agent", user.RawData["type"])
+	a.Equal(ts.URL, user.RawData["instance_url"])
+
+	_, err = provider.UserFromAPIKey("other")
+	a.EqualError(err, "freshdesk responded with a 401 trying to fetch user information")
+	_, err = provider.UserFromAPIKey("")
+	a.Error(err)
+}
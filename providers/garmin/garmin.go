@@ -0,0 +1,160 @@
+// Package garmin implements the OAuth protocol for authenticating users through Garmin Connect.
+// Garmin only shares the id of the user, through the Health API of the
+// Garmin Connect Developer Program.
+// Reference: https://developer.garmin.com/gc-developer-program/overview/
+package garmin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"github.com/mrjones/oauth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	requestURL   = "https://connectapi.garmin.com/oauth-service/oauth/request_token"
+	authorizeURL = "https://connect.garmin.com/oauthConfirm"
+	tokenURL     = "https://connectapi.garmin.com/oauth-service/oauth/access_token"
+	endpointUser = "https://apis.garmin.com/wellness-api/rest/user/id"
+)
+
+// New creates a new Garmin provider, and sets up important connection details.
+// You should always call `garmin.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "garmin",
+	}
+	p.consumer = newConsumer(p)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Garmin.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	debug        bool
+	consumer     *oauth.Consumer
+	providerName string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	consumer := *p.consumer
+	consumer.HttpClient = goth.ClientFunc(c.Client)
+	c.consumer = &consumer
+	return &c
+}
+
+// Debug sets the logging of the OAuth client to verbose.
+func (p *Provider) Debug(debug bool) {
+	p.debug = debug
+}
+
+// BeginAuth asks Garmin for an authentication end-point and a request token for a session.
+// Garmin does not support the "state" variable.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	requestToken, authURL, err := p.consumer.GetRequestTokenAndUrl(p.CallbackURL)
+	session := &Session{
+		AuthURL:      authURL,
+		RequestToken: requestToken,
+	}
+	return session, err
+}
+
+// FetchUser will go to Garmin and access the id of the user, the only
+// information Garmin shares.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		Provider: p.Name(),
+	}
+
+	if sess.AccessToken == nil {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	response, err := p.consumer.Get(endpointUser, nil, sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	u := struct {
+		UserID string `json:"userId"`
+	}{}
+	if err = json.Unmarshal(bits, &u); err != nil {
+		return user, err
+	}
+	if err = json.Unmarshal(bits, &user.RawData); err != nil {
+		return user, err
+	}
+
+	user.UserID = u.UserID
+	user.AccessToken = sess.AccessToken.Token
+	user.AccessTokenSecret = sess.AccessToken.Secret
+	return user, err
+}
+
+func newConsumer(provider *Provider) *oauth.Consumer {
+	c := oauth.NewConsumer(
+		provider.ClientKey,
+		provider.Secret,
+		oauth.ServiceProvider{
+			RequestTokenUrl:   requestURL,
+			AuthorizeTokenUrl: authorizeURL,
+			AccessTokenUrl:    tokenURL,
+			HttpMethod:        "POST",
+		})
+
+	c.HttpClient = goth.ClientFunc(provider.Client)
+	c.Debug(provider.debug)
+	return c
+}
+
+// RefreshToken refresh token is not provided by Garmin
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Garmin")
+}
+
+// RefreshTokenAvailable refresh token is not provided by Garmin
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
@@ -0,0 +1,80 @@
+package garmin
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+	"github.com/mrjones/oauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := garminProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), garminProvider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := garminProvider()
+	session, err := provider.BeginAuth("state")
+	s := session.(*Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "connect.garmin.com/oauthConfirm?")
+	a.Contains(s.AuthURL, "oauth_token=TOKEN")
+	a.Equal("TOKEN", s.RequestToken.Token)
+	a.Equal("SECRET", s.RequestToken.Secret)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := garminProvider()
+	session := Session{AccessToken: &oauth.AccessToken{Token: "TOKEN", Secret: "SECRET"}}
+
+	user, err := provider.FetchUser(&session)
+	a.NoError(err)
+	a.Equal("d3315b1072421d0dd7c8f6b8e1de4df8", user.UserID)
+	a.Equal("TOKEN", user.AccessToken)
+	a.Equal("SECRET", user.AccessTokenSecret)
+
+	_, err = provider.FetchUser(&Session{})
+	a.Error(err)
+}
+
+func garminProvider() *Provider {
+	return New("key", "secret", "/foo")
+}
+
+func init() {
+	e := echo.New()
+	e.POST("/oauth-service/oauth/request_token", func(c echo.Context) error {
+		fmt.Fprint(c.Response(), "oauth_callback_confirmed=true&oauth_token=TOKEN&oauth_token_secret=SECRET")
+		return nil
+	})
+	e.GET("/wellness-api/rest/user/id", func(c echo.Context) error {
+		fmt.Fprint(c.Response(), `{"userId":"d3315b1072421d0dd7c8f6b8e1de4df8"}`)
+		return nil
+	})
+	ts := httptest.NewServer(e)
+
+	requestURL = ts.URL + "/oauth-service/oauth/request_token"
+	endpointUser = ts.URL + "/wellness-api/rest/user/id"
+}
@@ -0,0 +1,123 @@
+// Package gcpiap authenticates users of applications served behind Google Cloud Identity-Aware Proxy.
+// IAP signs in the users itself and passes their identity along with every
+// request in a signed JWT header, there is no OAuth dance: the provider
+// validates the header and turns its claims into a goth.User. Use it to load
+// the user of gothic.RequireAuth:
+//
+//	iap := gcpiap.New("/projects/123456789/global/backendServices/987654321")
+//	loader := gothic.UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+//		user, err := iap.UserFromRequest(c.Request())
+//		return user, err == nil, nil
+//	})
+//
+// Reference: https://cloud.google.com/iap/docs/signed-headers-howto
+package gcpiap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// HeaderAssertion is the header IAP passes the signed JWT in.
+const HeaderAssertion = "X-Goog-IAP-JWT-Assertion"
+
+// Issuer is the issuer of the JWTs signed by IAP.
+const Issuer = "https://cloud.google.com/iap"
+
+// KeysURL is where Google publishes the keys IAP signs the JWTs with.
+var KeysURL = "https://www.gstatic.com/iap/verify/public_key-jwk"
+
+// ErrNoAssertion is returned when a request has no HeaderAssertion, when it
+// did not go through IAP.
+var ErrNoAssertion = errors.New("gcpiap: request has no IAP assertion header")
+
+// ErrNoAudience is returned for every assertion while the provider has no
+// Audience, as IAP signs the assertions of every backend with the same keys.
+var ErrNoAudience = errors.New("gcpiap: the provider has no audience")
+
+// New creates a new IAP provider. audience is the audience of the JWTs of the
+// application, "/projects/PROJECT_NUMBER/global/backendServices/SERVICE_ID"
+// for load balancers, or "/projects/PROJECT_NUMBER/apps/PROJECT_ID" for App
+// Engine; it is shown on the IAP page of the console. It is required, without
+// it every assertion is refused with ErrNoAudience.
+func New(audience string) *Provider {
+	return &Provider{
+		Audience:     audience,
+		providerName: "gcpiap",
+		keys:         goth.NewKeySet(KeysURL, 12*time.Hour),
+	}
+}
+
+// Provider validates the JWTs signed by IAP.
+type Provider struct {
+	Audience     string
+	HTTPClient   *http.Client
+	providerName string
+	keys         *goth.KeySet
+}
+
+// Claims are the claims of the JWTs signed by IAP.
+type Claims struct {
+	jwt.RegisteredClaims
+	Email        string `json:"email"`
+	HostedDomain string `json:"hd"`
+	Google       struct {
+		AccessLevels []string `json:"access_levels"`
+	} `json:"google"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromRequest returns the user IAP authenticated the request for. It
+// returns ErrNoAssertion when the request did not go through IAP.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	assertion := r.Header.Get(HeaderAssertion)
+	if assertion == "" {
+		return goth.User{Provider: p.Name()}, ErrNoAssertion
+	}
+	return p.UserFromAssertion(assertion)
+}
+
+// UserFromAssertion validates a JWT signed by IAP, and returns the user it
+// was issued for. The claims are kept in RawData.
+func (p *Provider) UserFromAssertion(assertion string) (goth.User, error) {
+	user := goth.User{Provider: p.Name()}
+	if p.Audience == "" {
+		return user, ErrNoAudience
+	}
+
+	claims := &Claims{}
+	raw, err := idtoken.Validate(assertion, claims, p.keys.Keyfunc(p.Client()), []string{"ES256"}, Issuer, p.Audience)
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+
+	// The subject is prefixed with the identity provider, such as
+	// accounts.google.com:118194327462862.
+	user.UserID = claims.Subject
+	user.Email = claims.Email
+	user.RawData = raw
+	if claims.ExpiresAt != nil {
+		user.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return user, nil
+}
@@ -0,0 +1,113 @@
+package gcpiap_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/gcpiap"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+const audience = "/projects/123456789/global/backendServices/987654321"
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := gcpiap.New(audience)
+	a.Equal(audience, provider.Audience)
+	a.Equal("gcpiap", provider.Name())
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := jwk.New(&priv.PublicKey)
+		a.NoError(err)
+		a.NoError(key.Set(jwk.KeyIDKey, "0oeLcQ"))
+		set := jwk.NewSet()
+		set.Add(key)
+		a.NoError(json.NewEncoder(w).Encode(set))
+	}))
+	defer ts.Close()
+
+	gcpiap.KeysURL = ts.URL
+	defer func() { gcpiap.KeysURL = "https://www.gstatic.com/iap/verify/public_key-jwk" }()
+	provider := gcpiap.New(audience)
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = "0oeLcQ"
+		signed, err := token.SignedString(key)
+		a.NoError(err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    gcpiap.Issuer,
+			"aud":    audience,
+			"sub":    "accounts.google.com:118194327462862",
+			"email":  "jane@example.com",
+			"hd":     "example.com",
+			"iat":    now.Unix(),
+			"exp":    now.Add(10 * time.Minute).Unix(),
+			"google": map[string]interface{}{"access_levels": []string{"accessPolicies/1/accessLevels/corp"}},
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	_, err = provider.UserFromRequest(req)
+	a.Equal(gcpiap.ErrNoAssertion, err)
+
+	req.Header.Set(gcpiap.HeaderAssertion, sign(jwt.SigningMethodES256, priv, claims()))
+	user, err := provider.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal("gcpiap", user.Provider)
+	a.Equal("accounts.google.com:118194327462862", user.UserID)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("example.com", user.RawData["hd"])
+	a.Equal(now.Add(10*time.Minute), user.ExpiresAt)
+
+	c := claims()
+	c["aud"] = "/projects/123456789/apps/other"
+	_, err = provider.UserFromAssertion(sign(jwt.SigningMethodES256, priv, c))
+	a.EqualError(err, "gcpiap: audience is incorrect")
+
+	c = claims()
+	c["iss"] = "https://accounts.google.com"
+	_, err = provider.UserFromAssertion(sign(jwt.SigningMethodES256, priv, c))
+	a.EqualError(err, `gcpiap: issuer is incorrect: "https://accounts.google.com"`)
+
+	c = claims()
+	c["exp"] = now.Add(-time.Minute).Unix()
+	_, err = provider.UserFromAssertion(sign(jwt.SigningMethodES256, priv, c))
+	a.EqualError(err, "gcpiap: token is expired")
+
+	// Tokens signed with another key, or algorithm, are rejected.
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	_, err = provider.UserFromAssertion(sign(jwt.SigningMethodES256, other, claims()))
+	a.Error(err)
+	_, err = provider.UserFromAssertion(sign(jwt.SigningMethodHS256, []byte("secret"), claims()))
+	a.Error(err)
+
+	// Without an audience, the assertions of any backend would be accepted.
+	_, err = gcpiap.New("").UserFromAssertion(sign(jwt.SigningMethodES256, priv, claims()))
+	a.Equal(gcpiap.ErrNoAudience, err)
+}
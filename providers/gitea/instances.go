@@ -0,0 +1,21 @@
+package gitea
+
+import "github.com/bgdsh/goth"
+
+// NewInstances returns the goth.Instances of Gitea, for applications whose
+// users log in with their own Gitea or Forgejo server. Gitea has no dynamic
+// client registration: credentials returns the OAuth2 application registered
+// on each server.
+func NewInstances(callbackURL string, credentials goth.InstanceCredentials, scopes ...string) *goth.Instances {
+	return goth.NewInstances("gitea", func(instanceURL string) (goth.Provider, error) {
+		clientKey, secret, err := credentials(instanceURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewCustomisedURL(clientKey, secret, callbackURL,
+			instanceURL+"/login/oauth/authorize",
+			instanceURL+"/login/oauth/access_token",
+			instanceURL+"/api/v1/user",
+			scopes...), nil
+	})
+}
@@ -21,6 +21,7 @@ import (
 // using GitHub enterprise you should change these values before calling New.
 //
 // Examples:
+//
 //	github.AuthURL = "https://github.acme.com/login/oauth/authorize
 //	github.TokenURL = "https://github.acme.com/login/oauth/access_token
 //	github.ProfileURL = "https://github.acme.com/api/v3/user
@@ -59,6 +60,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 	profileURL   string
@@ -79,14 +81,36 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the github package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Github for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state, pkce...)
 	session := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return session, nil
 }
@@ -117,7 +141,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("GitHub API responded with a %d trying to fetch user information", response.StatusCode)
+		return user, goth.NewProviderAPIError("GitHub API", "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -142,6 +166,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 				if err != nil {
 					return user, err
 				}
+				user.EmailVerified = true
 				break
 			}
 		}
@@ -189,7 +214,7 @@ func getPrivateMail(p *Provider, sess *Session) (email string, err error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return email, fmt.Errorf("GitHub API responded with a %d trying to fetch user email", response.StatusCode)
+		return email, goth.NewProviderAPIError("GitHub API", "trying to fetch user email", response)
 	}
 
 	var mailList = []struct {
@@ -237,3 +262,33 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 func (p *Provider) RefreshTokenAvailable() bool {
 	return false
 }
+
+// RevokeToken revokes an access token of the application. The API of GitHub
+// Enterprise is found from the profile URL of the provider. Tokens GitHub does
+// not know, such as already revoked ones, are not an error.
+// See https://docs.github.com/en/rest/apps/oauth-applications#delete-an-app-token
+func (p *Provider) RevokeToken(token string) error {
+	body, err := json.Marshal(map[string]string{"access_token": token})
+	if err != nil {
+		return err
+	}
+	apiURL := strings.TrimSuffix(p.profileURL, "/user")
+	req, err := http.NewRequest("DELETE", apiURL+"/applications/"+p.ClientKey+"/token", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.ClientKey, p.Secret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return goth.NewProviderAPIError(p.providerName, "trying to revoke the token", resp)
+	}
+	return nil
+}
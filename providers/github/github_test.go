@@ -1,7 +1,11 @@
 package github_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
@@ -51,6 +55,34 @@ func Test_BeginAuth(t *testing.T) {
 	a.Contains(s.AuthURL, "scope=user")
 }
 
+func Test_BeginAuthWithPKCE(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("auth_code", r.PostForm.Get("code"))
+		a.NotEmpty(r.PostForm.Get("code_verifier"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"1234567890","token_type":"bearer"}`)
+	}))
+	defer ts.Close()
+
+	p := github.NewCustomisedURL("key", "secret", "/foo", "http://authURL", ts.URL, "http://profileURL", "http://emailURL")
+	p.PKCE = true
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*github.Session)
+	a.NotEmpty(s.CodeVerifier)
+	a.Contains(s.AuthURL, "code_challenge="+goth.CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	token, err := s.Authorize(p, url.Values{"code": {"auth_code"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Empty(s.CodeVerifier)
+}
+
 func Test_SessionFromJSON(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -64,6 +96,27 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(session.AccessToken, "1234567890")
 }
 
+func Test_RevokeToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("DELETE", r.Method)
+		a.Equal("/api/v3/applications/key/token", r.URL.Path)
+		id, secret, _ := r.BasicAuth()
+		a.Equal("key", id)
+		a.Equal("secret", secret)
+		body := map[string]string{}
+		a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		a.Equal("1234567890", body["access_token"])
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	p := github.NewCustomisedURL("key", "secret", "/foo", "http://authURL", "http://tokenURL", ts.URL+"/api/v3/user", ts.URL+"/api/v3/user/emails")
+	a.NoError(goth.RevokeToken(p, "1234567890"))
+}
+
 func githubProvider() *github.Provider {
 	return github.New(os.Getenv("GITHUB_KEY"), os.Getenv("GITHUB_SECRET"), "/foo", "user")
 }
@@ -46,3 +46,12 @@ func Test_String(t *testing.T) {
 
 	a.Equal(s.String(), s.Marshal())
 }
+
+func Benchmark_Marshal(b *testing.B) {
+	s := &github.Session{AuthURL: "https://github.com/login/oauth/authorize?state=state", AccessToken: "1234567890"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = s.Marshal()
+	}
+}
@@ -21,6 +21,7 @@ import (
 // using Gitlab CE or EE, you should change these values before calling New.
 //
 // Examples:
+//
 //	gitlab.AuthURL = "https://gitlab.acme.com/oauth/authorize
 //	gitlab.TokenURL = "https://gitlab.acme.com/oauth/token
 //	gitlab.ProfileURL = "https://gitlab.acme.com/api/v3/user
@@ -36,6 +37,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 	authURL      string
@@ -77,13 +79,35 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the gitlab package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Gitlab for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -113,7 +137,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -155,6 +179,7 @@ func userFromReader(r io.Reader, user *goth.User) error {
 	u := struct {
 		Name      string `json:"name"`
 		Email     string `json:"email"`
+		Confirmed string `json:"confirmed_at"`
 		NickName  string `json:"username"`
 		ID        int    `json:"id"`
 		AvatarURL string `json:"avatar_url"`
@@ -164,6 +189,8 @@ func userFromReader(r io.Reader, user *goth.User) error {
 		return err
 	}
 	user.Email = u.Email
+	// The email is the primary one, confirmed along with the account.
+	user.EmailVerified = u.Confirmed != ""
 	user.Name = u.Name
 	user.NickName = u.NickName
 	user.UserID = strconv.Itoa(u.ID)
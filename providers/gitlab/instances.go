@@ -0,0 +1,21 @@
+package gitlab
+
+import "github.com/bgdsh/goth"
+
+// NewInstances returns the goth.Instances of GitLab, for applications whose
+// users log in with gitlab.com or their self-managed GitLab. GitLab has no
+// dynamic client registration: credentials returns the application
+// registered on each instance.
+func NewInstances(callbackURL string, credentials goth.InstanceCredentials, scopes ...string) *goth.Instances {
+	return goth.NewInstances("gitlab", func(instanceURL string) (goth.Provider, error) {
+		clientKey, secret, err := credentials(instanceURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewCustomisedURL(clientKey, secret, callbackURL,
+			instanceURL+"/oauth/authorize",
+			instanceURL+"/oauth/token",
+			instanceURL+"/api/v4/user",
+			scopes...), nil
+	})
+}
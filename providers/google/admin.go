@@ -0,0 +1,114 @@
+package google
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+)
+
+// Scopes of the Directory API of the Google Admin SDK. They are only of use to
+// administrators of a Google Workspace or Cloud Identity account.
+// See https://developers.google.com/admin-sdk/directory/v1/guides/authorizing
+const (
+	ScopeAdminDirectoryUser                   = "https://www.googleapis.com/auth/admin.directory.user"
+	ScopeAdminDirectoryUserReadonly           = "https://www.googleapis.com/auth/admin.directory.user.readonly"
+	ScopeAdminDirectoryGroup                  = "https://www.googleapis.com/auth/admin.directory.group"
+	ScopeAdminDirectoryGroupReadonly          = "https://www.googleapis.com/auth/admin.directory.group.readonly"
+	ScopeAdminDirectoryGroupMember            = "https://www.googleapis.com/auth/admin.directory.group.member"
+	ScopeAdminDirectoryOrgUnit                = "https://www.googleapis.com/auth/admin.directory.orgunit"
+	ScopeAdminDirectoryOrgUnitReadonly        = "https://www.googleapis.com/auth/admin.directory.orgunit.readonly"
+	ScopeAdminDirectoryDomainReadonly         = "https://www.googleapis.com/auth/admin.directory.domain.readonly"
+	ScopeAdminDirectoryCustomerReadonly       = "https://www.googleapis.com/auth/admin.directory.customer.readonly"
+	ScopeAdminDirectoryRoleManagementReadonly = "https://www.googleapis.com/auth/admin.directory.rolemanagement.readonly"
+)
+
+// Scope presets for admin consoles, pass them to New along with the scopes
+// identifying the user:
+//
+//	google.New(key, secret, callbackURL, append([]string{"openid", "email", "profile"}, google.AdminDirectoryReadonlyScopes...)...)
+var (
+	// AdminDirectoryReadonlyScopes read the users, groups and organisational
+	// units of the account.
+	AdminDirectoryReadonlyScopes = []string{
+		ScopeAdminDirectoryUserReadonly,
+		ScopeAdminDirectoryGroupReadonly,
+		ScopeAdminDirectoryOrgUnitReadonly,
+		ScopeAdminDirectoryDomainReadonly,
+		ScopeAdminDirectoryCustomerReadonly,
+	}
+	// AdminDirectoryScopes manage the users, groups and organisational units
+	// of the account.
+	AdminDirectoryScopes = []string{
+		ScopeAdminDirectoryUser,
+		ScopeAdminDirectoryGroup,
+		ScopeAdminDirectoryGroupMember,
+		ScopeAdminDirectoryOrgUnit,
+		ScopeAdminDirectoryDomainReadonly,
+		ScopeAdminDirectoryCustomerReadonly,
+	}
+)
+
+// AdminDirectoryURL is the end-point CheckAdminDirectory lists a user of the
+// account of the administrator with.
+var AdminDirectoryURL = "https://admin.googleapis.com/admin/directory/v1/users?customer=my_customer&maxResults=1"
+
+// CheckScopes returns a *goth.ConsentRequiredError when scopes were not all
+// granted to the session, as users can leave out some of the scopes on the
+// consent screen of Google. Sessions created before the granted scopes were
+// recorded pass.
+func (p *Provider) CheckScopes(session goth.Session, scopes ...string) error {
+	sess := session.(*Session)
+	if len(sess.Scopes) == 0 {
+		return nil
+	}
+	if missing := goth.MissingScopes(sess.Scopes, scopes...); len(missing) > 0 {
+		return &goth.ConsentRequiredError{Provider: p.providerName, Scopes: missing}
+	}
+	return nil
+}
+
+// CheckAdminDirectory checks that the session can read the directory of the
+// account of the user. A *goth.ConsentRequiredError, with AdminConsent set, is
+// returned when Google refuses it: the user is not an administrator, or the
+// Admin SDK is not enabled for the account.
+func (p *Provider) CheckAdminDirectory(session goth.Session) error {
+	sess := session.(*Session)
+	if sess.AccessToken == "" {
+		return fmt.Errorf("%s cannot check the directory without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", AdminDirectoryURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		b, _ := ioutil.ReadAll(resp.Body)
+		e := struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}{}
+		_ = json.Unmarshal(b, &e)
+		return &goth.ConsentRequiredError{
+			Provider:     p.providerName,
+			AdminConsent: true,
+			Description:  e.Error.Message,
+		}
+	default:
+		return goth.NewProviderAPIError(p.providerName, "trying to read the directory", resp)
+	}
+}
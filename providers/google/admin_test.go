@@ -0,0 +1,64 @@
+package google_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/google"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func Test_AdminDirectory(t *testing.T) {
+	a := assert.New(t)
+
+	admin := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			fmt.Fprintf(w, `{"access_token":"token","token_type":"Bearer","expires_in":3599,"id_token":"id","scope":"email %s"}`, google.ScopeAdminDirectoryUserReadonly)
+		case "/admin/directory/v1/users":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			if !admin {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"error":{"code":403,"message":"Not Authorized to access this resource/api"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"kind":"admin#directory#users","users":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	defer func(endpoint oauth2.Endpoint, directoryURL string) {
+		google.Endpoint = endpoint
+		google.AdminDirectoryURL = directoryURL
+	}(google.Endpoint, google.AdminDirectoryURL)
+	google.Endpoint = oauth2.Endpoint{AuthURL: ts.URL + "/auth", TokenURL: ts.URL + "/token"}
+	google.AdminDirectoryURL = ts.URL + "/admin/directory/v1/users?customer=my_customer&maxResults=1"
+	provider := google.New(os.Getenv("GOOGLE_KEY"), os.Getenv("GOOGLE_SECRET"), "/foo", append([]string{"email"}, google.AdminDirectoryReadonlyScopes...)...)
+
+	session, _ := provider.BeginAuth("state")
+	_, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal([]string{"email", google.ScopeAdminDirectoryUserReadonly}, session.(*google.Session).Scopes)
+
+	a.NoError(provider.CheckScopes(session, google.ScopeAdminDirectoryUserReadonly))
+	err = provider.CheckScopes(session, google.AdminDirectoryReadonlyScopes...)
+	a.True(errors.Is(err, goth.ErrConsentRequired))
+	a.Equal(google.AdminDirectoryReadonlyScopes[1:], err.(*goth.ConsentRequiredError).Scopes)
+
+	a.NoError(provider.CheckAdminDirectory(session))
+	admin = false
+	err = provider.CheckAdminDirectory(session)
+	a.True(errors.Is(err, goth.ErrConsentRequired))
+	a.EqualError(err, "google: admin consent required: Not Authorized to access this resource/api")
+}
@@ -12,10 +12,18 @@ import (
 	"strings"
 
 	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
 	"golang.org/x/oauth2"
 )
 
-const endpointProfile string = "https://www.googleapis.com/oauth2/v2/userinfo"
+const (
+	endpointProfile string = "https://www.googleapis.com/oauth2/v2/userinfo"
+	endpointSTS     string = "https://sts.googleapis.com/v1/token"
+	endpointRevoke  string = "https://oauth2.googleapis.com/revoke"
+)
+
+// KeysURL is where Google publishes the keys it signs its ID tokens with.
+var KeysURL = "https://www.googleapis.com/oauth2/v3/certs"
 
 // New creates a new Google provider, and sets up important connection details.
 // You should always call `google.New` to get a new Provider. Never try to create
@@ -46,6 +54,7 @@ type Provider struct {
 	Secret          string
 	CallbackURL     string
 	HTTPClient      *http.Client
+	PKCE            bool
 	config          *oauth2.Config
 	authCodeOptions []oauth2.AuthCodeOption
 	providerName    string
@@ -66,14 +75,36 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the google package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Google for an authentication endpoint.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state, p.authCodeOptions...)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state, append(pkce, p.authCodeOptions...)...)
 	session := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return session, nil
 }
@@ -81,6 +112,7 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 type googleUser struct {
 	ID        string `json:"id"`
 	Email     string `json:"email"`
+	Verified  bool   `json:"verified_email"`
 	Name      string `json:"name"`
 	FirstName string `json:"given_name"`
 	LastName  string `json:"family_name"`
@@ -104,6 +136,16 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
+	// The ID token only comes with the openid, email and profile scopes.
+	subject := ""
+	if sess.IDToken != "" {
+		claims, err := p.verifier().Verify(p.Client(), sess.IDToken, "")
+		if err != nil {
+			return user, fmt.Errorf("%s: %w", p.providerName, err)
+		}
+		subject = claims.Subject
+	}
+
 	response, err := p.Client().Get(endpointProfile + "?access_token=" + url.QueryEscape(sess.AccessToken))
 	if err != nil {
 		return user, err
@@ -111,7 +153,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	responseBytes, err := ioutil.ReadAll(response.Body)
@@ -123,6 +165,9 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	if err := json.Unmarshal(responseBytes, &u); err != nil {
 		return user, err
 	}
+	if subject != "" && subject != u.ID {
+		return user, fmt.Errorf("%s: user info is not about the subject of the ID token", p.providerName)
+	}
 
 	// Extract the user data we got from Google into our goth.User.
 	user.Name = u.Name
@@ -130,6 +175,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	user.LastName = u.LastName
 	user.NickName = u.Name
 	user.Email = u.Email
+	user.EmailVerified = u.Verified
 	user.AvatarURL = u.Picture
 	user.UserID = u.ID
 	// Google provides other useful fields such as 'hd'; get them from RawData
@@ -140,6 +186,15 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, nil
 }
 
+// verifier validates the ID tokens Google issues to the client.
+func (p *Provider) verifier() *idtoken.Verifier {
+	return &idtoken.Verifier{
+		KeysURL:  KeysURL,
+		ClientID: p.ClientKey,
+		Issuers:  []string{"https://accounts.google.com", "accounts.google.com"},
+	}
+}
+
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 	c := &oauth2.Config{
 		ClientID:     provider.ClientKey,
@@ -213,3 +268,32 @@ func (p *Provider) SetAccessType(at string) {
 	}
 	p.authCodeOptions = append(p.authCodeOptions, oauth2.SetAuthURLParam("access_type", at))
 }
+
+// TokenExchange trades subjectToken for a Google access token using the
+// Security Token Service, which implements OAuth 2.0 Token Exchange (RFC 8693).
+// The audience is typically the full resource name of a workload identity
+// pool provider.
+// See https://cloud.google.com/iam/docs/reference/sts/rest/v1/TopLevel/token
+func (p *Provider) TokenExchange(subjectToken, audience string, scopes ...string) (*oauth2.Token, error) {
+	if len(scopes) == 0 {
+		scopes = []string{"https://www.googleapis.com/auth/cloud-platform"}
+	}
+	return goth.ExchangeToken(p.Client(), goth.TokenExchangeRequest{
+		TokenURL:           endpointSTS,
+		SubjectToken:       subjectToken,
+		SubjectTokenType:   goth.TokenTypeJWT,
+		RequestedTokenType: goth.TokenTypeAccessToken,
+		Audience:           audience,
+		Scopes:             scopes,
+	})
+}
+
+// RevokeToken revokes an access or refresh token, and with it the grant the
+// user gave the application.
+// See https://developers.google.com/identity/protocols/oauth2/web-server#tokenrevoke
+func (p *Provider) RevokeToken(token string) error {
+	return goth.Revoke(p.Client(), goth.RevocationRequest{
+		RevocationURL: endpointRevoke,
+		Token:         token,
+	})
+}
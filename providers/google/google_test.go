@@ -10,6 +10,16 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func init() {
+	// google.New refuses to build a provider without credentials.
+	if os.Getenv("GOOGLE_KEY") == "" {
+		os.Setenv("GOOGLE_KEY", "google-key")
+	}
+	if os.Getenv("GOOGLE_SECRET") == "" {
+		os.Setenv("GOOGLE_SECRET", "google-secret")
+	}
+}
+
 func Test_New(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -116,5 +126,5 @@ func Test_SessionFromJSON(t *testing.T) {
 }
 
 func googleProvider() *google.Provider {
-	return google.New(os.Getenv("GOOGLE_KEY"), os.Getenv("GOOGEL_SECRET"), "/foo")
+	return google.New(os.Getenv("GOOGLE_KEY"), os.Getenv("GOOGLE_SECRET"), "/foo")
 }
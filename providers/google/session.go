@@ -16,6 +16,9 @@ type Session struct {
 	RefreshToken string
 	ExpiresAt    time.Time
 	IDToken      string
+	// Scopes are the scopes granted to the application.
+	Scopes       []string `json:",omitempty"`
+	CodeVerifier string   `json:",omitempty"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the Google provider.
@@ -29,7 +32,7 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Google and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -42,6 +45,10 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
 	s.IDToken = token.Extra("id_token").(string)
+	if scope, ok := token.Extra("scope").(string); ok {
+		s.Scopes = strings.Fields(scope)
+	}
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
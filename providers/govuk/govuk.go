@@ -0,0 +1,277 @@
+// Package govuk implements the OpenID Connect protocol for authenticating
+// users through GOV.UK One Login. Applications authenticate with
+// private_key_jwt: the token requests are signed with the PrivateKey of the
+// application, whose public key is registered with GOV.UK One Login. The ID
+// token is validated, with its nonce and vector of trust, against the keys of
+// GOV.UK One Login.
+// Reference: https://docs.sign-in.service.gov.uk/integrate-with-integration-environment/
+package govuk
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// Environments of GOV.UK One Login, New uses ProductionURL.
+const (
+	ProductionURL  = "https://oidc.account.gov.uk/"
+	IntegrationURL = "https://oidc.integration.account.gov.uk/"
+)
+
+// Vectors of trust of GOV.UK One Login, VectorMediumConfidence is requested
+// unless VectorOfTrust is set.
+const (
+	VectorLowConfidence    = "Cl"
+	VectorMediumConfidence = "Cl.Cm"
+)
+
+// Scopes of GOV.UK One Login, ScopeOpenID and ScopeEmail are requested when no
+// scopes are given.
+const (
+	ScopeOpenID = "openid"
+	ScopeEmail  = "email"
+	ScopePhone  = "phone"
+)
+
+// New creates a new GOV.UK One Login provider, and sets up important connection
+// details. privateKey, an *rsa.PrivateKey or *ecdsa.PrivateKey, signs the token
+// requests.
+// You should always call `govuk.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientID string, privateKey interface{}, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientID, privateKey, callbackURL, ProductionURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set the
+// environment of GOV.UK One Login, such as IntegrationURL.
+func NewCustomisedURL(clientID string, privateKey interface{}, callbackURL, oneLoginURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeOpenID, ScopeEmail}
+	}
+	if !strings.HasSuffix(oneLoginURL, "/") {
+		oneLoginURL += "/"
+	}
+	p := &Provider{
+		ClientKey:     clientID,
+		PrivateKey:    privateKey,
+		CallbackURL:   callbackURL,
+		VectorOfTrust: VectorMediumConfidence,
+		providerName:  "govuk",
+		issuer:        oneLoginURL,
+		profileURL:    oneLoginURL + "userinfo",
+		keys:          goth.NewKeySet(oneLoginURL+".well-known/jwks.json", 12*time.Hour),
+	}
+	p.config = newConfig(p, oneLoginURL, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing GOV.UK One Login.
+type Provider struct {
+	ClientKey string
+	// PrivateKey signs the token requests.
+	PrivateKey interface{}
+	// KeyID is the kid of PrivateKey, if any.
+	KeyID       string
+	CallbackURL string
+	// VectorOfTrust is the level of authentication requested.
+	VectorOfTrust string
+	HTTPClient    *http.Client
+	PKCE          bool
+	config        *oauth2.Config
+	providerName  string
+	issuer        string
+	profileURL    string
+	keys          *goth.KeySet
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the govuk package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks GOV.UK One Login for an authentication end-point, with a new
+// nonce.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	vtr, _ := json.Marshal([]string{p.VectorOfTrust})
+	opts := append(pkce,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("vtr", string(vtr)),
+	)
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, opts...),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// tokenOptions returns the options authenticating the application on the
+// token request.
+func (p *Provider) tokenOptions() ([]oauth2.AuthCodeOption, error) {
+	assertion, err := goth.ClientAssertion(p.ClientKey, p.config.Endpoint.TokenURL, p.PrivateKey, p.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	return goth.ClientAssertionOptions(assertion), nil
+}
+
+// idTokenClaims are the claims of the ID tokens of GOV.UK One Login checked
+// by validateIDToken.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce string `json:"nonce"`
+	VOT   string `json:"vot"`
+}
+
+// validateIDToken checks the signature and the claims of idToken, which must
+// carry nonce and the vector of trust requested.
+func (p *Provider) validateIDToken(idToken, nonce string) error {
+	claims := &idTokenClaims{}
+	if _, err := idtoken.Validate(idToken, claims, p.keys.Keyfunc(p.Client()), []string{"ES256", "RS256"}, p.issuer, p.ClientKey); err != nil {
+		return fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return fmt.Errorf("%s: nonce is incorrect", p.providerName)
+	}
+	if claims.VOT != p.VectorOfTrust {
+		return fmt.Errorf("%s: vector of trust is incorrect: %q", p.providerName, claims.VOT)
+	}
+	return nil
+}
+
+// FetchUser will go to GOV.UK One Login and access the attributes of the user
+// allowed by the scopes.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+		IDToken:     sess.IDToken,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Sub   string `json:"sub"`
+		Email string `json:"email"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.Sub
+	user.Email = u.Email
+	return nil
+}
+
+func newConfig(provider *Provider, oneLoginURL string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:    provider.ClientKey,
+		RedirectURL: provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   oneLoginURL + "authorize",
+			TokenURL:  oneLoginURL + "token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by GOV.UK One Login")
+}
@@ -0,0 +1,124 @@
+package govuk_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/govuk"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), govukProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := govukProvider()
+	a.Equal(provider.ClientKey, "client")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.VectorOfTrust, govuk.VectorMediumConfidence)
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := govukProvider().BeginAuth("test_state")
+	s := session.(*govuk.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://oidc.account.gov.uk/authorize")
+	a.Contains(s.AuthURL, "client_id=client")
+	a.Contains(s.AuthURL, "scope=openid+email")
+	a.Contains(s.AuthURL, "nonce="+s.Nonce)
+	a.Contains(s.AuthURL, "vtr=%5B%22Cl.Cm%22%5D")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	appKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	idpKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+
+	var ts *httptest.Server
+	nonce, vot := "", "Cl.Cm"
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/jwks.json":
+			key, err := jwk.New(&idpKey.PublicKey)
+			a.NoError(err)
+			a.NoError(key.Set(jwk.KeyIDKey, "idp"))
+			set := jwk.NewSet()
+			set.Add(key)
+			a.NoError(json.NewEncoder(w).Encode(set))
+		case "/token":
+			a.NoError(r.ParseForm())
+			a.Equal(goth.ClientAssertionType, r.PostForm.Get("client_assertion_type"))
+			_, err := jwt.Parse(r.PostForm.Get("client_assertion"), func(*jwt.Token) (interface{}, error) {
+				return &appKey.PublicKey, nil
+			})
+			a.NoError(err)
+
+			token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+				"iss":   ts.URL + "/",
+				"aud":   "client",
+				"sub":   "urn:fdc:gov.uk:2022:56P4CMsGh_02YOlWpd8PAOI-2sVlB2nsNU7mcLZYhYw=",
+				"exp":   time.Now().Add(time.Hour).Unix(),
+				"nonce": nonce,
+				"vot":   vot,
+			})
+			token.Header["kid"] = "idp"
+			idToken, err := token.SignedString(idpKey)
+			a.NoError(err)
+			fmt.Fprintf(w, `{"access_token":"token","token_type":"Bearer","expires_in":180,"id_token":%q}`, idToken)
+		case "/userinfo":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"sub":"urn:fdc:gov.uk:2022:56P4CMsGh_02YOlWpd8PAOI-2sVlB2nsNU7mcLZYhYw=","email":"test@example.com","email_verified":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := govuk.NewCustomisedURL("client", appKey, "/foo", ts.URL)
+
+	session, err := provider.BeginAuth("state")
+	a.NoError(err)
+	nonce = session.(*govuk.Session).Nonce
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("urn:fdc:gov.uk:2022:56P4CMsGh_02YOlWpd8PAOI-2sVlB2nsNU7mcLZYhYw=", user.UserID)
+	a.Equal("test@example.com", user.Email)
+
+	session, _ = provider.BeginAuth("state")
+	nonce, vot = session.(*govuk.Session).Nonce, "Cl"
+	_, err = session.Authorize(provider, url.Values{"code": {"code"}})
+	a.EqualError(err, `govuk: vector of trust is incorrect: "Cl"`)
+}
+
+func govukProvider() *govuk.Provider {
+	return govuk.New("client", nil, "/foo")
+}
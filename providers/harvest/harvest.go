@@ -0,0 +1,280 @@
+// Package harvest implements the OAuth2 protocol for authenticating users through Harvest.
+// Reference: https://help.getharvest.com/api-v2/authentication-api/authentication/authentication/
+package harvest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL     = "https://id.getharvest.com/oauth2/authorize"
+	TokenURL    = "https://id.getharvest.com/api/v2/oauth2/token"
+	AccountsURL = "https://id.getharvest.com/api/v2/accounts"
+	APIURL      = "https://api.harvestapp.com/v2"
+)
+
+// HeaderAccountID is the header naming the account every call to the Harvest
+// API is made for.
+const HeaderAccountID = "Harvest-Account-Id"
+
+// New creates a new Harvest provider, and sets up important connection details.
+// You should always call `harvest.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "harvest",
+		accountsURL:  AccountsURL,
+		apiURL:       APIURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Harvest.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+	PKCE        bool
+
+	// AccountID, when set, restricts logins to the users of that Harvest
+	// account, whose profile is then fetched from the Harvest API.
+	AccountID string
+
+	config       *oauth2.Config
+	providerName string
+	accountsURL  string
+	apiURL       string
+}
+
+// Account is a Harvest or Forecast account the user has access to.
+type Account struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Product string `json:"product"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the harvest package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Harvest for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Harvest and access basic information about the user,
+// and the accounts the user has access to. Get these with Accounts.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	body, err := p.get(p.accountsURL, sess.AccessToken, "")
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+	if err := userFromReader(body, &user); err != nil {
+		return user, err
+	}
+
+	if p.AccountID == "" {
+		return user, nil
+	}
+
+	accounts, err := Accounts(user)
+	if err != nil {
+		return user, err
+	}
+	member := false
+	for _, a := range accounts {
+		if strconv.FormatInt(a.ID, 10) == p.AccountID {
+			member = true
+		}
+	}
+	if !member {
+		return user, fmt.Errorf("%s: user has no access to account %s", p.providerName, p.AccountID)
+	}
+
+	body, err = p.get(p.apiURL+"/users/me", sess.AccessToken, p.AccountID)
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+
+	me := struct {
+		AvatarURL string `json:"avatar_url"`
+		Timezone  string `json:"timezone"`
+	}{}
+	if err := json.NewDecoder(body).Decode(&me); err != nil {
+		return user, err
+	}
+	user.AvatarURL = me.AvatarURL
+	user.RawData["timezone"] = me.Timezone
+	return user, nil
+}
+
+// get calls endpoint with the access token of the user, for the account
+// accountID when it is not empty.
+func (p *Provider) get(endpoint, accessToken, accountID string) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	req.Header.Add("User-Agent", "goth")
+	if accountID != "" {
+		req.Header.Add(HeaderAccountID, accountID)
+	}
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+	return resp.Body, nil
+}
+
+// Accounts returns the accounts of a user fetched by FetchUser. Calls to the
+// Harvest API must name one of them in the HeaderAccountID header.
+func Accounts(user goth.User) ([]Account, error) {
+	raw, ok := user.RawData["accounts"]
+	if !ok {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []Account
+	err = json.Unmarshal(b, &accounts)
+	return accounts, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		User struct {
+			ID        int64  `json:"id"`
+			FirstName string `json:"first_name"`
+			LastName  string `json:"last_name"`
+			Email     string `json:"email"`
+		} `json:"user"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = strconv.FormatInt(u.User.ID, 10)
+	user.FirstName = u.User.FirstName
+	user.LastName = u.User.LastName
+	user.Name = strings.TrimSpace(u.User.FirstName + " " + u.User.LastName)
+	user.Email = u.User.Email
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,101 @@
+package harvest_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/harvest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), harvestProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := harvestProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := harvestProvider().BeginAuth("test_state")
+	s := session.(*harvest.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://id.getharvest.com/oauth2/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":1209599}`)
+		case "/accounts":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"user":{"id":1000000,"first_name":"Bob","last_name":"Powell","email":"bob@example.com"},"accounts":[{"id":123456,"name":"Example Harvest","product":"harvest"},{"id":987654,"name":"Example Forecast","product":"forecast"}]}`)
+		case "/v2/users/me":
+			a.Equal("123456", r.Header.Get(harvest.HeaderAccountID))
+			fmt.Fprint(w, `{"id":1000000,"avatar_url":"https://cache.harvestapp.com/avatar.png","timezone":"Eastern Time (US & Canada)"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	harvest.TokenURL, harvest.AccountsURL, harvest.APIURL = ts.URL+"/token", ts.URL+"/accounts", ts.URL+"/v2"
+	defer func() {
+		harvest.TokenURL = "https://id.getharvest.com/api/v2/oauth2/token"
+		harvest.AccountsURL = "https://id.getharvest.com/api/v2/accounts"
+		harvest.APIURL = "https://api.harvestapp.com/v2"
+	}()
+	provider := harvestProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("1000000", user.UserID)
+	a.Equal("Bob Powell", user.Name)
+	a.Equal("bob@example.com", user.Email)
+	a.Empty(user.AvatarURL)
+
+	accounts, err := harvest.Accounts(user)
+	a.NoError(err)
+	a.Len(accounts, 2)
+	a.Equal(int64(123456), accounts[0].ID)
+
+	provider.AccountID = "123456"
+	user, err = provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("https://cache.harvestapp.com/avatar.png", user.AvatarURL)
+
+	provider.AccountID = "1"
+	_, err = provider.FetchUser(session)
+	a.EqualError(err, "harvest: user has no access to account 1")
+}
+
+func harvestProvider() *harvest.Provider {
+	return harvest.New("key", "secret", "/foo")
+}
@@ -0,0 +1,144 @@
+// Package hashnode authenticates users of Hashnode with their Personal Access Tokens.
+// Hashnode has no OAuth for third party applications: users generate a
+// Personal Access Token in their developer settings and hand it to the
+// application, the provider checks it and returns the user it belongs to. The
+// token is the AccessToken of the user.
+//
+//	user, err := hashnode.New().UserFromToken(c.FormValue("token"))
+//
+// Reference: https://apidocs.hashnode.com/#authentication
+package hashnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+)
+
+var APIURL = "https://gql.hashnode.com"
+
+const meQuery = `query { me { id username name email profilePicture bio { text } location } }`
+
+// New creates a new Hashnode provider.
+func New() *Provider {
+	return &Provider{
+		providerName: "hashnode",
+		apiURL:       APIURL,
+	}
+}
+
+// Provider checks Hashnode Personal Access Tokens.
+type Provider struct {
+	HTTPClient   *http.Client
+	providerName string
+	apiURL       string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromToken returns the user the Personal Access Token belongs to.
+func (p *Provider) UserFromToken(token string) (goth.User, error) {
+	user := goth.User{
+		AccessToken: token,
+		Provider:    p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	body, err := json.Marshal(map[string]string{"query": meQuery})
+	if err != nil {
+		return user, err
+	}
+	req, err := http.NewRequest("POST", p.apiURL, bytes.NewReader(body))
+	if err != nil {
+		return user, err
+	}
+	// The API takes the bare token, without the Bearer prefix.
+	req.Header.Add("Authorization", token)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	// Invalid tokens are answered with a 200 and a list of errors.
+	u := struct {
+		Data struct {
+			Me struct {
+				ID             string `json:"id"`
+				Username       string `json:"username"`
+				Name           string `json:"name"`
+				Email          string `json:"email"`
+				ProfilePicture string `json:"profilePicture"`
+				Location       string `json:"location"`
+				Bio            struct {
+					Text string `json:"text"`
+				} `json:"bio"`
+			} `json:"me"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if len(u.Errors) > 0 {
+		return errors.New(u.Errors[0].Message)
+	}
+	raw := struct {
+		Data struct {
+			Me map[string]interface{} `json:"me"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	user.UserID = u.Data.Me.ID
+	user.Name = u.Data.Me.Name
+	user.NickName = u.Data.Me.Username
+	user.Email = u.Data.Me.Email
+	user.AvatarURL = u.Data.Me.ProfilePicture
+	user.Location = u.Data.Me.Location
+	user.Description = u.Data.Me.Bio.Text
+	user.RawData = raw.Data.Me
+	return nil
+}
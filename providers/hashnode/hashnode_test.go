@@ -0,0 +1,48 @@
+package hashnode_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth/providers/hashnode"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal("hashnode", hashnode.New().Name())
+}
+
+func Test_UserFromToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "token" {
+			fmt.Fprint(w, `{"errors":[{"message":"You must be authenticated.","extensions":{"code":"UNAUTHENTICATED"}}],"data":null}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"me":{"id":"5f7d4b8e1f2c3a0d4e5b6c7d","username":"jane","name":"Jane Doe","email":"jane@example.com","profilePicture":"https://cdn.hashnode.com/p.png","bio":{"text":"Writer"},"location":"Berlin"}}}`)
+	}))
+	defer ts.Close()
+
+	hashnode.APIURL = ts.URL
+	defer func() { hashnode.APIURL = "https://gql.hashnode.com" }()
+	provider := hashnode.New()
+
+	user, err := provider.UserFromToken("token")
+	a.NoError(err)
+	a.Equal("hashnode", user.Provider)
+	a.Equal("5f7d4b8e1f2c3a0d4e5b6c7d", user.UserID)
+	a.Equal("jane", user.NickName)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("Writer", user.Description)
+	a.Equal("token", user.AccessToken)
+
+	_, err = provider.UserFromToken("other")
+	a.EqualError(err, "You must be authenticated.")
+}
@@ -0,0 +1,84 @@
+package hubspot_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/hubspot"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), hubspotProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := hubspotProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := hubspotProvider().BeginAuth("test_state")
+	s := session.(*hubspot.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://app.hubspot.com/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=oauth")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/v1/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":1800}`)
+		case "/oauth/v1/access-tokens/token":
+			fmt.Fprint(w, `{"token":"token","user":"test@hubspot.com","hub_domain":"demo.hubapi.com","scopes":["oauth"],"hub_id":62515,"app_id":456,"expires_in":1754,"user_id":123,"token_type":"access"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	hubspot.TokenURL, hubspot.TokenInfoURL = ts.URL+"/oauth/v1/token", ts.URL+"/oauth/v1/access-tokens/"
+	defer func() {
+		hubspot.TokenURL, hubspot.TokenInfoURL = "https://api.hubapi.com/oauth/v1/token", "https://api.hubapi.com/oauth/v1/access-tokens/"
+	}()
+	provider := hubspotProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("123", user.UserID)
+	a.Equal("test@hubspot.com", user.Email)
+	a.Equal(float64(62515), user.RawData["hub_id"])
+	a.Equal("demo.hubapi.com", user.RawData["hub_domain"])
+	a.NotContains(user.RawData, "token")
+}
+
+func hubspotProvider() *hubspot.Provider {
+	return hubspot.New("key", "secret", "/foo")
+}
@@ -0,0 +1,262 @@
+// Package idme implements the OAuth2 protocol for authenticating users through
+// ID.me, and for reading the communities, such as military or student, ID.me
+// verified them to belong to. Request the scope of each community the
+// application cares about; FetchUser keeps them in RawData[RawDataGroups], read
+// them with Groups and Verified.
+// Reference: https://developers.id.me/documentation
+package idme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Environments of ID.me, New uses ProductionURL.
+const (
+	ProductionURL = "https://api.id.me"
+	SandboxURL    = "https://api.idmelabs.com"
+)
+
+// Scopes of ID.me, one per community.
+const (
+	ScopeMilitary   = "military"
+	ScopeStudent    = "student"
+	ScopeTeacher    = "teacher"
+	ScopeResponder  = "responder"
+	ScopeNurse      = "nurse"
+	ScopeMedical    = "medical"
+	ScopeGovernment = "government"
+)
+
+// RawDataGroups is the key of the []Group of the user in RawData.
+const RawDataGroups = "groups"
+
+// Group is a community of the user, such as military with the subgroup
+// Veteran.
+type Group struct {
+	Name      string   `json:"group"`
+	Subgroups []string `json:"subgroups"`
+	Verified  bool     `json:"verified"`
+}
+
+// New creates a new ID.me provider, and sets up important connection details.
+// You should always call `idme.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, ProductionURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set the
+// environment of ID.me, such as SandboxURL.
+func NewCustomisedURL(clientKey, secret, callbackURL, idmeURL string, scopes ...string) *Provider {
+	idmeURL = strings.TrimSuffix(idmeURL, "/")
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "idme",
+		profileURL:   idmeURL + "/api/public/v3/attributes.json",
+	}
+	p.config = newConfig(p, idmeURL, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing ID.me.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the idme package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ID.me for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to ID.me and access the attributes and the communities of
+// the user. The attributes are kept in RawData by their handle, such as zip.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Attributes []struct {
+			Handle string      `json:"handle"`
+			Value  interface{} `json:"value"`
+		} `json:"attributes"`
+		Status []Group `json:"status"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+
+	user.RawData = map[string]interface{}{}
+	for _, attribute := range u.Attributes {
+		user.RawData[attribute.Handle] = attribute.Value
+	}
+	user.RawData[RawDataGroups] = u.Status
+
+	user.UserID, _ = user.RawData["uuid"].(string)
+	user.Email, _ = user.RawData["email"].(string)
+	user.FirstName, _ = user.RawData["fname"].(string)
+	user.LastName, _ = user.RawData["lname"].(string)
+	user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	return nil
+}
+
+// Groups returns the communities of a user fetched by FetchUser.
+func Groups(user goth.User) []Group {
+	switch groups := user.RawData[RawDataGroups].(type) {
+	case []Group:
+		return groups
+	case nil:
+		return nil
+	default:
+		// RawData was decoded from JSON, such as a stored user.
+		b, err := json.Marshal(groups)
+		if err != nil {
+			return nil
+		}
+		var decoded []Group
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			return nil
+		}
+		return decoded
+	}
+}
+
+// Verified reports whether ID.me verified the user belongs to group, such as
+// ScopeMilitary.
+func Verified(user goth.User, group string) bool {
+	for _, g := range Groups(user) {
+		if g.Name == group && g.Verified {
+			return true
+		}
+	}
+	return false
+}
+
+func newConfig(provider *Provider, idmeURL string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   idmeURL + "/oauth/authorize",
+			TokenURL:  idmeURL + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,91 @@
+package idme_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/idme"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), idmeProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := idmeProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := idmeProvider().BeginAuth("test_state")
+	s := session.(*idme.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.id.me/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=military+student")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.NoError(r.ParseForm())
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","refresh_token":"refresh","expires_in":300,"scope":"military"}`)
+		case "/api/public/v3/attributes.json":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"attributes":[{"handle":"fname","name":"First Name","value":"John"},{"handle":"lname","name":"Last Name","value":"Doe"},{"handle":"email","name":"Email","value":"john@example.com"},{"handle":"uuid","name":"Unique Identifier","value":"4a8f9c1e2b3d4e5f"},{"handle":"zip","name":"Zip Code","value":"22102"}],"status":[{"group":"military","subgroups":["Veteran"],"verified":true},{"group":"student","subgroups":[],"verified":false}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := idme.NewCustomisedURL("key", "secret", "/foo", ts.URL, idme.ScopeMilitary)
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("4a8f9c1e2b3d4e5f", user.UserID)
+	a.Equal("John Doe", user.Name)
+	a.Equal("john@example.com", user.Email)
+	a.Equal("22102", user.RawData["zip"])
+	a.Equal([]idme.Group{{Name: "military", Subgroups: []string{"Veteran"}, Verified: true}, {Name: "student", Subgroups: []string{}}}, idme.Groups(user))
+	a.True(idme.Verified(user, idme.ScopeMilitary))
+	a.False(idme.Verified(user, idme.ScopeStudent))
+
+	// Users stored as JSON keep their groups.
+	b, err := json.Marshal(user)
+	a.NoError(err)
+	stored := goth.User{}
+	a.NoError(json.Unmarshal(b, &stored))
+	a.True(idme.Verified(stored, idme.ScopeMilitary))
+}
+
+func idmeProvider() *idme.Provider {
+	return idme.New("key", "secret", "/foo", idme.ScopeMilitary, idme.ScopeStudent)
+}
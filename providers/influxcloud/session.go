@@ -10,8 +10,9 @@ import (
 
 // Session stores data during the auth process with Influxcloud.
 type Session struct {
-	AuthURL     string
-	AccessToken string
+	AuthURL      string
+	AccessToken  string
+	CodeVerifier string `json:",omitempty"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the Influxcloud provider.
@@ -26,7 +27,7 @@ func (s Session) GetAuthURL() (string, error) {
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
 
-	token, err := p.Config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.Config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 
 	if err != nil {
 		return "", err
@@ -37,6 +38,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	}
 
 	s.AccessToken = token.AccessToken
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
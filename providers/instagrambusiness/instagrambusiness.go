@@ -0,0 +1,304 @@
+// Package instagrambusiness implements the OAuth2 protocol for authenticating Instagram professional
+// accounts through Facebook Login for Business.
+// Instagram business and creator accounts are managed through the Facebook
+// Pages they are linked to: the provider signs in the Facebook user and lists
+// the pages the user granted access to, with their Instagram account. Get
+// them with Pages.
+// Reference: https://developers.facebook.com/docs/instagram-platform/instagram-api-with-facebook-login
+package instagrambusiness
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL  = "https://www.facebook.com/v19.0/dialog/oauth"
+	TokenURL = "https://graph.facebook.com/v19.0/oauth/access_token"
+	GraphURL = "https://graph.facebook.com/v19.0"
+)
+
+// Scopes listing the pages of the user and reading their Instagram accounts,
+// they are requested when no scopes are given.
+const (
+	ScopeInstagramBasic = "instagram_basic"
+	ScopePagesShowList  = "pages_show_list"
+)
+
+// New creates a new Instagram business provider, and sets up important connection details.
+// You should always call `instagrambusiness.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeInstagramBasic, ScopePagesShowList}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "instagrambusiness",
+		graphURL:     GraphURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Instagram
+// professional accounts.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+	PKCE        bool
+
+	// ConfigID is the id of a Facebook Login for Business configuration.
+	// When set, the permissions are those of the configuration rather than
+	// the scopes.
+	ConfigID string
+
+	config       *oauth2.Config
+	providerName string
+	graphURL     string
+}
+
+// Page is a Facebook Page the user granted access to.
+type Page struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// InstagramBusinessAccount is the Instagram professional account linked
+	// to the page, if any.
+	InstagramBusinessAccount *InstagramAccount `json:"instagram_business_account,omitempty"`
+}
+
+// InstagramAccount is an Instagram business or creator account.
+type InstagramAccount struct {
+	ID                string `json:"id"`
+	Username          string `json:"username"`
+	Name              string `json:"name"`
+	ProfilePictureURL string `json:"profile_picture_url"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the instagrambusiness package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Facebook for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	opts := pkce
+	if p.ConfigID != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("config_id", p.ConfigID))
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, opts...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Facebook and access basic information about the user,
+// and the pages and Instagram accounts the user granted access to. When the
+// user granted access to a single Instagram account, its username and
+// picture are the NickName and AvatarURL of the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	body, err := p.get("/me", "id,name,email", sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+	if err := userFromReader(body, &user); err != nil {
+		return user, err
+	}
+
+	body, err = p.get("/me/accounts", "id,name,instagram_business_account{id,username,name,profile_picture_url}", sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer body.Close()
+
+	pages := struct {
+		Data []map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(body).Decode(&pages); err != nil {
+		return user, err
+	}
+	user.RawData["pages"] = pages.Data
+
+	accounts, err := InstagramAccounts(user)
+	if err != nil {
+		return user, err
+	}
+	ids := make([]string, 0, len(accounts))
+	for _, a := range accounts {
+		ids = append(ids, a.ID)
+	}
+	user.RawData["instagram_business_account_ids"] = ids
+	if len(accounts) == 1 {
+		user.NickName = accounts[0].Username
+		user.AvatarURL = accounts[0].ProfilePictureURL
+	}
+	return user, nil
+}
+
+// get calls the end-point path of the Graph API for fields, with the access
+// token of the user and its appsecret_proof.
+func (p *Provider) get(path, fields, accessToken string) (io.ReadCloser, error) {
+	// https://developers.facebook.com/docs/graph-api/securing-requests
+	hash := hmac.New(sha256.New, []byte(p.Secret))
+	hash.Write([]byte(accessToken))
+
+	resp, err := p.Client().Get(p.graphURL + path + "?" + url.Values{
+		"fields":          {fields},
+		"access_token":    {accessToken},
+		"appsecret_proof": {hex.EncodeToString(hash.Sum(nil))},
+	}.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+	return resp.Body, nil
+}
+
+// Pages returns the Facebook Pages of a user fetched by FetchUser.
+func Pages(user goth.User) ([]Page, error) {
+	raw, ok := user.RawData["pages"]
+	if !ok {
+		return nil, nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var pages []Page
+	err = json.Unmarshal(b, &pages)
+	return pages, err
+}
+
+// InstagramAccounts returns the Instagram professional accounts linked to the
+// Facebook Pages of a user fetched by FetchUser.
+func InstagramAccounts(user goth.User) ([]InstagramAccount, error) {
+	pages, err := Pages(user)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []InstagramAccount
+	for _, page := range pages {
+		if page.InstagramBusinessAccount != nil {
+			accounts = append(accounts, *page.InstagramBusinessAccount)
+		}
+	}
+	return accounts, nil
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.ID
+	user.Name = u.Name
+	user.NickName = u.Name
+	user.Email = u.Email
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Facebook")
+}
@@ -0,0 +1,101 @@
+package instagrambusiness_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/instagrambusiness"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), instagrambusinessProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := instagrambusinessProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := instagrambusinessProvider()
+	session, err := provider.BeginAuth("test_state")
+	s := session.(*instagrambusiness.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.facebook.com/v19.0/dialog/oauth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=instagram_basic+pages_show_list")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.NotContains(s.AuthURL, "config_id")
+
+	provider.ConfigID = "1234"
+	session, err = provider.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*instagrambusiness.Session).AuthURL, "config_id=1234")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/access_token":
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","expires_in":5183944}`)
+		case "/me":
+			a.Equal("token", r.URL.Query().Get("access_token"))
+			a.NotEmpty(r.URL.Query().Get("appsecret_proof"))
+			fmt.Fprint(w, `{"id":"10224","name":"Jane Doe","email":"jane@example.com"}`)
+		case "/me/accounts":
+			fmt.Fprint(w, `{"data":[{"id":"1340","name":"Jane's Bakery","instagram_business_account":{"id":"17841405822304914","username":"janesbakery","name":"Jane's Bakery","profile_picture_url":"https://scontent.cdninstagram.com/p.jpg"}},{"id":"1341","name":"Jane's Blog"}],"paging":{"cursors":{"before":"a","after":"b"}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	instagrambusiness.TokenURL, instagrambusiness.GraphURL = ts.URL+"/oauth/access_token", ts.URL
+	defer func() {
+		instagrambusiness.TokenURL, instagrambusiness.GraphURL = "https://graph.facebook.com/v19.0/oauth/access_token", "https://graph.facebook.com/v19.0"
+	}()
+	provider := instagrambusinessProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("10224", user.UserID)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("janesbakery", user.NickName)
+	a.Equal("https://scontent.cdninstagram.com/p.jpg", user.AvatarURL)
+	a.Equal([]string{"17841405822304914"}, user.RawData["instagram_business_account_ids"])
+
+	pages, err := instagrambusiness.Pages(user)
+	a.NoError(err)
+	a.Len(pages, 2)
+	a.Equal("1340", pages[0].ID)
+	a.Equal("17841405822304914", pages[0].InstagramBusinessAccount.ID)
+	a.Nil(pages[1].InstagramBusinessAccount)
+}
+
+func instagrambusinessProvider() *instagrambusiness.Provider {
+	return instagrambusiness.New("key", "secret", "/foo")
+}
@@ -92,6 +92,7 @@ func Test_FetchUser(t *testing.T) {
 		a.Equal("Washburne", user.LastName)
 		a.Equal("http://avatarURL", user.AvatarURL)
 		a.Equal(true, user.RawData["email_verified"])
+		a.True(user.EmailVerified)
 		a.Equal("token", user.AccessToken)
 	})
 }
@@ -120,6 +121,7 @@ func Test_FetchUnverifiedUser(t *testing.T) {
 		a.Equal("Washburne", user.LastName)
 		a.Equal("http://avatarURL", user.AvatarURL)
 		a.Equal(false, user.RawData["email_verified"])
+		a.False(user.EmailVerified)
 		a.Equal("token", user.AccessToken)
 	})
 }
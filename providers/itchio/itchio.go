@@ -0,0 +1,202 @@
+// Package itchio implements the OAuth2 protocol for authenticating users through itch.io.
+// itch.io only supports the implicit grant: the access token is returned to
+// the callback URL in the fragment, which browsers do not send to the server.
+// The callback page has to pass it on in the query, for example with:
+//
+//	<script>location.replace(location.pathname + "?" + location.hash.slice(1))</script>
+//
+// Authorize then takes the access_token parameter instead of exchanging a code.
+// Reference: https://itch.io/docs/api/oauth
+package itchio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://itch.io/user/oauth"
+	ProfileURL = "https://itch.io/api/1/key/me"
+)
+
+// ScopeProfileMe lets the provider read the profile of the user, it is
+// requested when no scopes are given.
+const ScopeProfileMe = "profile:me"
+
+// New creates a new itch.io provider, and sets up important connection details.
+// You should always call `itchio.New` to get a new Provider. Never try to create
+// one manually. itch.io does not issue client secrets.
+func New(clientKey, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeProfileMe}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		CallbackURL:  callbackURL,
+		providerName: "itchio",
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing itch.io.
+type Provider struct {
+	ClientKey    string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	if options.PKCE {
+		return nil, &goth.OptionNotSupportedError{Provider: p.Name(), Option: "WithPKCE"}
+	}
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the itchio package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks itch.io for an authentication end-point, returning the access
+// token itself rather than a code.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state, oauth2.SetAuthURLParam("response_type", "token")),
+	}, nil
+}
+
+// FetchUser will go to itch.io and access basic information about the user.
+// itch.io does not share the email address of users.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	// Invalid keys are answered with a 200 and a list of errors.
+	u := struct {
+		User struct {
+			ID          int64  `json:"id"`
+			Username    string `json:"username"`
+			DisplayName string `json:"display_name"`
+			URL         string `json:"url"`
+			CoverURL    string `json:"cover_url"`
+		} `json:"user"`
+		Errors []string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if len(u.Errors) > 0 {
+		return fmt.Errorf("%s responded with an error: %s", user.Provider, u.Errors[0])
+	}
+	raw := struct {
+		User map[string]interface{} `json:"user"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	user.UserID = strconv.FormatInt(u.User.ID, 10)
+	user.Name = u.User.DisplayName
+	user.NickName = u.User.Username
+	user.AvatarURL = u.User.CoverURL
+	user.RawData = raw.User
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:    provider.ClientKey,
+		RedirectURL: provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL: AuthURL,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by itch.io")
+}
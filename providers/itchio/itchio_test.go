@@ -0,0 +1,82 @@
+package itchio_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/itchio"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), itchioProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := itchioProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := itchioProvider().BeginAuth("test_state")
+	s := session.(*itchio.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://itch.io/user/oauth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=profile%3Ame")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "response_type=token")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer token" {
+			fmt.Fprint(w, `{"errors":["invalid key"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"user":{"username":"fasterthanlime","gamer":true,"display_name":"Amos","cover_url":"https://img.itch.zone/cover.png","url":"https://fasterthanlime.itch.io","press_user":true,"developer":true,"id":3}}`)
+	}))
+	defer ts.Close()
+
+	itchio.ProfileURL = ts.URL
+	defer func() { itchio.ProfileURL = "https://itch.io/api/1/key/me" }()
+	provider := itchioProvider()
+
+	session, _ := provider.BeginAuth("state")
+	_, err := session.Authorize(provider, url.Values{})
+	a.Error(err)
+	token, err := session.Authorize(provider, url.Values{"access_token": {"token"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("3", user.UserID)
+	a.Equal("Amos", user.Name)
+	a.Equal("fasterthanlime", user.NickName)
+	a.Equal(true, user.RawData["developer"])
+
+	_, err = provider.FetchUser(&itchio.Session{AccessToken: "other"})
+	a.EqualError(err, "itchio responded with an error: invalid key")
+}
+
+func itchioProvider() *itchio.Provider {
+	return itchio.New("key", "/foo")
+}
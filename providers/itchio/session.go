@@ -0,0 +1,55 @@
+package itchio
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with itch.io.
+type Session struct {
+	AuthURL     string
+	AccessToken string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the itch.io provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with itch.io and return the access token to be stored for future use.
+// itch.io returns the access token itself, passed on by the callback page as
+// the access_token parameter.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	token := params.Get("access_token")
+	if token == "" {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = token
+	return token, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
@@ -0,0 +1,100 @@
+package kick_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/kick"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), kickProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := kickProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := kickProvider().BeginAuth("test_state")
+	s := session.(*kick.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://id.kick.com/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=user%3Aread")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","expires_in":7200,"scope":"user:read"}`)
+		case "/public/v1/users":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"data":[{"user_id":4377088,"name":"jane_doe","email":"jane@example.com","profile_picture":"https://files.kick.com/avatar.png"}],"message":"OK"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	kick.TokenURL, kick.ProfileURL = ts.URL+"/oauth/token", ts.URL+"/public/v1/users"
+	defer func() {
+		kick.TokenURL, kick.ProfileURL = "https://id.kick.com/oauth/token", "https://api.kick.com/public/v1/users"
+	}()
+	provider := kickProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*kick.Session).CodeVerifier
+
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("4377088", user.UserID)
+	a.Equal("jane_doe", user.NickName)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("https://files.kick.com/avatar.png", user.AvatarURL)
+	a.Equal("refresh", user.RefreshToken)
+}
+
+func kickProvider() *kick.Provider {
+	return kick.New("key", "secret", "/foo")
+}
@@ -0,0 +1,162 @@
+// Package kratos authenticates the users of Ory Kratos, self-hosted or on the
+// Ory Network. Kratos signs in the users itself and keeps their session in a
+// cookie, or hands a session token to native apps, there is no OAuth dance:
+// the provider asks Kratos whom the session belongs to and turns its identity
+// into a goth.User. Use it to load the user of gothic.RequireAuth:
+//
+//	k := kratos.New("https://auth.example.com")
+//	loader := gothic.UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+//		user, err := k.UserFromRequest(c.Request())
+//		return user, err == nil, nil
+//	})
+//
+// The application has to be served on a domain the session cookie is sent to.
+// Ory Hydra, the OAuth2 server of Ory, is used through openidConnect.OryHydra.
+// Reference: https://www.ory.sh/docs/kratos/self-service/flows/user-login#checking-login-status
+package kratos
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+)
+
+// HeaderSessionToken is the header native apps send their session token in.
+const HeaderSessionToken = "X-Session-Token"
+
+// ErrNoSession is returned when a request has no session cookie nor token,
+// or when Kratos does not know its session.
+var ErrNoSession = errors.New("kratos: request has no active session")
+
+// New creates a new Kratos provider. publicURL is the URL of the public API of
+// Kratos, such as https://auth.example.com or the URL of an Ory Network
+// project.
+func New(publicURL string) *Provider {
+	return &Provider{
+		PublicURL:    strings.TrimSuffix(publicURL, "/"),
+		providerName: "kratos",
+	}
+}
+
+// Provider validates the sessions of Ory Kratos.
+type Provider struct {
+	PublicURL    string
+	HTTPClient   *http.Client
+	providerName string
+}
+
+// Session is a session of Kratos, as returned by its whoami end-point.
+type Session struct {
+	ID              string    `json:"id"`
+	Active          bool      `json:"active"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	AuthenticatedAt time.Time `json:"authenticated_at"`
+	Identity        struct {
+		ID       string                 `json:"id"`
+		SchemaID string                 `json:"schema_id"`
+		Traits   map[string]interface{} `json:"traits"`
+	} `json:"identity"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromRequest returns the user of the session of the request, read from
+// its cookies or HeaderSessionToken. It returns ErrNoSession when the request
+// has no active session.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	if token := r.Header.Get(HeaderSessionToken); token != "" {
+		return p.UserFromSessionToken(token)
+	}
+	cookie := r.Header.Get("Cookie")
+	if cookie == "" {
+		return goth.User{Provider: p.Name()}, ErrNoSession
+	}
+	return p.whoami("Cookie", cookie)
+}
+
+// UserFromSessionToken returns the user of the session token of a native app.
+// It returns ErrNoSession when the session is not active.
+func (p *Provider) UserFromSessionToken(token string) (goth.User, error) {
+	return p.whoami(HeaderSessionToken, token)
+}
+
+// whoami asks Kratos for the session authenticated by the header key.
+func (p *Provider) whoami(key, value string) (goth.User, error) {
+	user := goth.User{Provider: p.Name()}
+
+	req, err := http.NewRequest("GET", p.PublicURL+"/sessions/whoami", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add(key, value)
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return user, ErrNoSession
+	default:
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch the session", resp)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return user, err
+	}
+	session := &Session{}
+	if err := json.Unmarshal(b, session); err != nil {
+		return user, err
+	}
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return user, err
+	}
+	if !session.Active || goth.Expired(session.ExpiresAt) {
+		return user, ErrNoSession
+	}
+
+	user.UserID = session.Identity.ID
+	user.ExpiresAt = session.ExpiresAt
+	user.RawData = raw
+	userFromTraits(session.Identity.Traits, &user)
+	return user, nil
+}
+
+// userFromTraits reads the traits of the default identity schemas of Kratos,
+// where the name is either a string or has first and last parts.
+func userFromTraits(traits map[string]interface{}, user *goth.User) {
+	user.Email, _ = traits["email"].(string)
+	user.NickName, _ = traits["username"].(string)
+	switch name := traits["name"].(type) {
+	case string:
+		user.Name = name
+	case map[string]interface{}:
+		user.FirstName, _ = name["first"].(string)
+		user.LastName, _ = name["last"].(string)
+		user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+}
@@ -0,0 +1,73 @@
+package kratos_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth/providers/kratos"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := kratos.New("https://auth.example.com/")
+	a.Equal("https://auth.example.com", provider.PublicURL)
+	a.Equal("kratos", provider.Name())
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/sessions/whoami", r.URL.Path)
+		cookie, _ := r.Cookie("ory_kratos_session")
+		switch {
+		case cookie != nil && cookie.Value == "valid", r.Header.Get(kratos.HeaderSessionToken) == "token":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"8f1e2d3c","active":true,"expires_at":%q,"authenticated_at":"2026-10-16T08:00:00Z","identity":{"id":"9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d","schema_id":"default","traits":{"email":"jane@example.com","name":{"first":"Jane","last":"Doe"}}}}`, expiresAt.Format(time.RFC3339))
+		case cookie != nil && cookie.Value == "inactive":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"8f1e2d3c","active":false,"identity":{"id":"9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"}}`)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+	provider := kratos.New(ts.URL)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	_, err := provider.UserFromRequest(req)
+	a.Equal(kratos.ErrNoSession, err)
+
+	req.AddCookie(&http.Cookie{Name: "ory_kratos_session", Value: "valid"})
+	user, err := provider.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal("kratos", user.Provider)
+	a.Equal("9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d", user.UserID)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal(expiresAt, user.ExpiresAt.UTC())
+	a.Equal("8f1e2d3c", user.RawData["id"])
+
+	user, err = provider.UserFromSessionToken("token")
+	a.NoError(err)
+	a.Equal("9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d", user.UserID)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "ory_kratos_session", Value: "inactive"})
+	_, err = provider.UserFromRequest(req)
+	a.Equal(kratos.ErrNoSession, err)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "ory_kratos_session", Value: "expired"})
+	_, err = provider.UserFromRequest(req)
+	a.Equal(kratos.ErrNoSession, err)
+}
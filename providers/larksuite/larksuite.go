@@ -0,0 +1,325 @@
+// Package larksuite implements the OAuth2 protocol for authenticating users through Feishu (China)
+// and Lark (international), the two editions of Lark Suite.
+// Reference: https://open.larksuite.com/document/common-capabilities/sso/web-application-sso/web-app-overview
+package larksuite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Hosts of the two editions of Lark Suite.
+const (
+	FeishuHost = "https://open.feishu.cn"
+	LarkHost   = "https://open.larksuite.com"
+)
+
+// New creates a new Feishu provider, and sets up important connection details.
+func New(appID, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(appID, secret, callbackURL, FeishuHost, scopes...).withName("feishu")
+}
+
+// NewLark creates a new Lark provider, and sets up important connection details.
+func NewLark(appID, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(appID, secret, callbackURL, LarkHost, scopes...).withName("lark")
+}
+
+// NewCustomisedHost is similar to New(...) but can be used to point to a
+// private deployment of Lark Suite.
+func NewCustomisedHost(appID, secret, callbackURL, host string, scopes ...string) *Provider {
+	return &Provider{
+		ClientKey:    appID,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		Scopes:       scopes,
+		providerName: "larksuite",
+		host:         strings.TrimSuffix(host, "/"),
+		cache:        &tokenCache{},
+	}
+}
+
+func (p *Provider) withName(name string) *Provider {
+	p.providerName = name
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Lark Suite.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+	providerName string
+
+	// cache holds the app_access_token. It is shared by every login handled
+	// by this provider, and by the copies returned by BindClient.
+	cache *tokenCache
+
+	host string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the larksuite package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Lark Suite for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	params := url.Values{}
+	params.Add("app_id", p.ClientKey)
+	params.Add("redirect_uri", p.CallbackURL)
+	params.Add("state", state)
+	if len(p.Scopes) > 0 {
+		params.Add("scope", strings.Join(p.Scopes, " "))
+	}
+	session := &Session{
+		AuthURL: fmt.Sprintf("%s/open-apis/authen/v1/authorize?%s", p.host, params.Encode()),
+	}
+	return session, nil
+}
+
+// FetchUser will go to Lark Suite and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		Provider:     p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.host+"/open-apis/authen/v1/user_info", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Set("Authorization", "Bearer "+user.AccessToken)
+
+	var data json.RawMessage
+	if err := p.do(req, "/authen/v1/user_info", &data); err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(data), &user)
+	return user, err
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.fetchUserToken("/open-apis/authen/v1/oidc/refresh_access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// tokenCache caches the app_access_token used to request user access tokens.
+type tokenCache struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// fetchAppToken returns the app_access_token of the application, which
+// authenticates it when requesting user access tokens.
+func (p *Provider) fetchAppToken() (*oauth2.Token, error) {
+	p.cache.mu.Lock()
+	defer p.cache.mu.Unlock()
+
+	if p.cache.token != nil && p.cache.token.Valid() {
+		return p.cache.token, nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"app_id":     p.ClientKey,
+		"app_secret": p.Secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.host+"/open-apis/auth/v3/app_access_token/internal", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("larksuite /auth/v3/app_access_token/internal returns code: %d", resp.StatusCode)
+	}
+
+	// Unlike the other end-points, this one does not wrap its result in data.
+	obj := struct {
+		Code           int    `json:"code"`
+		Msg            string `json:"msg"`
+		AppAccessToken string `json:"app_access_token"`
+		Expire         int64  `json:"expire"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, err
+	}
+	if obj.Code != 0 {
+		return nil, fmt.Errorf("CODE: %d, MSG: %s", obj.Code, obj.Msg)
+	}
+
+	p.cache.token = &oauth2.Token{
+		AccessToken: obj.AppAccessToken,
+		Expiry:      goth.Now().Add(time.Duration(obj.Expire) * time.Second),
+	}
+	return p.cache.token, nil
+}
+
+// fetchUserToken requests a user access token from endpoint, authenticating
+// with the app_access_token.
+func (p *Provider) fetchUserToken(endpoint string, params map[string]string) (*oauth2.Token, error) {
+	appToken, err := p.fetchAppToken()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.host+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+appToken.AccessToken)
+
+	obj := struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}{}
+	if err := p.do(req, strings.TrimPrefix(endpoint, "/open-apis"), &obj); err != nil {
+		return nil, err
+	}
+	if obj.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  obj.AccessToken,
+		RefreshToken: obj.RefreshToken,
+	}
+	if obj.ExpiresIn > 0 {
+		token.Expiry = goth.Now().Add(time.Duration(obj.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// do sends req and decodes the data of the response into v. Lark Suite
+// wraps results as {"code":0,"msg":"success","data":{...}}.
+func (p *Provider) do(req *http.Request, endpoint string, v interface{}) error {
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	obj := struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("larksuite %s returns code: %d", endpoint, resp.StatusCode)
+		}
+		return err
+	}
+	if obj.Code != 0 {
+		return fmt.Errorf("CODE: %d, MSG: %s", obj.Code, obj.Msg)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("larksuite %s returns code: %d", endpoint, resp.StatusCode)
+	}
+	return json.Unmarshal(obj.Data, v)
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	obj := struct {
+		Name            string `json:"name"`
+		EnName          string `json:"en_name"`
+		AvatarURL       string `json:"avatar_url"`
+		AvatarBig       string `json:"avatar_big"`
+		OpenID          string `json:"open_id"`
+		UnionID         string `json:"union_id"`
+		Email           string `json:"email"`
+		EnterpriseEmail string `json:"enterprise_email"`
+	}{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	// The union_id identifies the user across all the applications of a
+	// developer, the open_id within this application only.
+	user.UserID = obj.UnionID
+	if user.UserID == "" {
+		user.UserID = obj.OpenID
+	}
+	user.Name = obj.Name
+	user.NickName = obj.EnName
+	user.Email = obj.Email
+	if user.Email == "" {
+		user.Email = obj.EnterpriseEmail
+	}
+	user.AvatarURL = obj.AvatarBig
+	if user.AvatarURL == "" {
+		user.AvatarURL = obj.AvatarURL
+	}
+	return nil
+}
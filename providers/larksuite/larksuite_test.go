@@ -0,0 +1,110 @@
+package larksuite_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/larksuite"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), larksuiteProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := larksuiteProvider()
+	a.Equal(provider.ClientKey, "cli_a")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.Name(), "feishu")
+	a.Equal(larksuite.NewLark("cli_a", "secret", "/foo").Name(), "lark")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := larksuiteProvider().BeginAuth("test_state")
+	s := session.(*larksuite.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://open.feishu.cn/open-apis/authen/v1/authorize")
+	a.Contains(s.AuthURL, "app_id=cli_a")
+	a.Contains(s.AuthURL, "state=test_state")
+
+	session, _ = larksuite.NewLark("cli_a", "secret", "/foo", "contact:user.email:readonly").BeginAuth("test_state")
+	s = session.(*larksuite.Session)
+	a.Contains(s.AuthURL, "https://open.larksuite.com/open-apis/authen/v1/authorize")
+	a.Contains(s.AuthURL, "scope=contact%3Auser.email%3Areadonly")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	appTokens := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		if r.Method == http.MethodPost {
+			a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		}
+		switch r.URL.Path {
+		case "/open-apis/auth/v3/app_access_token/internal":
+			appTokens++
+			a.Equal("cli_a", body["app_id"])
+			fmt.Fprint(w, `{"code":0,"msg":"ok","app_access_token":"t-app","expire":7200}`)
+		case "/open-apis/authen/v1/oidc/access_token", "/open-apis/authen/v1/oidc/refresh_access_token":
+			a.Equal("Bearer t-app", r.Header.Get("Authorization"))
+			if body["code"] != "good" && body["refresh_token"] != "ur-refresh" {
+				fmt.Fprint(w, `{"code":20003,"msg":"invalid code"}`)
+				return
+			}
+			fmt.Fprint(w, `{"code":0,"msg":"success","data":{"access_token":"u-token","refresh_token":"ur-refresh","token_type":"Bearer","expires_in":7140}}`)
+		case "/open-apis/authen/v1/user_info":
+			a.Equal("Bearer u-token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"code":0,"msg":"success","data":{"name":"张三","en_name":"Zhang San","avatar_url":"https://a/72","avatar_big":"https://a/640","open_id":"ou_1","union_id":"on_1","enterprise_email":"zhangsan@example.com","tenant_key":"736588c9260f175d"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	provider := larksuite.NewCustomisedHost("cli_a", "secret", "/foo", ts.URL)
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"good"}})
+	a.NoError(err)
+	a.Equal("u-token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("on_1", user.UserID)
+	a.Equal("ou_1", user.RawData["open_id"])
+	a.Equal("张三", user.Name)
+	a.Equal("zhangsan@example.com", user.Email)
+	a.Equal("https://a/640", user.AvatarURL)
+
+	refreshed, err := provider.RefreshToken("ur-refresh")
+	a.NoError(err)
+	a.Equal("u-token", refreshed.AccessToken)
+	a.Equal(1, appTokens, "the app_access_token is cached")
+
+	session, _ = provider.BeginAuth("state")
+	_, err = session.Authorize(provider, url.Values{"code": {"bad"}})
+	a.EqualError(err, "CODE: 20003, MSG: invalid code")
+}
+
+func larksuiteProvider() *larksuite.Provider {
+	return larksuite.New("cli_a", "secret", "/foo")
+}
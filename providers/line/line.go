@@ -11,6 +11,7 @@ import (
 	"fmt"
 
 	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
 	"golang.org/x/oauth2"
 )
 
@@ -18,6 +19,8 @@ const (
 	authURL      string = "https://access.line.me/oauth2/v2.1/authorize"
 	tokenURL     string = "https://api.line.me/oauth2/v2.1/token"
 	endpointUser string = "https://api.line.me/v2/profile"
+	keysURL      string = "https://api.line.me/oauth2/v2.1/certs"
+	issuer       string = "https://access.line.me"
 )
 
 // Provider is the implementation of `goth.Provider` for accessing Line.me.
@@ -26,6 +29,7 @@ type Provider struct {
 	Secret          string
 	CallbackURL     string
 	HTTPClient      *http.Client
+	PKCE            bool
 	config          *oauth2.Config
 	authCodeOptions []oauth2.AuthCodeOption
 	providerName    string
@@ -60,13 +64,35 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the line package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks line.me for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state, p.authCodeOptions...),
+		AuthURL:      p.config.AuthCodeURL(state, append(pkce, p.authCodeOptions...)...),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -78,6 +104,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		Provider:     p.Name(),
 		RefreshToken: sess.RefreshToken,
 		ExpiresAt:    sess.ExpiresAt,
+		IDToken:      sess.IDToken,
 	}
 
 	if user.AccessToken == "" {
@@ -85,6 +112,16 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
+	// The ID token only comes with the openid scope, and holds the email of
+	// the user with the email scope.
+	if sess.IDToken != "" {
+		claims, err := p.verifier().Verify(p.Client(), sess.IDToken, "")
+		if err != nil {
+			return user, fmt.Errorf("%s: %w", p.providerName, err)
+		}
+		user.Email, _ = claims.Raw["email"].(string)
+	}
+
 	// Get the userID, line needs userID in order to get user profile info
 	c := p.Client()
 	req, err := http.NewRequest("GET", endpointUser, nil)
@@ -103,7 +140,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -129,6 +166,18 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// verifier validates the ID tokens issued to the channel. LINE signs those of
+// web logins with the channel secret, and those of native apps with ES256.
+func (p *Provider) verifier() *idtoken.Verifier {
+	return &idtoken.Verifier{
+		KeysURL:    keysURL,
+		ClientID:   p.ClientKey,
+		Issuers:    []string{issuer},
+		Algorithms: []string{"HS256", "ES256"},
+		Secret:     p.Secret,
+	}
+}
+
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 	c := &oauth2.Config{
 		ClientID:     provider.ClientKey,
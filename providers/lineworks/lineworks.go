@@ -0,0 +1,273 @@
+// Package lineworks implements the OAuth2 protocol for authenticating users through LINE WORKS,
+// and the JWT grant giving service accounts access to the LINE WORKS API.
+// Reference: https://developers.worksmobile.com/en/docs/auth
+package lineworks
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://auth.worksmobile.com/oauth2/v2.0/authorize"
+	TokenURL   = "https://auth.worksmobile.com/oauth2/v2.0/token"
+	ProfileURL = "https://www.worksapis.com/v1.0/users/me"
+)
+
+// ScopeUserProfileRead lets the provider read the profile of the user, it is
+// requested when no scopes are given.
+const ScopeUserProfileRead = "user.profile.read"
+
+// New creates a new LINE WORKS provider, and sets up important connection details.
+// You should always call `lineworks.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeUserProfileRead}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "lineworks",
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing LINE WORKS.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the lineworks package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks LINE WORKS for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to LINE WORKS and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		UserID   string `json:"userId"`
+		Email    string `json:"email"`
+		NickName string `json:"nickName"`
+		UserName struct {
+			LastName  string `json:"lastName"`
+			FirstName string `json:"firstName"`
+		} `json:"userName"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.UserID
+	user.Email = u.Email
+	user.NickName = u.NickName
+	user.FirstName = u.UserName.FirstName
+	user.LastName = u.UserName.LastName
+	// LINE WORKS is mostly used in Japan and Korea, where the family name
+	// comes first.
+	user.Name = strings.TrimSpace(u.UserName.LastName + " " + u.UserName.FirstName)
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
+
+// ServiceAccountToken returns an access token for a service account, to call
+// the LINE WORKS API on behalf of the application rather than of a user. The
+// token is obtained with a JWT, signed with the private key issued along with
+// the service account in the Developer Console.
+func (p *Provider) ServiceAccountToken(serviceAccount string, privateKey *rsa.PrivateKey, scopes ...string) (*oauth2.Token, error) {
+	if privateKey == nil {
+		return nil, errors.New("lineworks: no private key to sign the service account JWT with")
+	}
+
+	now := goth.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": p.ClientKey,
+		"sub": serviceAccount,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}).SignedString(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("assertion", assertion)
+	params.Add("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	params.Add("client_id", p.ClientKey)
+	params.Add("client_secret", p.Secret)
+	params.Add("scope", strings.Join(scopes, " "))
+	resp, err := p.Client().PostForm(p.config.Endpoint.TokenURL, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	obj := struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        string `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to get a service account token", resp)
+	}
+	if obj.Error != "" {
+		return nil, fmt.Errorf("%s: %s: %s", p.providerName, obj.Error, obj.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || obj.AccessToken == "" {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to get a service account token", resp)
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  obj.AccessToken,
+		RefreshToken: obj.RefreshToken,
+		TokenType:    obj.TokenType,
+	}
+	var expiresIn int64
+	if _, err := fmt.Sscan(obj.ExpiresIn, &expiresIn); err == nil && expiresIn > 0 {
+		token.Expiry = now.Add(time.Duration(expiresIn) * time.Second)
+	}
+	return token, nil
+}
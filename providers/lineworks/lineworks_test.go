@@ -0,0 +1,105 @@
+package lineworks_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/lineworks"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), lineworksProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := lineworksProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := lineworksProvider().BeginAuth("test_state")
+	s := session.(*lineworks.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://auth.worksmobile.com/oauth2/v2.0/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=user.profile.read")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			a.NoError(r.ParseForm())
+			if r.PostForm.Get("grant_type") == "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+				claims := jwt.MapClaims{}
+				_, err := jwt.ParseWithClaims(r.PostForm.Get("assertion"), claims, func(*jwt.Token) (interface{}, error) {
+					return &key.PublicKey, nil
+				})
+				a.NoError(err)
+				a.Equal("key", claims["iss"])
+				a.Equal("svc@example", claims["sub"])
+				a.Equal("bot", r.PostForm.Get("scope"))
+				fmt.Fprint(w, `{"access_token":"svc-token","token_type":"Bearer","expires_in":"86400","scope":"bot"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","expires_in":"86400","scope":"user.profile.read"}`)
+		case "/users/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"userId":"4f2b3a0c","email":"yamada@example.com","userName":{"lastName":"山田","firstName":"太郎"},"nickName":"Taro"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	lineworks.TokenURL, lineworks.ProfileURL = ts.URL+"/token", ts.URL+"/users/me"
+	defer func() {
+		lineworks.TokenURL, lineworks.ProfileURL = "https://auth.worksmobile.com/oauth2/v2.0/token", "https://www.worksapis.com/v1.0/users/me"
+	}()
+	provider := lineworksProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("4f2b3a0c", user.UserID)
+	a.Equal("山田 太郎", user.Name)
+	a.Equal("yamada@example.com", user.Email)
+	a.Equal("refresh", user.RefreshToken)
+
+	svc, err := provider.ServiceAccountToken("svc@example", key, "bot")
+	a.NoError(err)
+	a.Equal("svc-token", svc.AccessToken)
+	a.False(svc.Expiry.IsZero())
+}
+
+func lineworksProvider() *lineworks.Provider {
+	return lineworks.New("key", "secret", "/foo")
+}
@@ -0,0 +1,84 @@
+package linode_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/linode"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), linodeProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := linodeProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := linode.New("key", "secret", "/foo", "linodes:read_only").BeginAuth("test_state")
+	s := session.(*linode.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://login.linode.com/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=linodes%3Aread_only")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","expires_in":7200,"refresh_token":"refresh","scopes":"linodes:read_only"}`)
+		case "/v4/profile":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"uid":1234,"username":"example_user","email":"example-user@gmail.com","timezone":"US/Eastern","restricted":false}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	linode.TokenURL, linode.ProfileURL = ts.URL+"/oauth/token", ts.URL+"/v4/profile"
+	defer func() {
+		linode.TokenURL, linode.ProfileURL = "https://login.linode.com/oauth/token", "https://api.linode.com/v4/profile"
+	}()
+	provider := linodeProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("1234", user.UserID)
+	a.Equal("example_user", user.NickName)
+	a.Equal("example-user@gmail.com", user.Email)
+	a.Equal("refresh", user.RefreshToken)
+}
+
+func linodeProvider() *linode.Provider {
+	return linode.New("key", "secret", "/foo")
+}
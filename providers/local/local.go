@@ -0,0 +1,159 @@
+// Package local implements logins with a username and password checked by the
+// application, for applications offering a local account alongside the
+// social providers without a second authentication stack. The provider holds
+// no accounts: Verifier checks the credentials against the database of the
+// application, and VerifyOTP, when set, the one-time code of users with a
+// second factor.
+//
+// BeginAuth sends the user to LoginURL, the login form of the application,
+// with the state of the login in the state parameter. The form posts the
+// state, username, password and, for users with a second factor, otp
+// parameters to the callback:
+//
+//	<form method="post" action="/auth/local/callback">
+//		<input type="hidden" name="state" value="{{.State}}">
+//		<input name="username"> <input type="password" name="password">
+//		<input name="otp" autocomplete="one-time-code">
+//	</form>
+//
+// Rate limit the attempts with gothic.LoginGuard.
+package local
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Parameters of the callback.
+const (
+	UsernameParam = "username"
+	PasswordParam = "password"
+	OTPParam      = "otp"
+)
+
+var (
+	// ErrInvalidCredentials is returned by Verifiers for unknown usernames
+	// and wrong passwords alike, so that the login form does not tell which.
+	ErrInvalidCredentials = errors.New("local: invalid username or password")
+	// ErrOTPRequired is returned by VerifyOTP when the user has a second
+	// factor and the form sent no code, for the login form to ask for it.
+	ErrOTPRequired = errors.New("local: one-time code required")
+	// ErrInvalidOTP is returned by VerifyOTP for wrong codes.
+	ErrInvalidOTP = errors.New("local: invalid one-time code")
+)
+
+// Verifier checks the credentials of a user against the accounts of the
+// application. It returns the user, without tokens, or ErrInvalidCredentials.
+type Verifier interface {
+	VerifyPassword(username, password string) (goth.User, error)
+}
+
+// VerifierFunc adapts a function to the Verifier interface.
+type VerifierFunc func(username, password string) (goth.User, error)
+
+// VerifyPassword calls f(username, password).
+func (f VerifierFunc) VerifyPassword(username, password string) (goth.User, error) {
+	return f(username, password)
+}
+
+// New creates a new local provider. loginURL is the login form of the
+// application, and callbackURL the URL it posts to.
+func New(verifier Verifier, loginURL, callbackURL string) *Provider {
+	return &Provider{
+		Verifier:     verifier,
+		LoginURL:     loginURL,
+		CallbackURL:  callbackURL,
+		providerName: "local",
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for local accounts.
+type Provider struct {
+	Verifier    Verifier
+	LoginURL    string
+	CallbackURL string
+
+	// VerifyOTP, when set, checks the one-time code sent with the password
+	// of user, once the password has been verified. It returns nil for users
+	// without a second factor, ErrOTPRequired when code is empty and
+	// ErrInvalidOTP when it is wrong; ValidTOTP checks the codes of
+	// authenticator apps.
+	VerifyOTP func(user goth.User, code string) error
+
+	providerName string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Debug is a no-op for the local package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth sends the user to LoginURL with state.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	u, err := url.Parse(p.LoginURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("state", state)
+	u.RawQuery = q.Encode()
+	return &Session{
+		AuthURL: u.String(),
+	}, nil
+}
+
+// FetchUser returns the user whose credentials were verified.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	if sess.User == nil {
+		// data is not yet retrieved since the credentials are not verified yet
+		return goth.User{Provider: p.Name()}, fmt.Errorf("%s cannot get user information without verified credentials", p.providerName)
+	}
+	return *sess.User, nil
+}
+
+// verify checks the credentials and one-time code posted to the callback.
+func (p *Provider) verify(params goth.Params) (goth.User, error) {
+	username, password := params.Get(UsernameParam), params.Get(PasswordParam)
+	if username == "" || password == "" {
+		return goth.User{}, ErrInvalidCredentials
+	}
+	user, err := p.Verifier.VerifyPassword(username, password)
+	if err != nil {
+		return goth.User{}, err
+	}
+	if p.VerifyOTP != nil {
+		if err := p.VerifyOTP(user, params.Get(OTPParam)); err != nil {
+			return goth.User{}, err
+		}
+	}
+
+	user = user.Redacted()
+	user.Provider = p.Name()
+	if user.NickName == "" {
+		user.NickName = username
+	}
+	return user, nil
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by local
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by local")
+}
@@ -0,0 +1,121 @@
+package local_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/local"
+	"github.com/stretchr/testify/assert"
+)
+
+func provider() *local.Provider {
+	verifier := local.VerifierFunc(func(username, password string) (goth.User, error) {
+		if username != "homer" || password != "donuts" {
+			return goth.User{}, local.ErrInvalidCredentials
+		}
+		return goth.User{UserID: "42", Email: "homer@example.com", AccessToken: "leaked"}, nil
+	})
+	return local.New(verifier, "https://app.example.com/login", "https://app.example.com/auth/local/callback")
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	a.Equal("local", p.Name())
+	a.Equal("https://app.example.com/login", p.LoginURL)
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := provider().BeginAuth("test_state")
+	a.NoError(err)
+	a.Equal("https://app.example.com/login?state=test_state", session.(*local.Session).AuthURL)
+}
+
+func Test_AuthorizeAndFetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	session, _ := p.BeginAuth("test_state")
+	_, err := p.FetchUser(session)
+	a.Error(err)
+
+	_, err = session.Authorize(p, url.Values{"username": {"homer"}, "password": {"beer"}})
+	a.Equal(local.ErrInvalidCredentials, err)
+	_, err = session.Authorize(p, url.Values{"username": {"homer"}})
+	a.Equal(local.ErrInvalidCredentials, err)
+
+	_, err = session.Authorize(p, url.Values{"username": {"homer"}, "password": {"donuts"}})
+	a.NoError(err)
+	user, err := p.FetchUser(session)
+	a.NoError(err)
+	a.Equal("local", user.Provider)
+	a.Equal("42", user.UserID)
+	a.Equal("homer", user.NickName)
+	a.Empty(user.AccessToken)
+
+	// The verified user survives the session being stored between requests.
+	restored, err := p.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	user, err = p.FetchUser(restored)
+	a.NoError(err)
+	a.Equal("homer@example.com", user.Email)
+}
+
+func Test_VerifyOTP(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	p := provider()
+	p.VerifyOTP = func(user goth.User, code string) error {
+		if code == "" {
+			return local.ErrOTPRequired
+		}
+		if !local.ValidTOTP(secret, code) {
+			return local.ErrInvalidOTP
+		}
+		return nil
+	}
+
+	session, _ := p.BeginAuth("test_state")
+	params := url.Values{"username": {"homer"}, "password": {"donuts"}}
+	_, err := session.Authorize(p, params)
+	a.Equal(local.ErrOTPRequired, err)
+
+	params.Set("otp", "000000")
+	_, err = session.Authorize(p, params)
+	a.Equal(local.ErrInvalidOTP, err)
+
+	params.Set("otp", local.TOTP([]byte("12345678901234567890"), time.Now()))
+	_, err = session.Authorize(p, params)
+	a.NoError(err)
+}
+
+func Test_TOTP(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	// Test vectors of RFC 6238, truncated to 6 digits.
+	secret := []byte("12345678901234567890")
+	a.Equal("287082", local.TOTP(secret, time.Unix(59, 0)))
+	a.Equal("081804", local.TOTP(secret, time.Unix(1111111109, 0)))
+	a.Equal("005924", local.TOTP(secret, time.Unix(1234567890, 0)))
+
+	a.False(local.ValidTOTP("not base32!", "287082"))
+}
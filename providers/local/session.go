@@ -0,0 +1,54 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process of a local account.
+type Session struct {
+	AuthURL string
+	User    *goth.User `json:",omitempty"`
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the local provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize checks the credentials, and one-time code, posted to the callback.
+// There is no token: it returns an empty string.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	user, err := p.verify(params)
+	if err != nil {
+		return "", err
+	}
+	s.User = &user
+	return "", nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
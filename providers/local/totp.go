@@ -0,0 +1,45 @@
+package local
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+)
+
+// TOTP returns the 6 digit time-based one-time password of secret at t, as
+// computed by authenticator apps.
+// See https://datatracker.ietf.org/doc/html/rfc6238
+func TOTP(secret []byte, t time.Time) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/30))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", code%1000000)
+}
+
+// ValidTOTP reports whether code is the one-time password of secret, base32
+// encoded as in the otpauth:// URIs of authenticator apps, for the current
+// period of 30 seconds or the one before or after it, allowing for the clock
+// of the device.
+func ValidTOTP(secret, code string) bool {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(code) != 6 {
+		return false
+	}
+	now := goth.Now()
+	for _, skew := range []time.Duration{0, -30 * time.Second, 30 * time.Second} {
+		if hmac.Equal([]byte(TOTP(key, now.Add(skew))), []byte(code)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+// Package logingov implements the OpenID Connect protocol for authenticating
+// users through Login.gov. Applications authenticate with private_key_jwt: the
+// token requests are signed with the PrivateKey of the application, whose
+// public certificate is registered with Login.gov. Applications which cannot
+// keep a private key, such as single page applications, use PKCE instead.
+// The ID token is validated, with its nonce, against the keys of Login.gov.
+// Reference: https://developers.login.gov/oidc/getting-started/
+package logingov
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// Environments of Login.gov, New uses ProductionURL.
+const (
+	ProductionURL = "https://secure.login.gov/"
+	SandboxURL    = "https://idp.int.identitysandbox.gov/"
+)
+
+// Authentication contexts of Login.gov, ACRAuthOnly is requested unless
+// ACRValues is set.
+const (
+	ACRAuthOnly            = "urn:acr.login.gov:auth-only"
+	ACRVerified            = "urn:acr.login.gov:verified"
+	ACRVerifiedFacialMatch = "urn:acr.login.gov:verified-facial-match-required"
+)
+
+// Scopes of Login.gov, ScopeOpenID and ScopeEmail are requested when no scopes
+// are given. The identity verification attributes need ACRVerified.
+const (
+	ScopeOpenID      = "openid"
+	ScopeEmail       = "email"
+	ScopeAllEmails   = "all_emails"
+	ScopeProfile     = "profile"
+	ScopeProfileName = "profile:name"
+	ScopeAddress     = "address"
+	ScopePhone       = "phone"
+	ScopeVerifiedAt  = "profile:verified_at"
+)
+
+// New creates a new Login.gov provider, and sets up important connection details.
+// privateKey, an *rsa.PrivateKey, signs the token requests; with a nil
+// privateKey PKCE is used instead.
+// You should always call `logingov.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientID string, privateKey interface{}, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientID, privateKey, callbackURL, ProductionURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set the
+// environment of Login.gov, such as SandboxURL.
+func NewCustomisedURL(clientID string, privateKey interface{}, callbackURL, loginGovURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeOpenID, ScopeEmail}
+	}
+	if !strings.HasSuffix(loginGovURL, "/") {
+		loginGovURL += "/"
+	}
+	p := &Provider{
+		ClientKey:    clientID,
+		PrivateKey:   privateKey,
+		CallbackURL:  callbackURL,
+		ACRValues:    ACRAuthOnly,
+		providerName: "logingov",
+		issuer:       loginGovURL,
+		profileURL:   loginGovURL + "api/openid_connect/userinfo",
+		keys:         goth.NewKeySet(loginGovURL+"api/openid_connect/certs", 12*time.Hour),
+	}
+	p.config = newConfig(p, loginGovURL, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Login.gov.
+type Provider struct {
+	ClientKey string
+	// PrivateKey signs the token requests, PKCE is used when it is nil.
+	PrivateKey interface{}
+	// KeyID is the kid of PrivateKey, if any.
+	KeyID       string
+	CallbackURL string
+	// ACRValues are the authentication contexts requested, separated by spaces.
+	ACRValues    string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	issuer       string
+	profileURL   string
+	keys         *goth.KeySet
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the logingov package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Login.gov for an authentication end-point, with a new nonce.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{Nonce: nonce}
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("acr_values", p.ACRValues),
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("prompt", "select_account"),
+	}
+	if p.PrivateKey == nil {
+		s.CodeVerifier, err = randomString()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts,
+			oauth2.SetAuthURLParam("code_challenge", goth.CodeChallenge(s.CodeVerifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		)
+	}
+	s.AuthURL = p.config.AuthCodeURL(state, opts...)
+	return s, nil
+}
+
+// tokenOptions returns the options authenticating the application on the
+// token request.
+func (p *Provider) tokenOptions(codeVerifier string) ([]oauth2.AuthCodeOption, error) {
+	if p.PrivateKey == nil {
+		return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("code_verifier", codeVerifier)}, nil
+	}
+	assertion, err := goth.ClientAssertion(p.ClientKey, p.config.Endpoint.TokenURL, p.PrivateKey, p.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	return goth.ClientAssertionOptions(assertion), nil
+}
+
+// idTokenClaims are the claims of the ID tokens of Login.gov checked by
+// validateIDToken.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce string `json:"nonce"`
+}
+
+// validateIDToken checks the signature and the claims of idToken, which must
+// carry nonce.
+func (p *Provider) validateIDToken(idToken, nonce string) error {
+	claims := &idTokenClaims{}
+	if _, err := idtoken.Validate(idToken, claims, p.keys.Keyfunc(p.Client()), []string{"RS256"}, p.issuer, p.ClientKey); err != nil {
+		return fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return fmt.Errorf("%s: nonce is incorrect", p.providerName)
+	}
+	return nil
+}
+
+// FetchUser will go to Login.gov and access the attributes of the user
+// allowed by the scopes.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+		IDToken:     sess.IDToken,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Sub        string `json:"sub"`
+		Email      string `json:"email"`
+		GivenName  string `json:"given_name"`
+		FamilyName string `json:"family_name"`
+		Address    *struct {
+			Locality string `json:"locality"`
+			Region   string `json:"region"`
+		} `json:"address"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.Sub
+	user.Email = u.Email
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.Name = strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+	if u.Address != nil {
+		user.Location = strings.Trim(u.Address.Locality+", "+u.Address.Region, ", ")
+	}
+	return nil
+}
+
+// randomString returns a random string, long enough for nonces and PKCE code
+// verifiers.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func newConfig(provider *Provider, loginGovURL string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:    provider.ClientKey,
+		RedirectURL: provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   loginGovURL + "openid_connect/authorize",
+			TokenURL:  loginGovURL + "api/openid_connect/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Login.gov")
+}
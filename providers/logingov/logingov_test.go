@@ -0,0 +1,130 @@
+package logingov_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/logingov"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), logingovProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := logingovProvider()
+	a.Equal(provider.ClientKey, "urn:gov:gsa:openidconnect.profiles:sp:sso:agency:app")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.ACRValues, logingov.ACRAuthOnly)
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := logingovProvider().BeginAuth("test_state")
+	s := session.(*logingov.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://secure.login.gov/openid_connect/authorize")
+	a.Contains(s.AuthURL, "acr_values=urn%3Aacr.login.gov%3Aauth-only")
+	a.Contains(s.AuthURL, "scope=openid+email")
+	a.Contains(s.AuthURL, "prompt=select_account")
+	a.Contains(s.AuthURL, "nonce="+s.Nonce)
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+	a.NotEmpty(s.CodeVerifier)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	appKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	idpKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+
+	var ts *httptest.Server
+	nonce := ""
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/openid_connect/certs":
+			key, err := jwk.New(&idpKey.PublicKey)
+			a.NoError(err)
+			a.NoError(key.Set(jwk.KeyIDKey, "idp"))
+			set := jwk.NewSet()
+			set.Add(key)
+			a.NoError(json.NewEncoder(w).Encode(set))
+		case "/api/openid_connect/token":
+			a.NoError(r.ParseForm())
+			a.Equal(goth.ClientAssertionType, r.PostForm.Get("client_assertion_type"))
+			a.Empty(r.PostForm.Get("client_secret"))
+			claims := &jwt.RegisteredClaims{}
+			_, err := jwt.ParseWithClaims(r.PostForm.Get("client_assertion"), claims, func(*jwt.Token) (interface{}, error) {
+				return &appKey.PublicKey, nil
+			})
+			a.NoError(err)
+			a.True(claims.VerifyAudience(ts.URL+"/api/openid_connect/token", true))
+
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+				"iss":   ts.URL + "/",
+				"aud":   "client",
+				"sub":   "b2d2d115-1d7e-4579-b9d6-f8e84f4f56ca",
+				"exp":   time.Now().Add(time.Hour).Unix(),
+				"nonce": nonce,
+			})
+			token.Header["kid"] = "idp"
+			idToken, err := token.SignedString(idpKey)
+			a.NoError(err)
+			fmt.Fprintf(w, `{"access_token":"token","token_type":"Bearer","expires_in":900,"id_token":%q}`, idToken)
+		case "/api/openid_connect/userinfo":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"sub":"b2d2d115-1d7e-4579-b9d6-f8e84f4f56ca","iss":"https://secure.login.gov/","email":"test@example.com","email_verified":true,"given_name":"Jane","family_name":"Doe","ial":"http://idmanagement.gov/ns/assurance/ial/1"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := logingov.NewCustomisedURL("client", appKey, "/foo", ts.URL)
+
+	session, err := provider.BeginAuth("state")
+	a.NoError(err)
+	a.Empty(session.(*logingov.Session).CodeVerifier)
+	nonce = session.(*logingov.Session).Nonce
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("b2d2d115-1d7e-4579-b9d6-f8e84f4f56ca", user.UserID)
+	a.Equal("test@example.com", user.Email)
+	a.Equal("Jane Doe", user.Name)
+	a.NotEmpty(user.IDToken)
+
+	session, _ = provider.BeginAuth("state")
+	nonce = "other"
+	_, err = session.Authorize(provider, url.Values{"code": {"code"}})
+	a.EqualError(err, "logingov: nonce is incorrect")
+}
+
+func logingovProvider() *logingov.Provider {
+	return logingov.New("urn:gov:gsa:openidconnect.profiles:sp:sso:agency:app", nil, "/foo")
+}
@@ -52,6 +52,7 @@ type Provider struct {
 	clientSecret string
 	httpClient   *http.Client
 	oauthConfig  *oauth2.Config
+	PKCE         bool
 }
 
 // Name is the name used to retrieve this provider later.
@@ -68,10 +69,32 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.httpClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.httpClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.oauthConfig = options.OAuth2Config(p.oauthConfig)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // BeginAuth asks MAILRU for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.oauthConfig.AuthCodeURL(state),
+		AuthURL:      p.oauthConfig.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -116,7 +139,7 @@ func (p *Provider) FetchUser(session goth.Session) (_ goth.User, err error) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.name, res.StatusCode)
+		return user, goth.NewProviderAPIError(p.name, "trying to fetch user information", res)
 	}
 
 	buf, err := ioutil.ReadAll(res.Body)
@@ -15,6 +15,7 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
+	CodeVerifier string `json:",omitempty"`
 }
 
 // GetAuthURL returns the URL for the authentication end-point for the provider.
@@ -35,7 +36,7 @@ func (s *Session) Marshal() string {
 // Authorize the session with MAILRU and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.oauthConfig.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.oauthConfig.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -48,6 +49,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
 
+	s.CodeVerifier = ""
 	return s.AccessToken, err
 }
 
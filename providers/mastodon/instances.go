@@ -0,0 +1,67 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// RegisterApp registers an application on the instance at instanceURL, and
+// returns its client credentials. Mastodon lets any application register,
+// so a client can be created on every instance users log in with.
+// See https://docs.joinmastodon.org/methods/apps/#create
+func RegisterApp(client *http.Client, instanceURL, clientName, callbackURL string, scopes ...string) (clientKey, secret string, err error) {
+	if len(scopes) == 0 {
+		scopes = []string{"read"}
+	}
+	form := url.Values{
+		"client_name":   {clientName},
+		"redirect_uris": {callbackURL},
+		"scopes":        {strings.Join(scopes, " ")},
+	}
+	resp, err := goth.HTTPClientWithFallBack(client).PostForm(strings.TrimSuffix(instanceURL, "/")+"/api/v1/apps", form)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", goth.NewProviderAPIError(instanceURL, "trying to register the application", resp)
+	}
+
+	app := struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&app); err != nil {
+		return "", "", err
+	}
+	if app.ClientID == "" {
+		return "", "", fmt.Errorf("%s registered the application without a client_id", instanceURL)
+	}
+	return app.ClientID, app.ClientSecret, nil
+}
+
+// NewInstances returns the goth.Instances of Mastodon. Without credentials,
+// the application is registered as clientName on the instances users log in
+// with, once per process. To keep the registrations, give credentials
+// looking them up in the application's storage, and calling RegisterApp
+// for the instances it has none for.
+func NewInstances(clientName, callbackURL string, credentials goth.InstanceCredentials, scopes ...string) *goth.Instances {
+	if credentials == nil {
+		credentials = func(instanceURL string) (string, string, error) {
+			return RegisterApp(nil, instanceURL, clientName, callbackURL, scopes...)
+		}
+	}
+	return goth.NewInstances("mastodon", func(instanceURL string) (goth.Provider, error) {
+		clientKey, secret, err := credentials(instanceURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewCustomisedURL(clientKey, secret, callbackURL, instanceURL, scopes...), nil
+	})
+}
@@ -1,6 +1,9 @@
 package mastodon_test
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -58,6 +61,44 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_NewInstances(t *testing.T) {
+	a := assert.New(t)
+
+	registrations := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/api/v1/apps", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("goth", r.PostForm.Get("client_name"))
+		a.Equal("/foo", r.PostForm.Get("redirect_uris"))
+		a.Equal("read write", r.PostForm.Get("scopes"))
+		registrations++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"563419","name":"goth","redirect_uri":"/foo","client_id":"key","client_secret":"secret"}`)
+	}))
+	defer ts.Close()
+	goth.DefaultHTTPClient = ts.Client()
+	defer func() { goth.DefaultHTTPClient = nil }()
+
+	instances := mastodon.NewInstances("goth", "/foo", nil, "read", "write")
+	instances.Allow = goth.AllowInstanceURLs(ts.URL)
+	instances.AllowPrivateAddresses = true
+	a.Equal("mastodon", instances.Name())
+	p, err := instances.Provider(ts.URL)
+	a.NoError(err)
+	a.Equal("mastodon", p.Name())
+	a.Equal("key", p.(*mastodon.Provider).ClientKey)
+	a.Equal("secret", p.(*mastodon.Provider).Secret)
+
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*mastodon.Session).AuthURL, ts.URL+"/oauth/authorize")
+
+	// The application is registered once per instance.
+	_, err = instances.Provider(ts.URL + "/")
+	a.NoError(err)
+	a.Equal(1, registrations)
+}
+
 func provider() *mastodon.Provider {
 	return mastodon.New(os.Getenv("MASTODON_KEY"), os.Getenv("MASTODON_SECRET"), "/foo")
 }
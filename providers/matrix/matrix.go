@@ -0,0 +1,316 @@
+// Package matrix implements "login with your Matrix account", through the
+// single sign-on of the homeserver of the user and the OpenID tokens of Matrix.
+//
+// BeginAuth sends the user to the SSO login of the homeserver, which comes back
+// to the callback with a login token. Authorize exchanges it for an access
+// token, only used to request an OpenID token and read the profile of the user
+// before it is logged out: the application never acts as the user. FetchUser
+// checks the OpenID token with the homeserver over the federation API, found
+// through the server name of the user, so the user ID cannot be forged by the
+// homeserver the login went through.
+//
+// OpenID tokens requested by Matrix clients, such as the ones handed to
+// widgets, are checked with VerifyOpenIDToken.
+// Reference: https://spec.matrix.org/latest/client-server-api/#openid
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+const (
+	clientPrefix     = "/_matrix/client/v3"
+	federationPrefix = "/_matrix/federation/v1"
+
+	// DefaultFederationPort is the port of the federation API of servers that
+	// delegate it to no other host.
+	DefaultFederationPort = "8448"
+)
+
+// serverNamePattern matches the server names of Matrix, a host and optional
+// port, keeping paths and credentials out of the URLs built from them.
+var serverNamePattern = regexp.MustCompile(`^(\[[0-9A-Fa-f:.]+\]|[0-9A-Za-z.\-]+)(:[0-9]{1,5})?$`)
+
+// New creates a new Matrix provider for the homeserver at homeserverURL, the
+// base URL of its client API such as https://matrix-client.matrix.org.
+// Matrix needs no client registration.
+// You should always call `matrix.New` to get a new Provider. Never try to create
+// one manually.
+func New(callbackURL, homeserverURL string) *Provider {
+	return &Provider{
+		CallbackURL:   callbackURL,
+		HomeserverURL: strings.TrimSuffix(homeserverURL, "/"),
+		providerName:  "matrix",
+	}
+}
+
+// NewInstances returns the goth.Instances of Matrix, for users logging in
+// with the homeserver they type in, such as matrix.org. The base URL of the
+// client API of the homeserver is discovered with DiscoverHomeserver.
+func NewInstances(callbackURL string) *goth.Instances {
+	return goth.NewInstances("matrix", func(instanceURL string) (goth.Provider, error) {
+		homeserverURL, err := DiscoverHomeserver(nil, instanceURL)
+		if err != nil {
+			return nil, err
+		}
+		return New(callbackURL, homeserverURL), nil
+	})
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Matrix.
+type Provider struct {
+	CallbackURL   string
+	HomeserverURL string
+	HTTPClient    *http.Client
+	providerName  string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the matrix package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks the homeserver for its SSO login end-point. Matrix SSO has no
+// state parameter, the state is added to the URL the homeserver redirects to,
+// and to the AuthURL so that gothic can check it.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	redirectURL, err := url.Parse(p.CallbackURL)
+	if err != nil {
+		return nil, err
+	}
+	q := redirectURL.Query()
+	q.Set("state", state)
+	redirectURL.RawQuery = q.Encode()
+
+	params := url.Values{
+		"redirectUrl": {redirectURL.String()},
+		"state":       {state},
+	}
+	return &Session{
+		AuthURL: p.HomeserverURL + clientPrefix + "/login/sso/redirect?" + params.Encode(),
+	}, nil
+}
+
+// FetchUser checks the OpenID token of the session over federation, and
+// returns the user it belongs to with the profile read during Authorize.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	userID, err := p.VerifyOpenIDToken(sess.ServerName, sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+
+	user.UserID = userID
+	user.NickName = strings.TrimPrefix(strings.SplitN(userID, ":", 2)[0], "@")
+	user.Name = sess.DisplayName
+	user.AvatarURL = p.mediaURL(sess.AvatarURL)
+	user.RawData = map[string]interface{}{
+		"user_id":     userID,
+		"server_name": sess.ServerName,
+		"displayname": sess.DisplayName,
+		"avatar_url":  sess.AvatarURL,
+	}
+	return user, nil
+}
+
+// VerifyOpenIDToken checks an OpenID token issued by the homeserver of
+// serverName, and returns the ID of the user it was issued for. The user must
+// belong to serverName.
+func (p *Provider) VerifyOpenIDToken(serverName, token string) (string, error) {
+	federationURL, err := p.FederationURL(serverName)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.Client().Get(federationURL + federationPrefix + "/openid/userinfo?" + url.Values{"access_token": {token}}.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", goth.NewProviderAPIError(p.providerName, "trying to verify the OpenID token", resp)
+	}
+
+	u := struct {
+		Sub string `json:"sub"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return "", err
+	}
+	parts := strings.SplitN(u.Sub, ":", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "@") || parts[1] != serverName {
+		return "", fmt.Errorf("%s: user %q does not belong to %s", p.providerName, u.Sub, serverName)
+	}
+	return u.Sub, nil
+}
+
+// FederationURL returns the base URL of the federation API of serverName.
+// Servers delegate it with /.well-known/matrix/server, or serve it on
+// DefaultFederationPort. SRV records are not looked up.
+// See https://spec.matrix.org/latest/server-server-api/#resolving-server-names
+func (p *Provider) FederationURL(serverName string) (string, error) {
+	if !serverNamePattern.MatchString(serverName) {
+		return "", fmt.Errorf("%s: invalid server name %q", p.providerName, serverName)
+	}
+	host, port := splitServerName(serverName)
+	if port != "" {
+		return "https://" + serverName, nil
+	}
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return "https://" + serverName + ":" + DefaultFederationPort, nil
+	}
+
+	resp, err := p.Client().Get("https://" + serverName + "/.well-known/matrix/server")
+	if err == nil {
+		defer resp.Body.Close()
+		wellKnown := struct {
+			Server string `json:"m.server"`
+		}{}
+		if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&wellKnown) == nil && serverNamePattern.MatchString(wellKnown.Server) {
+			if _, port := splitServerName(wellKnown.Server); port != "" {
+				return "https://" + wellKnown.Server, nil
+			}
+			return "https://" + wellKnown.Server + ":" + DefaultFederationPort, nil
+		}
+	}
+	return "https://" + serverName + ":" + DefaultFederationPort, nil
+}
+
+// DiscoverHomeserver returns the base URL of the client API of the homeserver
+// at serverURL, such as https://matrix.org, as advertised in its
+// /.well-known/matrix/client. serverURL is returned when it advertises none.
+func DiscoverHomeserver(client *http.Client, serverURL string) (string, error) {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	resp, err := goth.HTTPClientWithFallBack(client).Get(serverURL + "/.well-known/matrix/client")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return serverURL, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", goth.NewProviderAPIError(serverURL, "trying to discover the homeserver", resp)
+	}
+
+	wellKnown := struct {
+		Homeserver struct {
+			BaseURL string `json:"base_url"`
+		} `json:"m.homeserver"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&wellKnown); err != nil {
+		return "", err
+	}
+	if wellKnown.Homeserver.BaseURL == "" {
+		return serverURL, nil
+	}
+	return strings.TrimSuffix(wellKnown.Homeserver.BaseURL, "/"), nil
+}
+
+// mediaURL returns the download URL of an mxc:// URL on the homeserver.
+func (p *Provider) mediaURL(mxc string) string {
+	if !strings.HasPrefix(mxc, "mxc://") {
+		return mxc
+	}
+	return p.HomeserverURL + "/_matrix/media/v3/download/" + strings.TrimPrefix(mxc, "mxc://")
+}
+
+// do sends a request to the client API of the homeserver, and decodes its
+// response into v. A nil body sends none.
+func (p *Provider) do(method, path, accessToken string, body, v interface{}) error {
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, p.HomeserverURL+clientPrefix+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return goth.NewProviderAPIError(p.providerName, "to "+method+" "+path, resp)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func splitServerName(serverName string) (host, port string) {
+	i := strings.LastIndex(serverName, ":")
+	if i < 0 || strings.HasSuffix(serverName, "]") {
+		return serverName, ""
+	}
+	return serverName[:i], serverName[i+1:]
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Matrix, OpenID tokens are
+// requested again with a new login.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Matrix")
+}
@@ -0,0 +1,209 @@
+package matrix_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/matrix"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := matrix.New("/foo", "https://matrix-client.matrix.org/")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.HomeserverURL, "https://matrix-client.matrix.org")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), matrix.New("/foo", "https://matrix.example.com"))
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := matrix.New("https://app.example.com/auth/matrix/callback", "https://matrix.example.com")
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*matrix.Session)
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	a.Equal("https://matrix.example.com/_matrix/client/v3/login/sso/redirect", u.Scheme+"://"+u.Host+u.Path)
+	a.Equal("test_state", u.Query().Get("state"))
+	a.Equal("https://app.example.com/auth/matrix/callback?state=test_state", u.Query().Get("redirectUrl"))
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := matrix.New("/foo", "https://matrix.example.com")
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://matrix.example.com/_matrix/client/v3/login/sso/redirect","AccessToken":"openid","ServerName":"example.com"}`)
+	a.NoError(err)
+
+	s := session.(*matrix.Session)
+	a.Equal(s.AuthURL, "https://matrix.example.com/_matrix/client/v3/login/sso/redirect")
+	a.Equal(s.AccessToken, "openid")
+	a.Equal(s.ServerName, "example.com")
+}
+
+func Test_AuthorizeAndFetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts, serverName, loggedOut := homeserver(t, "")
+	defer ts.Close()
+
+	p := matrix.New("/foo", ts.URL)
+	p.HTTPClient = ts.Client()
+	session := &matrix.Session{}
+	token, err := session.Authorize(p, url.Values{"loginToken": {"login-token"}})
+	a.NoError(err)
+	a.Equal("openid-token", token)
+	a.Equal(serverName, session.ServerName)
+	a.True(*loggedOut)
+
+	user, err := p.FetchUser(session)
+	a.NoError(err)
+	a.Equal("@alice:"+serverName, user.UserID)
+	a.Equal("alice", user.NickName)
+	a.Equal("Alice", user.Name)
+	a.Equal(ts.URL+"/_matrix/media/v3/download/"+serverName+"/avatar", user.AvatarURL)
+	a.Equal(serverName, user.RawData["server_name"])
+}
+
+func Test_AuthorizeWithoutLoginToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session := &matrix.Session{}
+	_, err := session.Authorize(matrix.New("/foo", "https://matrix.example.com"), url.Values{})
+	a.Error(err)
+}
+
+func Test_FetchUserOfAnotherServer(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts, serverName, _ := homeserver(t, "@alice:matrix.org")
+	defer ts.Close()
+
+	p := matrix.New("/foo", ts.URL)
+	p.HTTPClient = ts.Client()
+	_, err := p.FetchUser(&matrix.Session{AccessToken: "openid-token", ServerName: serverName})
+	a.Error(err)
+}
+
+func Test_FederationURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := matrix.New("/foo", "https://matrix.example.com")
+
+	u, err := p.FederationURL("matrix.example.com:8443")
+	a.NoError(err)
+	a.Equal("https://matrix.example.com:8443", u)
+
+	u, err = p.FederationURL("127.0.0.1")
+	a.NoError(err)
+	a.Equal("https://127.0.0.1:8448", u)
+
+	_, err = p.FederationURL("example.com/path")
+	a.Error(err)
+	_, err = p.FederationURL("user@example.com")
+	a.Error(err)
+}
+
+func Test_DiscoverHomeserver(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/.well-known/matrix/client", r.URL.Path)
+		w.Write([]byte(`{"m.homeserver":{"base_url":"https://matrix-client.example.com/"}}`))
+	}))
+	defer ts.Close()
+
+	homeserverURL, err := matrix.DiscoverHomeserver(nil, ts.URL+"/")
+	a.NoError(err)
+	a.Equal("https://matrix-client.example.com", homeserverURL)
+
+	none := httptest.NewServer(http.NotFoundHandler())
+	defer none.Close()
+
+	homeserverURL, err = matrix.DiscoverHomeserver(nil, none.URL)
+	a.NoError(err)
+	a.Equal(none.URL, homeserverURL)
+}
+
+func Test_NewInstances(t *testing.T) {
+	a := assert.New(t)
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	defer ts.Close()
+	goth.DefaultHTTPClient = ts.Client()
+	defer func() { goth.DefaultHTTPClient = nil }()
+
+	instances := matrix.NewInstances("/foo")
+	a.Equal("matrix", instances.Name())
+	instances.Allow = goth.AllowInstanceURLs(ts.URL)
+	instances.AllowPrivateAddresses = true
+
+	provider, err := instances.Provider(ts.URL)
+	a.NoError(err)
+	a.Equal(ts.URL, provider.(*matrix.Provider).HomeserverURL)
+}
+
+// homeserver serves the client and federation APIs of a homeserver whose
+// server name is the host of its URL. The OpenID token belongs to sub, or to
+// @alice of the server when sub is empty.
+func homeserver(t *testing.T, sub string) (*httptest.Server, string, *bool) {
+	a := assert.New(t)
+	loggedOut := false
+	var serverName string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := "@alice:" + serverName
+		switch r.URL.Path {
+		case "/_matrix/client/v3/login":
+			body := map[string]string{}
+			a.NoError(json.NewDecoder(r.Body).Decode(&body))
+			a.Equal("m.login.token", body["type"])
+			a.Equal("login-token", body["token"])
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "access-token", "user_id": userID})
+		case "/_matrix/client/v3/logout":
+			a.Equal("Bearer access-token", r.Header.Get("Authorization"))
+			loggedOut = true
+			w.Write([]byte(`{}`))
+		case "/_matrix/client/v3/user/" + userID + "/openid/request_token":
+			a.Equal("Bearer access-token", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":       "openid-token",
+				"token_type":         "Bearer",
+				"matrix_server_name": serverName,
+				"expires_in":         3600,
+			})
+		case "/_matrix/client/v3/profile/" + userID:
+			json.NewEncoder(w).Encode(map[string]string{"displayname": "Alice", "avatar_url": "mxc://" + serverName + "/avatar"})
+		case "/_matrix/federation/v1/openid/userinfo":
+			if r.URL.Query().Get("access_token") != "openid-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if sub == "" {
+				sub = userID
+			}
+			json.NewEncoder(w).Encode(map[string]string{"sub": sub})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	serverName = strings.TrimPrefix(ts.URL, "https://")
+	return ts, serverName, &loggedOut
+}
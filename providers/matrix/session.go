@@ -0,0 +1,102 @@
+package matrix
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with Matrix. AccessToken is
+// the OpenID token of the user, which only proves their identity.
+type Session struct {
+	AuthURL     string
+	AccessToken string
+	ExpiresAt   time.Time
+	ServerName  string
+	DisplayName string
+	AvatarURL   string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Matrix provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize logs in with the login token the homeserver redirected back with,
+// requests an OpenID token and the profile of the user, then logs out the
+// device the login created. It returns the OpenID token.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	loginToken := params.Get("loginToken")
+	if loginToken == "" {
+		return "", errors.New("matrix: the callback has no loginToken")
+	}
+
+	login := struct {
+		AccessToken string `json:"access_token"`
+		UserID      string `json:"user_id"`
+	}{}
+	err := p.do("POST", "/login", "", map[string]interface{}{
+		"type":                        "m.login.token",
+		"token":                       loginToken,
+		"initial_device_display_name": p.providerName,
+	}, &login)
+	if err != nil {
+		return "", err
+	}
+	// The access token lets the application act as the user, it is not kept.
+	defer p.do("POST", "/logout", login.AccessToken, struct{}{}, nil)
+
+	path := "/user/" + url.PathEscape(login.UserID)
+	openID := struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		MatrixServerName string `json:"matrix_server_name"`
+	}{}
+	if err := p.do("POST", path+"/openid/request_token", login.AccessToken, struct{}{}, &openID); err != nil {
+		return "", err
+	}
+	if openID.AccessToken == "" {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	profile := struct {
+		DisplayName string `json:"displayname"`
+		AvatarURL   string `json:"avatar_url"`
+	}{}
+	// Profiles may be hidden, the login goes on without them.
+	_ = p.do("GET", "/profile/"+url.PathEscape(login.UserID), login.AccessToken, nil, &profile)
+
+	s.AccessToken = openID.AccessToken
+	s.ExpiresAt = goth.ExpiresIn(openID.ExpiresIn)
+	s.ServerName = openID.MatrixServerName
+	s.DisplayName = profile.DisplayName
+	s.AvatarURL = profile.AvatarURL
+	return s.AccessToken, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
@@ -0,0 +1,91 @@
+package medium_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/medium"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), mediumProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := mediumProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := mediumProvider().BeginAuth("test_state")
+	s := session.(*medium.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://medium.com/m/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=basicProfile")
+
+	session, err = medium.New("key", "secret", "/foo", medium.ScopeBasicProfile, medium.ScopePublishPost).BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*medium.Session).AuthURL, "scope=basicProfile%2CpublishPost")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/tokens":
+			a.Equal("key", r.FormValue("client_id"))
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"token_type":"Bearer","access_token":"token","refresh_token":"refresh","scope":["basicProfile"],"expires_at":4102444800000}`)
+		case "/v1/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"data":{"id":"5303d74c64f66366f00cb9b2a94f3251bf5","username":"majelbstoat","name":"Jamie Talbot","url":"https://medium.com/@majelbstoat","imageUrl":"https://images.medium.com/0*fkfQiTzT7TlUGGyI.png"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	medium.TokenURL, medium.ProfileURL = ts.URL+"/v1/tokens", ts.URL+"/v1/me"
+	defer func() {
+		medium.TokenURL, medium.ProfileURL = "https://api.medium.com/v1/tokens", "https://api.medium.com/v1/me"
+	}()
+	provider := mediumProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("5303d74c64f66366f00cb9b2a94f3251bf5", user.UserID)
+	a.Equal("Jamie Talbot", user.Name)
+	a.Equal("majelbstoat", user.NickName)
+	a.Equal("https://medium.com/@majelbstoat", user.RawData["url"])
+	a.Equal(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), user.ExpiresAt.UTC())
+}
+
+func mediumProvider() *medium.Provider {
+	return medium.New("key", "secret", "/foo")
+}
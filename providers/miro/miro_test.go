@@ -0,0 +1,90 @@
+package miro_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/miro"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), miroProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := miroProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := miroProvider()
+	session, err := provider.BeginAuth("test_state")
+	s := session.(*miro.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://miro.com/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.NotContains(s.AuthURL, "team_id")
+
+	provider.TeamID = "3074457345618265000"
+	session, err = provider.BeginAuth("test_state")
+	s = session.(*miro.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "team_id=3074457345618265000")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/oauth/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"user_id":"3074457345618265001","refresh_token":"refresh","access_token":"token","expires_in":3599,"team_id":"3074457345618265000","scope":"boards:read","token_type":"bearer"}`)
+		case "/v1/oauth-token":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"type":"oauth-token","scopes":["boards:read"],"team":{"type":"team","name":"Design","id":"3074457345618265000"},"createdBy":{"type":"user","name":"Kim Lee","id":"3074457345618265001"},"user":{"type":"user","name":"Kim Lee","id":"3074457345618265001"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	miro.TokenURL, miro.ProfileURL = ts.URL+"/v1/oauth/token", ts.URL+"/v1/oauth-token"
+	defer func() {
+		miro.TokenURL, miro.ProfileURL = "https://api.miro.com/v1/oauth/token", "https://api.miro.com/v1/oauth-token"
+	}()
+	provider := miroProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("3074457345618265001", user.UserID)
+	a.Equal("Kim Lee", user.Name)
+	a.Equal("Design", user.RawData["team"].(map[string]interface{})["name"])
+}
+
+func miroProvider() *miro.Provider {
+	return miro.New("key", "secret", "/foo")
+}
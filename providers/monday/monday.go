@@ -0,0 +1,231 @@
+// Package monday implements the OAuth2 protocol for authenticating users through Monday.com.
+// Reference: https://developer.monday.com/apps/docs/oauth
+package monday
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL  = "https://auth.monday.com/oauth2/authorize"
+	TokenURL = "https://auth.monday.com/oauth2/token"
+	APIURL   = "https://api.monday.com/v2"
+)
+
+// ScopeMeRead lets the provider read the profile of the user, it is
+// requested when no scopes are given.
+const ScopeMeRead = "me:read"
+
+// meQuery is the GraphQL query reading the profile of the user.
+const meQuery = `query { me { id name email title location photo_original account { id name slug } } }`
+
+// New creates a new Monday.com provider, and sets up important connection details.
+// You should always call `monday.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeMeRead}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "monday",
+		apiURL:       APIURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Monday.com.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	apiURL       string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the monday package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Monday.com for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Monday.com and access basic information about the user,
+// with a GraphQL query of the user and their account.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	body, err := json.Marshal(map[string]string{"query": meQuery})
+	if err != nil {
+		return user, err
+	}
+	req, err := http.NewRequest("POST", p.apiURL, bytes.NewReader(body))
+	if err != nil {
+		return user, err
+	}
+	// The API takes the bare token, without the Bearer prefix.
+	req.Header.Add("Authorization", sess.AccessToken)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	// Depending on the API version, ids are numbers or strings, json.Number
+	// takes both.
+	u := struct {
+		Data struct {
+			Me struct {
+				ID            json.Number `json:"id"`
+				Name          string      `json:"name"`
+				Email         string      `json:"email"`
+				Title         string      `json:"title"`
+				Location      string      `json:"location"`
+				PhotoOriginal string      `json:"photo_original"`
+			} `json:"me"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		ErrorMessage string `json:"error_message"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if len(u.Errors) > 0 {
+		return errors.New(u.Errors[0].Message)
+	}
+	if u.ErrorMessage != "" {
+		return errors.New(u.ErrorMessage)
+	}
+	raw := struct {
+		Data struct {
+			Me map[string]interface{} `json:"me"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	me := u.Data.Me
+	user.RawData = raw.Data.Me
+	user.UserID = me.ID.String()
+	user.Name = me.Name
+	user.Email = me.Email
+	user.Description = me.Title
+	user.Location = me.Location
+	user.AvatarURL = me.PhotoOriginal
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by Monday.com, whose
+// access tokens do not expire.
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Monday.com
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Monday.com")
+}
@@ -0,0 +1,95 @@
+package monday_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/monday"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), mondayProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := mondayProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := mondayProvider().BeginAuth("test_state")
+	s := session.(*monday.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://auth.monday.com/oauth2/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=me%3Aread")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	me := `{"data":{"me":{"id":"4012689","name":"Ada Lovelace","email":"ada@example.com","title":"Analyst","location":"London","photo_original":"https://example.com/ada.png","account":{"id":"9876","name":"Engines","slug":"engines"}}},"account_id":9876}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer","scope":"me:read"}`)
+		case "/v2":
+			a.Equal("POST", r.Method)
+			a.Equal("token", r.Header.Get("Authorization"))
+			body := map[string]string{}
+			a.NoError(json.NewDecoder(r.Body).Decode(&body))
+			a.Contains(body["query"], "me {")
+			fmt.Fprint(w, me)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	monday.TokenURL, monday.APIURL = ts.URL+"/token", ts.URL+"/v2"
+	defer func() {
+		monday.TokenURL, monday.APIURL = "https://auth.monday.com/oauth2/token", "https://api.monday.com/v2"
+	}()
+	provider := mondayProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("4012689", user.UserID)
+	a.Equal("Ada Lovelace", user.Name)
+	a.Equal("ada@example.com", user.Email)
+	a.Equal("London", user.Location)
+	a.Equal("https://example.com/ada.png", user.AvatarURL)
+	a.Contains(user.RawData, "account")
+
+	me = `{"errors":[{"message":"Permission Denied! Your token doesn't grant access to me:read"}],"account_id":9876}`
+	_, err = provider.FetchUser(session)
+	a.EqualError(err, "Permission Denied! Your token doesn't grant access to me:read")
+}
+
+func mondayProvider() *monday.Provider {
+	return monday.New("key", "secret", "/foo")
+}
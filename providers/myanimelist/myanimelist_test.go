@@ -0,0 +1,96 @@
+package myanimelist_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/myanimelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), myanimelistProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := myanimelistProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := myanimelistProvider().BeginAuth("test_state")
+	s := session.(*myanimelist.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://myanimelist.net/v1/oauth2/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=plain")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	a.Len(s.CodeVerifier, 43)
+	a.Equal(s.CodeVerifier, u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/oauth2/token":
+			a.Equal("key", r.FormValue("client_id"))
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"token_type":"Bearer","expires_in":2678400,"access_token":"token","refresh_token":"refresh"}`)
+		case "/v2/users/@me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"id":1234,"name":"jane_doe","picture":"https://cdn.myanimelist.net/images/userimages/1234.jpg","location":"Tokyo","joined_at":"2015-03-02T06:03:11+00:00","time_zone":"Asia/Tokyo"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	myanimelist.TokenURL, myanimelist.ProfileURL = ts.URL+"/v1/oauth2/token", ts.URL+"/v2/users/@me"
+	defer func() {
+		myanimelist.TokenURL, myanimelist.ProfileURL = "https://myanimelist.net/v1/oauth2/token", "https://api.myanimelist.net/v2/users/@me"
+	}()
+	provider := myanimelistProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*myanimelist.Session).CodeVerifier
+
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("1234", user.UserID)
+	a.Equal("jane_doe", user.NickName)
+	a.Equal("Tokyo", user.Location)
+	a.Equal("Asia/Tokyo", user.RawData["time_zone"])
+}
+
+func myanimelistProvider() *myanimelist.Provider {
+	return myanimelist.New("key", "secret", "/foo")
+}
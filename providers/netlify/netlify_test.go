@@ -0,0 +1,83 @@
+package netlify_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/netlify"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), netlifyProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := netlifyProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := netlifyProvider().BeginAuth("test_state")
+	s := session.(*netlify.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://app.netlify.com/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer","scope":"public","created_at":1700000000}`)
+		case "/api/v1/user":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"id":"5b1a7fb1a6e8b2466b4c2c91","uid":"jdoe","full_name":"John Doe","avatar_url":"https://example.com/jdoe.png","email":"jdoe@example.com"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	netlify.TokenURL, netlify.ProfileURL = ts.URL+"/oauth/token", ts.URL+"/api/v1/user"
+	defer func() {
+		netlify.TokenURL, netlify.ProfileURL = "https://api.netlify.com/oauth/token", "https://api.netlify.com/api/v1/user"
+	}()
+	provider := netlifyProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("5b1a7fb1a6e8b2466b4c2c91", user.UserID)
+	a.Equal("John Doe", user.Name)
+	a.Equal("jdoe", user.NickName)
+	a.Equal("jdoe@example.com", user.Email)
+}
+
+func netlifyProvider() *netlify.Provider {
+	return netlify.New("key", "secret", "/foo")
+}
@@ -0,0 +1,17 @@
+package nextcloud
+
+import "github.com/bgdsh/goth"
+
+// NewInstances returns the goth.Instances of Nextcloud, for applications
+// whose users log in with their own Nextcloud server. Nextcloud has no
+// dynamic client registration: credentials returns the client an
+// administrator registered on each server.
+func NewInstances(callbackURL string, credentials goth.InstanceCredentials, scopes ...string) *goth.Instances {
+	return goth.NewInstances("nextcloud", func(instanceURL string) (goth.Provider, error) {
+		clientKey, secret, err := credentials(instanceURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewCustomisedDNS(clientKey, secret, callbackURL, instanceURL, scopes...), nil
+	})
+}
@@ -0,0 +1,312 @@
+// Package nostr implements logins with Nostr keys, through the signature of a
+// challenge by the browser extension of the user (NIP-07), and the
+// authentication of HTTP requests by signed events (NIP-98). There is no
+// identity provider: the user is their public key, in UserID as hex and in
+// NickName as npub.
+//
+// BeginAuth sends the user to LoginURL, a page of the application, with the
+// challenge in the challenge parameter. The page asks the extension to sign an
+// event of KindAuth holding the challenge in a challenge tag and the callback
+// URL in a relay tag, as NIP-42 does, and posts it as JSON in the event
+// parameter of the callback:
+//
+//	const event = await window.nostr.signEvent({
+//		kind: 22242, created_at: Math.floor(Date.now() / 1000), content: "",
+//		tags: [["challenge", challenge], ["relay", callbackURL]],
+//	})
+//
+// APIs called by Nostr clients check their requests with UserFromRequest.
+// Profiles (kind 0 events) live on relays and are not fetched.
+// Reference: https://github.com/nostr-protocol/nips/blob/master/98.md
+package nostr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Kinds of the events signed to log in.
+const (
+	// KindAuth is the kind of the events signing a login challenge (NIP-42).
+	KindAuth = 22242
+	// KindHTTPAuth is the kind of the events authenticating HTTP requests (NIP-98).
+	KindHTTPAuth = 27235
+)
+
+// ErrNoAuthorization is returned when a request has no Nostr Authorization
+// header.
+var ErrNoAuthorization = errors.New("nostr: request has no Nostr authorization")
+
+// New creates a new Nostr provider. loginURL is the page of the application
+// asking the extension of the user to sign the challenge, and callbackURL the
+// URL the signed event is posted to.
+func New(loginURL, callbackURL string) *Provider {
+	return &Provider{
+		LoginURL:     loginURL,
+		CallbackURL:  callbackURL,
+		MaxAge:       time.Minute,
+		providerName: "nostr",
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for Nostr logins.
+type Provider struct {
+	LoginURL    string
+	CallbackURL string
+
+	// MaxAge is how far from now the events may have been created.
+	MaxAge time.Duration
+
+	// PublicURL, when set, is the scheme and host requests checked by
+	// UserFromRequest were sent to, such as https://api.example.com, for
+	// applications behind a proxy. It is read from the request otherwise.
+	PublicURL string
+
+	providerName string
+}
+
+// Event is a signed Nostr event (NIP-01).
+type Event struct {
+	ID        string     `json:"id"`
+	PubKey    string     `json:"pubkey"`
+	CreatedAt int64      `json:"created_at"`
+	Kind      int        `json:"kind"`
+	Tags      [][]string `json:"tags"`
+	Content   string     `json:"content"`
+	Sig       string     `json:"sig"`
+}
+
+// Tag returns the value of the first tag of the event named name.
+func (e *Event) Tag(name string) string {
+	for _, t := range e.Tags {
+		if len(t) > 1 && t[0] == name {
+			return t[1]
+		}
+	}
+	return ""
+}
+
+// Verify checks the ID of the event is the hash of its content, and the
+// signature of the ID by the public key of the event.
+func (e *Event) Verify() error {
+	id := sha256.Sum256(e.serialize())
+	if hex.EncodeToString(id[:]) != strings.ToLower(e.ID) {
+		return errors.New("nostr: event ID does not match its content")
+	}
+	pubKey, err := hex.DecodeString(e.PubKey)
+	if err != nil {
+		return fmt.Errorf("nostr: %w", err)
+	}
+	sig, err := hex.DecodeString(e.Sig)
+	if err != nil {
+		return fmt.Errorf("nostr: %w", err)
+	}
+	if err := verifySchnorr(pubKey, id[:], sig); err != nil {
+		return fmt.Errorf("nostr: %w", err)
+	}
+	return nil
+}
+
+// serialize returns the serialization of the event its ID is the hash of:
+// [0,<pubkey>,<created_at>,<kind>,<tags>,<content>] in compact JSON.
+func (e *Event) serialize() []byte {
+	var b bytes.Buffer
+	b.WriteString(`[0,`)
+	writeString(&b, strings.ToLower(e.PubKey))
+	b.WriteString("," + strconv.FormatInt(e.CreatedAt, 10) + "," + strconv.Itoa(e.Kind) + ",[")
+	for i, t := range e.Tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('[')
+		for j, v := range t {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			writeString(&b, v)
+		}
+		b.WriteByte(']')
+	}
+	b.WriteString("],")
+	writeString(&b, e.Content)
+	b.WriteByte(']')
+	return b.Bytes()
+}
+
+// writeString writes s as a JSON string escaped as NIP-01 requires, which
+// encoding/json does not.
+func writeString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Debug is a no-op for the nostr package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth sends the user to LoginURL with state as the challenge to sign.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	u, err := url.Parse(p.LoginURL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("challenge", state)
+	q.Set("state", state)
+	u.RawQuery = q.Encode()
+	return &Session{
+		AuthURL:   u.String(),
+		Challenge: state,
+	}, nil
+}
+
+// FetchUser returns the user of the public key that signed the challenge.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		Provider: p.Name(),
+	}
+
+	if sess.PubKey == "" {
+		// data is not yet retrieved since the challenge is not signed yet
+		return user, fmt.Errorf("%s cannot get user information without a signed challenge", p.providerName)
+	}
+	return p.userFromPubKey(sess.PubKey)
+}
+
+// UserFromRequest returns the user of a request authenticated by a signed
+// event of KindHTTPAuth, in its "Authorization: Nostr <base64 event>"
+// header. The event must have been created within MaxAge for the URL and
+// method of the request, and for its body when the event has a payload tag.
+// It returns ErrNoAuthorization when the request has no such header.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	user := goth.User{Provider: p.Name()}
+	header := r.Header.Get("Authorization")
+	if len(header) < 6 || !strings.EqualFold(header[:6], "Nostr ") {
+		return user, ErrNoAuthorization
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(header[6:]))
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	event := &Event{}
+	if err := json.Unmarshal(b, event); err != nil {
+		return user, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	if err := p.verifyEvent(event, KindHTTPAuth); err != nil {
+		return user, err
+	}
+
+	if event.Tag("u") != p.requestURL(r) {
+		return user, fmt.Errorf("%s: event is for %q, not this URL", p.providerName, event.Tag("u"))
+	}
+	if !strings.EqualFold(event.Tag("method"), r.Method) {
+		return user, fmt.Errorf("%s: event is for %s requests", p.providerName, event.Tag("method"))
+	}
+	if payload := event.Tag("payload"); payload != "" {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return user, err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		if !strings.EqualFold(payload, hex.EncodeToString(sum[:])) {
+			return user, fmt.Errorf("%s: event is for another body", p.providerName)
+		}
+	}
+	return p.userFromPubKey(event.PubKey)
+}
+
+// verifyEvent checks the signature, kind and age of event.
+func (p *Provider) verifyEvent(event *Event, kind int) error {
+	if event.Kind != kind {
+		return fmt.Errorf("%s: event is of kind %d, not %d", p.providerName, event.Kind, kind)
+	}
+	age := goth.Now().Sub(time.Unix(event.CreatedAt, 0))
+	if age > p.MaxAge || age < -p.MaxAge {
+		return fmt.Errorf("%s: event was not created within %s", p.providerName, p.MaxAge)
+	}
+	return event.Verify()
+}
+
+// requestURL returns the absolute URL of r, as set in the u tag of events.
+func (p *Provider) requestURL(r *http.Request) string {
+	base := p.PublicURL
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + r.Host
+	}
+	return strings.TrimSuffix(base, "/") + r.URL.RequestURI()
+}
+
+func (p *Provider) userFromPubKey(pubKey string) (goth.User, error) {
+	key, err := hex.DecodeString(pubKey)
+	if err != nil || len(key) != 32 {
+		return goth.User{Provider: p.Name()}, fmt.Errorf("%s: invalid public key %q", p.providerName, pubKey)
+	}
+	pubKey = hex.EncodeToString(key)
+	return goth.User{
+		Provider: p.Name(),
+		UserID:   pubKey,
+		NickName: npub(key),
+		RawData: map[string]interface{}{
+			"pubkey": pubKey,
+			"npub":   npub(key),
+		},
+	}, nil
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Nostr
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Nostr")
+}
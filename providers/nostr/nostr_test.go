@@ -0,0 +1,221 @@
+package nostr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := nostrProvider()
+	a.Equal(provider.LoginURL, "https://app.example.com/login/nostr")
+	a.Equal(provider.CallbackURL, "https://app.example.com/auth/nostr/callback")
+	a.Equal(provider.MaxAge, time.Minute)
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), nostrProvider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := nostrProvider().BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*Session)
+	a.Equal("test_state", s.Challenge)
+	a.Contains(s.AuthURL, "https://app.example.com/login/nostr?")
+	a.Contains(s.AuthURL, "challenge=test_state")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_VerifySchnorr(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	// Test vector 0 of BIP-340.
+	pubKey, _ := hex.DecodeString("f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9")
+	sig, _ := hex.DecodeString("e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0")
+	msg := make([]byte, 32)
+	a.NoError(verifySchnorr(pubKey, msg, sig))
+
+	msg[0] = 1
+	a.Error(verifySchnorr(pubKey, msg, sig))
+}
+
+func Test_Npub(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	pubKey, _ := hex.DecodeString("3bf0c63fcb93463407af97a5e5ee64fa883d107ef9e558472c4eb9aaaefa459d")
+	a.Equal("npub180cvv07tjdrrgpa0j7j7tmnyl2yr6yr7l8j4s3evf6u64th6gkwsyjh6w6", npub(pubKey))
+}
+
+func Test_AuthorizeAndFetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := nostrProvider()
+
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	event := signEvent(&Event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      KindAuth,
+		Tags:      [][]string{{"challenge", "test_state"}, {"relay", p.CallbackURL}},
+		Content:   "Log in to \"Example\"\n",
+	})
+	b, _ := json.Marshal(event)
+
+	pubKey, err := session.Authorize(p, url.Values{"event": {string(b)}})
+	a.NoError(err)
+	a.Equal(event.PubKey, pubKey)
+
+	user, err := p.FetchUser(session)
+	a.NoError(err)
+	a.Equal(event.PubKey, user.UserID)
+	a.True(strings.HasPrefix(user.NickName, "npub1"))
+	a.Equal(user.NickName, user.RawData["npub"])
+}
+
+func Test_AuthorizeRejectsEvents(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := nostrProvider()
+
+	authorize := func(event *Event) error {
+		b, _ := json.Marshal(event)
+		_, err := (&Session{Challenge: "test_state"}).Authorize(p, url.Values{"event": {string(b)}})
+		return err
+	}
+	valid := func() *Event {
+		return &Event{
+			CreatedAt: time.Now().Unix(),
+			Kind:      KindAuth,
+			Tags:      [][]string{{"challenge", "test_state"}, {"relay", p.CallbackURL}},
+		}
+	}
+
+	a.NoError(authorize(signEvent(valid())))
+
+	e := valid()
+	e.Tags[0][1] = "other_state"
+	a.Error(authorize(signEvent(e)), "another challenge")
+
+	e = valid()
+	e.Tags[1][1] = "https://evil.example.com/callback"
+	a.Error(authorize(signEvent(e)), "another site")
+
+	e = valid()
+	e.CreatedAt -= 3600
+	a.Error(authorize(signEvent(e)), "too old")
+
+	e = valid()
+	e.Kind = 1
+	a.Error(authorize(signEvent(e)), "another kind")
+
+	e = signEvent(valid())
+	e.Content = "tampered"
+	a.Error(authorize(e), "content changed after signing")
+
+	_, err := (&Session{Challenge: "test_state"}).Authorize(p, url.Values{})
+	a.Error(err)
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := nostrProvider()
+
+	body := []byte(`{"note":"hello"}`)
+	sum := sha256.Sum256(body)
+	event := signEvent(&Event{
+		CreatedAt: time.Now().Unix(),
+		Kind:      KindHTTPAuth,
+		Tags: [][]string{
+			{"u", "http://api.example.com/notes?draft=1"},
+			{"method", "POST"},
+			{"payload", hex.EncodeToString(sum[:])},
+		},
+	})
+	request := func(body []byte) *http.Request {
+		r := httptest.NewRequest("POST", "http://api.example.com/notes?draft=1", bytes.NewReader(body))
+		b, _ := json.Marshal(event)
+		r.Header.Set("Authorization", "Nostr "+base64.StdEncoding.EncodeToString(b))
+		return r
+	}
+
+	r := request(body)
+	user, err := p.UserFromRequest(r)
+	a.NoError(err)
+	a.Equal(event.PubKey, user.UserID)
+	read, _ := ioutil.ReadAll(r.Body)
+	a.Equal(body, read, "the body is still readable")
+
+	_, err = p.UserFromRequest(request([]byte(`{"note":"bye"}`)))
+	a.Error(err)
+
+	r = request(body)
+	r.Method = "PUT"
+	_, err = p.UserFromRequest(r)
+	a.Error(err)
+
+	r = request(body)
+	r.Host = "evil.example.com"
+	_, err = p.UserFromRequest(r)
+	a.Error(err)
+
+	r = request(body)
+	r.Header.Del("Authorization")
+	_, err = p.UserFromRequest(r)
+	a.Equal(ErrNoAuthorization, err)
+}
+
+func nostrProvider() *Provider {
+	return New("https://app.example.com/login/nostr", "https://app.example.com/auth/nostr/callback")
+}
+
+// testKey is the private key the test events are signed with.
+var testKey, _ = btcec.PrivKeyFromBytes(mustHex("1f2e3d4c5b6a79881f2e3d4c5b6a79881f2e3d4c5b6a79881f2e3d4c5b6a7988"))
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// signEvent sets the public key, ID and BIP-340 signature of event.
+func signEvent(event *Event) *Event {
+	event.PubKey = hex.EncodeToString(schnorr.SerializePubKey(testKey.PubKey()))
+	id := sha256.Sum256(event.serialize())
+	event.ID = hex.EncodeToString(id[:])
+
+	sig, err := schnorr.Sign(testKey, id[:])
+	if err != nil {
+		panic(err)
+	}
+	event.Sig = hex.EncodeToString(sig.Serialize())
+	return event
+}
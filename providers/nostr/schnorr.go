@@ -0,0 +1,91 @@
+package nostr
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// verifySchnorr checks the BIP-340 signature sig of msg by the x-only public
+// key pubKey, all of them 32 bytes but the 64 bytes signature.
+func verifySchnorr(pubKey, msg, sig []byte) error {
+	key, err := schnorr.ParsePubKey(pubKey)
+	if err != nil {
+		return err
+	}
+	signature, err := schnorr.ParseSignature(sig)
+	if err != nil {
+		return err
+	}
+	if !signature.Verify(msg, key) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// npub encodes an x-only public key as a NIP-19 npub, the form users know
+// their key by.
+func npub(pubKey []byte) string {
+	data := convertBits(pubKey, 8, 5)
+	hrp := "npub"
+	values := append(hrpExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+
+	var b strings.Builder
+	b.WriteString(hrp + "1")
+	for _, d := range data {
+		b.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	return b.String()
+}
+
+func hrpExpand(hrp string) []byte {
+	r := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		r = append(r, hrp[i]>>5)
+	}
+	r = append(r, 0)
+	for i := 0; i < len(hrp); i++ {
+		r = append(r, hrp[i]&31)
+	}
+	return r
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func convertBits(data []byte, from, to uint) []byte {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	r := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, d := range data {
+		acc = acc<<from | uint(d)
+		bits += from
+		for bits >= to {
+			bits -= to
+			r = append(r, byte(acc>>bits&maxv))
+		}
+	}
+	if bits > 0 {
+		r = append(r, byte(acc<<(to-bits)&maxv))
+	}
+	return r
+}
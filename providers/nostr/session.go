@@ -0,0 +1,73 @@
+package nostr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with Nostr.
+type Session struct {
+	AuthURL   string
+	Challenge string
+	PubKey    string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Nostr provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize checks the event posted to the callback signs the challenge of
+// the session for the callback URL, and returns the public key that signed it.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p, ok := provider.(*Provider)
+	if !ok {
+		return "", fmt.Errorf("nostr: cannot authorize with a %T provider", provider)
+	}
+	data := params.Get("event")
+	if data == "" {
+		return "", errors.New("nostr: the callback has no signed event")
+	}
+	event := &Event{}
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		return "", fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	if err := p.verifyEvent(event, KindAuth); err != nil {
+		return "", err
+	}
+	if s.Challenge == "" || event.Tag("challenge") != s.Challenge {
+		return "", fmt.Errorf("%s: event does not sign the challenge of the session", p.providerName)
+	}
+	if event.Tag("relay") != p.CallbackURL {
+		return "", fmt.Errorf("%s: event is for %q, not %q", p.providerName, event.Tag("relay"), p.CallbackURL)
+	}
+
+	s.PubKey = strings.ToLower(event.PubKey)
+	return s.PubKey, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
@@ -0,0 +1,58 @@
+package nostr_test
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/bgdsh/goth/providers/nostr"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Session(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &nostr.Session{}
+
+	a.Implements((*goth.Session)(nil), s)
+}
+
+func Test_GetAuthURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &nostr.Session{}
+
+	_, err := s.GetAuthURL()
+	a.Error(err)
+
+	s.AuthURL = "/foo"
+
+	url, _ := s.GetAuthURL()
+	a.Equal(url, "/foo")
+}
+
+func Test_AuthorizeWithOtherProvider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &nostr.Session{Challenge: "challenge"}
+
+	_, err := s.Authorize(&faux.Provider{}, nil)
+	a.EqualError(err, "nostr: cannot authorize with a *faux.Provider provider")
+}
+
+func Test_ToJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &nostr.Session{}
+
+	data := s.Marshal()
+	a.Equal(data, `{"AuthURL":"","Challenge":"","PubKey":""}`)
+}
+
+func Test_String(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &nostr.Session{}
+
+	a.Equal(s.String(), s.Marshal())
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"fmt"
 
@@ -21,10 +22,12 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 	issuerURL    string
 	profileURL   string
+	resources    []string
 }
 
 // New creates a new Okta provider and sets up important connection details.
@@ -66,16 +69,49 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the okta package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks okta for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	authURL, err := goth.AppendResources(p.config.AuthCodeURL(state, pkce...), p.resources...)
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL:      authURL,
+		CodeVerifier: verifier,
 	}, nil
 }
 
+// SetResources sets the resource indicators sent on the authorize and token
+// requests, so the issued access token is restricted to those APIs.
+// See https://datatracker.ietf.org/doc/html/rfc8707
+func (p *Provider) SetResources(resources ...string) {
+	p.resources = resources
+}
+
 // FetchUser will go to okta and access basic information about the user.
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	sess := session.(*Session)
@@ -107,7 +143,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -149,6 +185,7 @@ func userFromReader(r io.Reader, user *goth.User) error {
 	u := struct {
 		Name       string `json:"name"`
 		Email      string `json:"email"`
+		Verified   bool   `json:"email_verified"`
 		FirstName  string `json:"given_name"`
 		LastName   string `json:"family_name"`
 		NickName   string `json:"nickname"`
@@ -172,6 +209,7 @@ func userFromReader(r io.Reader, user *goth.User) error {
 
 	user.UserID = u.ID
 	user.Email = u.Email
+	user.EmailVerified = u.Verified
 	user.Name = u.Name
 	user.NickName = u.NickName
 	user.FirstName = u.FirstName
@@ -197,3 +235,55 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	}
 	return newToken, err
 }
+
+// TokenExchange trades subjectToken for a token issued to the given audience
+// using OAuth 2.0 Token Exchange (RFC 8693).
+func (p *Provider) TokenExchange(subjectToken, audience string, scopes ...string) (*oauth2.Token, error) {
+	return goth.ExchangeToken(p.Client(), goth.TokenExchangeRequest{
+		TokenURL:     p.config.Endpoint.TokenURL,
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		SubjectToken: subjectToken,
+		Audience:     audience,
+		Scopes:       scopes,
+	})
+}
+
+// RevokeToken revokes an access or refresh token with the revocation end-point
+// of the authorization server the tokens come from.
+func (p *Provider) RevokeToken(token string) error {
+	return goth.Revoke(p.Client(), goth.RevocationRequest{
+		RevocationURL: strings.TrimSuffix(p.config.Endpoint.TokenURL, "/token") + "/revoke",
+		ClientID:      p.ClientKey,
+		ClientSecret:  p.Secret,
+		Token:         token,
+	})
+}
+
+// Introspect asks the introspection end-point of the authorization server the
+// token comes from whether it is active, for resource servers handed tokens
+// issued to the client.
+func (p *Provider) Introspect(token string) (*goth.Introspection, error) {
+	return goth.Introspect(p.Client(), goth.IntrospectionRequest{
+		IntrospectionURL: strings.TrimSuffix(p.config.Endpoint.TokenURL, "/token") + "/introspect",
+		ClientID:         p.ClientKey,
+		ClientSecret:     p.Secret,
+		Token:            token,
+	})
+}
+
+// ClientCredentialsToken requests a token for the application itself with the
+// client credentials grant, for the resource set with SetResources if there
+// is one.
+func (p *Provider) ClientCredentialsToken(scopes ...string) (*oauth2.Token, error) {
+	r := goth.ClientCredentialsRequest{
+		TokenURL:     p.config.Endpoint.TokenURL,
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		Scopes:       scopes,
+	}
+	if len(p.resources) == 1 {
+		r.Resource = p.resources[0]
+	}
+	return goth.RequestClientCredentials(p.Client(), r)
+}
@@ -1,6 +1,8 @@
 package okta_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -45,6 +47,17 @@ func Test_BeginAuth(t *testing.T) {
 	a.Contains(s.AuthURL, os.Getenv("OKTA_ORG_URL"))
 }
 
+func Test_BeginAuthWithResources(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	p.SetResources("https://api.example.com")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*okta.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "resource=https%3A%2F%2Fapi.example.com")
+}
+
 func Test_SessionFromJSON(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -58,6 +71,42 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_RevokeToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth2/default/v1/revoke", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("1234567890", r.PostForm.Get("token"))
+		id, _, _ := r.BasicAuth()
+		a.Equal("client", id)
+	}))
+	defer ts.Close()
+
+	p := okta.New("client", "secret", ts.URL, "/foo")
+	a.NoError(goth.RevokeToken(p, "1234567890"))
+}
+
+func Test_Introspect(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth2/default/v1/introspect", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("1234567890", r.PostForm.Get("token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"active":false}`))
+	}))
+	defer ts.Close()
+
+	p := okta.New("client", "secret", ts.URL, "/foo")
+	i, err := goth.IntrospectToken(p, "1234567890")
+	a.NoError(err)
+	a.False(i.Valid())
+}
+
 func provider() *okta.Provider {
 	return okta.New(os.Getenv("OKTA_ID"), os.Getenv("OKTA_SECRET"), os.Getenv("OKTA_ORG_URL"), "/foo")
 }
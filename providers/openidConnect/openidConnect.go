@@ -2,42 +2,40 @@ package openidConnect
 
 import (
 	"bytes"
-	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
 	"golang.org/x/oauth2"
 )
 
 const (
 	// Standard Claims http://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
 	// fixed, cannot be changed
-	subjectClaim  = "sub"
-	expiryClaim   = "exp"
-	audienceClaim = "aud"
-	issuerClaim   = "iss"
+	subjectClaim = "sub"
 
 	PreferredUsernameClaim = "preferred_username"
 	EmailClaim             = "email"
+	EmailVerifiedClaim     = "email_verified"
 	NameClaim              = "name"
 	NicknameClaim          = "nickname"
 	PictureClaim           = "picture"
 	GivenNameClaim         = "given_name"
 	FamilyNameClaim        = "family_name"
 	AddressClaim           = "address"
+	GroupsClaim            = "groups"
 
 	// Unused but available to set in Provider claims
 	MiddleNameClaim          = "middle_name"
 	ProfileClaim             = "profile"
 	WebsiteClaim             = "website"
-	EmailVerifiedClaim       = "email_verified"
 	GenderClaim              = "gender"
 	BirthdateClaim           = "birthdate"
 	ZoneinfoClaim            = "zoneinfo"
@@ -45,8 +43,6 @@ const (
 	PhoneNumberClaim         = "phone_number"
 	PhoneNumberVerifiedClaim = "phone_number_verified"
 	UpdatedAtClaim           = "updated_at"
-
-	clockSkew = 10 * time.Second
 )
 
 // Provider is the implementation of `goth.Provider` for accessing OpenID Connect provider
@@ -55,9 +51,12 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	OpenIDConfig *OpenIDConfig
 	config       *oauth2.Config
 	providerName string
+	resources    []string
+	authParams   map[string]string
 
 	UserIdClaims    []string
 	NameClaims      []string
@@ -67,8 +66,19 @@ type Provider struct {
 	FirstNameClaims []string
 	LastNameClaims  []string
 	LocationClaims  []string
+	GroupsClaims    []string
+	// OrganizationClaims name the claims holding the organizations or
+	// tenants of the user, none are read by default.
+	OrganizationClaims []string
 
 	SkipUserInfoRequest bool
+
+	// discovery is the discovery shared by the copies of a provider of
+	// NewLazy, nil for those of New.
+	discovery *discovery
+	scopes    []string
+	options   []goth.ProviderOptions
+	authStyle oauth2.AuthStyle
 }
 
 type OpenIDConfig struct {
@@ -79,8 +89,25 @@ type OpenIDConfig struct {
 	// If OpenID discovery is enabled, the end_session_endpoint field can optionally be provided
 	// in the discovery endpoint response according to OpenID spec. See:
 	// https://openid.net/specs/openid-connect-session-1_0-17.html#OPMetadata
-	EndSessionEndpoint string `json:"end_session_endpoint, omitempty"`
+	EndSessionEndpoint string `json:"end_session_endpoint,omitempty"`
 	Issuer             string `json:"issuer"`
+
+	// JWKSURI is where the keys signing the ID tokens are published.
+	JWKSURI string `json:"jwks_uri,omitempty"`
+
+	// IDTokenSigningAlgValuesSupported lists the algorithms the ID tokens may
+	// be signed with, RS256 when empty.
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported,omitempty"`
+
+	// RevocationEndpoint is the RFC 7009 end-point RevokeToken uses, if any.
+	RevocationEndpoint string `json:"revocation_endpoint,omitempty"`
+
+	// IntrospectionEndpoint is the RFC 7662 end-point Introspect uses, if any.
+	IntrospectionEndpoint string `json:"introspection_endpoint,omitempty"`
+
+	// GrantTypesSupported lists the grants of the token end-point, if the
+	// discovery document advertises them.
+	GrantTypesSupported []string `json:"grant_types_supported,omitempty"`
 }
 
 type RefreshTokenResponse struct {
@@ -90,7 +117,7 @@ type RefreshTokenResponse struct {
 	// refresh token flow. As a result, a new ID token may not be returned in a successful
 	// response.
 	// See more: https://openid.net/specs/openid-connect-core-1_0.html#RefreshingAccessToken
-	IdToken string `json:"id_token, omitempty"`
+	IdToken string `json:"id_token,omitempty"`
 
 	// The OAuth spec defines the refresh token as an optional response field in the
 	// refresh token flow. As a result, a new refresh token may not be returned in a successful
@@ -107,7 +134,19 @@ type RefreshTokenResponse struct {
 // ID Token decryption is not (yet) supported
 // UserInfo decryption is not (yet) supported
 func New(clientKey, secret, callbackURL, openIDAutoDiscoveryURL string, scopes ...string) (*Provider, error) {
-	p := &Provider{
+	p := newProvider(clientKey, secret, callbackURL)
+	openIDConfig, err := getOpenIDConfig(p, openIDAutoDiscoveryURL)
+	if err != nil {
+		return nil, err
+	}
+	p.OpenIDConfig = openIDConfig
+
+	p.config = newConfig(p, scopes, openIDConfig)
+	return p, nil
+}
+
+func newProvider(clientKey, secret, callbackURL string) *Provider {
+	return &Provider{
 		ClientKey:   clientKey,
 		Secret:      secret,
 		CallbackURL: callbackURL,
@@ -120,18 +159,96 @@ func New(clientKey, secret, callbackURL, openIDAutoDiscoveryURL string, scopes .
 		FirstNameClaims: []string{GivenNameClaim},
 		LastNameClaims:  []string{FamilyNameClaim},
 		LocationClaims:  []string{AddressClaim},
+		GroupsClaims:    []string{GroupsClaim},
 
 		providerName: "openid-connect",
 	}
+}
 
-	openIDConfig, err := getOpenIDConfig(p, openIDAutoDiscoveryURL)
+var (
+	// DiscoveryRetryDelay is how long the providers of NewLazy wait before
+	// fetching again a discovery document they failed to fetch. The delay
+	// doubles with every failure, up to DiscoveryMaxRetryDelay.
+	DiscoveryRetryDelay    = time.Second
+	DiscoveryMaxRetryDelay = time.Minute
+
+	// RediscoveryInterval is how often the providers of NewLazy fetch their
+	// discovery document again, to pick up new end-points. They keep the
+	// previous document when it fails.
+	RediscoveryInterval = 24 * time.Hour
+)
+
+// NewLazy creates an OpenID Connect provider like New, but fetches the
+// discovery document when it is first needed, by BeginAuth, rather than at
+// once, so that applications can start while their identity provider is
+// unreachable. Until the document is fetched, the provider fails with the
+// error of the last attempt, and tries again once DiscoveryRetryDelay has
+// passed. The document is then fetched again every RediscoveryInterval.
+func NewLazy(clientKey, secret, callbackURL, openIDAutoDiscoveryURL string, scopes ...string) *Provider {
+	p := newProvider(clientKey, secret, callbackURL)
+	p.discovery = &discovery{url: openIDAutoDiscoveryURL}
+	p.scopes = scopes
+	return p
+}
+
+// discovery fetches the discovery document of the providers of NewLazy.
+type discovery struct {
+	url string
+
+	mu        sync.Mutex
+	config    *OpenIDConfig
+	fetchedAt time.Time
+	err       error
+	delay     time.Duration
+	retryAt   time.Time
+}
+
+// get returns the discovery document, fetching it when it was never fetched
+// or is older than RediscoveryInterval, and no failure is being waited out.
+func (d *discovery) get(p *Provider) (*OpenIDConfig, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := goth.Now()
+	fresh := d.config != nil && (RediscoveryInterval <= 0 || now.Before(d.fetchedAt.Add(RediscoveryInterval)))
+	if fresh || now.Before(d.retryAt) {
+		return d.config, d.err
+	}
+
+	config, err := getOpenIDConfig(p, d.url)
 	if err != nil {
-		return nil, err
+		if d.delay *= 2; d.delay == 0 {
+			d.delay = DiscoveryRetryDelay
+		}
+		if d.delay > DiscoveryMaxRetryDelay {
+			d.delay = DiscoveryMaxRetryDelay
+		}
+		d.retryAt = now.Add(d.delay)
+		if d.config == nil {
+			d.err = fmt.Errorf("%s: cannot fetch the discovery document: %w", p.Name(), err)
+		}
+		return d.config, d.err
 	}
-	p.OpenIDConfig = openIDConfig
+	d.config, d.fetchedAt, d.err, d.delay, d.retryAt = config, now, nil, 0, time.Time{}
+	return config, nil
+}
 
-	p.config = newConfig(p, scopes, openIDConfig)
-	return p, nil
+// discovered returns the provider or, for the providers of NewLazy, a copy of
+// it with the end-points of its discovery document.
+func (p *Provider) discovered() (*Provider, error) {
+	if p.discovery == nil {
+		return p, nil
+	}
+	config, err := p.discovery.get(p)
+	if err != nil {
+		return nil, err
+	}
+	c := *p
+	c.OpenIDConfig = config
+	c.config = newConfig(&c, c.scopes, config)
+	for _, o := range c.options {
+		c.config = o.OAuth2Config(c.config)
+	}
+	return &c, nil
 }
 
 // Name is the name used to retrieve this provider later.
@@ -148,21 +265,71 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.options = append(append([]goth.ProviderOptions{}, p.options...), options)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the openidConnect package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks the OpenID Connect provider for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	p, err := p.discovered()
+	if err != nil {
+		return nil, err
+	}
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := idtoken.NewNonce()
+	if err != nil {
+		return nil, err
+	}
+	opts := append(pkce, oauth2.SetAuthURLParam("nonce", nonce))
+	for key, value := range p.authParams {
+		opts = append(opts, oauth2.SetAuthURLParam(key, value))
+	}
+	url, err := goth.AppendResources(goth.ScopeFormatOf(p.Name(), goth.SpaceSeparatedScopes).AuthCodeURL(p.config, state, opts...), p.resources...)
+	if err != nil {
+		return nil, err
+	}
 	session := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
 	}
 	return session, nil
 }
 
+// SetResources sets the resource indicators sent on the authorize and token
+// requests, so the issued access token is restricted to those APIs.
+// See https://datatracker.ietf.org/doc/html/rfc8707
+func (p *Provider) SetResources(resources ...string) {
+	p.resources = resources
+}
+
 // FetchUser will use the the id_token and access requested information about the user.
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	sess := session.(*Session)
+	p, err := p.discovered()
+	if err != nil {
+		return goth.User{}, err
+	}
 
 	expiresAt := sess.ExpiresAt
 
@@ -170,19 +337,13 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return goth.User{}, fmt.Errorf("%s cannot get user information without id_token", p.providerName)
 	}
 
-	// decode returned id token to get expiry
-	claims, err := decodeJWT(sess.IDToken)
-
-	if err != nil {
-		return goth.User{}, fmt.Errorf("oauth2: error decoding JWT token: %v", err)
-	}
-
-	expiry, err := p.validateClaims(claims)
+	idToken, err := p.verifier().Verify(p.Client(), sess.IDToken, sess.Nonce)
 	if err != nil {
-		return goth.User{}, fmt.Errorf("oauth2: error validating JWT token: %v", err)
+		return goth.User{}, fmt.Errorf("%s: %w", p.providerName, err)
 	}
+	claims := idToken.Raw
 
-	if expiry.Before(expiresAt) {
+	if expiry := idToken.ExpiresAt.Time; expiry.Before(expiresAt) {
 		expiresAt = expiry
 	}
 
@@ -210,8 +371,12 @@ func (p *Provider) RefreshTokenAvailable() bool {
 
 //RefreshToken get new access token based on the refresh token
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	p, err := p.discovered()
+	if err != nil {
+		return nil, err
+	}
 	token := &oauth2.Token{RefreshToken: refreshToken}
-	ts := p.config.TokenSource(oauth2.NoContext, token)
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
 	newToken, err := ts.Token()
 	if err != nil {
 		return nil, err
@@ -225,6 +390,10 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 // compatibility purposes) that also returns the id_token in the OpenID refresh token flow API response
 // Learn more about ID tokens: https://openid.net/specs/openid-connect-core-1_0.html#IDToken
 func (p *Provider) RefreshTokenWithIDToken(refreshToken string) (*RefreshTokenResponse, error) {
+	p, err := p.discovered()
+	if err != nil {
+		return nil, err
+	}
 	urlValues := url.Values{
 		"grant_type":    {"refresh_token"},
 		"refresh_token": {refreshToken},
@@ -262,37 +431,22 @@ func (p *Provider) RefreshTokenWithIDToken(refreshToken string) (*RefreshTokenRe
 	return refreshTokenResponse, nil
 }
 
-// validate according to standard, returns expiry
+// verifier validates the ID tokens of the provider, as its discovery
+// document describes them.
 // http://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation
-func (p *Provider) validateClaims(claims map[string]interface{}) (time.Time, error) {
-	audience := getClaimValue(claims, []string{audienceClaim})
-	if audience != p.ClientKey {
-		found := false
-		audiences := getClaimValues(claims, []string{audienceClaim})
-		for _, aud := range audiences {
-			if aud == p.ClientKey {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return time.Time{}, errors.New("audience in token does not match client key")
+func (p *Provider) verifier() *idtoken.Verifier {
+	v := &idtoken.Verifier{
+		KeysURL:  p.OpenIDConfig.JWKSURI,
+		ClientID: p.ClientKey,
+		Issuers:  []string{p.OpenIDConfig.Issuer},
+		Secret:   p.Secret,
+	}
+	for _, alg := range p.OpenIDConfig.IDTokenSigningAlgValuesSupported {
+		if alg != "none" {
+			v.Algorithms = append(v.Algorithms, alg)
 		}
 	}
-
-	issuer := getClaimValue(claims, []string{issuerClaim})
-	if issuer != p.OpenIDConfig.Issuer {
-		return time.Time{}, errors.New("issuer in token does not match issuer in OpenIDConfig discovery")
-	}
-
-	// expiry is required for JWT, not for UserInfoResponse
-	// is actually a int64, so force it in to that type
-	expiryClaim := int64(claims[expiryClaim].(float64))
-	expiry := time.Unix(expiryClaim, 0)
-	if expiry.Add(clockSkew).Before(time.Now()) {
-		return time.Time{}, errors.New("user info JWT token is expired")
-	}
-	return expiry, nil
+	return v
 }
 
 func (p *Provider) userFromClaims(claims map[string]interface{}, user *goth.User) {
@@ -302,12 +456,25 @@ func (p *Provider) userFromClaims(claims map[string]interface{}, user *goth.User
 	user.Name = getClaimValue(claims, p.NameClaims)
 	user.NickName = getClaimValue(claims, p.NickNameClaims)
 	user.Email = getClaimValue(claims, p.EmailClaims)
+	user.EmailVerified = goth.BoolClaim(claims, EmailVerifiedClaim)
 	user.AvatarURL = getClaimValue(claims, p.AvatarURLClaims)
 	user.FirstName = getClaimValue(claims, p.FirstNameClaims)
 	user.LastName = getClaimValue(claims, p.LastNameClaims)
 	user.Location = getClaimValue(claims, p.LocationClaims)
 }
 
+// Groups returns the groups of user, read from the GroupsClaims of the ID
+// token and user info it was created from.
+func (p *Provider) Groups(user goth.User) []string {
+	return getClaimStrings(user.RawData, p.GroupsClaims)
+}
+
+// Organizations returns the organizations of user, read from the
+// OrganizationClaims of the ID token and user info it was created from.
+func (p *Provider) Organizations(user goth.User) []string {
+	return getClaimStrings(user.RawData, p.OrganizationClaims)
+}
+
 func (p *Provider) getUserInfo(accessToken string, claims map[string]interface{}) error {
 	// skip if there is no UserInfoEndpoint or is explicitly disabled
 	if p.OpenIDConfig.UserInfoEndpoint == "" || p.SkipUserInfoRequest {
@@ -373,6 +540,9 @@ func getOpenIDConfig(p *Provider, openIDAutoDiscoveryURL string) (*OpenIDConfig,
 		return nil, err
 	}
 	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch the discovery document", res)
+	}
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
@@ -394,8 +564,9 @@ func newConfig(provider *Provider, scopes []string, openIDConfig *OpenIDConfig)
 		ClientSecret: provider.Secret,
 		RedirectURL:  provider.CallbackURL,
 		Endpoint: oauth2.Endpoint{
-			AuthURL:  openIDConfig.AuthEndpoint,
-			TokenURL: openIDConfig.TokenEndpoint,
+			AuthURL:   openIDConfig.AuthEndpoint,
+			TokenURL:  openIDConfig.TokenEndpoint,
+			AuthStyle: provider.authStyle,
 		},
 		Scopes: []string{},
 	}
@@ -450,25 +621,123 @@ func getClaimValues(data map[string]interface{}, claims []string) []string {
 	return result
 }
 
+// getClaimStrings returns the values of claims holding either a list of
+// strings or a single string.
+func getClaimStrings(data map[string]interface{}, claims []string) []string {
+	var result []string
+
+	for _, claim := range claims {
+		if s, ok := data[claim].(string); ok && len(s) > 0 {
+			result = append(result, s)
+			continue
+		}
+		result = append(result, getClaimValues(data, []string{claim})...)
+	}
+
+	return result
+}
+
 // decodeJWT decodes a JSON Web Token into a simple map
 // http://openid.net/specs/draft-jones-json-web-token-07.html
-func decodeJWT(jwt string) (map[string]interface{}, error) {
-	jwtParts := strings.Split(jwt, ".")
-	if len(jwtParts) != 3 {
-		return nil, errors.New("jws: invalid token received, not all parts available")
-	}
+func unMarshal(payload []byte) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
 
-	decodedPayload, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(jwtParts[1])
+	return data, json.NewDecoder(bytes.NewBuffer(payload)).Decode(&data)
+}
 
+// TokenExchange trades subjectToken for a token issued to the given audience
+// using OAuth 2.0 Token Exchange (RFC 8693). Keycloak and other identity
+// providers that implement the token exchange grant can be used this way.
+func (p *Provider) TokenExchange(subjectToken, audience string, scopes ...string) (*oauth2.Token, error) {
+	p, err := p.discovered()
 	if err != nil {
 		return nil, err
 	}
+	return goth.ExchangeToken(p.Client(), goth.TokenExchangeRequest{
+		TokenURL:     p.OpenIDConfig.TokenEndpoint,
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		SubjectToken: subjectToken,
+		Audience:     audience,
+		Scopes:       scopes,
+	})
+}
 
-	return unMarshal(decodedPayload)
+// RevokeToken revokes an access or refresh token with the revocation_endpoint
+// of the discovery document. It returns a *goth.RevocationNotSupportedError
+// when the provider advertises none.
+func (p *Provider) RevokeToken(token string) error {
+	p, err := p.discovered()
+	if err != nil {
+		return err
+	}
+	if p.OpenIDConfig.RevocationEndpoint == "" {
+		return &goth.RevocationNotSupportedError{Provider: p.providerName}
+	}
+	return goth.Revoke(p.Client(), goth.RevocationRequest{
+		RevocationURL: p.OpenIDConfig.RevocationEndpoint,
+		ClientID:      p.ClientKey,
+		ClientSecret:  p.Secret,
+		AuthStyle:     p.config.Endpoint.AuthStyle,
+		Token:         token,
+	})
 }
 
-func unMarshal(payload []byte) (map[string]interface{}, error) {
-	data := make(map[string]interface{})
+// Introspect asks the introspection_endpoint of the discovery document whether
+// token is active. It returns a *goth.IntrospectionNotSupportedError when the
+// provider advertises none.
+func (p *Provider) Introspect(token string) (*goth.Introspection, error) {
+	p, err := p.discovered()
+	if err != nil {
+		return nil, err
+	}
+	if p.OpenIDConfig.IntrospectionEndpoint == "" {
+		return nil, &goth.IntrospectionNotSupportedError{Provider: p.providerName}
+	}
+	return goth.Introspect(p.Client(), goth.IntrospectionRequest{
+		IntrospectionURL: p.OpenIDConfig.IntrospectionEndpoint,
+		ClientID:         p.ClientKey,
+		ClientSecret:     p.Secret,
+		AuthStyle:        p.config.Endpoint.AuthStyle,
+		Token:            token,
+	})
+}
 
-	return data, json.NewDecoder(bytes.NewBuffer(payload)).Decode(&data)
+// ClientCredentialsToken requests a token for the application itself with the
+// client credentials grant, for the resource set with SetResources if there
+// is one. It returns a *goth.ClientCredentialsNotSupportedError when the
+// discovery document lists the supported grant types without it.
+func (p *Provider) ClientCredentialsToken(scopes ...string) (*oauth2.Token, error) {
+	p, err := p.discovered()
+	if err != nil {
+		return nil, err
+	}
+	if !p.supportsGrant("client_credentials") {
+		return nil, &goth.ClientCredentialsNotSupportedError{Provider: p.providerName}
+	}
+	r := goth.ClientCredentialsRequest{
+		TokenURL:     p.config.Endpoint.TokenURL,
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		AuthStyle:    p.config.Endpoint.AuthStyle,
+		Scopes:       scopes,
+	}
+	if len(p.resources) == 1 {
+		r.Resource = p.resources[0]
+	}
+	return goth.RequestClientCredentials(p.Client(), r)
+}
+
+// supportsGrant reports whether the discovery document lists grant among the
+// supported grant types, or does not list them.
+func (p *Provider) supportsGrant(grant string) bool {
+	if len(p.OpenIDConfig.GrantTypesSupported) == 0 {
+		return true
+	}
+	for _, g := range p.OpenIDConfig.GrantTypesSupported {
+		if g == grant {
+			return true
+		}
+	}
+	return false
 }
@@ -1,14 +1,23 @@
 package openidConnect
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/bgdsh/goth"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 var (
@@ -73,7 +82,289 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal("abc", session.IDToken)
 }
 
+func Test_ClientCredentialsToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := openidConnectProvider()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("client_credentials", r.PostForm.Get("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer ts.Close()
+	provider.config.Endpoint.TokenURL = ts.URL
+
+	token, err := provider.ClientCredentialsToken("jobs:run")
+	a.NoError(err)
+	a.Equal("1234567890", token.AccessToken)
+
+	provider.OpenIDConfig.GrantTypesSupported = []string{"authorization_code", "refresh_token"}
+	var notSupported *goth.ClientCredentialsNotSupportedError
+	_, err = provider.ClientCredentialsToken("jobs:run")
+	a.True(errors.As(err, &notSupported))
+}
+
+func Test_Introspect(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := openidConnectProvider()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("1234567890", r.PostForm.Get("token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"active":true,"sub":"user","scope":"openid email"}`))
+	}))
+	defer ts.Close()
+
+	var notSupported *goth.IntrospectionNotSupportedError
+	_, err := goth.IntrospectToken(provider, "1234567890")
+	a.True(errors.As(err, &notSupported))
+
+	provider.OpenIDConfig.IntrospectionEndpoint = ts.URL
+	i, err := goth.IntrospectToken(provider, "1234567890")
+	a.NoError(err)
+	a.True(i.Valid())
+	a.Equal("user", i.Subject)
+	a.Equal([]string{"openid", "email"}, i.Scopes)
+}
+
+func Test_FetchUserValidatesIDToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k, err := jwk.New(&key.PublicKey)
+		a.NoError(err)
+		a.NoError(k.Set(jwk.KeyIDKey, "k1"))
+		set := jwk.NewSet()
+		set.Add(k)
+		a.NoError(json.NewEncoder(w).Encode(set))
+	}))
+	defer ts.Close()
+
+	provider := openidConnectProvider()
+	provider.ClientKey = "client"
+	provider.SkipUserInfoRequest = true
+	provider.OpenIDConfig.JWKSURI = ts.URL
+	a.Equal([]string{"RS256"}, provider.OpenIDConfig.IDTokenSigningAlgValuesSupported)
+
+	session, err := provider.BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*Session)
+	authURL, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	a.Equal(s.Nonce, authURL.Query().Get("nonce"))
+
+	idToken := func(nonce string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":   "https://accounts.google.com",
+			"sub":   "user",
+			"aud":   "client",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": nonce,
+			"email": "homer@example.com",
+			// Some providers send the boolean claims as strings.
+			"email_verified": "true",
+		})
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		a.NoError(err)
+		return signed
+	}
+
+	s.AccessToken = "1234567890"
+	s.IDToken = idToken(s.Nonce)
+	user, err := provider.FetchUser(s)
+	a.NoError(err)
+	a.Equal("user", user.UserID)
+	a.Equal("homer@example.com", user.Email)
+	a.True(user.EmailVerified)
+
+	s.IDToken = idToken("replayed")
+	_, err = provider.FetchUser(s)
+	a.EqualError(err, "openid-connect: id token: nonce is incorrect")
+}
+
 func openidConnectProvider() *Provider {
 	provider, _ := New(os.Getenv("OPENID_CONNECT_KEY"), os.Getenv("OPENID_CONNECT_SECRET"), "http://localhost/foo", server.URL)
 	return provider
 }
+
+func Test_NewPreset(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	preset := CILogon
+	preset.DiscoveryURL = server.URL
+	provider, err := NewPreset("key", "secret", "http://localhost/foo", preset)
+	a.NoError(err)
+	a.Equal("cilogon", provider.Name())
+	a.Equal([]string{"eppn", PreferredUsernameClaim}, provider.NickNameClaims)
+
+	session, err := provider.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*Session).AuthURL, "scope=openid+profile+email+org.cilogon.userinfo")
+}
+
+func Test_NewPresetEnterprise(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal("https://sso.example.com/.well-known/openid-configuration", PingFederate("https://sso.example.com/").DiscoveryURL)
+	a.Equal("https://auth.pingone.eu/env/as/.well-known/openid-configuration", PingOne(PingOneEurope, "env").DiscoveryURL)
+	a.Equal("https://am.example.com/am/oauth2/realms/root/.well-known/openid-configuration", ForgeRock("https://am.example.com/am", "").DiscoveryURL)
+	a.Equal("https://am.example.com/am/oauth2/realms/root/realms/alpha/.well-known/openid-configuration", ForgeRock("https://am.example.com/am", "alpha").DiscoveryURL)
+	a.Equal("https://isam.example.com/mga/sps/oauth/oauth20/metadata/OIDC", IBMSecurityVerifyAccess("https://isam.example.com/mga", "OIDC").DiscoveryURL)
+
+	preset := IBMSecurityVerifyAccess("https://isam.example.com/mga", "OIDC")
+	preset.DiscoveryURL = server.URL
+	preset.AuthParams = map[string]string{"acr_values": "urn:ibm:security:policy:id:1"}
+	provider, err := NewPreset("key", "secret", "http://localhost/foo", preset)
+	a.NoError(err)
+	a.Equal("isam", provider.Name())
+	a.Equal(oauth2.AuthStyleInParams, provider.config.Endpoint.AuthStyle)
+
+	session, err := provider.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*Session).AuthURL, "acr_values=urn%3Aibm%3Asecurity%3Apolicy%3Aid%3A1")
+	a.Contains(session.(*Session).AuthURL, "scope=openid+profile+email")
+}
+
+func Test_Groups(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal("https://example.onelogin.com/oidc/2/.well-known/openid-configuration", OneLogin("example").DiscoveryURL)
+	a.Equal("https://sso-abc123.sso.duosecurity.com/oidc/key/.well-known/openid-configuration", DuoSSO("sso-abc123.sso.duosecurity.com", "key").DiscoveryURL)
+
+	preset := JumpCloud
+	preset.DiscoveryURL = server.URL
+	provider, err := NewPreset("key", "secret", "http://localhost/foo", preset)
+	a.NoError(err)
+	a.Equal("jumpcloud", provider.Name())
+
+	user := goth.User{RawData: map[string]interface{}{"memberOf": []interface{}{"engineering", "admins"}}}
+	a.Equal([]string{"engineering", "admins"}, provider.Groups(user))
+	a.Empty(openidConnectProvider().Groups(user))
+}
+
+func Test_Organizations(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal("https://app-abc123.frontegg.com/oauth/.well-known/openid-configuration", Frontegg("app-abc123.frontegg.com").DiscoveryURL)
+	a.Equal("https://clerk.example.com/.well-known/openid-configuration", Clerk("clerk.example.com").DiscoveryURL)
+	a.Equal("https://api.example.com/auth/.well-known/openid-configuration", SuperTokens("https://api.example.com/auth/").DiscoveryURL)
+
+	preset := Clerk("clerk.example.com")
+	preset.DiscoveryURL = server.URL
+	provider, err := NewPreset("key", "secret", "http://localhost/foo", preset)
+	a.NoError(err)
+
+	user := goth.User{RawData: map[string]interface{}{"org_id": "org_29w9UBMW", "org_role": "org:admin"}}
+	a.Equal([]string{"org:admin"}, provider.Groups(user))
+	a.Equal([]string{"org_29w9UBMW"}, provider.Organizations(user))
+	a.Empty(openidConnectProvider().Organizations(user))
+}
+
+func Test_NewPresetOryHydra(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal("https://oauth.example.com/.well-known/openid-configuration", OryHydra("https://oauth.example.com/").DiscoveryURL)
+
+	preset := OryHydra("https://oauth.example.com")
+	preset.DiscoveryURL = server.URL
+	provider, err := NewPreset("key", "secret", "http://localhost/foo", preset)
+	a.NoError(err)
+	a.Equal("oryhydra", provider.Name())
+	a.Equal(oauth2.AuthStyleInHeader, provider.config.Endpoint.AuthStyle)
+
+	session, err := provider.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*Session).AuthURL, "scope=openid+offline_access+profile+email")
+}
+
+func Test_RevokeToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := openidConnectProvider()
+	a.Equal("https://accounts.google.com/o/oauth2/revoke", provider.OpenIDConfig.RevocationEndpoint)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("1234567890", r.PostForm.Get("token"))
+	}))
+	defer ts.Close()
+	provider.OpenIDConfig.RevocationEndpoint = ts.URL
+	a.NoError(goth.RevokeToken(provider, "1234567890"))
+
+	provider.OpenIDConfig.RevocationEndpoint = ""
+	var notSupported *goth.RevocationNotSupportedError
+	a.True(errors.As(goth.RevokeToken(provider, "1234567890"), &notSupported))
+}
+
+// Test_NewLazy is not parallel: it changes the clock of goth.
+func Test_NewLazy(t *testing.T) {
+	a := assert.New(t)
+	now := time.Now()
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	fetches, down := 0, true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"issuer": "https://idp.example.com", "authorization_endpoint": "https://idp.example.com/authorize", "token_endpoint": "https://idp.example.com/token"}`)
+	}))
+	defer ts.Close()
+
+	provider := NewLazy("key", "secret", "http://localhost/foo", ts.URL, "email")
+	a.Equal(0, fetches)
+
+	_, err := provider.BeginAuth("test_state")
+	a.True(errors.Is(err, goth.ErrProviderAPI))
+	a.Contains(err.Error(), "openid-connect: cannot fetch the discovery document")
+	_, err = provider.BeginAuth("test_state")
+	a.Error(err)
+	a.Equal(1, fetches, "the failure is waited out")
+
+	now = now.Add(DiscoveryRetryDelay)
+	_, err = provider.BeginAuth("test_state")
+	a.Error(err)
+	a.Equal(2, fetches)
+	now = now.Add(DiscoveryRetryDelay)
+	_, err = provider.BeginAuth("test_state")
+	a.Error(err)
+	a.Equal(2, fetches, "the delay doubled")
+
+	now = now.Add(DiscoveryRetryDelay)
+	down = false
+	configured, err := goth.Configure(provider, goth.WithScopes("profile"))
+	a.NoError(err)
+	session, err := configured.BeginAuth("test_state")
+	a.NoError(err)
+	a.Equal(3, fetches)
+	a.Contains(session.(*Session).AuthURL, "https://idp.example.com/authorize?")
+	a.Contains(session.(*Session).AuthURL, "scope=profile")
+
+	session, err = provider.BeginAuth("test_state")
+	a.NoError(err)
+	a.Equal(3, fetches, "the copies share the discovery document")
+	a.Contains(session.(*Session).AuthURL, "scope=email+openid")
+
+	down = true
+	now = now.Add(RediscoveryInterval)
+	_, err = provider.BeginAuth("test_state")
+	a.NoError(err, "the previous document is kept")
+	a.Equal(4, fetches)
+}
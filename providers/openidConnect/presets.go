@@ -0,0 +1,253 @@
+package openidConnect
+
+import (
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Preset describes a well known OpenID Connect provider, to be created with
+// NewPreset.
+type Preset struct {
+	// Name is the name of the provider created.
+	Name string
+	// DiscoveryURL is the URL of the OpenID Connect discovery document.
+	DiscoveryURL string
+	// Scopes are requested when NewPreset is given none.
+	Scopes []string
+	// NickNameClaims replace the default NickNameClaims, when set.
+	NickNameClaims []string
+	// GroupsClaims replace the default GroupsClaims, when set.
+	GroupsClaims []string
+	// OrganizationClaims replace the default OrganizationClaims, when set.
+	OrganizationClaims []string
+	// AuthParams are added to the authorization URL, such as the acr_values
+	// selecting an authentication policy.
+	AuthParams map[string]string
+	// AuthStyle is how the client authenticates to the token end-point,
+	// auto detected when not set.
+	AuthStyle oauth2.AuthStyle
+}
+
+// Presets of the research and education identity federations. The eduPerson
+// principal name, a scoped identifier of the user at their institution, is used
+// as the nickname.
+var (
+	// SURFconext is the identity federation of Dutch education and research.
+	// See https://servicedesk.surf.nl/wiki/display/IAM/OpenID+Connect
+	SURFconext = Preset{
+		Name:           "surfconext",
+		DiscoveryURL:   "https://connect.surfconext.nl/.well-known/openid-configuration",
+		Scopes:         []string{"openid", "profile", "email"},
+		NickNameClaims: []string{"eduperson_principal_name", PreferredUsernameClaim},
+	}
+	// SURFconextTest is the test environment of SURFconext.
+	SURFconextTest = Preset{
+		Name:           "surfconext",
+		DiscoveryURL:   "https://connect.test.surfconext.nl/.well-known/openid-configuration",
+		Scopes:         []string{"openid", "profile", "email"},
+		NickNameClaims: []string{"eduperson_principal_name", PreferredUsernameClaim},
+	}
+	// CILogon federates the institutions of eduGAIN and InCommon.
+	// See https://www.cilogon.org/oidc
+	CILogon = Preset{
+		Name:           "cilogon",
+		DiscoveryURL:   "https://cilogon.org/.well-known/openid-configuration",
+		Scopes:         []string{"openid", "profile", "email", "org.cilogon.userinfo"},
+		NickNameClaims: []string{"eppn", PreferredUsernameClaim},
+	}
+)
+
+// Regions of PingOne, the domain of its authentication end-points.
+const (
+	PingOneNorthAmerica = "auth.pingone.com"
+	PingOneEurope       = "auth.pingone.eu"
+	PingOneCanada       = "auth.pingone.ca"
+	PingOneAsiaPacific  = "auth.pingone.asia"
+)
+
+// Presets of the enterprise identity products deployed by banks and other
+// regulated organisations. Their discovery URL depends on the deployment, it
+// is built from the host, environment or realm given. The presets request
+// openid, profile and email, and authenticate to the token end-point with
+// the method the product registers clients with by default.
+
+// PingFederate is the preset of a PingFederate server, host is its base URL
+// such as https://sso.example.com.
+func PingFederate(host string) Preset {
+	return Preset{
+		Name:         "pingfederate",
+		DiscoveryURL: strings.TrimSuffix(host, "/") + "/.well-known/openid-configuration",
+		Scopes:       []string{"openid", "profile", "email"},
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+}
+
+// PingOne is the preset of a PingOne environment, region is one of the
+// PingOne region domains, such as PingOneEurope.
+func PingOne(region, environmentID string) Preset {
+	return Preset{
+		Name:         "pingone",
+		DiscoveryURL: "https://" + region + "/" + environmentID + "/as/.well-known/openid-configuration",
+		Scopes:       []string{"openid", "profile", "email"},
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+}
+
+// ForgeRock is the preset of a realm of ForgeRock Access Management, baseURL
+// is the URL AM is deployed at, such as https://am.example.com/am. realm is a
+// sub realm of the root realm, such as alpha in ForgeRock Identity Cloud, the
+// root realm is used when it is empty.
+func ForgeRock(baseURL, realm string) Preset {
+	path := "/oauth2/realms/root"
+	if realm = strings.Trim(realm, "/"); realm != "" {
+		path += "/realms/" + realm
+	}
+	return Preset{
+		Name:         "forgerock",
+		DiscoveryURL: strings.TrimSuffix(baseURL, "/") + path + "/.well-known/openid-configuration",
+		Scopes:       []string{"openid", "profile", "email"},
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+}
+
+// IBMSecurityVerifyAccess is the preset of an OpenID Connect definition of
+// IBM Security Verify Access, formerly IBM Security Access Manager (ISAM).
+// baseURL is the URL of the junction of the federation module, such as
+// https://isam.example.com/mga. Its clients send their secret in the body of
+// the token request.
+func IBMSecurityVerifyAccess(baseURL, definition string) Preset {
+	return Preset{
+		Name:         "isam",
+		DiscoveryURL: strings.TrimSuffix(baseURL, "/") + "/sps/oauth/oauth20/metadata/" + definition,
+		Scopes:       []string{"openid", "profile", "email"},
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+}
+
+// Presets of the SSO services of IT tools. The groups of the user are read
+// with Provider.Groups, once the service is configured to send them.
+
+// JumpCloud is the preset of the JumpCloud SSO. The groups attribute of the
+// application is named groups or memberOf.
+var JumpCloud = Preset{
+	Name:         "jumpcloud",
+	DiscoveryURL: "https://oauth.id.jumpcloud.com/.well-known/openid-configuration",
+	Scopes:       []string{"openid", "profile", "email"},
+	GroupsClaims: []string{GroupsClaim, "memberOf"},
+	AuthStyle:    oauth2.AuthStyleInHeader,
+}
+
+// OneLogin is the preset of a OneLogin account, subdomain is the one of the
+// account, such as example for example.onelogin.com. The groups scope
+// returns the groups parameter of the application.
+func OneLogin(subdomain string) Preset {
+	return Preset{
+		Name:         "onelogin",
+		DiscoveryURL: "https://" + subdomain + ".onelogin.com/oidc/2/.well-known/openid-configuration",
+		Scopes:       []string{"openid", "profile", "email", "groups"},
+		GroupsClaims: []string{GroupsClaim},
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+}
+
+// DuoSSO is the preset of an OpenID Connect application of Duo Single
+// Sign-On, host is the host of the Duo SSO account, such as
+// sso-abc123.sso.duosecurity.com. The groups claim is mapped in the
+// application.
+func DuoSSO(host, clientKey string) Preset {
+	return Preset{
+		Name:         "duo",
+		DiscoveryURL: "https://" + host + "/oidc/" + clientKey + "/.well-known/openid-configuration",
+		Scopes:       []string{"openid", "profile", "email"},
+		GroupsClaims: []string{GroupsClaim},
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+}
+
+// Presets of the hosted authentication platforms, to bridge their users while
+// migrating to or from them. Their roles are read with Provider.Groups, and
+// their organizations or tenants with Provider.Organizations.
+
+// Frontegg is the preset of a Frontegg workspace, domain is its login domain
+// such as app-abc123.frontegg.com.
+func Frontegg(domain string) Preset {
+	return Preset{
+		Name:               "frontegg",
+		DiscoveryURL:       "https://" + domain + "/oauth/.well-known/openid-configuration",
+		Scopes:             []string{"openid", "profile", "email"},
+		GroupsClaims:       []string{"roles"},
+		OrganizationClaims: []string{"tenantIds", "tenantId"},
+	}
+}
+
+// Clerk is the preset of a Clerk application acting as an OAuth provider,
+// frontendAPI is the host of its Frontend API such as clerk.example.com. The
+// active organization and role are read when the claims of the application
+// include org_id and org_role.
+func Clerk(frontendAPI string) Preset {
+	return Preset{
+		Name:               "clerk",
+		DiscoveryURL:       "https://" + frontendAPI + "/.well-known/openid-configuration",
+		Scopes:             []string{"openid", "profile", "email"},
+		GroupsClaims:       []string{"org_role"},
+		OrganizationClaims: []string{"org_id"},
+	}
+}
+
+// SuperTokens is the preset of a SuperTokens core with its OAuth2 provider
+// recipe, apiURL is the URL of the API domain and base path such as
+// https://api.example.com/auth. The roles scope returns the roles of the
+// user, and the tenant is read from tId.
+func SuperTokens(apiURL string) Preset {
+	return Preset{
+		Name:               "supertokens",
+		DiscoveryURL:       strings.TrimSuffix(apiURL, "/") + "/.well-known/openid-configuration",
+		Scopes:             []string{"openid", "email", "roles"},
+		GroupsClaims:       []string{"roles"},
+		OrganizationClaims: []string{"tId"},
+	}
+}
+
+// OryHydra is the preset of Ory Hydra, the OAuth2 server of Ory, self-hosted
+// or on the Ory Network. publicURL is the URL of its public API, such as
+// https://oauth.example.com or https://PROJECT_SLUG.projects.oryapis.com.
+// The offline_access scope returns a refresh token. The claims of the ID token
+// are the ones the consent application of Hydra adds to the session. Users
+// of Ory Kratos are also read from their session with providers/kratos.
+func OryHydra(publicURL string) Preset {
+	return Preset{
+		Name:         "oryhydra",
+		DiscoveryURL: strings.TrimSuffix(publicURL, "/") + "/.well-known/openid-configuration",
+		Scopes:       []string{"openid", "offline_access", "profile", "email"},
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+}
+
+// NewPreset creates a new OpenID Connect provider for preset, requesting the
+// scopes of the preset when none are given.
+func NewPreset(clientKey, secret, callbackURL string, preset Preset, scopes ...string) (*Provider, error) {
+	if len(scopes) == 0 {
+		scopes = preset.Scopes
+	}
+	p, err := New(clientKey, secret, callbackURL, preset.DiscoveryURL, scopes...)
+	if err != nil {
+		return nil, err
+	}
+	if preset.Name != "" {
+		p.providerName = preset.Name
+	}
+	if len(preset.NickNameClaims) > 0 {
+		p.NickNameClaims = preset.NickNameClaims
+	}
+	if len(preset.GroupsClaims) > 0 {
+		p.GroupsClaims = preset.GroupsClaims
+	}
+	if len(preset.OrganizationClaims) > 0 {
+		p.OrganizationClaims = preset.OrganizationClaims
+	}
+	p.authParams = preset.AuthParams
+	p.authStyle = preset.AuthStyle
+	p.config.Endpoint.AuthStyle = preset.AuthStyle
+	return p, nil
+}
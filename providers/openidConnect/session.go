@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/bgdsh/goth"
-	"golang.org/x/oauth2"
 )
 
 // Session stores data during the auth process with the OpenID Connect provider.
@@ -17,6 +16,8 @@ type Session struct {
 	RefreshToken string
 	ExpiresAt    time.Time
 	IDToken      string
+	CodeVerifier string `json:",omitempty"`
+	Nonce        string `json:",omitempty"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the OpenID Connect provider.
@@ -29,8 +30,12 @@ func (s Session) GetAuthURL() (string, error) {
 
 // Authorize the session with the OpenID Connect provider and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
-	p := provider.(*Provider)
-	token, err := p.config.Exchange(oauth2.NoContext, params.Get("code"))
+	p, err := provider.(*Provider).discovered()
+	if err != nil {
+		return "", err
+	}
+	opts := append(goth.VerifierOptions(s.CodeVerifier), goth.ResourceTokenOptions(p.resources...)...)
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), opts...)
 	if err != nil {
 		return "", err
 	}
@@ -43,6 +48,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
 	s.IDToken = token.Extra("id_token").(string)
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
@@ -0,0 +1,250 @@
+// Package orcid implements the OAuth2 protocol for authenticating researchers
+// through ORCID. The ORCID iD of the user, such as 0000-0002-1825-0097, is
+// returned with the token and is the UserID.
+// Reference: https://info.orcid.org/documentation/integration-guide/getting-started-with-your-orcid-integration/
+package orcid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Registries of ORCID, New uses ProductionURL and ProductionAPIURL.
+const (
+	ProductionURL    = "https://orcid.org"
+	ProductionAPIURL = "https://pub.orcid.org"
+	SandboxURL       = "https://sandbox.orcid.org"
+	SandboxAPIURL    = "https://pub.sandbox.orcid.org"
+)
+
+// Scopes of ORCID, ScopeAuthenticate is requested when no scopes are given.
+const (
+	ScopeAuthenticate = "/authenticate"
+	ScopeOpenID       = "openid"
+	ScopeReadLimited  = "/read-limited"
+)
+
+// New creates a new ORCID provider, and sets up important connection details.
+// You should always call `orcid.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, ProductionURL, ProductionAPIURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set the registry
+// and the API of ORCID, such as SandboxURL and SandboxAPIURL.
+func NewCustomisedURL(clientKey, secret, callbackURL, orcidURL, apiURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeAuthenticate}
+	}
+	orcidURL = strings.TrimSuffix(orcidURL, "/")
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "orcid",
+		apiURL:       strings.TrimSuffix(apiURL, "/") + "/v3.0/",
+	}
+	p.config = newConfig(p, orcidURL, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing ORCID.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	apiURL       string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the orcid package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ORCID for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to ORCID and access the public record of the user. Emails
+// are only part of it when the user made them public.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.ORCID,
+		Name:         sess.Name,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.apiURL+sess.ORCID+"/person", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+// value is a value of an ORCID record.
+type value struct {
+	Value string `json:"value"`
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Name *struct {
+			GivenNames *value `json:"given-names"`
+			FamilyName *value `json:"family-name"`
+			CreditName *value `json:"credit-name"`
+		} `json:"name"`
+		Biography *struct {
+			Content string `json:"content"`
+		} `json:"biography"`
+		Emails struct {
+			Email []struct {
+				Email   string `json:"email"`
+				Primary bool   `json:"primary"`
+			} `json:"email"`
+		} `json:"emails"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	if u.Name != nil {
+		if u.Name.GivenNames != nil {
+			user.FirstName = u.Name.GivenNames.Value
+		}
+		if u.Name.FamilyName != nil {
+			user.LastName = u.Name.FamilyName.Value
+		}
+		if u.Name.CreditName != nil && u.Name.CreditName.Value != "" {
+			user.Name = u.Name.CreditName.Value
+		}
+	}
+	if user.Name == "" {
+		user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+	if u.Biography != nil {
+		user.Description = u.Biography.Content
+	}
+	for _, email := range u.Emails.Email {
+		if user.Email == "" || email.Primary {
+			user.Email = email.Email
+		}
+	}
+	return nil
+}
+
+func newConfig(provider *Provider, orcidURL string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   orcidURL + "/oauth/authorize",
+			TokenURL:  orcidURL + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. ORCID tokens
+// last 20 years, unless the user revokes them.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
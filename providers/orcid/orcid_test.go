@@ -0,0 +1,88 @@
+package orcid_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/orcid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), orcidProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := orcidProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := orcidProvider().BeginAuth("test_state")
+	s := session.(*orcid.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://orcid.org/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=%2Fauthenticate")
+	a.Contains(s.AuthURL, "state=test_state")
+
+	session, err = orcid.NewCustomisedURL("key", "secret", "/foo", orcid.SandboxURL, orcid.SandboxAPIURL).BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*orcid.Session).AuthURL, "https://sandbox.orcid.org/oauth/authorize")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","refresh_token":"refresh","expires_in":631138518,"scope":"/authenticate","name":"Sofia Maria Hernandez Garcia","orcid":"0000-0002-9227-8514"}`)
+		case "/v3.0/0000-0002-9227-8514/person":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"name":{"given-names":{"value":"Sofia Maria"},"family-name":{"value":"Hernandez Garcia"},"credit-name":null,"visibility":"public"},"biography":{"content":"Researcher at the ORCID institute.","visibility":"public"},"emails":{"email":[{"email":"s.garcia@example.com","primary":true,"verified":true}]},"path":"/0000-0002-9227-8514/person"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := orcid.NewCustomisedURL("key", "secret", "/foo", ts.URL, ts.URL)
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal("0000-0002-9227-8514", session.(*orcid.Session).ORCID)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("0000-0002-9227-8514", user.UserID)
+	a.Equal("Sofia Maria Hernandez Garcia", user.Name)
+	a.Equal("Sofia Maria", user.FirstName)
+	a.Equal("Hernandez Garcia", user.LastName)
+	a.Equal("s.garcia@example.com", user.Email)
+	a.Equal("Researcher at the ORCID institute.", user.Description)
+}
+
+func orcidProvider() *orcid.Provider {
+	return orcid.New("key", "secret", "/foo")
+}
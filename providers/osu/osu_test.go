@@ -0,0 +1,85 @@
+package osu_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/osu"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), osuProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := osuProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := osuProvider().BeginAuth("test_state")
+	s := session.(*osu.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://osu.ppy.sh/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=identify")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.Equal("key", r.FormValue("client_id"))
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","expires_in":86400}`)
+		case "/api/v2/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"id":2,"username":"peppy","avatar_url":"https://a.ppy.sh/2?1519081077.png","country_code":"AU","is_bot":false,"playmode":"osu"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	osu.TokenURL, osu.ProfileURL = ts.URL+"/oauth/token", ts.URL+"/api/v2/me"
+	defer func() {
+		osu.TokenURL, osu.ProfileURL = "https://osu.ppy.sh/oauth/token", "https://osu.ppy.sh/api/v2/me"
+	}()
+	provider := osuProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("2", user.UserID)
+	a.Equal("peppy", user.NickName)
+	a.Equal("AU", user.Location)
+	a.Equal("osu", user.RawData["playmode"])
+}
+
+func osuProvider() *osu.Provider {
+	return osu.New("key", "secret", "/foo")
+}
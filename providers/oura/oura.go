@@ -1,4 +1,6 @@
 // Package oura implements the OAuth protocol for authenticating users through Oura API (for OuraRing).
+// The user is read from the personal info end-point of the API v2.
+// Reference: https://cloud.ouraring.com/v2/docs
 package oura
 
 import (
@@ -15,7 +17,7 @@ import (
 const (
 	authURL         string = "https://cloud.ouraring.com/oauth/authorize"
 	tokenURL        string = "https://api.ouraring.com/oauth/token"
-	endpointProfile string = "https://api.ouraring.com/v1/userinfo"
+	endpointProfile string = "https://api.ouraring.com/v2/usercollection/personal_info"
 )
 
 const (
@@ -47,6 +49,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -66,14 +69,36 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is a no-op for the oura package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Oura for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state, pkce...)
 	session := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return session, nil
 }
@@ -119,12 +144,12 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 
 func userFromReader(reader io.Reader, user *goth.User) error {
 	u := struct {
-		Age    int     `json:"age"`
-		Weight float32 `json:"weight"` // kg
-		Height int     `json:"height"` // cm
-		Gender string  `json:"gender"`
-		Email  string  `json:"email"`
-		UserID string  `json:"user_id"`
+		ID            string  `json:"id"`
+		Age           int     `json:"age"`
+		Weight        float32 `json:"weight"` // kg
+		Height        float32 `json:"height"` // m
+		BiologicalSex string  `json:"biological_sex"`
+		Email         string  `json:"email"`
 	}{}
 
 	err := json.NewDecoder(reader).Decode(&u)
@@ -143,11 +168,11 @@ func userFromReader(reader io.Reader, user *goth.User) error {
 	if u.Height != 0 {
 		rawData["height"] = u.Height
 	}
-	if u.Gender != "" {
-		rawData["gender"] = u.Gender
+	if u.BiologicalSex != "" {
+		rawData["biological_sex"] = u.BiologicalSex
 	}
 
-	user.UserID = u.UserID
+	user.UserID = u.ID
 	user.Email = u.Email
 	if len(rawData) > 0 {
 		user.RawData = rawData
@@ -178,7 +203,7 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 //RefreshToken get new access token based on the refresh token
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	token := &oauth2.Token{RefreshToken: refreshToken}
-	ts := p.config.TokenSource(oauth2.NoContext, token)
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
 	newToken, err := ts.Token()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,97 @@
+package pinterest_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/pinterest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), pinterestProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := pinterestProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := pinterestProvider().BeginAuth("test_state")
+	s := session.(*pinterest.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.pinterest.com/oauth/")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=user_accounts%3Aread")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v5/oauth/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			if r.FormValue("grant_type") == "refresh_token" {
+				fmt.Fprint(w, `{"access_token":"token2","refresh_token":"refresh2","token_type":"bearer","expires_in":2592000,"refresh_token_expires_in":5184000}`)
+				return
+			}
+			a.Equal("true", r.FormValue("continuous_refresh"))
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":2592000,"refresh_token_expires_in":5184000,"scope":"user_accounts:read"}`)
+		case "/v5/user_account":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"account_type":"BUSINESS","id":"549755885175","profile_image":"https://i.pinimg.com/p.jpg","website_url":"https://example.com","username":"janesbakery","business_name":"Jane's Bakery","board_count":12}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	pinterest.TokenURL, pinterest.ProfileURL = ts.URL+"/v5/oauth/token", ts.URL+"/v5/user_account"
+	defer func() {
+		pinterest.TokenURL, pinterest.ProfileURL = "https://api.pinterest.com/v5/oauth/token", "https://api.pinterest.com/v5/user_account"
+	}()
+	provider := pinterestProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("549755885175", user.UserID)
+	a.Equal("Jane's Bakery", user.Name)
+	a.Equal("janesbakery", user.NickName)
+	a.Equal("BUSINESS", user.RawData["account_type"])
+
+	newToken, err := provider.RefreshToken(user.RefreshToken)
+	a.NoError(err)
+	a.Equal("token2", newToken.AccessToken)
+	a.Equal("refresh2", newToken.RefreshToken)
+}
+
+func pinterestProvider() *pinterest.Provider {
+	return pinterest.New("key", "secret", "/foo")
+}
@@ -0,0 +1,279 @@
+// Package plaid wraps Plaid Link in the provider interface, to link bank
+// accounts through the same flow as logins.
+// BeginAuth creates a Link token for Hosted Link and sends the user to it, Plaid
+// then sends the user back to the CallbackURL. Authorize exchanges the
+// public token, given as the public_token parameter when Link runs in the page
+// or read from the Link token otherwise, for the access token of the Item
+// (the bank login), and FetchUser reads the identity of the account owners.
+// Reference: https://plaid.com/docs/link/hosted-link/
+package plaid
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Environments of Plaid, New uses APIURL.
+const (
+	SandboxURL    = "https://sandbox.plaid.com"
+	ProductionURL = "https://production.plaid.com"
+)
+
+var (
+	APIURL = ProductionURL
+)
+
+// ProductIdentity is the product of the owners of the accounts, it is
+// requested when no products are given.
+const ProductIdentity = "identity"
+
+// New creates a new Plaid provider, and sets up important connection details.
+// You should always call `plaid.New` to get a new Provider. Never try to create
+// one manually. products are the Plaid products the Item is initialized with.
+func New(clientID, secret, callbackURL string, products ...string) *Provider {
+	return NewCustomisedURL(clientID, secret, callbackURL, APIURL, products...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set the
+// environment of Plaid, such as SandboxURL.
+func NewCustomisedURL(clientID, secret, callbackURL, apiURL string, products ...string) *Provider {
+	if len(products) == 0 {
+		products = []string{ProductIdentity}
+	}
+	return &Provider{
+		ClientKey:    clientID,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		ClientName:   "goth",
+		CountryCodes: []string{"US"},
+		Language:     "en",
+		Products:     products,
+		providerName: "plaid",
+		apiURL:       strings.TrimSuffix(apiURL, "/"),
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for linking Plaid Items.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+
+	// ClientName is the name of the application shown in Link.
+	ClientName   string
+	CountryCodes []string
+	Language     string
+	Products     []string
+
+	providerName string
+	apiURL       string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the plaid package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth creates a Link token, and returns the Hosted Link URL of it.
+// goth does not know the user yet, the state is used as client_user_id. Plaid
+// sends the user back without parameters, the state is added to the
+// CallbackURL.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	completionURL := p.CallbackURL
+	if strings.Contains(completionURL, "?") {
+		completionURL += "&"
+	} else {
+		completionURL += "?"
+	}
+	completionURL += url.Values{"state": {state}}.Encode()
+
+	link := struct {
+		LinkToken     string `json:"link_token"`
+		HostedLinkURL string `json:"hosted_link_url"`
+	}{}
+	err := p.post("/link/token/create", map[string]interface{}{
+		"client_name":   p.ClientName,
+		"country_codes": p.CountryCodes,
+		"language":      p.Language,
+		"products":      p.Products,
+		"user":          map[string]string{"client_user_id": state},
+		"hosted_link":   map[string]string{"completion_redirect_uri": completionURL},
+	}, &link)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		AuthURL:   link.HostedLinkURL,
+		LinkToken: link.LinkToken,
+	}, nil
+}
+
+// FetchUser will go to Plaid and access the identity of the owners of the
+// accounts of the Item. The first owner is the user, the Item its id.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		UserID:      sess.ItemID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	var raw map[string]interface{}
+	if err := p.post("/identity/get", map[string]interface{}{"access_token": sess.AccessToken}, &raw); err != nil {
+		return user, err
+	}
+	delete(raw, "request_id")
+
+	// raw is encoded again to read the identity.
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return user, err
+	}
+	identity := struct {
+		Accounts []struct {
+			Owners []struct {
+				Names  []string `json:"names"`
+				Emails []struct {
+					Data    string `json:"data"`
+					Primary bool   `json:"primary"`
+				} `json:"emails"`
+				Addresses []struct {
+					Data struct {
+						City string `json:"city"`
+					} `json:"data"`
+					Primary bool `json:"primary"`
+				} `json:"addresses"`
+			} `json:"owners"`
+		} `json:"accounts"`
+	}{}
+	if err := json.Unmarshal(b, &identity); err != nil {
+		return user, err
+	}
+
+	user.RawData = raw
+	for _, account := range identity.Accounts {
+		if len(account.Owners) == 0 {
+			continue
+		}
+		owner := account.Owners[0]
+		if len(owner.Names) > 0 {
+			user.Name = owner.Names[0]
+		}
+		for _, e := range owner.Emails {
+			if e.Primary || user.Email == "" {
+				user.Email = e.Data
+			}
+		}
+		for _, a := range owner.Addresses {
+			if a.Primary || user.Location == "" {
+				user.Location = a.Data.City
+			}
+		}
+		break
+	}
+	return user, nil
+}
+
+// publicToken returns the public token of the last Item linked with the Link
+// token.
+func (p *Provider) publicToken(linkToken string) (string, error) {
+	link := struct {
+		LinkSessions []struct {
+			Results struct {
+				ItemAddResults []struct {
+					PublicToken string `json:"public_token"`
+				} `json:"item_add_results"`
+			} `json:"results"`
+		} `json:"link_sessions"`
+	}{}
+	if err := p.post("/link/token/get", map[string]interface{}{"link_token": linkToken}, &link); err != nil {
+		return "", err
+	}
+
+	publicToken := ""
+	for _, s := range link.LinkSessions {
+		for _, r := range s.Results.ItemAddResults {
+			publicToken = r.PublicToken
+		}
+	}
+	if publicToken == "" {
+		return "", errors.New("no Item was linked with the Link token")
+	}
+	return publicToken, nil
+}
+
+// post calls the end-point path of Plaid with body, authenticated by the
+// client id and secret, and decodes the response into v.
+func (p *Provider) post(path string, body map[string]interface{}, v interface{}) error {
+	body["client_id"] = p.ClientKey
+	body["secret"] = p.Secret
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.Client().Post(p.apiURL+path, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		e := struct {
+			ErrorCode    string `json:"error_code"`
+			ErrorMessage string `json:"error_message"`
+		}{}
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.ErrorCode != "" {
+			return fmt.Errorf("%s responded with a %d: %s %s", p.providerName, resp.StatusCode, e.ErrorCode, e.ErrorMessage)
+		}
+		return goth.NewProviderAPIError(p.providerName, "", resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token. The access
+// tokens of Plaid do not expire.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Plaid")
+}
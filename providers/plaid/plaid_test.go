@@ -0,0 +1,95 @@
+package plaid_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/plaid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), plaidProvider(plaid.SandboxURL))
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := plaid.New("client-id", "secret", "/foo")
+	a.Equal(provider.ClientKey, "client-id")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal([]string{plaid.ProductIdentity}, provider.Products)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body := map[string]interface{}{}
+		a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		a.Equal("client-id", body["client_id"])
+		a.Equal("secret", body["secret"])
+		switch r.URL.Path {
+		case "/link/token/create":
+			a.Equal(map[string]interface{}{"client_user_id": "state"}, body["user"])
+			a.Equal(map[string]interface{}{"completion_redirect_uri": "/foo?state=state"}, body["hosted_link"])
+			a.Equal([]interface{}{"identity"}, body["products"])
+			fmt.Fprint(w, `{"expiration":"2024-03-27T12:56:34Z","link_token":"link-sandbox-af1a0311","hosted_link_url":"https://secure.plaid.com/hl/link-sandbox-af1a0311","request_id":"XQVgFigpGHXkb0b"}`)
+		case "/link/token/get":
+			a.Equal("link-sandbox-af1a0311", body["link_token"])
+			fmt.Fprint(w, `{"link_token":"link-sandbox-af1a0311","link_sessions":[{"link_session_id":"1daca4d5","results":{"item_add_results":[{"public_token":"public-sandbox-b0e2c4ee","institution":{"name":"First Platypus Bank","institution_id":"ins_109508"}}]}}],"request_id":"u0ydFs493XjyTYn"}`)
+		case "/item/public_token/exchange":
+			if body["public_token"] != "public-sandbox-b0e2c4ee" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error_type":"INVALID_INPUT","error_code":"INVALID_PUBLIC_TOKEN","error_message":"provided public token is in an invalid format","request_id":"a"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"access-sandbox-de3ce8ef","item_id":"M5eVJqLnv3tbzdngLDp9FL5OlDNxlNhlE55op","request_id":"Aim3b"}`)
+		case "/identity/get":
+			a.Equal("access-sandbox-de3ce8ef", body["access_token"])
+			fmt.Fprint(w, `{"accounts":[{"account_id":"BxBXxLj1m4HMXBm9WZZmCWVbPjX16EHwv99vp","name":"Plaid Checking","owners":[{"names":["Alberta Bobbeth Charleson"],"emails":[{"data":"accountholder1@example.com","primary":false,"type":"secondary"},{"data":"accountholder0@example.com","primary":true,"type":"primary"}],"addresses":[{"data":{"city":"Malakoff","country":"US","postal_code":"14236","region":"NY","street":"2992 Cameron Road"},"primary":true}],"phone_numbers":[{"data":"1112223333","primary":false,"type":"home"}]}]}],"item":{"item_id":"M5eVJqLnv3tbzdngLDp9FL5OlDNxlNhlE55op","institution_id":"ins_109508"},"request_id":"3nARps6TOYtbACO"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := plaidProvider(ts.URL)
+
+	session, err := provider.BeginAuth("state")
+	a.NoError(err)
+	authURL, _ := session.GetAuthURL()
+	a.Equal("https://secure.plaid.com/hl/link-sandbox-af1a0311", authURL)
+
+	_, err = session.Authorize(provider, url.Values{"public_token": {"public-other"}})
+	a.EqualError(err, "plaid responded with a 400: INVALID_PUBLIC_TOKEN provided public token is in an invalid format")
+
+	session, err = provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"state": {"state"}})
+	a.NoError(err)
+	a.Equal("access-sandbox-de3ce8ef", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("M5eVJqLnv3tbzdngLDp9FL5OlDNxlNhlE55op", user.UserID)
+	a.Equal("Alberta Bobbeth Charleson", user.Name)
+	a.Equal("accountholder0@example.com", user.Email)
+	a.Equal("Malakoff", user.Location)
+	a.Contains(user.RawData, "accounts")
+	a.NotContains(user.RawData, "request_id")
+}
+
+func plaidProvider(apiURL string) *plaid.Provider {
+	return plaid.NewCustomisedURL("client-id", "secret", "/foo", apiURL)
+}
@@ -0,0 +1,73 @@
+package plaid
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the linking of an Item with Plaid.
+type Session struct {
+	AuthURL     string
+	LinkToken   string
+	AccessToken string
+	ItemID      string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Plaid provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize exchanges the public token of the linked Item and returns the
+// access token to be stored for future use. The public token is the
+// public_token parameter, or read from the Link token of the session.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	publicToken := params.Get("public_token")
+	if publicToken == "" {
+		var err error
+		if publicToken, err = p.publicToken(s.LinkToken); err != nil {
+			return "", err
+		}
+	}
+
+	item := struct {
+		AccessToken string `json:"access_token"`
+		ItemID      string `json:"item_id"`
+	}{}
+	if err := p.post("/item/public_token/exchange", map[string]interface{}{"public_token": publicToken}, &item); err != nil {
+		return "", err
+	}
+	if item.AccessToken == "" {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = item.AccessToken
+	s.ItemID = item.ItemID
+	return item.AccessToken, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
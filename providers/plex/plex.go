@@ -0,0 +1,227 @@
+// Package plex implements the PIN based flow for authenticating users through Plex.
+// Plex has no OAuth: BeginAuth creates a PIN and sends the user to Plex to
+// claim it, Plex then forwards the user to the CallbackURL, with the state, and
+// Authorize checks the PIN for the token the user claimed it with.
+// Reference: https://forums.plex.tv/t/authenticating-with-plex/609370
+package plex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://app.plex.tv/auth"
+	PinsURL    = "https://plex.tv/api/v2/pins"
+	ProfileURL = "https://plex.tv/api/v2/user"
+)
+
+// New creates a new Plex provider, and sets up important connection details.
+// You should always call `plex.New` to get a new Provider. Never try to create
+// one manually. clientIdentifier is a unique and stable id of the application,
+// product its name, shown to the users.
+func New(clientIdentifier, product, callbackURL string) *Provider {
+	return &Provider{
+		ClientKey:    clientIdentifier,
+		Product:      product,
+		CallbackURL:  callbackURL,
+		providerName: "plex",
+		pinsURL:      PinsURL,
+		profileURL:   ProfileURL,
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Plex.
+type Provider struct {
+	ClientKey    string
+	Product      string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	providerName string
+	pinsURL      string
+	profileURL   string
+}
+
+// pin is a PIN of Plex, its AuthToken is set once the user claimed it.
+type pin struct {
+	ID        int64  `json:"id"`
+	Code      string `json:"code"`
+	AuthToken string `json:"authToken"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the plex package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth creates a new PIN, and returns the end-point of Plex claiming it.
+// Plex forwards the users to the CallbackURL without parameters, the state is
+// added to it.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	pin := &pin{}
+	if err := p.do("POST", p.pinsURL+"?strong=true", "", pin); err != nil {
+		return nil, err
+	}
+
+	forwardURL := p.CallbackURL
+	if strings.Contains(forwardURL, "?") {
+		forwardURL += "&"
+	} else {
+		forwardURL += "?"
+	}
+	forwardURL += url.Values{"state": {state}}.Encode()
+
+	// The parameters are read by the web application, from the fragment.
+	return &Session{
+		AuthURL: AuthURL + "#?" + url.Values{
+			"clientID":                 {p.ClientKey},
+			"code":                     {pin.Code},
+			"context[device][product]": {p.Product},
+			"forwardUrl":               {forwardURL},
+		}.Encode(),
+		PinID:   pin.ID,
+		PinCode: pin.Code,
+	}, nil
+}
+
+// FetchUser will go to Plex and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := p.newRequest("GET", p.profileURL, sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID           int64  `json:"id"`
+		Username     string `json:"username"`
+		Title        string `json:"title"`
+		FriendlyName string `json:"friendlyName"`
+		Email        string `json:"email"`
+		Thumb        string `json:"thumb"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+	// The token is the password of the account, keep it out of RawData.
+	delete(user.RawData, "authToken")
+
+	user.UserID = strconv.FormatInt(u.ID, 10)
+	user.Name = u.FriendlyName
+	if user.Name == "" {
+		user.Name = u.Title
+	}
+	user.NickName = u.Username
+	user.Email = u.Email
+	user.AvatarURL = u.Thumb
+	return nil
+}
+
+// newRequest returns a request to the Plex API, identifying the application.
+func (p *Provider) newRequest(method, endpoint, token string) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("X-Plex-Client-Identifier", p.ClientKey)
+	req.Header.Add("X-Plex-Product", p.Product)
+	if token != "" {
+		req.Header.Add("X-Plex-Token", token)
+	}
+	return req, nil
+}
+
+// do calls endpoint and decodes the response into v.
+func (p *Provider) do(method, endpoint, token string, v interface{}) error {
+	req, err := p.newRequest(method, endpoint, token)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return goth.NewProviderAPIError(p.providerName, "", resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken get new access token based on the refresh token. Plex tokens
+// do not expire.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Plex")
+}
@@ -0,0 +1,98 @@
+package plex_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/plex"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), plexProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := plexProvider()
+	a.Equal(provider.ClientKey, "client-id")
+	a.Equal(provider.Product, "My App")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	claimed := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		a.Equal("client-id", r.Header.Get("X-Plex-Client-Identifier"))
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/pins":
+			a.Equal("true", r.URL.Query().Get("strong"))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":1234,"code":"8aoqw7dfmhv8ju2kb4sl7y7sw","product":"My App","trusted":false,"qr":"https://plex.tv/api/v2/pins/qr/8aoqw7dfmhv8ju2kb4sl7y7sw","clientIdentifier":"client-id","expiresIn":1800,"authToken":null}`)
+		case r.Method == "GET" && r.URL.Path == "/pins/1234":
+			token := "null"
+			if claimed {
+				token = `"token"`
+			}
+			fmt.Fprintf(w, `{"id":1234,"code":"8aoqw7dfmhv8ju2kb4sl7y7sw","authToken":%s}`, token)
+		case r.URL.Path == "/user":
+			a.Equal("token", r.Header.Get("X-Plex-Token"))
+			fmt.Fprint(w, `{"id":987654,"uuid":"3c1b8e5f0e7e4f1b","username":"jane_doe","title":"jane_doe","friendlyName":"Jane","email":"jane@example.com","thumb":"https://plex.tv/users/3c1b8e5f0e7e4f1b/avatar","authToken":"token"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	plex.PinsURL, plex.ProfileURL = ts.URL+"/pins", ts.URL+"/user"
+	defer func() {
+		plex.PinsURL, plex.ProfileURL = "https://plex.tv/api/v2/pins", "https://plex.tv/api/v2/user"
+	}()
+	provider := plexProvider()
+
+	session, err := provider.BeginAuth("state")
+	a.NoError(err)
+	authURL, _ := session.GetAuthURL()
+	a.True(strings.HasPrefix(authURL, "https://app.plex.tv/auth#?"))
+	params, err := url.ParseQuery(strings.SplitN(authURL, "#?", 2)[1])
+	a.NoError(err)
+	a.Equal("client-id", params.Get("clientID"))
+	a.Equal("8aoqw7dfmhv8ju2kb4sl7y7sw", params.Get("code"))
+	a.Equal("My App", params.Get("context[device][product]"))
+	a.Equal("/foo?state=state", params.Get("forwardUrl"))
+
+	session, err = provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	_, err = session.Authorize(provider, url.Values{"state": {"state"}})
+	a.Error(err)
+
+	claimed = true
+	token, err := session.Authorize(provider, url.Values{"state": {"state"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("987654", user.UserID)
+	a.Equal("Jane", user.Name)
+	a.Equal("jane_doe", user.NickName)
+	a.Equal("jane@example.com", user.Email)
+	a.NotContains(user.RawData, "authToken")
+}
+
+func plexProvider() *plex.Provider {
+	return plex.New("client-id", "My App", "/foo")
+}
@@ -0,0 +1,61 @@
+package plex
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with Plex.
+type Session struct {
+	AuthURL     string
+	AccessToken string
+	PinID       int64
+	PinCode     string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Plex provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with Plex and return the access token to be stored for future use.
+// The PIN of the session must have been claimed by the user.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	pin := &pin{}
+	if err := p.do("GET", p.pinsURL+"/"+strconv.FormatInt(s.PinID, 10), "", pin); err != nil {
+		return "", err
+	}
+	if pin.Code != s.PinCode || pin.AuthToken == "" {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = pin.AuthToken
+	return pin.AuthToken, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
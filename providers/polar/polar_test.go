@@ -0,0 +1,100 @@
+package polar_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/polar"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), polarProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := polarProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := polarProvider().BeginAuth("test_state")
+	s := session.(*polar.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://flow.polar.com/oauth2/authorization")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=accesslink.read_all")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	registered := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","expires_in":315359999,"x_user_id":10998862}`)
+		case r.Method == "POST" && r.URL.Path == "/v3/users":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			b, _ := ioutil.ReadAll(r.Body)
+			a.JSONEq(`{"member-id":"10998862"}`, string(b))
+			if registered {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			registered = true
+			fmt.Fprint(w, `{"polar-user-id":10998862,"member-id":"10998862","registration-date":"2011-10-14T12:50:37.000Z","first-name":"Eka","last-name":"Toka","birthdate":"1985-09-06","gender":"MALE","weight":66,"height":170}`)
+		case r.Method == "GET" && r.URL.Path == "/v3/users/10998862":
+			fmt.Fprint(w, `{"polar-user-id":10998862,"member-id":"10998862","first-name":"Eka","last-name":"Toka"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	polar.TokenURL, polar.APIURL = ts.URL+"/token", ts.URL+"/v3"
+	defer func() {
+		polar.TokenURL, polar.APIURL = "https://polarremote.com/v2/oauth2/token", "https://www.polaraccesslink.com/v3"
+	}()
+	provider := polarProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	// The user is registered the first time, and fetched afterwards.
+	for i := 0; i < 2; i++ {
+		user, err := provider.FetchUser(session)
+		a.NoError(err)
+		a.Equal("10998862", user.UserID)
+		a.Equal("Eka Toka", user.Name)
+		a.Equal("Toka", user.LastName)
+	}
+}
+
+func polarProvider() *polar.Provider {
+	return polar.New("key", "secret", "/foo")
+}
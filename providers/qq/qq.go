@@ -0,0 +1,284 @@
+// Package qq implements the OAuth2 protocol for authenticating users through QQ.
+// Reference: https://wiki.connect.qq.com/oauth2-0%E7%AE%80%E4%BB%8B
+package qq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	BaseURL = "https://graph.qq.com"
+)
+
+// New creates a new QQ provider, and sets up important connection details.
+// The get_user_info scope is requested when no scopes are given.
+func New(appID, appKey, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{"get_user_info"}
+	}
+	return &Provider{
+		ClientKey:    appID,
+		Secret:       appKey,
+		CallbackURL:  callbackURL,
+		Scopes:       scopes,
+		providerName: "qq",
+		baseURL:      BaseURL,
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for accessing QQ.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	Scopes      []string
+	HTTPClient  *http.Client
+
+	// UnionID asks QQ for the user's unionid, which identifies the user
+	// across all the applications of a developer account.
+	UnionID bool
+
+	providerName string
+	baseURL      string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the qq package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks QQ for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	params := url.Values{}
+	params.Add("response_type", "code")
+	params.Add("client_id", p.ClientKey)
+	params.Add("redirect_uri", p.CallbackURL)
+	params.Add("state", state)
+	params.Add("scope", goth.ScopeFormatOf(p.Name(), goth.CommaSeparatedScopes).Join(p.Scopes))
+	session := &Session{
+		AuthURL: fmt.Sprintf("%s/oauth2.0/authorize?%s", p.baseURL, params.Encode()),
+	}
+	return session, nil
+}
+
+// FetchUser will go to QQ and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		Provider:     p.Name(),
+		UserID:       sess.OpenID,
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	params := url.Values{}
+	params.Add("access_token", user.AccessToken)
+	params.Add("oauth_consumer_key", p.ClientKey)
+	params.Add("openid", sess.OpenID)
+	resp, err := p.Client().Get(fmt.Sprintf("%s/user/get_user_info?%s", p.baseURL, params.Encode()))
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("qq /user/get_user_info returns code: %d", resp.StatusCode)
+	}
+
+	if err := userFromReader(resp.Body, &user); err != nil {
+		return user, err
+	}
+	if sess.UnionID != "" {
+		user.RawData["unionid"] = sess.UnionID
+	}
+
+	return user, nil
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	params := url.Values{}
+	params.Add("grant_type", "refresh_token")
+	params.Add("refresh_token", refreshToken)
+	return p.fetchToken(params)
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// fetchToken calls the token end-point with params. QQ answers with a query
+// string unless it is asked for JSON with fmt=json.
+func (p *Provider) fetchToken(params url.Values) (*oauth2.Token, error) {
+	params.Add("client_id", p.ClientKey)
+	params.Add("client_secret", p.Secret)
+	params.Add("fmt", "json")
+	resp, err := p.Client().Get(fmt.Sprintf("%s/oauth2.0/token?%s", p.baseURL, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("qq /oauth2.0/token returns code: %d", resp.StatusCode)
+	}
+
+	obj := struct {
+		AccessToken  string      `json:"access_token"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    json.Number `json:"expires_in"`
+		Code         int         `json:"error"`
+		Msg          string      `json:"error_description"`
+	}{}
+	if err := decodeJSONP(resp.Body, &obj); err != nil {
+		return nil, err
+	}
+	if obj.Code != 0 {
+		return nil, fmt.Errorf("CODE: %d, MSG: %s", obj.Code, obj.Msg)
+	}
+	if obj.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  obj.AccessToken,
+		RefreshToken: obj.RefreshToken,
+	}
+	if expiresIn, err := strconv.ParseInt(obj.ExpiresIn.String(), 10, 64); err == nil && expiresIn > 0 {
+		token.Expiry = goth.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// fetchOpenID asks QQ whom the access token belongs to.
+func (p *Provider) fetchOpenID(accessToken string) (openID, unionID string, err error) {
+	params := url.Values{}
+	params.Add("access_token", accessToken)
+	if p.UnionID {
+		params.Add("unionid", "1")
+	}
+	resp, err := p.Client().Get(fmt.Sprintf("%s/oauth2.0/me?%s", p.baseURL, params.Encode()))
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("qq /oauth2.0/me returns code: %d", resp.StatusCode)
+	}
+
+	obj := struct {
+		ClientID string `json:"client_id"`
+		OpenID   string `json:"openid"`
+		UnionID  string `json:"unionid"`
+		Code     int    `json:"error"`
+		Msg      string `json:"error_description"`
+	}{}
+	if err := decodeJSONP(resp.Body, &obj); err != nil {
+		return "", "", err
+	}
+	if obj.Code != 0 {
+		return "", "", fmt.Errorf("CODE: %d, MSG: %s", obj.Code, obj.Msg)
+	}
+	if obj.ClientID != p.ClientKey {
+		return "", "", errors.New("qq: access token was issued to another application")
+	}
+
+	return obj.OpenID, obj.UnionID, nil
+}
+
+// decodeJSONP decodes a JSON value which may be wrapped in a JSONP callback,
+// as in `callback( {"openid":"..."} );`. QQ answers this way on the
+// /oauth2.0/me end-point, and on the others when they fail.
+func decodeJSONP(reader io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("callback")) {
+		start := bytes.IndexByte(b, '(')
+		end := bytes.LastIndexByte(b, ')')
+		if start < 0 || end < start {
+			return fmt.Errorf("qq: malformed JSONP response: %s", b)
+		}
+		b = b[start+1 : end]
+	}
+	return json.Unmarshal(b, v)
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	obj := struct {
+		Ret       int    `json:"ret"`
+		Msg       string `json:"msg"`
+		Nickname  string `json:"nickname"`
+		Figure    string `json:"figureurl_qq_1"`
+		FigureHD  string `json:"figureurl_qq_2"`
+		Gender    string `json:"gender"`
+		Province  string `json:"province"`
+		City      string `json:"city"`
+		BirthYear string `json:"year"`
+	}{}
+
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if obj.Ret != 0 {
+		return fmt.Errorf("CODE: %d, MSG: %s", obj.Ret, obj.Msg)
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.Name = obj.Nickname
+	user.NickName = obj.Nickname
+	user.AvatarURL = obj.FigureHD
+	if user.AvatarURL == "" {
+		user.AvatarURL = obj.Figure
+	}
+	user.Location = obj.City
+	if obj.Province != "" && obj.Province != obj.City {
+		user.Location = obj.Province + " " + obj.City
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package qq_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/qq"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), qqProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := qqProvider()
+	a.Equal(provider.ClientKey, "101")
+	a.Equal(provider.Secret, "appkey")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.Scopes, []string{"get_user_info"})
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := qqProvider()
+	session, err := provider.BeginAuth("test_state")
+	s := session.(*qq.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://graph.qq.com/oauth2.0/authorize")
+	a.Contains(s.AuthURL, "client_id=101")
+	a.Contains(s.AuthURL, "scope=get_user_info")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/oauth2.0/token":
+			a.Equal("json", q.Get("fmt"))
+			if q.Get("code") != "good" && q.Get("refresh_token") != "refresh" {
+				fmt.Fprint(w, `callback( {"error":100019,"error_description":"code to access token error"} );`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"token","expires_in":"7776000","refresh_token":"refresh"}`)
+		case "/oauth2.0/me":
+			a.Equal("token", q.Get("access_token"))
+			a.Equal("1", q.Get("unionid"))
+			fmt.Fprint(w, `callback( {"client_id":"101","openid":"openid","unionid":"unionid"} );`)
+		case "/user/get_user_info":
+			a.Equal("openid", q.Get("openid"))
+			a.Equal("101", q.Get("oauth_consumer_key"))
+			fmt.Fprint(w, `{"ret":0,"msg":"","nickname":"Homer","figureurl_qq_1":"http://q/40","figureurl_qq_2":"http://q/100","province":"广东","city":"深圳"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	qq.BaseURL = ts.URL
+	defer func() { qq.BaseURL = "https://graph.qq.com" }()
+	provider := qqProvider()
+	provider.UnionID = true
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"good"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("openid", user.UserID)
+	a.Equal("Homer", user.NickName)
+	a.Equal("http://q/100", user.AvatarURL)
+	a.Equal("广东 深圳", user.Location)
+	a.Equal("unionid", user.RawData["unionid"])
+	a.Equal("refresh", user.RefreshToken)
+	a.False(user.ExpiresAt.IsZero())
+
+	refreshed, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token", refreshed.AccessToken)
+
+	session, _ = provider.BeginAuth("state")
+	_, err = session.Authorize(provider, url.Values{"code": {"bad"}})
+	a.EqualError(err, "CODE: 100019, MSG: code to access token error")
+}
+
+func qqProvider() *qq.Provider {
+	return qq.New("101", "appkey", "/foo")
+}
@@ -0,0 +1,53 @@
+package qq_test
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/qq"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Session(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &qq.Session{}
+
+	a.Implements((*goth.Session)(nil), s)
+}
+
+func Test_GetAuthURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &qq.Session{}
+
+	_, err := s.GetAuthURL()
+	a.Error(err)
+
+	s.AuthURL = "/foo"
+
+	url, _ := s.GetAuthURL()
+	a.Equal(url, "/foo")
+}
+
+func Test_Marshal(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &qq.Session{}
+
+	data := s.Marshal()
+	a.Equal(data, `{"AuthURL":"","AccessToken":"","RefreshToken":"","ExpiresAt":"0001-01-01T00:00:00Z","OpenID":"","UnionID":""}`)
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := qq.New("101", "appkey", "/foo")
+	s, err := provider.UnmarshalSession(`{"AuthURL":"http://qq/auth_url","AccessToken":"1234567890","OpenID":"openid"}`)
+	a.NoError(err)
+	session := s.(*qq.Session)
+	a.Equal(session.AuthURL, "http://qq/auth_url")
+	a.Equal(session.AccessToken, "1234567890")
+	a.Equal(session.OpenID, "openid")
+}
@@ -0,0 +1,92 @@
+package questrade_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/questrade"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), questradeProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := questradeProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := questradeProvider().BeginAuth("test_state")
+	s := session.(*questrade.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://login.questrade.com/oauth2/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			a.Empty(r.PostForm.Get("client_secret"))
+			if r.PostForm.Get("grant_type") == "refresh_token" {
+				a.Equal("refresh", r.PostForm.Get("refresh_token"))
+				fmt.Fprintf(w, `{"access_token":"token2","api_server":"%s/api02/","expires_in":1800,"refresh_token":"refresh2","token_type":"Bearer"}`, ts.URL)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token":"token","api_server":"%s/api01/","expires_in":1800,"refresh_token":"refresh","token_type":"Bearer"}`, ts.URL)
+		case "/api01/v1/accounts":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"accounts":[{"type":"Margin","number":"26598145","status":"Active","isPrimary":true,"isBilling":true,"clientAccountType":"Individual"}],"userId":3000124}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	questrade.TokenURL = ts.URL + "/token"
+	defer func() { questrade.TokenURL = "https://login.questrade.com/oauth2/token" }()
+	provider := questradeProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(ts.URL+"/api01/", session.(*questrade.Session).APIServer)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("3000124", user.UserID)
+	a.Equal("refresh", user.RefreshToken)
+
+	newToken, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("refresh2", newToken.RefreshToken)
+	a.Equal(ts.URL+"/api02/", newToken.Extra("api_server"))
+}
+
+func questradeProvider() *questrade.Provider {
+	return questrade.New("key", "/foo")
+}
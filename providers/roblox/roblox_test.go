@@ -0,0 +1,99 @@
+package roblox_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/roblox"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), robloxProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := robloxProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := robloxProvider().BeginAuth("test_state")
+	s := session.(*roblox.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://apis.roblox.com/oauth/v1/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=openid+profile")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/v1/token":
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","expires_in":899,"scope":"openid profile"}`)
+		case "/oauth/v1/userinfo":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"sub":"1516563360","name":"Jane","nickname":"Jane","preferred_username":"jane_doe","created_at":1584682495,"profile":"https://www.roblox.com/users/1516563360/profile","picture":"https://tr.rbxcdn.com/avatar.png"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	roblox.TokenURL, roblox.UserInfoURL = ts.URL+"/oauth/v1/token", ts.URL+"/oauth/v1/userinfo"
+	defer func() {
+		roblox.TokenURL, roblox.UserInfoURL = "https://apis.roblox.com/oauth/v1/token", "https://apis.roblox.com/oauth/v1/userinfo"
+	}()
+	provider := robloxProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*roblox.Session).CodeVerifier
+
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("1516563360", user.UserID)
+	a.Equal("Jane", user.Name)
+	a.Equal("jane_doe", user.NickName)
+	a.Equal("https://tr.rbxcdn.com/avatar.png", user.AvatarURL)
+	a.Equal("refresh", user.RefreshToken)
+}
+
+func robloxProvider() *roblox.Provider {
+	return roblox.New("key", "secret", "/foo")
+}
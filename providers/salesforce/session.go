@@ -23,6 +23,7 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ID           string //Required to get the user info from sales force
+	CodeVerifier string `json:",omitempty"`
 }
 
 var _ goth.Session = &Session{}
@@ -38,7 +39,7 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Salesforce and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 
 	if err != nil {
 		return "", err
@@ -51,6 +52,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ID = token.Extra("id").(string) //Required to get the user info from sales force
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
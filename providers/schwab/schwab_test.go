@@ -0,0 +1,86 @@
+package schwab_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/schwab"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), schwabProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := schwabProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := schwabProvider().BeginAuth("test_state")
+	s := session.(*schwab.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.schwabapi.com/v1/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			fmt.Fprint(w, `{"expires_in":1800,"token_type":"Bearer","scope":"api","refresh_token":"refresh","access_token":"token","id_token":"id"}`)
+		case "/userPreference":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"accounts":[{"accountNumber":"12345678","primaryAccount":true,"type":"BROKERAGE","nickName":"Individual","displayAcctId":"...678","autoPositionEffect":false,"accountColor":"Green"}],"streamerInfo":[{"streamerSocketUrl":"wss://streamer-api.schwab.com/ws","schwabClientCustomerId":"a1b2c3","schwabClientCorrelId":"d4e5f6","schwabClientChannel":"N9","schwabClientFunctionId":"APIAPP"}],"offers":[{"level2Permissions":true,"mktDataPermission":"NP"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	schwab.TokenURL, schwab.ProfileURL = ts.URL+"/token", ts.URL+"/userPreference"
+	defer func() {
+		schwab.TokenURL, schwab.ProfileURL = "https://api.schwabapi.com/v1/oauth/token", "https://api.schwabapi.com/trader/v1/userPreference"
+	}()
+	provider := schwabProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("a1b2c3", user.UserID)
+	a.Equal("Individual", user.NickName)
+	a.Equal("refresh", user.RefreshToken)
+	a.Equal("id", user.IDToken)
+}
+
+func schwabProvider() *schwab.Provider {
+	return schwab.New("key", "secret", "/foo")
+}
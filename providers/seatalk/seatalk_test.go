@@ -1,7 +1,10 @@
 package seatalk_test
 
 import (
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/bgdsh/goth"
@@ -35,6 +38,34 @@ func Test_BeginAuth(t *testing.T) {
 	a.Contains(s.AuthURL, "seatalkweb.com/webapp/oauth2/authorize")
 }
 
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func Test_FetchUserWithHTTPClient(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		a.Equal("seatalkweb.com", r.URL.Host)
+		a.Equal("1234567890", r.URL.Query().Get("access_token"))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"user_id":"42","name":"Homer Simpson","email":"homer@example.com"}`)),
+		}, nil
+	})}
+	p, err := goth.Configure(provider(), goth.WithHTTPClient(client))
+	a.NoError(err)
+
+	user, err := p.FetchUser(&seatalk.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("homer@example.com", user.Email)
+}
+
 func Test_SessionFromJSON(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -0,0 +1,229 @@
+// Package servicenow implements the OAuth2 protocol for authenticating users
+// through a ServiceNow instance. The URL of the instance is kept in
+// RawData["instance_url"].
+// Reference: https://docs.servicenow.com/bundle/vancouver-platform-security/page/administer/security/concept/c_OAuthAuthorizationCodeFlow.html
+package servicenow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// ScopeUserAccount gives the access of the user, it is what ServiceNow grants
+// when no scopes are given.
+const ScopeUserAccount = "useraccount"
+
+// userFields are the fields of the sys_user record read by FetchUser.
+const userFields = "sys_id,user_name,name,first_name,last_name,email,title,location,photo"
+
+// New creates a new ServiceNow provider for the instance served from
+// https://<instance>.service-now.com, and sets up important connection details.
+// You should always call `servicenow.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL, instance string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, "https://"+instance+".service-now.com", scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but takes the URL of the instance,
+// for instances served from a custom domain.
+func NewCustomisedURL(clientKey, secret, callbackURL, instanceURL string, scopes ...string) *Provider {
+	instanceURL = strings.TrimSuffix(instanceURL, "/")
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		InstanceURL:  instanceURL,
+		providerName: "servicenow",
+		profileURL: instanceURL + "/api/now/table/sys_user?" + url.Values{
+			"sysparm_query":  {"sys_id=javascript:gs.getUserID()"},
+			"sysparm_fields": {userFields},
+			"sysparm_limit":  {"1"},
+		}.Encode(),
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing ServiceNow.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	InstanceURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the servicenow package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ServiceNow for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to ServiceNow and access the sys_user record of the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	if err == nil {
+		user.RawData["instance_url"] = p.InstanceURL
+	}
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Result []json.RawMessage `json:"result"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if len(u.Result) == 0 {
+		return fmt.Errorf("%s responded without the user", user.Provider)
+	}
+
+	profile := struct {
+		SysID     string `json:"sys_id"`
+		UserName  string `json:"user_name"`
+		Name      string `json:"name"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+		Title     string `json:"title"`
+	}{}
+	if err := json.Unmarshal(u.Result[0], &profile); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(u.Result[0], &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = profile.SysID
+	user.NickName = profile.UserName
+	user.Name = profile.Name
+	user.FirstName = profile.FirstName
+	user.LastName = profile.LastName
+	user.Email = profile.Email
+	user.Description = profile.Title
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.InstanceURL + "/oauth_auth.do",
+			TokenURL:  provider.InstanceURL + "/oauth_token.do",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,85 @@
+package servicenow_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/servicenow"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), servicenowProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := servicenowProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.InstanceURL, "https://acme.service-now.com")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := servicenowProvider().BeginAuth("test_state")
+	s := session.(*servicenow.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://acme.service-now.com/oauth_auth.do")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth_token.do":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer","refresh_token":"refresh","scope":"useraccount","expires_in":1799}`)
+		case "/api/now/table/sys_user":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			a.Equal("sys_id=javascript:gs.getUserID()", r.URL.Query().Get("sysparm_query"))
+			fmt.Fprint(w, `{"result":[{"sys_id":"6816f79cc0a8016401c5a33be04be441","user_name":"abel.tuter","name":"Abel Tuter","first_name":"Abel","last_name":"Tuter","email":"abel.tuter@example.com","title":"Service Desk Agent"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := servicenow.NewCustomisedURL("key", "secret", "/foo", ts.URL)
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("6816f79cc0a8016401c5a33be04be441", user.UserID)
+	a.Equal("abel.tuter", user.NickName)
+	a.Equal("Abel Tuter", user.Name)
+	a.Equal("abel.tuter@example.com", user.Email)
+	a.Equal("Service Desk Agent", user.Description)
+	a.Equal("refresh", user.RefreshToken)
+	a.Equal(ts.URL, user.RawData["instance_url"])
+}
+
+func servicenowProvider() *servicenow.Provider {
+	return servicenow.New("key", "secret", "/foo", "acme")
+}
@@ -21,11 +21,12 @@ const (
 
 // Session stores data during the auth process with Shopify.
 type Session struct {
-	AuthURL     string
-	AccessToken string
-	Hostname    string
-	HMAC        string
-	ExpiresAt   time.Time
+	AuthURL      string
+	AccessToken  string
+	Hostname     string
+	HMAC         string
+	ExpiresAt    time.Time
+	CodeVerifier string `json:",omitempty"`
 }
 
 var _ goth.Session = &Session{}
@@ -67,7 +68,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 
 	// Make the exchange for an access token.
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -81,6 +82,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.Hostname = params.Get("hostname")
 	s.HMAC = params.Get("hmac")
 
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
@@ -0,0 +1,378 @@
+// Package smartonfhir implements the SMART App Launch profile of OAuth2, for
+// authenticating the users of an EHR through its FHIR server.
+//
+// A standalone launch goes through BeginAuth, as with any provider. An EHR
+// launch starts with the EHR opening the launch URL of the application with
+// the iss and launch parameters, the application begins the authentication of
+// the copy returned by Launch:
+//
+//	state := gothic.SetState(c)
+//	session, err := provider.Launch(c.QueryParam("launch")).BeginAuth(state)
+//	// store session.Marshal() with gothic.StoreInSession, and redirect to its AuthURL
+//
+// The callback is completed as usual, the launch context (patient, encounter)
+// is kept in the session and in RawData. The fhirUser of the ID token is the
+// UserID, FetchUser reads the name and email of the FHIR resource it names.
+// Reference: https://hl7.org/fhir/smart-app-launch/app-launch.html
+package smartonfhir
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// Scopes of SMART App Launch, ScopeOpenID and ScopeFHIRUser are requested
+// when no scopes are given. Add the scopes of the FHIR resources the
+// application reads, such as patient/Observation.rs.
+const (
+	ScopeOpenID          = "openid"
+	ScopeFHIRUser        = "fhirUser"
+	ScopeLaunch          = "launch"
+	ScopeLaunchPatient   = "launch/patient"
+	ScopeLaunchEncounter = "launch/encounter"
+	ScopeOfflineAccess   = "offline_access"
+	ScopeOnlineAccess    = "online_access"
+)
+
+// Keys of the launch context in RawData.
+const (
+	RawDataFHIRBaseURL = "fhir_base_url"
+	RawDataFHIRUser    = "fhirUser"
+	RawDataPatient     = "patient"
+	RawDataEncounter   = "encounter"
+)
+
+// SMARTConfig is the SMART configuration of a FHIR server.
+// See https://hl7.org/fhir/smart-app-launch/conformance.html
+type SMARTConfig struct {
+	Issuer                string   `json:"issuer"`
+	JWKSURI               string   `json:"jwks_uri"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	Capabilities          []string `json:"capabilities"`
+}
+
+// New creates a new SMART on FHIR provider for the FHIR server at
+// fhirBaseURL, whose SMART configuration is discovered. secret is empty for
+// public clients.
+// You should always call `smartonfhir.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL, fhirBaseURL string, scopes ...string) (*Provider, error) {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeOpenID, ScopeFHIRUser}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		FHIRBaseURL:  strings.TrimSuffix(fhirBaseURL, "/"),
+		providerName: "smartonfhir",
+	}
+
+	config, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+	p.SMARTConfig = config
+	if config.JWKSURI != "" {
+		p.keys = goth.NewKeySet(config.JWKSURI, 12*time.Hour)
+	}
+	p.config = newConfig(p, scopes)
+	return p, nil
+}
+
+// Provider is the implementation of `goth.Provider` for accessing a FHIR
+// server with SMART App Launch.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	FHIRBaseURL  string
+	SMARTConfig  *SMARTConfig
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	launch       string
+	keys         *goth.KeySet
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Launch returns a copy of the provider beginning the authentication of an
+// EHR launch, launch is the parameter the EHR opened the launch URL with.
+func (p *Provider) Launch(launch string) *Provider {
+	c := *p
+	c.launch = launch
+	return &c
+}
+
+// Debug is a no-op for the smartonfhir package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks the FHIR server for an authentication end-point. The FHIR
+// server is the audience, and PKCE is always used.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("aud", p.FHIRBaseURL),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+	scopes := p.config.Scopes
+	if p.launch != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("launch", p.launch))
+		if !hasScope(scopes, ScopeLaunch) {
+			scopes = append([]string{ScopeLaunch}, scopes...)
+			opts = append(opts, oauth2.SetAuthURLParam("scope", strings.Join(scopes, " ")))
+		}
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, opts...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// fhirUserFromIDToken returns the fhirUser claim of idToken, whose signature
+// is checked when the FHIR server publishes its keys.
+func (p *Provider) fhirUserFromIDToken(idToken string) (string, error) {
+	claims := &struct {
+		jwt.RegisteredClaims
+		FHIRUser string `json:"fhirUser"`
+	}{}
+	methods := []string{"RS256", "ES256", "ES384"}
+	if p.keys != nil {
+		if _, err := idtoken.Validate(idToken, claims, p.keys.Keyfunc(p.Client()), methods, p.SMARTConfig.Issuer, p.ClientKey); err != nil {
+			return "", fmt.Errorf("%s: %w", p.providerName, err)
+		}
+		return claims.FHIRUser, nil
+	}
+
+	// The ID token was received from the token end-point over TLS.
+	parser := &jwt.Parser{ValidMethods: methods}
+	if _, _, err := parser.ParseUnverified(idToken, claims); err != nil {
+		return "", fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	if err := goth.ValidateClaims(&claims.RegisteredClaims, p.SMARTConfig.Issuer, p.ClientKey); err != nil {
+		return "", fmt.Errorf("%s: %w", p.providerName, err)
+	}
+	return claims.FHIRUser, nil
+}
+
+// FetchUser returns the user of the session, with its launch context. When
+// the session has a fhirUser, the name and email are read from the FHIR
+// resource it names, such as Practitioner/123.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		IDToken:      sess.IDToken,
+		UserID:       sess.FHIRUser,
+		RawData: map[string]interface{}{
+			RawDataFHIRBaseURL: p.FHIRBaseURL,
+			RawDataFHIRUser:    sess.FHIRUser,
+			RawDataPatient:     sess.Patient,
+			RawDataEncounter:   sess.Encounter,
+		},
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+	if sess.FHIRUser == "" {
+		return user, nil
+	}
+
+	resourceURL := sess.FHIRUser
+	if !strings.HasPrefix(resourceURL, "http://") && !strings.HasPrefix(resourceURL, "https://") {
+		resourceURL = p.FHIRBaseURL + "/" + resourceURL
+	}
+	req, err := http.NewRequest("GET", resourceURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/fhir+json")
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ResourceType string `json:"resourceType"`
+		Name         []struct {
+			Text   string   `json:"text"`
+			Family string   `json:"family"`
+			Given  []string `json:"given"`
+		} `json:"name"`
+		Telecom []struct {
+			System string `json:"system"`
+			Value  string `json:"value"`
+		} `json:"telecom"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	resource := map[string]interface{}{}
+	if err := json.Unmarshal(b, &resource); err != nil {
+		return err
+	}
+	user.RawData[u.ResourceType] = resource
+
+	if len(u.Name) > 0 {
+		name := u.Name[0]
+		user.FirstName = strings.Join(name.Given, " ")
+		user.LastName = name.Family
+		user.Name = name.Text
+		if user.Name == "" {
+			user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		}
+	}
+	for _, telecom := range u.Telecom {
+		if telecom.System == "email" {
+			user.Email = telecom.Value
+			break
+		}
+	}
+	return nil
+}
+
+// discover fetches the SMART configuration of the FHIR server.
+func (p *Provider) discover() (*SMARTConfig, error) {
+	resp, err := p.Client().Get(p.FHIRBaseURL + "/.well-known/smart-configuration")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to fetch the SMART configuration", resp)
+	}
+
+	config := &SMARTConfig{}
+	if err := json.NewDecoder(resp.Body).Decode(config); err != nil {
+		return nil, err
+	}
+	if config.AuthorizationEndpoint == "" || config.TokenEndpoint == "" {
+		return nil, fmt.Errorf("%s: the SMART configuration has no authorization or token end-point", p.providerName)
+	}
+	return config, nil
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	// Confidential clients authenticate with HTTP Basic, public clients only
+	// send their client_id.
+	authStyle := oauth2.AuthStyleInHeader
+	if provider.Secret == "" {
+		authStyle = oauth2.AuthStyleInParams
+	}
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.SMARTConfig.AuthorizationEndpoint,
+			TokenURL:  provider.SMARTConfig.TokenEndpoint,
+			AuthStyle: authStyle,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token, it is only
+// issued with the ScopeOfflineAccess or ScopeOnlineAccess scopes.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,187 @@
+package smartonfhir_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/smartonfhir"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := fhirServer(t)
+	defer ts.Close()
+
+	a.Implements((*goth.Provider)(nil), smartonfhirProvider(t, ts.URL))
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := fhirServer(t)
+	defer ts.Close()
+
+	provider := smartonfhirProvider(t, ts.URL)
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.FHIRBaseURL, ts.URL+"/fhir")
+	a.Equal(provider.SMARTConfig.TokenEndpoint, ts.URL+"/auth/token")
+}
+
+func Test_NewWithoutConfiguration(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	_, err := smartonfhir.New("key", "", "/foo", ts.URL)
+	a.EqualError(err, "smartonfhir responded with a 404 trying to fetch the SMART configuration")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := fhirServer(t)
+	defer ts.Close()
+
+	session, err := smartonfhirProvider(t, ts.URL).BeginAuth("test_state")
+	s := session.(*smartonfhir.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, ts.URL+"/auth/authorize")
+	a.Contains(s.AuthURL, "aud="+url.QueryEscape(ts.URL+"/fhir"))
+	a.Contains(s.AuthURL, "scope=openid+fhirUser+launch%2Fpatient")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+	a.NotContains(s.AuthURL, "launch=")
+	a.NotEmpty(s.CodeVerifier)
+}
+
+func Test_BeginAuthLaunch(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := fhirServer(t)
+	defer ts.Close()
+
+	session, err := smartonfhirProvider(t, ts.URL).Launch("xyz123").BeginAuth("test_state")
+	s := session.(*smartonfhir.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "launch=xyz123")
+	a.Contains(s.AuthURL, "scope=launch+openid+fhirUser+launch%2Fpatient")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/fhir/.well-known/smart-configuration":
+			fmt.Fprintf(w, `{"issuer":%q,"jwks_uri":%q,"authorization_endpoint":%q,"token_endpoint":%q,"capabilities":["launch-ehr","launch-standalone"]}`,
+				ts.URL, ts.URL+"/auth/jwks", ts.URL+"/auth/authorize", ts.URL+"/auth/token")
+		case "/auth/jwks":
+			pub, err := jwk.New(&key.PublicKey)
+			a.NoError(err)
+			a.NoError(pub.Set(jwk.KeyIDKey, "ehr"))
+			set := jwk.NewSet()
+			set.Add(pub)
+			a.NoError(json.NewEncoder(w).Encode(set))
+		case "/auth/token":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			a.NotEmpty(r.PostForm.Get("code_verifier"))
+
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+				"iss":      ts.URL,
+				"aud":      "key",
+				"sub":      "42",
+				"exp":      time.Now().Add(time.Hour).Unix(),
+				"fhirUser": "Practitioner/123",
+			})
+			token.Header["kid"] = "ehr"
+			idToken, err := token.SignedString(key)
+			a.NoError(err)
+			fmt.Fprintf(w, `{"access_token":"token","token_type":"Bearer","expires_in":3600,"scope":"openid fhirUser launch/patient","patient":"456","encounter":"789","id_token":%q}`, idToken)
+		case "/fhir/Practitioner/123":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"resourceType":"Practitioner","id":"123","name":[{"family":"Doe","given":["Jane","Q"]}],"telecom":[{"system":"phone","value":"555-0100"},{"system":"email","value":"jane@example.com"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := smartonfhirProvider(t, ts.URL)
+
+	session, err := provider.BeginAuth("state")
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("Practitioner/123", user.UserID)
+	a.Equal("Jane Q", user.FirstName)
+	a.Equal("Doe", user.LastName)
+	a.Equal("Jane Q Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("456", user.RawData[smartonfhir.RawDataPatient])
+	a.Equal("789", user.RawData[smartonfhir.RawDataEncounter])
+	a.NotNil(user.RawData["Practitioner"])
+	a.NotEmpty(user.IDToken)
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := fhirServer(t)
+	defer ts.Close()
+
+	provider := smartonfhirProvider(t, ts.URL)
+
+	s, err := provider.UnmarshalSession(`{"AuthURL":"https://ehr.example.com/auth/authorize","AccessToken":"1234567890","Patient":"456"}`)
+	a.NoError(err)
+	session := s.(*smartonfhir.Session)
+	a.Equal(session.AuthURL, "https://ehr.example.com/auth/authorize")
+	a.Equal(session.AccessToken, "1234567890")
+	a.Equal(session.Patient, "456")
+}
+
+// fhirServer serves the SMART configuration of a FHIR server at /fhir.
+func fhirServer(t *testing.T) *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fhir/.well-known/smart-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"issuer":%q,"authorization_endpoint":%q,"token_endpoint":%q,"capabilities":["launch-ehr","launch-standalone"]}`,
+			ts.URL, ts.URL+"/auth/authorize", ts.URL+"/auth/token")
+	}))
+	return ts
+}
+
+func smartonfhirProvider(t *testing.T, serverURL string) *smartonfhir.Provider {
+	provider, err := smartonfhir.New("key", "", "/foo", serverURL+"/fhir/", smartonfhir.ScopeOpenID, smartonfhir.ScopeFHIRUser, smartonfhir.ScopeLaunchPatient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
@@ -0,0 +1,103 @@
+package snapchat_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/snapchat"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), snapchatProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := snapchatProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := snapchatProvider().BeginAuth("test_state")
+	s := session.(*snapchat.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://accounts.snapchat.com/accounts/oauth2/auth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=https%3A%2F%2Fauth.snapchat.com%2Foauth2%2Fapi%2Fuser.external_id+https%3A%2F%2Fauth.snapchat.com%2Foauth2%2Fapi%2Fuser.display_name")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/accounts/oauth2/token":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`)
+		case "/v1/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			a.Equal("{me{externalId displayName bitmoji{avatar}}}", r.URL.Query().Get("query"))
+			fmt.Fprint(w, `{"data":{"me":{"externalId":"CAESIPiRBp0e5gLDq7VVurQ3rVdmdbqxpOJWynjyBL/xlo0w","displayName":"Jane Doe","bitmoji":{"avatar":"https://sdk.bitmoji.com/avatar.png"}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	snapchat.TokenURL, snapchat.ProfileURL = ts.URL+"/accounts/oauth2/token", ts.URL+"/v1/me"
+	defer func() {
+		snapchat.TokenURL, snapchat.ProfileURL = "https://accounts.snapchat.com/accounts/oauth2/token", "https://kit.snapchat.com/v1/me"
+	}()
+	provider := snapchatProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*snapchat.Session).CodeVerifier
+
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("CAESIPiRBp0e5gLDq7VVurQ3rVdmdbqxpOJWynjyBL/xlo0w", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("https://sdk.bitmoji.com/avatar.png", user.AvatarURL)
+	a.Equal("refresh", user.RefreshToken)
+}
+
+func snapchatProvider() *snapchat.Provider {
+	return snapchat.New("key", "secret", "/foo")
+}
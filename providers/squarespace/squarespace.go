@@ -0,0 +1,257 @@
+// Package squarespace implements the OAuth2 protocol for authorizing applications on Squarespace websites.
+// The user is the website the application was authorized for. Refresh tokens
+// are only granted with AccessTypeOffline, and can be used once.
+// Reference: https://developers.squarespace.com/commerce-apis/oauth
+package squarespace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL    = "https://login.squarespace.com/api/1/login/oauth/provider/authorize"
+	TokenURL   = "https://login.squarespace.com/api/1/login/oauth/provider/tokens"
+	ProfileURL = "https://api.squarespace.com/1.0/authorization/website"
+)
+
+// Scopes of the Squarespace Commerce APIs.
+const (
+	ScopeOrders           = "website.orders"
+	ScopeOrdersRead       = "website.orders.read"
+	ScopeTransactionsRead = "website.transactions.read"
+	ScopeInventory        = "website.inventory"
+	ScopeInventoryRead    = "website.inventory.read"
+	ScopeProducts         = "website.products"
+	ScopeProductsRead     = "website.products.read"
+)
+
+// New creates a new Squarespace provider, and sets up important connection details.
+// You should always call `squarespace.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		UserAgent:    "goth",
+		Offline:      true,
+		providerName: "squarespace",
+		tokenURL:     TokenURL,
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Squarespace.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+
+	// UserAgent identifies the application, Squarespace requires it.
+	UserAgent string
+	// Offline asks for a refresh token, it is set by New.
+	Offline bool
+
+	config       *oauth2.Config
+	providerName string
+	tokenURL     string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	if options.PKCE {
+		return nil, &goth.OptionNotSupportedError{Provider: p.Name(), Option: "WithPKCE"}
+	}
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the squarespace package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Squarespace for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	var opts []oauth2.AuthCodeOption
+	if p.Offline {
+		opts = append(opts, oauth2.AccessTypeOffline)
+	}
+	return &Session{
+		AuthURL: goth.ScopeFormatOf(p.Name(), goth.CommaSeparatedScopes).AuthCodeURL(p.config, state, opts...),
+	}, nil
+}
+
+// FetchUser will go to Squarespace and access the website the application was
+// authorized for. Squarespace does not share information about users.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("User-Agent", p.UserAgent)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID         string `json:"id"`
+		Identifier string `json:"identifier"`
+		URL        string `json:"url"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.ID
+	user.NickName = u.Identifier
+	return nil
+}
+
+// token calls the token end-point with params, as JSON.
+func (p *Provider) token(params map[string]string) (*oauth2.Token, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", p.tokenURL, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.ClientKey, p.Secret)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", p.UserAgent)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to get a token", resp)
+	}
+
+	t := struct {
+		TokenType            string  `json:"token_type"`
+		AccessToken          string  `json:"access_token"`
+		AccessTokenExpiresAt float64 `json:"access_token_expires_at"`
+		RefreshToken         string  `json:"refresh_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+	// access_token_expires_at is in seconds, with a fraction.
+	return &oauth2.Token{
+		AccessToken:  t.AccessToken,
+		TokenType:    t.TokenType,
+		RefreshToken: t.RefreshToken,
+		Expiry:       time.Unix(0, int64(t.AccessTokenExpiresAt*float64(time.Second))),
+	}, nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. The refresh
+// token can not be used again, store the new one.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.token(map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
@@ -0,0 +1,103 @@
+package squarespace_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/squarespace"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), squarespaceProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := squarespaceProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := squarespaceProvider().BeginAuth("test_state")
+	s := session.(*squarespace.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://login.squarespace.com/api/1/login/oauth/provider/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=website.orders.read%2Cwebsite.products.read")
+	a.Contains(s.AuthURL, "access_type=offline")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		a.Equal("goth", r.Header.Get("User-Agent"))
+		switch r.URL.Path {
+		case "/tokens":
+			user, pass, ok := r.BasicAuth()
+			a.True(ok)
+			a.Equal("key", user)
+			a.Equal("secret", pass)
+			body := map[string]string{}
+			a.NoError(json.NewDecoder(r.Body).Decode(&body))
+			if body["grant_type"] == "refresh_token" {
+				a.Equal("refresh", body["refresh_token"])
+				fmt.Fprint(w, `{"token_type":"bearer","access_token":"token2","access_token_expires_at":1579210785.5,"refresh_token":"refresh2","refresh_token_expires_at":1579813785.5}`)
+				return
+			}
+			a.Equal("code", body["code"])
+			a.Equal("/foo", body["redirect_uri"])
+			fmt.Fprint(w, `{"token_type":"bearer","access_token":"token","access_token_expires_at":4102444800.5,"refresh_token":"refresh","refresh_token_expires_at":4102444800.5,"session_id":"abc"}`)
+		case "/website":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"id":"5e1bb5a07ba5b5213e9ad1a3","identifier":"my-store","url":"https://my-store.squarespace.com","language":"en-US","timeZone":"America/New_York"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	squarespace.TokenURL, squarespace.ProfileURL = ts.URL+"/tokens", ts.URL+"/website"
+	defer func() {
+		squarespace.TokenURL, squarespace.ProfileURL = "https://login.squarespace.com/api/1/login/oauth/provider/tokens", "https://api.squarespace.com/1.0/authorization/website"
+	}()
+	provider := squarespaceProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("5e1bb5a07ba5b5213e9ad1a3", user.UserID)
+	a.Equal("my-store", user.NickName)
+	a.Equal(int64(4102444800), user.ExpiresAt.Unix())
+
+	newToken, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token2", newToken.AccessToken)
+	a.Equal("refresh2", newToken.RefreshToken)
+}
+
+func squarespaceProvider() *squarespace.Provider {
+	return squarespace.New("key", "secret", "/foo", squarespace.ScopeOrdersRead, squarespace.ScopeProductsRead)
+}
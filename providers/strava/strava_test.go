@@ -39,6 +39,10 @@ func Test_BeginAuth(t *testing.T) {
 	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("STRAVA_KEY")))
 	a.Contains(s.AuthURL, "state=test_state")
 	a.Contains(s.AuthURL, "scope=read")
+
+	session, err = strava.New("key", "secret", "/foo", "read", "activity:read_all").BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*strava.Session).AuthURL, "scope=read%2Cactivity%3Aread_all", "Strava scopes are comma separated")
 }
 
 func Test_SessionFromJSON(t *testing.T) {
@@ -16,6 +16,7 @@ type Session struct {
 	RefreshToken string
 	ExpiresAt    time.Time
 	ID           string
+	CodeVerifier string `json:",omitempty"`
 }
 
 var _ goth.Session = &Session{}
@@ -31,7 +32,7 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Stripe and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -44,6 +45,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
 	s.ID = token.Extra("stripe_user_id").(string) //Required to get the user info from sales force
+	s.CodeVerifier = ""
 	return token.AccessToken, err
 }
 
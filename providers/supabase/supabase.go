@@ -0,0 +1,198 @@
+// Package supabase authenticates the users of Supabase Auth in a Go backend.
+// Clients sign in with supabase-js and send their access token along with
+// their requests: the provider validates the JWT and turns its claims into a
+// goth.User. Use it to load the user of gothic.RequireAuth:
+//
+//	sb := supabase.New("https://abcdefghijklmnop.supabase.co")
+//	loader := gothic.UserLoaderFunc(func(c echo.Context) (goth.User, bool, error) {
+//		user, err := sb.UserFromRequest(c.Request())
+//		return user, err == nil, nil
+//	})
+//
+// Projects signing their JWTs with asymmetric keys are validated against the
+// JWKS of the project. Projects still using the legacy JWT secret set
+// JWTSecret. With a ServiceRoleKey, the user is completed from the Auth admin
+// API, such as the identities linked to the account.
+// Reference: https://supabase.com/docs/guides/auth/jwts
+package supabase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/idtoken"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Audience is the audience of the access tokens of signed in users.
+const Audience = "authenticated"
+
+// RawDataUser is the key of RawData holding the user returned by the admin
+// API, when the provider has a ServiceRoleKey.
+const RawDataUser = "user"
+
+// ErrNoToken is returned when a request has no bearer token.
+var ErrNoToken = errors.New("supabase: request has no bearer token")
+
+// New creates a new Supabase provider for the project at projectURL, such as
+// https://abcdefghijklmnop.supabase.co.
+func New(projectURL string) *Provider {
+	projectURL = strings.TrimSuffix(projectURL, "/")
+	return &Provider{
+		ProjectURL:   projectURL,
+		Audience:     Audience,
+		providerName: "supabase",
+		keys:         goth.NewKeySet(projectURL+"/auth/v1/.well-known/jwks.json", 10*time.Minute),
+	}
+}
+
+// Provider validates the access tokens of Supabase Auth.
+type Provider struct {
+	ProjectURL string
+	Audience   string
+	// JWTSecret is the legacy JWT secret of the project, to validate the
+	// tokens signed with HS256.
+	JWTSecret []byte
+	// ServiceRoleKey is the service_role key of the project, to complete the
+	// user from the admin API. It bypasses row level security, keep it on
+	// the server.
+	ServiceRoleKey string
+	HTTPClient     *http.Client
+	providerName   string
+	keys           *goth.KeySet
+}
+
+// Claims are the claims of a Supabase access token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Email        string                 `json:"email"`
+	Phone        string                 `json:"phone"`
+	Role         string                 `json:"role"`
+	AAL          string                 `json:"aal"`
+	SessionID    string                 `json:"session_id"`
+	IsAnonymous  bool                   `json:"is_anonymous"`
+	AppMetadata  map[string]interface{} `json:"app_metadata"`
+	UserMetadata map[string]interface{} `json:"user_metadata"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// UserFromRequest returns the user of the access token the request carries
+// in its Authorization header. It returns ErrNoToken when there is none.
+func (p *Provider) UserFromRequest(r *http.Request) (goth.User, error) {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return goth.User{Provider: p.Name()}, ErrNoToken
+	}
+	return p.UserFromAccessToken(strings.TrimSpace(auth[7:]))
+}
+
+// UserFromAccessToken validates a Supabase access token, and returns the
+// user it was issued for. The claims are kept in RawData.
+func (p *Provider) UserFromAccessToken(accessToken string) (goth.User, error) {
+	user := goth.User{Provider: p.Name(), AccessToken: accessToken}
+
+	methods := []string{"ES256", "RS256"}
+	if len(p.JWTSecret) > 0 {
+		methods = append(methods, "HS256")
+	}
+	claims := &Claims{}
+	raw, err := idtoken.Validate(accessToken, claims, p.keyfunc, methods, p.ProjectURL+"/auth/v1", p.Audience)
+	if err != nil {
+		return user, fmt.Errorf("%s: %w", p.providerName, err)
+	}
+
+	user.UserID = claims.Subject
+	user.Email = claims.Email
+	user.RawData = raw
+	userFromMetadata(claims.UserMetadata, &user)
+	if claims.ExpiresAt != nil {
+		user.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	if p.ServiceRoleKey == "" {
+		return user, nil
+	}
+	err = p.adminUser(&user)
+	return user, err
+}
+
+// adminUser completes user with the user returned by the admin API.
+func (p *Provider) adminUser(user *goth.User) error {
+	req, err := http.NewRequest("GET", p.ProjectURL+"/auth/v1/admin/users/"+url.PathEscape(user.UserID), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("apikey", p.ServiceRoleKey)
+	req.Header.Add("Authorization", "Bearer "+p.ServiceRoleKey)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	u := map[string]interface{}{}
+	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return err
+	}
+	user.RawData[RawDataUser] = u
+	if email, ok := u["email"].(string); ok && email != "" {
+		user.Email = email
+	}
+	metadata, _ := u["user_metadata"].(map[string]interface{})
+	userFromMetadata(metadata, user)
+	return nil
+}
+
+// userFromMetadata reads the profile the identity providers of the user
+// filled in its user_metadata.
+func userFromMetadata(metadata map[string]interface{}, user *goth.User) {
+	str := func(keys ...string) string {
+		for _, key := range keys {
+			if s, ok := metadata[key].(string); ok && s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	if name := str("full_name", "name"); name != "" {
+		user.Name = name
+	}
+	if nickName := str("user_name", "preferred_username"); nickName != "" {
+		user.NickName = nickName
+	}
+	if avatarURL := str("avatar_url", "picture"); avatarURL != "" {
+		user.AvatarURL = avatarURL
+	}
+}
+
+func (p *Provider) keyfunc(t *jwt.Token) (interface{}, error) {
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); ok {
+		return p.JWTSecret, nil
+	}
+	return p.keys.Keyfunc(p.Client())(t)
+}
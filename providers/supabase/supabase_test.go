@@ -0,0 +1,122 @@
+package supabase_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/supabase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := supabase.New("https://abcdefghijklmnop.supabase.co/")
+	a.Equal("https://abcdefghijklmnop.supabase.co", provider.ProjectURL)
+	a.Equal(supabase.Audience, provider.Audience)
+	a.Equal("supabase", provider.Name())
+}
+
+func Test_UserFromRequest(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Now().Truncate(time.Second)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth/v1/.well-known/jwks.json":
+			key, err := jwk.New(&priv.PublicKey)
+			a.NoError(err)
+			a.NoError(key.Set(jwk.KeyIDKey, "3f2a"))
+			set := jwk.NewSet()
+			set.Add(key)
+			a.NoError(json.NewEncoder(w).Encode(set))
+		case "/auth/v1/admin/users/d0a1b2c3-4e5f-4a6b-8c7d-9e0f1a2b3c4d":
+			a.Equal("service", r.Header.Get("apikey"))
+			a.Equal("Bearer service", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"id":"d0a1b2c3-4e5f-4a6b-8c7d-9e0f1a2b3c4d","aud":"authenticated","role":"authenticated","email":"jane@example.com","app_metadata":{"provider":"github","providers":["github"]},"user_metadata":{"avatar_url":"https://avatars.githubusercontent.com/u/1","full_name":"Jane Doe","user_name":"janedoe"},"identities":[{"provider":"github","identity_id":"1"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := supabase.New(ts.URL)
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = "3f2a"
+		signed, err := token.SignedString(key)
+		a.NoError(err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":           ts.URL + "/auth/v1",
+			"aud":           supabase.Audience,
+			"sub":           "d0a1b2c3-4e5f-4a6b-8c7d-9e0f1a2b3c4d",
+			"email":         "jane@example.com",
+			"role":          "authenticated",
+			"aal":           "aal1",
+			"session_id":    "5b4c3d2e",
+			"iat":           now.Unix(),
+			"exp":           now.Add(time.Hour).Unix(),
+			"app_metadata":  map[string]interface{}{"provider": "github", "providers": []string{"github"}},
+			"user_metadata": map[string]interface{}{"full_name": "Jane Doe", "avatar_url": "https://avatars.githubusercontent.com/u/1"},
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	_, err = provider.UserFromRequest(req)
+	a.Equal(supabase.ErrNoToken, err)
+
+	req.Header.Set("Authorization", "Bearer "+sign(jwt.SigningMethodES256, priv, claims()))
+	user, err := provider.UserFromRequest(req)
+	a.NoError(err)
+	a.Equal("supabase", user.Provider)
+	a.Equal("d0a1b2c3-4e5f-4a6b-8c7d-9e0f1a2b3c4d", user.UserID)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("https://avatars.githubusercontent.com/u/1", user.AvatarURL)
+	a.Equal("authenticated", user.RawData["role"])
+	a.Equal(now.Add(time.Hour), user.ExpiresAt)
+
+	c := claims()
+	c["aud"] = "anon"
+	_, err = provider.UserFromAccessToken(sign(jwt.SigningMethodES256, priv, c))
+	a.EqualError(err, "supabase: audience is incorrect")
+
+	c = claims()
+	c["exp"] = now.Add(-time.Minute).Unix()
+	_, err = provider.UserFromAccessToken(sign(jwt.SigningMethodES256, priv, c))
+	a.EqualError(err, "supabase: token is expired")
+
+	// Tokens signed with the legacy JWT secret are only accepted when it is set.
+	legacy := sign(jwt.SigningMethodHS256, []byte("super-secret-jwt-token"), claims())
+	_, err = provider.UserFromAccessToken(legacy)
+	a.Error(err)
+	provider.JWTSecret = []byte("super-secret-jwt-token")
+	_, err = provider.UserFromAccessToken(legacy)
+	a.NoError(err)
+
+	provider.ServiceRoleKey = "service"
+	user, err = provider.UserFromAccessToken(sign(jwt.SigningMethodES256, priv, claims()))
+	a.NoError(err)
+	a.Equal("janedoe", user.NickName)
+	a.NotNil(user.RawData[supabase.RawDataUser])
+}
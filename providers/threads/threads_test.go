@@ -0,0 +1,99 @@
+package threads_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/threads"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), threadsProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := threadsProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := threadsProvider().BeginAuth("test_state")
+	s := session.(*threads.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://threads.net/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=threads_basic")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/access_token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"access_token":"short","user_id":1234}`)
+		case "/access_token":
+			a.Equal("th_exchange_token", r.FormValue("grant_type"))
+			a.Equal("short", r.FormValue("access_token"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","expires_in":5183944}`)
+		case "/refresh_access_token":
+			a.Equal("th_refresh_token", r.FormValue("grant_type"))
+			a.Equal("token", r.FormValue("access_token"))
+			fmt.Fprint(w, `{"access_token":"refreshed","token_type":"bearer","expires_in":5183944}`)
+		case "/v1.0/me":
+			a.Equal("token", r.FormValue("access_token"))
+			fmt.Fprint(w, `{"id":"1234","username":"jane_doe","name":"Jane Doe","threads_profile_picture_url":"https://scontent.cdninstagram.com/p.jpg","threads_biography":"Hello"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	threads.TokenURL, threads.GraphURL = ts.URL+"/oauth/access_token", ts.URL
+	defer func() {
+		threads.TokenURL, threads.GraphURL = "https://graph.threads.net/oauth/access_token", "https://graph.threads.net"
+	}()
+	provider := threadsProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("1234", user.UserID)
+	a.Equal("jane_doe", user.NickName)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("token", user.RefreshToken)
+	a.True(user.ExpiresAt.After(time.Now().Add(59 * 24 * time.Hour)))
+
+	newToken, err := provider.RefreshToken(user.RefreshToken)
+	a.NoError(err)
+	a.Equal("refreshed", newToken.AccessToken)
+	a.Equal("refreshed", newToken.RefreshToken)
+}
+
+func threadsProvider() *threads.Provider {
+	return threads.New("key", "secret", "/foo")
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"time"
 
@@ -19,6 +18,7 @@ type Session struct {
 	OpenID           string
 	RefreshToken     string
 	RefreshExpiresAt time.Time
+	CodeVerifier     string `json:",omitempty"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the TikTok provider.
@@ -30,7 +30,7 @@ func (s Session) GetAuthURL() (string, error) {
 }
 
 // Authorize the session with TikTok and return the access token to be stored for future use. Note that
-// we call the endpoints directly vs calling *oauth2.Config.Exchange() due to inconsistent TikTok param names.
+// we call the endpoints directly vs calling *oauth2.Config.Exchange() due to the TikTok response format.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
 
@@ -42,16 +42,13 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	if p.config.RedirectURL != "" {
 		v.Set("redirect_uri", p.config.RedirectURL)
 	}
-
-	req, err := http.NewRequest(http.MethodPost, endpointToken, nil)
-	if err != nil {
-		return "", err
+	if s.CodeVerifier != "" {
+		v.Set("code_verifier", s.CodeVerifier)
 	}
-	v.Add("client_key", p.config.ClientID)
+
 	v.Add("client_secret", p.config.ClientSecret)
 
-	req.URL.RawQuery = v.Encode()
-	response, err := p.GetClient().Do(req)
+	response, err := p.tokenClient(p.config.Endpoint.TokenURL).PostForm(p.config.Endpoint.TokenURL, v)
 	if err != nil {
 		return "", err
 	}
@@ -86,10 +83,11 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 
 	// Create and Bind the Access Token
 	s.AccessToken = tokenResp.Data.AccessToken
-	s.ExpiresAt = time.Now().UTC().Add(time.Second * time.Duration(tokenResp.Data.ExpiresIn))
+	s.ExpiresAt = goth.ExpiresIn(tokenResp.Data.ExpiresIn).UTC()
 	s.OpenID = tokenResp.Data.OpenID
 	s.RefreshToken = tokenResp.Data.RefreshToken
-	s.RefreshExpiresAt = time.Now().UTC().Add(time.Second * time.Duration(tokenResp.Data.RefreshExpiresIn))
+	s.RefreshExpiresAt = goth.ExpiresIn(tokenResp.Data.RefreshExpiresIn).UTC()
+	s.CodeVerifier = ""
 	return s.AccessToken, nil
 }
 
@@ -11,7 +11,6 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"time"
 
 	"github.com/bgdsh/goth"
 	"golang.org/x/oauth2"
@@ -35,6 +34,7 @@ type Provider struct {
 	Client       *http.Client
 	ClientKey    string
 	ClientSecret string
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -65,6 +65,29 @@ func (p *Provider) GetClient() *http.Client {
 	return goth.HTTPClientWithFallBack(p.Client)
 }
 
+// tokenClient returns the client of the token requests to tokenURL, adding
+// the key of the app as client_key, the name TikTok gives client_id.
+func (p *Provider) tokenClient(tokenURL string) *http.Client {
+	return goth.HTTPClientWithTokenParams(p.GetClient(), tokenURL, url.Values{"client_key": {p.config.ClientID}})
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.Client = bind(p.GetClient())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 //Debug TODO
 func (p *Provider) Debug(debug bool) {}
 
@@ -83,9 +106,18 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 		v.Set("redirect_uri", p.config.RedirectURL)
 	}
 
-	// Note scopes are CSVs
+	verifier := ""
+	if p.PKCE {
+		var err error
+		if verifier, err = goth.NewCodeVerifier(); err != nil {
+			return nil, err
+		}
+		v.Set("code_challenge", goth.CodeChallenge(verifier))
+		v.Set("code_challenge_method", "S256")
+	}
+
 	if len(p.config.Scopes) > 0 {
-		v.Set("scope", strings.Join(p.config.Scopes, ","))
+		v.Set("scope", goth.ScopeFormatOf(p.Name(), goth.CommaSeparatedScopes).Join(p.config.Scopes))
 	}
 
 	if strings.Contains(p.config.Endpoint.AuthURL, "?") {
@@ -95,7 +127,8 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 	}
 	buf.WriteString(v.Encode())
 	return &Session{
-		AuthURL: buf.String(),
+		AuthURL:      buf.String(),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -126,7 +159,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	err = userFromReader(response.Body, &user)
@@ -172,7 +205,8 @@ func newConfig(p *Provider, scopes []string) *oauth2.Config {
 		ClientSecret: p.ClientSecret,
 		RedirectURL:  p.CallbackURL,
 		Endpoint: oauth2.Endpoint{
-			AuthURL: endpointAuth,
+			AuthURL:  endpointAuth,
+			TokenURL: endpointToken,
 		},
 		Scopes: []string{ScopeUserInfoBasic},
 	}
@@ -189,19 +223,12 @@ func newConfig(p *Provider, scopes []string) *oauth2.Config {
 
 // RefreshToken will refresh a TikTok access token.
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
-	req, err := http.NewRequest(http.MethodPost, endpointRefresh, nil)
-	if err != nil {
-		return nil, err
-	}
-
 	// Set up the url params to post to get a new access token from a code
 	v := url.Values{
-		"client_key":    {p.config.ClientID},
 		"grant_type":    {"refresh_token"},
 		"refresh_token": {refreshToken},
 	}
-	req.URL.RawQuery = v.Encode()
-	refreshResponse, err := p.GetClient().Do(req)
+	refreshResponse, err := p.tokenClient(endpointRefresh).PostForm(endpointRefresh, v)
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +264,7 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 		AccessToken:  refresh.Data.AccessToken,
 		TokenType:    "Bearer",
 		RefreshToken: refresh.Data.RefreshToken,
-		Expiry:       time.Now().Add(time.Second * time.Duration(refresh.Data.ExpiresIn)),
+		Expiry:       goth.ExpiresIn(refresh.Data.ExpiresIn),
 	}
 
 	tokenExtra := map[string]interface{}{
@@ -2,6 +2,9 @@ package tiktok_test
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
@@ -53,6 +56,36 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var form url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"open_id":"open-id","scope":"user.info.basic","access_token":"act","expires_in":86400,"refresh_token":"rft","refresh_expires_in":31536000},"message":"success"}`))
+	}))
+	defer ts.Close()
+
+	p, err := goth.Configure(tiktok.New("key", "secret", callbackURL), goth.WithTokenURL(ts.URL))
+	a.NoError(err)
+	session, err := p.BeginAuth("state")
+	a.NoError(err)
+	_, err = session.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+
+	s := session.(*tiktok.Session)
+	a.Equal("act", s.AccessToken)
+	a.Equal("open-id", s.OpenID)
+	a.Equal("rft", s.RefreshToken)
+	a.Equal("key", form.Get("client_key"))
+	a.Equal("secret", form.Get("client_secret"))
+	a.Equal("authorization_code", form.Get("grant_type"))
+	a.Equal("code", form.Get("code"))
+}
+
 func provider() *tiktok.Provider {
 	p := tiktok.New(os.Getenv("TIKTOK_KEY"), os.Getenv("TIKTOK_SECRET"), callbackURL, tiktok.ScopeVideoList)
 	return p
@@ -0,0 +1,101 @@
+package trakt_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/trakt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), traktProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := traktProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := traktProvider().BeginAuth("test_state")
+	s := session.(*trakt.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://trakt.tv/oauth/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	u, err := url.Parse(s.AuthURL)
+	a.NoError(err)
+	sum := sha256.Sum256([]byte(s.CodeVerifier))
+	a.Equal(base64.RawURLEncoding.EncodeToString(sum[:]), u.Query().Get("code_challenge"))
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	var verifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			verifier = r.FormValue("code_verifier")
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":7776000,"scope":"public","created_at":1487889741}`)
+		case "/users/settings":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			a.Equal("2", r.Header.Get("trakt-api-version"))
+			a.Equal("key", r.Header.Get("trakt-api-key"))
+			fmt.Fprint(w, `{"user":{"username":"justin","private":false,"name":"Justin Nemeth","vip":true,"vip_ep":false,"ids":{"slug":"justin","uuid":"b6589fc6ab0dc82cf12099d1c2d40ab994e8410c"},"joined_at":"2010-09-25T17:49:25.000Z","location":"San Diego, CA","about":"Co-founder of trakt.","gender":"male","age":32,"images":{"avatar":{"full":"https://walter.trakt.tv/images/users/000/000/001/avatars/large/cf8b1e1c4e.jpg"}}},"account":{"timezone":"America/Los_Angeles"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	trakt.TokenURL, trakt.ProfileURL = ts.URL+"/oauth/token", ts.URL+"/users/settings"
+	defer func() {
+		trakt.TokenURL, trakt.ProfileURL = "https://api.trakt.tv/oauth/token", "https://api.trakt.tv/users/settings"
+	}()
+	provider := traktProvider()
+
+	session, _ := provider.BeginAuth("state")
+	expected := session.(*trakt.Session).CodeVerifier
+
+	session, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal(expected, verifier)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("b6589fc6ab0dc82cf12099d1c2d40ab994e8410c", user.UserID)
+	a.Equal("Justin Nemeth", user.Name)
+	a.Equal("justin", user.NickName)
+	a.Equal("San Diego, CA", user.Location)
+	a.Equal("refresh", user.RefreshToken)
+}
+
+func traktProvider() *trakt.Provider {
+	return trakt.New("key", "secret", "/foo")
+}
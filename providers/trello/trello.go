@@ -0,0 +1,216 @@
+// Package trello implements the OAuth protocol for authenticating users through Trello.
+// Reference: https://developer.atlassian.com/cloud/trello/guides/rest-api/authorization/
+package trello
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"github.com/mrjones/oauth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	requestURL      = "https://trello.com/1/OAuthGetRequestToken"
+	authorizeURL    = "https://trello.com/1/OAuthAuthorizeToken"
+	tokenURL        = "https://trello.com/1/OAuthGetAccessToken"
+	endpointProfile = "https://api.trello.com/1/members/me"
+)
+
+// Scopes of the Trello API, ScopeRead is requested when no scopes are given.
+// ScopeAccount is needed to read the email address of the user.
+const (
+	ScopeRead    = "read"
+	ScopeWrite   = "write"
+	ScopeAccount = "account"
+)
+
+// Expirations of the tokens granted by Trello.
+const (
+	ExpirationOneHour    = "1hour"
+	ExpirationOneDay     = "1day"
+	ExpirationThirtyDays = "30days"
+	ExpirationNever      = "never"
+)
+
+// New creates a new Trello provider, and sets up important connection details.
+// You should always call `trello.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeRead}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "trello",
+	}
+	p.consumer = newConsumer(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Trello.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+
+	// AppName is the name shown to the user on the authorization page.
+	AppName string
+	// Expiration is how long the token is valid, Trello defaults to 30 days.
+	Expiration string
+
+	debug        bool
+	consumer     *oauth.Consumer
+	providerName string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	consumer := *p.consumer
+	consumer.HttpClient = goth.ClientFunc(c.Client)
+	c.consumer = &consumer
+	return &c
+}
+
+// Debug sets the logging of the OAuth client to verbose.
+func (p *Provider) Debug(debug bool) {
+	p.debug = debug
+}
+
+// BeginAuth asks Trello for an authentication end-point and a request token for a session.
+// Trello does not support the "state" variable.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	requestToken, authURL, err := p.consumer.GetRequestTokenAndUrl(p.CallbackURL)
+	if err == nil {
+		params := url.Values{}
+		if p.AppName != "" {
+			params.Add("name", p.AppName)
+		}
+		if p.Expiration != "" {
+			params.Add("expiration", p.Expiration)
+		}
+		if len(params) > 0 {
+			authURL += "&" + params.Encode()
+		}
+	}
+	session := &Session{
+		AuthURL:      authURL,
+		RequestToken: requestToken,
+	}
+	return session, err
+}
+
+// FetchUser will go to Trello and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		Provider: p.Name(),
+	}
+
+	if sess.AccessToken == nil {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	response, err := p.consumer.Get(
+		endpointProfile,
+		map[string]string{"fields": "id,username,fullName,email,avatarUrl,bio,url"},
+		sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	u := struct {
+		ID        string `json:"id"`
+		Username  string `json:"username"`
+		FullName  string `json:"fullName"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatarUrl"`
+		Bio       string `json:"bio"`
+	}{}
+	if err = json.Unmarshal(bits, &u); err != nil {
+		return user, err
+	}
+
+	user.UserID = u.ID
+	user.NickName = u.Username
+	user.Name = u.FullName
+	user.Email = u.Email
+	user.Description = u.Bio
+	if u.AvatarURL != "" {
+		// avatarUrl is the base of the URLs of the avatar in several sizes.
+		user.AvatarURL = u.AvatarURL + "/170.png"
+	}
+	user.AccessToken = sess.AccessToken.Token
+	user.AccessTokenSecret = sess.AccessToken.Secret
+	return user, err
+}
+
+func newConsumer(provider *Provider, scopes []string) *oauth.Consumer {
+	c := oauth.NewConsumer(
+		provider.ClientKey,
+		provider.Secret,
+		oauth.ServiceProvider{
+			RequestTokenUrl:   requestURL,
+			AuthorizeTokenUrl: authorizeURL,
+			AccessTokenUrl:    tokenURL,
+		})
+	c.AdditionalAuthorizationUrlParams = map[string]string{
+		"scope": goth.CommaSeparatedScopes.Join(scopes),
+	}
+
+	c.HttpClient = goth.ClientFunc(provider.Client)
+	c.Debug(provider.debug)
+	return c
+}
+
+// RefreshToken refresh token is not provided by Trello
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Trello")
+}
+
+// RefreshTokenAvailable refresh token is not provided by Trello
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
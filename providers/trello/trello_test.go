@@ -0,0 +1,101 @@
+package trello
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+	"github.com/mrjones/oauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := trelloProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), trelloProvider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := trelloProvider()
+	session, err := provider.BeginAuth("state")
+	s := session.(*Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "OAuthAuthorizeToken?")
+	a.Contains(s.AuthURL, "oauth_token=TOKEN")
+	a.Contains(s.AuthURL, "scope=read")
+	a.NotContains(s.AuthURL, "expiration=")
+	a.Equal("TOKEN", s.RequestToken.Token)
+	a.Equal("SECRET", s.RequestToken.Secret)
+
+	provider = New("key", "secret", "/foo", ScopeRead, ScopeAccount)
+	provider.AppName = "My App"
+	provider.Expiration = ExpirationNever
+	session, err = provider.BeginAuth("state")
+	s = session.(*Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "scope=read%2Caccount")
+	a.Contains(s.AuthURL, "name=My+App")
+	a.Contains(s.AuthURL, "expiration=never")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := trelloProvider()
+	session := Session{AccessToken: &oauth.AccessToken{Token: "TOKEN", Secret: "SECRET"}}
+
+	user, err := provider.FetchUser(&session)
+	a.NoError(err)
+
+	a.Equal("5abbe4b7ddc1b351ef961414", user.UserID)
+	a.Equal("bobtester", user.NickName)
+	a.Equal("Bob Tester", user.Name)
+	a.Equal("bob@example.com", user.Email)
+	a.Equal("https://trello-members.s3.amazonaws.com/5abbe4b7ddc1b351ef961414/abc/170.png", user.AvatarURL)
+	a.Equal("TOKEN", user.AccessToken)
+	a.Equal("SECRET", user.AccessTokenSecret)
+}
+
+func trelloProvider() *Provider {
+	return New("key", "secret", "/foo")
+}
+
+func init() {
+	e := echo.New()
+	e.GET("/1/OAuthGetRequestToken", func(c echo.Context) error {
+		fmt.Fprint(c.Response(), "oauth_token=TOKEN&oauth_token_secret=SECRET")
+		return nil
+	})
+	e.GET("/1/members/me", func(c echo.Context) error {
+		data := map[string]string{
+			"id":        "5abbe4b7ddc1b351ef961414",
+			"username":  "bobtester",
+			"fullName":  "Bob Tester",
+			"email":     "bob@example.com",
+			"avatarUrl": "https://trello-members.s3.amazonaws.com/5abbe4b7ddc1b351ef961414/abc",
+		}
+		return json.NewEncoder(c.Response()).Encode(&data)
+	})
+	ts := httptest.NewServer(e)
+
+	requestURL = ts.URL + "/1/OAuthGetRequestToken"
+	endpointProfile = ts.URL + "/1/members/me"
+}
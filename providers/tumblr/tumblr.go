@@ -1,11 +1,14 @@
 // Package tumblr implements the OAuth protocol for authenticating users through Tumblr.
 // This package can be used as a reference implementation of an OAuth provider for goth.
+// Reference: https://www.tumblr.com/docs/en/api/v2#authentication
 package tumblr
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/bgdsh/goth"
@@ -69,6 +72,17 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	consumer := *p.consumer
+	consumer.HttpClient = goth.ClientFunc(c.Client)
+	c.consumer = &consumer
+	return &c
+}
+
 // Debug sets the logging of the OAuth client to verbose.
 func (p *Provider) Debug(debug bool) {
 	p.debug = debug
@@ -104,24 +118,59 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
-	if err = json.NewDecoder(response.Body).Decode(&user.RawData); err != nil {
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
 		return user, err
 	}
 
-	res, ok := user.RawData["response"].(map[string]interface{})
-	if !ok {
-		return user, errors.New("could not decode response")
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
 	}
-	resUser, ok := res["user"].(map[string]interface{})
-	if !ok {
+
+	u := struct {
+		Response struct {
+			User struct {
+				Name  string `json:"name"`
+				Blogs []struct {
+					Name        string `json:"name"`
+					Title       string `json:"title"`
+					Description string `json:"description"`
+					UUID        string `json:"uuid"`
+					Primary     bool   `json:"primary"`
+					Avatar      []struct {
+						URL string `json:"url"`
+					} `json:"avatar"`
+				} `json:"blogs"`
+			} `json:"user"`
+		} `json:"response"`
+	}{}
+	if err = json.Unmarshal(bits, &u); err != nil {
+		return user, err
+	}
+	if u.Response.User.Name == "" {
 		return user, errors.New("could not decode user")
 	}
 
-	user.Name = resUser["name"].(string)
-	user.NickName = resUser["name"].(string)
+	// Tumblr users have no id of their own, their name is unique and the
+	// uuid of their primary blog stable.
+	user.UserID = u.Response.User.Name
+	user.Name = u.Response.User.Name
+	user.NickName = u.Response.User.Name
+	for _, blog := range u.Response.User.Blogs {
+		if !blog.Primary {
+			continue
+		}
+		user.UserID = blog.UUID
+		user.Description = blog.Description
+		if len(blog.Avatar) > 0 {
+			// The avatars are listed from the largest.
+			user.AvatarURL = blog.Avatar[0].URL
+		}
+	}
 	user.AccessToken = sess.AccessToken.Token
 	user.AccessTokenSecret = sess.AccessToken.Secret
 	return user, err
@@ -137,6 +186,7 @@ func newConsumer(provider *Provider, authURL string) *oauth.Consumer {
 			AccessTokenUrl:    tokenURL,
 		})
 
+	c.HttpClient = goth.ClientFunc(provider.Client)
 	c.Debug(provider.debug)
 	return c
 }
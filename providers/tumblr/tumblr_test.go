@@ -0,0 +1,80 @@
+package tumblr
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/labstack/echo/v4"
+	"github.com/mrjones/oauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := tumblrProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), tumblrProvider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := tumblrProvider().BeginAuth("state")
+	s := session.(*Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "oauth/authorize?")
+	a.Contains(s.AuthURL, "oauth_token=TOKEN")
+	a.Equal("TOKEN", s.RequestToken.Token)
+	a.Equal("SECRET", s.RequestToken.Secret)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := tumblrProvider()
+	session := Session{AccessToken: &oauth.AccessToken{Token: "TOKEN", Secret: "SECRET"}}
+
+	user, err := provider.FetchUser(&session)
+	a.NoError(err)
+
+	a.Equal("t:hpP9ZbQ2KaUidZ1xWbLlww", user.UserID)
+	a.Equal("derekg", user.NickName)
+	a.Equal("Hello", user.Description)
+	a.Equal("https://64.media.tumblr.com/avatar_512.png", user.AvatarURL)
+	a.Equal("TOKEN", user.AccessToken)
+	a.Equal("SECRET", user.AccessTokenSecret)
+}
+
+func tumblrProvider() *Provider {
+	return New("key", "secret", "/foo")
+}
+
+func init() {
+	e := echo.New()
+	e.GET("/oauth/request_token", func(c echo.Context) error {
+		fmt.Fprint(c.Response(), "oauth_token=TOKEN&oauth_token_secret=SECRET")
+		return nil
+	})
+	e.GET("/v2/user/info", func(c echo.Context) error {
+		fmt.Fprint(c.Response(), `{"meta":{"status":200,"msg":"OK"},"response":{"user":{"following":263,"default_post_format":"html","name":"derekg","likes":606,"blogs":[{"name":"derekg","title":"Derek Gottfrid","url":"https://derekg.org/","uuid":"t:hpP9ZbQ2KaUidZ1xWbLlww","primary":true,"description":"Hello","avatar":[{"width":512,"height":512,"url":"https://64.media.tumblr.com/avatar_512.png"},{"width":64,"height":64,"url":"https://64.media.tumblr.com/avatar_64.png"}]},{"name":"ihatehipstrz","title":"I Hate Hipstrz","uuid":"t:other","primary":false}]}}}`)
+		return nil
+	})
+	ts := httptest.NewServer(e)
+
+	requestURL = ts.URL + "/oauth/request_token"
+	endpointProfile = ts.URL + "/v2/user/info"
+}
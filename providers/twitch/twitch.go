@@ -17,6 +17,7 @@ import (
 const (
 	authURL      string = "https://id.twitch.tv/oauth2/authorize"
 	tokenURL     string = "https://id.twitch.tv/oauth2/token"
+	revokeURL    string = "https://id.twitch.tv/oauth2/revoke"
 	userEndpoint string = "https://api.twitch.tv/helix/users"
 )
 
@@ -169,6 +170,7 @@ type Provider struct {
 	Secret       string
 	CallbackURL  string
 	HTTPClient   *http.Client
+	PKCE         bool
 	config       *oauth2.Config
 	providerName string
 }
@@ -188,14 +190,36 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
 // Debug is no-op for the Twitch package.
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Twitch for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state, pkce...)
 	s := &Session{
-		AuthURL: url,
+		AuthURL:      url,
+		CodeVerifier: verifier,
 	}
 	return s, nil
 }
@@ -230,7 +254,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
 	}
 
 	err = userFromReader(resp.Body, &user)
@@ -305,3 +329,26 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	}
 	return newToken, err
 }
+
+// RevokeToken revokes an access token. Twitch identifies the client by its ID
+// only.
+func (p *Provider) RevokeToken(token string) error {
+	return goth.Revoke(p.Client(), goth.RevocationRequest{
+		RevocationURL: revokeURL,
+		ClientID:      p.ClientKey,
+		AuthStyle:     oauth2.AuthStyleInParams,
+		Token:         token,
+	})
+}
+
+// ClientCredentialsToken requests an app access token with the client
+// credentials grant.
+func (p *Provider) ClientCredentialsToken(scopes ...string) (*oauth2.Token, error) {
+	return goth.RequestClientCredentials(p.Client(), goth.ClientCredentialsRequest{
+		TokenURL:     p.config.Endpoint.TokenURL,
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		AuthStyle:    oauth2.AuthStyleInParams,
+		Scopes:       scopes,
+	})
+}
@@ -78,6 +78,17 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	consumer := *p.consumer
+	consumer.HttpClient = goth.ClientFunc(c.Client)
+	c.consumer = &consumer
+	return &c
+}
+
 // Debug sets the logging of the OAuth client to verbose.
 func (p *Provider) Debug(debug bool) {
 	p.debug = debug
@@ -116,7 +127,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	bits, err := ioutil.ReadAll(response.Body)
@@ -153,6 +164,7 @@ func newConsumer(provider *Provider, authURL string) *oauth.Consumer {
 			AccessTokenUrl:    tokenURL,
 		})
 
+	c.HttpClient = goth.ClientFunc(provider.Client)
 	c.Debug(provider.debug)
 	return c
 }
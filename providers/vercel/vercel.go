@@ -0,0 +1,202 @@
+// Package vercel implements the OAuth2 protocol for authenticating users through Vercel integrations.
+// Users authorize an integration by installing it on their personal account
+// or on a team, the token is then scoped to the account it was installed on.
+// Reference: https://vercel.com/docs/integrations/create-integration/submit-integration
+package vercel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// AuthURL is the base of the installation URLs of integrations.
+	AuthURL    = "https://vercel.com/integrations"
+	TokenURL   = "https://api.vercel.com/v2/oauth/access_token"
+	ProfileURL = "https://api.vercel.com/v2/user"
+)
+
+// New creates a new Vercel provider, and sets up important connection details.
+// You should always call `vercel.New` to get a new Provider. Never try to create
+// one manually. slug is the URL slug of the integration, the permissions of
+// the token are set in the integration console.
+func New(clientKey, secret, callbackURL, slug string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		Slug:         slug,
+		providerName: "vercel",
+		profileURL:   ProfileURL,
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Vercel.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	Slug         string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the vercel package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Vercel for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Vercel and access basic information about the user.
+// The team the integration was installed on, if any, is kept in RawData as
+// team_id, API calls for the team must pass it as the teamId parameter.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	if err = userFromReader(resp.Body, &user); err != nil {
+		return user, err
+	}
+	user.RawData["team_id"] = sess.TeamID
+	user.RawData["installation_id"] = sess.InstallationID
+	return user, nil
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		User struct {
+			ID       string `json:"id"`
+			Email    string `json:"email"`
+			Name     string `json:"name"`
+			Username string `json:"username"`
+		} `json:"user"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	raw := struct {
+		User map[string]interface{} `json:"user"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw.User == nil {
+		raw.User = map[string]interface{}{}
+	}
+
+	user.RawData = raw.User
+	user.UserID = u.User.ID
+	user.Email = u.User.Email
+	user.Name = u.User.Name
+	user.NickName = u.User.Username
+	return nil
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL + "/" + url.PathEscape(provider.Slug) + "/new",
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// RefreshTokenAvailable refresh token is not provided by Vercel, whose
+// access tokens last as long as the integration is installed.
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Vercel
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Vercel")
+}
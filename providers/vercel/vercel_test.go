@@ -0,0 +1,85 @@
+package vercel_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/vercel"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), vercelProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := vercelProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.Slug, "my-integration")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := vercelProvider().BeginAuth("test_state")
+	s := session.(*vercel.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://vercel.com/integrations/my-integration/new?")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/oauth/access_token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			fmt.Fprint(w, `{"token_type":"Bearer","access_token":"token","installation_id":"icfg_3bwCLgxL8qt5kjRLcv2Dit7F","user_id":"zTuNVUXEAvvnNN3IaqinkyMw","team_id":"team_LLHUOMOoDlqOp8wPE4kFo9pE"}`)
+		case "/v2/user":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"user":{"id":"zTuNVUXEAvvnNN3IaqinkyMw","email":"me@example.com","name":"John Doe","username":"jdoe","avatar":"22cb30c85ff45ac4c72de8981500006b28114aa1"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	vercel.TokenURL, vercel.ProfileURL = ts.URL+"/v2/oauth/access_token", ts.URL+"/v2/user"
+	defer func() {
+		vercel.TokenURL, vercel.ProfileURL = "https://api.vercel.com/v2/oauth/access_token", "https://api.vercel.com/v2/user"
+	}()
+	provider := vercelProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}, "configurationId": {"icfg_3bwCLgxL8qt5kjRLcv2Dit7F"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal("team_LLHUOMOoDlqOp8wPE4kFo9pE", session.(*vercel.Session).TeamID)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("zTuNVUXEAvvnNN3IaqinkyMw", user.UserID)
+	a.Equal("John Doe", user.Name)
+	a.Equal("jdoe", user.NickName)
+	a.Equal("me@example.com", user.Email)
+	a.Equal("team_LLHUOMOoDlqOp8wPE4kFo9pE", user.RawData["team_id"])
+}
+
+func vercelProvider() *vercel.Provider {
+	return vercel.New("key", "secret", "/foo", "my-integration")
+}
@@ -11,10 +11,11 @@ import (
 
 // Session stores data during the auth process with VK.
 type Session struct {
-	AuthURL     string
-	AccessToken string
-	ExpiresAt   time.Time
-	email       string
+	AuthURL      string
+	AccessToken  string
+	ExpiresAt    time.Time
+	email        string
+	CodeVerifier string `json:",omitempty"`
 }
 
 // GetAuthURL returns the URL for the authentication end-point for the provider.
@@ -34,7 +35,7 @@ func (s *Session) Marshal() string {
 // Authorize the session with VK and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), goth.VerifierOptions(s.CodeVerifier)...)
 	if err != nil {
 		return "", err
 	}
@@ -51,6 +52,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.ExpiresAt = token.Expiry
 	s.email = email
+	s.CodeVerifier = ""
 	return s.AccessToken, err
 }
 
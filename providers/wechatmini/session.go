@@ -0,0 +1,53 @@
+package wechatmini
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/bgdsh/goth"
+)
+
+// Session stores data during the auth process with WeChat Mini Programs.
+type Session struct {
+	OpenID     string
+	UnionID    string
+	SessionKey string
+}
+
+// GetAuthURL always fails: Mini Programs get their code from wx.login rather
+// than by redirecting users to WeChat.
+func (s Session) GetAuthURL() (string, error) {
+	return "", errors.New(goth.NoAuthUrlErrorMessage)
+}
+
+// Authorize exchanges the code returned by wx.login for the user's openid and
+// session key, and returns the session key.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	code := params.Get("code")
+	if code == "" {
+		return "", errors.New("wechatmini: no code to authorize")
+	}
+
+	sess, err := p.code2Session(code)
+	if err != nil {
+		return "", err
+	}
+	*s = *sess
+
+	return s.SessionKey, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	sess := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(sess)
+	return sess, err
+}
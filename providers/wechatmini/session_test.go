@@ -0,0 +1,48 @@
+package wechatmini_test
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/wechatmini"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Session(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &wechatmini.Session{}
+
+	a.Implements((*goth.Session)(nil), s)
+}
+
+func Test_GetAuthURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &wechatmini.Session{}
+
+	_, err := s.GetAuthURL()
+	a.Error(err)
+}
+
+func Test_Marshal(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &wechatmini.Session{}
+
+	data := s.Marshal()
+	a.Equal(data, `{"OpenID":"","UnionID":"","SessionKey":""}`)
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := wechatmini.New("wx0123456789", "secret")
+	s, err := provider.UnmarshalSession(`{"OpenID":"o1","UnionID":"u1","SessionKey":"key"}`)
+	a.NoError(err)
+	session := s.(*wechatmini.Session)
+	a.Equal(session.OpenID, "o1")
+	a.Equal(session.UnionID, "u1")
+	a.Equal(session.SessionKey, "key")
+}
@@ -0,0 +1,210 @@
+// Package wechatmini implements the code2Session flow for authenticating users of WeChat Mini Programs.
+// Reference: https://developers.weixin.qq.com/miniprogram/en/dev/api-backend/open-api/login/auth.code2Session.html
+//
+// Mini Programs do not redirect users to an authorization page: the Mini Program calls wx.login and
+// sends the code it gets to the server, which authorizes a session with it:
+//
+//	sess, _ := provider.BeginAuth("")
+//	_, err := sess.Authorize(provider, url.Values{"code": {code}})
+//	user, err := provider.FetchUser(sess)
+package wechatmini
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	BaseURL = "https://api.weixin.qq.com"
+)
+
+// New creates a new WeChat Mini Program provider, and sets up important connection details.
+func New(appID, secret string) *Provider {
+	return &Provider{
+		ClientKey:    appID,
+		Secret:       secret,
+		providerName: "wechatmini",
+		baseURL:      BaseURL,
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for accessing WeChat Mini Programs.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	HTTPClient   *http.Client
+	providerName string
+
+	baseURL string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the wechatmini package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth returns an empty session. Mini Programs get their code from wx.login,
+// so there is no authentication end-point to send users to.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{}, nil
+}
+
+// FetchUser returns the user identified by code2Session. WeChat has no user
+// information end-point for Mini Programs, profile and phone data are sent by
+// the Mini Program encrypted with the session key, see DecryptData.
+//
+// The session key is returned as the user's AccessToken. It must stay on the
+// server: never send it back to the Mini Program.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.SessionKey,
+		Provider:    p.Name(),
+		UserID:      sess.OpenID,
+	}
+
+	if user.AccessToken == "" {
+		return user, fmt.Errorf("%s cannot get user information without session_key", p.providerName)
+	}
+
+	user.RawData = map[string]interface{}{
+		"openid": sess.OpenID,
+	}
+	if sess.UnionID != "" {
+		user.RawData["unionid"] = sess.UnionID
+	}
+
+	return user, nil
+}
+
+// RefreshToken refresh token is not provided by WeChat Mini Programs
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("refresh token is not provided by wechatmini")
+}
+
+// RefreshTokenAvailable refresh token is not provided by WeChat Mini Programs
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+func (p *Provider) code2Session(code string) (*Session, error) {
+	params := url.Values{}
+	params.Add("appid", p.ClientKey)
+	params.Add("secret", p.Secret)
+	params.Add("js_code", code)
+	params.Add("grant_type", "authorization_code")
+	resp, err := p.Client().Get(fmt.Sprintf("%s/sns/jscode2session?%s", p.baseURL, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("wechatmini /sns/jscode2session returns code: %d", resp.StatusCode)
+	}
+
+	obj := struct {
+		OpenID     string `json:"openid"`
+		UnionID    string `json:"unionid"`
+		SessionKey string `json:"session_key"`
+		Code       int    `json:"errcode"`
+		Msg        string `json:"errmsg"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, err
+	}
+	if obj.Code != 0 {
+		return nil, fmt.Errorf("CODE: %d, MSG: %s", obj.Code, obj.Msg)
+	}
+
+	return &Session{
+		OpenID:     obj.OpenID,
+		UnionID:    obj.UnionID,
+		SessionKey: obj.SessionKey,
+	}, nil
+}
+
+// DecryptData decrypts the encryptedData and iv sent by a Mini Program, for
+// example by wx.getUserProfile, with the session key of the user. The
+// decrypted data is rejected unless its watermark names this Mini Program.
+func (p *Provider) DecryptData(sessionKey, encryptedData, iv string) (map[string]interface{}, error) {
+	key, err := base64.StdEncoding.DecodeString(sessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("wechatmini: invalid session key: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("wechatmini: invalid encrypted data: %v", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(iv)
+	if err != nil {
+		return nil, fmt.Errorf("wechatmini: invalid iv: %v", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("wechatmini: invalid session key: %v", err)
+	}
+	if len(nonce) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, errors.New("wechatmini: encrypted data or iv has an invalid length")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, nonce).CryptBlocks(plain, data)
+
+	plain, err = unpad(plain, block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := map[string]interface{}{}
+	if err := json.Unmarshal(plain, &decrypted); err != nil {
+		return nil, fmt.Errorf("wechatmini: could not decrypt data: %v", err)
+	}
+	watermark, _ := decrypted["watermark"].(map[string]interface{})
+	if appID, _ := watermark["appid"].(string); appID != p.ClientKey {
+		return nil, errors.New("wechatmini: decrypted data belongs to another Mini Program")
+	}
+	return decrypted, nil
+}
+
+// unpad removes the PKCS#7 padding of b.
+func unpad(b []byte, blockSize int) ([]byte, error) {
+	n := int(b[len(b)-1])
+	if n == 0 || n > blockSize || n > len(b) {
+		return nil, errors.New("wechatmini: could not decrypt data: invalid padding")
+	}
+	for _, c := range b[len(b)-n:] {
+		if int(c) != n {
+			return nil, errors.New("wechatmini: could not decrypt data: invalid padding")
+		}
+	}
+	return b[:len(b)-n], nil
+}
@@ -0,0 +1,103 @@
+package wechatmini_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/wechatmini"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), wechatminiProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := wechatminiProvider()
+	a.Equal(provider.ClientKey, "wx0123456789")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.Name(), "wechatmini")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/sns/jscode2session", r.URL.Path)
+		a.Equal("wx0123456789", r.URL.Query().Get("appid"))
+		if r.URL.Query().Get("js_code") != "good" {
+			fmt.Fprint(w, `{"errcode":40029,"errmsg":"invalid code"}`)
+			return
+		}
+		fmt.Fprint(w, `{"openid":"o1","unionid":"u1","session_key":"key"}`)
+	}))
+	defer ts.Close()
+
+	wechatmini.BaseURL = ts.URL
+	defer func() { wechatmini.BaseURL = "https://api.weixin.qq.com" }()
+	provider := wechatminiProvider()
+
+	session, err := provider.BeginAuth("")
+	a.NoError(err)
+	token, err := session.Authorize(provider, url.Values{"code": {"good"}})
+	a.NoError(err)
+	a.Equal("key", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("o1", user.UserID)
+	a.Equal("u1", user.RawData["unionid"])
+	a.Equal("key", user.AccessToken)
+
+	session, _ = provider.BeginAuth("")
+	_, err = session.Authorize(provider, url.Values{"code": {"bad"}})
+	a.EqualError(err, "CODE: 40029, MSG: invalid code")
+}
+
+func Test_DecryptData(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := wechatminiProvider()
+	key := bytes.Repeat([]byte{1}, 16)
+	iv := bytes.Repeat([]byte{2}, 16)
+
+	encrypted := encrypt(key, iv, `{"nickName":"Homer","watermark":{"appid":"wx0123456789","timestamp":1}}`)
+	data, err := provider.DecryptData(base64.StdEncoding.EncodeToString(key), encrypted, base64.StdEncoding.EncodeToString(iv))
+	a.NoError(err)
+	a.Equal("Homer", data["nickName"])
+
+	encrypted = encrypt(key, iv, `{"nickName":"Homer","watermark":{"appid":"wx-other"}}`)
+	_, err = provider.DecryptData(base64.StdEncoding.EncodeToString(key), encrypted, base64.StdEncoding.EncodeToString(iv))
+	a.Error(err)
+
+	wrong := bytes.Repeat([]byte{3}, 16)
+	_, err = provider.DecryptData(base64.StdEncoding.EncodeToString(wrong), encrypted, base64.StdEncoding.EncodeToString(iv))
+	a.Error(err)
+}
+
+func encrypt(key, iv []byte, plain string) string {
+	block, _ := aes.NewCipher(key)
+	n := block.BlockSize() - len(plain)%block.BlockSize()
+	data := append([]byte(plain), bytes.Repeat([]byte{byte(n)}, n)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func wechatminiProvider() *wechatmini.Provider {
+	return wechatmini.New("wx0123456789", "secret")
+}
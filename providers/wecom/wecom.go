@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/bgdsh/goth"
@@ -30,6 +31,7 @@ func New(corpID, secret, agentID, callbackURL string) *Provider {
 		providerName: "wecom",
 		authURL:      AuthURL,
 		baseURL:      BaseURL,
+		cache:        &tokenCache{},
 	}
 }
 
@@ -42,8 +44,9 @@ type Provider struct {
 	HTTPClient   *http.Client
 	providerName string
 
-	// token caches the access_token
-	token *oauth2.Token
+	// cache holds the access_token. It is shared by every login handled by
+	// this provider, and by the copies returned by BindClient.
+	cache *tokenCache
 
 	authURL string
 	baseURL string
@@ -63,6 +66,14 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
 // Debug is a no-op for the wecom package.
 func (p *Provider) Debug(debug bool) {}
 
@@ -120,9 +131,18 @@ func (p *Provider) RefreshTokenAvailable() bool {
 	return false
 }
 
+// tokenCache caches the access_token used to call the WeCom API.
+type tokenCache struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
 func (p *Provider) fetchToken() (*oauth2.Token, error) {
-	if p.token != nil && p.token.Valid() {
-		return p.token, nil
+	p.cache.mu.Lock()
+	defer p.cache.mu.Unlock()
+
+	if p.cache.token != nil && p.cache.token.Valid() {
+		return p.cache.token, nil
 	}
 
 	params := url.Values{}
@@ -150,12 +170,12 @@ func (p *Provider) fetchToken() (*oauth2.Token, error) {
 		return nil, fmt.Errorf("CODE: %d, MSG: %s", obj.Code, obj.Msg)
 	}
 
-	p.token = &oauth2.Token{
+	p.cache.token = &oauth2.Token{
 		AccessToken: obj.AccessToken,
-		Expiry:      time.Now().Add(obj.ExpiresIn * time.Second),
+		Expiry:      goth.Now().Add(obj.ExpiresIn * time.Second),
 	}
 
-	return p.token, nil
+	return p.cache.token, nil
 }
 
 func (p *Provider) fetchUserID(session goth.Session, code string) (string, error) {
@@ -0,0 +1,85 @@
+package weibo_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/weibo"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), weiboProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := weiboProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := weiboProvider()
+	session, err := provider.BeginAuth("test_state")
+	s := session.(*weibo.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.weibo.com/oauth2/authorize")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth2/access_token":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			fmt.Fprint(w, `{"access_token":"token","expires_in":157679999,"remind_in":"157679999","uid":"1404376560"}`)
+		case "/2/users/show.json":
+			a.Equal("1404376560", r.URL.Query().Get("uid"))
+			fmt.Fprint(w, `{"id":1404376560,"idstr":"1404376560","screen_name":"zaku","name":"zaku","location":"北京 朝阳区","profile_image_url":"http://tp1.sinaimg.cn/50","avatar_large":"http://tp1.sinaimg.cn/180"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	weibo.TokenURL, weibo.ProfileURL = ts.URL+"/oauth2/access_token", ts.URL+"/2/users/show.json"
+	defer func() {
+		weibo.TokenURL, weibo.ProfileURL = "https://api.weibo.com/oauth2/access_token", "https://api.weibo.com/2/users/show.json"
+	}()
+	provider := weiboProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("1404376560", user.UserID)
+	a.Equal("zaku", user.NickName)
+	a.Equal("北京 朝阳区", user.Location)
+	a.Equal("http://tp1.sinaimg.cn/180", user.AvatarURL)
+}
+
+func weiboProvider() *weibo.Provider {
+	return weibo.New("key", "secret", "/foo")
+}
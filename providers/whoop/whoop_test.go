@@ -0,0 +1,97 @@
+package whoop_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/whoop"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), whoopProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := whoopProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := whoop.New("key", "secret", "/foo", whoop.ScopeReadProfile, whoop.ScopeOffline, whoop.ScopeReadSleep).BeginAuth("test_state")
+	s := session.(*whoop.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.prod.whoop.com/oauth/oauth2/auth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=offline+read%3Aprofile+read%3Asleep&")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+			if r.PostForm.Get("grant_type") == "refresh_token" {
+				a.Equal("refresh", r.PostForm.Get("refresh_token"))
+				a.Equal("offline", r.PostForm.Get("scope"))
+				fmt.Fprint(w, `{"access_token":"token2","refresh_token":"refresh2","token_type":"bearer","expires_in":3600,"scope":"offline read:profile"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","token_type":"bearer","expires_in":3600,"scope":"offline read:profile"}`)
+		case "/profile":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"user_id":10129,"email":"jsmith123@whoop.com","first_name":"John","last_name":"Smith"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	whoop.TokenURL, whoop.ProfileURL = ts.URL+"/token", ts.URL+"/profile"
+	defer func() {
+		whoop.TokenURL, whoop.ProfileURL = "https://api.prod.whoop.com/oauth/oauth2/token", "https://api.prod.whoop.com/developer/v1/user/profile/basic"
+	}()
+	provider := whoopProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("10129", user.UserID)
+	a.Equal("John Smith", user.Name)
+	a.Equal("jsmith123@whoop.com", user.Email)
+	a.Equal("refresh", user.RefreshToken)
+
+	newToken, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token2", newToken.AccessToken)
+	a.Equal("refresh2", newToken.RefreshToken)
+}
+
+func whoopProvider() *whoop.Provider {
+	return whoop.New("key", "secret", "/foo")
+}
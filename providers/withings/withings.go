@@ -0,0 +1,201 @@
+// Package withings implements the OAuth2 protocol for authenticating users through Withings.
+// The token end-point of Withings is not standard: it expects an
+// action=requesttoken parameter and wraps its answers in a status and body
+// envelope, it is called by the provider instead of golang.org/x/oauth2.
+// Reference: https://developer.withings.com/api-reference#tag/oauth2
+package withings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL  = "https://account.withings.com/oauth2_user/authorize2"
+	TokenURL = "https://wbsapi.withings.net/v2/oauth2"
+)
+
+// Scopes of the Withings API, ScopeUserInfo is requested when no scopes are
+// given.
+const (
+	ScopeUserInfo     = "user.info"
+	ScopeUserMetrics  = "user.metrics"
+	ScopeUserActivity = "user.activity"
+)
+
+// New creates a new Withings provider, and sets up important connection details.
+// You should always call `withings.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeUserInfo}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "withings",
+		tokenURL:     TokenURL,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Withings.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	tokenURL     string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	if options.PKCE {
+		return nil, &goth.OptionNotSupportedError{Provider: p.Name(), Option: "WithPKCE"}
+	}
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	return &c, nil
+}
+
+// Debug is a no-op for the withings package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Withings for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: goth.ScopeFormatOf(p.Name(), goth.CommaSeparatedScopes).AuthCodeURL(p.config, state),
+	}, nil
+}
+
+// FetchUser returns the user the session was authorized by. Withings has no
+// profile end-point, only the id of the user is known.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.UserID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+	return user, nil
+}
+
+// requestToken calls the token end-point with params, and returns the token
+// and the id of the user it was granted by.
+func (p *Provider) requestToken(params url.Values) (*oauth2.Token, string, error) {
+	params.Set("action", "requesttoken")
+	params.Set("client_id", p.ClientKey)
+	params.Set("client_secret", p.Secret)
+
+	resp, err := p.Client().PostForm(p.tokenURL, params)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", goth.NewProviderAPIError(p.providerName, "trying to get a token", resp)
+	}
+
+	t := struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+		Body   struct {
+			UserID       string `json:"userid"`
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+			ExpiresIn    int64  `json:"expires_in"`
+			TokenType    string `json:"token_type"`
+		} `json:"body"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, "", err
+	}
+	// Errors are answered with a 200 too.
+	if t.Status != 0 {
+		return nil, "", fmt.Errorf("%s responded with an error: %d %s", p.providerName, t.Status, t.Error)
+	}
+	if t.Body.AccessToken == "" {
+		return nil, "", errors.New("Invalid token received from provider")
+	}
+	return &oauth2.Token{
+		AccessToken:  t.Body.AccessToken,
+		TokenType:    t.Body.TokenType,
+		RefreshToken: t.Body.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(t.Body.ExpiresIn) * time.Second),
+	}, t.Body.UserID, nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token, _, err := p.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	return token, err
+}
@@ -0,0 +1,94 @@
+package withings_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/withings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), withingsProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := withingsProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := withings.New("key", "secret", "/foo", withings.ScopeUserInfo, withings.ScopeUserMetrics).BeginAuth("test_state")
+	s := session.(*withings.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://account.withings.com/oauth2_user/authorize2")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=user.info%2Cuser.metrics")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		a.NoError(r.ParseForm())
+		a.Equal("requesttoken", r.PostForm.Get("action"))
+		a.Equal("key", r.PostForm.Get("client_id"))
+		a.Equal("secret", r.PostForm.Get("client_secret"))
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			if r.PostForm.Get("code") != "code" {
+				fmt.Fprint(w, `{"status":503,"body":{},"error":"Invalid Params: invalid code"}`)
+				return
+			}
+			a.Equal("/foo", r.PostForm.Get("redirect_uri"))
+			fmt.Fprint(w, `{"status":0,"body":{"userid":"363","access_token":"token","refresh_token":"refresh","expires_in":10800,"scope":"user.info","csrf_token":"csrf","token_type":"Bearer"}}`)
+		case "refresh_token":
+			a.Equal("refresh", r.PostForm.Get("refresh_token"))
+			fmt.Fprint(w, `{"status":0,"body":{"userid":"363","access_token":"token2","refresh_token":"refresh2","expires_in":10800,"scope":"user.info","token_type":"Bearer"}}`)
+		}
+	}))
+	defer ts.Close()
+
+	withings.TokenURL = ts.URL
+	defer func() { withings.TokenURL = "https://wbsapi.withings.net/v2/oauth2" }()
+	provider := withingsProvider()
+
+	session, _ := provider.BeginAuth("state")
+	_, err := session.Authorize(provider, url.Values{"code": {"other"}})
+	a.EqualError(err, "withings responded with an error: 503 Invalid Params: invalid code")
+
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("363", user.UserID)
+	a.Equal("refresh", user.RefreshToken)
+
+	newToken, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token2", newToken.AccessToken)
+	a.Equal("refresh2", newToken.RefreshToken)
+}
+
+func withingsProvider() *withings.Provider {
+	return withings.New("key", "secret", "/foo")
+}
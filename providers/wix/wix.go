@@ -0,0 +1,254 @@
+// Package wix implements the OAuth2 protocol for installing applications on Wix sites.
+// The user is the instance of the application on the site, owned by the
+// user. Wix also gives the signed instance to the pages of the application,
+// as the instance parameter: DecodeInstance verifies and decodes it.
+// Reference: https://dev.wix.com/docs/build-apps/develop-your-app/access/authentication/use-advanced-oauth
+package wix
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL  = "https://www.wix.com/installer/install"
+	TokenURL = "https://www.wixapis.com/oauth/access"
+	APIURL   = "https://www.wixapis.com"
+)
+
+// tokenLifetime is how long the access tokens of Wix are valid, Wix does not
+// tell it.
+const tokenLifetime = 5 * time.Minute
+
+// New creates a new Wix provider, and sets up important connection details.
+// You should always call `wix.New` to get a new Provider. Never try to create
+// one manually. appID is the id of the application, secret its secret key.
+func New(appID, secret, callbackURL string) *Provider {
+	return &Provider{
+		ClientKey:    appID,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "wix",
+		tokenURL:     TokenURL,
+		apiURL:       APIURL,
+	}
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Wix.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	providerName string
+	tokenURL     string
+	apiURL       string
+}
+
+// Instance is the instance of the application on a site, as given to its
+// pages.
+type Instance struct {
+	InstanceID      string   `json:"instanceId"`
+	AppDefID        string   `json:"appDefId"`
+	SignDate        string   `json:"signDate"`
+	UID             string   `json:"uid"`
+	Permissions     string   `json:"permissions"`
+	SiteOwnerID     string   `json:"siteOwnerId"`
+	VendorProductID string   `json:"vendorProductId"`
+	Demo            bool     `json:"demoMode"`
+	Roles           []string `json:"roles"`
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Debug is a no-op for the wix package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth returns the installer of Wix for the application.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: AuthURL + "?" + url.Values{
+			"appId":       {p.ClientKey},
+			"redirectUrl": {p.CallbackURL},
+			"state":       {state},
+		}.Encode(),
+	}, nil
+}
+
+// FetchUser will go to Wix and access the instance of the application, and
+// the site it is installed on.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.apiURL+"/apps/v1/instance", nil)
+	if err != nil {
+		return user, err
+	}
+	// The tokens of Wix are not bearer tokens.
+	req.Header.Add("Authorization", sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		Instance struct {
+			InstanceID string `json:"instanceId"`
+		} `json:"instance"`
+		Site struct {
+			SiteDisplayName string `json:"siteDisplayName"`
+			OwnerEmail      string `json:"ownerEmail"`
+			URL             string `json:"url"`
+		} `json:"site"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.Instance.InstanceID
+	user.Name = u.Site.SiteDisplayName
+	user.Email = u.Site.OwnerEmail
+	return nil
+}
+
+// DecodeInstance verifies the signature of instance, the parameter Wix gives
+// to the pages of the application, and returns the instance.
+func (p *Provider) DecodeInstance(instance string) (*Instance, error) {
+	parts := strings.SplitN(instance, ".", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("wix: malformed instance")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, []byte(p.Secret))
+	mac.Write([]byte(parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("wix: invalid instance signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, err
+	}
+	i := &Instance{}
+	if err := json.Unmarshal(payload, i); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// token calls the token end-point with params, as JSON.
+func (p *Provider) token(params map[string]string) (*oauth2.Token, error) {
+	params["client_id"] = p.ClientKey
+	params["client_secret"] = p.Secret
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Client().Post(p.tokenURL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, goth.NewProviderAPIError(p.providerName, "trying to get a token", resp)
+	}
+
+	t := struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+	return &oauth2.Token{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		Expiry:       time.Now().Add(tokenLifetime),
+	}, nil
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.token(map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
@@ -0,0 +1,123 @@
+package wix_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/wix"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), wixProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := wixProvider()
+	a.Equal(provider.ClientKey, "app-id")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := wixProvider().BeginAuth("test_state")
+	s := session.(*wix.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.wix.com/installer/install?")
+	a.Contains(s.AuthURL, "appId=app-id")
+	a.Contains(s.AuthURL, "redirectUrl=%2Ffoo")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/access":
+			body := map[string]string{}
+			a.NoError(json.NewDecoder(r.Body).Decode(&body))
+			a.Equal("app-id", body["client_id"])
+			a.Equal("secret", body["client_secret"])
+			if body["grant_type"] == "refresh_token" {
+				a.Equal("refresh", body["refresh_token"])
+				fmt.Fprint(w, `{"access_token":"token2","refresh_token":"refresh2"}`)
+				return
+			}
+			a.Equal("code", body["code"])
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh"}`)
+		case "/apps/v1/instance":
+			a.Equal("token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"instance":{"instanceId":"9f9c5c16-59e8-4be0-b3ea-bdb7ee0cc9b0","appName":"My App","appVersion":"1.0.0","isFree":true},"site":{"siteDisplayName":"My Store","locale":"en","ownerEmail":"owner@example.com","url":"https://example.wixsite.com/store","siteId":"1b2b3c"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	wix.TokenURL, wix.APIURL = ts.URL+"/oauth/access", ts.URL
+	defer func() {
+		wix.TokenURL, wix.APIURL = "https://www.wixapis.com/oauth/access", "https://www.wixapis.com"
+	}()
+	provider := wixProvider()
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}, "instanceId": {"9f9c5c16-59e8-4be0-b3ea-bdb7ee0cc9b0"}})
+	a.NoError(err)
+	a.Equal("token", token)
+	a.Equal("9f9c5c16-59e8-4be0-b3ea-bdb7ee0cc9b0", session.(*wix.Session).InstanceID)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("9f9c5c16-59e8-4be0-b3ea-bdb7ee0cc9b0", user.UserID)
+	a.Equal("My Store", user.Name)
+	a.Equal("owner@example.com", user.Email)
+
+	newToken, err := provider.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("token2", newToken.AccessToken)
+	a.Equal("refresh2", newToken.RefreshToken)
+}
+
+func Test_DecodeInstance(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"instanceId":"9f9c5c16-59e8-4be0-b3ea-bdb7ee0cc9b0","appDefId":"app-id","signDate":"2024-01-01T00:00:00.000Z","uid":"4a5b","permissions":"OWNER","siteOwnerId":"4a5b"}`))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	instance, err := wixProvider().DecodeInstance(signature + "." + payload)
+	a.NoError(err)
+	a.Equal("9f9c5c16-59e8-4be0-b3ea-bdb7ee0cc9b0", instance.InstanceID)
+	a.Equal("OWNER", instance.Permissions)
+	a.Equal("4a5b", instance.SiteOwnerID)
+
+	_, err = wix.New("app-id", "other", "/foo").DecodeInstance(signature + "." + payload)
+	a.EqualError(err, "wix: invalid instance signature")
+	_, err = wixProvider().DecodeInstance(payload)
+	a.Error(err)
+}
+
+func wixProvider() *wix.Provider {
+	return wix.New("app-id", "secret", "/foo")
+}
@@ -37,7 +37,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 		return "", err
 	}
 
-	s.AccessTokenExpires = time.Now().UTC().Add(30 * time.Minute)
+	s.AccessTokenExpires = goth.Now().UTC().Add(30 * time.Minute)
 	s.AccessToken = accessToken
 
 	return accessToken.Token, err
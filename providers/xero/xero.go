@@ -108,6 +108,17 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	consumer := *p.consumer
+	consumer.HttpClient = goth.ClientFunc(c.Client)
+	c.consumer = &consumer
+	return &c
+}
+
 // Debug sets the logging of the OAuth client to verbose.
 func (p *Provider) Debug(debug bool) {
 	p.debug = debug
@@ -151,7 +162,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
-		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", response)
 	}
 
 	var apiResponse APIResponse
@@ -190,6 +201,7 @@ func newPublicConsumer(provider *Provider, authURL string) *oauth.Consumer {
 			AccessTokenUrl:    tokenURL},
 	)
 
+	c.HttpClient = goth.ClientFunc(provider.Client)
 	c.Debug(provider.debug)
 
 	accepttype := []string{"application/json"}
@@ -224,6 +236,7 @@ func newPrivateOrPartnerConsumer(provider *Provider, authURL string) *oauth.Cons
 			AccessTokenUrl:    tokenURL},
 	)
 
+	c.HttpClient = goth.ClientFunc(provider.Client)
 	c.Debug(provider.debug)
 
 	accepttype := []string{"application/json"}
@@ -243,7 +256,7 @@ func (p *Provider) RefreshOAuth1Token(session *Session) error {
 		return err
 	}
 	session.AccessToken = newAccessToken
-	session.AccessTokenExpires = time.Now().UTC().Add(30 * time.Minute)
+	session.AccessTokenExpires = goth.Now().UTC().Add(30 * time.Minute)
 	return nil
 }
 
@@ -0,0 +1,224 @@
+// Package zendesk implements the OAuth2 protocol for authenticating agents and
+// end users through Zendesk. Each Zendesk account is served from its own
+// subdomain, the URL of the instance is kept in RawData["instance_url"].
+// Reference: https://developer.zendesk.com/documentation/ticketing/working-with-oauth/using-oauth-authentication-with-your-application/
+package zendesk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+// Scopes of Zendesk, ScopeUsersRead is requested when no scopes are given.
+const (
+	ScopeRead      = "read"
+	ScopeWrite     = "write"
+	ScopeUsersRead = "users:read"
+)
+
+// New creates a new Zendesk provider for the account served from
+// https://<subdomain>.zendesk.com, and sets up important connection details.
+// You should always call `zendesk.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL, subdomain string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, "https://"+subdomain+".zendesk.com", scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but takes the URL of the instance,
+// for accounts served from a host mapped domain.
+func NewCustomisedURL(clientKey, secret, callbackURL, instanceURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeUsersRead}
+	}
+	instanceURL = strings.TrimSuffix(instanceURL, "/")
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		InstanceURL:  instanceURL,
+		providerName: "zendesk",
+		profileURL:   instanceURL + "/api/v2/users/me",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Zendesk.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	InstanceURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+	profileURL   string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the zendesk package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Zendesk for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, pkce...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Zendesk and access basic information about the user.
+// Its role, end-user, agent or admin, is RawData["role"].
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	err = userFromReader(resp.Body, &user)
+	if err == nil {
+		user.RawData["instance_url"] = p.InstanceURL
+	}
+	return user, err
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		User json.RawMessage `json:"user"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+
+	profile := struct {
+		ID     int64  `json:"id"`
+		Name   string `json:"name"`
+		Email  string `json:"email"`
+		Locale string `json:"locale"`
+		Photo  struct {
+			ContentURL string `json:"content_url"`
+		} `json:"photo"`
+	}{}
+	if err := json.Unmarshal(u.User, &profile); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(u.User, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = strconv.FormatInt(profile.ID, 10)
+	user.Name = profile.Name
+	user.Email = profile.Email
+	user.Location = profile.Locale
+	user.AvatarURL = profile.Photo.ContentURL
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.InstanceURL + "/oauth/authorizations/new",
+			TokenURL:  provider.InstanceURL + "/oauth/tokens",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token, it is only
+// issued to OAuth clients with expiring tokens.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,84 @@
+package zendesk_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/zendesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), zendeskProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := zendeskProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+	a.Equal(provider.InstanceURL, "https://acme.zendesk.com")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := zendeskProvider().BeginAuth("test_state")
+	s := session.(*zendesk.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://acme.zendesk.com/oauth/authorizations/new")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=users%3Aread")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/tokens":
+			a.NoError(r.ParseForm())
+			a.Equal("key", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+			fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","scope":"users:read"}`)
+		case "/api/v2/users/me":
+			a.Equal("Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"user":{"id":35436,"name":"Johnny Agent","email":"johnny@example.com","role":"agent","locale":"en-US","photo":{"content_url":"https://acme.zendesk.com/photos/my_funny_profile_pic.png"}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	provider := zendesk.NewCustomisedURL("key", "secret", "/foo", ts.URL+"/")
+
+	session, _ := provider.BeginAuth("state")
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("35436", user.UserID)
+	a.Equal("Johnny Agent", user.Name)
+	a.Equal("johnny@example.com", user.Email)
+	a.Equal("https://acme.zendesk.com/photos/my_funny_profile_pic.png", user.AvatarURL)
+	a.Equal("agent", user.RawData["role"])
+	a.Equal(ts.URL, user.RawData["instance_url"])
+}
+
+func zendeskProvider() *zendesk.Provider {
+	return zendesk.New("key", "secret", "/foo", "acme")
+}
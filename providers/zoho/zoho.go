@@ -0,0 +1,255 @@
+// Package zoho implements the OAuth2 protocol for authenticating users through Zoho.
+// Zoho keeps the accounts of every region in its own data center, users are
+// authenticated by the accounts server of their region, named in the callback,
+// and their data is served from the API domain returned with the token.
+// Reference: https://www.zoho.com/accounts/protocol/oauth/multi-dc.html
+package zoho
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/bgdsh/goth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	AuthURL = "https://accounts.zoho.com/oauth/v2/auth"
+	// AccountsServers are the accounts servers of the Zoho data centers. The
+	// first one, of the US data center, is used when the callback names none.
+	AccountsServers = []string{
+		"https://accounts.zoho.com",
+		"https://accounts.zoho.eu",
+		"https://accounts.zoho.in",
+		"https://accounts.zoho.com.au",
+		"https://accounts.zoho.jp",
+		"https://accounts.zoho.uk",
+		"https://accounts.zohocloud.ca",
+		"https://accounts.zoho.sa",
+		"https://accounts.zoho.com.cn",
+	}
+)
+
+// ScopeProfileRead lets the provider read the profile of the user, it is
+// requested when no scopes are given.
+const ScopeProfileRead = "AaaServer.profile.Read"
+
+// New creates a new Zoho provider, and sets up important connection details.
+// You should always call `zoho.New` to get a new Provider. Never try to create
+// one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeProfileRead}
+	}
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "zoho",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Zoho.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	PKCE         bool
+	config       *oauth2.Config
+	providerName string
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns a pointer to http.Client setting some client fallback.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// BindClient returns a copy of the provider making its HTTP calls with the
+// client returned by bind.
+func (p *Provider) BindClient(bind func(*http.Client) *http.Client) goth.Provider {
+	c := *p
+	c.HTTPClient = bind(p.Client())
+	return &c
+}
+
+// Configure returns a copy of the provider with the scopes, end-points and PKCE
+// of options, see goth.Configure.
+func (p *Provider) Configure(options goth.ProviderOptions) (goth.Provider, error) {
+	c := *p
+	c.config = options.OAuth2Config(p.config)
+	c.PKCE = c.PKCE || options.PKCE
+	return &c, nil
+}
+
+// Debug is a no-op for the zoho package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Zoho for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, pkce, err := goth.NewPKCE(p.PKCE)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]oauth2.AuthCodeOption{oauth2.AccessTypeOffline}, pkce...)
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, opts...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Zoho and access basic information about the user. The
+// accounts server and API domain of the user are kept in RawData as
+// accounts_server and api_domain, get the latter with APIDomain.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	server := sess.AccountsServer
+	if server == "" {
+		server = AccountsServers[0]
+	}
+	req, err := http.NewRequest("GET", server+"/oauth/user/info", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Zoho-oauthtoken "+sess.AccessToken)
+
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, goth.NewProviderAPIError(p.providerName, "trying to fetch user information", resp)
+	}
+
+	if err = userFromReader(resp.Body, &user); err != nil {
+		return user, err
+	}
+	user.RawData["accounts_server"] = server
+	user.RawData["api_domain"] = sess.APIDomain
+	return user, nil
+}
+
+// APIDomain returns the domain serving the Zoho APIs to a user fetched by
+// FetchUser, such as https://www.zohoapis.eu for the users of the EU data
+// center.
+func APIDomain(user goth.User) string {
+	domain, _ := user.RawData["api_domain"].(string)
+	return domain
+}
+
+func userFromReader(reader io.Reader, user *goth.User) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ZUID        json.Number `json:"ZUID"`
+		FirstName   string      `json:"First_Name"`
+		LastName    string      `json:"Last_Name"`
+		DisplayName string      `json:"Display_Name"`
+		Email       string      `json:"Email"`
+	}{}
+	if err := json.Unmarshal(b, &u); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &user.RawData); err != nil {
+		return err
+	}
+
+	user.UserID = u.ZUID.String()
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = u.DisplayName
+	user.Email = u.Email
+	return nil
+}
+
+// accountsServer returns the config exchanging tokens with the accounts
+// server at server, which must be one of AccountsServers: the callback names
+// it in a query parameter anyone can tamper with.
+func (p *Provider) accountsServer(server string) (*oauth2.Config, error) {
+	for _, s := range AccountsServers {
+		if s == server {
+			c := *p.config
+			c.Endpoint.TokenURL = server + "/oauth/v2/token"
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: unknown accounts server %q", p.providerName, server)
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token. Zoho only
+// refreshes tokens on the accounts server of the user, RefreshToken asks the
+// one of the US data center, use RefreshTokenAt for the users of the others.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.RefreshTokenAt(AccountsServers[0], refreshToken)
+}
+
+// RefreshTokenAt get new access token based on the refresh token, from the
+// accounts server of the user, as kept by FetchUser in RawData.
+func (p *Provider) RefreshTokenAt(accountsServer, refreshToken string) (*oauth2.Token, error) {
+	c, err := p.accountsServer(accountsServer)
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := c.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,99 @@
+package zoho_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/zoho"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Implements((*goth.Provider)(nil), zohoProvider())
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := zohoProvider()
+	a.Equal(provider.ClientKey, "key")
+	a.Equal(provider.Secret, "secret")
+	a.Equal(provider.CallbackURL, "/foo")
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := zohoProvider().BeginAuth("test_state")
+	s := session.(*zoho.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://accounts.zoho.com/oauth/v2/auth")
+	a.Contains(s.AuthURL, "client_id=key")
+	a.Contains(s.AuthURL, "scope=AaaServer.profile.Read")
+	a.Contains(s.AuthURL, "access_type=offline")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/v2/token":
+			a.Equal("secret", r.FormValue("client_secret"))
+			if r.FormValue("grant_type") == "refresh_token" {
+				a.Equal("refresh", r.FormValue("refresh_token"))
+				fmt.Fprint(w, `{"access_token":"token2","api_domain":"https://www.zohoapis.eu","token_type":"Bearer","expires_in":3600}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"token","refresh_token":"refresh","api_domain":"https://www.zohoapis.eu","token_type":"Bearer","expires_in":3600}`)
+		case "/oauth/user/info":
+			a.Equal("Zoho-oauthtoken token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"First_Name":"Jane","Email":"jane@example.eu","Last_Name":"Doe","Display_Name":"jane.doe","ZUID":20061234}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	servers := zoho.AccountsServers
+	zoho.AccountsServers = append([]string{}, servers...)
+	zoho.AccountsServers = append(zoho.AccountsServers, ts.URL)
+	defer func() { zoho.AccountsServers = servers }()
+	provider := zohoProvider()
+
+	session, _ := provider.BeginAuth("state")
+	_, err := session.Authorize(provider, url.Values{"code": {"code"}, "accounts-server": {"https://evil.example.com"}})
+	a.EqualError(err, `zoho: unknown accounts server "https://evil.example.com"`)
+
+	token, err := session.Authorize(provider, url.Values{"code": {"code"}, "location": {"eu"}, "accounts-server": {ts.URL}})
+	a.NoError(err)
+	a.Equal("token", token)
+
+	user, err := provider.FetchUser(session)
+	a.NoError(err)
+	a.Equal("20061234", user.UserID)
+	a.Equal("jane.doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("jane@example.eu", user.Email)
+	a.Equal("https://www.zohoapis.eu", zoho.APIDomain(user))
+	a.Equal(ts.URL, user.RawData["accounts_server"])
+
+	newToken, err := provider.RefreshTokenAt(user.RawData["accounts_server"].(string), "refresh")
+	a.NoError(err)
+	a.Equal("token2", newToken.AccessToken)
+}
+
+func zohoProvider() *zoho.Provider {
+	return zoho.New("key", "secret", "/foo")
+}
@@ -0,0 +1,104 @@
+package goth
+
+import (
+	"context"
+	"strings"
+)
+
+// Reasons of a Deprovisioning.
+const (
+	// DeprovisionLogout is the reason of the deprovisionings sent by the
+	// OpenID Connect back-channel logouts of a provider.
+	DeprovisionLogout = "backchannel_logout"
+	// DeprovisionRevoked is the reason of the deprovisionings of users whose
+	// access the provider revoked, as reported by its webhooks.
+	DeprovisionRevoked = "revoked"
+)
+
+// Attributes are the attributes of a user, normalized across providers after
+// the core schema of SCIM, for the just-in-time provisioning of accounts.
+type Attributes struct {
+	Provider string `json:"provider"`
+	// ExternalID is the ID of the user at the provider, User.UserID.
+	ExternalID    string   `json:"externalId"`
+	UserName      string   `json:"userName,omitempty"`
+	DisplayName   string   `json:"displayName,omitempty"`
+	GivenName     string   `json:"givenName,omitempty"`
+	FamilyName    string   `json:"familyName,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	EmailVerified bool     `json:"emailVerified,omitempty"`
+	Groups        []string `json:"groups,omitempty"`
+	// Instance is the instance of the user, for the providers of Instances.
+	Instance string `json:"instance,omitempty"`
+}
+
+// Deprovisioning asks a Provisioner to deprovision the account of a user.
+type Deprovisioning struct {
+	Provider   string
+	ExternalID string
+	// SessionID is the session at the provider ended by a back-channel
+	// logout, when the logout token named one. ExternalID may then be
+	// empty.
+	SessionID string
+	Reason    string
+}
+
+// Provisioner provisions the accounts of the users of enterprise providers in
+// the application. Provision is called on every login, creating the account
+// on the first one and updating it on the next. Deprovision disables it, or
+// ends its sessions, depending on the reason.
+type Provisioner interface {
+	Provision(ctx context.Context, attrs Attributes) error
+	Deprovision(ctx context.Context, d Deprovisioning) error
+}
+
+// AttributesOf returns the attributes of user, reading the username and the
+// groups from the claims of RawData that OpenID Connect, Azure AD and Okta use
+// for them. The email is verified when User.EmailVerified or the
+// email_verified claim of RawData is true.
+func AttributesOf(user User) Attributes {
+	a := Attributes{
+		Provider:    user.Provider,
+		ExternalID:  user.UserID,
+		UserName:    user.NickName,
+		DisplayName: user.Name,
+		GivenName:   user.FirstName,
+		FamilyName:  user.LastName,
+		Email:       strings.ToLower(user.Email),
+	}
+	for _, claim := range []string{"preferred_username", "upn", "login", "username"} {
+		if s, ok := user.RawData[claim].(string); ok && s != "" && a.UserName == "" {
+			a.UserName = s
+		}
+	}
+	if a.UserName == "" {
+		a.UserName = a.Email
+	}
+	if a.DisplayName == "" {
+		a.DisplayName = strings.TrimSpace(a.GivenName + " " + a.FamilyName)
+	}
+	a.EmailVerified = user.EmailVerified || BoolClaim(user.RawData, "email_verified")
+	for _, claim := range []string{"groups", "roles"} {
+		a.Groups = appendStrings(a.Groups, user.RawData[claim])
+	}
+	a.Instance, _ = user.RawData[RawDataInstance].(string)
+	return a
+}
+
+func appendStrings(s []string, value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			s = append(s, v)
+		}
+	case []string:
+		s = append(s, v...)
+	case []interface{}:
+		for _, e := range v {
+			if str, ok := e.(string); ok && str != "" {
+				s = append(s, str)
+			}
+		}
+	}
+	return s
+}
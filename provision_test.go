@@ -0,0 +1,50 @@
+package goth_test
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AttributesOf(t *testing.T) {
+	a := assert.New(t)
+
+	attrs := goth.AttributesOf(goth.User{
+		Provider:  "openid-connect",
+		UserID:    "00u1",
+		Email:     "Homer@Example.com",
+		FirstName: "Homer",
+		LastName:  "Simpson",
+		RawData: map[string]interface{}{
+			"preferred_username": "homer",
+			"email_verified":     true,
+			"groups":             []interface{}{"safety", "union"},
+			"roles":              "inspector",
+		},
+	})
+	a.Equal(goth.Attributes{
+		Provider:      "openid-connect",
+		ExternalID:    "00u1",
+		UserName:      "homer",
+		DisplayName:   "Homer Simpson",
+		GivenName:     "Homer",
+		FamilyName:    "Simpson",
+		Email:         "homer@example.com",
+		EmailVerified: true,
+		Groups:        []string{"safety", "union", "inspector"},
+	}, attrs)
+
+	attrs = goth.AttributesOf(goth.User{
+		Provider: "mastodon",
+		UserID:   "1",
+		Email:    "homer@example.com",
+		RawData:  map[string]interface{}{goth.RawDataInstance: "https://mastodon.social", "email_verified": "false"},
+	})
+	a.Equal("homer@example.com", attrs.UserName)
+	a.Equal("https://mastodon.social", attrs.Instance)
+	a.False(attrs.EmailVerified)
+
+	attrs = goth.AttributesOf(goth.User{Provider: "github", UserID: "1", Email: "homer@example.com", EmailVerified: true})
+	a.True(attrs.EmailVerified)
+}
@@ -0,0 +1,135 @@
+package goth
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Raw returns the value of RawData at path, whose dot-separated elements are
+// the keys of nested objects or the indexes of arrays, such as
+// "emails.0.value". Keys holding dots, such as the namespaced claims of
+// "https://example.com/roles", are looked up as they are first. It reports
+// whether there is a value.
+func (u User) Raw(path string) (interface{}, bool) {
+	if v, ok := u.RawData[path]; ok {
+		return v, v != nil
+	}
+	var v interface{} = u.RawData
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			// The raw data of some providers holds other maps and slices.
+			rv := reflect.ValueOf(v)
+			switch {
+			case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+				e := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()))
+				if !e.IsValid() {
+					return nil, false
+				}
+				v = e.Interface()
+			case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= rv.Len() {
+					return nil, false
+				}
+				v = rv.Index(i).Interface()
+			default:
+				return nil, false
+			}
+		}
+	}
+	return v, v != nil
+}
+
+// RawString returns the string at path of RawData, see Raw, or the text of
+// the number or boolean there. It returns "" for the other values.
+func (u User) RawString(path string) string {
+	v, _ := u.Raw(path)
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// RawBool reports whether the value at path of RawData, see Raw, is true or
+// the string "true", as some providers send their booleans.
+func (u User) RawBool(path string) bool {
+	v, _ := u.Raw(path)
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// RawInt returns the integer at path of RawData, see Raw, which may be a
+// number or a numeric string, such as the IDs some providers send as
+// strings. It returns 0 for the other values.
+func (u User) RawInt(path string) int64 {
+	v, _ := u.Raw(path)
+	switch v := v.(type) {
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return int64(f)
+	case string:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		return i
+	}
+	return 0
+}
+
+// RawTime returns the time at path of RawData, see Raw, which may be an
+// RFC 3339 string or a number of seconds since the Unix epoch, as in the
+// claims of ID tokens. It returns the zero time for the other values.
+func (u User) RawTime(path string) time.Time {
+	v, _ := u.Raw(path)
+	if s, ok := v.(string); ok {
+		t, err := time.Parse(time.RFC3339, s)
+		if err == nil {
+			return t
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return time.Time{}
+		}
+	}
+	if seconds := u.RawInt(path); seconds != 0 {
+		return time.Unix(seconds, 0)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,64 @@
+package goth_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserRaw(t *testing.T) {
+	a := assert.New(t)
+	user := goth.User{}
+	a.NoError(json.Unmarshal([]byte(`{
+		"login": "octocat",
+		"id": 583231,
+		"site_admin": false,
+		"email_verified": "true",
+		"updated_at": "2024-01-02T03:04:05Z",
+		"auth_time": 1700000000,
+		"https://example.com/roles": ["admin"],
+		"emails": [{"value": "octocat@example.com", "primary": true}],
+		"address": {"country": "US", "zip": "94107"},
+		"nothing": null
+	}`), &user.RawData))
+
+	a.Equal("octocat", user.RawString("login"))
+	a.Equal("583231", user.RawString("id"))
+	a.Equal("false", user.RawString("site_admin"))
+	a.Equal("octocat@example.com", user.RawString("emails.0.value"))
+	a.Equal("US", user.RawString("address.country"))
+	a.Equal("", user.RawString("address"))
+	a.Equal("", user.RawString("emails.1.value"))
+	a.Equal("", user.RawString("login.first"))
+
+	a.True(user.RawBool("emails.0.primary"))
+	a.True(user.RawBool("email_verified"))
+	a.False(user.RawBool("site_admin"))
+	a.False(user.RawBool("missing"))
+
+	a.Equal(int64(583231), user.RawInt("id"))
+	a.Equal(int64(94107), user.RawInt("address.zip"))
+	a.Equal(int64(0), user.RawInt("login"))
+
+	a.True(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(user.RawTime("updated_at")))
+	a.True(time.Unix(1700000000, 0).Equal(user.RawTime("auth_time")))
+	a.True(user.RawTime("login").IsZero())
+
+	roles, ok := user.Raw("https://example.com/roles")
+	a.True(ok)
+	a.Equal([]interface{}{"admin"}, roles)
+	_, ok = user.Raw("nothing")
+	a.False(ok)
+	_, ok = goth.User{}.Raw("login")
+	a.False(ok)
+
+	typed := goth.User{RawData: map[string]interface{}{
+		"profile": map[string]string{"name": "Homer"},
+		"ids":     []int64{42},
+	}}
+	a.Equal("Homer", typed.RawString("profile.name"))
+	a.Equal(int64(42), typed.RawInt("ids.0"))
+}
@@ -0,0 +1,68 @@
+package goth
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Redacted is the placeholder written in place of secrets by MarshalRedacted.
+const Redacted = "[REDACTED]"
+
+// RedactedMarshaler can be implemented by a Session whose secrets are not
+// found by the default key based redaction of MarshalRedacted.
+type RedactedMarshaler interface {
+	MarshalRedacted() string
+}
+
+// sensitiveKeys are matched case-insensitively against session JSON keys.
+var sensitiveKeys = []string{"token", "secret", "verifier", "password"}
+
+// MarshalRedacted returns the same representation as Session.Marshal, but with
+// bearer tokens, refresh tokens and other secrets replaced by Redacted. Use it
+// whenever a session is written to a log.
+func MarshalRedacted(s Session) string {
+	if r, ok := s.(RedactedMarshaler); ok {
+		return r.MarshalRedacted()
+	}
+	return RedactJSON(s.Marshal())
+}
+
+// RedactJSON replaces the values of keys that look like secrets (tokens,
+// secrets, verifiers, ...) anywhere in the JSON document. Input that is not a
+// JSON object is redacted entirely.
+func RedactJSON(data string) string {
+	v := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return Redacted
+	}
+	b, _ := json.Marshal(redactValue(v))
+	return string(b)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if isSensitiveKey(k) && val != nil && val != "" {
+				t[k] = Redacted
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = redactValue(val)
+		}
+	}
+	return v
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package goth_test
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MarshalRedacted(t *testing.T) {
+	a := assert.New(t)
+
+	s := &faux.Session{ID: "id", Name: "Homer Simpson", AccessToken: "access"}
+	data := goth.MarshalRedacted(s)
+	a.NotContains(data, "access\"")
+	a.Contains(data, `"AccessToken":"[REDACTED]"`)
+	a.Contains(data, `"Name":"Homer Simpson"`)
+
+	s.AccessToken = ""
+	a.Contains(goth.MarshalRedacted(s), `"AccessToken":""`)
+}
+
+func Test_RedactJSON(t *testing.T) {
+	a := assert.New(t)
+
+	data := goth.RedactJSON(`{"AuthURL":"http://example.com","AccessToken":{"Token":"t","Secret":"s"},"Nested":[{"refresh_token":"r","scope":"read"}]}`)
+	a.Contains(data, `"AccessToken":"[REDACTED]"`)
+	a.Contains(data, `"refresh_token":"[REDACTED]"`)
+	a.Contains(data, `"scope":"read"`)
+	a.Contains(data, `"AuthURL":"http://example.com"`)
+
+	a.Equal(goth.Redacted, goth.RedactJSON("not json"))
+}
@@ -0,0 +1,124 @@
+package goth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// RefreshLeeway is how long before they expire RefreshToken refreshes tokens,
+// so that they do not expire while in use.
+var RefreshLeeway = time.Minute
+
+// RefreshRetries is how many more times RefreshToken tries a refresh that
+// failed with a network error or a 429 or 5xx response, waiting
+// RefreshRetryDelay between the attempts. Rejected refresh tokens are not
+// retried.
+var RefreshRetries = 1
+
+// RefreshRetryDelay is the delay between the attempts of RefreshToken.
+var RefreshRetryDelay = 500 * time.Millisecond
+
+// RefreshNotSupportedError is returned by RefreshToken when the provider does
+// not refresh tokens, or when the session has no refresh token. The user has
+// to log in again.
+type RefreshNotSupportedError struct {
+	Provider string
+	Reason   string
+}
+
+func (e *RefreshNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: cannot refresh the token: %s", e.Provider, e.Reason)
+}
+
+// RefreshToken refreshes the tokens of session when they expire within
+// RefreshLeeway, and stores the new ones in its AccessToken, RefreshToken,
+// ExpiresAt and, when present, IDToken fields. It returns whether the session
+// was refreshed, and then has to be stored again. Sessions whose ExpiresAt is
+// unknown are not refreshed.
+//
+// It returns a *RefreshNotSupportedError when the provider does not refresh
+// tokens, or the session has no refresh token or no such fields. Providers
+// that rotate refresh tokens invalidate the previous one: refresh a session
+// from a single goroutine, see Session.
+func RefreshToken(provider Provider, session Session) (bool, error) {
+	s, ok := sessionTokens(session)
+	if !ok {
+		return false, &RefreshNotSupportedError{Provider: provider.Name(), Reason: "the session holds no tokens"}
+	}
+	expiresAt := s.FieldByName("ExpiresAt").Interface().(time.Time)
+	if expiresAt.IsZero() || expiresAt.After(Now().Add(RefreshLeeway)) {
+		return false, nil
+	}
+	if !provider.RefreshTokenAvailable() {
+		return false, &RefreshNotSupportedError{Provider: provider.Name(), Reason: "the provider does not refresh tokens"}
+	}
+	refreshToken := s.FieldByName("RefreshToken").String()
+	if refreshToken == "" {
+		return false, &RefreshNotSupportedError{Provider: provider.Name(), Reason: "the session has no refresh token"}
+	}
+
+	token, err := provider.RefreshToken(refreshToken)
+	for i := 0; i < RefreshRetries && err != nil && retryable(err); i++ {
+		time.Sleep(RefreshRetryDelay)
+		token, err = provider.RefreshToken(refreshToken)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", provider.Name(), err)
+	}
+	if token == nil || token.AccessToken == "" {
+		return false, fmt.Errorf("%s: the refreshed token is empty", provider.Name())
+	}
+
+	s.FieldByName("AccessToken").SetString(token.AccessToken)
+	if token.RefreshToken != "" {
+		s.FieldByName("RefreshToken").SetString(token.RefreshToken)
+	}
+	s.FieldByName("ExpiresAt").Set(reflect.ValueOf(token.Expiry))
+	if idToken, ok := token.Extra("id_token").(string); ok && idToken != "" {
+		if f := s.FieldByName("IDToken"); f.IsValid() && f.Kind() == reflect.String {
+			f.SetString(idToken)
+		}
+	}
+	return true, nil
+}
+
+// sessionTokens returns the struct of a session pointer, if it has the
+// AccessToken, RefreshToken and ExpiresAt fields of OAuth2 sessions.
+func sessionTokens(session Session) (reflect.Value, bool) {
+	v := reflect.ValueOf(session)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return reflect.Value{}, false
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for name, typ := range map[string]reflect.Type{
+		"AccessToken":  reflect.TypeOf(""),
+		"RefreshToken": reflect.TypeOf(""),
+		"ExpiresAt":    reflect.TypeOf(time.Time{}),
+	} {
+		f := v.FieldByName(name)
+		if !f.IsValid() || f.Type() != typ || !f.CanSet() {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// retryable reports whether a refresh failed for a reason that may not last.
+func retryable(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+		code := retrieveErr.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,118 @@
+package goth_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type refreshingProvider struct {
+	faux.Provider
+	available bool
+	calls     int
+	errs      []error
+}
+
+func (p *refreshingProvider) RefreshTokenAvailable() bool {
+	return p.available
+}
+
+func (p *refreshingProvider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	p.calls++
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return nil, err
+	}
+	token := &oauth2.Token{
+		AccessToken:  "new-access",
+		RefreshToken: "new-refresh",
+		Expiry:       time.Now().Add(time.Hour),
+	}
+	return token.WithExtra(map[string]interface{}{"id_token": "new-id"}), nil
+}
+
+type tokenSession struct {
+	faux.Session
+	RefreshToken string
+	ExpiresAt    time.Time
+	IDToken      string
+}
+
+func expiredSession() *tokenSession {
+	s := &tokenSession{RefreshToken: "refresh", ExpiresAt: time.Now().Add(-time.Minute), IDToken: "id"}
+	s.AccessToken = "access"
+	return s
+}
+
+func Test_RefreshToken(t *testing.T) {
+	a := assert.New(t)
+	p := &refreshingProvider{available: true}
+	s := expiredSession()
+
+	refreshed, err := goth.RefreshToken(p, s)
+	a.NoError(err)
+	a.True(refreshed)
+	a.Equal("new-access", s.AccessToken)
+	a.Equal("new-refresh", s.RefreshToken)
+	a.Equal("new-id", s.IDToken)
+	a.True(s.ExpiresAt.After(time.Now()))
+
+	refreshed, err = goth.RefreshToken(p, s)
+	a.NoError(err)
+	a.False(refreshed, "the token is still valid")
+	a.Equal(1, p.calls)
+}
+
+func Test_RefreshTokenWithinLeeway(t *testing.T) {
+	a := assert.New(t)
+	p := &refreshingProvider{available: true}
+	s := expiredSession()
+	s.ExpiresAt = time.Now().Add(goth.RefreshLeeway / 2)
+
+	refreshed, err := goth.RefreshToken(p, s)
+	a.NoError(err)
+	a.True(refreshed)
+}
+
+func Test_RefreshTokenNotSupported(t *testing.T) {
+	a := assert.New(t)
+	var notSupported *goth.RefreshNotSupportedError
+
+	_, err := goth.RefreshToken(&refreshingProvider{}, expiredSession())
+	a.True(errors.As(err, &notSupported))
+
+	s := expiredSession()
+	s.RefreshToken = ""
+	_, err = goth.RefreshToken(&refreshingProvider{available: true}, s)
+	a.True(errors.As(err, &notSupported))
+
+	_, err = goth.RefreshToken(&refreshingProvider{available: true}, &faux.Session{AccessToken: "access"})
+	a.True(errors.As(err, &notSupported))
+}
+
+func Test_RefreshTokenRetries(t *testing.T) {
+	a := assert.New(t)
+	delay := goth.RefreshRetryDelay
+	goth.RefreshRetryDelay = 0
+	defer func() { goth.RefreshRetryDelay = delay }()
+
+	unavailable := &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+	p := &refreshingProvider{available: true, errs: []error{unavailable}}
+	refreshed, err := goth.RefreshToken(p, expiredSession())
+	a.NoError(err)
+	a.True(refreshed)
+	a.Equal(2, p.calls)
+
+	rejected := &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusBadRequest}}
+	p = &refreshingProvider{available: true, errs: []error{rejected}}
+	_, err = goth.RefreshToken(p, expiredSession())
+	a.Error(err)
+	a.Equal(1, p.calls)
+}
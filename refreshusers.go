@@ -0,0 +1,207 @@
+package goth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// StoredToken is a provider token persisted for a user, such as those of a
+// gothic store.TokenStore.
+type StoredToken struct {
+	Provider          string
+	UserID            string
+	AccessToken       string
+	AccessTokenSecret string
+	RefreshToken      string
+	IDToken           string
+	ExpiresAt         time.Time
+}
+
+// SessionFromToken returns a session of p holding the tokens of t, to refresh
+// them or fetch the user with them outside of a login. It fails for the
+// providers whose sessions do not hold an access token.
+func SessionFromToken(p Provider, t StoredToken) (Session, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	session, err := p.UnmarshalSession(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+	v, ok := sessionTokens(session)
+	if !ok || v.FieldByName("AccessToken").String() != t.AccessToken {
+		return nil, fmt.Errorf("%s: the sessions of the provider do not hold tokens", p.Name())
+	}
+	return session, nil
+}
+
+// tokenOf returns t with the tokens of session, one of SessionFromToken.
+func tokenOf(session Session, t StoredToken) StoredToken {
+	v, _ := sessionTokens(session)
+	t.AccessToken = v.FieldByName("AccessToken").String()
+	t.RefreshToken = v.FieldByName("RefreshToken").String()
+	t.ExpiresAt = v.FieldByName("ExpiresAt").Interface().(time.Time)
+	if f := v.FieldByName("IDToken"); f.IsValid() && f.Kind() == reflect.String {
+		t.IDToken = f.String()
+	}
+	return t
+}
+
+var (
+	// RefreshUsersConcurrency is how many users RefreshUsers refreshes at
+	// once.
+	RefreshUsersConcurrency = 8
+
+	// RefreshUsersRate is how many users of a provider RefreshUsers refreshes
+	// per second, at most, by provider name. The rate of "*" applies to the
+	// providers not listed; providers without a rate are not limited.
+	RefreshUsersRate = map[string]float64{"*": 5}
+)
+
+// RefreshedUser is the outcome of RefreshUsers for one token.
+type RefreshedUser struct {
+	// Token is the token, with the new tokens when they were refreshed, to
+	// be saved again.
+	Token StoredToken
+	// Refreshed reports whether the tokens were refreshed.
+	Refreshed bool
+	User      User
+	Err       error
+}
+
+// RefreshUsers refreshes the tokens that expire, see RefreshToken, and fetches
+// the users again with them, for the jobs keeping the profiles of users
+// current. The users are refreshed RefreshUsersConcurrency at a time, at the
+// rates of RefreshUsersRate, and their outcomes returned in the order of
+// tokens. The tokens not refreshed when ctx is done fail with its error.
+//
+//	for _, r := range goth.RefreshUsers(ctx, tokens) {
+//		if r.Err != nil {
+//			log.Printf("%s/%s: %v", r.Token.Provider, r.Token.UserID, r.Err)
+//			continue
+//		}
+//		if r.Refreshed {
+//			tokens.Save(ctx, r.Token)
+//		}
+//		profiles.Update(ctx, r.User)
+//	}
+func RefreshUsers(ctx context.Context, tokens []StoredToken) []RefreshedUser {
+	results := make([]RefreshedUser, len(tokens))
+	limiters := &rateLimiters{limiters: map[string]*rateLimiter{}}
+	work := make(chan int)
+
+	workers := RefreshUsersConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = refreshUser(ctx, limiters, tokens[i])
+			}
+		}()
+	}
+	for i := range tokens {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+func refreshUser(ctx context.Context, limiters *rateLimiters, t StoredToken) RefreshedUser {
+	r := RefreshedUser{Token: t}
+	if err := ctx.Err(); err != nil {
+		r.Err = err
+		return r
+	}
+	provider, err := GetProvider(t.Provider)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	session, err := SessionFromToken(provider, t)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if err := limiters.wait(ctx, provider.Name()); err != nil {
+		r.Err = err
+		return r
+	}
+
+	var notSupported *RefreshNotSupportedError
+	r.Refreshed, err = RefreshToken(provider, session)
+	if err != nil && !errors.As(err, &notSupported) {
+		r.Err = err
+		return r
+	}
+	if r.Refreshed {
+		r.Token = tokenOf(session, t)
+	}
+	r.User, r.Err = provider.FetchUser(session)
+	return r
+}
+
+// rateLimiters paces the calls to each provider at its RefreshUsersRate.
+type rateLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]*rateLimiter
+}
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *rateLimiters) wait(ctx context.Context, provider string) error {
+	l.mu.Lock()
+	limiter, ok := l.limiters[provider]
+	if !ok {
+		rate, ok := RefreshUsersRate[provider]
+		if !ok {
+			rate = RefreshUsersRate["*"]
+		}
+		limiter = &rateLimiter{}
+		if rate > 0 {
+			limiter.interval = time.Duration(float64(time.Second) / rate)
+		}
+		l.limiters[provider] = limiter
+	}
+	l.mu.Unlock()
+	return limiter.wait(ctx)
+}
+
+// wait waits for the next slot of the limiter.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(slot.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
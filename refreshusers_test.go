@@ -0,0 +1,141 @@
+package goth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/gothic/store"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+// batchProvider fetches the users named by their access tokens and records
+// how many are fetched at once.
+type batchProvider struct {
+	refreshingProvider
+
+	mu               sync.Mutex
+	running, maxRuns int
+}
+
+func (p *batchProvider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &tokenSession{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
+
+func (p *batchProvider) FetchUser(session goth.Session) (goth.User, error) {
+	p.mu.Lock()
+	if p.running++; p.running > p.maxRuns {
+		p.maxRuns = p.running
+	}
+	p.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	p.mu.Lock()
+	p.running--
+	p.mu.Unlock()
+
+	s := session.(*tokenSession)
+	if s.AccessToken == "revoked" {
+		return goth.User{}, errors.New("invalid token")
+	}
+	return goth.User{Provider: p.Name(), UserID: strings.TrimPrefix(s.AccessToken, "access-"), AccessToken: s.AccessToken}, nil
+}
+
+func Test_RefreshUsers(t *testing.T) {
+	a := assert.New(t)
+	p := &batchProvider{refreshingProvider: refreshingProvider{available: true}}
+	p.SetName("batch")
+	goth.UseProviders(p)
+	defer goth.ClearProviders()
+
+	concurrency, rates := goth.RefreshUsersConcurrency, goth.RefreshUsersRate
+	goth.RefreshUsersConcurrency = 3
+	goth.RefreshUsersRate = map[string]float64{}
+	defer func() { goth.RefreshUsersConcurrency, goth.RefreshUsersRate = concurrency, rates }()
+
+	var tokens []goth.StoredToken
+	for i := 0; i < 10; i++ {
+		tokens = append(tokens, goth.StoredToken{
+			Provider:    "batch",
+			UserID:      fmt.Sprint(i),
+			AccessToken: fmt.Sprintf("access-%d", i),
+			ExpiresAt:   time.Now().Add(time.Hour),
+		})
+	}
+	tokens[3].RefreshToken, tokens[3].ExpiresAt = "refresh", time.Now().Add(-time.Minute)
+	tokens[5].AccessToken = "revoked"
+	tokens = append(tokens, goth.StoredToken{Provider: "unknown", UserID: "10"})
+
+	results := goth.RefreshUsers(context.Background(), tokens)
+	a.Len(results, len(tokens))
+	for i, r := range results[:10] {
+		switch i {
+		case 3:
+			a.NoError(r.Err)
+			a.True(r.Refreshed)
+			a.Equal("new-access", r.Token.AccessToken)
+			a.Equal("new-refresh", r.Token.RefreshToken)
+			a.Equal("new-id", r.Token.IDToken)
+			a.Equal("3", r.Token.UserID)
+			a.Equal("new-access", r.User.AccessToken)
+		case 5:
+			a.EqualError(r.Err, "invalid token")
+		default:
+			a.NoError(r.Err)
+			a.False(r.Refreshed)
+			a.Equal(tokens[i], r.Token)
+			a.Equal(fmt.Sprint(i), r.User.UserID)
+		}
+	}
+	a.True(errors.Is(results[10].Err, goth.ErrProviderNotFound))
+	a.Equal(1, p.calls)
+	a.True(p.maxRuns > 1 && p.maxRuns <= 3, "ran %d at once", p.maxRuns)
+}
+
+func Test_RefreshUsersRate(t *testing.T) {
+	a := assert.New(t)
+	p := &batchProvider{}
+	p.SetName("batch")
+	goth.UseProviders(p)
+	defer goth.ClearProviders()
+
+	rates := goth.RefreshUsersRate
+	goth.RefreshUsersRate = map[string]float64{"batch": 100}
+	defer func() { goth.RefreshUsersRate = rates }()
+
+	tokens := make([]goth.StoredToken, 5)
+	for i := range tokens {
+		tokens[i] = goth.StoredToken{Provider: "batch", AccessToken: fmt.Sprintf("access-%d", i)}
+	}
+	start := time.Now()
+	for _, r := range goth.RefreshUsers(context.Background(), tokens) {
+		a.NoError(r.Err)
+	}
+	a.True(time.Since(start) >= 40*time.Millisecond, "5 users at 100 per second take 40ms")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, r := range goth.RefreshUsers(ctx, tokens) {
+		a.Equal(context.Canceled, r.Err)
+	}
+}
+
+func Test_SessionFromToken(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := goth.SessionFromToken(&faux.Provider{}, store.Token{Provider: "faux", AccessToken: "access"})
+	a.EqualError(err, "faux: the sessions of the provider do not hold tokens")
+
+	s, err := goth.SessionFromToken(&batchProvider{}, goth.StoredToken{AccessToken: "access", IDToken: "id"})
+	a.NoError(err)
+	a.Equal("access", s.(*tokenSession).AccessToken)
+	a.Equal("id", s.(*tokenSession).IDToken)
+}
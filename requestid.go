@@ -0,0 +1,83 @@
+package goth
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to send the correlation ID of the
+// request that triggered a provider call. It matches the header set by
+// Echo's RequestID middleware.
+var RequestIDHeader = "X-Request-Id"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the given request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by
+// ContextWithRequestID, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ClientBinder is implemented by providers that can return a copy of
+// themselves making all of their HTTP calls with a different client. bind is
+// given the client the provider currently uses and returns the one the copy
+// should use. The original provider is left untouched, so a copy can be bound
+// to a single request without affecting the others.
+type ClientBinder interface {
+	BindClient(bind func(*http.Client) *http.Client) Provider
+}
+
+// WithRequestID returns a provider whose HTTP calls send id in the
+// RequestIDHeader header, so the calls made on behalf of a user can be tied
+// back to the request that triggered them. Providers that do not implement
+// ClientBinder are returned unchanged.
+func WithRequestID(p Provider, id string) Provider {
+	b, ok := p.(ClientBinder)
+	if !ok || id == "" {
+		return p
+	}
+	return b.BindClient(func(c *http.Client) *http.Client {
+		return HTTPClientWithRequestID(c, id)
+	})
+}
+
+// HTTPClientWithRequestID returns a copy of client that sends id in the
+// RequestIDHeader header of every request that does not already have one.
+// When id is empty, the ID stored in the request context by
+// ContextWithRequestID is sent instead.
+func HTTPClientWithRequestID(client *http.Client, id string) *http.Client {
+	c := *HTTPClientWithFallBack(client)
+	c.Transport = &requestIDTransport{base: c.Transport, id: id}
+	return &c
+}
+
+type requestIDTransport struct {
+	base http.RoundTripper
+	id   string
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	id := t.id
+	if id == "" {
+		id = RequestIDFromContext(req.Context())
+	}
+	if id == "" || req.Header.Get(RequestIDHeader) != "" {
+		return base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it is given.
+	r := req.Clone(req.Context())
+	r.Header.Set(RequestIDHeader, id)
+	return base.RoundTrip(r)
+}
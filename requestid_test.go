@@ -0,0 +1,66 @@
+package goth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/github"
+	"github.com/bgdsh/goth/providers/nostr"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTTPClientWithRequestID(t *testing.T) {
+	a := assert.New(t)
+
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(goth.RequestIDHeader))
+	}))
+	defer ts.Close()
+
+	client := goth.HTTPClientWithRequestID(nil, "abc")
+	_, err := client.Get(ts.URL)
+	a.NoError(err)
+
+	// A request ID set by the caller wins.
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	req.Header.Set(goth.RequestIDHeader, "caller")
+	_, err = client.Do(req)
+	a.NoError(err)
+
+	// Without an explicit ID, the one carried by the context is used.
+	req, _ = http.NewRequestWithContext(goth.ContextWithRequestID(context.Background(), "ctx"), "GET", ts.URL, nil)
+	_, err = goth.HTTPClientWithRequestID(nil, "").Do(req)
+	a.NoError(err)
+
+	a.Equal([]string{"abc", "caller", "ctx"}, got)
+	a.Nil(http.DefaultClient.Transport)
+}
+
+func Test_WithRequestID(t *testing.T) {
+	a := assert.New(t)
+
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(goth.RequestIDHeader)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"login":"homer","email":"homer@example.com"}`))
+	}))
+	defer ts.Close()
+
+	provider := github.NewCustomisedURL("key", "secret", "/foo", ts.URL, ts.URL, ts.URL, ts.URL)
+	bound := goth.WithRequestID(provider, "abc")
+	a.NotEqual(provider, bound)
+	a.Nil(provider.HTTPClient)
+
+	_, err := bound.FetchUser(&github.Session{AccessToken: "token"})
+	a.NoError(err)
+	a.Equal("abc", got)
+
+	// Providers unable to bind a client are returned as is.
+	n := nostr.New("/login", "/callback")
+	a.Equal(n, goth.WithRequestID(n, "abc"))
+}
@@ -0,0 +1,45 @@
+package goth
+
+import (
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+// ResourceParam is the request parameter used to indicate the target
+// service of an access token.
+// See https://datatracker.ietf.org/doc/html/rfc8707
+const ResourceParam = "resource"
+
+// AppendResources adds one resource parameter per resource to the given
+// authorization URL. RFC 8707 allows the parameter to be repeated, which
+// oauth2.SetAuthURLParam cannot express.
+func AppendResources(authURL string, resources ...string) (string, error) {
+	if len(resources) == 0 {
+		return authURL, nil
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	for _, resource := range resources {
+		q.Add(ResourceParam, resource)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// ResourceTokenOptions returns the options needed to send a resource
+// indicator on the token request. The token request can only carry a
+// single resource through golang.org/x/oauth2, so nothing is sent when
+// several resources are configured and the authorization server decides
+// the audience from the authorization grant instead.
+func ResourceTokenOptions(resources ...string) []oauth2.AuthCodeOption {
+	if len(resources) != 1 {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam(ResourceParam, resources[0])}
+}
@@ -0,0 +1,33 @@
+package goth_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AppendResources(t *testing.T) {
+	a := assert.New(t)
+
+	u, err := goth.AppendResources("https://example.com/authorize?state=xyz", "https://api.example.com", "https://files.example.com")
+	a.NoError(err)
+
+	parsed, err := url.Parse(u)
+	a.NoError(err)
+	a.Equal("xyz", parsed.Query().Get("state"))
+	a.Equal([]string{"https://api.example.com", "https://files.example.com"}, parsed.Query()["resource"])
+
+	u, err = goth.AppendResources("https://example.com/authorize?state=xyz")
+	a.NoError(err)
+	a.Equal("https://example.com/authorize?state=xyz", u)
+}
+
+func Test_ResourceTokenOptions(t *testing.T) {
+	a := assert.New(t)
+
+	a.Len(goth.ResourceTokenOptions("https://api.example.com"), 1)
+	a.Len(goth.ResourceTokenOptions(), 0)
+	a.Len(goth.ResourceTokenOptions("https://api.example.com", "https://files.example.com"), 0)
+}
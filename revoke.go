@@ -0,0 +1,97 @@
+package goth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Token type hints of OAuth 2.0 Token Revocation.
+// See https://datatracker.ietf.org/doc/html/rfc7009#section-2.1
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// TokenRevoker is implemented by providers that can revoke the access and
+// refresh tokens they issued, for example when a user logs out or deletes
+// their account. Check for it with RevokeToken.
+type TokenRevoker interface {
+	RevokeToken(token string) error
+}
+
+// RevocationNotSupportedError is returned by RevokeToken when the provider has
+// no revocation end-point. The token stays valid until it expires.
+type RevocationNotSupportedError struct {
+	Provider string
+}
+
+func (e *RevocationNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: token revocation is not supported", e.Provider)
+}
+
+// RevokeToken revokes token, an access or refresh token issued by provider.
+// Depending on the provider, revoking one token revokes the tokens issued with
+// it, or the whole grant. It returns a *RevocationNotSupportedError when the
+// provider cannot revoke tokens.
+func RevokeToken(provider Provider, token string) error {
+	revoker, ok := provider.(TokenRevoker)
+	if !ok {
+		return &RevocationNotSupportedError{Provider: provider.Name()}
+	}
+	return revoker.RevokeToken(token)
+}
+
+// RevocationRequest describes a single RFC 7009 token revocation request.
+// ClientID and ClientSecret are optional; they are sent as AuthStyle says,
+// with HTTP Basic authentication by default.
+type RevocationRequest struct {
+	RevocationURL string
+	ClientID      string
+	ClientSecret  string
+	AuthStyle     oauth2.AuthStyle
+	Token         string
+	TokenTypeHint string
+}
+
+// Revoke performs an RFC 7009 token revocation against the revocation
+// end-point described by r. Servers answer with a 200 even when the token was
+// already invalid.
+// See https://datatracker.ietf.org/doc/html/rfc7009#section-2
+func Revoke(client *http.Client, r RevocationRequest) error {
+	v := url.Values{"token": {r.Token}}
+	if r.TokenTypeHint != "" {
+		v.Set("token_type_hint", r.TokenTypeHint)
+	}
+	if r.ClientID != "" && r.AuthStyle == oauth2.AuthStyleInParams {
+		v.Set("client_id", r.ClientID)
+		if r.ClientSecret != "" {
+			v.Set("client_secret", r.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequest("POST", r.RevocationURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if r.ClientID != "" && r.AuthStyle != oauth2.AuthStyleInParams {
+		req.SetBasicAuth(url.QueryEscape(r.ClientID), url.QueryEscape(r.ClientSecret))
+	}
+
+	resp, err := HTTPClientWithFallBack(client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &ProviderAPIError{Provider: "token revocation", Status: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package goth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type revokingProvider struct {
+	faux.Provider
+	revoked []string
+}
+
+func (p *revokingProvider) RevokeToken(token string) error {
+	p.revoked = append(p.revoked, token)
+	return nil
+}
+
+func Test_RevokeToken(t *testing.T) {
+	a := assert.New(t)
+
+	p := &revokingProvider{}
+	a.NoError(goth.RevokeToken(p, "token"))
+	a.Equal([]string{"token"}, p.revoked)
+
+	var notSupported *goth.RevocationNotSupportedError
+	err := goth.RevokeToken(&faux.Provider{}, "token")
+	a.True(errors.As(err, &notSupported))
+	a.Equal("faux", notSupported.Provider)
+}
+
+func Test_Revoke(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("token", r.PostForm.Get("token"))
+		a.Equal("refresh_token", r.PostForm.Get("token_type_hint"))
+		if id, secret, ok := r.BasicAuth(); ok {
+			a.Equal("client", id)
+			a.Equal("secret", secret)
+			a.Empty(r.PostForm.Get("client_id"))
+		} else {
+			a.Equal("client", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+		}
+	}))
+	defer ts.Close()
+
+	r := goth.RevocationRequest{
+		RevocationURL: ts.URL,
+		ClientID:      "client",
+		ClientSecret:  "secret",
+		Token:         "token",
+		TokenTypeHint: goth.TokenTypeHintRefreshToken,
+	}
+	a.NoError(goth.Revoke(nil, r))
+
+	r.AuthStyle = oauth2.AuthStyleInParams
+	a.NoError(goth.Revoke(nil, r))
+}
+
+func Test_RevokeRejected(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"unsupported_token_type"}`))
+	}))
+	defer ts.Close()
+
+	err := goth.Revoke(nil, goth.RevocationRequest{RevocationURL: ts.URL, Token: "token"})
+	a.EqualError(err, `token revocation responded with a 400: {"error":"unsupported_token_type"}`)
+}
@@ -0,0 +1,230 @@
+package goth
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Providers change their user APIs, renaming or dropping fields, and users
+// then get empty profiles without any error. A UserSchema describes what the
+// users of a provider are expected to have, so that such a drift is reported
+// with OnSchemaDrift as soon as it happens:
+//
+//	goth.UseUserSchema("linkedin", goth.UserSchema{
+//		Fields: []string{"Email", "Name"},
+//		RawData: &goth.Schema{
+//			Required:   []string{"sub", "email"},
+//			Properties: map[string]*goth.Schema{"email_verified": {Type: "boolean"}},
+//		},
+//	})
+
+// Schema is the subset of JSON Schema describing the shape of the user data
+// of a provider: the type of a value, the required properties and the schemas
+// of the properties of an object, and the schema of the items of an array. It
+// can be unmarshaled from a JSON Schema document.
+type Schema struct {
+	// Type is one of "string", "number", "integer", "boolean", "object",
+	// "array" and "null", any type when empty.
+	Type       string             `json:"type,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+// UserSchema is what the users of a provider are expected to have.
+type UserSchema struct {
+	// Fields are the fields of User that must not be empty, such as "Email"
+	// or "AvatarURL".
+	Fields []string
+	// RawData is the schema of User.RawData.
+	RawData *Schema
+}
+
+// SchemaViolation is a difference between a user and the schema of its
+// provider.
+type SchemaViolation struct {
+	// Path is the field of User, or the path of the value in RawData, such
+	// as "RawData.picture.data.url".
+	Path    string
+	Problem string
+}
+
+func (v SchemaViolation) String() string {
+	return v.Path + ": " + v.Problem
+}
+
+// SchemaDrift reports the users of a provider that do not match its schema.
+type SchemaDrift struct {
+	Provider   string
+	Time       time.Time
+	Violations []SchemaViolation
+}
+
+// OnSchemaDrift, when set, is called by CheckUserSchema with the users that do
+// not match the schema of their provider, the place to log the drift or count
+// it in the metrics of the application.
+var OnSchemaDrift func(d SchemaDrift)
+
+var (
+	schemasMu    sync.Mutex
+	schemas      = map[string]UserSchema{}
+	schemaDrifts = map[string]int{}
+)
+
+// UseUserSchema makes CheckUserSchema check the users of the named provider
+// against schema.
+func UseUserSchema(provider string, schema UserSchema) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	schemas[provider] = schema
+}
+
+// ClearUserSchemas removes the schemas of every provider and resets their
+// drift counts.
+func ClearUserSchemas() {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	schemas = map[string]UserSchema{}
+	schemaDrifts = map[string]int{}
+}
+
+// SchemaDrifts returns how many users of each provider did not match its
+// schema.
+func SchemaDrifts() map[string]int {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	counts := make(map[string]int, len(schemaDrifts))
+	for provider, n := range schemaDrifts {
+		counts[provider] = n
+	}
+	return counts
+}
+
+// CheckUserSchema checks user against the schema of its provider, if any, and
+// reports the violations to OnSchemaDrift. gothic calls it with every user it
+// fetches.
+func CheckUserSchema(user User) []SchemaViolation {
+	schemasMu.Lock()
+	schema, ok := schemas[user.Provider]
+	schemasMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	violations := schema.Validate(user)
+	if len(violations) == 0 {
+		return nil
+	}
+	schemasMu.Lock()
+	schemaDrifts[user.Provider]++
+	schemasMu.Unlock()
+	if OnSchemaDrift != nil {
+		OnSchemaDrift(SchemaDrift{Provider: user.Provider, Time: Now(), Violations: violations})
+	}
+	return violations
+}
+
+// Validate returns the differences between user and the schema.
+func (s UserSchema) Validate(user User) []SchemaViolation {
+	var violations []SchemaViolation
+	v := reflect.ValueOf(user)
+	for _, name := range s.Fields {
+		field := v.FieldByName(name)
+		switch {
+		case !field.IsValid():
+			violations = append(violations, SchemaViolation{Path: name, Problem: "is not a field of goth.User"})
+		case field.IsZero():
+			violations = append(violations, SchemaViolation{Path: name, Problem: "is empty"})
+		}
+	}
+	if s.RawData != nil {
+		raw, err := NormalizeRawData(user.RawData)
+		if err != nil {
+			return append(violations, SchemaViolation{Path: "RawData", Problem: err.Error()})
+		}
+		var data interface{} = map[string]interface{}{}
+		if raw != nil {
+			data = raw
+		}
+		violations = s.RawData.validate("RawData", data, violations)
+	}
+	return violations
+}
+
+// Validate returns the differences between value, decoded from JSON, and the
+// schema.
+func (s *Schema) Validate(value interface{}) []SchemaViolation {
+	return s.validate("", value, nil)
+}
+
+func (s *Schema) validate(path string, value interface{}, violations []SchemaViolation) []SchemaViolation {
+	if s.Type != "" && !hasJSONType(value, s.Type) {
+		return append(violations, SchemaViolation{
+			Path:    path,
+			Problem: fmt.Sprintf("expected %s, got %s", s.Type, jsonType(value)),
+		})
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				violations = append(violations, SchemaViolation{Path: joinPath(path, name), Problem: "is missing"})
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := v[name]; ok {
+				violations = s.Properties[name].validate(joinPath(path, name), property, violations)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				violations = s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	}
+	return violations
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func hasJSONType(value interface{}, typ string) bool {
+	if typ == "integer" {
+		f, ok := value.(float64)
+		return ok && f == float64(int64(f))
+	}
+	return jsonType(value) == typ
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", value), "*")
+}
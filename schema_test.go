@@ -0,0 +1,91 @@
+package goth_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckUserSchema(t *testing.T) {
+	a := assert.New(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+	defer goth.ClearUserSchemas()
+
+	var drifts []goth.SchemaDrift
+	goth.OnSchemaDrift = func(d goth.SchemaDrift) { drifts = append(drifts, d) }
+	defer func() { goth.OnSchemaDrift = nil }()
+
+	raw := &goth.Schema{}
+	a.NoError(json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["sub", "email"],
+		"properties": {
+			"email_verified": {"type": "boolean"},
+			"picture": {"type": "object", "required": ["url"]},
+			"groups": {"type": "array", "items": {"type": "string"}}
+		}
+	}`), raw))
+	goth.UseUserSchema("linkedin", goth.UserSchema{Fields: []string{"Email", "Name"}, RawData: raw})
+
+	a.Empty(goth.CheckUserSchema(goth.User{Provider: "github"}))
+
+	good := goth.User{
+		Provider: "linkedin",
+		Email:    "homer@example.com",
+		Name:     "Homer",
+		RawData: map[string]interface{}{
+			"sub":            "42",
+			"email":          "homer@example.com",
+			"email_verified": true,
+			"picture":        map[string]interface{}{"url": "https://example.com/homer.png"},
+			"groups":         []string{"admins"},
+		},
+	}
+	a.Empty(goth.CheckUserSchema(good))
+	a.Empty(drifts)
+
+	drifted := goth.User{
+		Provider: "linkedin",
+		Email:    "homer@example.com",
+		RawData: map[string]interface{}{
+			"sub":            "42",
+			"email_verified": "true",
+			"picture":        map[string]interface{}{},
+			"groups":         []interface{}{"admins", 7},
+		},
+	}
+	violations := goth.CheckUserSchema(drifted)
+	a.Equal([]goth.SchemaViolation{
+		{Path: "Name", Problem: "is empty"},
+		{Path: "RawData.email", Problem: "is missing"},
+		{Path: "RawData.email_verified", Problem: "expected boolean, got string"},
+		{Path: "RawData.groups[1]", Problem: "expected string, got number"},
+		{Path: "RawData.picture.url", Problem: "is missing"},
+	}, violations)
+	a.Len(drifts, 1)
+	a.Equal(goth.SchemaDrift{Provider: "linkedin", Time: now, Violations: violations}, drifts[0])
+	a.Equal(map[string]int{"linkedin": 1}, goth.SchemaDrifts())
+
+	a.Equal([]goth.SchemaViolation{{Path: "Phone", Problem: "is not a field of goth.User"}},
+		goth.UserSchema{Fields: []string{"Phone"}}.Validate(good))
+
+	goth.ClearUserSchemas()
+	a.Empty(goth.CheckUserSchema(drifted))
+	a.Empty(goth.SchemaDrifts())
+}
+
+func Test_Schema_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	s := &goth.Schema{Type: "integer"}
+	a.Empty(s.Validate(float64(3)))
+	a.Equal([]goth.SchemaViolation{{Problem: "expected integer, got number"}}, s.Validate(3.5))
+
+	s = &goth.Schema{Type: "object"}
+	a.Equal([]goth.SchemaViolation{{Problem: "expected object, got null"}}, s.Validate(nil))
+}
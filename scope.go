@@ -0,0 +1,80 @@
+package goth
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// ScopeFormat is how the scopes of the authorization URLs of a provider are
+// joined and encoded. OAuth2 separates them with spaces, encoded as plus
+// signs, but some providers expect commas or spaces encoded as %20.
+type ScopeFormat struct {
+	// Separator joins the scopes, a space when empty.
+	Separator string
+	// PercentEncodeSpaces encodes the spaces of the query of the
+	// authorization URL as %20 rather than plus signs, for the providers
+	// not decoding them.
+	PercentEncodeSpaces bool
+}
+
+var (
+	// SpaceSeparatedScopes is the scope format of OAuth2, used by most
+	// providers.
+	SpaceSeparatedScopes = ScopeFormat{Separator: " "}
+	// CommaSeparatedScopes is the scope format of Strava, Adobe, Medium and
+	// others.
+	CommaSeparatedScopes = ScopeFormat{Separator: ","}
+	// PercentEncodedScopes is the scope format of Apple, separating the
+	// scopes with spaces encoded as %20.
+	PercentEncodedScopes = ScopeFormat{Separator: " ", PercentEncodeSpaces: true}
+)
+
+// ScopeFormats overrides the scope formats of providers, by provider name,
+// for the providers whose format is not that of their package, such as the
+// identity providers behind a generic OpenID Connect provider.
+var ScopeFormats = map[string]ScopeFormat{}
+
+// ScopeFormatOf returns the scope format of the provider name, from
+// ScopeFormats, or def.
+func ScopeFormatOf(name string, def ScopeFormat) ScopeFormat {
+	if f, ok := ScopeFormats[name]; ok {
+		return f
+	}
+	return def
+}
+
+// Join joins scopes with the separator of the format.
+func (f ScopeFormat) Join(scopes []string) string {
+	sep := f.Separator
+	if sep == "" {
+		sep = " "
+	}
+	return strings.Join(scopes, sep)
+}
+
+// AuthCodeURL returns the authorization URL of config, see
+// oauth2.Config.AuthCodeURL, with its scopes in the format.
+func (f ScopeFormat) AuthCodeURL(config *oauth2.Config, state string, opts ...oauth2.AuthCodeOption) string {
+	if len(config.Scopes) > 0 && f.Join(config.Scopes) != strings.Join(config.Scopes, " ") {
+		// Options set later take precedence, so the scope of opts still does.
+		opts = append([]oauth2.AuthCodeOption{oauth2.SetAuthURLParam("scope", f.Join(config.Scopes))}, opts...)
+	}
+	return f.EncodeURL(config.AuthCodeURL(state, opts...))
+}
+
+// EncodeURL returns authURL with the spaces of its query encoded in the
+// format. The plus signs of a query built by url.Values are encoded spaces,
+// those of the values being encoded as %2B.
+func (f ScopeFormat) EncodeURL(authURL string) string {
+	if !f.PercentEncodeSpaces {
+		return authURL
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return authURL
+	}
+	u.RawQuery = strings.ReplaceAll(u.RawQuery, "+", "%20")
+	return u.String()
+}
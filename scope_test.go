@@ -0,0 +1,33 @@
+package goth_test
+
+import (
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func Test_ScopeFormat(t *testing.T) {
+	a := assert.New(t)
+	config := &oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{AuthURL: "https://example.com/authorize"},
+		Scopes:   []string{"openid", "user:read+write"},
+	}
+
+	a.Equal("openid user:read+write", goth.ScopeFormat{}.Join(config.Scopes))
+	a.Equal(config.AuthCodeURL("state"), goth.SpaceSeparatedScopes.AuthCodeURL(config, "state"))
+	a.Equal("https://example.com/authorize?client_id=client&response_type=code&scope=openid%2Cuser%3Aread%2Bwrite&state=state",
+		goth.CommaSeparatedScopes.AuthCodeURL(config, "state"))
+	a.Equal("https://example.com/authorize?client_id=client&response_type=code&scope=openid%20user%3Aread%2Bwrite&state=a%20b",
+		goth.PercentEncodedScopes.AuthCodeURL(config, "a b"))
+	a.Equal("https://example.com/authorize?client_id=client&response_type=code&scope=email&state=state",
+		goth.CommaSeparatedScopes.AuthCodeURL(config, "state", oauth2.SetAuthURLParam("scope", "email")),
+		"the scope of the options takes precedence")
+
+	goth.ScopeFormats["strava"] = goth.SpaceSeparatedScopes
+	defer delete(goth.ScopeFormats, "strava")
+	a.Equal(goth.SpaceSeparatedScopes, goth.ScopeFormatOf("strava", goth.CommaSeparatedScopes))
+	a.Equal(goth.CommaSeparatedScopes, goth.ScopeFormatOf("medium", goth.CommaSeparatedScopes))
+}
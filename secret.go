@@ -0,0 +1,179 @@
+package goth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretSource supplies a secret, such as the client secret of a provider or
+// the private key Apple client secrets are signed with, so that it can be kept
+// out of the code and rotated. EnvSecret, FileSecret and VaultSecret are
+// sources; the awssecrets package reads AWS Secrets Manager. RotateProvider
+// builds providers with the secret of a source and rebuilds them whenever it
+// changes.
+type SecretSource interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// SecretSourceFunc adapts a function to the SecretSource interface.
+type SecretSourceFunc func(ctx context.Context) (string, error)
+
+// Secret calls f(ctx).
+func (f SecretSourceFunc) Secret(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// EnvSecret returns the source of the secret held by the named environment
+// variable, read again on every call.
+func EnvSecret(name string) SecretSource {
+	return SecretSourceFunc(func(ctx context.Context) (string, error) {
+		secret := os.Getenv(name)
+		if secret == "" {
+			return "", fmt.Errorf("goth: the %s environment variable is not set", name)
+		}
+		return secret, nil
+	})
+}
+
+// FileSecret returns the source of the secret held by the file at path, such
+// as the secrets mounted by Kubernetes or a .p8 key, read again on every call.
+// Surrounding whitespace is removed.
+func FileSecret(path string) SecretSource {
+	return SecretSourceFunc(func(ctx context.Context) (string, error) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("goth: %w", err)
+		}
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return "", fmt.Errorf("goth: %s is empty", path)
+		}
+		return secret, nil
+	})
+}
+
+// OnSecretRefreshError, when set, is called with the errors of the refreshes
+// of the providers of RotateProvider, which keep their previous secret.
+var OnSecretRefreshError func(provider string, err error)
+
+// SecretRotation keeps a provider of RotateProvider built with the current
+// secret of its source.
+type SecretRotation struct {
+	source SecretSource
+	build  func(secret string) (Provider, error)
+	stop   chan struct{}
+
+	mu       sync.Mutex
+	secret   string
+	provider Provider
+	err      error
+	stopped  bool
+}
+
+// RotateProvider registers the provider build returns for the secret of
+// source, then fetches the secret again every interval and, when it changed,
+// registers the provider built with the new one in place of the previous one,
+// so that rotated secrets take effect without a restart. The secret is not
+// fetched again when interval is 0.
+//
+//	rotation, err := goth.RotateProvider(goth.FileSecret("/run/secrets/github"), 5*time.Minute,
+//		func(secret string) (goth.Provider, error) {
+//			return github.New(os.Getenv("GITHUB_KEY"), secret, callbackURL), nil
+//		})
+//	defer rotation.Stop()
+//
+// It fails, registering nothing, when the secret cannot be fetched or the
+// provider cannot be built.
+func RotateProvider(source SecretSource, interval time.Duration, build func(secret string) (Provider, error)) (*SecretRotation, error) {
+	r := &SecretRotation{source: source, build: build, stop: make(chan struct{})}
+	if err := r.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	if interval > 0 {
+		go r.run(interval)
+	}
+	return r, nil
+}
+
+func (r *SecretRotation) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := r.Refresh(ctx)
+			cancel()
+			if err != nil && OnSecretRefreshError != nil {
+				OnSecretRefreshError(r.Provider().Name(), err)
+			}
+		}
+	}
+}
+
+// Refresh fetches the secret now and, when it changed, registers the provider
+// built with it. The provider keeps its previous secret when it fails.
+func (r *SecretRotation) Refresh(ctx context.Context) error {
+	err := r.refresh(ctx)
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+	return err
+}
+
+func (r *SecretRotation) refresh(ctx context.Context) error {
+	secret, err := r.source.Secret(ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	unchanged := r.provider != nil && secret == r.secret
+	r.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	provider, err := r.build(secret)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return nil
+	}
+	r.secret, r.provider = secret, provider
+	UseProviders(provider)
+	return nil
+}
+
+// Provider returns the provider built with the current secret.
+func (r *SecretRotation) Provider() Provider {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.provider
+}
+
+// Err returns the error of the last refresh, nil when it succeeded.
+func (r *SecretRotation) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Stop stops fetching the secret. The provider stays registered.
+func (r *SecretRotation) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.stop)
+	}
+}
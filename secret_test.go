@@ -0,0 +1,161 @@
+package goth_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/gitlab"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EnvSecret(t *testing.T) {
+	a := assert.New(t)
+	os.Setenv("GOTH_TEST_SECRET", "s3cret")
+	defer os.Unsetenv("GOTH_TEST_SECRET")
+
+	secret, err := goth.EnvSecret("GOTH_TEST_SECRET").Secret(context.Background())
+	a.NoError(err)
+	a.Equal("s3cret", secret)
+
+	_, err = goth.EnvSecret("GOTH_TEST_UNSET").Secret(context.Background())
+	a.EqualError(err, "goth: the GOTH_TEST_UNSET environment variable is not set")
+}
+
+func Test_FileSecret(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "goth")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secret")
+	a.NoError(ioutil.WriteFile(path, []byte("s3cret\n"), 0600))
+
+	source := goth.FileSecret(path)
+	secret, err := source.Secret(context.Background())
+	a.NoError(err)
+	a.Equal("s3cret", secret)
+
+	a.NoError(ioutil.WriteFile(path, []byte("rotated"), 0600))
+	secret, err = source.Secret(context.Background())
+	a.NoError(err)
+	a.Equal("rotated", secret, "the file is read again")
+
+	_, err = goth.FileSecret(filepath.Join(dir, "missing")).Secret(context.Background())
+	a.True(errors.Is(err, os.ErrNotExist))
+}
+
+func Test_VaultSecret(t *testing.T) {
+	a := assert.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/goth":
+			w.Write([]byte(`{"data": {"data": {"client_secret": "v2-secret"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/goth":
+			w.Write([]byte(`{"data": {"client_secret": "v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	source := &goth.VaultSecret{Addr: ts.URL, Token: "token", Path: "secret/data/goth", Field: "client_secret"}
+	secret, err := source.Secret(context.Background())
+	a.NoError(err)
+	a.Equal("v2-secret", secret)
+
+	source.Path = "kv/goth"
+	secret, err = source.Secret(context.Background())
+	a.NoError(err)
+	a.Equal("v1-secret", secret)
+
+	source.Field = "key"
+	_, err = source.Secret(context.Background())
+	a.EqualError(err, "vault: kv/goth has no key field")
+
+	source.Token = "wrong"
+	_, err = source.Secret(context.Background())
+	var apiErr *goth.ProviderAPIError
+	a.True(errors.As(err, &apiErr))
+	a.Equal(http.StatusForbidden, apiErr.Status)
+}
+
+func Test_RotateProvider(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	secret, fetchErr := "first", error(nil)
+	source := goth.SecretSourceFunc(func(context.Context) (string, error) {
+		return secret, fetchErr
+	})
+	var built []string
+	rotation, err := goth.RotateProvider(source, 0, func(secret string) (goth.Provider, error) {
+		built = append(built, secret)
+		p := gitlab.New("key", secret, "https://example.com/auth/gitlab/callback")
+		p.SetName("gitlab-rotated")
+		return p, nil
+	})
+	a.NoError(err)
+	defer rotation.Stop()
+
+	registered, err := goth.GetProvider("gitlab-rotated")
+	a.NoError(err)
+	a.Equal(rotation.Provider(), registered)
+	a.Equal([]string{"first"}, built)
+
+	a.NoError(rotation.Refresh(context.Background()))
+	a.Equal([]string{"first"}, built, "the secret has not changed")
+
+	secret = "second"
+	a.NoError(rotation.Refresh(context.Background()))
+	a.Equal([]string{"first", "second"}, built)
+	registered, err = goth.GetProvider("gitlab-rotated")
+	a.NoError(err)
+	a.Equal("second", registered.(*gitlab.Provider).Secret)
+
+	fetchErr = errors.New("vault is sealed")
+	a.EqualError(rotation.Refresh(context.Background()), "vault is sealed")
+	a.EqualError(rotation.Err(), "vault is sealed")
+	registered, err = goth.GetProvider("gitlab-rotated")
+	a.NoError(err)
+	a.Equal("second", registered.(*gitlab.Provider).Secret, "the previous secret is kept")
+
+	_, err = goth.RotateProvider(source, 0, nil)
+	a.EqualError(err, "vault is sealed")
+}
+
+func Test_RotateProviderRefreshesPeriodically(t *testing.T) {
+	a := assert.New(t)
+	defer goth.ClearProviders()
+
+	fetched := make(chan struct{}, 1)
+	source := goth.SecretSourceFunc(func(context.Context) (string, error) {
+		select {
+		case fetched <- struct{}{}:
+		default:
+		}
+		return "secret", nil
+	})
+	rotation, err := goth.RotateProvider(source, 10*time.Millisecond, func(secret string) (goth.Provider, error) {
+		return gitlab.New("key", secret, "https://example.com/auth/gitlab/callback"), nil
+	})
+	a.NoError(err)
+	<-fetched
+	select {
+	case <-fetched:
+	case <-time.After(time.Second):
+		t.Fatal("the secret was not fetched again")
+	}
+	rotation.Stop()
+	rotation.Stop()
+}
@@ -10,6 +10,11 @@ type Params interface {
 // It will be marshaled and persisted between requests to "tie"
 // the start and the end of the authorization process with a
 // 3rd party provider.
+//
+// A Session belongs to a single authentication attempt and is not safe for
+// concurrent use: Authorize mutates it. Code that shares a session between
+// goroutines, for example to refresh its tokens, must synchronize access
+// itself, or better, unmarshal its own copy with Provider.UnmarshalSession.
 type Session interface {
 	// GetAuthURL returns the URL for the authentication end-point for the provider.
 	GetAuthURL() (string, error)
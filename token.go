@@ -0,0 +1,86 @@
+package goth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNoToken is returned by TokenFromSession for the sessions not holding an
+// OAuth2 access token, such as those of OAuth1 providers or not authorized
+// yet.
+var ErrNoToken = errors.New("session holds no token")
+
+// TokenFromUser returns the tokens of user as an OAuth2 token, for the SDKs
+// and libraries built on golang.org/x/oauth2. The ID token, when there is one,
+// is its "id_token" extra. The tokens of OAuth1 providers, which come with an
+// AccessTokenSecret, are not OAuth2 tokens.
+func TokenFromUser(user User) *oauth2.Token {
+	return newToken(user.AccessToken, user.RefreshToken, user.ExpiresAt, user.IDToken)
+}
+
+// TokenFromSession returns the tokens held by session as an OAuth2 token, see
+// TokenFromUser. It returns ErrNoToken for the sessions without an access
+// token.
+func TokenFromSession(session Session) (*oauth2.Token, error) {
+	v, ok := sessionTokens(session)
+	if !ok || v.FieldByName("AccessToken").String() == "" {
+		return nil, ErrNoToken
+	}
+	t := tokenOf(session, StoredToken{})
+	return newToken(t.AccessToken, t.RefreshToken, t.ExpiresAt, t.IDToken), nil
+}
+
+func newToken(accessToken, refreshToken string, expiresAt time.Time, idToken string) *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		RefreshToken: refreshToken,
+		Expiry:       expiresAt,
+	}
+	if idToken != "" {
+		token = token.WithExtra(map[string]interface{}{"id_token": idToken})
+	}
+	return token
+}
+
+// SessionTokenSource returns a token source of the tokens of session, which
+// refreshes them when they expire within RefreshLeeway, see RefreshToken. The
+// session is refreshed in place: store it again once done with the source, as
+// providers rotating their refresh tokens invalidate the previous one. The
+// access tokens of providers not refreshing tokens are used as they are,
+// until they are rejected.
+//
+//	client := oauth2.NewClient(ctx, goth.SessionTokenSource(provider, session))
+func SessionTokenSource(provider Provider, session Session) oauth2.TokenSource {
+	return &sessionTokenSource{provider: provider, session: session}
+}
+
+type sessionTokenSource struct {
+	provider Provider
+	// onRefresh, when not nil, is called with the session once refreshed.
+	onRefresh func(Session) error
+
+	// mu serializes the refreshes of the session, see Session.
+	mu      sync.Mutex
+	session Session
+}
+
+func (s *sessionTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refreshed, err := RefreshToken(s.provider, s.session)
+	var notSupported *RefreshNotSupportedError
+	if err != nil && !errors.As(err, &notSupported) {
+		return nil, err
+	}
+	if refreshed && s.onRefresh != nil {
+		if err := s.onRefresh(s.session); err != nil {
+			return nil, err
+		}
+	}
+	return TokenFromSession(s.session)
+}
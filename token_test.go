@@ -0,0 +1,60 @@
+package goth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TokenFromUser(t *testing.T) {
+	a := assert.New(t)
+	expiresAt := time.Now().Add(time.Hour)
+
+	token := goth.TokenFromUser(goth.User{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: expiresAt, IDToken: "id"})
+	a.Equal("access", token.AccessToken)
+	a.Equal("Bearer", token.TokenType)
+	a.Equal("refresh", token.RefreshToken)
+	a.True(expiresAt.Equal(token.Expiry))
+	a.Equal("id", token.Extra("id_token"))
+	a.True(token.Valid())
+
+	a.Nil(goth.TokenFromUser(goth.User{AccessToken: "access"}).Extra("id_token"))
+}
+
+func Test_TokenFromSession(t *testing.T) {
+	a := assert.New(t)
+
+	token, err := goth.TokenFromSession(expiredSession())
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+	a.Equal("refresh", token.RefreshToken)
+	a.Equal("id", token.Extra("id_token"))
+	a.False(token.Valid())
+
+	_, err = goth.TokenFromSession(&faux.Session{AccessToken: "access"})
+	a.Equal(goth.ErrNoToken, err)
+	_, err = goth.TokenFromSession(&tokenSession{})
+	a.Equal(goth.ErrNoToken, err)
+}
+
+func Test_SessionTokenSource(t *testing.T) {
+	a := assert.New(t)
+	p := &refreshingProvider{available: true}
+	s := expiredSession()
+
+	source := goth.SessionTokenSource(p, s)
+	for i := 0; i < 2; i++ {
+		token, err := source.Token()
+		a.NoError(err)
+		a.Equal("new-access", token.AccessToken)
+		a.Equal("new-id", token.Extra("id_token"))
+	}
+	a.Equal(1, p.calls)
+	a.Equal("new-refresh", s.RefreshToken, "the session is refreshed in place")
+
+	_, err := goth.SessionTokenSource(p, &faux.Session{}).Token()
+	a.Equal(goth.ErrNoToken, err)
+}
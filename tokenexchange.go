@@ -0,0 +1,142 @@
+package goth
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Token type identifiers defined by OAuth 2.0 Token Exchange.
+// See https://datatracker.ietf.org/doc/html/rfc8693#section-3
+const (
+	TokenTypeAccessToken  = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeRefreshToken = "urn:ietf:params:oauth:token-type:refresh_token"
+	TokenTypeIDToken      = "urn:ietf:params:oauth:token-type:id_token"
+	TokenTypeJWT          = "urn:ietf:params:oauth:token-type:jwt"
+
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// TokenExchanger is implemented by providers whose token endpoint supports
+// OAuth 2.0 Token Exchange (RFC 8693). It trades a token the caller already
+// holds for a delegated or impersonation token scoped to the given audience.
+type TokenExchanger interface {
+	TokenExchange(subjectToken, audience string, scopes ...string) (*oauth2.Token, error)
+}
+
+// TokenExchangeRequest describes a single RFC 8693 token exchange request.
+// ClientID and ClientSecret are optional; when set they are sent using
+// HTTP Basic authentication.
+type TokenExchangeRequest struct {
+	TokenURL           string
+	ClientID           string
+	ClientSecret       string
+	SubjectToken       string
+	SubjectTokenType   string
+	ActorToken         string
+	ActorTokenType     string
+	RequestedTokenType string
+	Audience           string
+	Resource           string
+	Scopes             []string
+}
+
+type tokenExchangeResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Scope           string `json:"scope"`
+	RefreshToken    string `json:"refresh_token"`
+}
+
+// ExchangeToken performs an RFC 8693 token exchange against the token endpoint
+// described by r. If SubjectTokenType is empty, the subject token is assumed
+// to be an access token.
+// See https://datatracker.ietf.org/doc/html/rfc8693#section-2.1
+func ExchangeToken(client *http.Client, r TokenExchangeRequest) (*oauth2.Token, error) {
+	if r.TokenURL == "" {
+		return nil, errors.New("token exchange requires a token URL")
+	}
+	if r.SubjectToken == "" {
+		return nil, errors.New("token exchange requires a subject token")
+	}
+
+	subjectTokenType := r.SubjectTokenType
+	if subjectTokenType == "" {
+		subjectTokenType = TokenTypeAccessToken
+	}
+
+	v := url.Values{
+		"grant_type":         {grantTypeTokenExchange},
+		"subject_token":      {r.SubjectToken},
+		"subject_token_type": {subjectTokenType},
+	}
+	if r.Audience != "" {
+		v.Set("audience", r.Audience)
+	}
+	if r.Resource != "" {
+		v.Set("resource", r.Resource)
+	}
+	if len(r.Scopes) > 0 {
+		v.Set("scope", strings.Join(r.Scopes, " "))
+	}
+	if r.RequestedTokenType != "" {
+		v.Set("requested_token_type", r.RequestedTokenType)
+	}
+	if r.ActorToken != "" {
+		v.Set("actor_token", r.ActorToken)
+		v.Set("actor_token_type", r.ActorTokenType)
+	}
+
+	req, err := http.NewRequest("POST", r.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if r.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(r.ClientID), url.QueryEscape(r.ClientSecret))
+	}
+
+	resp, err := HTTPClientWithFallBack(client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ProviderAPIError{Provider: "token exchange", Status: resp.StatusCode, Body: string(body)}
+	}
+
+	tr := tokenExchangeResponse{}
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, err
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("token exchange response did not contain an access_token")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = ExpiresIn(tr.ExpiresIn)
+	}
+
+	return token.WithExtra(map[string]interface{}{
+		"issued_token_type": tr.IssuedTokenType,
+		"scope":             tr.Scope,
+	}), nil
+}
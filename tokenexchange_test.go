@@ -0,0 +1,66 @@
+package goth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExchangeToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("urn:ietf:params:oauth:grant-type:token-exchange", r.PostForm.Get("grant_type"))
+		a.Equal("subject", r.PostForm.Get("subject_token"))
+		a.Equal(goth.TokenTypeAccessToken, r.PostForm.Get("subject_token_type"))
+		a.Equal("https://api.example.com", r.PostForm.Get("audience"))
+		a.Equal("read write", r.PostForm.Get("scope"))
+
+		id, secret, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal("client", id)
+		a.Equal("secret", secret)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"delegated","issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer","expires_in":60}`))
+	}))
+	defer ts.Close()
+
+	token, err := goth.ExchangeToken(nil, goth.TokenExchangeRequest{
+		TokenURL:     ts.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		SubjectToken: "subject",
+		Audience:     "https://api.example.com",
+		Scopes:       []string{"read", "write"},
+	})
+	a.NoError(err)
+	a.Equal("delegated", token.AccessToken)
+	a.Equal("Bearer", token.TokenType)
+	a.Equal(goth.TokenTypeAccessToken, token.Extra("issued_token_type"))
+	a.False(token.Expiry.IsZero())
+}
+
+func Test_ExchangeTokenError(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_target"}`))
+	}))
+	defer ts.Close()
+
+	_, err := goth.ExchangeToken(nil, goth.TokenExchangeRequest{
+		TokenURL:     ts.URL,
+		SubjectToken: "subject",
+	})
+	a.Error(err)
+	a.Contains(err.Error(), "invalid_target")
+
+	_, err = goth.ExchangeToken(nil, goth.TokenExchangeRequest{TokenURL: ts.URL})
+	a.Error(err)
+}
@@ -1,7 +1,9 @@
 package goth
 
 import (
+	"bytes"
 	"encoding/gob"
+	"encoding/json"
 	"time"
 )
 
@@ -28,4 +30,81 @@ type User struct {
 	RefreshToken      string
 	ExpiresAt         time.Time
 	IDToken           string
+	// EmailVerified reports whether the provider verified that Email
+	// belongs to the user, so it can be trusted to link accounts. It is
+	// false when the provider does not tell.
+	EmailVerified bool
+	// ImpersonatedBy is the ID of the administrator acting as the user, for
+	// the users of an impersonation. It is empty otherwise.
+	ImpersonatedBy string
+}
+
+// user has the same fields as User but none of its methods, so it can be
+// encoded without recursing into MarshalJSON.
+type user User
+
+// MarshalJSON encodes the user with its RawData normalized, so the output
+// does not depend on the concrete types a provider happened to decode into.
+func (u User) MarshalJSON() ([]byte, error) {
+	raw, err := NormalizeRawData(u.RawData)
+	if err != nil {
+		return nil, err
+	}
+	u.RawData = raw
+	return json.Marshal(user(u))
+}
+
+// GobEncode encodes the user as JSON. Gob cannot encode the arbitrary values
+// found in RawData unless every concrete type has been registered, so the
+// JSON form is used instead.
+func (u User) GobEncode() ([]byte, error) {
+	return u.MarshalJSON()
+}
+
+// GobDecode decodes a user previously encoded with GobEncode.
+func (u *User) GobDecode(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	return d.Decode((*user)(u))
+}
+
+// Redacted returns a copy of the user with all tokens removed, which is
+// safe to write to logs.
+func (u User) Redacted() User {
+	u.AccessToken = ""
+	u.AccessTokenSecret = ""
+	u.RefreshToken = ""
+	u.IDToken = ""
+	return u
+}
+
+// BoolClaim reports whether the claim name of claims is true. Some providers
+// send their boolean claims, such as email_verified, as strings.
+func BoolClaim(claims map[string]interface{}, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// NormalizeRawData converts raw provider data into the generic JSON types
+// (string, float64, bool, nil, []interface{} and map[string]interface{}),
+// so it round-trips through JSON and gob without changing shape.
+func NormalizeRawData(raw map[string]interface{}) (map[string]interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := map[string]interface{}{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
 }
@@ -1 +1,91 @@
 package goth_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+type rawProfile struct {
+	Login string `json:"login"`
+	Age   int    `json:"age"`
+}
+
+func testUser() goth.User {
+	return goth.User{
+		RawData: map[string]interface{}{
+			"profile": rawProfile{Login: "homer", Age: 39},
+			"groups":  []string{"admins"},
+		},
+		Provider:     "faux",
+		Email:        "homer@example.com",
+		UserID:       "1",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		IDToken:      "id",
+		ExpiresAt:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func Test_UserMarshalJSON(t *testing.T) {
+	a := assert.New(t)
+
+	b, err := json.Marshal(testUser())
+	a.NoError(err)
+
+	u := goth.User{}
+	a.NoError(json.Unmarshal(b, &u))
+	a.Equal("homer@example.com", u.Email)
+	a.Equal(map[string]interface{}{"login": "homer", "age": float64(39)}, u.RawData["profile"])
+	a.Equal([]interface{}{"admins"}, u.RawData["groups"])
+
+	again, err := json.Marshal(u)
+	a.NoError(err)
+	a.Equal(string(b), string(again))
+}
+
+func Test_UserGob(t *testing.T) {
+	a := assert.New(t)
+
+	var buf bytes.Buffer
+	a.NoError(gob.NewEncoder(&buf).Encode(testUser()))
+
+	u := goth.User{}
+	a.NoError(gob.NewDecoder(&buf).Decode(&u))
+	a.Equal("homer@example.com", u.Email)
+	a.Equal("access", u.AccessToken)
+	a.True(u.ExpiresAt.Equal(testUser().ExpiresAt))
+	a.Equal("homer", u.RawData["profile"].(map[string]interface{})["login"])
+}
+
+func Test_UserRedacted(t *testing.T) {
+	a := assert.New(t)
+
+	u := testUser()
+	r := u.Redacted()
+	a.Empty(r.AccessToken)
+	a.Empty(r.RefreshToken)
+	a.Empty(r.IDToken)
+	a.Equal(u.Email, r.Email)
+	a.Equal("access", u.AccessToken)
+}
+
+func Test_NormalizeRawData(t *testing.T) {
+	a := assert.New(t)
+
+	raw, err := goth.NormalizeRawData(nil)
+	a.NoError(err)
+	a.Nil(raw)
+
+	raw, err = goth.NormalizeRawData(map[string]interface{}{"id": 42})
+	a.NoError(err)
+	a.Equal(float64(42), raw["id"])
+
+	_, err = goth.NormalizeRawData(map[string]interface{}{"fn": func() {}})
+	a.Error(err)
+}
@@ -0,0 +1,130 @@
+package goth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// UserCache stores users fetched from providers so that FetchUser does not
+// have to call the provider's user info endpoint again for the same access
+// token. Keys are built with UserCacheKey and never contain the token itself.
+// Implementations must be safe for concurrent use.
+type UserCache interface {
+	Get(key string) (User, bool)
+	Set(key string, user User)
+}
+
+// UserCacheKey returns the cache key of the user holding accessToken for the
+// named provider.
+func UserCacheKey(provider, accessToken string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + accessToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// FetchUserWithCache returns the user cached for accessToken, or fetches it
+// with p.FetchUser and caches it. Failed fetches are not cached. A nil cache
+// or an empty access token disables caching.
+func FetchUserWithCache(cache UserCache, p Provider, s Session, accessToken string) (User, error) {
+	if cache == nil || accessToken == "" {
+		return p.FetchUser(s)
+	}
+
+	key := UserCacheKey(p.Name(), accessToken)
+	if user, ok := cache.Get(key); ok {
+		return user, nil
+	}
+
+	user, err := p.FetchUser(s)
+	if err != nil {
+		return user, err
+	}
+	cache.Set(key, user)
+	return user, nil
+}
+
+// DefaultUserCacheSize is the number of users a MemoryUserCache holds when
+// its MaxEntries is not set.
+const DefaultUserCacheSize = 10000
+
+// MemoryUserCache is an in-process UserCache. Entries expire after TTL, or
+// when the access token they were fetched with expires, whichever comes
+// first.
+type MemoryUserCache struct {
+	TTL        time.Duration
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]userCacheEntry
+}
+
+type userCacheEntry struct {
+	user      User
+	expiresAt time.Time
+}
+
+// NewMemoryUserCache returns an in-process cache keeping users for ttl.
+func NewMemoryUserCache(ttl time.Duration) *MemoryUserCache {
+	return &MemoryUserCache{TTL: ttl}
+}
+
+// Get returns the cached user for key, if it has not expired.
+func (c *MemoryUserCache) Get(key string) (User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return User{}, false
+	}
+	if !Now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return User{}, false
+	}
+	return e.user, true
+}
+
+// Set caches user under key.
+func (c *MemoryUserCache) Set(key string, user User) {
+	expiresAt := Now().Add(c.TTL)
+	if !user.ExpiresAt.IsZero() && user.ExpiresAt.Before(expiresAt) {
+		expiresAt = user.ExpiresAt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]userCacheEntry{}
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries() {
+		c.evict()
+	}
+	c.entries[key] = userCacheEntry{user: user, expiresAt: expiresAt}
+}
+
+func (c *MemoryUserCache) maxEntries() int {
+	if c.MaxEntries > 0 {
+		return c.MaxEntries
+	}
+	return DefaultUserCacheSize
+}
+
+// evict drops the expired entries, or an arbitrary one when none has
+// expired. c.mu must be held.
+func (c *MemoryUserCache) evict() {
+	now := Now()
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) < c.maxEntries() {
+		return
+	}
+	for k := range c.entries {
+		delete(c.entries, k)
+		return
+	}
+}
@@ -0,0 +1,113 @@
+package goth_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/bgdsh/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingProvider struct {
+	faux.Provider
+	calls int
+	err   error
+}
+
+func (p *countingProvider) FetchUser(s goth.Session) (goth.User, error) {
+	p.calls++
+	if p.err != nil {
+		return goth.User{}, p.err
+	}
+	return p.Provider.FetchUser(s)
+}
+
+func Test_FetchUserWithCache(t *testing.T) {
+	a := assert.New(t)
+
+	cache := goth.NewMemoryUserCache(time.Minute)
+	p := &countingProvider{}
+	sess := &faux.Session{Name: "Homer Simpson", AccessToken: "token"}
+
+	u, err := goth.FetchUserWithCache(cache, p, sess, "token")
+	a.NoError(err)
+	a.Equal("Homer Simpson", u.Name)
+	u, err = goth.FetchUserWithCache(cache, p, sess, "token")
+	a.NoError(err)
+	a.Equal("Homer Simpson", u.Name)
+	a.Equal(1, p.calls)
+
+	_, err = goth.FetchUserWithCache(cache, p, sess, "other")
+	a.NoError(err)
+	a.Equal(2, p.calls)
+
+	_, err = goth.FetchUserWithCache(nil, p, sess, "token")
+	a.NoError(err)
+	a.Equal(3, p.calls)
+}
+
+func Test_FetchUserWithCacheError(t *testing.T) {
+	a := assert.New(t)
+
+	cache := goth.NewMemoryUserCache(time.Minute)
+	p := &countingProvider{err: errors.New("rate limited")}
+	sess := &faux.Session{AccessToken: "token"}
+
+	_, err := goth.FetchUserWithCache(cache, p, sess, "token")
+	a.Error(err)
+	_, ok := cache.Get(goth.UserCacheKey(p.Name(), "token"))
+	a.False(ok)
+}
+
+func Test_MemoryUserCacheExpiry(t *testing.T) {
+	a := assert.New(t)
+
+	now := time.Now()
+	goth.Clock = func() time.Time { return now }
+	defer func() { goth.Clock = time.Now }()
+
+	cache := goth.NewMemoryUserCache(time.Minute)
+	cache.Set("a", goth.User{UserID: "a"})
+	cache.Set("b", goth.User{UserID: "b", ExpiresAt: now.Add(time.Second)})
+
+	now = now.Add(2 * time.Second)
+	_, ok := cache.Get("a")
+	a.True(ok)
+	_, ok = cache.Get("b")
+	a.False(ok, "entries expire with the access token")
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("a")
+	a.False(ok)
+}
+
+func Test_MemoryUserCacheMaxEntries(t *testing.T) {
+	a := assert.New(t)
+
+	cache := goth.NewMemoryUserCache(time.Minute)
+	cache.MaxEntries = 2
+	cache.Set("a", goth.User{})
+	cache.Set("b", goth.User{})
+	cache.Set("c", goth.User{})
+
+	n := 0
+	for _, k := range []string{"a", "b", "c"} {
+		if _, ok := cache.Get(k); ok {
+			n++
+		}
+	}
+	a.Equal(2, n)
+	_, ok := cache.Get("c")
+	a.True(ok)
+}
+
+func Test_UserCacheKey(t *testing.T) {
+	a := assert.New(t)
+
+	key := goth.UserCacheKey("github", "token")
+	a.Len(key, 64)
+	a.NotContains(key, "token")
+	a.NotEqual(key, goth.UserCacheKey("gitlab", "token"))
+}
@@ -0,0 +1,67 @@
+package goth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenSaver persists the tokens refreshed by the token sources of
+// UserTokenSource. The store.TokenStore implementations of gothic are
+// TokenSavers.
+type TokenSaver interface {
+	SaveToken(ctx context.Context, token StoredToken) error
+}
+
+// UserClient returns an HTTP client calling the APIs of the provider of token
+// on behalf of its user, outside of any request of the user, such as from a
+// background job. The client authenticates its calls with the access token,
+// refreshes it when it expires, see UserTokenSource, and makes them with the
+// HTTP client of the provider.
+//
+//	token, err := tokenStore.LoadToken(ctx, "google", userID)
+//	client, err := goth.UserClient(ctx, token, tokenStore)
+//	resp, err := client.Get("https://www.googleapis.com/calendar/v3/users/me/calendarList")
+func UserClient(ctx context.Context, token StoredToken, saver TokenSaver) (*http.Client, error) {
+	source, err := UserTokenSource(ctx, token, saver)
+	if err != nil {
+		return nil, err
+	}
+	provider, _ := GetProvider(token.Provider)
+	base := DefaultHTTPClient
+	if c, ok := provider.(interface{ Client() *http.Client }); ok {
+		base = c.Client()
+	}
+	if base != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, base)
+	}
+	return oauth2.NewClient(ctx, source), nil
+}
+
+// UserTokenSource returns the token source of the user of token. It refreshes
+// the access token when it expires within RefreshLeeway, see RefreshToken, and
+// saves the new tokens with saver, when not nil, as providers rotating their
+// refresh tokens invalidate the previous one. The access tokens of providers
+// not refreshing tokens are used as they are, until they are rejected.
+func UserTokenSource(ctx context.Context, token StoredToken, saver TokenSaver) (oauth2.TokenSource, error) {
+	provider, err := GetProvider(token.Provider)
+	if err != nil {
+		return nil, err
+	}
+	session, err := SessionFromToken(provider, token)
+	if err != nil {
+		return nil, err
+	}
+	source := &sessionTokenSource{provider: provider, session: session}
+	if saver != nil {
+		source.onRefresh = func(session Session) error {
+			if err := saver.SaveToken(ctx, tokenOf(session, token)); err != nil {
+				return fmt.Errorf("%s: cannot save the refreshed token: %w", provider.Name(), err)
+			}
+			return nil
+		}
+	}
+	return source, nil
+}
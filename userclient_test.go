@@ -0,0 +1,94 @@
+package goth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bgdsh/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+type savedTokens []goth.StoredToken
+
+func (s *savedTokens) SaveToken(ctx context.Context, token goth.StoredToken) error {
+	*s = append(*s, token)
+	return nil
+}
+
+func Test_UserClient(t *testing.T) {
+	a := assert.New(t)
+	p := &batchProvider{refreshingProvider: refreshingProvider{available: true}}
+	p.SetName("batch")
+	goth.UseProviders(p)
+	defer goth.ClearProviders()
+
+	var authorizations []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	var saved savedTokens
+	token := goth.StoredToken{
+		Provider:     "batch",
+		UserID:       "42",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}
+	client, err := goth.UserClient(context.Background(), token, &saved)
+	a.NoError(err)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ts.URL)
+		a.NoError(err)
+		resp.Body.Close()
+	}
+	a.Equal([]string{"Bearer new-access", "Bearer new-access"}, authorizations)
+	a.Equal(1, p.calls, "the refreshed token is reused")
+	a.Len(saved, 1)
+	a.Equal("42", saved[0].UserID)
+	a.Equal("new-access", saved[0].AccessToken)
+	a.Equal("new-refresh", saved[0].RefreshToken)
+	a.Equal("new-id", saved[0].IDToken)
+
+	_, err = goth.UserClient(context.Background(), goth.StoredToken{Provider: "unknown"}, nil)
+	a.True(errors.Is(err, goth.ErrProviderNotFound))
+}
+
+func Test_UserTokenSourceWithoutRefresh(t *testing.T) {
+	a := assert.New(t)
+	p := &batchProvider{}
+	p.SetName("batch")
+	goth.UseProviders(p)
+	defer goth.ClearProviders()
+
+	expiresAt := time.Now().Add(-time.Minute)
+	source, err := goth.UserTokenSource(context.Background(), goth.StoredToken{
+		Provider:    "batch",
+		AccessToken: "access",
+		ExpiresAt:   expiresAt,
+	}, nil)
+	a.NoError(err)
+	token, err := source.Token()
+	a.NoError(err, "the access token is used until it is rejected")
+	a.Equal("access", token.AccessToken)
+	a.Equal("Bearer", token.TokenType)
+	a.True(expiresAt.Equal(token.Expiry))
+
+	p.available = true
+	p.errs = []error{errors.New("invalid_grant")}
+	source, err = goth.UserTokenSource(context.Background(), goth.StoredToken{
+		Provider:     "batch",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    expiresAt,
+	}, nil)
+	a.NoError(err)
+	_, err = source.Token()
+	a.EqualError(err, "batch: invalid_grant")
+}
@@ -0,0 +1,73 @@
+package goth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// VaultSecret is the SecretSource of a field of a secret of the key/value
+// secrets engine of HashiCorp Vault, version 1 or 2:
+//
+//	source := &goth.VaultSecret{Path: "secret/data/goth/github", Field: "client_secret"}
+type VaultSecret struct {
+	// Addr is the address of Vault, VAULT_ADDR when empty.
+	Addr string
+	// Token authenticates to Vault, VAULT_TOKEN when empty.
+	Token string
+	// Path is the API path of the secret, such as "secret/data/goth/github"
+	// for version 2 of the engine, mounted at secret/.
+	Path string
+	// Field is the field of the secret holding the secret.
+	Field string
+
+	HTTPClient *http.Client
+}
+
+// Secret reads the secret from Vault.
+func (v *VaultSecret) Secret(ctx context.Context) (string, error) {
+	addr, token := v.Addr, v.Token
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("vault: the address and token of Vault are needed to read %s", v.Path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(v.Path, "/"), nil)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := HTTPClientWithFallBack(v.HTTPClient).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", NewProviderAPIError("vault", "trying to read "+v.Path, resp)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	fields := body.Data
+	if nested, ok := fields["data"].(map[string]interface{}); ok && fields["metadata"] != nil {
+		// version 2 of the engine nests the fields of the secret
+		fields = nested
+	}
+	secret, _ := fields[v.Field].(string)
+	if secret == "" {
+		return "", fmt.Errorf("vault: %s has no %s field", v.Path, v.Field)
+	}
+	return secret, nil
+}